@@ -0,0 +1,156 @@
+// Package accesslog writes the access logs of middleware.AccessLogUnaryInterceptor and
+// middleware.AccessLogHTTPMiddleware in other formats and to other sinks than the
+// application log. Every piece is a slog.Handler: NewHandler formats records as JSON
+// lines or in the Apache combined format, the Exporter sends them to an OTLP logs
+// endpoint, and NewSampler keeps only a fraction of the records of high-traffic routes.
+//
+// Access records carry the attributes method, code or status, duration, request_id,
+// peer and user_agent; HTTP records add path, proto, bytes and referer.
+package accesslog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strconv"
+	"sync"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc/codes"
+)
+
+// Formats accepted by NewHandler
+const (
+	// FormatJSON writes one JSON object per record
+	FormatJSON = "json"
+	// FormatCombined writes the Apache combined log format, gRPC calls appearing as
+	// HTTP/2 POST requests with the HTTP status of their code
+	FormatCombined = "combined"
+)
+
+// NewHandler returns a handler writing records to w in format
+func NewHandler(w io.Writer, format string) (slog.Handler, error) {
+	switch format {
+	case FormatJSON:
+		return slog.NewJSONHandler(w, nil), nil
+	case FormatCombined:
+		return &combinedHandler{mu: &sync.Mutex{}, w: w}, nil
+	}
+	return nil, fmt.Errorf("invalid access log format %q: must be %q or %q", format, FormatJSON, FormatCombined)
+}
+
+// combinedHandler writes records in the Apache combined log format
+type combinedHandler struct {
+	mu    *sync.Mutex
+	w     io.Writer
+	attrs []slog.Attr
+}
+
+func (*combinedHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+func (h *combinedHandler) Handle(_ context.Context, r slog.Record) error {
+	fields := make(map[string]slog.Value, len(h.attrs)+r.NumAttrs())
+	for _, a := range h.attrs {
+		fields[a.Key] = a.Value.Resolve()
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		fields[a.Key] = a.Value.Resolve()
+		return true
+	})
+
+	line := fmt.Sprintf("%s - - [%s] %q %s %s %q %q\n",
+		orDash(host(str(fields, "peer"))),
+		r.Time.Format("02/Jan/2006:15:04:05 -0700"),
+		requestLine(fields),
+		statusOf(fields),
+		bytesOf(fields),
+		orDash(str(fields, "referer")),
+		orDash(str(fields, "user_agent")),
+	)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, line)
+	return err
+}
+
+func (h *combinedHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &combinedHandler{mu: h.mu, w: h.w, attrs: append(h.attrs[:len(h.attrs):len(h.attrs)], attrs...)}
+}
+
+// WithGroup returns the handler itself, the combined format having no room for groups
+func (h *combinedHandler) WithGroup(string) slog.Handler {
+	return h
+}
+
+// requestLine returns the request of an HTTP record, or a POST of the method of a gRPC
+// record
+func requestLine(fields map[string]slog.Value) string {
+	if path := str(fields, "path"); path != "" {
+		proto := str(fields, "proto")
+		if proto == "" {
+			proto = "HTTP/1.1"
+		}
+		return str(fields, "method") + " " + path + " " + proto
+	}
+	return "POST " + str(fields, "method") + " HTTP/2.0"
+}
+
+// statusOf returns the HTTP status of an HTTP record, or the one of the code of a gRPC
+// record
+func statusOf(fields map[string]slog.Value) string {
+	if v, ok := fields["status"]; ok {
+		return strconv.FormatInt(v.Int64(), 10)
+	}
+	code, ok := codesByName[str(fields, "code")]
+	if !ok {
+		return "-"
+	}
+	return strconv.Itoa(runtime.HTTPStatusFromCode(code))
+}
+
+// codesByName maps the names logged for codes, such as NotFound, to the codes
+var codesByName = func() map[string]codes.Code {
+	m := make(map[string]codes.Code)
+	for c := codes.OK; c <= codes.Unauthenticated; c++ {
+		m[c.String()] = c
+	}
+	return m
+}()
+
+// bytesOf returns the response size of a record, "-" when unknown or empty
+func bytesOf(fields map[string]slog.Value) string {
+	v, ok := fields["bytes"]
+	if !ok || v.Kind() != slog.KindInt64 || v.Int64() == 0 {
+		return "-"
+	}
+	return strconv.FormatInt(v.Int64(), 10)
+}
+
+// host returns the host of a peer address
+func host(addr string) string {
+	if h, _, err := net.SplitHostPort(addr); err == nil {
+		return h
+	}
+	return addr
+}
+
+// str returns the attribute of a record as a string, empty when it has none
+func str(fields map[string]slog.Value, key string) string {
+	v, ok := fields[key]
+	if !ok {
+		return ""
+	}
+	return v.String()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,72 @@
+package accesslog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHandler_Combined(t *testing.T) {
+	at := time.Date(2024, 3, 5, 14, 7, 9, 0, time.UTC)
+	tests := []struct {
+		name  string
+		attrs []slog.Attr
+		want  string
+	}{
+		{
+			name: "gRPC call",
+			attrs: []slog.Attr{
+				slog.String("method", "/orders.v1.Orders/Get"),
+				slog.String("code", "NotFound"),
+				slog.String("peer", "10.0.0.7:51234"),
+				slog.String("user_agent", "grpc-go/1.71.0"),
+			},
+			want: `10.0.0.7 - - [05/Mar/2024:14:07:09 +0000] "POST /orders.v1.Orders/Get HTTP/2.0" 404 - "-" "grpc-go/1.71.0"` + "\n",
+		},
+		{
+			name: "HTTP request",
+			attrs: []slog.Attr{
+				slog.String("method", "GET"),
+				slog.String("path", "/v1/orders"),
+				slog.String("proto", "HTTP/1.1"),
+				slog.Int("status", 200),
+				slog.Int("bytes", 512),
+				slog.String("peer", "192.0.2.1:40000"),
+				slog.String("user_agent", "curl/8.0"),
+				slog.String("referer", "https://example.com/"),
+			},
+			want: `192.0.2.1 - - [05/Mar/2024:14:07:09 +0000] "GET /v1/orders HTTP/1.1" 200 512 "https://example.com/" "curl/8.0"` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var out bytes.Buffer
+			handler, err := NewHandler(&out, FormatCombined)
+			require.NoError(t, err)
+			r := slog.NewRecord(at, slog.LevelInfo, "access", 0)
+			r.AddAttrs(tt.attrs...)
+
+			// Act
+			err = handler.Handle(context.Background(), r)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, out.String())
+		})
+	}
+}
+
+func TestNewHandler_InvalidFormat(t *testing.T) {
+	// Act
+	_, err := NewHandler(&bytes.Buffer{}, "xml")
+
+	// Assert
+	assert.ErrorContains(t, err, `invalid access log format "xml"`)
+}
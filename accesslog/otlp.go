@@ -0,0 +1,327 @@
+package accesslog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/legrch/netgex/internal/eventqueue"
+)
+
+// DefaultOTLPEndpoint is the OTLP/HTTP logs endpoint of a local collector
+const DefaultOTLPEndpoint = "http://localhost:4318/v1/logs"
+
+// scopeName names the instrumentation scope of exported records
+const scopeName = "github.com/legrch/netgex/accesslog"
+
+// Option configures an Exporter
+type Option func(*Exporter)
+
+// WithResource sets the resource attributes of exported records, such as service.name
+func WithResource(attrs map[string]string) Option {
+	return func(e *Exporter) {
+		for k, v := range attrs {
+			e.resource[k] = v
+		}
+	}
+}
+
+// WithHeaders adds headers to export requests, such as the API key of a vendor
+func WithHeaders(headers map[string]string) Option {
+	return func(e *Exporter) {
+		for k, v := range headers {
+			e.headers[k] = v
+		}
+	}
+}
+
+// WithQueueSize sets how many records wait to be exported before new ones are dropped,
+// 2048 by default
+func WithQueueSize(size int) Option {
+	return func(e *Exporter) {
+		e.queueSize = size
+	}
+}
+
+// WithBatchSize sets how many records an export request carries at most, 512 by default
+func WithBatchSize(size int) Option {
+	return func(e *Exporter) {
+		e.batchSize = size
+	}
+}
+
+// WithTimeout bounds an export request, 10s by default
+func WithTimeout(timeout time.Duration) Option {
+	return func(e *Exporter) {
+		e.timeout = timeout
+	}
+}
+
+// WithHTTPClient sets the HTTP client sending export requests
+func WithHTTPClient(client *http.Client) Option {
+	return func(e *Exporter) {
+		e.httpClient = client
+	}
+}
+
+// WithLogger sets the logger of export failures, slog.Default() by default
+func WithLogger(logger *slog.Logger) Option {
+	return func(e *Exporter) {
+		e.logger = logger
+	}
+}
+
+// Exporter sends records to an OTLP/HTTP logs endpoint in JSON. Its Handler queues
+// records; they are sent in batches while it runs as a server process, and the pending
+// ones are flushed on shutdown.
+type Exporter struct {
+	endpoint   string
+	resource   map[string]string
+	headers    map[string]string
+	queueSize  int
+	batchSize  int
+	timeout    time.Duration
+	httpClient *http.Client
+	logger     *slog.Logger
+
+	queue *eventqueue.Queue[logRecord]
+}
+
+// NewExporter creates an exporter sending records to endpoint, such as
+// DefaultOTLPEndpoint
+func NewExporter(endpoint string, opts ...Option) *Exporter {
+	e := &Exporter{
+		endpoint:   endpoint,
+		resource:   map[string]string{},
+		headers:    map[string]string{},
+		queueSize:  2048,
+		batchSize:  512,
+		timeout:    10 * time.Second,
+		httpClient: http.DefaultClient,
+		logger:     slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	e.queue = eventqueue.NewBatched(e.queueSize, e.batchSize, e.send)
+	return e
+}
+
+// Handler returns a handler queuing records for export, dropping them when the queue is
+// full
+func (e *Exporter) Handler() slog.Handler {
+	return &otlpHandler{exporter: e}
+}
+
+// send posts a batch of records
+func (e *Exporter) send(ctx context.Context, records []logRecord) {
+	resource := make([]keyValue, 0, len(e.resource))
+	for k, v := range e.resource {
+		resource = append(resource, keyValue{Key: k, Value: anyValue{StringValue: &v}})
+	}
+	body, err := json.Marshal(exportRequest{ResourceLogs: []resourceLogs{{
+		Resource: resourceAttrs{Attributes: resource},
+		ScopeLogs: []scopeLogs{{
+			Scope:      scope{Name: scopeName},
+			LogRecords: records,
+		}},
+	}}})
+	if err != nil {
+		e.logger.Error("failed to encode access log records", "error", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		e.logger.Error("failed to export access log records", "error", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		e.logger.Warn("failed to export access log records", "records", len(records), "error", err)
+		return
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		e.logger.Warn("OTLP endpoint rejected access log records", "records", len(records), "status", resp.StatusCode)
+	}
+}
+
+// Flush waits until the queued records are sent or ctx ends
+func (e *Exporter) Flush(ctx context.Context) error {
+	return e.queue.Flush(ctx)
+}
+
+// PreRun prepares the exporter
+func (*Exporter) PreRun(_ context.Context) error {
+	return nil
+}
+
+// Run sends the queued records until ctx is canceled
+func (e *Exporter) Run(ctx context.Context) error {
+	return e.queue.Run(ctx)
+}
+
+// Shutdown stops exporting and sends the remaining records, bounded by ctx
+func (e *Exporter) Shutdown(ctx context.Context) error {
+	if err := e.queue.Shutdown(ctx); err != nil {
+		return fmt.Errorf("access log exporter: %w", err)
+	}
+	return nil
+}
+
+// otlpHandler converts records to OTLP log records and queues them
+type otlpHandler struct {
+	exporter *Exporter
+	attrs    []keyValue
+	groups   []string
+}
+
+func (*otlpHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+func (h *otlpHandler) Handle(ctx context.Context, r slog.Record) error {
+	record := logRecord{
+		TimeUnixNano:         strconv.FormatInt(r.Time.UnixNano(), 10),
+		ObservedTimeUnixNano: strconv.FormatInt(time.Now().UnixNano(), 10),
+		SeverityNumber:       min(max(int(r.Level)+9, 1), 24),
+		SeverityText:         r.Level.String(),
+		Body:                 anyValue{StringValue: &r.Message},
+		Attributes:           append([]keyValue(nil), h.attrs...),
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		record.Attributes = appendAttr(record.Attributes, h.groups, a)
+		return true
+	})
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		record.TraceID = sc.TraceID().String()
+		record.SpanID = sc.SpanID().String()
+	}
+
+	if !h.exporter.queue.Enqueue(record) {
+		return errors.New("access log exporter queue is full")
+	}
+	return nil
+}
+
+func (h *otlpHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = append([]keyValue(nil), h.attrs...)
+	for _, a := range attrs {
+		clone.attrs = appendAttr(clone.attrs, h.groups, a)
+	}
+	return &clone
+}
+
+func (h *otlpHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.groups = append(h.groups[:len(h.groups):len(h.groups)], name)
+	return &clone
+}
+
+// appendAttr appends an attribute, its key prefixed with the open groups
+func appendAttr(kvs []keyValue, groups []string, a slog.Attr) []keyValue {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return kvs
+	}
+	key := a.Key
+	for i := len(groups) - 1; i >= 0; i-- {
+		key = groups[i] + "." + key
+	}
+	return append(kvs, keyValue{Key: key, Value: otlpValue(a.Value)})
+}
+
+// otlpValue converts a slog value to an OTLP value
+func otlpValue(v slog.Value) anyValue {
+	switch v.Kind() {
+	case slog.KindString:
+		s := v.String()
+		return anyValue{StringValue: &s}
+	case slog.KindInt64:
+		i := strconv.FormatInt(v.Int64(), 10)
+		return anyValue{IntValue: &i}
+	case slog.KindUint64:
+		i := strconv.FormatUint(v.Uint64(), 10)
+		return anyValue{IntValue: &i}
+	case slog.KindFloat64:
+		f := v.Float64()
+		return anyValue{DoubleValue: &f}
+	case slog.KindBool:
+		b := v.Bool()
+		return anyValue{BoolValue: &b}
+	case slog.KindGroup:
+		var kvs []keyValue
+		for _, a := range v.Group() {
+			kvs = appendAttr(kvs, nil, a)
+		}
+		return anyValue{KvlistValue: &kvList{Values: kvs}}
+	default:
+		s := v.String()
+		return anyValue{StringValue: &s}
+	}
+}
+
+// The OTLP/HTTP JSON encoding of an export request
+type (
+	exportRequest struct {
+		ResourceLogs []resourceLogs `json:"resourceLogs"`
+	}
+	resourceLogs struct {
+		Resource  resourceAttrs `json:"resource"`
+		ScopeLogs []scopeLogs   `json:"scopeLogs"`
+	}
+	resourceAttrs struct {
+		Attributes []keyValue `json:"attributes"`
+	}
+	scopeLogs struct {
+		Scope      scope       `json:"scope"`
+		LogRecords []logRecord `json:"logRecords"`
+	}
+	scope struct {
+		Name string `json:"name"`
+	}
+	logRecord struct {
+		TimeUnixNano         string     `json:"timeUnixNano"`
+		ObservedTimeUnixNano string     `json:"observedTimeUnixNano"`
+		SeverityNumber       int        `json:"severityNumber"`
+		SeverityText         string     `json:"severityText"`
+		Body                 anyValue   `json:"body"`
+		Attributes           []keyValue `json:"attributes,omitempty"`
+		TraceID              string     `json:"traceId,omitempty"`
+		SpanID               string     `json:"spanId,omitempty"`
+	}
+	keyValue struct {
+		Key   string   `json:"key"`
+		Value anyValue `json:"value"`
+	}
+	anyValue struct {
+		StringValue *string  `json:"stringValue,omitempty"`
+		IntValue    *string  `json:"intValue,omitempty"`
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+		BoolValue   *bool    `json:"boolValue,omitempty"`
+		KvlistValue *kvList  `json:"kvlistValue,omitempty"`
+	}
+	kvList struct {
+		Values []keyValue `json:"values"`
+	}
+)
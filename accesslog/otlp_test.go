@@ -0,0 +1,58 @@
+package accesslog
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExporter(t *testing.T) {
+	// Arrange
+	requests := make(chan exportRequest, 1)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/logs", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "secret", r.Header.Get("Api-Key"))
+		var req exportRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		requests <- req
+	}))
+	defer collector.Close()
+
+	exporter := NewExporter(collector.URL+"/v1/logs",
+		WithResource(map[string]string{"service.name": "orders"}),
+		WithHeaders(map[string]string{"Api-Key": "secret"}),
+	)
+	logger := slog.New(exporter.Handler()).WithGroup("rpc")
+	logger.Warn("gRPC call", "method", "/orders.v1.Orders/Get", "status", 503)
+
+	// Act
+	err := exporter.Shutdown(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	req := <-requests
+	require.Len(t, req.ResourceLogs, 1)
+	resource := req.ResourceLogs[0]
+	require.Len(t, resource.Resource.Attributes, 1)
+	assert.Equal(t, "service.name", resource.Resource.Attributes[0].Key)
+	assert.Equal(t, "orders", *resource.Resource.Attributes[0].Value.StringValue)
+
+	require.Len(t, resource.ScopeLogs, 1)
+	require.Len(t, resource.ScopeLogs[0].LogRecords, 1)
+	record := resource.ScopeLogs[0].LogRecords[0]
+	assert.Equal(t, 13, record.SeverityNumber)
+	assert.Equal(t, "WARN", record.SeverityText)
+	assert.Equal(t, "gRPC call", *record.Body.StringValue)
+	require.Len(t, record.Attributes, 2)
+	assert.Equal(t, "rpc.method", record.Attributes[0].Key)
+	assert.Equal(t, "/orders.v1.Orders/Get", *record.Attributes[0].Value.StringValue)
+	assert.Equal(t, "rpc.status", record.Attributes[1].Key)
+	assert.Equal(t, "503", *record.Attributes[1].Value.IntValue)
+}
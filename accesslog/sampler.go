@@ -0,0 +1,120 @@
+package accesslog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// SampleRate keeps Rate, between 0 and 1, of the records of the routes matching Pattern.
+// Routes are the full method of gRPC calls, such as /orders.v1.Orders/List, and the
+// method and path of HTTP requests, such as GET /v1/orders; patterns use path.Match
+// syntax.
+type SampleRate struct {
+	Pattern string
+	Rate    float64
+}
+
+// ParseSampleRates parses rates written as pattern=rate, such as
+// /orders.v1.Orders/*=0.1
+func ParseSampleRates(specs []string) ([]SampleRate, error) {
+	rates := make([]SampleRate, 0, len(specs))
+	for _, spec := range specs {
+		i := strings.LastIndex(spec, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid sample rate %q: expected pattern=rate", spec)
+		}
+		pattern := strings.TrimSpace(spec[:i])
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid sample rate pattern %q: %w", pattern, err)
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(spec[i+1:]), 64)
+		if err != nil || rate < 0 || rate > 1 {
+			return nil, fmt.Errorf("invalid sample rate %q: rate must be between 0 and 1", spec)
+		}
+		rates = append(rates, SampleRate{Pattern: pattern, Rate: rate})
+	}
+	return rates, nil
+}
+
+// NewSampler returns a handler passing to next the records of routes without a rate and
+// a sample of the others, by the first rate whose pattern matches. Warnings and errors
+// are always kept.
+func NewSampler(next slog.Handler, rates []SampleRate) slog.Handler {
+	return &sampler{next: next, rates: rates}
+}
+
+// sampler drops records of sampled routes
+type sampler struct {
+	next  slog.Handler
+	rates []SampleRate
+
+	// method and path added with WithAttrs, outside of groups
+	method, path string
+	grouped      bool
+}
+
+func (h *sampler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *sampler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < slog.LevelWarn && !h.keep(r) {
+		return nil
+	}
+	return h.next.Handle(ctx, r)
+}
+
+// keep reports whether a record is part of the sample of its route
+func (h *sampler) keep(r slog.Record) bool {
+	method, p := h.method, h.path
+	if !h.grouped {
+		r.Attrs(func(a slog.Attr) bool {
+			switch a.Key {
+			case "method":
+				method = a.Value.String()
+			case "path":
+				p = a.Value.String()
+			}
+			return true
+		})
+	}
+	route := method
+	if p != "" {
+		route = method + " " + p
+	}
+
+	for _, rate := range h.rates {
+		if ok, _ := path.Match(rate.Pattern, route); ok {
+			return rand.Float64() < rate.Rate
+		}
+	}
+	return true
+}
+
+func (h *sampler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.next = h.next.WithAttrs(attrs)
+	if !h.grouped {
+		for _, a := range attrs {
+			switch a.Key {
+			case "method":
+				clone.method = a.Value.String()
+			case "path":
+				clone.path = a.Value.String()
+			}
+		}
+	}
+	return &clone
+}
+
+func (h *sampler) WithGroup(name string) slog.Handler {
+	clone := *h
+	clone.next = h.next.WithGroup(name)
+	clone.grouped = true
+	return &clone
+}
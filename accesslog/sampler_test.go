@@ -0,0 +1,70 @@
+package accesslog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSampleRates(t *testing.T) {
+	tests := []struct {
+		name    string
+		specs   []string
+		want    []SampleRate
+		wantErr string
+	}{
+		{
+			name:  "valid",
+			specs: []string{"/orders.v1.Orders/*=0.1", "GET /v1/orders = 0.5"},
+			want:  []SampleRate{{Pattern: "/orders.v1.Orders/*", Rate: 0.1}, {Pattern: "GET /v1/orders", Rate: 0.5}},
+		},
+		{name: "missing rate", specs: []string{"/orders.v1.Orders/List"}, wantErr: "expected pattern=rate"},
+		{name: "rate out of range", specs: []string{"/orders.v1.Orders/List=2"}, wantErr: "rate must be between 0 and 1"},
+		{name: "bad pattern", specs: []string{"/orders.v1.Orders/[=0.1"}, wantErr: "invalid sample rate pattern"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			rates, err := ParseSampleRates(tt.specs)
+
+			// Assert
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, rates)
+		})
+	}
+}
+
+func TestSampler(t *testing.T) {
+	// Arrange
+	var out bytes.Buffer
+	logger := slog.New(NewSampler(slog.NewTextHandler(&out, nil), []SampleRate{
+		{Pattern: "/orders.v1.Orders/List", Rate: 0},
+		{Pattern: "GET /v1/orders", Rate: 0},
+		{Pattern: "/orders.v1.Orders/*", Rate: 1},
+	}))
+	ctx := context.Background()
+
+	// Act
+	logger.Info("gRPC call", "method", "/orders.v1.Orders/List")
+	logger.Warn("gRPC call", "method", "/orders.v1.Orders/List", "code", "Internal")
+	logger.Info("gRPC call", "method", "/orders.v1.Orders/Get")
+	logger.Info("HTTP request", "method", "GET", "path", "/v1/orders")
+	logger.With("method", "POST").InfoContext(ctx, "HTTP request", "path", "/v1/orders")
+
+	// Assert
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[0], "code=Internal", "warnings are kept")
+	assert.Contains(t, lines[1], "method=/orders.v1.Orders/Get")
+	assert.Contains(t, lines[2], "method=POST")
+}
@@ -0,0 +1,227 @@
+package client
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	metricsreg "github.com/legrch/netgex/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// BreakerPolicy describes when the circuit breaker of a method opens and recovers
+type BreakerPolicy struct {
+	// FailureThreshold is the number of consecutive failures opening the circuit
+	FailureThreshold int
+	// OpenTimeout is how long the circuit stays open before letting probe calls through
+	OpenTimeout time.Duration
+	// HalfOpenRequests is the number of concurrent probe calls while half-open
+	HalfOpenRequests int
+	// Codes are the status codes counted as failures
+	Codes []codes.Code
+}
+
+// DefaultBreakerPolicy opens after five consecutive server-side failures and probes
+// again after ten seconds
+var DefaultBreakerPolicy = BreakerPolicy{
+	FailureThreshold: 5,
+	OpenTimeout:      10 * time.Second,
+	HalfOpenRequests: 1,
+	Codes:            []codes.Code{codes.Unavailable, codes.DeadlineExceeded, codes.Internal, codes.Unknown},
+}
+
+// ErrCircuitOpen is returned, with the Unavailable code, for calls rejected by an open
+// circuit breaker
+var ErrCircuitOpen = status.Error(codes.Unavailable, "circuit breaker open")
+
+// BreakerState is the state of a circuit breaker, exported as the value of the
+// grpc_client_circuit_breaker_state metric
+type BreakerState int
+
+// Circuit breaker states
+const (
+	BreakerClosed BreakerState = iota
+	BreakerHalfOpen
+	BreakerOpen
+)
+
+// String returns the name of the state
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerHalfOpen:
+		return "half-open"
+	case BreakerOpen:
+		return "open"
+	default:
+		return "unknown"
+	}
+}
+
+// WithCircuitBreaker rejects unary calls to a method with ErrCircuitOpen while its
+// recent calls keep failing, so a failing dependency is not piled up with requests.
+// Each target and method has its own breaker.
+func WithCircuitBreaker(policy BreakerPolicy) Option {
+	return func(o *options) {
+		o.breaker = &policy
+	}
+}
+
+// breaker is the circuit breaker of a single method
+type breaker struct {
+	policy   BreakerPolicy
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probes   int
+}
+
+// allow reports whether a call may proceed, moving an open circuit to half-open once
+// the open timeout elapsed
+func (b *breaker) allow(now time.Time) bool {
+	switch b.state {
+	case BreakerOpen:
+		if now.Sub(b.openedAt) < b.policy.OpenTimeout {
+			return false
+		}
+		b.state = BreakerHalfOpen
+		b.probes = 0
+		fallthrough
+	case BreakerHalfOpen:
+		if b.probes >= max(b.policy.HalfOpenRequests, 1) {
+			return false
+		}
+		b.probes++
+		return true
+	default:
+		return true
+	}
+}
+
+// record updates the breaker with the result of a call and reports whether it tripped
+func (b *breaker) record(failed bool, now time.Time) bool {
+	if !failed {
+		b.state = BreakerClosed
+		b.failures = 0
+		return false
+	}
+
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.policy.FailureThreshold {
+		b.state = BreakerOpen
+		b.openedAt = now
+		b.failures = 0
+		return true
+	}
+	return false
+}
+
+// breakerSet holds the breakers of the methods of a connection
+type breakerSet struct {
+	policy   BreakerPolicy
+	metrics  *breakerMetrics
+	now      func() time.Time
+	mu       sync.Mutex
+	breakers map[string]*breaker
+}
+
+// newBreakerSet creates the breakers of a connection, exporting their state when
+// metrics is set
+func newBreakerSet(policy BreakerPolicy, metrics *breakerMetrics) *breakerSet {
+	return &breakerSet{
+		policy:   policy,
+		metrics:  metrics,
+		now:      time.Now,
+		breakers: map[string]*breaker{},
+	}
+}
+
+// allow reports whether a call to the method may proceed
+func (s *breakerSet) allow(target, method string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.breakers[method]
+	if !ok {
+		b = &breaker{policy: s.policy}
+		s.breakers[method] = b
+	}
+	allowed := b.allow(s.now())
+	s.metrics.setState(target, method, b.state)
+	return allowed
+}
+
+// record updates the breaker of the method with the result of a call
+func (s *breakerSet) record(target, method string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.breakers[method]
+	failed := err != nil && slices.Contains(s.policy.Codes, status.Code(err))
+	if b.record(failed, s.now()) {
+		s.metrics.trip(target, method)
+	}
+	s.metrics.setState(target, method, b.state)
+}
+
+// unaryInterceptor rejects calls while the breaker of their method is open
+func (s *breakerSet) unaryInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		target := cc.Target()
+		if !s.allow(target, method) {
+			return ErrCircuitOpen
+		}
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		s.record(target, method, err)
+		return err
+	}
+}
+
+// breakerMetrics exports the state and trips of circuit breakers
+type breakerMetrics struct {
+	state *prometheus.GaugeVec
+	trips *prometheus.CounterVec
+}
+
+// clientBreakerMetrics returns the breaker collectors of the namespace, registering
+// them unless already registered
+func clientBreakerMetrics(namespace string) *breakerMetrics {
+	m := &breakerMetrics{
+		state: metricsreg.Register(prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "grpc_client_circuit_breaker_state",
+				Help:      "State of outbound gRPC circuit breakers: 0 closed, 1 half-open, 2 open",
+			},
+			[]string{"target", "method"},
+		)),
+		trips: metricsreg.Register(prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "grpc_client_circuit_breaker_trips_total",
+				Help:      "Total number of times outbound gRPC circuit breakers opened",
+			},
+			[]string{"target", "method"},
+		)),
+	}
+	return m
+}
+
+// setState exports the state of a breaker
+func (m *breakerMetrics) setState(target, method string, state BreakerState) {
+	if m != nil {
+		m.state.WithLabelValues(target, method).Set(float64(state))
+	}
+}
+
+// trip counts a breaker opening
+func (m *breakerMetrics) trip(target, method string) {
+	if m != nil {
+		m.trips.WithLabelValues(target, method).Inc()
+	}
+}
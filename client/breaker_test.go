@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+func TestBreakerSet_UnaryInterceptor(t *testing.T) {
+	// Arrange
+	conn, err := grpc.NewClient("passthrough:///orders", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	now := time.Now()
+	metrics := clientBreakerMetrics("breakertest")
+	set := newBreakerSet(BreakerPolicy{
+		FailureThreshold: 2,
+		OpenTimeout:      time.Second,
+		HalfOpenRequests: 1,
+		Codes:            []codes.Code{codes.Unavailable},
+	}, metrics)
+	set.now = func() time.Time { return now }
+	interceptor := set.unaryInterceptor()
+
+	var result error
+	invoked := 0
+	invoker := func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+		invoked++
+		return result
+	}
+	call := func() error {
+		return interceptor(context.Background(), "/svc/Get", nil, nil, conn, invoker)
+	}
+
+	// Act & Assert - a code not counted as failure keeps the circuit closed
+	result = status.Error(codes.NotFound, "missing")
+	assert.Equal(t, codes.NotFound, status.Code(call()))
+
+	// Two consecutive failures open the circuit
+	result = status.Error(codes.Unavailable, "down")
+	_ = call()
+	_ = call()
+	assert.ErrorIs(t, call(), ErrCircuitOpen)
+	assert.Equal(t, 3, invoked)
+
+	// After the open timeout a failing probe opens it again
+	now = now.Add(time.Second)
+	assert.Equal(t, codes.Unavailable, status.Code(call()))
+	assert.ErrorIs(t, call(), ErrCircuitOpen)
+
+	// A successful probe closes it
+	now = now.Add(time.Second)
+	result = nil
+	assert.NoError(t, call())
+	assert.NoError(t, call())
+	assert.Equal(t, 6, invoked)
+
+	target := conn.Target()
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.trips.WithLabelValues(target, "/svc/Get")))
+	assert.Equal(t, float64(BreakerClosed), testutil.ToFloat64(metrics.state.WithLabelValues(target, "/svc/Get")))
+}
+
+func TestBreaker_HalfOpenProbes(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	b := &breaker{policy: BreakerPolicy{FailureThreshold: 1, OpenTimeout: time.Second, HalfOpenRequests: 2}}
+	b.record(true, now)
+
+	// Act
+	beforeTimeout := b.allow(now)
+	now = now.Add(time.Second)
+	first, second, third := b.allow(now), b.allow(now), b.allow(now)
+
+	// Assert
+	assert.False(t, beforeTimeout)
+	assert.True(t, first)
+	assert.True(t, second)
+	assert.False(t, third)
+	assert.Equal(t, BreakerHalfOpen, b.state)
+}
@@ -0,0 +1,176 @@
+// Package client creates outbound gRPC connections instrumented like the netgex server:
+// they identify the calling service, trace and count calls according to the telemetry
+// configuration and propagate service mesh headers from the inbound request.
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/legrch/netgex/config"
+	"github.com/legrch/netgex/mesh"
+)
+
+// Option configures the connections created by Dial and Pool
+type Option func(*options)
+
+// options holds the settings of a connection
+type options struct {
+	cfg                *config.Config
+	tlsConfig          *tls.Config
+	block              bool
+	retry              *RetryPolicy
+	breaker            *BreakerPolicy
+	methodRetry        map[string]RetryPolicy
+	unaryInterceptors  []grpc.UnaryClientInterceptor
+	streamInterceptors []grpc.StreamClientInterceptor
+	dialOptions        []grpc.DialOption
+}
+
+// WithConfig takes the service identity, telemetry and mesh settings from cfg, usually
+// the configuration the server runs with. Defaults to config.NewConfig().
+func WithConfig(cfg *config.Config) Option {
+	return func(o *options) {
+		o.cfg = cfg
+	}
+}
+
+// WithTLS connects over TLS. Connections are insecure by default, as inside a mesh the
+// sidecar terminates TLS.
+func WithTLS(cfg *tls.Config) Option {
+	return func(o *options) {
+		o.tlsConfig = cfg
+	}
+}
+
+// WithBlock makes Dial wait until the connection is ready or its context is done
+func WithBlock() Option {
+	return func(o *options) {
+		o.block = true
+	}
+}
+
+// WithUnaryInterceptors adds unary interceptors that run after the built-in ones
+func WithUnaryInterceptors(interceptors ...grpc.UnaryClientInterceptor) Option {
+	return func(o *options) {
+		o.unaryInterceptors = append(o.unaryInterceptors, interceptors...)
+	}
+}
+
+// WithStreamInterceptors adds stream interceptors that run after the built-in ones
+func WithStreamInterceptors(interceptors ...grpc.StreamClientInterceptor) Option {
+	return func(o *options) {
+		o.streamInterceptors = append(o.streamInterceptors, interceptors...)
+	}
+}
+
+// WithDialOptions adds raw gRPC dial options, applied after the built-in ones
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(o *options) {
+		o.dialOptions = append(o.dialOptions, opts...)
+	}
+}
+
+// newOptions applies opts over the defaults
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.cfg == nil {
+		o.cfg = config.NewConfig()
+	}
+	return o
+}
+
+// Dial creates a connection to target. Calls carry the service name and version in the
+// user agent, are traced and counted when telemetry is enabled in the configuration and
+// propagate the mesh headers of the inbound request in their context.
+func Dial(ctx context.Context, target string, opts ...Option) (*grpc.ClientConn, error) {
+	o := newOptions(opts)
+
+	conn, err := grpc.NewClient(target, o.grpcDialOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("dial %s: %w", target, err)
+	}
+	if o.block {
+		if err := waitReady(ctx, conn); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("dial %s: %w", target, err)
+		}
+	}
+
+	return conn, nil
+}
+
+// grpcDialOptions returns the gRPC dial options of the connection
+func (o *options) grpcDialOptions() []grpc.DialOption {
+	creds := insecure.NewCredentials()
+	if o.tlsConfig != nil {
+		creds = credentials.NewTLS(o.tlsConfig)
+	}
+
+	unary, stream := o.interceptors()
+	dialOpts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithUserAgent(o.cfg.ServiceName + "/" + o.cfg.ServiceVersion),
+		grpc.WithChainUnaryInterceptor(unary...),
+		grpc.WithChainStreamInterceptor(stream...),
+	}
+	return append(dialOpts, o.dialOptions...)
+}
+
+// interceptors returns the built-in interceptors, mesh headers, tracing, metrics, the
+// circuit breaker and retries, followed by the user's. Retries come last so spans,
+// metrics and the breaker see the whole call rather than each attempt.
+func (o *options) interceptors() ([]grpc.UnaryClientInterceptor, []grpc.StreamClientInterceptor) {
+	var unary []grpc.UnaryClientInterceptor
+	var stream []grpc.StreamClientInterceptor
+	prometheusEnabled := o.cfg.Telemetry.Metrics.Enabled && o.cfg.Telemetry.Metrics.Backend == "prometheus"
+
+	if o.cfg.MeshHeadersEnabled {
+		unary = append(unary, mesh.UnaryClientInterceptor())
+		stream = append(stream, mesh.StreamClientInterceptor())
+	}
+	if o.cfg.Telemetry.Tracing.Enabled || o.cfg.Telemetry.OTEL.Enabled {
+		unary = append(unary, tracingUnaryInterceptor(o.cfg))
+		stream = append(stream, tracingStreamInterceptor(o.cfg))
+	}
+	if prometheusEnabled {
+		m := clientMetrics(o.cfg.Telemetry.Metrics.Namespace)
+		unary = append(unary, m.unaryInterceptor())
+		stream = append(stream, m.streamInterceptor())
+	}
+	if o.breaker != nil {
+		var m *breakerMetrics
+		if prometheusEnabled {
+			m = clientBreakerMetrics(o.cfg.Telemetry.Metrics.Namespace)
+		}
+		unary = append(unary, newBreakerSet(*o.breaker, m).unaryInterceptor())
+	}
+	if o.retry != nil || len(o.methodRetry) > 0 {
+		unary = append(unary, retryUnaryInterceptor(o.retry, o.methodRetry))
+	}
+
+	return append(unary, o.unaryInterceptors...), append(stream, o.streamInterceptors...)
+}
+
+// waitReady connects and waits until the connection is ready or ctx is done
+func waitReady(ctx context.Context, conn *grpc.ClientConn) error {
+	conn.Connect()
+	for {
+		state := conn.GetState()
+		if state == connectivity.Ready {
+			return nil
+		}
+		if !conn.WaitForStateChange(ctx, state) {
+			return fmt.Errorf("connection not ready (%s): %w", state, ctx.Err())
+		}
+	}
+}
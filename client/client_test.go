@@ -0,0 +1,143 @@
+package client
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/legrch/netgex/config"
+	"github.com/legrch/netgex/mesh"
+)
+
+// startServer serves the health service on a loopback port, sending the metadata of
+// every call to the returned channel
+func startServer(t *testing.T) (string, <-chan metadata.MD) {
+	t.Helper()
+
+	received := make(chan metadata.MD, 10)
+	srv := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		received <- md
+		return handler(ctx, req)
+	}))
+	grpc_health_v1.RegisterHealthServer(srv, health.NewServer())
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	return lis.Addr().String(), received
+}
+
+func TestDial_IdentityAndMeshHeaders(t *testing.T) {
+	// Arrange
+	address, received := startServer(t)
+	cfg := config.NewConfig()
+	cfg.ServiceName = "orders"
+	cfg.ServiceVersion = "1.2.3"
+
+	conn, err := Dial(context.Background(), address, WithConfig(cfg))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx := mesh.NewContext(context.Background(), metadata.Pairs("x-request-id", "req-1"))
+
+	// Act
+	_, err = grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+
+	// Assert
+	require.NoError(t, err)
+	md := <-received
+	assert.Equal(t, []string{"req-1"}, md.Get("x-request-id"))
+	require.NotEmpty(t, md.Get("user-agent"))
+	assert.Contains(t, md.Get("user-agent")[0], "orders/1.2.3")
+}
+
+func TestDial_Metrics(t *testing.T) {
+	// Arrange
+	address, _ := startServer(t)
+	cfg := config.NewConfig()
+	cfg.Telemetry.Metrics.Enabled = true
+	cfg.Telemetry.Metrics.Namespace = "clienttest"
+
+	conn, err := Dial(context.Background(), address, WithConfig(cfg))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// Act
+	for range 2 {
+		_, err = grpc_health_v1.NewHealthClient(conn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+		require.NoError(t, err)
+	}
+
+	// Assert
+	requests := clientMetrics("clienttest").requests.WithLabelValues(address, "/grpc.health.v1.Health/Check", "success")
+	assert.Equal(t, 2.0, testutil.ToFloat64(requests))
+}
+
+func TestDial_Block(t *testing.T) {
+	// Arrange
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := lis.Addr().String()
+	require.NoError(t, lis.Close())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	// Act
+	conn, err := Dial(ctx, address, WithBlock())
+
+	// Assert
+	assert.Nil(t, conn)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestPool(t *testing.T) {
+	// Arrange
+	address, _ := startServer(t)
+	pool := NewPool()
+
+	// Act
+	first, err := pool.Get(context.Background(), address)
+	require.NoError(t, err)
+	second, err := pool.Get(context.Background(), address)
+	require.NoError(t, err)
+	require.NoError(t, pool.Close())
+	_, closedErr := pool.Get(context.Background(), address)
+
+	// Assert
+	assert.Same(t, first, second)
+	assert.ErrorIs(t, closedErr, ErrPoolClosed)
+}
+
+func TestDial_MetricsSharedBetweenConnections(t *testing.T) {
+	// Arrange
+	cfg := config.NewConfig()
+	cfg.Telemetry.Metrics.Enabled = true
+	cfg.Telemetry.Metrics.Namespace = "clientshared"
+	breaker := WithCircuitBreaker(BreakerPolicy{FailureThreshold: 1, OpenTimeout: time.Second, HalfOpenRequests: 1})
+
+	// Act
+	for range 2 {
+		assert.NotPanics(t, func() {
+			conn, err := Dial(context.Background(), "127.0.0.1:1", WithConfig(cfg), breaker)
+			require.NoError(t, err)
+			_ = conn.Close()
+		})
+	}
+
+	// Assert
+	assert.Same(t, clientMetrics("clientshared").requests, clientMetrics("clientshared").requests)
+	assert.Same(t, clientBreakerMetrics("clientshared").state, clientBreakerMetrics("clientshared").state)
+}
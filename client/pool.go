@@ -0,0 +1,81 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"google.golang.org/grpc"
+)
+
+// ErrPoolClosed is returned by Pool.Get after the pool is closed
+var ErrPoolClosed = errors.New("client pool closed")
+
+// Pool shares one connection per target between callers. A gRPC connection multiplexes
+// concurrent calls, so dependencies should be dialed once rather than per request.
+type Pool struct {
+	opts   []Option
+	mu     sync.Mutex
+	conns  map[string]*grpc.ClientConn
+	closed bool
+}
+
+// NewPool creates a pool whose connections are dialed with opts
+func NewPool(opts ...Option) *Pool {
+	return &Pool{
+		opts:  opts,
+		conns: map[string]*grpc.ClientConn{},
+	}
+}
+
+// Get returns the connection to target, dialing it on first use
+func (p *Pool) Get(ctx context.Context, target string) (*grpc.ClientConn, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrPoolClosed
+	}
+	if conn, ok := p.conns[target]; ok {
+		return conn, nil
+	}
+	conn, err := Dial(ctx, target, p.opts...)
+	if err != nil {
+		return nil, err
+	}
+	p.conns[target] = conn
+	return conn, nil
+}
+
+// Close closes every connection of the pool
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.closed = true
+	var errs []error
+	for target, conn := range p.conns {
+		if err := conn.Close(); err != nil {
+			errs = append(errs, err)
+		}
+		delete(p.conns, target)
+	}
+	return errors.Join(errs...)
+}
+
+// PreRun is a no-op, connections are dialed on first use
+func (p *Pool) PreRun(context.Context) error {
+	return nil
+}
+
+// Run waits for the server to stop, so the pool can be added with server.WithProcesses
+// and closed on shutdown
+func (p *Pool) Run(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+// Shutdown closes the connections of the pool
+func (p *Pool) Shutdown(context.Context) error {
+	return p.Close()
+}
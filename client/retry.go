@@ -0,0 +1,110 @@
+package client
+
+import (
+	"context"
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RetryPolicy describes how failed unary calls are retried
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts including the first; 1 disables retries
+	MaxAttempts int
+	// Codes are the status codes retried
+	Codes []codes.Code
+	// InitialBackoff is the wait before the first retry
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between attempts
+	MaxBackoff time.Duration
+	// Multiplier grows the backoff after every attempt
+	Multiplier float64
+	// Jitter is the fraction of the backoff randomly removed, between 0 and 1
+	Jitter float64
+}
+
+// DefaultRetryPolicy retries calls failing with Unavailable up to three attempts
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	Codes:          []codes.Code{codes.Unavailable},
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     2 * time.Second,
+	Multiplier:     2,
+	Jitter:         0.2,
+}
+
+// WithRetry retries failed unary calls according to policy. Streams are never retried.
+func WithRetry(policy RetryPolicy) Option {
+	return func(o *options) {
+		o.retry = &policy
+	}
+}
+
+// WithMethodRetry overrides the retry policy of a method, e.g. /orders.v1.Orders/Create.
+// A policy with MaxAttempts 1 disables retries for non-idempotent methods.
+func WithMethodRetry(method string, policy RetryPolicy) Option {
+	return func(o *options) {
+		if o.methodRetry == nil {
+			o.methodRetry = map[string]RetryPolicy{}
+		}
+		o.methodRetry[method] = policy
+	}
+}
+
+// retryUnaryInterceptor retries calls per the policy of their method, never waiting past
+// the deadline of the call
+func retryUnaryInterceptor(policy *RetryPolicy, methods map[string]RetryPolicy) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		p, ok := methods[method]
+		if !ok {
+			if policy == nil {
+				return invoker(ctx, method, req, reply, cc, opts...)
+			}
+			p = *policy
+		}
+
+		backoff := p.InitialBackoff
+		for attempt := 1; ; attempt++ {
+			err := invoker(ctx, method, req, reply, cc, opts...)
+			if err == nil || attempt >= p.MaxAttempts || !slices.Contains(p.Codes, status.Code(err)) {
+				return err
+			}
+
+			wait := p.jittered(backoff)
+			if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= wait {
+				return err
+			}
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+			backoff = p.next(backoff)
+		}
+	}
+}
+
+// jittered removes a random fraction of up to Jitter from the backoff
+func (p RetryPolicy) jittered(backoff time.Duration) time.Duration {
+	if p.Jitter <= 0 {
+		return backoff
+	}
+	return time.Duration(float64(backoff) * (1 - p.Jitter*rand.Float64()))
+}
+
+// next returns the backoff following backoff
+func (p RetryPolicy) next(backoff time.Duration) time.Duration {
+	if p.Multiplier > 0 {
+		backoff = time.Duration(float64(backoff) * p.Multiplier)
+	}
+	if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+	return backoff
+}
@@ -0,0 +1,115 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRetryUnaryInterceptor(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts:    3,
+		Codes:          []codes.Code{codes.Unavailable},
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+		Multiplier:     2,
+		Jitter:         0.5,
+	}
+
+	tests := []struct {
+		name         string
+		method       string
+		methods      map[string]RetryPolicy
+		timeout      time.Duration
+		failures     []codes.Code
+		wantAttempts int
+		wantCode     codes.Code
+	}{
+		{
+			name:         "retried until success",
+			failures:     []codes.Code{codes.Unavailable, codes.Unavailable},
+			wantAttempts: 3,
+			wantCode:     codes.OK,
+		},
+		{
+			name:         "gives up after max attempts",
+			failures:     []codes.Code{codes.Unavailable, codes.Unavailable, codes.Unavailable, codes.Unavailable},
+			wantAttempts: 3,
+			wantCode:     codes.Unavailable,
+		},
+		{
+			name:         "code not retried",
+			failures:     []codes.Code{codes.InvalidArgument},
+			wantAttempts: 1,
+			wantCode:     codes.InvalidArgument,
+		},
+		{
+			name:         "method override",
+			method:       "/svc/Create",
+			methods:      map[string]RetryPolicy{"/svc/Create": {MaxAttempts: 1}},
+			failures:     []codes.Code{codes.Unavailable},
+			wantAttempts: 1,
+			wantCode:     codes.Unavailable,
+		},
+		{
+			name:         "deadline shorter than backoff",
+			methods:      map[string]RetryPolicy{"/svc/Get": {MaxAttempts: 3, Codes: []codes.Code{codes.Unavailable}, InitialBackoff: time.Hour}},
+			timeout:      time.Second,
+			failures:     []codes.Code{codes.Unavailable},
+			wantAttempts: 1,
+			wantCode:     codes.Unavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			method := tt.method
+			if method == "" {
+				method = "/svc/Get"
+			}
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+			attempts := 0
+			invoker := func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+				attempts++
+				if attempts <= len(tt.failures) {
+					return status.Error(tt.failures[attempts-1], "failed")
+				}
+				return nil
+			}
+
+			// Act
+			err := retryUnaryInterceptor(&policy, tt.methods)(ctx, method, nil, nil, nil, invoker)
+
+			// Assert
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			assert.Equal(t, tt.wantAttempts, attempts)
+		})
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	// Arrange
+	p := RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 3 * time.Second, Multiplier: 2, Jitter: 0.5}
+
+	// Act
+	second := p.next(p.InitialBackoff)
+	third := p.next(second)
+	jittered := p.jittered(time.Second)
+
+	// Assert
+	assert.Equal(t, 2*time.Second, second)
+	assert.Equal(t, 3*time.Second, third)
+	assert.GreaterOrEqual(t, jittered, 500*time.Millisecond)
+	assert.LessOrEqual(t, jittered, time.Second)
+}
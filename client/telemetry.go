@@ -0,0 +1,194 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/legrch/netgex/config"
+	metricsreg "github.com/legrch/netgex/internal/metrics"
+)
+
+// tracingUnaryInterceptor starts a client span per call and injects its context into the
+// outgoing metadata
+func tracingUnaryInterceptor(cfg *config.Config) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, span := startSpan(ctx, cfg, method, cc.Target(), false)
+		defer span.End()
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		endSpan(span, err)
+		return err
+	}
+}
+
+// tracingStreamInterceptor starts a client span per stream, ended when the stream is
+func tracingStreamInterceptor(cfg *config.Config) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx, span := startSpan(ctx, cfg, method, cc.Target(), true)
+
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			endSpan(span, err)
+			span.End()
+			return nil, err
+		}
+		return &observedStream{ClientStream: cs, done: func(err error) {
+			endSpan(span, err)
+			span.End()
+		}}, nil
+	}
+}
+
+// startSpan starts the client span of a call
+func startSpan(ctx context.Context, cfg *config.Config, method, target string, stream bool) (context.Context, trace.Span) {
+	ctx, span := otel.Tracer("grpc.client").Start(ctx, method,
+		trace.WithAttributes(
+			attribute.String("rpc.service", cfg.ServiceName),
+			attribute.String("rpc.method", method),
+			attribute.String("server.address", target),
+			attribute.Bool("rpc.stream", stream),
+		),
+		trace.WithSpanKind(trace.SpanKindClient),
+	)
+
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	otel.GetTextMapPropagator().Inject(ctx, metadataCarrier(md))
+	return metadata.NewOutgoingContext(ctx, md), span
+}
+
+// endSpan sets the span status from the result of the call
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		st, _ := status.FromError(err)
+		span.SetStatus(otelcodes.Error, st.Message())
+		span.SetAttributes(attribute.String("error.code", st.Code().String()))
+		return
+	}
+	span.SetStatus(otelcodes.Ok, "")
+}
+
+// metadataCarrier adapts gRPC metadata to the OpenTelemetry propagators
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if values := metadata.MD(c).Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// metrics counts outbound calls and their duration
+type metrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+// clientMetrics returns the collectors of the namespace, registering them unless already
+// registered
+func clientMetrics(namespace string) *metrics {
+	m := &metrics{
+		requests: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "grpc_client_requests_total",
+				Help:      "Total number of outbound gRPC requests",
+			},
+			[]string{"target", "method", "status"},
+		),
+		duration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "grpc_client_request_duration_seconds",
+				Help:      "Duration of outbound gRPC requests in seconds",
+				Buckets:   []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 2, 5, 10},
+			},
+			[]string{"target", "method"},
+		),
+	}
+	m.requests = metricsreg.Register(m.requests)
+	m.duration = metricsreg.Register(m.duration)
+	return m
+}
+
+// observe records a finished call
+func (m *metrics) observe(target, method string, start time.Time, err error) {
+	statusCode := "success"
+	if err != nil {
+		statusCode = status.Code(err).String()
+	}
+	m.requests.WithLabelValues(target, method, statusCode).Inc()
+	m.duration.WithLabelValues(target, method).Observe(time.Since(start).Seconds())
+}
+
+// unaryInterceptor records unary calls
+func (m *metrics) unaryInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		m.observe(cc.Target(), method, start, err)
+		return err
+	}
+}
+
+// streamInterceptor records streams once they end
+func (m *metrics) streamInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			m.observe(cc.Target(), method, start, err)
+			return nil, err
+		}
+		return &observedStream{ClientStream: cs, done: func(err error) {
+			m.observe(cc.Target(), method, start, err)
+		}}, nil
+	}
+}
+
+// observedStream calls done once with the result of the stream: nil when it ended with
+// io.EOF, the error otherwise
+type observedStream struct {
+	grpc.ClientStream
+	once sync.Once
+	done func(error)
+}
+
+func (s *observedStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil {
+		s.finish(err)
+	}
+	return err
+}
+
+func (s *observedStream) finish(err error) {
+	if errors.Is(err, io.EOF) {
+		err = nil
+	}
+	s.once.Do(func() { s.done(err) })
+}
@@ -0,0 +1,78 @@
+// Command netgex is the netgex developer tool.
+//
+// Usage:
+//
+//	netgex new service <name> [-proto file] [-module path] [-dir dir]
+//	netgex healthprobe [-addr host:port] [-service name] [-timeout duration]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/legrch/netgex/healthprobe"
+	"github.com/legrch/netgex/internal/scaffold"
+)
+
+const usage = `Usage:
+  netgex new service <name> [-proto file] [-module path] [-dir dir]
+  netgex healthprobe [-addr host:port] [-service name] [-timeout duration]
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the command line and returns the exit code
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) >= 2 && args[0] == "new" && args[1] == "service" {
+		return newService(args[2:], stdout, stderr)
+	}
+	if len(args) >= 1 && args[0] == "healthprobe" {
+		return healthprobe.Main(args[1:], stderr)
+	}
+	fmt.Fprint(stderr, usage)
+	return 2
+}
+
+// newService generates a service skeleton
+func newService(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("netgex new service", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		flags.PrintDefaults()
+	}
+	var opts scaffold.Options
+	flags.StringVar(&opts.Proto, "proto", "", "proto file whose services get registrars")
+	flags.StringVar(&opts.Module, "module", "", "Go module path (default the service name)")
+	flags.StringVar(&opts.Dir, "dir", "", "output directory (default the service name)")
+
+	// Accept the name before or after the flags
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		opts.Name, args = args[0], args[1:]
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if opts.Name == "" && flags.NArg() == 1 {
+		opts.Name = flags.Arg(0)
+	} else if flags.NArg() > 0 || opts.Name == "" {
+		flags.Usage()
+		return 2
+	}
+
+	files, err := scaffold.Generate(opts)
+	if err != nil {
+		fmt.Fprintf(stderr, "netgex: %v\n", err)
+		return 1
+	}
+	for _, file := range files {
+		fmt.Fprintln(stdout, "created", file)
+	}
+	fmt.Fprintln(stdout, "\nNext: run go mod tidy in the service directory")
+	return 0
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		wantOut  string
+	}{
+		{name: "name first", args: []string{"new", "service", "orders", "-dir", filepath.Join(dir, "a")}, wantOut: "main.go"},
+		{name: "name last", args: []string{"new", "service", "-dir", filepath.Join(dir, "b"), "orders"}, wantOut: "main.go"},
+		{name: "missing name", args: []string{"new", "service"}, wantCode: 2},
+		{name: "unknown command", args: []string{"build"}, wantCode: 2},
+		{name: "healthprobe usage", args: []string{"healthprobe", "extra"}, wantCode: 2},
+		{name: "invalid name", args: []string{"new", "service", "Orders", "-dir", filepath.Join(dir, "c")}, wantCode: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var stdout, stderr bytes.Buffer
+
+			// Act
+			code := run(tt.args, &stdout, &stderr)
+
+			// Assert
+			assert.Equal(t, tt.wantCode, code, stderr.String())
+			assert.Contains(t, stdout.String(), tt.wantOut)
+		})
+	}
+}
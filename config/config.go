@@ -1,6 +1,7 @@
 package config
 
 import (
+	"math"
 	"time"
 
 	"github.com/kelseyhightower/envconfig"
@@ -9,8 +10,14 @@ import (
 // Config represents the comprehensive configuration for the server.Server
 type Config struct {
 	// Core settings
-	LogLevel     string        `envconfig:"LOG_LEVEL" default:"info"`
-	CloseTimeout time.Duration `envconfig:"CLOSE_TIMEOUT" default:"10s"`
+	LogLevel      string        `envconfig:"LOG_LEVEL" default:"info"`
+	CloseTimeout  time.Duration `envconfig:"CLOSE_TIMEOUT" default:"10s"`
+	ShutdownDelay time.Duration `envconfig:"SHUTDOWN_DELAY" default:"0s"` // Time to keep serving after the context is canceled
+	DrainDelay    time.Duration `envconfig:"DRAIN_DELAY" default:"0s"`    // Time between reporting NOT_SERVING and closing listeners
+
+	// Startup budget
+	StartupTimeout  time.Duration `envconfig:"STARTUP_TIMEOUT" default:"0s"`      // Time to become ready before the startup phases are reported
+	StartupFailFast bool          `envconfig:"STARTUP_FAIL_FAST" default:"false"` // Fail when not ready within STARTUP_TIMEOUT
 
 	// Server addresses
 	GRPCAddress    string `envconfig:"GRPC_ADDRESS" default:":9090"`
@@ -19,14 +26,146 @@ type Config struct {
 	PprofEnabled   bool   `envconfig:"PPROF_ENABLED" default:"true"`
 	PprofAddress   string `envconfig:"PPROF_ADDRESS" default:":6060"`
 
+	// Gateway and metrics servers; disabled servers create no listener
+	HTTPEnabled          bool `envconfig:"HTTP_ENABLED" default:"true"`
+	MetricsServerEnabled bool `envconfig:"METRICS_SERVER_ENABLED" default:"true"`
+
+	// Gateway health endpoints; empty disables them
+	HTTPHealthPaths []string `envconfig:"HTTP_HEALTH_PATH" default:"/health"` // Format: "/health,/healthz"
+
+	// Gateway build metadata endpoint; empty disables it
+	HTTPVersionPath string `envconfig:"HTTP_VERSION_PATH" default:"/version"`
+
+	// Ignore unknown fields of gateway request bodies instead of answering 400
+	JSONDiscardUnknown bool `envconfig:"JSON_DISCARD_UNKNOWN" default:"false"`
+
+	// Gateway request parsing: how path parameters are unescaped ("legacy",
+	// "all_except_reserved", "all_except_slash", "all_characters"), and whether
+	// form-encoded POST requests are served as GET routes with the form as query parameters
+	GatewayUnescapingMode     string `envconfig:"GATEWAY_UNESCAPING_MODE" default:"legacy"`
+	GatewayPathLengthFallback bool   `envconfig:"GATEWAY_PATH_LENGTH_FALLBACK" default:"true"`
+
+	// Partial responses: GET responses pruned to the fields listed in a query parameter
+	GatewayPartialResponseEnabled bool   `envconfig:"GATEWAY_PARTIAL_RESPONSE_ENABLED" default:"false"`
+	GatewayPartialResponseParam   string `envconfig:"GATEWAY_PARTIAL_RESPONSE_PARAM" default:"fields"`
+
+	// Framing of server-streaming responses ("default", "ndjson", "json-array",
+	// "length-prefixed") and the least time between flushes, 0 flushing every message
+	GatewayStreamFraming       string        `envconfig:"GATEWAY_STREAM_FRAMING" default:"default"`
+	GatewayStreamFlushInterval time.Duration `envconfig:"GATEWAY_STREAM_FLUSH_INTERVAL" default:"0s"`
+
+	// Response metadata forwarded as headers: as Grpc-Metadata-<key> ("prefixed"), as
+	// <key> ("stripped") or not at all ("none"), optionally only the listed keys
+	GatewayOutgoingHeaders    string   `envconfig:"GATEWAY_OUTGOING_HEADERS" default:"prefixed"`
+	GatewayOutgoingHeaderKeys []string `envconfig:"GATEWAY_OUTGOING_HEADER_KEYS" default:""` // Format: "x-request-id,x-ratelimit-*"
+
+	// Request headers holding the deadline of the gateway's gRPC call, as a Go duration or
+	// seconds, e.g. "X-Request-Timeout"; Grpc-Timeout headers are always honored
+	GatewayTimeoutHeaders []string `envconfig:"GATEWAY_TIMEOUT_HEADERS" default:""`
+
+	// GraphQL endpoint generated from the gRPC services, served on the gateway port
+	GraphQLEnabled bool   `envconfig:"GRAPHQL_ENABLED" default:"false"`
+	GraphQLPath    string `envconfig:"GRAPHQL_PATH" default:"/graphql"`
+
+	// Limits of GraphQL operations: how deep fields nest and how many fields are
+	// selected, fragments counted each time they are spread; 0 disables a limit
+	GraphQLMaxDepth      int `envconfig:"GRAPHQL_MAX_DEPTH" default:"20"`
+	GraphQLMaxComplexity int `envconfig:"GRAPHQL_MAX_COMPLEXITY" default:"2000"`
+
+	// Twirp-style endpoints of the gRPC services, served on the gateway port under the
+	// prefix, and JSON-RPC 2.0 on its path; an empty path disables JSON-RPC
+	TwirpEnabled     bool   `envconfig:"TWIRP_ENABLED" default:"false"`
+	TwirpPrefix      string `envconfig:"TWIRP_PREFIX" default:"/twirp"`
+	TwirpJSONRPCPath string `envconfig:"TWIRP_JSONRPC_PATH" default:""`
+
+	// Pprof access restrictions
+	PprofAuthToken         string   `envconfig:"PPROF_AUTH_TOKEN" default:"" secret:"true"`
+	PprofBasicAuthUser     string   `envconfig:"PPROF_BASIC_AUTH_USER" default:""`
+	PprofBasicAuthPassword string   `envconfig:"PPROF_BASIC_AUTH_PASSWORD" default:"" secret:"true"`
+	PprofAllowedCIDRs      []string `envconfig:"PPROF_ALLOWED_CIDRS" default:""` // Format: "10.0.0.0/8,127.0.0.1"
+
+	// Pprof optional endpoints
+	PprofFgprofEnabled bool `envconfig:"PPROF_FGPROF_ENABLED" default:"false"`
+
+	// Contention profiling rates (0 leaves the runtime default)
+	PprofBlockRate     int `envconfig:"PPROF_BLOCK_RATE" default:"0"`
+	PprofMutexFraction int `envconfig:"PPROF_MUTEX_FRACTION" default:"0"`
+
+	// Heap dump endpoint (disabled when the directory is empty)
+	PprofHeapDumpDir      string `envconfig:"PPROF_HEAP_DUMP_DIR" default:""`
+	PprofHeapDumpMaxBytes int64  `envconfig:"PPROF_HEAP_DUMP_MAX_BYTES" default:"1073741824"`
+
+	// Single-port mode for platforms routing one PORT (Cloud Run, Heroku)
+	SinglePortEnabled     bool   `envconfig:"SINGLE_PORT_ENABLED" default:"false"`
+	SinglePortGRPCEnabled bool   `envconfig:"SINGLE_PORT_GRPC_ENABLED" default:"true"`
+	Port                  string `envconfig:"PORT" default:"8080"`
+
+	// Serverless mode serving the gateway from AWS Lambda events
+	LambdaEnabled bool `envconfig:"LAMBDA_ENABLED" default:"false"`
+
+	// Runtime tuning (empty leaves the runtime default, "off" disables)
+	MemoryLimit string `envconfig:"GOMEMLIMIT" default:""` // Format: "512MiB", "1GiB" or bytes
+	GCPercent   string `envconfig:"GOGC" default:""`
+
 	// Feature flags
 	ReflectionEnabled  bool `envconfig:"REFLECTION_ENABLED" default:"true"`
 	HealthCheckEnabled bool `envconfig:"HEALTH_CHECK_ENABLED" default:"true"`
+	MeshHeadersEnabled bool `envconfig:"MESH_HEADERS_ENABLED" default:"true"`
+	ReusePortEnabled   bool `envconfig:"REUSE_PORT_ENABLED" default:"false"` // Bind listeners with SO_REUSEPORT
+
+	// Interval at which registrar health checks update the gRPC health status (0 disables)
+	HealthCheckInterval time.Duration `envconfig:"HEALTH_CHECK_INTERVAL" default:"10s"`
+
+	// gRPC methods the built-in tracing, metrics and access log interceptors apply to, as
+	// path.Match patterns: the methods matching an include pattern, or all without any,
+	// and no exclude pattern, e.g. "/grpc.health.v1.Health/*,/grpc.reflection.*/*"
+	InterceptorIncludeMethods []string `envconfig:"INTERCEPTOR_INCLUDE_METHODS" default:""`
+	InterceptorExcludeMethods []string `envconfig:"INTERCEPTOR_EXCLUDE_METHODS" default:""`
+
+	// Paths of operational endpoints left out of gateway access logs and in-flight request
+	// metrics; paths ending with "*" match every path starting with the rest
+	ObservabilityExcludePaths []string `envconfig:"OBSERVABILITY_EXCLUDE_PATHS" default:"/health,/healthz,/readyz,/livez,/version,/metrics,/internal/*,/debug/*,/swagger/*"`
+
+	// Socket options of every listener: the TCP keepalive period of accepted connections
+	// (0 uses Go's 15s, negative disables it), TCP_NODELAY, and the listen backlog (0
+	// uses the system's default)
+	TCPKeepAlive  time.Duration `envconfig:"TCP_KEEPALIVE" default:"0s"`
+	TCPNoDelay    bool          `envconfig:"TCP_NODELAY" default:"true"`
+	ListenBacklog int           `envconfig:"LISTEN_BACKLOG" default:"0"`
+
+	// Deprecated gRPC methods, comma-separated, each optionally followed by its sunset
+	// date, e.g. "/orders.v1.Orders/Get@2026-06-30"
+	DeprecatedMethods string `envconfig:"DEPRECATED_METHODS" default:""`
+
+	// Internal admin gRPC server (health, channelz, reflection, service info)
+	AdminEnabled bool   `envconfig:"ADMIN_ENABLED" default:"false"`
+	AdminAddress string `envconfig:"ADMIN_ADDRESS" default:"127.0.0.1:9095"`
+
+	// Admin HTTP address serving metrics, pprof and health instead of their own listeners
+	AdminHTTPAddress string `envconfig:"ADMIN_HTTP_ADDRESS" default:""`
+
+	// gRPC message size limits in bytes, applied to the server and the gateway's client
+	GRPCMaxRecvMsgSize int `envconfig:"GRPC_MAX_RECV_MSG_SIZE" default:"4194304"`
+	GRPCMaxSendMsgSize int `envconfig:"GRPC_MAX_SEND_MSG_SIZE" default:"2147483647"`
+
+	// Reflection protocol versions to register: "v1", "v1alpha" or "both"
+	ReflectionVersions string `envconfig:"REFLECTION_VERSIONS" default:"both"`
 
 	// Swagger configuration
 	SwaggerEnabled  bool   `envconfig:"SWAGGER_ENABLED" default:"true"`
 	SwaggerDir      string `envconfig:"SWAGGER_DIR" default:"./api"`
 	SwaggerBasePath string `envconfig:"SWAGGER_BASE_PATH" default:"/"`
+	SwaggerUICDN    string `envconfig:"SWAGGER_UI_CDN" default:""` // Base URL for Swagger UI assets; empty serves the embedded assets
+
+	// Swagger access restrictions; Swagger is not served in the "production" environment unless enabled
+	SwaggerAuthToken         string `envconfig:"SWAGGER_AUTH_TOKEN" default:"" secret:"true"`
+	SwaggerBasicAuthUser     string `envconfig:"SWAGGER_BASIC_AUTH_USER" default:""`
+	SwaggerBasicAuthPassword string `envconfig:"SWAGGER_BASIC_AUTH_PASSWORD" default:"" secret:"true"`
+	SwaggerProductionEnabled bool   `envconfig:"SWAGGER_PRODUCTION_ENABLED" default:"false"`
+
+	// Splash screen printed once the server has started
+	SplashEnabled bool   `envconfig:"SPLASH_ENABLED" default:"true"`
+	SplashFormat  string `envconfig:"SPLASH_FORMAT" default:"banner"` // "banner" or "log" for a single structured log record
 
 	// Service information for telemetry
 	ServiceName    string `envconfig:"SERVICE_NAME" default:"netgex"`
@@ -35,6 +174,18 @@ type Config struct {
 
 	// Telemetry configuration
 	Telemetry TelemetryConfig
+
+	// Watchdog configuration
+	Watchdog WatchdogConfig
+
+	// Service registry configuration
+	Registry RegistryConfig
+
+	// Access log configuration
+	AccessLog AccessLogConfig
+
+	// gRPC keepalive configuration
+	GRPCKeepalive GRPCKeepaliveConfig
 }
 
 // TelemetryConfig holds all observability configuration settings
@@ -49,6 +200,10 @@ type TelemetryConfig struct {
 	Profiling ProfilingConfig
 	// OpenTelemetry configuration (unified approach)
 	OTEL OTELConfig
+
+	// Time given to flushing the last spans, metrics and profiles on shutdown, on top of
+	// CLOSE_TIMEOUT
+	FlushTimeout time.Duration `envconfig:"TELEMETRY_FLUSH_TIMEOUT" default:"5s"`
 }
 
 // TracingConfig configures distributed tracing
@@ -58,6 +213,7 @@ type TracingConfig struct {
 	Endpoint     string        `envconfig:"TRACING_ENDPOINT" default:"localhost:4318"`
 	Insecure     bool          `envconfig:"TRACING_INSECURE" default:"true"`
 	SampleRate   float64       `envconfig:"TRACING_SAMPLE_RATE" default:"1.0"`
+	Sampler      string        `envconfig:"TRACING_SAMPLER" default:"ratio"` // "ratio" or "errorbiased", for OTEL tracing too
 	BatchSize    int           `envconfig:"TRACING_BATCH_SIZE" default:"100"`
 	BatchTimeout time.Duration `envconfig:"TRACING_BATCH_TIMEOUT" default:"5s"`
 }
@@ -71,6 +227,16 @@ type MetricsConfig struct {
 	Path      string `envconfig:"METRICS_PATH" default:"/metrics"`
 	Port      int    `envconfig:"METRICS_PORT" default:"9091"`
 	Namespace string `envconfig:"METRICS_NAMESPACE" default:"netgex"`
+
+	// gRPC methods left out of request metrics, and methods recorded under the pattern
+	// they match instead of their own name, as path.Match patterns,
+	// e.g. "/grpc.health.v1.Health/*,/grpc.reflection.*/*"
+	ExcludeMethods  []string `envconfig:"METRICS_EXCLUDE_METHODS" default:""`
+	CollapseMethods []string `envconfig:"METRICS_COLLAPSE_METHODS" default:""`
+
+	// Constant labels added to every served metric, including application and
+	// third-party ones, comma-separated key=value pairs, e.g. "team=payments,region=eu-west-1"
+	Labels []string `envconfig:"METRICS_LABELS" default:""`
 }
 
 // LoggingConfig configures structured logging
@@ -97,8 +263,8 @@ type OTELConfig struct {
 	Enabled  bool   `envconfig:"OTEL_ENABLED" default:"false"`
 	Endpoint string `envconfig:"OTEL_ENDPOINT" default:"localhost:4318"`
 	Insecure bool   `envconfig:"OTEL_INSECURE" default:"true"`
-	Headers  string `envconfig:"OTEL_HEADERS" default:""`      // Format: "key1=value1,key2=value2"
-	Protocol string `envconfig:"OTEL_PROTOCOL" default:"http"` // "http" or "grpc"
+	Headers  string `envconfig:"OTEL_HEADERS" default:"" secret:"true"` // Format: "key1=value1,key2=value2"
+	Protocol string `envconfig:"OTEL_PROTOCOL" default:"http"`          // "http" or "grpc"
 
 	// Signal-specific configuration
 	TracesEnabled  bool          `envconfig:"OTEL_TRACES_ENABLED" default:"true"`
@@ -107,26 +273,114 @@ type OTELConfig struct {
 	SampleRate     float64       `envconfig:"OTEL_SAMPLE_RATE" default:"1.0"`
 	BatchSize      int           `envconfig:"OTEL_BATCH_SIZE" default:"100"`
 	BatchTimeout   time.Duration `envconfig:"OTEL_BATCH_TIMEOUT" default:"5s"`
+
+	// Export the Prometheus registry over OTLP as well when both Prometheus and OTEL
+	// metrics are enabled, so collectors registered with Prometheus reach the collector
+	MetricsPrometheusBridge bool `envconfig:"OTEL_METRICS_PROMETHEUS_BRIDGE" default:"true"`
+}
+
+// WatchdogConfig configures the goroutine leak and scheduler stall watchdog
+type WatchdogConfig struct {
+	Enabled            bool          `envconfig:"WATCHDOG_ENABLED" default:"false"`
+	Interval           time.Duration `envconfig:"WATCHDOG_INTERVAL" default:"10s"`
+	GoroutineThreshold int           `envconfig:"WATCHDOG_GOROUTINE_THRESHOLD" default:"10000"`
+	LatencyThreshold   time.Duration `envconfig:"WATCHDOG_LATENCY_THRESHOLD" default:"100ms"`
+	DumpStacks         bool          `envconfig:"WATCHDOG_DUMP_STACKS" default:"false"`
+}
+
+// RegistryConfig configures registration with a Consul or etcd service registry
+type RegistryConfig struct {
+	Enabled       bool          `envconfig:"REGISTRY_ENABLED" default:"false"`
+	Backend       string        `envconfig:"REGISTRY_BACKEND" default:"consul"` // "consul" or "etcd"
+	Address       string        `envconfig:"REGISTRY_ADDRESS" default:"http://127.0.0.1:8500"`
+	Token         string        `envconfig:"REGISTRY_TOKEN" default:"" secret:"true"`
+	Prefix        string        `envconfig:"REGISTRY_PREFIX" default:"/services/"` // etcd only
+	ServiceID     string        `envconfig:"REGISTRY_SERVICE_ID" default:""`
+	AdvertiseHost string        `envconfig:"REGISTRY_ADVERTISE_HOST" default:""`
+	Tags          []string      `envconfig:"REGISTRY_TAGS" default:""`
+	TTL           time.Duration `envconfig:"REGISTRY_TTL" default:"15s"`
+}
+
+// AccessLogConfig configures where and how the default middleware writes access logs
+type AccessLogConfig struct {
+	Sink        string   `envconfig:"ACCESS_LOG_SINK" default:"slog"`                              // "slog", "stdout", "file" or "otlp"
+	Format      string   `envconfig:"ACCESS_LOG_FORMAT" default:"json"`                            // "json" or "combined", for the stdout and file sinks
+	FilePath    string   `envconfig:"ACCESS_LOG_FILE_PATH" default:""`                             // file sink only
+	Endpoint    string   `envconfig:"ACCESS_LOG_ENDPOINT" default:"http://localhost:4318/v1/logs"` // OTLP/HTTP logs endpoint of the otlp sink
+	SampleRates []string `envconfig:"ACCESS_LOG_SAMPLE_RATES" default:""`                          // Comma-separated pattern=rate, e.g. "/orders.v1.Orders/List=0.1"
+	HTTPEnabled bool     `envconfig:"ACCESS_LOG_HTTP_ENABLED" default:"false"`                     // Also log gateway HTTP requests
+}
+
+// GRPCKeepaliveConfig configures gRPC server keepalive parameters and the enforcement
+// policy for client pings. The defaults match the gRPC defaults; 0 means infinity for
+// the connection limits.
+type GRPCKeepaliveConfig struct {
+	MaxConnectionIdle     time.Duration `envconfig:"GRPC_KEEPALIVE_MAX_CONNECTION_IDLE" default:"0s"`
+	MaxConnectionAge      time.Duration `envconfig:"GRPC_KEEPALIVE_MAX_CONNECTION_AGE" default:"0s"`
+	MaxConnectionAgeGrace time.Duration `envconfig:"GRPC_KEEPALIVE_MAX_CONNECTION_AGE_GRACE" default:"0s"`
+	Time                  time.Duration `envconfig:"GRPC_KEEPALIVE_TIME" default:"2h"`
+	Timeout               time.Duration `envconfig:"GRPC_KEEPALIVE_TIMEOUT" default:"20s"`
+	MinTime               time.Duration `envconfig:"GRPC_KEEPALIVE_MIN_TIME" default:"5m"`
+	PermitWithoutStream   bool          `envconfig:"GRPC_KEEPALIVE_PERMIT_WITHOUT_STREAM" default:"false"`
 }
 
 // NewConfig creates a new Config with default values
 func NewConfig() *Config {
 	return &Config{
-		LogLevel:           "info",
-		CloseTimeout:       10 * time.Second,
-		GRPCAddress:        ":9090",
-		HTTPAddress:        ":8080",
-		MetricsAddress:     ":9091",
-		PprofEnabled:       true,
-		PprofAddress:       ":6060",
-		ReflectionEnabled:  true,
-		HealthCheckEnabled: true,
-		SwaggerEnabled:     true,
-		SwaggerDir:         "./api",
-		SwaggerBasePath:    "/",
-		ServiceName:        "netgex",
-		ServiceVersion:     "0.0.0",
-		Environment:        "development",
+		LogLevel:            "info",
+		CloseTimeout:        10 * time.Second,
+		GRPCAddress:         ":9090",
+		HTTPAddress:         ":8080",
+		MetricsAddress:      ":9091",
+		PprofEnabled:        true,
+		PprofAddress:        ":6060",
+		ReflectionEnabled:   true,
+		HealthCheckEnabled:  true,
+		HealthCheckInterval: 10 * time.Second,
+		TCPNoDelay:          true,
+		MeshHeadersEnabled:  true,
+		ReflectionVersions:  "both",
+		SwaggerEnabled:      true,
+		SwaggerDir:          "./api",
+		SwaggerBasePath:     "/",
+		SplashEnabled:       true,
+		SplashFormat:        "banner",
+		ServiceName:         "netgex",
+		ServiceVersion:      "0.0.0",
+		Environment:         "development",
+
+		HTTPEnabled:          true,
+		MetricsServerEnabled: true,
+		HTTPHealthPaths:      []string{"/health"},
+		HTTPVersionPath:      "/version",
+		ObservabilityExcludePaths: []string{
+			"/health", "/healthz", "/readyz", "/livez", "/version", "/metrics", "/internal/*", "/debug/*", "/swagger/*",
+		},
+
+		GatewayUnescapingMode:     "legacy",
+		GatewayPathLengthFallback: true,
+
+		GatewayPartialResponseParam: "fields",
+
+		GatewayStreamFraming: "default",
+
+		GatewayOutgoingHeaders: "prefixed",
+
+		GraphQLPath:          "/graphql",
+		GraphQLMaxDepth:      20,
+		GraphQLMaxComplexity: 2000,
+		TwirpPrefix:          "/twirp",
+
+		PprofHeapDumpMaxBytes: 1 << 30,
+
+		SinglePortGRPCEnabled: true,
+		Port:                  "8080",
+
+		AdminAddress: "127.0.0.1:9095",
+
+		GRPCMaxRecvMsgSize: 4 << 20,
+		GRPCMaxSendMsgSize: math.MaxInt32,
+
 		Telemetry: TelemetryConfig{
 			Tracing: TracingConfig{
 				Enabled:      false,
@@ -134,6 +388,7 @@ func NewConfig() *Config {
 				Endpoint:     "localhost:4318",
 				Insecure:     true,
 				SampleRate:   1.0,
+				Sampler:      "ratio",
 				BatchSize:    100,
 				BatchTimeout: 5 * time.Second,
 			},
@@ -172,7 +427,34 @@ func NewConfig() *Config {
 				SampleRate:     1.0,
 				BatchSize:      100,
 				BatchTimeout:   5 * time.Second,
+
+				MetricsPrometheusBridge: true,
 			},
+			FlushTimeout: 5 * time.Second,
+		},
+		Watchdog: WatchdogConfig{
+			Enabled:            false,
+			Interval:           10 * time.Second,
+			GoroutineThreshold: 10000,
+			LatencyThreshold:   100 * time.Millisecond,
+			DumpStacks:         false,
+		},
+		Registry: RegistryConfig{
+			Enabled: false,
+			Backend: "consul",
+			Address: "http://127.0.0.1:8500",
+			Prefix:  "/services/",
+			TTL:     15 * time.Second,
+		},
+		AccessLog: AccessLogConfig{
+			Sink:     "slog",
+			Format:   "json",
+			Endpoint: "http://localhost:4318/v1/logs",
+		},
+		GRPCKeepalive: GRPCKeepaliveConfig{
+			Time:    2 * time.Hour,
+			Timeout: 20 * time.Second,
+			MinTime: 5 * time.Minute,
 		},
 	}
 }
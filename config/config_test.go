@@ -20,11 +20,15 @@ func TestNewConfig(t *testing.T) {
 	assert.Equal(t, ":8080", cfg.HTTPAddress, "default HTTP address should be ':8080'")
 	assert.Equal(t, ":9091", cfg.MetricsAddress, "default metrics address should be ':9091'")
 	assert.Equal(t, ":6060", cfg.PprofAddress, "default pprof address should be ':6060'")
+	assert.Equal(t, []string{"/health"}, cfg.HTTPHealthPaths, "default gateway health path should be '/health'")
+	assert.Equal(t, "slog", cfg.AccessLog.Sink, "access logs should go to the application log by default")
 	assert.True(t, cfg.ReflectionEnabled, "reflection should be enabled by default")
 	assert.True(t, cfg.HealthCheckEnabled, "health check should be enabled by default")
+	assert.True(t, cfg.MeshHeadersEnabled, "mesh header propagation should be enabled by default")
 	assert.True(t, cfg.SwaggerEnabled, "swagger should be enabled by default")
 	assert.Equal(t, "./api", cfg.SwaggerDir, "default swagger dir should be './api'")
 	assert.Equal(t, "/", cfg.SwaggerBasePath, "default swagger base path should be '/'")
+	assert.Equal(t, int64(1<<30), cfg.PprofHeapDumpMaxBytes, "default heap dump limit should be 1GiB")
 }
 
 func TestLoadFromEnv(t *testing.T) {
@@ -91,3 +95,35 @@ func TestLoadFromEnv_InvalidFormat(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "CLOSE_TIMEOUT")
 }
+
+func TestConfig_Values(t *testing.T) {
+	// Arrange
+	cfg := NewConfig()
+	cfg.PprofAllowedCIDRs = []string{"10.0.0.0/8", "127.0.0.1"}
+	cfg.PprofAuthToken = "s3cret"
+	cfg.Registry.Token = ""
+
+	// Act
+	values := cfg.Values()
+
+	// Assert
+	assert.Equal(t, ":9090", values["GRPC_ADDRESS"])
+	assert.Equal(t, "10s", values["CLOSE_TIMEOUT"])
+	assert.Equal(t, "true", values["REFLECTION_ENABLED"])
+	assert.Equal(t, "10.0.0.0/8,127.0.0.1", values["PPROF_ALLOWED_CIDRS"])
+	assert.Equal(t, "2h0m0s", values["GRPC_KEEPALIVE_TIME"], "nested structs should be included")
+	assert.Equal(t, Redacted, values["PPROF_AUTH_TOKEN"], "secrets should be redacted")
+	assert.Empty(t, values["REGISTRY_TOKEN"], "unset secrets should stay empty")
+}
+
+func TestVariables(t *testing.T) {
+	// Act
+	vars := Variables()
+
+	// Assert
+	require.NotEmpty(t, vars)
+	assert.Equal(t, Variable{Name: "LOG_LEVEL", Default: "info"}, vars[0], "variables keep declaration order")
+	assert.Contains(t, vars, Variable{Name: "GRPC_KEEPALIVE_TIME", Default: "2h"}, "nested structs should be included")
+	assert.Contains(t, vars, Variable{Name: "PPROF_AUTH_TOKEN", Default: "", Secret: true})
+	assert.Len(t, vars, len(NewConfig().Values()))
+}
@@ -0,0 +1,229 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadOption is a function that configures how Load builds a Config
+type LoadOption func(*loader)
+
+// loader holds the sources Load layers on top of the defaults
+type loader struct {
+	envPrefix string
+	filePath  string
+	lookupEnv func(string) (string, bool)
+	resolvers map[string]SecretResolver
+	remote    RemoteSource
+}
+
+// remoteTimeout bounds reading the remote source at load, so an unreachable store fails
+// startup instead of hanging it; watches keep their own long-poll requests
+const remoteTimeout = 10 * time.Second
+
+// WithEnvPrefix namespaces environment variables, so GRPC_ADDRESS is read from <PREFIX>_GRPC_ADDRESS.
+// Unprefixed variables are ignored when a prefix is set.
+func WithEnvPrefix(prefix string) LoadOption {
+	return func(l *loader) {
+		l.envPrefix = prefix
+	}
+}
+
+// WithFile reads configuration from a YAML or JSON file whose keys are the
+// environment variable names without prefix (e.g. GRPC_ADDRESS: ":9090")
+func WithFile(path string) LoadOption {
+	return func(l *loader) {
+		l.filePath = path
+	}
+}
+
+// Load builds a Config by layering, from lowest to highest precedence:
+//
+//  1. defaults from NewConfig
+//  2. values from the configuration file, if any
+//  3. values from the remote source, if any
+//  4. environment variables
+//
+// Secret references in the result are then resolved using the registered resolvers.
+// Functional options passed to server.NewServer are applied on top of the result.
+func Load(opts ...LoadOption) (*Config, error) {
+	l := &loader{
+		lookupEnv: os.LookupEnv,
+	}
+
+	// Apply options
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	cfg := NewConfig()
+
+	if l.filePath != "" {
+		values, err := readFile(l.filePath)
+		if err != nil {
+			return nil, err
+		}
+
+		lookup := func(key string) (string, bool) {
+			v, ok := values[key]
+			return v, ok
+		}
+		if err := apply(cfg, lookup, func(key string) { delete(values, key) }); err != nil {
+			return nil, fmt.Errorf("config file %s: %w", l.filePath, err)
+		}
+
+		if len(values) > 0 {
+			unknown := make([]string, 0, len(values))
+			for key := range values {
+				unknown = append(unknown, key)
+			}
+			sort.Strings(unknown)
+			return nil, fmt.Errorf("config file %s: unknown keys: %s", l.filePath, strings.Join(unknown, ", "))
+		}
+	}
+
+	if l.remote != nil {
+		// The agent may be unreachable; fail instead of hanging the server constructor
+		ctx, cancel := context.WithTimeout(context.Background(), remoteTimeout)
+		values, err := l.remote.Values(ctx)
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("remote config: %w", err)
+		}
+
+		lookup := func(key string) (string, bool) {
+			v, ok := values[key]
+			return v, ok
+		}
+		if err := apply(cfg, lookup, nil); err != nil {
+			return nil, fmt.Errorf("remote config: %w", err)
+		}
+	}
+
+	lookup := func(key string) (string, bool) {
+		if l.envPrefix != "" {
+			key = strings.ToUpper(l.envPrefix) + "_" + key
+		}
+		return l.lookupEnv(key)
+	}
+	if err := apply(cfg, lookup, nil); err != nil {
+		return nil, fmt.Errorf("environment: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), SecretsTimeout)
+	defer cancel()
+	if err := cfg.ResolveSecrets(ctx, l.resolvers); err != nil {
+		return nil, fmt.Errorf("secrets: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// readFile reads a flat map of configuration keys to values from a YAML or JSON file
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	values := map[string]string{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	return values, nil
+}
+
+// apply walks the struct fields carrying an envconfig tag and sets those found by lookup.
+// The optional used callback is invoked with every key that was consumed.
+func apply(target any, lookup func(string) (string, bool), used func(string)) error {
+	v := reflect.ValueOf(target).Elem()
+	t := v.Type()
+
+	for i := range t.NumField() {
+		field := v.Field(i)
+		structField := t.Field(i)
+
+		key := structField.Tag.Get("envconfig")
+		if key == "" {
+			if field.Kind() == reflect.Struct {
+				if err := apply(field.Addr().Interface(), lookup, used); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		value, ok := lookup(key)
+		if !ok {
+			continue
+		}
+		if used != nil {
+			used(key)
+		}
+
+		if err := setField(field, value); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// setField parses value into the field according to its type
+func setField(field reflect.Value, value string) error {
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported slice type %s", field.Type())
+		}
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+
+	return nil
+}
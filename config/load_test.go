@@ -0,0 +1,116 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	// Arrange
+	path := writeConfigFile(t, "config.yaml", `
+GRPC_ADDRESS: ":7000"
+HTTP_ADDRESS: ":7001"
+CLOSE_TIMEOUT: 3s
+REFLECTION_ENABLED: false
+TRACING_SAMPLE_RATE: 0.5
+PPROF_ALLOWED_CIDRS: 10.0.0.0/8, 127.0.0.1
+`)
+	t.Setenv("HTTP_ADDRESS", ":7002")
+
+	// Act
+	cfg, err := Load(WithFile(path))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, ":7000", cfg.GRPCAddress, "file overrides defaults")
+	assert.Equal(t, ":7002", cfg.HTTPAddress, "environment overrides file")
+	assert.Equal(t, ":9091", cfg.MetricsAddress, "defaults are kept")
+	assert.Equal(t, 3*time.Second, cfg.CloseTimeout)
+	assert.False(t, cfg.ReflectionEnabled)
+	assert.InDelta(t, 0.5, cfg.Telemetry.Tracing.SampleRate, 0)
+	assert.Equal(t, []string{"10.0.0.0/8", "127.0.0.1"}, cfg.PprofAllowedCIDRs)
+}
+
+func TestLoad_JSONFile(t *testing.T) {
+	// Arrange
+	path := writeConfigFile(t, "config.json", `{"GRPC_ADDRESS": ":7000", "OTEL_ENABLED": "true"}`)
+
+	// Act
+	cfg, err := Load(WithFile(path))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, ":7000", cfg.GRPCAddress)
+	assert.True(t, cfg.Telemetry.OTEL.Enabled)
+}
+
+func TestLoad_EnvPrefix(t *testing.T) {
+	// Arrange
+	t.Setenv("GRPC_ADDRESS", ":7000")
+	t.Setenv("MYAPP_HTTP_ADDRESS", ":7001")
+
+	// Act
+	cfg, err := Load(WithEnvPrefix("myapp"))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, ":9090", cfg.GRPCAddress, "unprefixed variables are ignored")
+	assert.Equal(t, ":7001", cfg.HTTPAddress)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		env     map[string]string
+		wantErr string
+	}{
+		{
+			name:    "unknown file key",
+			content: "GRPC_ADDRES: \":7000\"\n",
+			wantErr: "unknown keys: GRPC_ADDRES",
+		},
+		{
+			name:    "invalid file value",
+			content: "CLOSE_TIMEOUT: soon\n",
+			wantErr: "CLOSE_TIMEOUT",
+		},
+		{
+			name:    "invalid environment value",
+			env:     map[string]string{"REFLECTION_ENABLED": "maybe"},
+			wantErr: "REFLECTION_ENABLED",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var opts []LoadOption
+			if tt.content != "" {
+				opts = append(opts, WithFile(writeConfigFile(t, "config.yaml", tt.content)))
+			}
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			// Act
+			_, err := Load(opts...)
+
+			// Assert
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,280 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RemoteSource reads configuration values from a remote key-value store. Keys are the
+// environment variable names without prefix, as in configuration files.
+type RemoteSource interface {
+	// Values returns the current configuration values
+	Values(ctx context.Context) (map[string]string, error)
+	// Watch blocks until the context is canceled, calling onChange with the full set of
+	// values whenever they change
+	Watch(ctx context.Context, onChange func(map[string]string)) error
+}
+
+// retryInterval is how long the Consul watch waits after a failed request, so an
+// unavailable agent isn't hammered
+const retryInterval = 5 * time.Second
+
+// blockingWait is how long a Consul blocking query waits for a change. The agent adds
+// up to a sixteenth of it as jitter, which blockingTimeout leaves room for.
+const (
+	blockingWait    = 5 * time.Minute
+	blockingTimeout = blockingWait + blockingWait/16 + 10*time.Second
+)
+
+// wait waits for d, reporting false when the context is canceled first
+func wait(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
+// WithRemoteSource layers values from a remote source between the config file and the environment.
+// Keys unknown to Config are ignored so the store can hold application-specific settings.
+func WithRemoteSource(source RemoteSource) LoadOption {
+	return func(l *loader) {
+		l.remote = source
+	}
+}
+
+// ConsulSource reads configuration values from the Consul KV store below a key prefix
+// and watches them using blocking queries
+type ConsulSource struct {
+	address string
+	prefix  string
+	token   string
+	client  *http.Client
+}
+
+// NewConsulSource creates a source reading keys below prefix (e.g. "config/myservice/")
+// from the Consul agent at address. The token may be empty.
+func NewConsulSource(address, prefix, token string) *ConsulSource {
+	return &ConsulSource{
+		address: strings.TrimSuffix(address, "/"),
+		prefix:  strings.TrimPrefix(prefix, "/"),
+		token:   token,
+		client:  &http.Client{Timeout: blockingTimeout},
+	}
+}
+
+// Values returns the current configuration values
+func (s *ConsulSource) Values(ctx context.Context) (map[string]string, error) {
+	values, _, err := s.fetch(ctx, 0)
+	return values, err
+}
+
+// Watch blocks until the context is canceled, calling onChange when the values change.
+// Failed requests, including the first one, are retried: the configuration was loaded
+// at startup, so an unavailable agent only delays reloads.
+func (s *ConsulSource) Watch(ctx context.Context, onChange func(map[string]string)) error {
+	current, index, err := s.fetch(ctx, 0)
+	for err != nil {
+		if !wait(ctx, retryInterval) {
+			return nil
+		}
+		current, index, err = s.fetch(ctx, 0)
+	}
+
+	for {
+		values, next, err := s.fetch(ctx, index)
+		if err != nil {
+			if !wait(ctx, retryInterval) {
+				return nil
+			}
+			continue
+		}
+
+		// Consul may reset the index; restart blocking from zero in that case
+		if next < index {
+			next = 0
+		}
+		index = next
+
+		if !maps.Equal(values, current) {
+			current = values
+			onChange(values)
+		}
+	}
+}
+
+// fetch reads all keys below the prefix, blocking until the index changes when index > 0
+func (s *ConsulSource) fetch(ctx context.Context, index uint64) (map[string]string, uint64, error) {
+	url := fmt.Sprintf("%s/v1/kv/%s?recurse=true", s.address, s.prefix)
+	if index > 0 {
+		url += fmt.Sprintf("&index=%d&wait=%s", index, blockingWait)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	if s.token != "" {
+		req.Header.Set("X-Consul-Token", s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("consul request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	next, _ := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+
+	values := map[string]string{}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		// No keys below the prefix yet
+		return values, next, nil
+	default:
+		return nil, 0, fmt.Errorf("consul returned status %d", resp.StatusCode)
+	}
+
+	var pairs []struct {
+		Key   string
+		Value []byte
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&pairs); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode consul response: %w", err)
+	}
+
+	for _, pair := range pairs {
+		key := strings.TrimPrefix(strings.TrimPrefix(pair.Key, s.prefix), "/")
+		if key == "" || strings.HasSuffix(key, "/") {
+			continue
+		}
+		values[key] = string(pair.Value)
+	}
+
+	return values, next, nil
+}
+
+// EtcdSource reads configuration values from etcd below a key prefix using the
+// etcd v3 JSON gateway, polling for changes
+type EtcdSource struct {
+	endpoint     string
+	prefix       string
+	pollInterval time.Duration
+	client       *http.Client
+}
+
+// NewEtcdSource creates a source reading keys below prefix (e.g. "/config/myservice/")
+// from the etcd endpoint (e.g. "http://etcd:2379"), polling every pollInterval
+func NewEtcdSource(endpoint, prefix string, pollInterval time.Duration) *EtcdSource {
+	return &EtcdSource{
+		endpoint:     strings.TrimSuffix(endpoint, "/"),
+		prefix:       prefix,
+		pollInterval: pollInterval,
+		client:       &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Values returns the current configuration values
+func (s *EtcdSource) Values(ctx context.Context) (map[string]string, error) {
+	return s.fetch(ctx)
+}
+
+// Watch blocks until the context is canceled, calling onChange when the values change.
+// Failed requests, including the first one, are retried at the next poll. The values
+// are compared rather than the store revision, which any write to the cluster changes.
+func (s *EtcdSource) Watch(ctx context.Context, onChange func(map[string]string)) error {
+	current, err := s.fetch(ctx)
+	for err != nil {
+		if !wait(ctx, s.pollInterval) {
+			return nil
+		}
+		current, err = s.fetch(ctx)
+	}
+
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		values, err := s.fetch(ctx)
+		if err != nil || maps.Equal(values, current) {
+			continue
+		}
+		current = values
+		onChange(values)
+	}
+}
+
+// fetch reads all keys below the prefix
+func (s *EtcdSource) fetch(ctx context.Context) (map[string]string, error) {
+	body, err := json.Marshal(map[string]string{
+		"key":       base64.StdEncoding.EncodeToString([]byte(s.prefix)),
+		"range_end": base64.StdEncoding.EncodeToString(prefixRangeEnd(s.prefix)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint+"/v3/kv/range", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("etcd request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("etcd returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Kvs []struct {
+			Key   []byte `json:"key"`
+			Value []byte `json:"value"`
+		} `json:"kvs"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode etcd response: %w", err)
+	}
+
+	values := make(map[string]string, len(result.Kvs))
+	for _, kv := range result.Kvs {
+		key := strings.TrimPrefix(strings.TrimPrefix(string(kv.Key), s.prefix), "/")
+		if key != "" {
+			values[key] = string(kv.Value)
+		}
+	}
+	return values, nil
+}
+
+// prefixRangeEnd returns the etcd range end matching every key with the given prefix
+func prefixRangeEnd(prefix string) []byte {
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	// The prefix is all 0xff bytes; match every key
+	return []byte{0}
+}
@@ -0,0 +1,166 @@
+package config
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticSource is a RemoteSource returning fixed values
+type staticSource map[string]string
+
+func (s staticSource) Values(context.Context) (map[string]string, error) { return s, nil }
+func (staticSource) Watch(ctx context.Context, _ func(map[string]string)) error {
+	<-ctx.Done()
+	return nil
+}
+
+func TestLoad_WithRemoteSource(t *testing.T) {
+	// Arrange
+	t.Setenv("HTTP_ADDRESS", ":7101")
+	source := staticSource{
+		"GRPC_ADDRESS":        ":7000",
+		"HTTP_ADDRESS":        ":7001",
+		"TRACING_SAMPLE_RATE": "0.25",
+		"FEATURE_X":           "on",
+	}
+
+	// Act
+	cfg, err := Load(WithRemoteSource(source))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, ":7000", cfg.GRPCAddress, "remote overrides defaults")
+	assert.Equal(t, ":7101", cfg.HTTPAddress, "environment overrides remote")
+	assert.InDelta(t, 0.25, cfg.Telemetry.Tracing.SampleRate, 0)
+}
+
+func TestConsulSource(t *testing.T) {
+	// Arrange
+	var index atomic.Uint64
+	index.Store(1)
+	consul := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/kv/config/svc/", r.URL.Path)
+		assert.Equal(t, "token", r.Header.Get("X-Consul-Token"))
+
+		value := "0.5"
+		if r.URL.Query().Get("index") != "" {
+			assert.Equal(t, "5m0s", r.URL.Query().Get("wait"))
+			// Simulate a change delivered by the blocking query
+			index.Store(2)
+			value = "0.1"
+		}
+		w.Header().Set("X-Consul-Index", "1")
+		if index.Load() == 2 {
+			w.Header().Set("X-Consul-Index", "2")
+		}
+		encoded := base64.StdEncoding.EncodeToString([]byte(value))
+		_, _ = w.Write([]byte(`[{"Key":"config/svc/","Value":null},{"Key":"config/svc/TRACING_SAMPLE_RATE","Value":"` + encoded + `"}]`))
+	}))
+	defer consul.Close()
+
+	source := NewConsulSource(consul.URL, "config/svc/", "token")
+
+	// Act
+	values, err := source.Values(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Greater(t, source.client.Timeout, blockingWait, "requests time out, after the blocking query wait")
+	assert.Equal(t, map[string]string{"TRACING_SAMPLE_RATE": "0.5"}, values)
+
+	// Act - watch delivers the changed values
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	changed := make(chan map[string]string, 1)
+	go func() {
+		_ = source.Watch(ctx, func(values map[string]string) {
+			select {
+			case changed <- values:
+			default:
+			}
+		})
+	}()
+
+	// Assert
+	select {
+	case values := <-changed:
+		assert.Equal(t, "0.1", values["TRACING_SAMPLE_RATE"])
+	case <-ctx.Done():
+		t.Fatal("watch did not report the change")
+	}
+}
+
+func TestEtcdSource(t *testing.T) {
+	// Arrange
+	var revision atomic.Int64
+	revision.Store(1)
+	var value atomic.Value
+	value.Store("0.5")
+	var failures atomic.Int32
+	etcd := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v3/kv/range", r.URL.Path)
+		if failures.Add(-1) >= 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		key := base64.StdEncoding.EncodeToString([]byte("/config/svc/TRACING_SAMPLE_RATE"))
+		encoded := base64.StdEncoding.EncodeToString([]byte(value.Load().(string)))
+		_, _ = w.Write([]byte(`{"header":{"revision":"` + strconv.FormatInt(revision.Load(), 10) + `"},"kvs":[{"key":"` + key + `","value":"` + encoded + `"}]}`))
+	}))
+	defer etcd.Close()
+
+	source := NewEtcdSource(etcd.URL, "/config/svc/", 10*time.Millisecond)
+
+	// Act
+	values, err := source.Values(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"TRACING_SAMPLE_RATE": "0.5"}, values)
+
+	// Act - watch survives failing first requests, ignores writes to other keys and
+	// delivers the changed values
+	failures.Store(3)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	changed := make(chan map[string]string, 2)
+	watched := make(chan error, 1)
+	go func() {
+		watched <- source.Watch(ctx, func(values map[string]string) {
+			select {
+			case changed <- values:
+			default:
+			}
+		})
+	}()
+	time.Sleep(60 * time.Millisecond)
+	revision.Store(2)
+	time.Sleep(30 * time.Millisecond)
+	value.Store("0.1")
+	revision.Store(3)
+
+	// Assert
+	select {
+	case values := <-changed:
+		assert.Equal(t, "0.1", values["TRACING_SAMPLE_RATE"])
+	case <-ctx.Done():
+		t.Fatal("watch did not report the change")
+	}
+	cancel()
+	assert.NoError(t, <-watched)
+	assert.Empty(t, changed, "only the changed values are reported")
+}
+
+func TestPrefixRangeEnd(t *testing.T) {
+	assert.Equal(t, []byte("/config/svc0"), prefixRangeEnd("/config/svc/"))
+	assert.Equal(t, []byte{0}, prefixRangeEnd(string([]byte{0xff})))
+}
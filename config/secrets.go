@@ -0,0 +1,190 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// secretSchemes are the reference schemes treated as secrets; values using them
+// must be resolved, otherwise loading fails instead of passing the reference on
+var secretSchemes = []string{"vault", "awssm", "gcpsm"}
+
+// SecretsTimeout bounds resolving the secret references of a configuration, so an
+// unreachable secret manager fails startup instead of hanging it
+const SecretsTimeout = 30 * time.Second
+
+// SecretResolver resolves a secret reference such as vault://path#key to its value
+type SecretResolver interface {
+	ResolveSecret(ctx context.Context, ref *url.URL) (string, error)
+}
+
+// SecretResolverFunc is an adapter to allow the use of ordinary functions as secret resolvers
+type SecretResolverFunc func(ctx context.Context, ref *url.URL) (string, error)
+
+// ResolveSecret calls f(ctx, ref)
+func (f SecretResolverFunc) ResolveSecret(ctx context.Context, ref *url.URL) (string, error) {
+	return f(ctx, ref)
+}
+
+// WithSecretResolver registers a resolver for config values using the given scheme (e.g. "awssm")
+func WithSecretResolver(scheme string, resolver SecretResolver) LoadOption {
+	return func(l *loader) {
+		if l.resolvers == nil {
+			l.resolvers = map[string]SecretResolver{}
+		}
+		l.resolvers[scheme] = resolver
+	}
+}
+
+// ResolveSecrets replaces string values of the form scheme://... with the value returned by
+// the resolver registered for the scheme. Values using a secret scheme (vault, awssm, gcpsm)
+// without a registered resolver are reported as errors.
+func (c *Config) ResolveSecrets(ctx context.Context, resolvers map[string]SecretResolver) error {
+	return resolveSecrets(ctx, reflect.ValueOf(c).Elem(), reflect.Value{}, resolvers)
+}
+
+// ResolveChangedSecrets resolves like ResolveSecrets the values that differ from base,
+// e.g. those set by options on top of a loaded configuration whose values are resolved
+func (c *Config) ResolveChangedSecrets(ctx context.Context, base *Config, resolvers map[string]SecretResolver) error {
+	return resolveSecrets(ctx, reflect.ValueOf(c).Elem(), reflect.ValueOf(base).Elem(), resolvers)
+}
+
+// resolveSecrets walks string and string slice fields, resolving secret references in place.
+// Fields equal to those of base are skipped when base is valid.
+func resolveSecrets(ctx context.Context, v, base reflect.Value, resolvers map[string]SecretResolver) error {
+	t := v.Type()
+	for i := range t.NumField() {
+		field := v.Field(i)
+		if !t.Field(i).IsExported() {
+			continue
+		}
+
+		var baseField reflect.Value
+		if base.IsValid() {
+			baseField = base.Field(i)
+			if field.Kind() != reflect.Struct && reflect.DeepEqual(field.Interface(), baseField.Interface()) {
+				continue
+			}
+		}
+
+		switch {
+		case field.Kind() == reflect.Struct:
+			if err := resolveSecrets(ctx, field, baseField, resolvers); err != nil {
+				return err
+			}
+		case field.Kind() == reflect.String:
+			value, err := resolveSecret(ctx, field.String(), resolvers)
+			if err != nil {
+				return fmt.Errorf("%s: %w", t.Field(i).Name, err)
+			}
+			field.SetString(value)
+		case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String:
+			for j := range field.Len() {
+				value, err := resolveSecret(ctx, field.Index(j).String(), resolvers)
+				if err != nil {
+					return fmt.Errorf("%s[%d]: %w", t.Field(i).Name, j, err)
+				}
+				field.Index(j).SetString(value)
+			}
+		}
+	}
+	return nil
+}
+
+// resolveSecret resolves a single value, returning it unchanged when it isn't a secret reference
+func resolveSecret(ctx context.Context, value string, resolvers map[string]SecretResolver) (string, error) {
+	scheme, _, ok := strings.Cut(value, "://")
+	if !ok {
+		return value, nil
+	}
+
+	resolver, registered := resolvers[scheme]
+	if !registered {
+		for _, secretScheme := range secretSchemes {
+			if scheme == secretScheme {
+				return "", fmt.Errorf("no secret resolver registered for scheme %q", scheme)
+			}
+		}
+		return value, nil
+	}
+
+	ref, err := url.Parse(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid secret reference: %w", err)
+	}
+
+	secret, err := resolver.ResolveSecret(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s secret %s: %w", scheme, ref.Redacted(), err)
+	}
+
+	return secret, nil
+}
+
+// VaultResolver resolves vault://<path>#<key> references using the Vault HTTP API.
+// Both KV v1 and KV v2 responses are supported; for KV v2 the path includes "data/",
+// e.g. vault://secret/data/myapp#password.
+type VaultResolver struct {
+	address string
+	token   string
+	client  *http.Client
+}
+
+// NewVaultResolver creates a resolver reading secrets from the Vault server at address
+func NewVaultResolver(address, token string) *VaultResolver {
+	return &VaultResolver{
+		address: strings.TrimSuffix(address, "/"),
+		token:   token,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// ResolveSecret reads the secret at the reference path and returns the key named by its fragment
+func (r *VaultResolver) ResolveSecret(ctx context.Context, ref *url.URL) (string, error) {
+	if ref.Fragment == "" {
+		return "", fmt.Errorf("missing secret key, expected vault://<path>#<key>")
+	}
+
+	path := strings.Trim(ref.Host+ref.Path, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.address+"/v1/"+path, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", r.token)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode vault response: %w", err)
+	}
+
+	// KV v2 nests the secret data under data.data
+	data := body.Data
+	if nested, ok := data["data"].(map[string]any); ok {
+		data = nested
+	}
+
+	value, ok := data[ref.Fragment]
+	if !ok {
+		return "", fmt.Errorf("key %q not found", ref.Fragment)
+	}
+
+	return fmt.Sprint(value), nil
+}
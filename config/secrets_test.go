@@ -0,0 +1,171 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_ResolveSecrets(t *testing.T) {
+	// Arrange
+	cfg := NewConfig()
+	cfg.Telemetry.OTEL.Headers = "awssm://otel-headers"
+	cfg.Telemetry.OTEL.Endpoint = "https://collector:4318"
+	cfg.PprofAllowedCIDRs = []string{"gcpsm://projects/p/secrets/cidr", "127.0.0.1"}
+
+	resolvers := map[string]SecretResolver{
+		"awssm": SecretResolverFunc(func(_ context.Context, ref *url.URL) (string, error) {
+			return "authorization=" + ref.Host, nil
+		}),
+		"gcpsm": SecretResolverFunc(func(_ context.Context, _ *url.URL) (string, error) {
+			return "10.0.0.0/8", nil
+		}),
+	}
+
+	// Act
+	err := cfg.ResolveSecrets(context.Background(), resolvers)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "authorization=otel-headers", cfg.Telemetry.OTEL.Headers)
+	assert.Equal(t, "https://collector:4318", cfg.Telemetry.OTEL.Endpoint, "non-secret URLs are untouched")
+	assert.Equal(t, []string{"10.0.0.0/8", "127.0.0.1"}, cfg.PprofAllowedCIDRs)
+}
+
+func TestConfig_ResolveChangedSecrets(t *testing.T) {
+	// Arrange
+	base := NewConfig()
+	base.PprofAuthToken = "awssm://already-resolved-value"
+	cfg := *base
+	cfg.Telemetry.OTEL.Headers = "awssm://otel-headers"
+
+	var resolved []string
+	resolvers := map[string]SecretResolver{
+		"awssm": SecretResolverFunc(func(_ context.Context, ref *url.URL) (string, error) {
+			resolved = append(resolved, ref.Host)
+			return "secret", nil
+		}),
+	}
+
+	// Act
+	err := cfg.ResolveChangedSecrets(context.Background(), base, resolvers)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"otel-headers"}, resolved)
+	assert.Equal(t, "secret", cfg.Telemetry.OTEL.Headers)
+	assert.Equal(t, "awssm://already-resolved-value", cfg.PprofAuthToken)
+}
+
+func TestConfig_ResolveSecrets_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		resolvers map[string]SecretResolver
+		wantErr   string
+	}{
+		{
+			name:    "unregistered secret scheme",
+			wantErr: `no secret resolver registered for scheme "vault"`,
+		},
+		{
+			name: "resolver failure",
+			resolvers: map[string]SecretResolver{
+				"vault": SecretResolverFunc(func(context.Context, *url.URL) (string, error) {
+					return "", errors.New("permission denied")
+				}),
+			},
+			wantErr: "permission denied",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			cfg := NewConfig()
+			cfg.PprofAuthToken = "vault://secret/data/app#token"
+
+			// Act
+			err := cfg.ResolveSecrets(context.Background(), tt.resolvers)
+
+			// Assert
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "PprofAuthToken")
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestVaultResolver(t *testing.T) {
+	// Arrange
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/app":
+			_, _ = w.Write([]byte(`{"data":{"data":{"token":"s3cr3t"}}}`))
+		case "/v1/kv/app":
+			_, _ = w.Write([]byte(`{"data":{"token":"v1-s3cr3t"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer vault.Close()
+
+	tests := []struct {
+		name    string
+		token   string
+		ref     string
+		want    string
+		wantErr string
+	}{
+		{name: "kv v2", token: "root", ref: "vault://secret/data/app#token", want: "s3cr3t"},
+		{name: "kv v1", token: "root", ref: "vault://kv/app#token", want: "v1-s3cr3t"},
+		{name: "missing key", token: "root", ref: "vault://kv/app#password", wantErr: `key "password" not found`},
+		{name: "missing fragment", token: "root", ref: "vault://kv/app", wantErr: "missing secret key"},
+		{name: "forbidden", token: "wrong", ref: "vault://kv/app#token", wantErr: "status 403"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			resolver := NewVaultResolver(vault.URL, tt.token)
+			ref, err := url.Parse(tt.ref)
+			require.NoError(t, err)
+
+			// Act
+			got, err := resolver.ResolveSecret(context.Background(), ref)
+
+			// Assert
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLoad_WithSecretResolver(t *testing.T) {
+	// Arrange
+	t.Setenv("PPROF_AUTH_TOKEN", "awssm://pprof-token")
+	resolver := SecretResolverFunc(func(context.Context, *url.URL) (string, error) {
+		return "resolved", nil
+	})
+
+	// Act
+	cfg, err := Load(WithSecretResolver("awssm", resolver))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "resolved", cfg.PprofAuthToken)
+}
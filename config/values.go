@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Redacted replaces the value of fields tagged secret:"true" in Values
+const Redacted = "REDACTED"
+
+// Values returns the configuration keyed by environment variable name, so it can be
+// reported by admin endpoints. Non-empty secrets are replaced by Redacted.
+func (c *Config) Values() map[string]string {
+	values := map[string]string{}
+	collectValues(reflect.ValueOf(c).Elem(), values)
+	return values
+}
+
+// collectValues walks the fields with an envconfig tag, descending into nested structs
+func collectValues(v reflect.Value, values map[string]string) {
+	t := v.Type()
+	for i := range t.NumField() {
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct {
+			collectValues(field, values)
+			continue
+		}
+
+		name := t.Field(i).Tag.Get("envconfig")
+		if name == "" {
+			continue
+		}
+
+		var value string
+		if field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String {
+			value = strings.Join(field.Interface().([]string), ",")
+		} else {
+			value = fmt.Sprint(field.Interface())
+		}
+		if value != "" && t.Field(i).Tag.Get("secret") == "true" {
+			value = Redacted
+		}
+		values[name] = value
+	}
+}
+
+// Variable describes an environment variable read into the configuration
+type Variable struct {
+	Name    string
+	Default string
+	Secret  bool
+}
+
+// Variables returns the environment variables of the configuration with their default
+// values, in declaration order
+func Variables() []Variable {
+	var vars []Variable
+	collectVariables(reflect.TypeOf(Config{}), &vars)
+	return vars
+}
+
+// collectVariables walks the fields with an envconfig tag, descending into nested structs
+func collectVariables(t reflect.Type, vars *[]Variable) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if field.Type.Kind() == reflect.Struct {
+			collectVariables(field.Type, vars)
+			continue
+		}
+
+		name := field.Tag.Get("envconfig")
+		if name == "" {
+			continue
+		}
+		*vars = append(*vars, Variable{
+			Name:    name,
+			Default: field.Tag.Get("default"),
+			Secret:  field.Tag.Get("secret") == "true",
+		})
+	}
+}
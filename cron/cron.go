@@ -0,0 +1,378 @@
+// Package cron runs jobs on cron schedules as a server process. Every run gets a timeout,
+// a tracing span and metrics, overlapping runs follow the job's overlap policy, panics
+// are recovered, and shutdown waits for running jobs before canceling them.
+package cron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	metricsreg "github.com/legrch/netgex/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Job is the work run on a schedule; the context is canceled when the run times out or
+// shutdown gives up waiting for it
+type Job func(ctx context.Context) error
+
+// Overlap decides what happens when a run is due while the previous one is still running
+type Overlap int
+
+const (
+	// OverlapSkip skips the due run
+	OverlapSkip Overlap = iota
+	// OverlapAllow starts the due run alongside the previous one
+	OverlapAllow
+	// OverlapDelay starts the due run once the previous one finishes; further due runs
+	// are coalesced into it
+	OverlapDelay
+)
+
+// JobOption configures a job
+type JobOption func(*entry)
+
+// WithTimeout cancels the context of runs lasting longer than timeout
+func WithTimeout(timeout time.Duration) JobOption {
+	return func(e *entry) {
+		e.timeout = timeout
+	}
+}
+
+// WithOverlap sets the overlap policy of the job, OverlapSkip by default
+func WithOverlap(overlap Overlap) JobOption {
+	return func(e *entry) {
+		e.overlap = overlap
+	}
+}
+
+// Option configures a Scheduler
+type Option func(*Scheduler)
+
+// WithLogger sets the logger of the scheduler, slog.Default() by default
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Scheduler) {
+		s.logger = logger
+	}
+}
+
+// WithLocation sets the time zone schedules are evaluated in, time.Local by default
+func WithLocation(loc *time.Location) Option {
+	return func(s *Scheduler) {
+		s.location = loc
+	}
+}
+
+// WithMetrics records runs in the cron_job_runs_total, cron_job_duration_seconds and
+// cron_job_last_success_timestamp_seconds metrics of the namespace
+func WithMetrics(namespace string) Option {
+	return func(s *Scheduler) {
+		s.metrics = jobMetrics(namespace)
+	}
+}
+
+// entry is a registered job and the state of its runs
+type entry struct {
+	name     string
+	schedule Schedule
+	job      Job
+	timeout  time.Duration
+	overlap  Overlap
+
+	mu      sync.Mutex
+	running int
+	pending bool
+}
+
+// Scheduler is a process running jobs on their schedules
+type Scheduler struct {
+	logger   *slog.Logger
+	location *time.Location
+	metrics  *metrics
+	now      func() time.Time
+
+	mu      sync.Mutex
+	entries []*entry
+	runCtx  context.Context
+	stopped bool
+
+	// stop ends the schedule loops, jobCtx is the parent of every run, canceled when
+	// shutdown stops waiting
+	stop      chan struct{}
+	jobCtx    context.Context
+	cancelJob context.CancelFunc
+	runs      sync.WaitGroup
+}
+
+// New creates a scheduler without jobs
+func New(opts ...Option) *Scheduler {
+	jobCtx, cancel := context.WithCancel(context.Background())
+	s := &Scheduler{
+		logger:    slog.Default(),
+		location:  time.Local,
+		now:       time.Now,
+		stop:      make(chan struct{}),
+		jobCtx:    jobCtx,
+		cancelJob: cancel,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Add registers a job under a unique name, running it on the schedule spec, see Parse.
+// Jobs added while the scheduler runs are scheduled immediately.
+func (s *Scheduler) Add(name, spec string, job Job, opts ...JobOption) error {
+	schedule, err := Parse(spec)
+	if err != nil {
+		return fmt.Errorf("cron job %s: %w", name, err)
+	}
+	e := &entry{name: name, schedule: schedule, job: job}
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.entries {
+		if existing.name == name {
+			return fmt.Errorf("cron job %s already registered", name)
+		}
+	}
+	s.entries = append(s.entries, e)
+	if s.runCtx != nil && !s.stopped {
+		go s.loop(s.runCtx, e)
+	}
+	return nil
+}
+
+// PreRun prepares the scheduler
+func (*Scheduler) PreRun(_ context.Context) error {
+	return nil
+}
+
+// Run schedules the jobs until the context is canceled or the scheduler is shut down
+func (s *Scheduler) Run(ctx context.Context) error {
+	s.mu.Lock()
+	s.runCtx = ctx
+	for _, e := range s.entries {
+		go s.loop(ctx, e)
+	}
+	s.logger.Info("starting cron scheduler", "jobs", len(s.entries))
+	s.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+	case <-s.stop:
+	}
+	return nil
+}
+
+// Shutdown stops scheduling runs and waits for the running ones. When ctx ends first
+// their contexts are canceled and an error is returned.
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+	s.logger.Info("shutting down cron scheduler")
+	s.mu.Lock()
+	if !s.stopped {
+		s.stopped = true
+		close(s.stop)
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.runs.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.cancelJob()
+		return nil
+	case <-ctx.Done():
+		s.cancelJob()
+		return fmt.Errorf("cron jobs still running at shutdown: %w", ctx.Err())
+	}
+}
+
+// loop dispatches the runs of a job as they come due
+func (s *Scheduler) loop(ctx context.Context, e *entry) {
+	for {
+		now := s.now().In(s.location)
+		next := e.schedule.Next(now)
+		if next.IsZero() {
+			s.logger.Warn("cron job schedule never activates", "job", e.name)
+			return
+		}
+
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-s.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+			s.dispatch(e)
+		}
+	}
+}
+
+// dispatch starts a due run, following the overlap policy when one is running
+func (s *Scheduler) dispatch(e *entry) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.running > 0 {
+		switch e.overlap {
+		case OverlapSkip:
+			s.logger.Warn("cron job still running, skipping run", "job", e.name)
+			s.metrics.skipped(e.name)
+			return
+		case OverlapDelay:
+			e.pending = true
+			return
+		}
+	}
+	s.start(e)
+}
+
+// start starts a run; e.mu must be held
+func (s *Scheduler) start(e *entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return
+	}
+
+	e.running++
+	s.runs.Add(1)
+	go func() {
+		defer s.runs.Done()
+		s.execute(e)
+
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		e.running--
+		if e.pending {
+			e.pending = false
+			s.start(e)
+		}
+	}()
+}
+
+// execute runs the job once with its timeout, span and metrics
+func (s *Scheduler) execute(e *entry) {
+	ctx := s.jobCtx
+	if e.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.timeout)
+		defer cancel()
+	}
+	ctx, span := otel.Tracer("cron").Start(ctx, "cron "+e.name,
+		trace.WithAttributes(attribute.String("cron.job", e.name)),
+		trace.WithSpanKind(trace.SpanKindInternal),
+	)
+	defer span.End()
+
+	start := time.Now()
+	err := s.call(ctx, e)
+	duration := time.Since(start)
+
+	result := "success"
+	switch {
+	case errors.Is(err, errPanic):
+		result = "panic"
+	case err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded):
+		result = "timeout"
+	case err != nil:
+		result = "error"
+	}
+	s.metrics.observe(e.name, result, duration, s.now())
+
+	if err != nil {
+		span.SetStatus(otelcodes.Error, err.Error())
+		s.logger.Error("cron job failed", "job", e.name, "result", result, "duration", duration, "error", err)
+		return
+	}
+	span.SetStatus(otelcodes.Ok, "")
+	s.logger.Debug("cron job finished", "job", e.name, "duration", duration)
+}
+
+// errPanic marks runs ended by a panic
+var errPanic = errors.New("cron job panicked")
+
+// call runs the job, turning a panic into an error
+func (s *Scheduler) call(ctx context.Context, e *entry) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			s.logger.Error("panic in cron job", "job", e.name, "panic", p, "stack", string(debug.Stack()))
+			err = fmt.Errorf("%w: %v", errPanic, p)
+		}
+	}()
+	return e.job(ctx)
+}
+
+// metrics are the collectors of a namespace; a nil *metrics records nothing
+type metrics struct {
+	runs        *prometheus.CounterVec
+	duration    *prometheus.HistogramVec
+	lastSuccess *prometheus.GaugeVec
+}
+
+// observe records a finished run
+func (m *metrics) observe(job, result string, duration time.Duration, now time.Time) {
+	if m == nil {
+		return
+	}
+	m.runs.WithLabelValues(job, result).Inc()
+	m.duration.WithLabelValues(job).Observe(duration.Seconds())
+	if result == "success" {
+		m.lastSuccess.WithLabelValues(job).Set(float64(now.Unix()))
+	}
+}
+
+// skipped records a run skipped by the overlap policy
+func (m *metrics) skipped(job string) {
+	if m != nil {
+		m.runs.WithLabelValues(job, "skipped").Inc()
+	}
+}
+
+// jobMetrics returns the collectors of the namespace, registering them unless already
+// registered
+func jobMetrics(namespace string) *metrics {
+	m := &metrics{
+		runs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "cron_job_runs_total",
+			Help:      "Total number of cron job runs by result: success, error, timeout, panic or skipped",
+		}, []string{"job", "result"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "cron_job_duration_seconds",
+			Help:      "Duration of cron job runs in seconds",
+			Buckets:   []float64{.01, .1, .5, 1, 5, 10, 30, 60, 300, 900, 3600},
+		}, []string{"job"}),
+		lastSuccess: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "cron_job_last_success_timestamp_seconds",
+			Help:      "Unix time of the last successful run of each cron job",
+		}, []string{"job"}),
+	}
+	m.runs = metricsreg.Register(m.runs)
+	m.duration = metricsreg.Register(m.duration)
+	m.lastSuccess = metricsreg.Register(m.lastSuccess)
+	return m
+}
@@ -0,0 +1,176 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduler_Add(t *testing.T) {
+	// Arrange
+	s := New()
+	job := func(context.Context) error { return nil }
+
+	// Act
+	err := s.Add("cleanup", "@every 1m", job)
+	duplicateErr := s.Add("cleanup", "@every 1m", job)
+	invalidErr := s.Add("report", "every minute", job)
+
+	// Assert
+	require.NoError(t, err)
+	assert.ErrorContains(t, duplicateErr, "already registered")
+	assert.ErrorContains(t, invalidErr, "cron job report")
+}
+
+func TestScheduler_Run(t *testing.T) {
+	// Arrange
+	s := New(WithMetrics("crontest"))
+	var runs atomic.Int32
+	require.NoError(t, s.Add("tick", "@every 10ms", func(context.Context) error {
+		runs.Add(1)
+		return nil
+	}))
+	require.NoError(t, s.Add("fail", "@every 10ms", func(context.Context) error {
+		return errors.New("boom")
+	}))
+	require.NoError(t, s.Add("panic", "@every 10ms", func(context.Context) error {
+		panic("boom")
+	}))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Act
+	go func() { _ = s.Run(ctx) }()
+	require.Eventually(t, func() bool { return runs.Load() >= 3 }, time.Second, 5*time.Millisecond)
+	err := s.Shutdown(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	m := jobMetrics("crontest")
+	assert.GreaterOrEqual(t, testutil.ToFloat64(m.runs.WithLabelValues("tick", "success")), 3.0)
+	assert.Positive(t, testutil.ToFloat64(m.runs.WithLabelValues("fail", "error")))
+	assert.Positive(t, testutil.ToFloat64(m.runs.WithLabelValues("panic", "panic")))
+	assert.Positive(t, testutil.ToFloat64(m.lastSuccess.WithLabelValues("tick")))
+}
+
+func TestScheduler_Timeout(t *testing.T) {
+	// Arrange
+	s := New(WithMetrics("crontimeouttest"))
+	require.NoError(t, s.Add("slow", "@every 10ms", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, WithTimeout(10*time.Millisecond)))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	runs := jobMetrics("crontimeouttest").runs.WithLabelValues("slow", "timeout")
+
+	// Act
+	go func() { _ = s.Run(ctx) }()
+	require.Eventually(t, func() bool { return testutil.ToFloat64(runs) >= 1 }, time.Second, 5*time.Millisecond)
+
+	// Assert
+	assert.NoError(t, s.Shutdown(context.Background()))
+}
+
+func TestScheduler_Overlap(t *testing.T) {
+	tests := []struct {
+		name        string
+		overlap     Overlap
+		wantRunning int32
+	}{
+		{name: "skip", overlap: OverlapSkip, wantRunning: 1},
+		{name: "delay", overlap: OverlapDelay, wantRunning: 1},
+		{name: "allow", overlap: OverlapAllow, wantRunning: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			s := New()
+			var running, maxRunning atomic.Int32
+			release := make(chan struct{})
+			require.NoError(t, s.Add("job", "@every 5ms", func(context.Context) error {
+				n := running.Add(1)
+				defer running.Add(-1)
+				for {
+					current := maxRunning.Load()
+					if n <= current || maxRunning.CompareAndSwap(current, n) {
+						break
+					}
+				}
+				<-release
+				return nil
+			}, WithOverlap(tt.overlap)))
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			// Act
+			go func() { _ = s.Run(ctx) }()
+			require.Eventually(t, func() bool { return maxRunning.Load() >= tt.wantRunning }, time.Second, time.Millisecond)
+			time.Sleep(30 * time.Millisecond)
+			close(release)
+			err := s.Shutdown(context.Background())
+
+			// Assert
+			require.NoError(t, err)
+			if tt.overlap == OverlapAllow {
+				assert.GreaterOrEqual(t, maxRunning.Load(), tt.wantRunning)
+				return
+			}
+			assert.Equal(t, tt.wantRunning, maxRunning.Load())
+		})
+	}
+}
+
+func TestScheduler_ShutdownCancelsRuns(t *testing.T) {
+	// Arrange
+	s := New()
+	started := make(chan struct{}, 1)
+	canceled := make(chan struct{})
+	require.NoError(t, s.Add("stuck", "@every 5ms", func(ctx context.Context) error {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-ctx.Done()
+		close(canceled)
+		return ctx.Err()
+	}))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = s.Run(ctx) }()
+	<-started
+
+	// Act
+	shutdownCtx, stop := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer stop()
+	err := s.Shutdown(shutdownCtx)
+
+	// Assert
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("run was not canceled")
+	}
+}
+
+func TestWithMetrics_SharedBetweenSchedulers(t *testing.T) {
+	// Act
+	var first, second *Scheduler
+	assert.NotPanics(t, func() {
+		first = New(WithMetrics("cronshared"))
+		second = New(WithMetrics("cronshared"))
+	})
+
+	// Assert
+	assert.Same(t, first.metrics.runs, second.metrics.runs)
+	assert.Same(t, first.metrics.duration, second.metrics.duration)
+	assert.Same(t, first.metrics.lastSuccess, second.metrics.lastSuccess)
+}
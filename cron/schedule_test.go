@@ -0,0 +1,86 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_Next(t *testing.T) {
+	// Wednesday
+	from := time.Date(2025, 1, 15, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{spec: "* * * * *", want: time.Date(2025, 1, 15, 10, 8, 0, 0, time.UTC)},
+		{spec: "*/15 * * * *", want: time.Date(2025, 1, 15, 10, 15, 0, 0, time.UTC)},
+		{spec: "5 * * * *", want: time.Date(2025, 1, 15, 11, 5, 0, 0, time.UTC)},
+		{spec: "0 9-17 * * MON-FRI", want: time.Date(2025, 1, 15, 11, 0, 0, 0, time.UTC)},
+		{spec: "30 2 * * sat,sun", want: time.Date(2025, 1, 18, 2, 30, 0, 0, time.UTC)},
+		{spec: "0 0 * * 7", want: time.Date(2025, 1, 19, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 0 1 */3 *", want: time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 12 29 FEB *", want: time.Date(2028, 2, 29, 12, 0, 0, 0, time.UTC)},
+		// Both days restricted: the 20th or any Monday
+		{spec: "0 0 20 * MON", want: time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC)},
+		{spec: "@daily", want: time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)},
+		{spec: "@hourly", want: time.Date(2025, 1, 15, 11, 0, 0, 0, time.UTC)},
+		{spec: "@every 90s", want: time.Date(2025, 1, 15, 10, 9, 0, 0, time.UTC)},
+		{spec: "0 0 30 2 *", want: time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			// Arrange
+			schedule, err := Parse(tt.spec)
+			require.NoError(t, err)
+
+			// Act
+			next := schedule.Next(from)
+
+			// Assert
+			assert.Equal(t, tt.want, next)
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"* * * FOO *",
+		"@every never",
+		"@every -1m",
+	} {
+		t.Run(spec, func(t *testing.T) {
+			// Act
+			_, err := Parse(spec)
+
+			// Assert
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParse_Location(t *testing.T) {
+	// Arrange
+	loc := time.FixedZone("UTC+3", 3*60*60)
+	schedule, err := Parse("0 9 * * *")
+	require.NoError(t, err)
+
+	// Act
+	next := schedule.Next(time.Date(2025, 1, 15, 7, 0, 0, 0, time.UTC).In(loc))
+
+	// Assert
+	assert.Equal(t, time.Date(2025, 1, 16, 6, 0, 0, 0, time.UTC), next.UTC())
+}
@@ -0,0 +1,174 @@
+// Package db manages a database/sql connection pool as a server process: it connects
+// and pings in PreRun so a broken database fails startup, reports its health to the
+// server's readiness checks, exports pool metrics and closes on shutdown. PostgreSQL
+// pools use the pgx driver through its database/sql adapter, github.com/jackc/pgx/v5/stdlib.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Option configures a Pool
+type Option func(*Pool)
+
+// WithName names the pool in logs, health errors and metrics, "default" by default
+func WithName(name string) Option {
+	return func(p *Pool) {
+		p.name = name
+	}
+}
+
+// WithLogger sets the logger of the pool, slog.Default() by default
+func WithLogger(logger *slog.Logger) Option {
+	return func(p *Pool) {
+		p.logger = logger
+	}
+}
+
+// WithMaxOpenConns limits the number of open connections, unlimited by default
+func WithMaxOpenConns(n int) Option {
+	return func(p *Pool) {
+		p.maxOpen = n
+	}
+}
+
+// WithMaxIdleConns sets how many idle connections are kept, 2 by default
+func WithMaxIdleConns(n int) Option {
+	return func(p *Pool) {
+		p.maxIdle = n
+	}
+}
+
+// WithConnMaxLifetime closes connections older than d, e.g. to follow failovers
+func WithConnMaxLifetime(d time.Duration) Option {
+	return func(p *Pool) {
+		p.maxLifetime = d
+	}
+}
+
+// WithConnMaxIdleTime closes connections idle for longer than d
+func WithConnMaxIdleTime(d time.Duration) Option {
+	return func(p *Pool) {
+		p.maxIdleTime = d
+	}
+}
+
+// WithPingTimeout bounds the ping of PreRun and of health checks, 5s by default
+func WithPingTimeout(timeout time.Duration) Option {
+	return func(p *Pool) {
+		p.pingTimeout = timeout
+	}
+}
+
+// WithMetrics exports the connection and wait statistics of the pool in the db_pool_*
+// metrics of the namespace, labeled with the pool name
+func WithMetrics(namespace string) Option {
+	return func(p *Pool) {
+		p.namespace = namespace
+		p.metrics = true
+	}
+}
+
+// Pool is a process managing a database/sql connection pool
+type Pool struct {
+	driver      string
+	dsn         string
+	name        string
+	logger      *slog.Logger
+	maxOpen     int
+	maxIdle     int
+	maxLifetime time.Duration
+	maxIdleTime time.Duration
+	pingTimeout time.Duration
+	namespace   string
+	metrics     bool
+
+	db *sql.DB
+}
+
+// New creates a pool connecting to dsn with the registered driver, e.g. "pgx"
+func New(driver, dsn string, opts ...Option) *Pool {
+	p := &Pool{
+		driver:      driver,
+		dsn:         dsn,
+		name:        "default",
+		logger:      slog.Default(),
+		maxIdle:     2,
+		pingTimeout: 5 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// DB returns the connection pool, nil before PreRun
+func (p *Pool) DB() *sql.DB {
+	return p.db
+}
+
+// Name returns the name of the pool
+func (p *Pool) Name() string {
+	return "db/" + p.name
+}
+
+// PreRun opens the pool and pings the database
+func (p *Pool) PreRun(ctx context.Context) error {
+	db, err := sql.Open(p.driver, p.dsn)
+	if err != nil {
+		return fmt.Errorf("failed to open database %s: %w", p.name, err)
+	}
+	db.SetMaxOpenConns(p.maxOpen)
+	db.SetMaxIdleConns(p.maxIdle)
+	db.SetConnMaxLifetime(p.maxLifetime)
+	db.SetConnMaxIdleTime(p.maxIdleTime)
+
+	pingCtx, cancel := context.WithTimeout(ctx, p.pingTimeout)
+	defer cancel()
+	if err := db.PingContext(pingCtx); err != nil {
+		_ = db.Close()
+		return fmt.Errorf("failed to connect to database %s: %w", p.name, err)
+	}
+
+	p.db = db
+	if p.metrics {
+		statsCollector(p.namespace).add(p.name, db)
+	}
+	p.logger.Info("connected to database", "pool", p.name, "driver", p.driver)
+	return nil
+}
+
+// Run keeps the pool open until the context is canceled
+func (p *Pool) Run(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+// HealthCheck pings the database, failing readiness while it is unreachable
+func (p *Pool) HealthCheck(ctx context.Context) error {
+	if p.db == nil {
+		return fmt.Errorf("database %s not connected", p.name)
+	}
+	ctx, cancel := context.WithTimeout(ctx, p.pingTimeout)
+	defer cancel()
+	return p.db.PingContext(ctx)
+}
+
+// Shutdown closes the pool once the servers no longer use it
+func (p *Pool) Shutdown(_ context.Context) error {
+	if p.db == nil {
+		return nil
+	}
+	p.logger.Info("closing database pool", "pool", p.name)
+	if p.metrics {
+		statsCollector(p.namespace).remove(p.name)
+	}
+	if err := p.db.Close(); err != nil {
+		return fmt.Errorf("failed to close database %s: %w", p.name, err)
+	}
+	return nil
+}
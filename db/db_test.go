@@ -0,0 +1,130 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDriver opens connections whose pings fail while down is set
+type fakeDriver struct {
+	down atomic.Bool
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) {
+	return &fakeConn{driver: d}, nil
+}
+
+type fakeConn struct {
+	driver *fakeDriver
+}
+
+func (c *fakeConn) Ping(context.Context) error {
+	if c.driver.down.Load() {
+		return driver.ErrBadConn
+	}
+	return nil
+}
+
+func (*fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (*fakeConn) Close() error {
+	return nil
+}
+
+func (*fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("not supported")
+}
+
+var testDriver = &fakeDriver{}
+
+func init() {
+	sql.Register("dbtest", testDriver)
+}
+
+func TestPool_Lifecycle(t *testing.T) {
+	// Arrange
+	testDriver.down.Store(false)
+	p := New("dbtest", "", WithName("orders"), WithMaxOpenConns(4), WithMetrics("dbtest"))
+
+	// Act
+	err := p.PreRun(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, p.DB())
+	assert.Equal(t, "db/orders", p.Name())
+	assert.NoError(t, p.HealthCheck(context.Background()))
+
+	testDriver.down.Store(true)
+	assert.Error(t, p.HealthCheck(context.Background()))
+	testDriver.down.Store(false)
+
+	expected := `
+# HELP dbtest_db_pool_max_open_connections Maximum number of open connections of the pool, 0 when unlimited
+# TYPE dbtest_db_pool_max_open_connections gauge
+dbtest_db_pool_max_open_connections{pool="orders"} 4
+`
+	c := statsCollector("dbtest")
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "dbtest_db_pool_max_open_connections"))
+
+	require.NoError(t, p.Shutdown(context.Background()))
+	assert.Equal(t, 0, testutil.CollectAndCount(c, "dbtest_db_pool_max_open_connections"))
+	assert.Error(t, p.DB().Ping())
+}
+
+func TestPool_PreRunFailsWhenUnreachable(t *testing.T) {
+	// Arrange
+	testDriver.down.Store(true)
+	defer testDriver.down.Store(false)
+	p := New("dbtest", "", WithName("down"))
+
+	// Act
+	err := p.PreRun(context.Background())
+
+	// Assert
+	assert.ErrorContains(t, err, "failed to connect to database down")
+	assert.Nil(t, p.DB())
+	assert.ErrorContains(t, p.HealthCheck(context.Background()), "not connected")
+	assert.NoError(t, p.Shutdown(context.Background()))
+}
+
+func TestPool_UnknownDriver(t *testing.T) {
+	// Arrange
+	p := New("missing", "")
+
+	// Act
+	err := p.PreRun(context.Background())
+
+	// Assert
+	assert.ErrorContains(t, err, "failed to open database default")
+}
+
+func TestWithMetrics_SharedBetweenPools(t *testing.T) {
+	// Arrange
+	testDriver.down.Store(false)
+	first := New("dbtest", "", WithName("first"), WithMetrics("dbshared"))
+	second := New("dbtest", "", WithName("second"), WithMetrics("dbshared"))
+
+	// Act
+	assert.NotPanics(t, func() {
+		require.NoError(t, first.PreRun(context.Background()))
+		require.NoError(t, second.PreRun(context.Background()))
+	})
+
+	// Assert
+	assert.Same(t, statsCollector("dbshared"), statsCollector("dbshared"))
+	assert.Equal(t, 2, testutil.CollectAndCount(statsCollector("dbshared"), "dbshared_db_pool_max_open_connections"))
+	assert.NoError(t, first.Shutdown(context.Background()))
+	assert.NoError(t, second.Shutdown(context.Background()))
+}
@@ -0,0 +1,83 @@
+package db
+
+import (
+	"database/sql"
+	"sync"
+
+	"github.com/legrch/netgex/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// collector exports the statistics of the pools of a namespace when scraped
+type collector struct {
+	mu    sync.Mutex
+	pools map[string]*sql.DB
+
+	connections *prometheus.Desc
+	maxOpen     *prometheus.Desc
+	waits       *prometheus.Desc
+	waitTime    *prometheus.Desc
+	closed      *prometheus.Desc
+}
+
+// add starts exporting the statistics of a pool
+func (c *collector) add(name string, db *sql.DB) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pools[name] = db
+}
+
+// remove stops exporting the statistics of a pool
+func (c *collector) remove(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.pools, name)
+}
+
+// Describe implements prometheus.Collector
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.connections
+	ch <- c.maxOpen
+	ch <- c.waits
+	ch <- c.waitTime
+	ch <- c.closed
+}
+
+// Collect implements prometheus.Collector
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for name, db := range c.pools {
+		stats := db.Stats()
+		ch <- prometheus.MustNewConstMetric(c.connections, prometheus.GaugeValue, float64(stats.InUse), name, "in_use")
+		ch <- prometheus.MustNewConstMetric(c.connections, prometheus.GaugeValue, float64(stats.Idle), name, "idle")
+		ch <- prometheus.MustNewConstMetric(c.maxOpen, prometheus.GaugeValue, float64(stats.MaxOpenConnections), name)
+		ch <- prometheus.MustNewConstMetric(c.waits, prometheus.CounterValue, float64(stats.WaitCount), name)
+		ch <- prometheus.MustNewConstMetric(c.waitTime, prometheus.CounterValue, stats.WaitDuration.Seconds(), name)
+		ch <- prometheus.MustNewConstMetric(c.closed, prometheus.CounterValue,
+			float64(stats.MaxIdleClosed+stats.MaxIdleTimeClosed+stats.MaxLifetimeClosed), name)
+	}
+}
+
+// statsCollector returns the collector of the namespace, registering it unless already
+// registered
+func statsCollector(namespace string) *collector {
+	name := func(metric string) string {
+		return prometheus.BuildFQName(namespace, "db_pool", metric)
+	}
+	c := &collector{
+		pools: map[string]*sql.DB{},
+		connections: prometheus.NewDesc(name("connections"),
+			"Number of connections of the pool by state: in_use or idle", []string{"pool", "state"}, nil),
+		maxOpen: prometheus.NewDesc(name("max_open_connections"),
+			"Maximum number of open connections of the pool, 0 when unlimited", []string{"pool"}, nil),
+		waits: prometheus.NewDesc(name("waits_total"),
+			"Total number of times a query waited for a free connection", []string{"pool"}, nil),
+		waitTime: prometheus.NewDesc(name("wait_seconds_total"),
+			"Total time queries waited for a free connection in seconds", []string{"pool"}, nil),
+		closed: prometheus.NewDesc(name("closed_connections_total"),
+			"Total number of connections closed for being idle or too old", []string{"pool"}, nil),
+	}
+	return metrics.Register(c)
+}
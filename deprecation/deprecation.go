@@ -0,0 +1,231 @@
+// Package deprecation signals deprecated gRPC methods and HTTP routes to clients. Calls
+// to them get Deprecation (RFC 9745), Sunset (RFC 8594) and Link headers, as gRPC
+// response metadata and as HTTP headers through the gateway, and are counted so
+// remaining callers can be tracked down before the endpoint is removed.
+package deprecation
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/legrch/netgex/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Header names set on deprecated calls
+const (
+	HeaderDeprecation = "Deprecation"
+	HeaderSunset      = "Sunset"
+	HeaderLink        = "Link"
+)
+
+// Deprecation describes when an endpoint was deprecated and when it goes away
+type Deprecation struct {
+	// Since is when the endpoint was deprecated; zero sends "Deprecation: true"
+	Since time.Time
+	// Sunset is when the endpoint stops being served; zero omits the Sunset header
+	Sunset time.Time
+	// Link points to the migration guide, sent as Link with rel="deprecation"
+	Link string
+}
+
+// headers returns the headers announcing the deprecation
+func (d Deprecation) headers() map[string]string {
+	headers := map[string]string{HeaderDeprecation: "true"}
+	if !d.Since.IsZero() {
+		headers[HeaderDeprecation] = "@" + strconv.FormatInt(d.Since.Unix(), 10)
+	}
+	if !d.Sunset.IsZero() {
+		headers[HeaderSunset] = d.Sunset.UTC().Format(http.TimeFormat)
+	}
+	if d.Link != "" {
+		headers[HeaderLink] = "<" + d.Link + `>; rel="deprecation"`
+	}
+	return headers
+}
+
+// Option configures a Policy
+type Option func(*Policy)
+
+// WithMethod deprecates a gRPC method, e.g. /orders.v1.Orders/Get
+func WithMethod(method string, d Deprecation) Option {
+	return func(p *Policy) {
+		p.methods[method] = d
+	}
+}
+
+// WithRoute deprecates the HTTP routes starting with prefix, including routes that are
+// not backed by gRPC methods
+func WithRoute(prefix string, d Deprecation) Option {
+	return func(p *Policy) {
+		p.routes = append(p.routes, route{prefix: prefix, deprecation: d})
+	}
+}
+
+// WithMetrics counts calls to deprecated endpoints in the deprecated_calls_total metric
+// of the namespace
+func WithMetrics(namespace string) Option {
+	return func(p *Policy) {
+		p.calls = callsCounter(namespace)
+	}
+}
+
+// route is a deprecated HTTP route prefix
+type route struct {
+	prefix      string
+	deprecation Deprecation
+}
+
+// Policy knows the deprecated endpoints: methods and routes configured with options,
+// and methods, services or files marked with the standard deprecated proto option
+type Policy struct {
+	methods map[string]Deprecation
+	routes  []route
+	calls   *prometheus.CounterVec
+	// proto caches the deprecated proto option of methods by name
+	proto sync.Map
+}
+
+// New creates a policy from opts
+func New(opts ...Option) *Policy {
+	p := &Policy{methods: map[string]Deprecation{}}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Method returns the deprecation of a gRPC method, reporting whether it is deprecated
+func (p *Policy) Method(method string) (Deprecation, bool) {
+	if d, ok := p.methods[method]; ok {
+		return d, true
+	}
+	if deprecated, ok := p.proto.Load(method); ok {
+		return Deprecation{}, deprecated.(bool)
+	}
+	deprecated := protoDeprecated(method)
+	p.proto.Store(method, deprecated)
+	return Deprecation{}, deprecated
+}
+
+// protoDeprecated reports whether the method, its service or its file has the
+// deprecated option in the registered proto descriptors
+func protoDeprecated(method string) bool {
+	name := protoreflect.FullName(strings.ReplaceAll(strings.TrimPrefix(method, "/"), "/", "."))
+	desc, err := protoregistry.GlobalFiles.FindDescriptorByName(name)
+	if err != nil {
+		return false
+	}
+	md, ok := desc.(protoreflect.MethodDescriptor)
+	if !ok {
+		return false
+	}
+	if opts, ok := md.Options().(*descriptorpb.MethodOptions); ok && opts.GetDeprecated() {
+		return true
+	}
+	if opts, ok := md.Parent().Options().(*descriptorpb.ServiceOptions); ok && opts.GetDeprecated() {
+		return true
+	}
+	opts, ok := md.ParentFile().Options().(*descriptorpb.FileOptions)
+	return ok && opts.GetDeprecated()
+}
+
+// count records a call to a deprecated endpoint
+func (p *Policy) count(endpoint string) {
+	if p.calls != nil {
+		p.calls.WithLabelValues(endpoint).Inc()
+	}
+}
+
+// signal sends the deprecation headers of a deprecated method as response metadata
+func (p *Policy) signal(ctx context.Context, method string) {
+	d, ok := p.Method(method)
+	if !ok {
+		return
+	}
+	p.count(method)
+	md := metadata.MD{}
+	for name, value := range d.headers() {
+		md.Set(name, value)
+	}
+	_ = grpc.SetHeader(ctx, md)
+}
+
+// UnaryServerInterceptor signals deprecated methods in the response metadata
+func (p *Policy) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		p.signal(ctx, info.FullMethod)
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor signals deprecated methods in the response metadata
+func (p *Policy) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		p.signal(ss.Context(), info.FullMethod)
+		return handler(srv, ss)
+	}
+}
+
+// ForwardResponseOption copies the deprecation metadata of gRPC responses to the HTTP
+// headers of the gateway, for use with runtime.WithForwardResponseOption
+func (p *Policy) ForwardResponseOption(ctx context.Context, w http.ResponseWriter, _ proto.Message) error {
+	md, ok := runtime.ServerMetadataFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	for _, name := range []string{HeaderDeprecation, HeaderSunset, HeaderLink} {
+		if values := md.HeaderMD.Get(name); len(values) > 0 {
+			w.Header().Set(name, values[0])
+		}
+	}
+	return nil
+}
+
+// Middleware signals the deprecated HTTP routes; the longest matching prefix wins
+func (p *Policy) Middleware(next http.Handler) http.Handler {
+	if len(p.routes) == 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		match := -1
+		for i, rt := range p.routes {
+			if strings.HasPrefix(r.URL.Path, rt.prefix) && (match < 0 || len(rt.prefix) > len(p.routes[match].prefix)) {
+				match = i
+			}
+		}
+		if match >= 0 {
+			rt := p.routes[match]
+			p.count(rt.prefix)
+			for name, value := range rt.deprecation.headers() {
+				w.Header().Set(name, value)
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// callsCounter returns the deprecated calls counter of the namespace, registering it
+// unless already registered
+func callsCounter(namespace string) *prometheus.CounterVec {
+	counter := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "deprecated_calls_total",
+			Help:      "Total number of calls to deprecated gRPC methods and HTTP routes",
+		},
+		[]string{"endpoint"},
+	)
+	return metrics.Register(counter)
+}
@@ -0,0 +1,160 @@
+package deprecation
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+
+	_ "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+)
+
+func TestPolicy_Method(t *testing.T) {
+	sunset := time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC)
+	p := New(WithMethod("/orders.v1.Orders/Get", Deprecation{Sunset: sunset}))
+
+	tests := []struct {
+		method string
+		want   bool
+	}{
+		{method: "/orders.v1.Orders/Get", want: true},
+		{method: "/orders.v1.Orders/List", want: false},
+		{method: "/grpc.health.v1.Health/Check", want: false},
+		// The v1alpha reflection proto file is marked deprecated
+		{method: "/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			// Act
+			_, got := p.Method(tt.method)
+
+			// Assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDeprecation_Headers(t *testing.T) {
+	// Arrange
+	d := Deprecation{
+		Since:  time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Sunset: time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC),
+		Link:   "https://example.com/migrate",
+	}
+
+	// Act
+	headers := d.headers()
+	bare := Deprecation{}.headers()
+
+	// Assert
+	assert.Equal(t, map[string]string{
+		HeaderDeprecation: "@1735689600",
+		HeaderSunset:      "Tue, 30 Jun 2026 00:00:00 GMT",
+		HeaderLink:        `<https://example.com/migrate>; rel="deprecation"`,
+	}, headers)
+	assert.Equal(t, map[string]string{HeaderDeprecation: "true"}, bare)
+}
+
+func TestPolicy_UnaryServerInterceptor(t *testing.T) {
+	// Arrange
+	p := New(
+		WithMethod("/grpc.health.v1.Health/Check", Deprecation{Sunset: time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC)}),
+		WithMetrics("deprecationtest"),
+	)
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(grpc.UnaryInterceptor(p.UnaryServerInterceptor()))
+	grpc_health_v1.RegisterHealthServer(srv, health.NewServer())
+	go func() { _ = srv.Serve(lis) }()
+	defer srv.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// Act
+	var header metadata.MD
+	_, err = grpc_health_v1.NewHealthClient(conn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{}, grpc.Header(&header))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"true"}, header.Get(HeaderDeprecation))
+	assert.Equal(t, []string{"Tue, 30 Jun 2026 00:00:00 GMT"}, header.Get(HeaderSunset))
+	assert.Equal(t, 1.0, testutil.ToFloat64(callsCounter("deprecationtest").WithLabelValues("/grpc.health.v1.Health/Check")))
+}
+
+func TestPolicy_ForwardResponseOption(t *testing.T) {
+	// Arrange
+	p := New()
+	ctx := runtime.NewServerMetadataContext(context.Background(), runtime.ServerMetadata{
+		HeaderMD: metadata.Pairs("deprecation", "true", "sunset", "Tue, 30 Jun 2026 00:00:00 GMT"),
+	})
+	rec := httptest.NewRecorder()
+
+	// Act
+	err := p.ForwardResponseOption(ctx, rec, nil)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "true", rec.Header().Get(HeaderDeprecation))
+	assert.Equal(t, "Tue, 30 Jun 2026 00:00:00 GMT", rec.Header().Get(HeaderSunset))
+}
+
+func TestPolicy_Middleware(t *testing.T) {
+	// Arrange
+	p := New(
+		WithRoute("/v1/", Deprecation{}),
+		WithRoute("/v1/legacy", Deprecation{Link: "https://example.com/v2"}),
+	)
+	handler := p.Middleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+
+	tests := []struct {
+		path     string
+		wantDep  string
+		wantLink string
+	}{
+		{path: "/v1/items", wantDep: "true"},
+		{path: "/v1/legacy/items", wantDep: "true", wantLink: `<https://example.com/v2>; rel="deprecation"`},
+		{path: "/v2/items"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			// Act
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			// Assert
+			assert.Equal(t, tt.wantDep, rec.Header().Get(HeaderDeprecation))
+			assert.Equal(t, tt.wantLink, rec.Header().Get(HeaderLink))
+		})
+	}
+}
+
+func TestWithMetrics_SharedBetweenPolicies(t *testing.T) {
+	// Act
+	var first, second *Policy
+	assert.NotPanics(t, func() {
+		first = New(WithMetrics("deprecationshared"))
+		second = New(WithMetrics("deprecationshared"))
+	})
+
+	// Assert
+	assert.Same(t, first.calls, second.calls)
+}
@@ -0,0 +1,99 @@
+// Package errreport decouples error tracking from its vendor. Recovery interceptors and
+// the server's process supervisor hand panics and failures to an ErrorReporter, which
+// logs them by default or sends them to a service such as Sentry or Rollbar.
+package errreport
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+)
+
+// Report is a failure to report
+type Report struct {
+	// Err is the failure; for panics it describes the panic value
+	Err error
+	// Panic is the recovered value, nil when the failure is an error
+	Panic any
+	// Stack holds the program counters of the panicking goroutine, innermost first
+	Stack []uintptr
+	// Tags describe where the failure happened, such as method, request_id or process
+	Tags map[string]string
+}
+
+// ErrorReporter sends reports to an error tracker. Report must not block the caller
+// for long; trackers reached over the network queue reports and send them in the
+// background.
+type ErrorReporter interface {
+	Report(ctx context.Context, r Report)
+}
+
+// Panic builds the report of a recovered panic, capturing the stack of the caller. It
+// must be called from the deferred function that recovered p.
+func Panic(p any, tags map[string]string) Report {
+	// Skip runtime.Callers, Panic, the deferred function and runtime.gopanic
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(4, pcs)
+	err, ok := p.(error)
+	if !ok {
+		err = fmt.Errorf("panic: %v", p)
+	}
+	return Report{Err: err, Panic: p, Stack: pcs[:n], Tags: tags}
+}
+
+// Multi sends reports to every reporter
+func Multi(reporters ...ErrorReporter) ErrorReporter {
+	return multi(reporters)
+}
+
+type multi []ErrorReporter
+
+func (m multi) Report(ctx context.Context, r Report) {
+	for _, reporter := range m {
+		reporter.Report(ctx, r)
+	}
+}
+
+// logReporter logs reports
+type logReporter struct {
+	logger *slog.Logger
+}
+
+// NewLogReporter returns the default reporter, logging reports at error level with their
+// tags and, for panics, the stack
+func NewLogReporter(logger *slog.Logger) ErrorReporter {
+	return &logReporter{logger: logger}
+}
+
+func (l *logReporter) Report(ctx context.Context, r Report) {
+	args := make([]any, 0, 2*len(r.Tags)+4)
+	for k, v := range r.Tags {
+		args = append(args, k, v)
+	}
+	if r.Panic != nil {
+		args = append(args, "panic", r.Panic, "stack", FormatStack(r.Stack))
+		l.logger.ErrorContext(ctx, "panic recovered", args...)
+		return
+	}
+	args = append(args, "error", r.Err)
+	l.logger.ErrorContext(ctx, "error reported", args...)
+}
+
+// FormatStack formats program counters like a goroutine trace, one function and its
+// location per frame
+func FormatStack(stack []uintptr) string {
+	if len(stack) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	frames := runtime.CallersFrames(stack)
+	for {
+		f, more := frames.Next()
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", f.Function, f.File, f.Line)
+		if !more {
+			return b.String()
+		}
+	}
+}
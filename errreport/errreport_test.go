@@ -0,0 +1,123 @@
+package errreport
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingReporter records the reports it receives
+type recordingReporter struct {
+	reports []Report
+}
+
+func (r *recordingReporter) Report(_ context.Context, report Report) {
+	r.reports = append(r.reports, report)
+}
+
+// recovered recovers a panic the way recovery interceptors do
+func recovered() (r Report) {
+	defer func() {
+		r = Panic(recover(), map[string]string{"method": "/svc/Method"})
+	}()
+	panic("boom")
+}
+
+func TestPanic(t *testing.T) {
+	// Act
+	r := recovered()
+
+	// Assert
+	assert.Equal(t, "boom", r.Panic)
+	assert.EqualError(t, r.Err, "panic: boom")
+	assert.Contains(t, FormatStack(r.Stack[:1]), "errreport.recovered", "the panicking frame is first")
+}
+
+func TestNewLogReporter(t *testing.T) {
+	tests := []struct {
+		name   string
+		report Report
+		want   []string
+	}{
+		{
+			name:   "error",
+			report: Report{Err: errors.New("disk full"), Tags: map[string]string{"process": "indexer"}},
+			want:   []string{`msg="error reported"`, "process=indexer", `error="disk full"`},
+		},
+		{
+			name:   "panic",
+			report: recovered(),
+			want:   []string{`msg="panic recovered"`, "method=/svc/Method", "panic=boom", "errreport.recovered"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var logs bytes.Buffer
+			reporter := NewLogReporter(slog.New(slog.NewTextHandler(&logs, nil)))
+
+			// Act
+			reporter.Report(context.Background(), tt.report)
+
+			// Assert
+			for _, want := range tt.want {
+				assert.Contains(t, logs.String(), want)
+			}
+		})
+	}
+}
+
+func TestMulti(t *testing.T) {
+	// Arrange
+	first, second := &recordingReporter{}, &recordingReporter{}
+	report := Report{Err: errors.New("disk full")}
+
+	// Act
+	Multi(first, second).Report(context.Background(), report)
+
+	// Assert
+	assert.Equal(t, []Report{report}, first.reports)
+	assert.Equal(t, []Report{report}, second.reports)
+}
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		panic    any
+		reported bool
+	}{
+		{name: "panic", panic: "template missing", reported: true},
+		{name: "aborted response", panic: http.ErrAbortHandler},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			reporter := &recordingReporter{}
+			handler := Middleware(reporter)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+				panic(tt.panic)
+			}))
+
+			// Act
+			assert.PanicsWithValue(t, tt.panic, func() {
+				handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/orders", nil))
+			}, "panics are re-raised for net/http")
+
+			// Assert
+			if !tt.reported {
+				assert.Empty(t, reporter.reports)
+				return
+			}
+			require.Len(t, reporter.reports, 1)
+			assert.Equal(t, "GET /v1/orders", reporter.reports[0].Tags["route"])
+		})
+	}
+}
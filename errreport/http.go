@@ -0,0 +1,23 @@
+package errreport
+
+import (
+	"net/http"
+)
+
+// Middleware reports panics of HTTP handlers, such as gateway routes registered with
+// HandlePath, and re-raises them for net/http to abort the response
+func Middleware(reporter ErrorReporter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				if p := recover(); p != nil {
+					if p != http.ErrAbortHandler {
+						reporter.Report(r.Context(), Panic(p, map[string]string{"route": r.Method + " " + r.URL.Path}))
+					}
+					panic(p)
+				}
+			}()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
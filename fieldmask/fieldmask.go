@@ -0,0 +1,215 @@
+// Package fieldmask validates and applies google.protobuf.FieldMask values, so partial
+// reads and updates behave the same in every service. Read masks prune responses to the
+// requested fields, update masks select the fields a partial update changes. The unary
+// interceptor does both from the read_mask and update_mask request fields, and the query
+// parser lets gateway clients pass masks with JSON field names.
+package fieldmask
+
+import (
+	"net/url"
+	"strings"
+	"unicode"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/utilities"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// fieldMaskName is the full name of the FieldMask message
+const fieldMaskName protoreflect.FullName = "google.protobuf.FieldMask"
+
+// Validate checks that every path of mask names a field of msg, returning an
+// InvalidArgument error for the first path that does not. Repeated and map fields may
+// only end a path.
+func Validate(mask *fieldmaskpb.FieldMask, msg proto.Message) error {
+	return validate(mask.GetPaths(), msg.ProtoReflect().Descriptor())
+}
+
+// validate checks the paths against a message descriptor
+func validate(paths []string, desc protoreflect.MessageDescriptor) error {
+	for _, path := range paths {
+		md := desc
+		segments := strings.Split(path, ".")
+		for i, segment := range segments {
+			var fd protoreflect.FieldDescriptor
+			if md != nil {
+				fd = md.Fields().ByName(protoreflect.Name(segment))
+			}
+			if fd == nil {
+				return status.Errorf(codes.InvalidArgument, "invalid field mask path %q for %s", path, desc.FullName())
+			}
+			md = nil
+			if i < len(segments)-1 && !fd.IsList() && !fd.IsMap() {
+				md = fd.Message()
+			}
+		}
+	}
+	return nil
+}
+
+// Normalize trims the paths of mask, drops empty ones and turns JSON field names such as
+// displayName into proto names such as display_name, as gateway query parameters carry
+// whatever the client typed
+func Normalize(mask *fieldmaskpb.FieldMask) {
+	if mask != nil {
+		mask.Paths = normalize(mask.Paths)
+	}
+}
+
+// normalize returns the normalized paths
+func normalize(paths []string) []string {
+	normalized := make([]string, 0, len(paths))
+	for _, path := range paths {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		segments := strings.Split(path, ".")
+		for i, segment := range segments {
+			segments[i] = snakeCase(segment)
+		}
+		normalized = append(normalized, strings.Join(segments, "."))
+	}
+	return normalized
+}
+
+// snakeCase turns a lowerCamelCase JSON name into its proto field name
+func snakeCase(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if unicode.IsUpper(r) {
+			b.WriteByte('_')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// node is a tree of mask paths; a nil node selects the whole field
+type node map[string]node
+
+// tree builds the tree of paths, where a path selects its whole field even when longer
+// paths below it are listed too
+func tree(paths []string) node {
+	root := node{}
+	for _, path := range paths {
+		n := root
+		segments := strings.Split(path, ".")
+		for i, segment := range segments {
+			child, ok := n[segment]
+			if ok && child == nil {
+				break
+			}
+			if i == len(segments)-1 {
+				n[segment] = nil
+				break
+			}
+			if !ok {
+				child = node{}
+				n[segment] = child
+			}
+			n = child
+		}
+	}
+	return root
+}
+
+// Apply clears every field of msg outside mask, answering a read with only the
+// requested fields. An empty mask leaves msg untouched.
+func Apply(mask *fieldmaskpb.FieldMask, msg proto.Message) {
+	if len(mask.GetPaths()) == 0 {
+		return
+	}
+	prune(msg.ProtoReflect(), tree(mask.GetPaths()))
+}
+
+// prune clears the fields of m outside n
+func prune(m protoreflect.Message, n node) {
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		child, ok := n[string(fd.Name())]
+		switch {
+		case !ok:
+			m.Clear(fd)
+		case child != nil && fd.Message() != nil && !fd.IsList() && !fd.IsMap():
+			prune(v.Message(), child)
+		}
+		return true
+	})
+}
+
+// Merge copies the fields of mask from src to dst, clearing those unset in src, which
+// is how a partial update applies to the stored resource. An empty mask merges every
+// populated field of src with proto.Merge. Both messages must be of the same type.
+func Merge(dst, src proto.Message, mask *fieldmaskpb.FieldMask) error {
+	if dst.ProtoReflect().Descriptor().FullName() != src.ProtoReflect().Descriptor().FullName() {
+		return status.Errorf(codes.InvalidArgument, "cannot merge %s into %s",
+			src.ProtoReflect().Descriptor().FullName(), dst.ProtoReflect().Descriptor().FullName())
+	}
+	if len(mask.GetPaths()) == 0 {
+		proto.Merge(dst, src)
+		return nil
+	}
+	if err := Validate(mask, dst); err != nil {
+		return err
+	}
+	// Copied values are shared with the clone only, never with the caller's src
+	merge(dst.ProtoReflect(), proto.Clone(src).ProtoReflect(), tree(mask.GetPaths()))
+	return nil
+}
+
+// merge copies the fields of n from src to dst
+func merge(dst, src protoreflect.Message, n node) {
+	fields := dst.Descriptor().Fields()
+	for name, child := range n {
+		fd := fields.ByName(protoreflect.Name(name))
+		if child == nil || fd.Message() == nil || fd.IsList() || fd.IsMap() {
+			if src.Has(fd) {
+				dst.Set(fd, src.Get(fd))
+			} else {
+				dst.Clear(fd)
+			}
+			continue
+		}
+		if !src.Has(fd) && !dst.Has(fd) {
+			continue
+		}
+		merge(dst.Mutable(fd).Message(), src.Get(fd).Message(), child)
+	}
+}
+
+// QueryParameterParser parses gateway query parameters like runtime.DefaultQueryParser,
+// then normalizes the field masks of the request, so ?read_mask=id,displayName works.
+// Install it with runtime.SetQueryParameterParser.
+type QueryParameterParser struct{}
+
+// Parse populates msg from the query values and normalizes its field masks
+func (QueryParameterParser) Parse(msg proto.Message, values url.Values, filter *utilities.DoubleArray) error {
+	if err := (&runtime.DefaultQueryParser{}).Parse(msg, values, filter); err != nil {
+		return err
+	}
+	m := msg.ProtoReflect()
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.Message() == nil || fd.Message().FullName() != fieldMaskName || fd.IsList() {
+			return true
+		}
+		mask := v.Message()
+		pathsField := mask.Descriptor().Fields().ByName("paths")
+		list := mask.Get(pathsField).List()
+		paths := make([]string, list.Len())
+		for i := range paths {
+			paths[i] = list.Get(i).String()
+		}
+		normalized := mask.NewField(pathsField).List()
+		for _, path := range normalize(paths) {
+			normalized.Append(protoreflect.ValueOfString(path))
+		}
+		mask.Set(pathsField, protoreflect.ValueOfList(normalized))
+		return true
+	})
+	return nil
+}
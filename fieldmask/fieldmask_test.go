@@ -0,0 +1,245 @@
+package fieldmask
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/utilities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// file returns a populated descriptor proto to mask
+func file() *descriptorpb.FileDescriptorProto {
+	return &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("orders.proto"),
+		Package: proto.String("orders.v1"),
+		Options: &descriptorpb.FileOptions{
+			GoPackage:   proto.String("example.com/orders"),
+			JavaPackage: proto.String("com.example.orders"),
+		},
+		Dependency: []string{"google/protobuf/field_mask.proto"},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		paths   []string
+		wantErr bool
+	}{
+		{name: "fields", paths: []string{"name", "options.go_package"}},
+		{name: "repeated field last", paths: []string{"message_type"}},
+		{name: "unknown field", paths: []string{"title"}, wantErr: true},
+		{name: "below scalar", paths: []string{"name.value"}, wantErr: true},
+		{name: "below repeated field", paths: []string{"message_type.name"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			err := Validate(&fieldmaskpb.FieldMask{Paths: tt.paths}, file())
+
+			// Assert
+			if tt.wantErr {
+				assert.Equal(t, codes.InvalidArgument, status.Code(err))
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	// Arrange
+	mask := &fieldmaskpb.FieldMask{Paths: []string{"displayName", " options.goPackage ", "", "name"}}
+
+	// Act
+	Normalize(mask)
+
+	// Assert
+	assert.Equal(t, []string{"display_name", "options.go_package", "name"}, mask.Paths)
+}
+
+func TestApply(t *testing.T) {
+	// Arrange
+	msg := file()
+
+	// Act
+	Apply(&fieldmaskpb.FieldMask{Paths: []string{"name", "options.go_package"}}, msg)
+
+	// Assert
+	assert.True(t, proto.Equal(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("orders.proto"),
+		Options: &descriptorpb.FileOptions{GoPackage: proto.String("example.com/orders")},
+	}, msg))
+}
+
+func TestApply_WholeFieldWins(t *testing.T) {
+	// Arrange
+	msg := file()
+
+	// Act
+	Apply(&fieldmaskpb.FieldMask{Paths: []string{"options.go_package", "options"}}, msg)
+
+	// Assert
+	assert.Empty(t, msg.GetName())
+	assert.Equal(t, "com.example.orders", msg.GetOptions().GetJavaPackage())
+}
+
+func TestMerge(t *testing.T) {
+	// Arrange
+	dst := file()
+	src := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("orders_v2.proto"),
+		Package:    proto.String("orders.v2"),
+		Options:    &descriptorpb.FileOptions{GoPackage: proto.String("example.com/orders/v2")},
+		Dependency: []string{"google/protobuf/timestamp.proto"},
+	}
+
+	// Act
+	err := Merge(dst, src, &fieldmaskpb.FieldMask{Paths: []string{"name", "options.go_package", "options.java_package", "dependency"}})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "orders_v2.proto", dst.GetName())
+	assert.Equal(t, "orders.v1", dst.GetPackage())
+	assert.Equal(t, "example.com/orders/v2", dst.GetOptions().GetGoPackage())
+	assert.Nil(t, dst.GetOptions().JavaPackage)
+	assert.Equal(t, []string{"google/protobuf/timestamp.proto"}, dst.GetDependency())
+
+	src.Dependency[0] = "changed.proto"
+	assert.Equal(t, []string{"google/protobuf/timestamp.proto"}, dst.GetDependency())
+}
+
+func TestMerge_InvalidMask(t *testing.T) {
+	// Act
+	err := Merge(file(), file(), &fieldmaskpb.FieldMask{Paths: []string{"title"}})
+
+	// Assert
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestQueryParameterParser(t *testing.T) {
+	// Arrange
+	msg := dynamicpb.NewMessage(requestTypes(t).update)
+	values := url.Values{"update_mask": {"title, author.displayName"}}
+
+	// Act
+	err := QueryParameterParser{}.Parse(msg, values, utilities.NewDoubleArray(nil))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"title", "author.display_name"}, maskPaths(msg, "update_mask"))
+}
+
+// types are the dynamic message types of the interceptor tests
+type types struct {
+	book, update, get protoreflect.MessageDescriptor
+}
+
+// requestTypes builds a books service with update and get requests carrying masks
+func requestTypes(t *testing.T) types {
+	t.Helper()
+	message := func(name string, fields ...*descriptorpb.FieldDescriptorProto) *descriptorpb.DescriptorProto {
+		return &descriptorpb.DescriptorProto{Name: proto.String(name), Field: fields}
+	}
+	field := func(name string, number int32, typeName string) *descriptorpb.FieldDescriptorProto {
+		fd := &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(number),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+		}
+		if typeName != "" {
+			fd.Type = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+			fd.TypeName = proto.String(typeName)
+		}
+		return fd
+	}
+
+	fd, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:       proto.String("fieldmasktest/books.proto"),
+		Package:    proto.String("fieldmasktest"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/protobuf/field_mask.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{
+			message("Author", field("display_name", 1, "")),
+			message("Book", field("name", 1, ""), field("title", 2, ""), field("author", 3, ".fieldmasktest.Author")),
+			message("UpdateBookRequest", field("book", 1, ".fieldmasktest.Book"), field("update_mask", 2, ".google.protobuf.FieldMask")),
+			message("GetBookRequest", field("name", 1, ""), field("read_mask", 2, ".google.protobuf.FieldMask")),
+		},
+	}, protoregistry.GlobalFiles)
+	require.NoError(t, err)
+	messages := fd.Messages()
+	return types{book: messages.ByName("Book"), update: messages.ByName("UpdateBookRequest"), get: messages.ByName("GetBookRequest")}
+}
+
+// withMask returns a request of desc with the mask paths set in the named field
+func withMask(desc protoreflect.MessageDescriptor, name string, paths ...string) *dynamicpb.Message {
+	msg := dynamicpb.NewMessage(desc)
+	fd := desc.Fields().ByName(protoreflect.Name(name))
+	mask := msg.Mutable(fd).Message()
+	list := mask.Mutable(mask.Descriptor().Fields().ByName("paths")).List()
+	for _, path := range paths {
+		list.Append(protoreflect.ValueOfString(path))
+	}
+	return msg
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	types := requestTypes(t)
+	book := dynamicpb.NewMessage(types.book)
+	book.Set(types.book.Fields().ByName("name"), protoreflect.ValueOfString("books/1"))
+	book.Set(types.book.Fields().ByName("title"), protoreflect.ValueOfString("Dune"))
+	handler := func(context.Context, any) (any, error) { return book, nil }
+	info := &grpc.UnaryServerInfo{FullMethod: "/fieldmasktest.Books/GetBook"}
+
+	tests := []struct {
+		name      string
+		req       proto.Message
+		wantCode  codes.Code
+		wantField string
+		wantTitle bool
+	}{
+		{name: "valid update mask", req: withMask(types.update, "update_mask", "title", "author.display_name"), wantTitle: true},
+		{name: "invalid update mask", req: withMask(types.update, "update_mask", "isbn"), wantCode: codes.InvalidArgument, wantField: "update_mask"},
+		{name: "read mask", req: withMask(types.get, "read_mask", "name")},
+		{name: "no mask", req: dynamicpb.NewMessage(types.get), wantTitle: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			resp, err := UnaryServerInterceptor()(context.Background(), tt.req, info, handler)
+
+			// Assert
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if err != nil {
+				details := status.Convert(err).Details()
+				require.Len(t, details, 1)
+				violations := details[0].(*errdetails.BadRequest).GetFieldViolations()
+				require.Len(t, violations, 1)
+				assert.Equal(t, tt.wantField, violations[0].GetField())
+				return
+			}
+			got := resp.(proto.Message).ProtoReflect()
+			assert.Equal(t, "books/1", got.Get(types.book.Fields().ByName("name")).String())
+			assert.Equal(t, tt.wantTitle, got.Has(types.book.Fields().ByName("title")))
+			assert.True(t, book.Has(types.book.Fields().ByName("title")), "handler response must not be pruned")
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package fieldmask
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// Option configures the interceptor
+type Option func(*config)
+
+// WithReadMaskField sets the request field holding the read mask, read_mask by default
+func WithReadMaskField(name string) Option {
+	return func(c *config) {
+		c.readMask = protoreflect.Name(name)
+	}
+}
+
+// WithUpdateMaskField sets the request field holding the update mask, update_mask by
+// default
+func WithUpdateMaskField(name string) Option {
+	return func(c *config) {
+		c.updateMask = protoreflect.Name(name)
+	}
+}
+
+// config is the configuration of the interceptor
+type config struct {
+	readMask   protoreflect.Name
+	updateMask protoreflect.Name
+}
+
+// UnaryServerInterceptor validates the field masks of requests and applies read masks to
+// responses. An update mask is validated against the resource it updates, the only other
+// message field of the request; a read mask against the response type of the method.
+// Invalid masks are rejected with InvalidArgument and a google.rpc.BadRequest detail
+// naming the mask field. Responses are cloned before pruning,
+// so handlers may return shared messages.
+func UnaryServerInterceptor(opts ...Option) grpc.UnaryServerInterceptor {
+	c := &config{readMask: "read_mask", updateMask: "update_mask"}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		msg, ok := req.(proto.Message)
+		if !ok {
+			return handler(ctx, req)
+		}
+		m := msg.ProtoReflect()
+
+		if paths := maskPaths(m, c.updateMask); len(paths) > 0 {
+			if resource := resourceField(m); resource != nil {
+				if err := validate(paths, resource); err != nil {
+					return nil, maskViolation(err, c.updateMask)
+				}
+			}
+		}
+
+		readPaths := maskPaths(m, c.readMask)
+		if len(readPaths) == 0 {
+			return handler(ctx, req)
+		}
+		if output := outputType(info.FullMethod); output != nil {
+			if err := validate(readPaths, output); err != nil {
+				return nil, maskViolation(err, c.readMask)
+			}
+		}
+
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, err
+		}
+		out, ok := resp.(proto.Message)
+		if !ok {
+			return resp, nil
+		}
+		out = proto.Clone(out)
+		Apply(&fieldmaskpb.FieldMask{Paths: readPaths}, out)
+		return out, nil
+	}
+}
+
+// maskViolation adds a google.rpc.BadRequest detail naming the mask field to an invalid
+// mask error, so gateway clients can tell which mask was rejected
+func maskViolation(err error, field protoreflect.Name) error {
+	st := status.Convert(err)
+	withDetails, derr := st.WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: string(field), Description: st.Message()}},
+	})
+	if derr != nil {
+		return err
+	}
+	return withDetails.Err()
+}
+
+// maskPaths returns the paths of the FieldMask held by the named field of m, read
+// through reflection so dynamic messages work too
+func maskPaths(m protoreflect.Message, name protoreflect.Name) []string {
+	fd := m.Descriptor().Fields().ByName(name)
+	if fd == nil || fd.Message() == nil || fd.Message().FullName() != fieldMaskName || fd.IsList() || !m.Has(fd) {
+		return nil
+	}
+	mask := m.Get(fd).Message()
+	list := mask.Get(mask.Descriptor().Fields().ByName("paths")).List()
+	paths := make([]string, list.Len())
+	for i := range paths {
+		paths[i] = list.Get(i).String()
+	}
+	return paths
+}
+
+// resourceField returns the message type of the only singular message field of m that
+// is not a field mask, or nil when there is none or several
+func resourceField(m protoreflect.Message) protoreflect.MessageDescriptor {
+	var resource protoreflect.MessageDescriptor
+	fields := m.Descriptor().Fields()
+	for i := range fields.Len() {
+		fd := fields.Get(i)
+		if fd.Message() == nil || fd.IsList() || fd.IsMap() || fd.Message().FullName() == fieldMaskName {
+			continue
+		}
+		if resource != nil {
+			return nil
+		}
+		resource = fd.Message()
+	}
+	return resource
+}
+
+// outputType returns the response type of a registered method, e.g.
+// /orders.v1.Orders/Get, or nil
+func outputType(method string) protoreflect.MessageDescriptor {
+	name := protoreflect.FullName(strings.ReplaceAll(strings.TrimPrefix(method, "/"), "/", "."))
+	desc, err := protoregistry.GlobalFiles.FindDescriptorByName(name)
+	if err != nil {
+		return nil
+	}
+	md, ok := desc.(protoreflect.MethodDescriptor)
+	if !ok {
+		return nil
+	}
+	return md.Output()
+}
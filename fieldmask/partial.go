@@ -0,0 +1,76 @@
+package fieldmask
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// DefaultFieldsParam is the query parameter listing the fields of partial responses
+const DefaultFieldsParam = "fields"
+
+// fieldsKey is the key under which the requested fields are stored in a context
+type fieldsKey struct{}
+
+// PartialResponse prunes the responses of gateway GET requests to the fields listed in a
+// query parameter, such as ?fields=name,author.displayName, so clients fetch only what
+// they show without changes to the protos. Paths follow FieldMask semantics and take
+// JSON or proto field names; paths the response does not have are rejected with
+// InvalidArgument. Responses are pruned in place, so handlers registered in process
+// with RegisterXxxHandlerServer must not return shared messages.
+type PartialResponse struct {
+	param string
+}
+
+// NewPartialResponse creates a partial response filter reading the paths from param,
+// DefaultFieldsParam when empty
+func NewPartialResponse(param string) *PartialResponse {
+	if param == "" {
+		param = DefaultFieldsParam
+	}
+	return &PartialResponse{param: param}
+}
+
+// Middleware takes the parameter off GET requests, so it does not reach the request
+// message, and keeps its paths for ForwardResponseOption. Repeated parameters add up.
+func (p *PartialResponse) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+		query := r.URL.Query()
+		values, ok := query[p.param]
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var paths []string
+		for _, v := range values {
+			paths = append(paths, strings.Split(v, ",")...)
+		}
+		query.Del(p.param)
+		r = r.Clone(context.WithValue(r.Context(), fieldsKey{}, normalize(paths)))
+		r.URL.RawQuery = query.Encode()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ForwardResponseOption prunes the response to the requested fields before it is
+// marshaled, for use with runtime.WithForwardResponseOption
+func (p *PartialResponse) ForwardResponseOption(ctx context.Context, _ http.ResponseWriter, msg proto.Message) error {
+	paths, _ := ctx.Value(fieldsKey{}).([]string)
+	if len(paths) == 0 {
+		return nil
+	}
+	m := msg.ProtoReflect()
+	if err := validate(paths, m.Descriptor()); err != nil {
+		return maskViolation(err, protoreflect.Name(p.param))
+	}
+	prune(m, tree(paths))
+	return nil
+}
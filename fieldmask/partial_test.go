@@ -0,0 +1,76 @@
+package fieldmask
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestPartialResponse(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		target    string
+		wantQuery string
+		want      *descriptorpb.FileDescriptorProto
+		wantField string
+	}{
+		{
+			name:      "JSON field names",
+			method:    http.MethodGet,
+			target:    "/v1/files/orders?fields=name,options.goPackage&view=full",
+			wantQuery: "view=full",
+			want: &descriptorpb.FileDescriptorProto{
+				Name:    proto.String("orders.proto"),
+				Options: &descriptorpb.FileOptions{GoPackage: proto.String("example.com/orders")},
+			},
+		},
+		{
+			name:      "repeated parameters",
+			method:    http.MethodGet,
+			target:    "/v1/files/orders?fields=name&fields=package",
+			wantQuery: "",
+			want:      &descriptorpb.FileDescriptorProto{Name: proto.String("orders.proto"), Package: proto.String("orders.v1")},
+		},
+		{name: "no parameter", method: http.MethodGet, target: "/v1/files/orders", want: file()},
+		{name: "not a read", method: http.MethodPost, target: "/v1/files?fields=name", wantQuery: "fields=name", want: file()},
+		{name: "unknown path", method: http.MethodGet, target: "/v1/files/orders?fields=title", wantField: "fields"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			partial := NewPartialResponse("")
+			msg := file()
+			var err error
+			var query string
+			handler := partial.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				query = r.URL.RawQuery
+				err = partial.ForwardResponseOption(r.Context(), w, msg)
+			}))
+
+			// Act
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tt.method, tt.target, nil))
+
+			// Assert
+			if tt.wantField != "" {
+				st := status.Convert(err)
+				assert.Equal(t, codes.InvalidArgument, st.Code())
+				require.Len(t, st.Details(), 1)
+				assert.Equal(t, tt.wantField, st.Details()[0].(*errdetails.BadRequest).GetFieldViolations()[0].GetField())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantQuery, query)
+			assert.True(t, proto.Equal(tt.want, msg), "got %v", msg)
+		})
+	}
+}
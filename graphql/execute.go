@@ -0,0 +1,532 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc/status"
+)
+
+// gqlError is an error of a GraphQL response
+type gqlError struct {
+	Message    string         `json:"message"`
+	Locations  []location     `json:"locations,omitempty"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+type location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// request is a GraphQL request, read from a JSON body or from query parameters
+type request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// response is the body of a GraphQL response; data is left out when the request fails
+// before execution
+type response struct {
+	Data   *orderedMap `json:"data,omitempty"`
+	Errors []*gqlError `json:"errors,omitempty"`
+}
+
+// prepare parses, selects and validates the operation of a request and coerces its
+// variables, returning the executor running it
+func (s *Schema) prepare(req *request) (*executor, *operation, []*gqlError) {
+	doc, err := parse(req.Query)
+	if err != nil {
+		gqlErr := &gqlError{Message: err.Error()}
+		if se, ok := err.(*syntaxError); ok {
+			gqlErr.Message = "Syntax Error: " + se.msg
+			gqlErr.Locations = []location{{Line: se.line, Column: se.col}}
+		}
+		return nil, nil, []*gqlError{gqlErr}
+	}
+
+	op, gqlErr := s.selectOperation(doc, req.OperationName)
+	if gqlErr != nil {
+		return nil, nil, []*gqlError{gqlErr}
+	}
+	if errs := s.validate(doc, op); len(errs) > 0 {
+		return nil, nil, errs
+	}
+
+	e := &executor{schema: s, doc: doc, vars: map[string]any{}}
+	var errs []*gqlError
+	for _, def := range op.variables {
+		v, ok := req.Variables[def.name]
+		if !ok && def.defaultValue.kind != valueNull {
+			v = e.literal(def.defaultValue)
+		}
+		if v == nil && def.typ.nonNull {
+			errs = append(errs, &gqlError{
+				Message: fmt.Sprintf("Variable \"$%s\" of required type \"%s\" was not provided.", def.name, def.typ),
+			})
+			continue
+		}
+		e.vars[def.name] = v
+	}
+	if len(errs) > 0 {
+		return nil, nil, errs
+	}
+	return e, op, nil
+}
+
+// selectOperation returns the operation named by the request, or the only operation of
+// the document
+func (s *Schema) selectOperation(doc *document, name string) (*operation, *gqlError) {
+	var op *operation
+	switch {
+	case len(doc.operations) == 0:
+		return nil, &gqlError{Message: "Must provide an operation."}
+	case name == "" && len(doc.operations) > 1:
+		return nil, &gqlError{Message: "Must provide operation name if query contains multiple operations."}
+	case name == "":
+		op = doc.operations[0]
+	default:
+		for _, o := range doc.operations {
+			if o.name == name {
+				op = o
+			}
+		}
+		if op == nil {
+			return nil, &gqlError{Message: fmt.Sprintf("Unknown operation named \"%s\".", name)}
+		}
+	}
+
+	switch {
+	case op.kind == "subscription":
+		return nil, &gqlError{Message: "Subscriptions are not supported."}
+	case op.kind == "mutation" && s.mutation == nil:
+		return nil, &gqlError{Message: "Schema is not configured for mutations."}
+	}
+	return op, nil
+}
+
+// orderedMap is a response object, its fields in the order they were selected
+type orderedMap struct {
+	keys   []string
+	values map[string]any
+}
+
+func newOrderedMap() *orderedMap {
+	return &orderedMap{values: map[string]any{}}
+}
+
+func (m *orderedMap) set(key string, v any) {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = v
+}
+
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		v, err := json.Marshal(m.values[k])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// callFunc calls the method of a root field with the arguments, encoded as the JSON
+// object of its request, and returns the JSON value of the response
+type callFunc func(ctx context.Context, m *method, request []byte) (any, error)
+
+// executor executes an operation
+type executor struct {
+	schema *Schema
+	doc    *document
+	vars   map[string]any
+	call   callFunc
+
+	mu     sync.Mutex
+	errors []*gqlError
+}
+
+// collectedField is a response key with the field nodes selecting it
+type collectedField struct {
+	key   string
+	nodes []*fieldNode
+}
+
+func (e *executor) addError(err *gqlError) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.errors = append(e.errors, err)
+}
+
+// fieldError returns the error of a field at a path
+func fieldError(f *fieldNode, path []any, msg string) *gqlError {
+	return &gqlError{
+		Message:   msg,
+		Locations: []location{{Line: f.line, Column: f.col}},
+		Path:      append([]any(nil), path...),
+	}
+}
+
+// executeOperation executes the root selection of an operation. Root fields of queries
+// are resolved concurrently, those of mutations one after the other.
+func (e *executor) executeOperation(ctx context.Context, op *operation) *orderedMap {
+	root := e.schema.query
+	if op.kind == "mutation" {
+		root = e.schema.mutation
+	}
+	fields := e.collectFields(root, op.selection, map[string]bool{})
+	results := make([]any, len(fields))
+
+	if op.kind == "mutation" {
+		for i, f := range fields {
+			results[i] = e.resolveRootField(ctx, root, f)
+		}
+	} else {
+		var wg sync.WaitGroup
+		for i, f := range fields {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[i] = e.resolveRootField(ctx, root, f)
+			}()
+		}
+		wg.Wait()
+	}
+
+	data := newOrderedMap()
+	for i, f := range fields {
+		data.set(f.key, results[i])
+	}
+	return data
+}
+
+// resolveRootField resolves a field of the query or mutation type
+func (e *executor) resolveRootField(ctx context.Context, root *gqlType, f collectedField) any {
+	node := f.nodes[0]
+	path := []any{f.key}
+	switch node.name {
+	case "__typename":
+		return root.name
+	case "__schema":
+		return e.completeValue(ctx, e.schema.schemaField.typ, f.nodes, e.schema, path)
+	case "__type":
+		args, err := e.arguments(e.schema.typeField.args, node.arguments)
+		if err != nil {
+			e.addError(fieldError(node, path, err.Error()))
+			return nil
+		}
+		name, _ := args["name"].(string)
+		t, ok := e.schema.types[name]
+		if !ok {
+			return nil
+		}
+		return e.completeValue(ctx, e.schema.typeField.typ, f.nodes, t, path)
+	}
+
+	def := root.field(node.name)
+	args, err := e.arguments(def.args, node.arguments)
+	if err != nil {
+		e.addError(fieldError(node, path, err.Error()))
+		return nil
+	}
+	request, err := json.Marshal(protoJSON(args, def.args))
+	if err != nil {
+		e.addError(fieldError(node, path, err.Error()))
+		return nil
+	}
+	resp, err := e.call(ctx, def.method, request)
+	if err != nil {
+		gqlErr := fieldError(node, path, err.Error())
+		if st, ok := status.FromError(err); ok {
+			gqlErr.Message = st.Message()
+			gqlErr.Extensions = map[string]any{"code": codeName(st.Code().String())}
+		}
+		e.addError(gqlErr)
+		return nil
+	}
+	return e.completeValue(ctx, def.typ, f.nodes, resp, path)
+}
+
+// completeValue shapes a resolved value after the type of its field and the selections
+// of the field nodes
+func (e *executor) completeValue(ctx context.Context, t *gqlType, nodes []*fieldNode, v any, path []any) any {
+	if t.kind == kindNonNull {
+		t = t.ofType
+	}
+	if v == nil {
+		return nil
+	}
+
+	switch t.kind {
+	case kindList:
+		items, ok := v.([]any)
+		if !ok {
+			e.addError(fieldError(nodes[0], path, fmt.Sprintf("expected a list for %s", t)))
+			return nil
+		}
+		out := make([]any, len(items))
+		for i, item := range items {
+			out[i] = e.completeValue(ctx, t.ofType, nodes, item, append(path[:len(path):len(path)], i))
+		}
+		return out
+	case kindObject:
+		var selections []selection
+		for _, n := range nodes {
+			selections = append(selections, n.selection...)
+		}
+		out := newOrderedMap()
+		for _, f := range e.collectFields(t, selections, map[string]bool{}) {
+			out.set(f.key, e.resolveField(ctx, t, f, v, append(path[:len(path):len(path)], f.key)))
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// resolveField resolves a field of an object: introspection types are described by the
+// schema, the others are read from the JSON object of their message
+func (e *executor) resolveField(ctx context.Context, parent *gqlType, f collectedField, source any, path []any) any {
+	node := f.nodes[0]
+	if node.name == "__typename" {
+		return parent.name
+	}
+	def := parent.field(node.name)
+
+	var v any
+	if isIntrospectionType(parent) {
+		args, err := e.arguments(def.args, node.arguments)
+		if err != nil {
+			e.addError(fieldError(node, path, err.Error()))
+			return nil
+		}
+		v = e.schema.resolveIntrospection(source, node.name, args)
+	} else if obj, ok := source.(map[string]any); ok {
+		v = obj[def.name]
+		if def.isMap {
+			v = mapEntries(v, def.typ.named())
+		}
+	}
+	return e.completeValue(ctx, def.typ, f.nodes, v, path)
+}
+
+// collectFields groups the fields selected on an object type by response key, applying
+// fragments and the @skip and @include directives
+func (e *executor) collectFields(t *gqlType, selections []selection, visited map[string]bool) []collectedField {
+	var fields []collectedField
+	index := map[string]int{}
+	add := func(key string, nodes ...*fieldNode) {
+		if i, ok := index[key]; ok {
+			fields[i].nodes = append(fields[i].nodes, nodes...)
+			return
+		}
+		index[key] = len(fields)
+		fields = append(fields, collectedField{key: key, nodes: nodes})
+	}
+
+	for _, sel := range selections {
+		switch s := sel.(type) {
+		case *fieldNode:
+			if e.included(s.directives) {
+				add(s.responseKey(), s)
+			}
+		case *fragmentSpread:
+			if !e.included(s.directives) || visited[s.name] {
+				continue
+			}
+			visited[s.name] = true
+			frag := e.doc.fragments[s.name]
+			for _, f := range e.collectFields(t, frag.selection, visited) {
+				add(f.key, f.nodes...)
+			}
+		case *inlineFragment:
+			if !e.included(s.directives) {
+				continue
+			}
+			for _, f := range e.collectFields(t, s.selection, visited) {
+				add(f.key, f.nodes...)
+			}
+		}
+	}
+	return fields
+}
+
+// included applies the @skip and @include directives
+func (e *executor) included(dirs []*directive) bool {
+	for _, d := range dirs {
+		for _, arg := range d.arguments {
+			v, _ := e.literal(arg.value).(bool)
+			if arg.name == "if" && (d.name == "skip" && v || d.name == "include" && !v) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// arguments returns the values of the arguments of a field, with the defaults of those
+// not given
+func (e *executor) arguments(defs []*field, args []*argument) (map[string]any, error) {
+	values := map[string]any{}
+	for _, def := range defs {
+		if def.defaultValue != "" {
+			var v any
+			if err := json.Unmarshal([]byte(def.defaultValue), &v); err == nil {
+				values[def.name] = v
+			}
+		}
+	}
+	for _, arg := range args {
+		values[arg.name] = e.literal(arg.value)
+	}
+	for _, def := range defs {
+		if def.typ.kind == kindNonNull && values[def.name] == nil {
+			return nil, fmt.Errorf("argument %q of type %q must not be null", def.name, def.typ)
+		}
+	}
+	return values, nil
+}
+
+// literal returns the JSON value of a document value, resolving variables
+func (e *executor) literal(v value) any {
+	switch v.kind {
+	case valueVariable:
+		return e.vars[v.raw]
+	case valueInt, valueFloat:
+		return json.Number(v.raw)
+	case valueString, valueEnum:
+		return v.raw
+	case valueBoolean:
+		return v.raw == "true"
+	case valueList:
+		items := make([]any, len(v.list))
+		for i, item := range v.list {
+			items[i] = e.literal(item)
+		}
+		return items
+	case valueObject:
+		obj := make(map[string]any, len(v.fields))
+		for _, f := range v.fields {
+			obj[f.name] = e.literal(f.value)
+		}
+		return obj
+	}
+	return nil
+}
+
+// protoJSON turns the arguments of a root field into the JSON of its request: map
+// fields, given as lists of entries, become objects, and null arguments are left out
+func protoJSON(args map[string]any, defs []*field) map[string]any {
+	obj := make(map[string]any, len(args))
+	for name, v := range args {
+		if v == nil {
+			continue
+		}
+		def := argDef(defs, name)
+		if def == nil {
+			obj[name] = v
+			continue
+		}
+		obj[name] = inputJSON(v, def)
+	}
+	return obj
+}
+
+// inputJSON converts the value of an input field for protojson
+func inputJSON(v any, def *field) any {
+	named := def.typ.named()
+	if def.isMap {
+		entries, _ := v.([]any)
+		obj := make(map[string]any, len(entries))
+		for _, entry := range entries {
+			e, ok := entry.(map[string]any)
+			if !ok {
+				continue
+			}
+			value := e["value"]
+			if valueDef := named.field("value"); valueDef != nil && value != nil {
+				value = inputJSON(value, valueDef)
+			}
+			obj[fmt.Sprint(e["key"])] = value
+		}
+		return obj
+	}
+	if named.kind != kindInputObject {
+		return v
+	}
+	if items, ok := v.([]any); ok {
+		out := make([]any, len(items))
+		for i, item := range items {
+			out[i] = inputJSON(item, &field{typ: named})
+		}
+		return out
+	}
+	if obj, ok := v.(map[string]any); ok {
+		return protoJSON(obj, named.fields)
+	}
+	return v
+}
+
+// mapEntries turns the JSON object of a map field into its list of entries, sorted by
+// key
+func mapEntries(v any, entry *gqlType) any {
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return v
+	}
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	keyType := stringType
+	if f := entry.field("key"); f != nil {
+		keyType = f.typ.named()
+	}
+	entries := make([]any, len(keys))
+	for i, k := range keys {
+		var key any = k
+		switch keyType {
+		case intType:
+			key = json.Number(k)
+		case booleanType:
+			key = k == "true"
+		}
+		entries[i] = map[string]any{"key": key, "value": obj[k]}
+	}
+	return entries
+}
+
+// codeName turns a gRPC code name such as NotFound into NOT_FOUND
+func codeName(code string) string {
+	var b strings.Builder
+	for i, r := range code {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			b.WriteByte('_')
+		}
+		b.WriteRune(r)
+	}
+	return strings.ToUpper(b.String())
+}
@@ -0,0 +1,280 @@
+// Package graphql serves a GraphQL endpoint on the gateway, generated from the
+// registered gRPC services, for frontends that need GraphQL without a separate BFF.
+//
+//	server.WithServices(
+//		orders.New(),
+//		graphql.New(graphql.WithServices(orders.New())),
+//	)
+//
+// Each unary method is a root field taking the request fields as arguments and
+// resolving to the response message: methods without side effects (idempotency_level
+// NO_SIDE_EFFECTS, bound to GET, or named Get, List, Search or BatchGet) are queries,
+// the others mutations. Messages are object and input types, enums keep their proto
+// value names, 64-bit integers and bytes are strings as in protojson, maps are lists of
+// key and value entries, and well-known types are scalars.
+//
+// Root fields call their method on the gateway's gRPC endpoint, with the HTTP request
+// headers as metadata like the generated gateway handlers. The schema is served in the
+// schema definition language at the endpoint path followed by /schema.graphql, and
+// through introspection.
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/legrch/netgex/internal/descriptors"
+	"github.com/legrch/netgex/service"
+)
+
+// DefaultPath is the path the endpoint is served on by default
+const DefaultPath = "/graphql"
+
+// maxRequestSize bounds the body of GraphQL requests
+const maxRequestSize = 1 << 20
+
+// Default limits of operations, generous enough for the introspection queries of
+// GraphQL tools
+const (
+	DefaultMaxDepth      = 20
+	DefaultMaxComplexity = 2000
+)
+
+// Option configures a Registrar
+type Option func(*Registrar)
+
+// WithServices sets the registrars whose gRPC services the schema is generated from
+func WithServices(services ...service.Registrar) Option {
+	return func(r *Registrar) {
+		r.services = append(r.services, services...)
+	}
+}
+
+// WithPath sets the path of the endpoint, /graphql by default
+func WithPath(path string) Option {
+	return func(r *Registrar) {
+		r.path = path
+	}
+}
+
+// WithMaxDepth sets how deep operations may nest fields, DefaultMaxDepth by default;
+// 0 disables the limit
+func WithMaxDepth(depth int) Option {
+	return func(r *Registrar) {
+		r.maxDepth = depth
+	}
+}
+
+// WithMaxComplexity sets how many fields operations may select, counting the fields of
+// fragments each time they are spread, DefaultMaxComplexity by default; 0 disables the
+// limit
+func WithMaxComplexity(fields int) Option {
+	return func(r *Registrar) {
+		r.maxComplexity = fields
+	}
+}
+
+// Registrar registers the GraphQL endpoint on the gateway
+type Registrar struct {
+	descriptors.GatewayOnly
+	services      []service.Registrar
+	path          string
+	maxDepth      int
+	maxComplexity int
+}
+
+// New creates a registrar serving the GraphQL schema of the services
+func New(opts ...Option) *Registrar {
+	r := &Registrar{path: DefaultPath, maxDepth: DefaultMaxDepth, maxComplexity: DefaultMaxComplexity}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Name returns the name of the registrar
+func (*Registrar) Name() string {
+	return "graphql"
+}
+
+// RegisterHTTP builds the schema of the services and registers the endpoint, calling
+// the methods on endpoint
+func (r *Registrar) RegisterHTTP(ctx context.Context, mux *runtime.ServeMux, endpoint string, opts []grpc.DialOption) (err error) {
+	schema, err := NewSchema(r.serviceNames()...)
+	if err != nil {
+		return err
+	}
+	schema.maxDepth, schema.maxComplexity = r.maxDepth, r.maxComplexity
+
+	conn, err := grpc.NewClient(endpoint, opts...)
+	if err != nil {
+		return fmt.Errorf("graphql: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = conn.Close()
+			return
+		}
+		go func() {
+			<-ctx.Done()
+			_ = conn.Close()
+		}()
+	}()
+
+	h := &handler{schema: schema, conn: conn, mux: mux}
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		if err := mux.HandlePath(method, r.path, h.serve); err != nil {
+			return fmt.Errorf("graphql: %s %s: %w", method, r.path, err)
+		}
+	}
+	sdlPath := strings.TrimSuffix(r.path, "/") + "/schema.graphql"
+	if err := mux.HandlePath(http.MethodGet, sdlPath, h.serveSDL); err != nil {
+		return fmt.Errorf("graphql: GET %s: %w", sdlPath, err)
+	}
+	return nil
+}
+
+// serviceNames returns the gRPC services of the registrars, leaving out the grpc.*
+// services such as health and reflection
+func (r *Registrar) serviceNames() []string {
+	var names []string
+	for _, sd := range descriptors.Services(r.services) {
+		if name := string(sd.FullName()); !strings.HasPrefix(name, "grpc.") {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// handler serves GraphQL requests, calling the methods on the gRPC connection
+type handler struct {
+	schema *Schema
+	conn   *grpc.ClientConn
+	mux    *runtime.ServeMux
+}
+
+// serve executes a request, read from a POST body, JSON or application/graphql, or
+// from the query, operationName and variables parameters of a GET. Mutations are only
+// executed on POST. Requests failing before execution are answered with 400.
+func (h *handler) serve(w http.ResponseWriter, req *http.Request, _ map[string]string) {
+	params, err := readRequest(req)
+	if err != nil {
+		writeResponse(w, http.StatusBadRequest, &response{Errors: []*gqlError{{Message: err.Error()}}})
+		return
+	}
+
+	e, op, errs := h.schema.prepare(params)
+	if len(errs) > 0 {
+		writeResponse(w, http.StatusBadRequest, &response{Errors: errs})
+		return
+	}
+	if op.kind == "mutation" && req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeResponse(w, http.StatusMethodNotAllowed, &response{
+			Errors: []*gqlError{{Message: "Mutations can only be executed with POST."}},
+		})
+		return
+	}
+
+	e.call = func(ctx context.Context, m *method, request []byte) (any, error) {
+		return h.call(ctx, req, m, request)
+	}
+	data := e.executeOperation(req.Context(), op)
+	writeResponse(w, http.StatusOK, &response{Data: data, Errors: e.errors})
+}
+
+// serveSDL serves the schema in the schema definition language
+func (h *handler) serveSDL(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = io.WriteString(w, h.schema.SDL())
+}
+
+// call invokes a method with the JSON of its request, forwarding the headers of the
+// HTTP request as metadata, and returns the JSON value of its response
+func (h *handler) call(ctx context.Context, req *http.Request, m *method, request []byte) (any, error) {
+	in := m.input.New().Interface()
+	if err := protojson.Unmarshal(request, in); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid arguments: %v", err)
+	}
+	ctx, err := runtime.AnnotateContext(ctx, h.mux, req, m.rpc)
+	if err != nil {
+		return nil, err
+	}
+	out := m.output.New().Interface()
+	if err := h.conn.Invoke(ctx, m.rpc, in, out); err != nil {
+		return nil, err
+	}
+
+	b, err := protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(out)
+	if err != nil {
+		return nil, err
+	}
+	var v any
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// readRequest reads the query, operation name and variables of a request
+func readRequest(req *http.Request) (*request, error) {
+	if req.Method == http.MethodGet {
+		q := req.URL.Query()
+		params := &request{Query: q.Get("query"), OperationName: q.Get("operationName")}
+		if vars := q.Get("variables"); vars != "" {
+			if err := decodeJSON(strings.NewReader(vars), &params.Variables); err != nil {
+				return nil, fmt.Errorf("invalid variables: %w", err)
+			}
+		}
+		return params, nil
+	}
+
+	body := http.MaxBytesReader(nil, req.Body, maxRequestSize)
+	if mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); mediaType == "application/graphql" {
+		query, err := io.ReadAll(body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid body: %w", err)
+		}
+		return &request{Query: string(query)}, nil
+	}
+	params := &request{}
+	if err := decodeJSON(body, params); err != nil {
+		return nil, fmt.Errorf("invalid body: %w", err)
+	}
+	return params, nil
+}
+
+// decodeJSON decodes JSON keeping numbers as json.Number, passed on to protojson as
+// written
+func decodeJSON(r io.Reader, v any) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		if errors.Is(err, io.EOF) {
+			return errors.New("empty body")
+		}
+		return err
+	}
+	return nil
+}
+
+func writeResponse(w http.ResponseWriter, code int, resp *response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,447 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const ordersService = "graphqltest.v1.Orders"
+
+// ordersFile is the descriptor of the test service: GetOrder is a query, CreateOrder a
+// mutation
+var ordersFile = func() protoreflect.FileDescriptor {
+	optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	repeated := descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	scalar := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name: proto.String(name), Number: proto.Int32(number), Label: optional, Type: typ.Enum(),
+		}
+	}
+	typed := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, typeName string) *descriptorpb.FieldDescriptorProto {
+		f := scalar(name, number, typ)
+		f.TypeName = proto.String(typeName)
+		return f
+	}
+	str := descriptorpb.FieldDescriptorProto_TYPE_STRING
+	msg := descriptorpb.FieldDescriptorProto_TYPE_MESSAGE
+
+	items := typed("items", 4, msg, ".graphqltest.v1.Item")
+	items.Label = repeated
+	labels := typed("labels", 5, msg, ".graphqltest.v1.Order.LabelsEntry")
+	labels.Label = repeated
+	note := scalar("legacy_note", 6, str)
+	note.Options = &descriptorpb.FieldOptions{Deprecated: proto.Bool(true)}
+
+	fd, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("graphqltest/v1/orders.proto"),
+		Package: proto.String("graphqltest.v1"),
+		Syntax:  proto.String("proto3"),
+		EnumType: []*descriptorpb.EnumDescriptorProto{{
+			Name: proto.String("Status"),
+			Value: []*descriptorpb.EnumValueDescriptorProto{
+				{Name: proto.String("STATUS_UNSPECIFIED"), Number: proto.Int32(0)},
+				{Name: proto.String("STATUS_OPEN"), Number: proto.Int32(1)},
+				{Name: proto.String("STATUS_CLOSED"), Number: proto.Int32(2)},
+			},
+		}},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Order"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					scalar("id", 1, str),
+					scalar("total", 2, descriptorpb.FieldDescriptorProto_TYPE_INT64),
+					typed("status", 3, descriptorpb.FieldDescriptorProto_TYPE_ENUM, ".graphqltest.v1.Status"),
+					items,
+					labels,
+					note,
+				},
+				NestedType: []*descriptorpb.DescriptorProto{{
+					Name:    proto.String("LabelsEntry"),
+					Field:   []*descriptorpb.FieldDescriptorProto{scalar("key", 1, str), scalar("value", 2, str)},
+					Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+				}},
+			},
+			{
+				Name: proto.String("Item"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					scalar("sku", 1, str),
+					scalar("quantity", 2, descriptorpb.FieldDescriptorProto_TYPE_INT32),
+				},
+			},
+			{Name: proto.String("GetOrderRequest"), Field: []*descriptorpb.FieldDescriptorProto{scalar("id", 1, str)}},
+			{
+				Name:  proto.String("CreateOrderRequest"),
+				Field: []*descriptorpb.FieldDescriptorProto{typed("order", 1, msg, ".graphqltest.v1.Order")},
+			},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("Orders"),
+			Method: []*descriptorpb.MethodDescriptorProto{
+				{Name: proto.String("GetOrder"), InputType: proto.String(".graphqltest.v1.GetOrderRequest"), OutputType: proto.String(".graphqltest.v1.Order")},
+				{Name: proto.String("CreateOrder"), InputType: proto.String(".graphqltest.v1.CreateOrderRequest"), OutputType: proto.String(".graphqltest.v1.Order")},
+			},
+		}},
+	}, protoregistry.GlobalFiles)
+	if err != nil {
+		panic(err)
+	}
+	if err := protoregistry.GlobalFiles.RegisterFile(fd); err != nil {
+		panic(err)
+	}
+	return fd
+}()
+
+// ordersServer implements the test service with dynamic messages: GetOrder returns
+// order 1 and NotFound for other ids, CreateOrder returns the order it is given
+type ordersServer struct{}
+
+func (ordersServer) RegisterGRPC(srv *grpc.Server) {
+	sd := ordersFile.Services().ByName("Orders")
+	handler := func(md protoreflect.MethodDescriptor, fn func(in *dynamicpb.Message) (proto.Message, error)) grpc.MethodDesc {
+		return grpc.MethodDesc{
+			MethodName: string(md.Name()),
+			Handler: func(_ any, _ context.Context, dec func(any) error, _ grpc.UnaryServerInterceptor) (any, error) {
+				in := dynamicpb.NewMessage(md.Input())
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				return fn(in)
+			},
+		}
+	}
+	getOrder := sd.Methods().ByName("GetOrder")
+	createOrder := sd.Methods().ByName("CreateOrder")
+	srv.RegisterService(&grpc.ServiceDesc{
+		ServiceName: ordersService,
+		HandlerType: (*any)(nil),
+		Methods: []grpc.MethodDesc{
+			handler(getOrder, func(in *dynamicpb.Message) (proto.Message, error) {
+				id := in.Get(in.Descriptor().Fields().ByName("id")).String()
+				if id != "1" {
+					return nil, status.Errorf(codes.NotFound, "order %q not found", id)
+				}
+				return testOrder(getOrder.Output()), nil
+			}),
+			handler(createOrder, func(in *dynamicpb.Message) (proto.Message, error) {
+				return in.Get(in.Descriptor().Fields().ByName("order")).Message().Interface(), nil
+			}),
+		},
+	}, struct{}{})
+}
+
+func (ordersServer) RegisterHTTP(context.Context, *runtime.ServeMux, string, []grpc.DialOption) error {
+	return nil
+}
+
+// healthServer registers the health service, left out of the schema
+type healthServer struct{}
+
+func (healthServer) RegisterGRPC(srv *grpc.Server) {
+	grpc_health_v1.RegisterHealthServer(srv, health.NewServer())
+}
+
+func (healthServer) RegisterHTTP(context.Context, *runtime.ServeMux, string, []grpc.DialOption) error {
+	return nil
+}
+
+// testOrder returns order 1, with an item and a label
+func testOrder(md protoreflect.MessageDescriptor) proto.Message {
+	order := dynamicpb.NewMessage(md)
+	fields := md.Fields()
+	order.Set(fields.ByName("id"), protoreflect.ValueOfString("1"))
+	order.Set(fields.ByName("total"), protoreflect.ValueOfInt64(1200))
+	order.Set(fields.ByName("status"), protoreflect.ValueOfEnum(1))
+
+	item := dynamicpb.NewMessage(fields.ByName("items").Message())
+	item.Set(item.Descriptor().Fields().ByName("sku"), protoreflect.ValueOfString("book"))
+	item.Set(item.Descriptor().Fields().ByName("quantity"), protoreflect.ValueOfInt32(2))
+	order.Mutable(fields.ByName("items")).List().Append(protoreflect.ValueOfMessage(item))
+	order.Mutable(fields.ByName("labels")).Map().Set(protoreflect.ValueOfString("gift").MapKey(), protoreflect.ValueOfString("yes"))
+	return order
+}
+
+// serveGraphQL serves the test and health services on an in-memory listener and
+// returns the gateway mux with the GraphQL endpoint registered
+func serveGraphQL(t *testing.T, opts ...Option) *runtime.ServeMux {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	ordersServer{}.RegisterGRPC(srv)
+	healthServer{}.RegisterGRPC(srv)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	mux := runtime.NewServeMux()
+	dialOpts := []grpc.DialOption{
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	}
+	opts = append([]Option{WithServices(ordersServer{}, healthServer{})}, opts...)
+	require.NoError(t, New(opts...).RegisterHTTP(ctx, mux, "passthrough:///bufconn", dialOpts))
+	return mux
+}
+
+func TestRegistrar_RegisterHTTP(t *testing.T) {
+	// Arrange
+	mux := serveGraphQL(t)
+
+	tests := []struct {
+		name        string
+		method      string
+		contentType string
+		body        string
+		wantStatus  int
+		wantBody    string
+	}{
+		{
+			name:       "query",
+			method:     http.MethodPost,
+			body:       `{"query":"{ getOrder(id: \"1\") { id total status items { sku quantity } labels { key value } } }"}`,
+			wantStatus: http.StatusOK,
+			wantBody: `{"data":{"getOrder":{"id":"1","total":"1200","status":"STATUS_OPEN",
+				"items":[{"sku":"book","quantity":2}],"labels":[{"key":"gift","value":"yes"}]}}}`,
+		},
+		{
+			name:       "variables, aliases and fragments",
+			method:     http.MethodPost,
+			body:       `{"query":"query Get($id: String!) { order: getOrder(id: $id) { ...ids status @skip(if: true) } } fragment ids on Order { id __typename }","variables":{"id":"1"}}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"data":{"order":{"id":"1","__typename":"Order"}}}`,
+		},
+		{
+			name:        "application/graphql body",
+			method:      http.MethodPost,
+			contentType: "application/graphql",
+			body:        `{ getOrder(id: "1") { id } }`,
+			wantStatus:  http.StatusOK,
+			wantBody:    `{"data":{"getOrder":{"id":"1"}}}`,
+		},
+		{
+			name:       "query over GET",
+			method:     http.MethodGet,
+			body:       `{ getOrder(id: "1") { id } }`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"data":{"getOrder":{"id":"1"}}}`,
+		},
+		{
+			name:       "mutation",
+			method:     http.MethodPost,
+			body:       `{"query":"mutation { createOrder(order: {id: \"2\", total: \"5\", status: STATUS_CLOSED, labels: [{key: \"a\", value: \"b\"}]}) { id total status labels { key value } } }"}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"data":{"createOrder":{"id":"2","total":"5","status":"STATUS_CLOSED","labels":[{"key":"a","value":"b"}]}}}`,
+		},
+		{
+			name:       "mutation over GET",
+			method:     http.MethodGet,
+			body:       `mutation { createOrder { id } }`,
+			wantStatus: http.StatusMethodNotAllowed,
+			wantBody:   `{"errors":[{"message":"Mutations can only be executed with POST."}]}`,
+		},
+		{
+			name:       "error status",
+			method:     http.MethodPost,
+			body:       `{"query":"{ getOrder(id: \"2\") { id } }"}`,
+			wantStatus: http.StatusOK,
+			wantBody: `{"data":{"getOrder":null},"errors":[{"message":"order \"2\" not found",
+				"locations":[{"line":1,"column":3}],"path":["getOrder"],"extensions":{"code":"NOT_FOUND"}}]}`,
+		},
+		{
+			name:       "validation error",
+			method:     http.MethodPost,
+			body:       `{"query":"{ getOrder { name } }"}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"errors":[{"message":"Cannot query field \"name\" on type \"Order\".","locations":[{"line":1,"column":14}]}]}`,
+		},
+		{
+			name:       "syntax error",
+			method:     http.MethodPost,
+			body:       `{"query":"{ getOrder {"}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"errors":[{"message":"Syntax Error: unexpected end of document","locations":[{"line":1,"column":13}]}]}`,
+		},
+		{
+			name:       "missing variable",
+			method:     http.MethodPost,
+			body:       `{"query":"query Get($id: String!) { getOrder(id: $id) { id } }"}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"errors":[{"message":"Variable \"$id\" of required type \"String!\" was not provided."}]}`,
+		},
+		{
+			name:       "invalid body",
+			method:     http.MethodPost,
+			body:       `{`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"errors":[{"message":"invalid body: unexpected EOF"}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/graphql", strings.NewReader(tt.body))
+			if tt.method == http.MethodGet {
+				req = httptest.NewRequest(tt.method, "/graphql?query="+url.QueryEscape(tt.body), nil)
+			}
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rec := httptest.NewRecorder()
+
+			// Act
+			mux.ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+			assert.JSONEq(t, tt.wantBody, rec.Body.String())
+		})
+	}
+}
+
+func TestRegistrar_RegisterHTTP_Introspection(t *testing.T) {
+	// Arrange
+	mux := serveGraphQL(t)
+	query := `{
+		__schema { queryType { name } mutationType { name } }
+		__type(name: "Order") {
+			kind
+			fields(includeDeprecated: true) { name isDeprecated type { kind name ofType { kind name } } }
+		}
+	}`
+	body, err := json.Marshal(map[string]string{"query": query})
+	require.NoError(t, err)
+	rec := httptest.NewRecorder()
+
+	// Act
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body))))
+
+	// Assert
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"data":{
+		"__schema":{"queryType":{"name":"Query"},"mutationType":{"name":"Mutation"}},
+		"__type":{"kind":"OBJECT","fields":[
+			{"name":"id","isDeprecated":false,"type":{"kind":"SCALAR","name":"String","ofType":null}},
+			{"name":"total","isDeprecated":false,"type":{"kind":"SCALAR","name":"String","ofType":null}},
+			{"name":"status","isDeprecated":false,"type":{"kind":"ENUM","name":"Status","ofType":null}},
+			{"name":"items","isDeprecated":false,"type":{"kind":"LIST","name":null,"ofType":{"kind":"NON_NULL","name":null}}},
+			{"name":"labels","isDeprecated":false,"type":{"kind":"LIST","name":null,"ofType":{"kind":"NON_NULL","name":null}}},
+			{"name":"legacyNote","isDeprecated":true,"type":{"kind":"SCALAR","name":"String","ofType":null}}
+		]}
+	}}`, rec.Body.String())
+}
+
+func TestRegistrar_RegisterHTTP_SDL(t *testing.T) {
+	// Arrange
+	mux := serveGraphQL(t)
+	rec := httptest.NewRecorder()
+
+	// Act
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/graphql/schema.graphql", nil))
+
+	// Assert
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "getOrder(id: String): Order")
+	assert.NotContains(t, rec.Body.String(), "Health", "grpc.* services are left out")
+}
+
+// fanOutQuery returns a query of fragments on Order, each spreading the next one ten
+// times: small to send, but selecting over a hundred million fields once expanded
+func fanOutQuery(levels int) string {
+	var b strings.Builder
+	b.WriteString(`{ getOrder(id: "1") { ...f0 } }`)
+	for i := range levels {
+		fmt.Fprintf(&b, " fragment f%d on Order { id", i)
+		if i < levels-1 {
+			b.WriteString(strings.Repeat(fmt.Sprintf(" ...f%d", i+1), 10))
+		}
+		b.WriteString(" }")
+	}
+	return b.String()
+}
+
+func TestRegistrar_RegisterHTTP_Limits(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		query    string
+		wantBody string
+	}{
+		{
+			name:     "fragment fan-out",
+			query:    fanOutQuery(9),
+			wantBody: `{"errors":[{"message":"Query selects more than the maximum of 2000 fields."}]}`,
+		},
+		{
+			name:     "depth",
+			opts:     []Option{WithMaxDepth(1)},
+			query:    `{ getOrder(id: "1") { items { sku } } }`,
+			wantBody: `{"errors":[{"message":"Query depth 3 exceeds the maximum depth of 1."}]}`,
+		},
+		{
+			name:     "complexity",
+			opts:     []Option{WithMaxComplexity(3)},
+			query:    `{ getOrder(id: "1") { ...ids ...ids } } fragment ids on Order { id total }`,
+			wantBody: `{"errors":[{"message":"Query selects more than the maximum of 3 fields."}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mux := serveGraphQL(t, tt.opts...)
+			body, err := json.Marshal(map[string]string{"query": tt.query})
+			require.NoError(t, err)
+			rec := httptest.NewRecorder()
+
+			// Act
+			start := time.Now()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body)))
+
+			// Assert
+			assert.Less(t, time.Since(start), time.Second)
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.JSONEq(t, tt.wantBody, rec.Body.String())
+		})
+	}
+}
+
+func TestRegistrar_RegisterHTTP_FragmentsSpreadRepeatedly(t *testing.T) {
+	// Arrange
+	mux := serveGraphQL(t, WithMaxComplexity(0))
+	body, err := json.Marshal(map[string]string{"query": fanOutQuery(3)})
+	require.NoError(t, err)
+	rec := httptest.NewRecorder()
+
+	// Act
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body)))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"data":{"getOrder":{"id":"1"}}}`, rec.Body.String())
+}
@@ -0,0 +1,265 @@
+package graphql
+
+import (
+	"strings"
+)
+
+// directiveDef is a directive the executor supports
+type directiveDef struct {
+	name      string
+	desc      string
+	locations []string
+	args      []*field
+}
+
+// directives are the directives of every schema: @skip and @include are applied by the
+// executor, @deprecated marks deprecated proto elements
+var directives = []*directiveDef{
+	{
+		name:      "include",
+		desc:      "Directs the executor to include this field or fragment only when the `if` argument is true.",
+		locations: []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"},
+		args:      []*field{{name: "if", typ: nonNullOf(booleanType), desc: "Included when true."}},
+	},
+	{
+		name:      "skip",
+		desc:      "Directs the executor to skip this field or fragment when the `if` argument is true.",
+		locations: []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"},
+		args:      []*field{{name: "if", typ: nonNullOf(booleanType), desc: "Skipped when true."}},
+	},
+	{
+		name:      "deprecated",
+		desc:      "Marks an element of a GraphQL schema as no longer supported.",
+		locations: []string{"FIELD_DEFINITION", "ARGUMENT_DEFINITION", "INPUT_FIELD_DEFINITION", "ENUM_VALUE"},
+		args:      []*field{{name: "reason", typ: stringType, defaultValue: `"No longer supported"`}},
+	},
+}
+
+// addIntrospectionTypes adds the types describing the schema to it
+func addIntrospectionTypes(s *Schema) {
+	includeDeprecated := func() []*field {
+		return []*field{{name: "includeDeprecated", typ: booleanType, defaultValue: "false"}}
+	}
+	named := func(kind typeKind, name string) *gqlType {
+		t := &gqlType{kind: kind, name: name}
+		s.types[name] = t
+		return t
+	}
+	list := func(t *gqlType) *gqlType { return nonNullOf(listOf(nonNullOf(t))) }
+
+	schema := named(kindObject, "__Schema")
+	typ := named(kindObject, "__Type")
+	fieldType := named(kindObject, "__Field")
+	inputValue := named(kindObject, "__InputValue")
+	enumValueType := named(kindObject, "__EnumValue")
+	directive := named(kindObject, "__Directive")
+
+	typeKindEnum := named(kindEnum, "__TypeKind")
+	for _, v := range []string{"SCALAR", "OBJECT", "INTERFACE", "UNION", "ENUM", "INPUT_OBJECT", "LIST", "NON_NULL"} {
+		typeKindEnum.values = append(typeKindEnum.values, &enumValue{name: v})
+	}
+	location := named(kindEnum, "__DirectiveLocation")
+	for _, v := range []string{
+		"QUERY", "MUTATION", "SUBSCRIPTION", "FIELD", "FRAGMENT_DEFINITION", "FRAGMENT_SPREAD",
+		"INLINE_FRAGMENT", "VARIABLE_DEFINITION", "SCHEMA", "SCALAR", "OBJECT", "FIELD_DEFINITION",
+		"ARGUMENT_DEFINITION", "INTERFACE", "UNION", "ENUM", "ENUM_VALUE", "INPUT_OBJECT",
+		"INPUT_FIELD_DEFINITION",
+	} {
+		location.values = append(location.values, &enumValue{name: v})
+	}
+
+	schema.fields = []*field{
+		{name: "description", typ: stringType},
+		{name: "types", typ: list(typ)},
+		{name: "queryType", typ: nonNullOf(typ)},
+		{name: "mutationType", typ: typ},
+		{name: "subscriptionType", typ: typ},
+		{name: "directives", typ: list(directive)},
+	}
+	typ.fields = []*field{
+		{name: "kind", typ: nonNullOf(typeKindEnum)},
+		{name: "name", typ: stringType},
+		{name: "description", typ: stringType},
+		{name: "specifiedByURL", typ: stringType},
+		{name: "fields", typ: listOf(nonNullOf(fieldType)), args: includeDeprecated()},
+		{name: "interfaces", typ: listOf(nonNullOf(typ))},
+		{name: "possibleTypes", typ: listOf(nonNullOf(typ))},
+		{name: "enumValues", typ: listOf(nonNullOf(enumValueType)), args: includeDeprecated()},
+		{name: "inputFields", typ: listOf(nonNullOf(inputValue)), args: includeDeprecated()},
+		{name: "ofType", typ: typ},
+		{name: "isOneOf", typ: booleanType},
+	}
+	fieldType.fields = []*field{
+		{name: "name", typ: nonNullOf(stringType)},
+		{name: "description", typ: stringType},
+		{name: "args", typ: list(inputValue), args: includeDeprecated()},
+		{name: "type", typ: nonNullOf(typ)},
+		{name: "isDeprecated", typ: nonNullOf(booleanType)},
+		{name: "deprecationReason", typ: stringType},
+	}
+	inputValue.fields = []*field{
+		{name: "name", typ: nonNullOf(stringType)},
+		{name: "description", typ: stringType},
+		{name: "type", typ: nonNullOf(typ)},
+		{name: "defaultValue", typ: stringType},
+		{name: "isDeprecated", typ: nonNullOf(booleanType)},
+		{name: "deprecationReason", typ: stringType},
+	}
+	enumValueType.fields = []*field{
+		{name: "name", typ: nonNullOf(stringType)},
+		{name: "description", typ: stringType},
+		{name: "isDeprecated", typ: nonNullOf(booleanType)},
+		{name: "deprecationReason", typ: stringType},
+	}
+	directive.fields = []*field{
+		{name: "name", typ: nonNullOf(stringType)},
+		{name: "description", typ: stringType},
+		{name: "locations", typ: list(location)},
+		{name: "args", typ: list(inputValue), args: includeDeprecated()},
+		{name: "isRepeatable", typ: nonNullOf(booleanType)},
+	}
+
+	// The meta-fields of the query type, resolved by the executor
+	s.schemaField = &field{name: "__schema", typ: nonNullOf(schema), desc: "Access the current type schema of this server."}
+	s.typeField = &field{
+		name: "__type",
+		typ:  typ,
+		desc: "Request the type information of a single type.",
+		args: []*field{{name: "name", typ: nonNullOf(stringType)}},
+	}
+}
+
+// resolveIntrospection resolves a field of an introspection type on its source: the
+// schema, a type, a field, an argument, an enum value or a directive
+func (s *Schema) resolveIntrospection(source any, name string, args map[string]any) any {
+	includeDeprecated, _ := args["includeDeprecated"].(bool)
+
+	switch src := source.(type) {
+	case *Schema:
+		switch name {
+		case "types":
+			return anySlice(src.sortedTypes())
+		case "queryType":
+			return src.query
+		case "mutationType":
+			if src.mutation == nil {
+				return nil
+			}
+			return src.mutation
+		case "directives":
+			return anySlice(directives)
+		}
+	case *gqlType:
+		switch name {
+		case "kind":
+			return string(src.kind)
+		case "name":
+			return optional(src.name)
+		case "description":
+			return optional(src.desc)
+		case "fields":
+			if src.kind != kindObject {
+				return nil
+			}
+			return anySlice(visibleFields(src.fields, includeDeprecated))
+		case "interfaces":
+			if src.kind != kindObject {
+				return nil
+			}
+			return []any{}
+		case "enumValues":
+			if src.kind != kindEnum {
+				return nil
+			}
+			values := []any{}
+			for _, v := range src.values {
+				if includeDeprecated || v.deprecated == "" {
+					values = append(values, v)
+				}
+			}
+			return values
+		case "inputFields":
+			if src.kind != kindInputObject {
+				return nil
+			}
+			return anySlice(visibleFields(src.fields, includeDeprecated))
+		case "ofType":
+			if src.ofType == nil {
+				return nil
+			}
+			return src.ofType
+		}
+	case *field:
+		switch name {
+		case "name":
+			return src.name
+		case "description":
+			return optional(src.desc)
+		case "args":
+			return anySlice(visibleFields(src.args, includeDeprecated))
+		case "type":
+			return src.typ
+		case "defaultValue":
+			return optional(src.defaultValue)
+		case "isDeprecated":
+			return src.deprecated != ""
+		case "deprecationReason":
+			return optional(src.deprecated)
+		}
+	case *enumValue:
+		switch name {
+		case "name":
+			return src.name
+		case "isDeprecated":
+			return src.deprecated != ""
+		case "deprecationReason":
+			return optional(src.deprecated)
+		}
+	case *directiveDef:
+		switch name {
+		case "name":
+			return src.name
+		case "description":
+			return optional(src.desc)
+		case "locations":
+			return anySlice(src.locations)
+		case "args":
+			return anySlice(src.args)
+		case "isRepeatable":
+			return false
+		}
+	}
+	return nil
+}
+
+// visibleFields returns the fields, leaving out the deprecated ones unless asked for
+func visibleFields(fields []*field, includeDeprecated bool) []*field {
+	visible := make([]*field, 0, len(fields))
+	for _, f := range fields {
+		if includeDeprecated || f.deprecated == "" {
+			visible = append(visible, f)
+		}
+	}
+	return visible
+}
+
+// optional returns nil for empty strings, null in the response
+func optional(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func anySlice[T any](items []T) []any {
+	out := make([]any, len(items))
+	for i, item := range items {
+		out[i] = item
+	}
+	return out
+}
+
+// isIntrospectionType reports whether a type describes the schema
+func isIntrospectionType(t *gqlType) bool {
+	return strings.HasPrefix(t.name, "__")
+}
@@ -0,0 +1,754 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// The executable definitions of a GraphQL document
+type (
+	document struct {
+		operations []*operation
+		fragments  map[string]*fragment
+	}
+	operation struct {
+		kind      string // query, mutation or subscription
+		name      string
+		variables []*variableDef
+		selection []selection
+	}
+	variableDef struct {
+		name         string
+		typ          *typeRef
+		defaultValue value
+	}
+	typeRef struct {
+		name    string
+		list    *typeRef
+		nonNull bool
+	}
+	fragment struct {
+		name          string
+		typeCondition string
+		selection     []selection
+	}
+
+	// selection is a *fieldNode, *fragmentSpread or *inlineFragment
+	selection interface{}
+
+	fieldNode struct {
+		alias      string
+		name       string
+		arguments  []*argument
+		directives []*directive
+		selection  []selection
+		line, col  int
+	}
+	fragmentSpread struct {
+		name       string
+		directives []*directive
+	}
+	inlineFragment struct {
+		typeCondition string
+		directives    []*directive
+		selection     []selection
+	}
+	argument struct {
+		name  string
+		value value
+	}
+	directive struct {
+		name      string
+		arguments []*argument
+	}
+)
+
+// responseKey is the key of the field in the response, its alias or name
+func (f *fieldNode) responseKey() string {
+	if f.alias != "" {
+		return f.alias
+	}
+	return f.name
+}
+
+// String returns the type as written in the document, such as [Int!]
+func (t *typeRef) String() string {
+	s := t.name
+	if t.list != nil {
+		s = "[" + t.list.String() + "]"
+	}
+	if t.nonNull {
+		s += "!"
+	}
+	return s
+}
+
+// value is a literal or variable of a document: a variable, int, float, string,
+// boolean, null, enum, list or object
+type value struct {
+	kind   valueKind
+	raw    string
+	list   []value
+	fields []objectField
+}
+
+type objectField struct {
+	name  string
+	value value
+}
+
+type valueKind int
+
+const (
+	valueNull valueKind = iota
+	valueVariable
+	valueInt
+	valueFloat
+	valueString
+	valueBoolean
+	valueEnum
+	valueList
+	valueObject
+)
+
+// syntaxError is an error of the document at a position
+type syntaxError struct {
+	msg       string
+	line, col int
+}
+
+func (e *syntaxError) Error() string {
+	return fmt.Sprintf("syntax error: %s (line %d, column %d)", e.msg, e.line, e.col)
+}
+
+// tokenKind is the kind of a lexical token
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunct
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind      tokenKind
+	value     string
+	line, col int
+}
+
+// lexer splits a document into tokens, skipping whitespace, commas and comments
+type lexer struct {
+	src       string
+	pos       int
+	line, col int
+}
+
+func (l *lexer) next() (token, error) {
+	l.skipIgnored()
+	tok := token{line: l.line, col: l.col}
+	if l.pos >= len(l.src) {
+		return tok, nil
+	}
+
+	c := l.src[l.pos]
+	switch {
+	case strings.IndexByte("!$&()=:@[]{}|", c) >= 0:
+		l.advance(1)
+		tok.kind, tok.value = tokenPunct, string(c)
+	case c == '.':
+		if !strings.HasPrefix(l.src[l.pos:], "...") {
+			return tok, l.errorf("unexpected %q", c)
+		}
+		l.advance(3)
+		tok.kind, tok.value = tokenPunct, "..."
+	case c == '_' || isLetter(c):
+		start := l.pos
+		for l.pos < len(l.src) && (l.src[l.pos] == '_' || isLetter(l.src[l.pos]) || isDigit(l.src[l.pos])) {
+			l.advance(1)
+		}
+		tok.kind, tok.value = tokenName, l.src[start:l.pos]
+	case c == '-' || isDigit(c):
+		return l.number(tok)
+	case c == '"':
+		return l.string(tok)
+	default:
+		r, _ := utf8.DecodeRuneInString(l.src[l.pos:])
+		return tok, l.errorf("unexpected %q", r)
+	}
+	return tok, nil
+}
+
+func (l *lexer) advance(n int) {
+	for range n {
+		if l.src[l.pos] == '\n' {
+			l.line++
+			l.col = 1
+		} else {
+			l.col++
+		}
+		l.pos++
+	}
+}
+
+func (l *lexer) skipIgnored() {
+	for l.pos < len(l.src) {
+		switch c := l.src[l.pos]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			l.advance(1)
+		case c == '#':
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.advance(1)
+			}
+		case strings.HasPrefix(l.src[l.pos:], "\ufeff"):
+			l.pos += len("\ufeff")
+		default:
+			return
+		}
+	}
+}
+
+func (l *lexer) number(tok token) (token, error) {
+	start := l.pos
+	if l.src[l.pos] == '-' {
+		l.advance(1)
+	}
+	digits := func() int {
+		n := 0
+		for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+			l.advance(1)
+			n++
+		}
+		return n
+	}
+	if digits() == 0 {
+		return tok, l.errorf("invalid number")
+	}
+	tok.kind = tokenInt
+	if l.pos < len(l.src) && l.src[l.pos] == '.' {
+		l.advance(1)
+		if digits() == 0 {
+			return tok, l.errorf("invalid number")
+		}
+		tok.kind = tokenFloat
+	}
+	if l.pos < len(l.src) && (l.src[l.pos] == 'e' || l.src[l.pos] == 'E') {
+		l.advance(1)
+		if l.pos < len(l.src) && (l.src[l.pos] == '+' || l.src[l.pos] == '-') {
+			l.advance(1)
+		}
+		if digits() == 0 {
+			return tok, l.errorf("invalid number")
+		}
+		tok.kind = tokenFloat
+	}
+	tok.value = l.src[start:l.pos]
+	return tok, nil
+}
+
+func (l *lexer) string(tok token) (token, error) {
+	tok.kind = tokenString
+	if strings.HasPrefix(l.src[l.pos:], `"""`) {
+		l.advance(3)
+		end := strings.Index(l.src[l.pos:], `"""`)
+		if end < 0 {
+			return tok, l.errorf("unterminated string")
+		}
+		tok.value = blockString(strings.ReplaceAll(l.src[l.pos:l.pos+end], `\"""`, `"""`))
+		l.advance(end + 3)
+		return tok, nil
+	}
+
+	l.advance(1)
+	var b strings.Builder
+	for {
+		if l.pos >= len(l.src) || l.src[l.pos] == '\n' {
+			return tok, l.errorf("unterminated string")
+		}
+		c := l.src[l.pos]
+		switch c {
+		case '"':
+			l.advance(1)
+			tok.value = b.String()
+			return tok, nil
+		case '\\':
+			if l.pos+1 >= len(l.src) {
+				return tok, l.errorf("unterminated string")
+			}
+			esc := l.src[l.pos+1]
+			if esc == 'u' {
+				if l.pos+6 > len(l.src) {
+					return tok, l.errorf("invalid unicode escape")
+				}
+				r, err := strconv.ParseUint(l.src[l.pos+2:l.pos+6], 16, 32)
+				if err != nil {
+					return tok, l.errorf("invalid unicode escape")
+				}
+				b.WriteRune(rune(r))
+				l.advance(6)
+				continue
+			}
+			replacement, ok := map[byte]string{'"': `"`, '\\': `\`, '/': "/", 'b': "\b", 'f': "\f", 'n': "\n", 'r': "\r", 't': "\t"}[esc]
+			if !ok {
+				return tok, l.errorf("invalid escape \\%c", esc)
+			}
+			b.WriteString(replacement)
+			l.advance(2)
+		default:
+			b.WriteByte(c)
+			l.advance(1)
+		}
+	}
+}
+
+func (l *lexer) errorf(format string, args ...any) error {
+	return &syntaxError{msg: fmt.Sprintf(format, args...), line: l.line, col: l.col}
+}
+
+// blockString removes the common indentation and the blank first and last lines of a
+// block string
+func blockString(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n")
+	indent := -1
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if n := len(line) - len(trimmed); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	if indent > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= indent {
+				lines[i] = lines[i][indent:]
+			} else {
+				lines[i] = ""
+			}
+		}
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func isLetter(c byte) bool { return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' }
+func isDigit(c byte) bool  { return c >= '0' && c <= '9' }
+
+// parser builds a document from the tokens of its source
+type parser struct {
+	lex lexer
+	tok token
+}
+
+// parse parses an executable GraphQL document
+func parse(src string) (*document, error) {
+	p := &parser{lex: lexer{src: src, line: 1, col: 1}}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	doc := &document{fragments: map[string]*fragment{}}
+	for p.tok.kind != tokenEOF {
+		switch {
+		case p.peek(tokenPunct, "{"), p.peek(tokenName, "query"), p.peek(tokenName, "mutation"), p.peek(tokenName, "subscription"):
+			op, err := p.operation()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, op)
+		case p.peek(tokenName, "fragment"):
+			f, err := p.fragment()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := doc.fragments[f.name]; ok {
+				return nil, fmt.Errorf("there can be only one fragment named %q", f.name)
+			}
+			doc.fragments[f.name] = f
+		default:
+			return nil, p.unexpected()
+		}
+	}
+	if len(doc.operations) == 0 {
+		return nil, fmt.Errorf("document has no operation")
+	}
+	return doc, nil
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) peek(kind tokenKind, v string) bool {
+	return p.tok.kind == kind && p.tok.value == v
+}
+
+// skip consumes the punctuator v when it is next
+func (p *parser) skip(v string) (bool, error) {
+	if !p.peek(tokenPunct, v) {
+		return false, nil
+	}
+	return true, p.advance()
+}
+
+func (p *parser) expect(v string) error {
+	if !p.peek(tokenPunct, v) {
+		return p.unexpected()
+	}
+	return p.advance()
+}
+
+func (p *parser) name() (string, error) {
+	if p.tok.kind != tokenName {
+		return "", p.unexpected()
+	}
+	name := p.tok.value
+	return name, p.advance()
+}
+
+func (p *parser) unexpected() error {
+	if p.tok.kind == tokenEOF {
+		return &syntaxError{msg: "unexpected end of document", line: p.tok.line, col: p.tok.col}
+	}
+	return &syntaxError{msg: fmt.Sprintf("unexpected %q", p.tok.value), line: p.tok.line, col: p.tok.col}
+}
+
+func (p *parser) operation() (*operation, error) {
+	op := &operation{kind: "query"}
+	if p.tok.kind == tokenName {
+		op.kind = p.tok.value
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if p.tok.kind == tokenName {
+			op.name = p.tok.value
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+		}
+		vars, err := p.variableDefs()
+		if err != nil {
+			return nil, err
+		}
+		op.variables = vars
+		if _, err := p.directives(); err != nil {
+			return nil, err
+		}
+	}
+	sel, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+	op.selection = sel
+	return op, nil
+}
+
+func (p *parser) variableDefs() ([]*variableDef, error) {
+	if ok, err := p.skip("("); !ok || err != nil {
+		return nil, err
+	}
+	var defs []*variableDef
+	for {
+		if ok, err := p.skip(")"); ok || err != nil {
+			return defs, err
+		}
+		if err := p.expect("$"); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		typ, err := p.typeRef()
+		if err != nil {
+			return nil, err
+		}
+		def := &variableDef{name: name, typ: typ}
+		if ok, err := p.skip("="); err != nil {
+			return nil, err
+		} else if ok {
+			if def.defaultValue, err = p.value(true); err != nil {
+				return nil, err
+			}
+		}
+		if _, err := p.directives(); err != nil {
+			return nil, err
+		}
+		defs = append(defs, def)
+	}
+}
+
+func (p *parser) typeRef() (*typeRef, error) {
+	var t *typeRef
+	if ok, err := p.skip("["); err != nil {
+		return nil, err
+	} else if ok {
+		elem, err := p.typeRef()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		t = &typeRef{list: elem}
+	} else {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		t = &typeRef{name: name}
+	}
+	ok, err := p.skip("!")
+	t.nonNull = ok
+	return t, err
+}
+
+func (p *parser) fragment() (*fragment, error) {
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if !p.peek(tokenName, "on") {
+		return nil, p.unexpected()
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	typeCondition, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.directives(); err != nil {
+		return nil, err
+	}
+	sel, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+	return &fragment{name: name, typeCondition: typeCondition, selection: sel}, nil
+}
+
+func (p *parser) selectionSet() ([]selection, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var sel []selection
+	for {
+		if ok, err := p.skip("}"); err != nil {
+			return nil, err
+		} else if ok {
+			if len(sel) == 0 {
+				return nil, &syntaxError{msg: "empty selection set", line: p.tok.line, col: p.tok.col}
+			}
+			return sel, nil
+		}
+		s, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+		sel = append(sel, s)
+	}
+}
+
+func (p *parser) selection() (selection, error) {
+	if ok, err := p.skip("..."); err != nil {
+		return nil, err
+	} else if ok {
+		return p.fragmentSelection()
+	}
+
+	f := &fieldNode{line: p.tok.line, col: p.tok.col}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if ok, err := p.skip(":"); err != nil {
+		return nil, err
+	} else if ok {
+		f.alias = name
+		if name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	f.name = name
+	if f.arguments, err = p.arguments(false); err != nil {
+		return nil, err
+	}
+	if f.directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	if p.peek(tokenPunct, "{") {
+		if f.selection, err = p.selectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+func (p *parser) fragmentSelection() (selection, error) {
+	if p.tok.kind == tokenName && p.tok.value != "on" {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		dirs, err := p.directives()
+		if err != nil {
+			return nil, err
+		}
+		return &fragmentSpread{name: name, directives: dirs}, nil
+	}
+
+	f := &inlineFragment{}
+	if p.peek(tokenName, "on") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		f.typeCondition = name
+	}
+	var err error
+	if f.directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	if f.selection, err = p.selectionSet(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (p *parser) arguments(constant bool) ([]*argument, error) {
+	if ok, err := p.skip("("); !ok || err != nil {
+		return nil, err
+	}
+	var args []*argument
+	for {
+		if ok, err := p.skip(")"); ok || err != nil {
+			return args, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		v, err := p.value(constant)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, &argument{name: name, value: v})
+	}
+}
+
+func (p *parser) directives() ([]*directive, error) {
+	var dirs []*directive
+	for p.peek(tokenPunct, "@") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		args, err := p.arguments(false)
+		if err != nil {
+			return nil, err
+		}
+		dirs = append(dirs, &directive{name: name, arguments: args})
+	}
+	return dirs, nil
+}
+
+func (p *parser) value(constant bool) (value, error) {
+	tok := p.tok
+	switch tok.kind {
+	case tokenPunct:
+		switch tok.value {
+		case "$":
+			if constant {
+				return value{}, p.unexpected()
+			}
+			if err := p.advance(); err != nil {
+				return value{}, err
+			}
+			name, err := p.name()
+			return value{kind: valueVariable, raw: name}, err
+		case "[":
+			if err := p.advance(); err != nil {
+				return value{}, err
+			}
+			v := value{kind: valueList}
+			for {
+				if ok, err := p.skip("]"); ok || err != nil {
+					return v, err
+				}
+				item, err := p.value(constant)
+				if err != nil {
+					return value{}, err
+				}
+				v.list = append(v.list, item)
+			}
+		case "{":
+			if err := p.advance(); err != nil {
+				return value{}, err
+			}
+			v := value{kind: valueObject}
+			for {
+				if ok, err := p.skip("}"); ok || err != nil {
+					return v, err
+				}
+				name, err := p.name()
+				if err != nil {
+					return value{}, err
+				}
+				if err := p.expect(":"); err != nil {
+					return value{}, err
+				}
+				item, err := p.value(constant)
+				if err != nil {
+					return value{}, err
+				}
+				v.fields = append(v.fields, objectField{name: name, value: item})
+			}
+		}
+	case tokenInt:
+		return value{kind: valueInt, raw: tok.value}, p.advance()
+	case tokenFloat:
+		return value{kind: valueFloat, raw: tok.value}, p.advance()
+	case tokenString:
+		return value{kind: valueString, raw: tok.value}, p.advance()
+	case tokenName:
+		switch tok.value {
+		case "true", "false":
+			return value{kind: valueBoolean, raw: tok.value}, p.advance()
+		case "null":
+			return value{kind: valueNull}, p.advance()
+		}
+		return value{kind: valueEnum, raw: tok.value}, p.advance()
+	}
+	return value{}, p.unexpected()
+}
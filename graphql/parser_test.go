@@ -0,0 +1,81 @@
+package graphql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	// Arrange
+	src := `
+		# The order with its items
+		query Get($id: String! = "1", $ids: [Int!]) {
+			order: getOrder(id: $id, filter: {tags: ["a", """b"""], limit: -1.5e3, active: true, status: OPEN, none: null}) {
+				...ids
+				... on Order @include(if: true) { total }
+			}
+		}
+		fragment ids on Order { id }
+	`
+
+	// Act
+	doc, err := parse(src)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, doc.operations, 1)
+	op := doc.operations[0]
+	assert.Equal(t, "query", op.kind)
+	assert.Equal(t, "Get", op.name)
+	require.Len(t, op.variables, 2)
+	assert.Equal(t, "String!", op.variables[0].typ.String())
+	assert.Equal(t, `1`, op.variables[0].defaultValue.raw)
+	assert.Equal(t, "[Int!]", op.variables[1].typ.String())
+
+	require.Len(t, op.selection, 1)
+	f := op.selection[0].(*fieldNode)
+	assert.Equal(t, "order", f.responseKey())
+	assert.Equal(t, "getOrder", f.name)
+	assert.Equal(t, 4, f.line)
+	require.Len(t, f.arguments, 2)
+	assert.Equal(t, valueVariable, f.arguments[0].value.kind)
+	filter := f.arguments[1].value
+	require.Equal(t, valueObject, filter.kind)
+	require.Len(t, filter.fields, 5)
+	assert.Equal(t, "b", filter.fields[0].value.list[1].raw)
+	assert.Equal(t, valueFloat, filter.fields[1].value.kind)
+	assert.Equal(t, valueBoolean, filter.fields[2].value.kind)
+	assert.Equal(t, valueEnum, filter.fields[3].value.kind)
+	assert.Equal(t, valueNull, filter.fields[4].value.kind)
+
+	require.Len(t, f.selection, 2)
+	assert.Equal(t, "ids", f.selection[0].(*fragmentSpread).name)
+	assert.Equal(t, "Order", f.selection[1].(*inlineFragment).typeCondition)
+	assert.Equal(t, "Order", doc.fragments["ids"].typeCondition)
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{name: "no operation", src: `fragment ids on Order { id }`},
+		{name: "unclosed selection", src: `{ getOrder {`},
+		{name: "unterminated string", src: `{ getOrder(id: "1) { id } }`},
+		{name: "invalid number", src: `{ getOrder(id: 1.) { id } }`},
+		{name: "variable in constant", src: `query($id: String = $other) { getOrder { id } }`},
+		{name: "duplicate fragment", src: `{ a } fragment f on Order { id } fragment f on Order { id }`},
+		{name: "schema definition", src: `type Order { id: ID }`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, err := parse(tt.src)
+
+			// Assert
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,450 @@
+package graphql
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/legrch/netgex/internal/descriptors"
+)
+
+// typeKind is the kind of a GraphQL type, as named by introspection
+type typeKind string
+
+const (
+	kindScalar      typeKind = "SCALAR"
+	kindObject      typeKind = "OBJECT"
+	kindEnum        typeKind = "ENUM"
+	kindInputObject typeKind = "INPUT_OBJECT"
+	kindList        typeKind = "LIST"
+	kindNonNull     typeKind = "NON_NULL"
+)
+
+// gqlType is a named GraphQL type, or a list or non-null wrapper of one
+type gqlType struct {
+	kind   typeKind
+	name   string
+	desc   string
+	fields []*field
+	values []*enumValue
+	ofType *gqlType
+}
+
+// field is a field of an object or input object, or an argument
+type field struct {
+	name         string
+	desc         string
+	typ          *gqlType
+	args         []*field
+	deprecated   string
+	defaultValue string
+
+	// method is the RPC a root field calls
+	method *method
+	// isMap marks proto map fields, served as lists of key and value entries
+	isMap bool
+}
+
+type enumValue struct {
+	name       string
+	deprecated string
+}
+
+// method is a unary gRPC method served as a root field
+type method struct {
+	rpc    string
+	input  protoreflect.MessageType
+	output protoreflect.MessageType
+}
+
+// field returns the field of an object or input object
+func (t *gqlType) field(name string) *field {
+	for _, f := range t.fields {
+		if f.name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// named returns the named type a wrapper type wraps
+func (t *gqlType) named() *gqlType {
+	for t.ofType != nil {
+		t = t.ofType
+	}
+	return t
+}
+
+// String returns the type as written in documents, such as [Order!]
+func (t *gqlType) String() string {
+	switch t.kind {
+	case kindList:
+		return "[" + t.ofType.String() + "]"
+	case kindNonNull:
+		return t.ofType.String() + "!"
+	}
+	return t.name
+}
+
+func listOf(t *gqlType) *gqlType    { return &gqlType{kind: kindList, ofType: t} }
+func nonNullOf(t *gqlType) *gqlType { return &gqlType{kind: kindNonNull, ofType: t} }
+
+// The built-in scalars and the JSON scalar of dynamic values such as google.protobuf.Struct
+var (
+	stringType  = &gqlType{kind: kindScalar, name: "String"}
+	intType     = &gqlType{kind: kindScalar, name: "Int"}
+	floatType   = &gqlType{kind: kindScalar, name: "Float"}
+	booleanType = &gqlType{kind: kindScalar, name: "Boolean"}
+	idType      = &gqlType{kind: kindScalar, name: "ID"}
+	jsonType    = &gqlType{kind: kindScalar, name: "JSON", desc: "A JSON value, as encoded by protojson"}
+)
+
+// wellKnownTypes maps the well-known types to the scalars of their JSON encoding
+var wellKnownTypes = map[protoreflect.FullName]*gqlType{
+	"google.protobuf.Timestamp":   stringType,
+	"google.protobuf.Duration":    stringType,
+	"google.protobuf.FieldMask":   stringType,
+	"google.protobuf.StringValue": stringType,
+	"google.protobuf.BytesValue":  stringType,
+	"google.protobuf.Int64Value":  stringType,
+	"google.protobuf.UInt64Value": stringType,
+	"google.protobuf.Int32Value":  intType,
+	"google.protobuf.UInt32Value": intType,
+	"google.protobuf.FloatValue":  floatType,
+	"google.protobuf.DoubleValue": floatType,
+	"google.protobuf.BoolValue":   booleanType,
+	"google.protobuf.Struct":      jsonType,
+	"google.protobuf.Value":       jsonType,
+	"google.protobuf.ListValue":   jsonType,
+	"google.protobuf.Any":         jsonType,
+	"google.protobuf.Empty":       jsonType,
+}
+
+// Schema is the GraphQL schema of gRPC services: a root field per unary method, with
+// the request fields as arguments and the response message as its type. Methods without
+// side effects are queries, the others mutations.
+type Schema struct {
+	types    map[string]*gqlType
+	query    *gqlType
+	mutation *gqlType
+
+	// schemaField and typeField are the __schema and __type meta-fields of the query type
+	schemaField *field
+	typeField   *field
+
+	// maxDepth and maxComplexity bound the nesting and the number of fields of
+	// operations, fragments expanded; 0 means no limit
+	maxDepth      int
+	maxComplexity int
+}
+
+// schemaBuilder derives the GraphQL types of proto messages and enums
+type schemaBuilder struct {
+	schema  *Schema
+	names   map[protoreflect.FullName]string
+	outputs map[protoreflect.FullName]*gqlType
+	inputs  map[protoreflect.FullName]*gqlType
+}
+
+// NewSchema builds the schema of the named gRPC services from the descriptors in the
+// global registry. Streaming methods are left out.
+func NewSchema(services ...string) (*Schema, error) {
+	var sds []protoreflect.ServiceDescriptor
+	for _, name := range services {
+		desc, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(name))
+		if err != nil {
+			return nil, fmt.Errorf("graphql: service %q: %w", name, err)
+		}
+		sd, ok := desc.(protoreflect.ServiceDescriptor)
+		if !ok {
+			return nil, fmt.Errorf("graphql: %q is not a service", name)
+		}
+		sds = append(sds, sd)
+	}
+
+	s := &Schema{
+		types:    map[string]*gqlType{},
+		query:    &gqlType{kind: kindObject, name: "Query"},
+		mutation: &gqlType{kind: kindObject, name: "Mutation"},
+	}
+	for _, t := range []*gqlType{stringType, intType, floatType, booleanType, idType, jsonType, s.query, s.mutation} {
+		s.types[t.name] = t
+	}
+	b := &schemaBuilder{
+		schema:  s,
+		names:   typeNames(sds),
+		outputs: map[protoreflect.FullName]*gqlType{},
+		inputs:  map[protoreflect.FullName]*gqlType{},
+	}
+
+	fieldNames := rootFieldNames(sds)
+	for _, sd := range sds {
+		methods := sd.Methods()
+		for i := range methods.Len() {
+			md := methods.Get(i)
+			if md.IsStreamingClient() || md.IsStreamingServer() {
+				continue
+			}
+			root := s.mutation
+			if isQuery(md) {
+				root = s.query
+			}
+			root.fields = append(root.fields, b.rootField(fieldNames[md.FullName()], md))
+		}
+	}
+
+	// A schema needs a query type with at least one field
+	if len(s.query.fields) == 0 {
+		s.query.fields = append(s.query.fields, &field{name: "_empty", typ: booleanType, desc: "The services have no queries"})
+	}
+	if len(s.mutation.fields) == 0 {
+		delete(s.types, s.mutation.name)
+		s.mutation = nil
+	}
+	addIntrospectionTypes(s)
+	return s, nil
+}
+
+// rootField returns the root field calling a method, taking the request fields as
+// arguments
+func (b *schemaBuilder) rootField(name string, md protoreflect.MethodDescriptor) *field {
+	f := &field{
+		name: name,
+		typ:  b.messageType(md.Output(), false),
+		method: &method{
+			rpc:    "/" + string(md.Parent().FullName()) + "/" + string(md.Name()),
+			input:  descriptors.MessageType(md.Input()),
+			output: descriptors.MessageType(md.Output()),
+		},
+	}
+	if opts, ok := md.Options().(*descriptorpb.MethodOptions); ok && opts.GetDeprecated() {
+		f.deprecated = "Deprecated in the proto"
+	}
+	if _, wkt := wellKnownTypes[md.Input().FullName()]; !wkt {
+		f.args = b.fields(md.Input(), true)
+	}
+	return f
+}
+
+// messageType returns the object, or input object, of a message
+func (b *schemaBuilder) messageType(md protoreflect.MessageDescriptor, input bool) *gqlType {
+	if t, ok := wellKnownTypes[md.FullName()]; ok {
+		return t
+	}
+	if md.Fields().Len() == 0 {
+		return jsonType
+	}
+	memo, kind, name := b.outputs, kindObject, b.names[md.FullName()]
+	if input {
+		memo, kind, name = b.inputs, kindInputObject, name+"Input"
+	}
+	if t, ok := memo[md.FullName()]; ok {
+		return t
+	}
+
+	// Register the type before its fields, which may refer to it
+	t := &gqlType{kind: kind, name: name}
+	memo[md.FullName()] = t
+	b.schema.types[name] = t
+	t.fields = b.fields(md, input)
+	return t
+}
+
+// fields returns the fields of a message
+func (b *schemaBuilder) fields(md protoreflect.MessageDescriptor, input bool) []*field {
+	fds := md.Fields()
+	fields := make([]*field, 0, fds.Len())
+	for i := range fds.Len() {
+		fd := fds.Get(i)
+		f := &field{name: fd.JSONName(), typ: b.fieldType(fd, input), isMap: fd.IsMap()}
+		if opts, ok := fd.Options().(*descriptorpb.FieldOptions); ok && opts.GetDeprecated() {
+			f.deprecated = "Deprecated in the proto"
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// fieldType returns the type of a field: maps are lists of their entries
+func (b *schemaBuilder) fieldType(fd protoreflect.FieldDescriptor, input bool) *gqlType {
+	if fd.IsMap() {
+		return listOf(nonNullOf(b.messageType(fd.Message(), input)))
+	}
+	t := b.kindType(fd, input)
+	if fd.IsList() {
+		return listOf(nonNullOf(t))
+	}
+	return t
+}
+
+// kindType returns the scalar, enum or message type of a field, following the JSON
+// encoding of protojson: 64-bit integers are strings
+func (b *schemaBuilder) kindType(fd protoreflect.FieldDescriptor, input bool) *gqlType {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return booleanType
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return intType
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return floatType
+	case protoreflect.EnumKind:
+		return b.enumType(fd.Enum())
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return b.messageType(fd.Message(), input)
+	default:
+		// 64-bit integers, strings and base64 bytes
+		return stringType
+	}
+}
+
+// enumType returns the enum of a proto enum; enum values are shared by inputs and
+// outputs
+func (b *schemaBuilder) enumType(ed protoreflect.EnumDescriptor) *gqlType {
+	if ed.FullName() == "google.protobuf.NullValue" {
+		return jsonType
+	}
+	if t, ok := b.outputs[ed.FullName()]; ok {
+		return t
+	}
+	t := &gqlType{kind: kindEnum, name: b.names[ed.FullName()]}
+	values := ed.Values()
+	for i := range values.Len() {
+		v := &enumValue{name: string(values.Get(i).Name())}
+		if opts, ok := values.Get(i).Options().(*descriptorpb.EnumValueOptions); ok && opts.GetDeprecated() {
+			v.deprecated = "Deprecated in the proto"
+		}
+		t.values = append(t.values, v)
+	}
+	b.outputs[ed.FullName()] = t
+	b.schema.types[t.name] = t
+	return t
+}
+
+// typeNames names the messages and enums the services use by their name within their
+// package, such as Order_Item, or by their full name with underscores when two of them
+// have the same name
+func typeNames(sds []protoreflect.ServiceDescriptor) map[protoreflect.FullName]string {
+	seen := map[protoreflect.FullName]protoreflect.Descriptor{}
+	var visitMessage func(md protoreflect.MessageDescriptor)
+	visitMessage = func(md protoreflect.MessageDescriptor) {
+		if _, ok := seen[md.FullName()]; ok {
+			return
+		}
+		seen[md.FullName()] = md
+		fields := md.Fields()
+		for i := range fields.Len() {
+			fd := fields.Get(i)
+			if fd.Message() != nil {
+				visitMessage(fd.Message())
+			}
+			if fd.Enum() != nil {
+				seen[fd.Enum().FullName()] = fd.Enum()
+			}
+		}
+	}
+	for _, sd := range sds {
+		methods := sd.Methods()
+		for i := range methods.Len() {
+			visitMessage(methods.Get(i).Input())
+			visitMessage(methods.Get(i).Output())
+		}
+	}
+
+	short := func(d protoreflect.Descriptor) string {
+		name := strings.TrimPrefix(string(d.FullName()), string(d.ParentFile().Package())+".")
+		return strings.ReplaceAll(name, ".", "_")
+	}
+	counts := map[string]int{}
+	for _, d := range seen {
+		counts[short(d)]++
+	}
+	names := make(map[protoreflect.FullName]string, len(seen))
+	for fullName, d := range seen {
+		name := short(d)
+		if counts[name] > 1 || isReservedName(name) {
+			name = strings.ReplaceAll(string(fullName), ".", "_")
+		}
+		names[fullName] = name
+	}
+	return names
+}
+
+// isReservedName reports whether a type name would collide with a built-in type
+func isReservedName(name string) bool {
+	switch name {
+	case "Query", "Mutation", "String", "Int", "Float", "Boolean", "ID", "JSON":
+		return true
+	}
+	return strings.HasPrefix(name, "__")
+}
+
+// rootFieldNames names the root fields of methods by their lower camel case name, such
+// as getOrder, prefixed with their service when two services have a method of that name
+func rootFieldNames(sds []protoreflect.ServiceDescriptor) map[protoreflect.FullName]string {
+	counts := map[string]int{}
+	for _, sd := range sds {
+		methods := sd.Methods()
+		for i := range methods.Len() {
+			counts[lowerFirst(string(methods.Get(i).Name()))]++
+		}
+	}
+	names := map[protoreflect.FullName]string{}
+	for _, sd := range sds {
+		methods := sd.Methods()
+		for i := range methods.Len() {
+			md := methods.Get(i)
+			name := lowerFirst(string(md.Name()))
+			if counts[name] > 1 {
+				name = lowerFirst(string(sd.Name())) + string(md.Name())
+			}
+			names[md.FullName()] = name
+		}
+	}
+	return names
+}
+
+// isQuery reports whether a method reads without side effects: it is marked with
+// idempotency_level NO_SIDE_EFFECTS, bound to GET, or named Get, List, Search or BatchGet
+func isQuery(md protoreflect.MethodDescriptor) bool {
+	if opts, ok := md.Options().(*descriptorpb.MethodOptions); ok {
+		if opts.GetIdempotencyLevel() == descriptorpb.MethodOptions_NO_SIDE_EFFECTS {
+			return true
+		}
+		if rule, ok := proto.GetExtension(opts, annotations.E_Http).(*annotations.HttpRule); ok && rule.GetGet() != "" {
+			return true
+		}
+	}
+	name := string(md.Name())
+	for _, prefix := range []string{"BatchGet", "Get", "List", "Search"} {
+		rest, ok := strings.CutPrefix(name, prefix)
+		if ok && (rest == "" || unicode.IsUpper(rune(rest[0]))) {
+			return true
+		}
+	}
+	return false
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+// sortedTypes returns the named types of the schema sorted by name
+func (s *Schema) sortedTypes() []*gqlType {
+	types := make([]*gqlType, 0, len(s.types))
+	for _, t := range s.types {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].name < types[j].name })
+	return types
+}
@@ -0,0 +1,76 @@
+package graphql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSchema(t *testing.T) {
+	// Act
+	s, err := NewSchema(ordersService)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, s.query.field("getOrder"))
+	assert.Nil(t, s.query.field("createOrder"))
+	require.NotNil(t, s.mutation.field("createOrder"))
+
+	createOrder := s.mutation.field("createOrder")
+	assert.Equal(t, "OrderInput", createOrder.args[0].typ.String())
+	assert.Equal(t, "/graphqltest.v1.Orders/CreateOrder", createOrder.method.rpc)
+	assert.Equal(t, "[Order_LabelsEntry!]", s.types["Order"].field("labels").typ.String())
+	assert.Equal(t, "String", s.types["Order"].field("total").typ.String())
+	assert.Equal(t, "Deprecated in the proto", s.types["Order"].field("legacyNote").deprecated)
+}
+
+func TestNewSchema_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		service string
+		wantErr string
+	}{
+		{name: "unknown service", service: "graphqltest.v1.Unknown", wantErr: "not found"},
+		{name: "not a service", service: "graphqltest.v1.Order", wantErr: "not a service"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, err := NewSchema(tt.service)
+
+			// Assert
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestNewSchema_HealthQueries(t *testing.T) {
+	// Act
+	s, err := NewSchema("grpc.health.v1.Health")
+
+	// Assert
+	require.NoError(t, err)
+	assert.NotNil(t, s.query.field("_empty"), "Check is not a query and Watch is streaming")
+	assert.NotNil(t, s.mutation.field("check"))
+	assert.Nil(t, s.mutation.field("watch"))
+}
+
+func TestSchema_SDL(t *testing.T) {
+	// Arrange
+	s, err := NewSchema(ordersService)
+	require.NoError(t, err)
+
+	// Act
+	sdl := s.SDL()
+
+	// Assert
+	assert.Contains(t, sdl, "schema {\n  query: Query\n  mutation: Mutation\n}\n")
+	assert.Contains(t, sdl, "enum Status {\n  STATUS_UNSPECIFIED\n  STATUS_OPEN\n  STATUS_CLOSED\n}\n")
+	assert.Contains(t, sdl, "  legacyNote: String @deprecated(reason: \"Deprecated in the proto\")\n")
+	assert.Contains(t, sdl, "input OrderInput {\n")
+	assert.Contains(t, sdl, "  createOrder(order: OrderInput): Order\n")
+	assert.NotContains(t, sdl, "__Schema")
+	assert.NotContains(t, sdl, "scalar String")
+}
@@ -0,0 +1,82 @@
+package graphql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SDL returns the schema in the GraphQL schema definition language, for clients and
+// code generators. Built-in scalars and introspection types are left out.
+func (s *Schema) SDL() string {
+	var b strings.Builder
+	b.WriteString("schema {\n  query: Query\n")
+	if s.mutation != nil {
+		b.WriteString("  mutation: Mutation\n")
+	}
+	b.WriteString("}\n")
+
+	for _, t := range s.sortedTypes() {
+		if isIntrospectionType(t) || isBuiltinScalar(t) {
+			continue
+		}
+		b.WriteString("\n")
+		if t.desc != "" {
+			fmt.Fprintf(&b, "%q\n", t.desc)
+		}
+		switch t.kind {
+		case kindScalar:
+			fmt.Fprintf(&b, "scalar %s\n", t.name)
+		case kindEnum:
+			fmt.Fprintf(&b, "enum %s {\n", t.name)
+			for _, v := range t.values {
+				fmt.Fprintf(&b, "  %s%s\n", v.name, deprecatedDirective(v.deprecated))
+			}
+			b.WriteString("}\n")
+		case kindObject, kindInputObject:
+			keyword := "type"
+			if t.kind == kindInputObject {
+				keyword = "input"
+			}
+			fmt.Fprintf(&b, "%s %s {\n", keyword, t.name)
+			for _, f := range t.fields {
+				if f.desc != "" {
+					fmt.Fprintf(&b, "  %q\n", f.desc)
+				}
+				fmt.Fprintf(&b, "  %s%s: %s%s\n", f.name, sdlArguments(f.args), f.typ, deprecatedDirective(f.deprecated))
+			}
+			b.WriteString("}\n")
+		}
+	}
+	return b.String()
+}
+
+// sdlArguments returns the argument list of a field, or nothing without arguments
+func sdlArguments(args []*field) string {
+	if len(args) == 0 {
+		return ""
+	}
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		parts[i] = fmt.Sprintf("%s: %s", arg.name, arg.typ)
+		if arg.defaultValue != "" {
+			parts[i] += " = " + arg.defaultValue
+		}
+		parts[i] += deprecatedDirective(arg.deprecated)
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+func deprecatedDirective(reason string) string {
+	if reason == "" {
+		return ""
+	}
+	return fmt.Sprintf(" @deprecated(reason: %q)", reason)
+}
+
+func isBuiltinScalar(t *gqlType) bool {
+	switch t {
+	case stringType, intType, floatType, booleanType, idType:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,260 @@
+package graphql
+
+import (
+	"fmt"
+)
+
+// maxMeasuredFields bounds the field count of selections, so counting the fields of
+// fragments spread many times over does not overflow
+const maxMeasuredFields = 1 << 30
+
+// validator checks an operation against the schema before it is executed
+type validator struct {
+	schema  *Schema
+	doc     *document
+	defined map[string]bool
+	// visiting holds the fragments being validated, validated those done with: each
+	// fragment is validated once, however many times it is spread
+	visiting  map[string]bool
+	validated map[string]bool
+	measured  map[string]measure
+	errors    []*gqlError
+}
+
+// measure is the depth and field count of a selection, fragments expanded
+type measure struct {
+	depth  int
+	fields int
+}
+
+// validate returns the errors of an operation: unknown fields, arguments, fragments,
+// directives and variables, selections that do not fit the types of their fields, and
+// selections nested deeper or selecting more fields than the schema allows
+func (s *Schema) validate(doc *document, op *operation) []*gqlError {
+	v := &validator{
+		schema:    s,
+		doc:       doc,
+		defined:   map[string]bool{},
+		visiting:  map[string]bool{},
+		validated: map[string]bool{},
+		measured:  map[string]measure{},
+	}
+	for _, def := range op.variables {
+		if v.defined[def.name] {
+			v.errorf(nil, "There can be only one variable named \"$%s\".", def.name)
+		}
+		v.defined[def.name] = true
+		named := def.typ
+		for named.list != nil {
+			named = named.list
+		}
+		t, ok := s.types[named.name]
+		if !ok || t.kind == kindObject {
+			v.errorf(nil, "Variable \"$%s\" cannot be of type \"%s\".", def.name, named.name)
+		}
+	}
+
+	root := s.query
+	if op.kind == "mutation" {
+		root = s.mutation
+	}
+	v.selection(root, op.selection)
+	if len(v.errors) > 0 {
+		return v.errors
+	}
+
+	// Fragments no longer spread within themselves, measure the expanded selection
+	m := v.measure(op.selection)
+	if s.maxDepth > 0 && m.depth > s.maxDepth {
+		v.errorf(nil, "Query depth %d exceeds the maximum depth of %d.", m.depth, s.maxDepth)
+	}
+	if s.maxComplexity > 0 && m.fields > s.maxComplexity {
+		v.errorf(nil, "Query selects more than the maximum of %d fields.", s.maxComplexity)
+	}
+	return v.errors
+}
+
+func (v *validator) errorf(f *fieldNode, format string, args ...any) {
+	err := &gqlError{Message: fmt.Sprintf(format, args...)}
+	if f != nil {
+		err.Locations = []location{{Line: f.line, Column: f.col}}
+	}
+	v.errors = append(v.errors, err)
+}
+
+func (v *validator) selection(parent *gqlType, sel []selection) {
+	for _, s := range sel {
+		switch s := s.(type) {
+		case *fieldNode:
+			v.field(parent, s)
+		case *fragmentSpread:
+			v.directives(nil, s.directives)
+			frag, ok := v.doc.fragments[s.name]
+			if !ok {
+				v.errorf(nil, "Unknown fragment \"%s\".", s.name)
+				continue
+			}
+			if v.visiting[s.name] {
+				v.errorf(nil, "Cannot spread fragment \"%s\" within itself.", s.name)
+				continue
+			}
+			if !v.fragmentApplies(parent, frag.typeCondition) {
+				v.errorf(nil, "Fragment \"%s\" cannot be spread here as objects of type \"%s\" can never be of type \"%s\".",
+					s.name, parent.name, frag.typeCondition)
+				continue
+			}
+			if v.validated[s.name] {
+				continue
+			}
+			v.visiting[s.name] = true
+			v.selection(parent, frag.selection)
+			delete(v.visiting, s.name)
+			v.validated[s.name] = true
+		case *inlineFragment:
+			v.directives(nil, s.directives)
+			if !v.fragmentApplies(parent, s.typeCondition) {
+				v.errorf(nil, "Fragment cannot be spread here as objects of type \"%s\" can never be of type \"%s\".",
+					parent.name, s.typeCondition)
+				continue
+			}
+			v.selection(parent, s.selection)
+		}
+	}
+}
+
+// measure returns the depth and field count of a selection, with the fragments spread
+// in it expanded. The measures of fragments are kept, so fragments spread many times
+// are measured once.
+func (v *validator) measure(sel []selection) measure {
+	var total measure
+	for _, s := range sel {
+		var m measure
+		switch s := s.(type) {
+		case *fieldNode:
+			m = v.measure(s.selection)
+			m.depth++
+			m.fields++
+		case *fragmentSpread:
+			var ok bool
+			if m, ok = v.measured[s.name]; !ok {
+				m = v.measure(v.doc.fragments[s.name].selection)
+				v.measured[s.name] = m
+			}
+		case *inlineFragment:
+			m = v.measure(s.selection)
+		}
+		total.depth = max(total.depth, m.depth)
+		total.fields = min(total.fields+m.fields, maxMeasuredFields)
+	}
+	return total
+}
+
+// fragmentApplies reports whether a fragment on a type condition applies to objects of
+// the parent type; the schema has no interfaces or unions
+func (v *validator) fragmentApplies(parent *gqlType, typeCondition string) bool {
+	return typeCondition == "" || typeCondition == parent.name
+}
+
+func (v *validator) field(parent *gqlType, f *fieldNode) {
+	v.directives(f, f.directives)
+	if f.name == "__typename" {
+		if len(f.arguments) > 0 || len(f.selection) > 0 {
+			v.errorf(f, "Field \"__typename\" takes no arguments or subfields.")
+		}
+		return
+	}
+
+	def := v.schema.fieldDef(parent, f.name)
+	if def == nil {
+		v.errorf(f, "Cannot query field \"%s\" on type \"%s\".", f.name, parent.name)
+		return
+	}
+	v.arguments(f, fmt.Sprintf("%s.%s", parent.name, f.name), def.args, f.arguments)
+
+	named := def.typ.named()
+	switch {
+	case named.kind == kindObject && len(f.selection) == 0:
+		v.errorf(f, "Field \"%s\" of type \"%s\" must have a selection of subfields.", f.name, def.typ)
+	case named.kind != kindObject && len(f.selection) > 0:
+		v.errorf(f, "Field \"%s\" must not have a selection since type \"%s\" has no subfields.", f.name, def.typ)
+	case named.kind == kindObject:
+		v.selection(named, f.selection)
+	}
+}
+
+func (v *validator) arguments(f *fieldNode, owner string, defs []*field, args []*argument) {
+	given := map[string]bool{}
+	for _, arg := range args {
+		given[arg.name] = true
+		if argDef(defs, arg.name) == nil {
+			v.errorf(f, "Unknown argument \"%s\" on \"%s\".", arg.name, owner)
+		}
+		v.variables(f, arg.value)
+	}
+	for _, def := range defs {
+		if def.typ.kind == kindNonNull && def.defaultValue == "" && !given[def.name] {
+			v.errorf(f, "Argument \"%s\" of type \"%s\" is required on \"%s\".", def.name, def.typ, owner)
+		}
+	}
+}
+
+func (v *validator) directives(f *fieldNode, dirs []*directive) {
+	for _, d := range dirs {
+		def := directiveByName(d.name)
+		if def == nil || (d.name != "skip" && d.name != "include") {
+			v.errorf(f, "Unknown directive \"@%s\".", d.name)
+			continue
+		}
+		v.arguments(f, "@"+d.name, def.args, d.arguments)
+	}
+}
+
+// variables checks that the variables a value refers to are defined
+func (v *validator) variables(f *fieldNode, val value) {
+	switch val.kind {
+	case valueVariable:
+		if !v.defined[val.raw] {
+			v.errorf(f, "Variable \"$%s\" is not defined.", val.raw)
+		}
+	case valueList:
+		for _, item := range val.list {
+			v.variables(f, item)
+		}
+	case valueObject:
+		for _, field := range val.fields {
+			v.variables(f, field.value)
+		}
+	}
+}
+
+// fieldDef returns the definition of a field of a type, including the meta-fields of
+// the query type
+func (s *Schema) fieldDef(parent *gqlType, name string) *field {
+	if parent == s.query {
+		switch name {
+		case "__schema":
+			return s.schemaField
+		case "__type":
+			return s.typeField
+		}
+	}
+	return parent.field(name)
+}
+
+func argDef(defs []*field, name string) *field {
+	for _, def := range defs {
+		if def.name == name {
+			return def
+		}
+	}
+	return nil
+}
+
+func directiveByName(name string) *directiveDef {
+	for _, d := range directives {
+		if d.name == name {
+			return d
+		}
+	}
+	return nil
+}
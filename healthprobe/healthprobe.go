@@ -0,0 +1,81 @@
+// Package healthprobe checks the gRPC health of a server, like grpc_health_probe, so a
+// service binary can serve as its own Docker HEALTHCHECK or Kubernetes exec probe:
+//
+//	if len(os.Args) > 1 && os.Args[1] == "healthprobe" {
+//		os.Exit(healthprobe.Main(os.Args[2:], os.Stderr))
+//	}
+package healthprobe
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Exit codes returned by Main
+const (
+	ExitHealthy   = 0
+	ExitUnhealthy = 1
+	ExitUsage     = 2
+)
+
+// DefaultTimeout bounds a health check when no timeout is given
+const DefaultTimeout = time.Second
+
+// ErrNotServing is returned by Check when the service is not SERVING
+var ErrNotServing = errors.New("service not serving")
+
+// Check asks the gRPC health service at address for the status of service, or of the
+// whole server when service is empty, and returns nil when it is SERVING. The
+// connection is plaintext unless opts set transport credentials.
+func Check(ctx context.Context, address, service string, opts ...grpc.DialOption) error {
+	opts = append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, opts...)
+	conn, err := grpc.NewClient(address, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	resp, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: service})
+	if err != nil {
+		return fmt.Errorf("health check failed: %w", err)
+	}
+	if resp.GetStatus() != grpc_health_v1.HealthCheckResponse_SERVING {
+		return fmt.Errorf("%w: %s", ErrNotServing, resp.GetStatus())
+	}
+	return nil
+}
+
+// Main runs a health check configured by command line arguments and returns the exit
+// code: ExitHealthy, ExitUnhealthy, or ExitUsage for invalid arguments. Failures are
+// written to stderr.
+func Main(args []string, stderr io.Writer) int {
+	flags := flag.NewFlagSet("healthprobe", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	addr := flags.String("addr", "127.0.0.1:9090", "gRPC server address")
+	service := flags.String("service", "", "service to check; empty checks the whole server")
+	timeout := flags.Duration("timeout", DefaultTimeout, "health check timeout")
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+	if flags.NArg() > 0 {
+		fmt.Fprintf(stderr, "healthprobe: unexpected arguments %v\n", flags.Args())
+		return ExitUsage
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	if err := Check(ctx, *addr, *service); err != nil {
+		fmt.Fprintf(stderr, "healthprobe: %v\n", err)
+		return ExitUnhealthy
+	}
+	return ExitHealthy
+}
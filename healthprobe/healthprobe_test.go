@@ -0,0 +1,60 @@
+package healthprobe
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// serveHealth serves a health service on a loopback port and returns its address
+func serveHealth(t *testing.T) (string, *health.Server) {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer()
+	healthServer := health.NewServer()
+	grpc_health_v1.RegisterHealthServer(srv, healthServer)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	return lis.Addr().String(), healthServer
+}
+
+func TestProbe(t *testing.T) {
+	addr, healthServer := serveHealth(t)
+	healthServer.SetServingStatus("orders.v1.Orders", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		wantErr  string
+	}{
+		{name: "server serving", args: []string{"-addr", addr}, wantCode: ExitHealthy},
+		{name: "service not serving", args: []string{"-addr", addr, "-service", "orders.v1.Orders"}, wantCode: ExitUnhealthy, wantErr: "service not serving: NOT_SERVING"},
+		{name: "unknown service", args: []string{"-addr", addr, "-service", "unknown"}, wantCode: ExitUnhealthy, wantErr: "NotFound"},
+		{name: "unexpected argument", args: []string{"-addr", addr, "extra"}, wantCode: ExitUsage},
+		{name: "unknown flag", args: []string{"-port", "9090"}, wantCode: ExitUsage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var stderr bytes.Buffer
+
+			// Act
+			code := Main(tt.args, &stderr)
+
+			// Assert
+			assert.Equal(t, tt.wantCode, code, stderr.String())
+			assert.Contains(t, stderr.String(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package httpcache
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// ControlOption configures the Cache-Control middleware
+type ControlOption func(*controls)
+
+// WithControlRoute sets the Cache-Control header of responses to paths starting with
+// prefix, e.g. "no-store" or "public, max-age=60". The policy applies to the given
+// methods, or to every method without any. The longest matching prefix accepting the
+// request method wins; an empty value leaves the header to the handlers.
+func WithControlRoute(prefix, value string, methods ...string) ControlOption {
+	return func(c *controls) {
+		c.routes = append(c.routes, controlRoute{prefix: prefix, value: value, methods: methods})
+	}
+}
+
+// controlRoute is the Cache-Control policy of a route
+type controlRoute struct {
+	prefix  string
+	value   string
+	methods []string
+}
+
+// controls is the configuration of the Cache-Control middleware
+type controls struct {
+	routes []controlRoute
+}
+
+// CacheControl sets the Cache-Control header of responses from policies per route
+// prefix, so handlers do not set it themselves. Handlers setting the header keep theirs,
+// and error responses (status 400 and above) get none.
+func CacheControl(opts ...ControlOption) func(http.Handler) http.Handler {
+	c := &controls{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	// Longest prefixes first, so the most specific route matches
+	slices.SortStableFunc(c.routes, func(a, b controlRoute) int {
+		return len(b.prefix) - len(a.prefix)
+	})
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			value := c.value(r)
+			if value == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			next.ServeHTTP(&controlWriter{ResponseWriter: w, value: value}, r)
+		})
+	}
+}
+
+// value returns the Cache-Control header of the response to the request, if any
+func (c *controls) value(r *http.Request) string {
+	for _, route := range c.routes {
+		if !strings.HasPrefix(r.URL.Path, route.prefix) {
+			continue
+		}
+		if len(route.methods) == 0 || slices.Contains(route.methods, r.Method) {
+			return route.value
+		}
+	}
+	return ""
+}
+
+// controlWriter sets the Cache-Control header before the response header is written
+type controlWriter struct {
+	http.ResponseWriter
+	value       string
+	wroteHeader bool
+}
+
+func (w *controlWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		header := w.Header()
+		if status < http.StatusBadRequest && header.Get("Cache-Control") == "" {
+			header.Set("Cache-Control", w.value)
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *controlWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush writes the header of streams before passing the flush through
+func (w *controlWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
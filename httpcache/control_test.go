@@ -0,0 +1,78 @@
+package httpcache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheControl(t *testing.T) {
+	opts := []ControlOption{
+		WithControlRoute("/v1", "no-store"),
+		WithControlRoute("/v1/products", "public, max-age=60", http.MethodGet, http.MethodHead),
+		WithControlRoute("/v1/products/stock", ""),
+	}
+
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		handlerHeader string
+		status        int
+		want          string
+	}{
+		{name: "authenticated route", path: "/v1/account", want: "no-store"},
+		{name: "public get", path: "/v1/products/1", want: "public, max-age=60"},
+		{name: "public post falls back to shorter prefix", method: http.MethodPost, path: "/v1/products", want: "no-store"},
+		{name: "empty value left to handlers", path: "/v1/products/stock"},
+		{name: "no route", path: "/healthz"},
+		{name: "handler header kept", path: "/v1/products/1", handlerHeader: "private", want: "private"},
+		{name: "error response", path: "/v1/products/1", status: http.StatusNotFound},
+		{name: "not modified", path: "/v1/products/1", status: http.StatusNotModified, want: "public, max-age=60"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handler := CacheControl(opts...)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				if tt.handlerHeader != "" {
+					w.Header().Set("Cache-Control", tt.handlerHeader)
+				}
+				if tt.status != 0 {
+					w.WriteHeader(tt.status)
+				}
+				_, _ = w.Write([]byte(`{}`))
+			}))
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			rec := httptest.NewRecorder()
+
+			// Act
+			handler.ServeHTTP(rec, httptest.NewRequest(method, tt.path, nil))
+
+			// Assert
+			assert.Equal(t, tt.want, rec.Header().Get("Cache-Control"))
+		})
+	}
+}
+
+func TestCacheControl_Stream(t *testing.T) {
+	// Arrange
+	handler := CacheControl(WithControlRoute("/", "no-cache"))(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.(http.Flusher).Flush()
+		_, _ = w.Write([]byte("first\n"))
+	}))
+	rec := httptest.NewRecorder()
+
+	// Act
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/stream", nil))
+
+	// Assert
+	assert.True(t, rec.Flushed)
+	assert.Equal(t, "no-cache", rec.Header().Get("Cache-Control"))
+	assert.Equal(t, "first\n", rec.Body.String())
+}
@@ -0,0 +1,167 @@
+package httpcache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ETagOption configures the ETag middleware
+type ETagOption func(*etags)
+
+// WithETagRoute adds ETags to responses of paths starting with prefix, weak ones when
+// weak is set. The longest matching prefix wins. Without routes every path gets strong
+// ETags.
+func WithETagRoute(prefix string, weak bool) ETagOption {
+	return func(e *etags) {
+		e.routes = append(e.routes, etagRoute{prefix: prefix, weak: weak})
+	}
+}
+
+// etagRoute selects the ETag kind of a route
+type etagRoute struct {
+	prefix string
+	weak   bool
+}
+
+// etags is the configuration of the ETag middleware
+type etags struct {
+	routes []etagRoute
+}
+
+// ETag adds an ETag computed from the body to successful GET and HEAD responses that
+// have none and answers conditional requests with 304 Not Modified: If-None-Match
+// against the ETag, or If-Modified-Since against the Last-Modified header the handler
+// set. Responses are buffered, except for streams, which are passed through once the
+// handler flushes.
+func ETag(opts ...ETagOption) func(http.Handler) http.Handler {
+	e := &etags{}
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			weak, ok := e.route(r)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			buf := &bufferedWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(buf, r)
+			if buf.streaming {
+				return
+			}
+
+			header := w.Header()
+			if buf.status == http.StatusOK && header.Get("ETag") == "" {
+				header.Set("ETag", computeETag(buf.body.Bytes(), weak))
+			}
+			if buf.status == http.StatusOK && notModified(r, header) {
+				for _, name := range []string{"Content-Type", "Content-Length"} {
+					header.Del(name)
+				}
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.WriteHeader(buf.status)
+			_, _ = w.Write(buf.body.Bytes())
+		})
+	}
+}
+
+// route returns the ETag kind of the request, reporting whether it gets one
+func (e *etags) route(r *http.Request) (weak bool, ok bool) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false, false
+	}
+	if len(e.routes) == 0 {
+		return false, true
+	}
+	match := -1
+	for i, route := range e.routes {
+		if strings.HasPrefix(r.URL.Path, route.prefix) && (match < 0 || len(route.prefix) > len(e.routes[match].prefix)) {
+			match = i
+		}
+	}
+	if match < 0 {
+		return false, false
+	}
+	return e.routes[match].weak, true
+}
+
+// computeETag returns the quoted ETag of body
+func computeETag(body []byte, weak bool) string {
+	sum := sha256.Sum256(body)
+	tag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	if weak {
+		return "W/" + tag
+	}
+	return tag
+}
+
+// notModified reports whether the conditional headers of the request match the response.
+// If-None-Match takes precedence over If-Modified-Since, as in RFC 9110.
+func notModified(r *http.Request, header http.Header) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		etag := strings.TrimPrefix(header.Get("ETag"), "W/")
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	ims, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(header.Get("Last-Modified"))
+	if err != nil {
+		return false
+	}
+	return !modified.Truncate(time.Second).After(ims)
+}
+
+// bufferedWriter holds the response back until the handler returns, switching to
+// passing it through when the handler flushes
+type bufferedWriter struct {
+	http.ResponseWriter
+	status    int
+	body      bytes.Buffer
+	streaming bool
+}
+
+func (w *bufferedWriter) WriteHeader(status int) {
+	if w.streaming {
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+	w.status = status
+}
+
+func (w *bufferedWriter) Write(b []byte) (int, error) {
+	if w.streaming {
+		return w.ResponseWriter.Write(b)
+	}
+	return w.body.Write(b)
+}
+
+// Flush writes the buffered response and passes the rest of the stream through
+func (w *bufferedWriter) Flush() {
+	if !w.streaming {
+		w.streaming = true
+		w.ResponseWriter.WriteHeader(w.status)
+		_, _ = w.ResponseWriter.Write(w.body.Bytes())
+		w.body.Reset()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,162 @@
+package httpcache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestETag(t *testing.T) {
+	lastModified := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	body := `{"id":"1"}`
+	strong := computeETag([]byte(body), false)
+
+	tests := []struct {
+		name       string
+		opts       []ETagOption
+		method     string
+		path       string
+		header     map[string]string
+		wantStatus int
+		wantETag   string
+		wantBody   string
+	}{
+		{
+			name:       "strong etag",
+			path:       "/v1/items/1",
+			wantStatus: http.StatusOK,
+			wantETag:   strong,
+			wantBody:   body,
+		},
+		{
+			name:       "weak etag for route",
+			opts:       []ETagOption{WithETagRoute("/v1", false), WithETagRoute("/v1/items", true)},
+			path:       "/v1/items/1",
+			wantStatus: http.StatusOK,
+			wantETag:   "W/" + strong,
+			wantBody:   body,
+		},
+		{
+			name:       "route without etags",
+			opts:       []ETagOption{WithETagRoute("/v2", false)},
+			path:       "/v1/items/1",
+			wantStatus: http.StatusOK,
+			wantBody:   body,
+		},
+		{
+			name:       "if-none-match",
+			path:       "/v1/items/1",
+			header:     map[string]string{"If-None-Match": `"other", ` + strong},
+			wantStatus: http.StatusNotModified,
+			wantETag:   strong,
+		},
+		{
+			name:       "if-none-match weak comparison",
+			opts:       []ETagOption{WithETagRoute("/", true)},
+			path:       "/v1/items/1",
+			header:     map[string]string{"If-None-Match": strong},
+			wantStatus: http.StatusNotModified,
+			wantETag:   "W/" + strong,
+		},
+		{
+			name:       "if-none-match changed",
+			path:       "/v1/items/1",
+			header:     map[string]string{"If-None-Match": `"stale"`},
+			wantStatus: http.StatusOK,
+			wantETag:   strong,
+			wantBody:   body,
+		},
+		{
+			name:       "if-modified-since",
+			path:       "/v1/items/1",
+			header:     map[string]string{"If-Modified-Since": lastModified.Format(http.TimeFormat)},
+			wantStatus: http.StatusNotModified,
+			wantETag:   strong,
+		},
+		{
+			name:       "modified since",
+			path:       "/v1/items/1",
+			header:     map[string]string{"If-Modified-Since": lastModified.Add(-time.Hour).Format(http.TimeFormat)},
+			wantStatus: http.StatusOK,
+			wantETag:   strong,
+			wantBody:   body,
+		},
+		{
+			name:       "post passed through",
+			method:     http.MethodPost,
+			path:       "/v1/items/1",
+			header:     map[string]string{"If-None-Match": strong},
+			wantStatus: http.StatusOK,
+			wantBody:   body,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handler := ETag(tt.opts...)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+				_, _ = w.Write([]byte(body))
+			}))
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			r := httptest.NewRequest(method, tt.path, nil)
+			for name, value := range tt.header {
+				r.Header.Set(name, value)
+			}
+			rec := httptest.NewRecorder()
+
+			// Act
+			handler.ServeHTTP(rec, r)
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, tt.wantETag, rec.Header().Get("ETag"))
+			assert.Equal(t, tt.wantBody, rec.Body.String())
+		})
+	}
+}
+
+func TestETag_Stream(t *testing.T) {
+	// Arrange
+	handler := ETag()(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("first\n"))
+		w.(http.Flusher).Flush()
+		_, _ = w.Write([]byte("second\n"))
+	}))
+	rec := httptest.NewRecorder()
+
+	// Act
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/stream", nil))
+
+	// Assert
+	require.True(t, rec.Flushed)
+	assert.Empty(t, rec.Header().Get("ETag"))
+	assert.Equal(t, "first\nsecond\n", rec.Body.String())
+}
+
+func TestETag_KeepsHandlerETag(t *testing.T) {
+	// Arrange
+	handler := ETag()(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("ETag", `"v7"`)
+		_, _ = w.Write([]byte(strings.Repeat("x", 10)))
+	}))
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("If-None-Match", `"v7"`)
+	rec := httptest.NewRecorder()
+
+	// Act
+	handler.ServeHTTP(rec, r)
+
+	// Assert
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Equal(t, `"v7"`, rec.Header().Get("ETag"))
+}
@@ -0,0 +1,253 @@
+// Package httpcache caches responses of idempotent GET routes served by the gateway.
+// Responses are stored in a Store, in memory or in Redis, under a key built from the
+// request path, query, selected headers and the authenticated subject. The ETag
+// middleware lets clients revalidate responses with conditional requests.
+package httpcache
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/legrch/netgex/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Store holds cached responses
+type Store interface {
+	// Get returns the value of key, reporting whether it was found
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores value under key for ttl
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// CacheHeader reports whether a response was served from the cache: HIT or MISS
+const CacheHeader = "X-Cache"
+
+// Route caches the GET responses of paths starting with Prefix for TTL
+type Route struct {
+	Prefix string
+	TTL    time.Duration
+}
+
+// SubjectFunc returns the authenticated subject of a request, e.g. the user ID of its
+// token, or an empty string for anonymous requests
+type SubjectFunc func(r *http.Request) string
+
+// Option configures the cache middleware
+type Option func(*cache)
+
+// WithRoute caches GET responses of paths starting with prefix for ttl. The longest
+// matching prefix wins, so a route can override the TTL of a broader one; a TTL of 0
+// disables caching below the prefix.
+func WithRoute(prefix string, ttl time.Duration) Option {
+	return func(c *cache) {
+		c.routes = append(c.routes, Route{Prefix: prefix, TTL: ttl})
+	}
+}
+
+// WithVaryHeaders adds the values of the headers to the cache key, e.g. Accept-Language
+func WithVaryHeaders(headers ...string) Option {
+	return func(c *cache) {
+		for _, h := range headers {
+			c.varyHeaders = append(c.varyHeaders, http.CanonicalHeaderKey(h))
+		}
+	}
+}
+
+// WithSubject adds the authenticated subject to the cache key, so users never see each
+// other's responses. Without it requests carrying an Authorization header or cookies
+// are not cached.
+func WithSubject(subject SubjectFunc) Option {
+	return func(c *cache) {
+		c.subject = subject
+	}
+}
+
+// WithKeyPrefix sets the prefix of the store keys, "netgex:httpcache:" by default, to
+// share a Redis database between services
+func WithKeyPrefix(prefix string) Option {
+	return func(c *cache) {
+		c.keyPrefix = prefix
+	}
+}
+
+// WithMetrics counts hits and misses in the http_cache_requests_total metric of the
+// namespace
+func WithMetrics(namespace string) Option {
+	return func(c *cache) {
+		c.requests = requestsCounter(namespace)
+	}
+}
+
+// cache is the configuration of the cache middleware
+type cache struct {
+	store       Store
+	routes      []Route
+	varyHeaders []string
+	subject     SubjectFunc
+	keyPrefix   string
+	requests    *prometheus.CounterVec
+}
+
+// entry is a cached response
+type entry struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// Middleware caches successful GET responses of the configured routes in store. Cached
+// responses carry X-Cache: HIT; store errors are treated as misses.
+func Middleware(store Store, opts ...Option) func(http.Handler) http.Handler {
+	c := &cache{store: store, keyPrefix: "netgex:httpcache:"}
+	for _, opt := range opts {
+		opt(c)
+	}
+	// Longest prefixes first, so the most specific route matches
+	slices.SortStableFunc(c.routes, func(a, b Route) int {
+		return len(b.Prefix) - len(a.Prefix)
+	})
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ttl, ok := c.ttl(r)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key := c.key(r)
+			if value, found, err := c.store.Get(r.Context(), key); err == nil && found {
+				var e entry
+				if json.Unmarshal(value, &e) == nil {
+					c.count("hit")
+					e.write(w)
+					return
+				}
+			}
+			c.count("miss")
+
+			rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+			w.Header().Set(CacheHeader, "MISS")
+			next.ServeHTTP(rec, r)
+
+			if rec.cacheable() {
+				header := w.Header().Clone()
+				header.Del(CacheHeader)
+				value, err := json.Marshal(entry{Status: rec.status, Header: header, Body: rec.body.Bytes()})
+				if err == nil {
+					_ = c.store.Set(context.WithoutCancel(r.Context()), key, value, ttl)
+				}
+			}
+		})
+	}
+}
+
+// ttl returns the TTL of the request, reporting whether it may be cached
+func (c *cache) ttl(r *http.Request) (time.Duration, bool) {
+	if r.Method != http.MethodGet {
+		return 0, false
+	}
+	if strings.Contains(r.Header.Get("Cache-Control"), "no-cache") {
+		return 0, false
+	}
+	if c.subject == nil && (r.Header.Get("Authorization") != "" || r.Header.Get("Cookie") != "") {
+		return 0, false
+	}
+	for _, route := range c.routes {
+		if strings.HasPrefix(r.URL.Path, route.Prefix) {
+			return route.TTL, route.TTL > 0
+		}
+	}
+	return 0, false
+}
+
+// key returns the store key of the request
+func (c *cache) key(r *http.Request) string {
+	h := sha256.New()
+	h.Write([]byte(r.Host + "\n" + r.URL.Path + "\n" + r.URL.RawQuery + "\n"))
+	for _, name := range c.varyHeaders {
+		h.Write([]byte(name + ":" + strings.Join(r.Header.Values(name), ",") + "\n"))
+	}
+	if c.subject != nil {
+		h.Write([]byte("subject:" + c.subject(r) + "\n"))
+	}
+	return c.keyPrefix + hex.EncodeToString(h.Sum(nil))
+}
+
+// count records a hit or a miss
+func (c *cache) count(result string) {
+	if c.requests != nil {
+		c.requests.WithLabelValues(result).Inc()
+	}
+}
+
+// write serves the cached response
+func (e *entry) write(w http.ResponseWriter) {
+	for name, values := range e.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set(CacheHeader, "HIT")
+	w.WriteHeader(e.Status)
+	_, _ = w.Write(e.Body)
+}
+
+// recorder copies the response written through it
+type recorder struct {
+	http.ResponseWriter
+	status  int
+	body    bytes.Buffer
+	flushed bool
+}
+
+func (r *recorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// Flush passes flushes through; flushed responses are streams and never cached
+func (r *recorder) Flush() {
+	r.flushed = true
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// cacheable reports whether the response may be stored
+func (r *recorder) cacheable() bool {
+	if r.status != http.StatusOK || r.flushed {
+		return false
+	}
+	header := r.Header()
+	if header.Get("Set-Cookie") != "" {
+		return false
+	}
+	control := header.Get("Cache-Control")
+	return !strings.Contains(control, "no-store") && !strings.Contains(control, "private")
+}
+
+// requestsCounter returns the hit and miss counter of the namespace, registering it
+// unless already registered
+func requestsCounter(namespace string) *prometheus.CounterVec {
+	counter := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_cache_requests_total",
+			Help:      "Total number of cacheable HTTP requests by result: hit or miss",
+		},
+		[]string{"result"},
+	)
+	return metrics.Register(counter)
+}
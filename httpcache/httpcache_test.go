@@ -0,0 +1,187 @@
+package httpcache
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// counter serves the number of requests it handled
+func counter(calls *int, header http.Header) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		*calls++
+		for name, values := range header {
+			w.Header()[name] = values
+		}
+		_, _ = fmt.Fprintf(w, "response %d", *calls)
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		header   http.Header
+		requests []*http.Request
+		want     []string
+	}{
+		{
+			name: "cached until expiry",
+			requests: []*http.Request{
+				httptest.NewRequest(http.MethodGet, "/v1/items?page=1", nil),
+				httptest.NewRequest(http.MethodGet, "/v1/items?page=1", nil),
+				httptest.NewRequest(http.MethodGet, "/v1/items?page=2", nil),
+			},
+			want: []string{"response 1 MISS", "response 1 HIT", "response 2 MISS"},
+		},
+		{
+			name: "route not cached",
+			requests: []*http.Request{
+				httptest.NewRequest(http.MethodGet, "/v1/orders", nil),
+				httptest.NewRequest(http.MethodGet, "/v1/orders", nil),
+			},
+			want: []string{"response 1 ", "response 2 "},
+		},
+		{
+			name: "more specific route disables caching",
+			opts: []Option{WithRoute("/v1/items/live", 0)},
+			requests: []*http.Request{
+				httptest.NewRequest(http.MethodGet, "/v1/items/live", nil),
+				httptest.NewRequest(http.MethodGet, "/v1/items/live", nil),
+			},
+			want: []string{"response 1 ", "response 2 "},
+		},
+		{
+			name: "post not cached",
+			requests: []*http.Request{
+				httptest.NewRequest(http.MethodPost, "/v1/items", nil),
+				httptest.NewRequest(http.MethodPost, "/v1/items", nil),
+			},
+			want: []string{"response 1 ", "response 2 "},
+		},
+		{
+			name:   "no-store response not cached",
+			header: http.Header{"Cache-Control": {"no-store"}},
+			requests: []*http.Request{
+				httptest.NewRequest(http.MethodGet, "/v1/items", nil),
+				httptest.NewRequest(http.MethodGet, "/v1/items", nil),
+			},
+			want: []string{"response 1 MISS", "response 2 MISS"},
+		},
+		{
+			name: "authorized request not cached without subject",
+			requests: []*http.Request{
+				withHeader(httptest.NewRequest(http.MethodGet, "/v1/items", nil), "Authorization", "Bearer a"),
+				withHeader(httptest.NewRequest(http.MethodGet, "/v1/items", nil), "Authorization", "Bearer a"),
+			},
+			want: []string{"response 1 ", "response 2 "},
+		},
+		{
+			name: "keyed by subject",
+			opts: []Option{WithSubject(func(r *http.Request) string { return r.Header.Get("Authorization") })},
+			requests: []*http.Request{
+				withHeader(httptest.NewRequest(http.MethodGet, "/v1/items", nil), "Authorization", "Bearer a"),
+				withHeader(httptest.NewRequest(http.MethodGet, "/v1/items", nil), "Authorization", "Bearer b"),
+				withHeader(httptest.NewRequest(http.MethodGet, "/v1/items", nil), "Authorization", "Bearer a"),
+			},
+			want: []string{"response 1 MISS", "response 2 MISS", "response 1 HIT"},
+		},
+		{
+			name: "keyed by vary headers",
+			opts: []Option{WithVaryHeaders("accept-language")},
+			requests: []*http.Request{
+				withHeader(httptest.NewRequest(http.MethodGet, "/v1/items", nil), "Accept-Language", "en"),
+				withHeader(httptest.NewRequest(http.MethodGet, "/v1/items", nil), "Accept-Language", "de"),
+				withHeader(httptest.NewRequest(http.MethodGet, "/v1/items", nil), "Accept-Language", "en"),
+			},
+			want: []string{"response 1 MISS", "response 2 MISS", "response 1 HIT"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			calls := 0
+			opts := append([]Option{WithRoute("/v1/items", time.Minute)}, tt.opts...)
+			handler := Middleware(NewMemoryStore(0), opts...)(counter(&calls, tt.header))
+
+			// Act
+			got := make([]string, 0, len(tt.requests))
+			for _, r := range tt.requests {
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, r)
+				got = append(got, rec.Body.String()+" "+rec.Header().Get(CacheHeader))
+			}
+
+			// Assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMiddleware_Metrics(t *testing.T) {
+	// Arrange
+	calls := 0
+	handler := Middleware(NewMemoryStore(0), WithRoute("/", time.Minute), WithMetrics("httpcachetest"))(counter(&calls, nil))
+
+	// Act
+	for range 3 {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/items", nil))
+	}
+
+	// Assert
+	requests := requestsCounter("httpcachetest")
+	assert.Equal(t, 1.0, testutil.ToFloat64(requests.WithLabelValues("miss")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(requests.WithLabelValues("hit")))
+}
+
+func TestMemoryStore(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	now := time.Now()
+	store := NewMemoryStore(2)
+	store.now = func() time.Time { return now }
+
+	// Act
+	require.NoError(t, store.Set(ctx, "a", []byte("1"), time.Second))
+	require.NoError(t, store.Set(ctx, "b", []byte("2"), time.Minute))
+	_, _, _ = store.Get(ctx, "a")
+	require.NoError(t, store.Set(ctx, "c", []byte("3"), time.Minute))
+	_, evicted, _ := store.Get(ctx, "b")
+	now = now.Add(time.Second)
+	_, expired, _ := store.Get(ctx, "a")
+	value, found, err := store.Get(ctx, "c")
+
+	// Assert
+	require.NoError(t, err)
+	assert.False(t, evicted)
+	assert.False(t, expired)
+	assert.True(t, found)
+	assert.Equal(t, []byte("3"), value)
+	assert.Equal(t, 1, store.Len())
+}
+
+// withHeader sets a header on the request
+func withHeader(r *http.Request, name, value string) *http.Request {
+	r.Header.Set(name, value)
+	return r
+}
+
+func TestWithMetrics_SharedBetweenMiddlewares(t *testing.T) {
+	// Act
+	var first, second cache
+	assert.NotPanics(t, func() {
+		WithMetrics("httpcacheshared")(&first)
+		WithMetrics("httpcacheshared")(&second)
+	})
+
+	// Assert
+	assert.Same(t, first.requests, second.requests)
+}
@@ -0,0 +1,52 @@
+package httpcache
+
+import (
+	"context"
+	"time"
+)
+
+// RedisClient runs the commands of a RedisStore, typically a redis.Client process, so
+// the cache shares its connections and its command spans
+type RedisClient interface {
+	// Get returns the value of key, reporting whether it was found
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores value under key, expiring after ttl
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// RedisStore keeps responses in Redis
+type RedisStore struct {
+	client RedisClient
+	prefix string
+}
+
+// RedisOption configures a RedisStore
+type RedisOption func(*RedisStore)
+
+// WithRedisKeyPrefix prefixes the keys of the store, e.g. "httpcache:" to keep responses
+// apart from the other keys of a shared Redis database
+func WithRedisKeyPrefix(prefix string) RedisOption {
+	return func(s *RedisStore) {
+		s.prefix = prefix
+	}
+}
+
+// NewRedisStore creates a store running its commands on client. The store leaves the
+// client open: its owner, e.g. the redis.Client registered with server.WithRedis, closes it.
+func NewRedisStore(client RedisClient, opts ...RedisOption) *RedisStore {
+	s := &RedisStore{client: client}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Get returns the value of key
+func (s *RedisStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	return s.client.Get(ctx, s.prefix+key)
+}
+
+// Set stores value under key for ttl
+func (s *RedisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return s.client.Set(ctx, s.prefix+key, value, ttl)
+}
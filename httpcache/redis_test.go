@@ -0,0 +1,72 @@
+package httpcache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/legrch/netgex/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRedisClient keeps values in a map, recording the ttl of each key, and fails every
+// command when err is set
+type fakeRedisClient struct {
+	values map[string][]byte
+	ttls   map[string]time.Duration
+	err    error
+}
+
+func (c *fakeRedisClient) Get(_ context.Context, key string) ([]byte, bool, error) {
+	if c.err != nil {
+		return nil, false, c.err
+	}
+	value, ok := c.values[key]
+	return value, ok, nil
+}
+
+func (c *fakeRedisClient) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.values[key] = value
+	c.ttls[key] = ttl
+	return nil
+}
+
+func TestRedisStore(t *testing.T) {
+	// Arrange
+	client := &fakeRedisClient{values: map[string][]byte{}, ttls: map[string]time.Duration{}}
+	store := NewRedisStore(client, WithRedisKeyPrefix("httpcache:"))
+	ctx := context.Background()
+
+	// Act
+	_, missing, missingErr := store.Get(ctx, "key")
+	setErr := store.Set(ctx, "key", []byte("value"), 90*time.Second)
+	value, found, err := store.Get(ctx, "key")
+
+	// Assert
+	require.NoError(t, missingErr)
+	require.NoError(t, setErr)
+	require.NoError(t, err)
+	assert.False(t, missing)
+	assert.True(t, found)
+	assert.Equal(t, []byte("value"), value)
+	assert.Equal(t, map[string]time.Duration{"httpcache:key": 90 * time.Second}, client.ttls)
+}
+
+func TestRedisStore_ClientError(t *testing.T) {
+	// Arrange
+	store := NewRedisStore(&fakeRedisClient{err: errors.New("connection refused")})
+
+	// Act
+	_, found, err := store.Get(context.Background(), "key")
+
+	// Assert
+	assert.Error(t, err)
+	assert.False(t, found)
+}
+
+var _ RedisClient = (*redis.Client)(nil)
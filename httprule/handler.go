@@ -0,0 +1,151 @@
+package httprule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/utilities"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/legrch/netgex/internal/descriptors"
+)
+
+// handler serves a binding of a method, as the handlers generated by
+// protoc-gen-grpc-gateway do
+type handler struct {
+	conn    *grpc.ClientConn
+	mux     *runtime.ServeMux
+	rpc     string
+	verb    string
+	pattern string
+	body    string
+	// bodyField is the request field filled from the body, nil without one or with "*"
+	bodyField protoreflect.FieldDescriptor
+	input     protoreflect.MessageType
+	output    protoreflect.MessageType
+}
+
+// newHandler creates the handler of a binding of a method
+func newHandler(conn *grpc.ClientConn, mux *runtime.ServeMux, md protoreflect.MethodDescriptor, rule *annotations.HttpRule) (*handler, error) {
+	h := &handler{
+		conn:   conn,
+		mux:    mux,
+		rpc:    "/" + string(md.Parent().FullName()) + "/" + string(md.Name()),
+		body:   rule.GetBody(),
+		input:  descriptors.MessageType(md.Input()),
+		output: descriptors.MessageType(md.Output()),
+	}
+	switch p := rule.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		h.verb, h.pattern = http.MethodGet, p.Get
+	case *annotations.HttpRule_Put:
+		h.verb, h.pattern = http.MethodPut, p.Put
+	case *annotations.HttpRule_Post:
+		h.verb, h.pattern = http.MethodPost, p.Post
+	case *annotations.HttpRule_Delete:
+		h.verb, h.pattern = http.MethodDelete, p.Delete
+	case *annotations.HttpRule_Patch:
+		h.verb, h.pattern = http.MethodPatch, p.Patch
+	case *annotations.HttpRule_Custom:
+		h.verb, h.pattern = p.Custom.GetKind(), p.Custom.GetPath()
+	default:
+		return nil, errors.New("rule has no pattern")
+	}
+
+	if h.body != "" && h.body != "*" {
+		fd := md.Input().Fields().ByName(protoreflect.Name(h.body))
+		if fd == nil {
+			return nil, fmt.Errorf("body field %q not found in %s", h.body, md.Input().FullName())
+		}
+		if fd.Message() == nil || fd.IsList() || fd.IsMap() {
+			return nil, fmt.Errorf("body field %q is not a message", h.body)
+		}
+		h.bodyField = fd
+	}
+	return h, nil
+}
+
+// serve decodes the request message, calls the method and forwards its response
+func (h *handler) serve(w http.ResponseWriter, req *http.Request, pathParams map[string]string) {
+	ctx, cancel := context.WithCancel(req.Context())
+	defer cancel()
+	inbound, outbound := runtime.MarshalerForRequest(h.mux, req)
+
+	annotated, err := runtime.AnnotateContext(ctx, h.mux, req, h.rpc, runtime.WithHTTPPathPattern(h.pattern))
+	if err != nil {
+		runtime.HTTPError(ctx, h.mux, outbound, w, req, err)
+		return
+	}
+	in, err := h.decode(req, inbound, pathParams)
+	if err != nil {
+		runtime.HTTPError(annotated, h.mux, outbound, w, req, err)
+		return
+	}
+
+	out := newMessage(h.output)
+	var md runtime.ServerMetadata
+	err = h.conn.Invoke(annotated, h.rpc, in, out, grpc.Header(&md.HeaderMD), grpc.Trailer(&md.TrailerMD))
+	annotated = runtime.NewServerMetadataContext(annotated, md)
+	if err != nil {
+		runtime.HTTPError(annotated, h.mux, outbound, w, req, err)
+		return
+	}
+	runtime.ForwardResponseMessage(annotated, h.mux, outbound, w, req, out, h.mux.GetForwardResponseOptions()...)
+}
+
+// decode builds the request message from the body, the path parameters and, unless the
+// body fills the whole message, the query parameters
+func (h *handler) decode(req *http.Request, inbound runtime.Marshaler, pathParams map[string]string) (proto.Message, error) {
+	in := newMessage(h.input)
+	switch {
+	case h.body == "*":
+		if err := decodeBody(req, inbound, in); err != nil {
+			return nil, err
+		}
+	case h.bodyField != nil:
+		field := in.ProtoReflect().Mutable(h.bodyField).Message().Interface()
+		if err := decodeBody(req, inbound, field); err != nil {
+			return nil, err
+		}
+	}
+
+	seqs := make([][]string, 0, len(pathParams)+1)
+	for name, value := range pathParams {
+		if err := runtime.PopulateFieldFromPath(in, name, value); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "type mismatch, parameter: %s, error: %v", name, err)
+		}
+		seqs = append(seqs, strings.Split(name, "."))
+	}
+	if h.body == "*" {
+		return in, nil
+	}
+	if h.body != "" {
+		seqs = append(seqs, []string{h.body})
+	}
+
+	if err := req.ParseForm(); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	if err := runtime.PopulateQueryParameters(in, req.Form, utilities.NewDoubleArray(seqs)); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	return in, nil
+}
+
+// decodeBody decodes the request body into msg, an empty body leaving it unset
+func decodeBody(req *http.Request, inbound runtime.Marshaler, msg proto.Message) error {
+	if err := inbound.NewDecoder(req.Body).Decode(msg); err != nil && !errors.Is(err, io.EOF) {
+		return status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+// Package httprule serves gRPC methods on the gateway from HTTP rules declared at
+// startup, for protos without google.api.http annotations such as third-party or
+// legacy APIs. Rules are google.api.HttpRule values, as in the http section of a gRPC
+// service config: the selector names the method, the pattern its verb and path
+// template, and body the request field filled from the request body.
+//
+//	server.WithServices(
+//		orders.New(),
+//		httprule.New(
+//			httprule.Get("orders.v1.Orders.GetOrder", "/v1/orders/{id}"),
+//			httprule.Post("orders.v1.Orders.CreateOrder", "/v1/orders", "order"),
+//		),
+//	)
+//
+// The gateway handlers are built from the method descriptors in the global registry
+// and call the method on the gateway's gRPC endpoint, like generated handlers do.
+package httprule
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"github.com/legrch/netgex/internal/descriptors"
+)
+
+// Get returns the rule binding GET requests on path to a method, named by its full
+// name such as pkg.Service.Method. Fields not bound by the path are read from the query.
+func Get(selector, path string) *annotations.HttpRule {
+	return &annotations.HttpRule{Selector: selector, Pattern: &annotations.HttpRule_Get{Get: path}}
+}
+
+// Delete returns the rule binding DELETE requests on path to a method
+func Delete(selector, path string) *annotations.HttpRule {
+	return &annotations.HttpRule{Selector: selector, Pattern: &annotations.HttpRule_Delete{Delete: path}}
+}
+
+// Post returns the rule binding POST requests on path to a method. The body fills the
+// named request field, or the whole request with "*".
+func Post(selector, path, body string) *annotations.HttpRule {
+	return &annotations.HttpRule{Selector: selector, Pattern: &annotations.HttpRule_Post{Post: path}, Body: body}
+}
+
+// Put returns the rule binding PUT requests on path to a method, with the body as in Post
+func Put(selector, path, body string) *annotations.HttpRule {
+	return &annotations.HttpRule{Selector: selector, Pattern: &annotations.HttpRule_Put{Put: path}, Body: body}
+}
+
+// Patch returns the rule binding PATCH requests on path to a method, with the body as
+// in Post
+func Patch(selector, path, body string) *annotations.HttpRule {
+	return &annotations.HttpRule{Selector: selector, Pattern: &annotations.HttpRule_Patch{Patch: path}, Body: body}
+}
+
+// Registrar registers gateway handlers for HTTP rules
+type Registrar struct {
+	descriptors.GatewayOnly
+	rules []*annotations.HttpRule
+}
+
+// New creates a registrar serving the rules, including their additional bindings
+func New(rules ...*annotations.HttpRule) *Registrar {
+	return &Registrar{rules: rules}
+}
+
+// HTTPRules returns the rules of the registrar, listed with the routes of the gateway
+func (r *Registrar) HTTPRules() []*annotations.HttpRule {
+	return r.rules
+}
+
+// RegisterHTTP registers a handler per binding, calling the methods on endpoint. Rules
+// naming unknown or streaming methods, or body fields that are not messages, fail.
+func (r *Registrar) RegisterHTTP(ctx context.Context, mux *runtime.ServeMux, endpoint string, opts []grpc.DialOption) (err error) {
+	conn, err := grpc.NewClient(endpoint, opts...)
+	if err != nil {
+		return fmt.Errorf("httprule: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = conn.Close()
+			return
+		}
+		go func() {
+			<-ctx.Done()
+			_ = conn.Close()
+		}()
+	}()
+
+	for _, rule := range r.rules {
+		method, err := findMethod(rule.GetSelector())
+		if err != nil {
+			return err
+		}
+		bindings := append([]*annotations.HttpRule{rule}, rule.GetAdditionalBindings()...)
+		for _, b := range bindings {
+			h, err := newHandler(conn, mux, method, b)
+			if err != nil {
+				return fmt.Errorf("httprule: %s: %w", rule.GetSelector(), err)
+			}
+			if err := mux.HandlePath(h.verb, h.pattern, h.serve); err != nil {
+				return fmt.Errorf("httprule: %s %s: %w", h.verb, h.pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// findMethod returns the descriptor of a unary method named pkg.Service.Method, or
+// /pkg.Service/Method as gRPC names it
+func findMethod(selector string) (protoreflect.MethodDescriptor, error) {
+	name := strings.ReplaceAll(strings.TrimPrefix(selector, "/"), "/", ".")
+	desc, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		return nil, fmt.Errorf("httprule: method %q: %w", selector, err)
+	}
+	md, ok := desc.(protoreflect.MethodDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("httprule: %q is not a method", selector)
+	}
+	if md.IsStreamingClient() || md.IsStreamingServer() {
+		return nil, fmt.Errorf("httprule: method %q is streaming, only unary methods are supported", selector)
+	}
+	return md, nil
+}
+
+// newMessage returns an empty message of the type
+func newMessage(mt protoreflect.MessageType) proto.Message {
+	return mt.New().Interface()
+}
@@ -0,0 +1,108 @@
+package httprule
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const checkMethod = "grpc.health.v1.Health.Check"
+
+// serveHealth serves the health service on an in-memory listener and returns the dial
+// options reaching it
+func serveHealth(t *testing.T) []grpc.DialOption {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	hs := health.NewServer()
+	hs.SetServingStatus("orders", grpc_health_v1.HealthCheckResponse_SERVING)
+	srv := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(srv, hs)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	return []grpc.DialOption{
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	}
+}
+
+func TestRegistrar_RegisterHTTP(t *testing.T) {
+	// Arrange
+	opts := serveHealth(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mux := runtime.NewServeMux()
+	rule := Get(checkMethod, "/v1/health/{service}")
+	rule.AdditionalBindings = []*annotations.HttpRule{Post("", "/v1/health:check", "*")}
+	registrar := New(rule, Get("/grpc.health.v1.Health/Check", "/v1/health"))
+	require.NoError(t, registrar.RegisterHTTP(ctx, mux, "passthrough:///bufconn", opts))
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "path parameter", method: http.MethodGet, target: "/v1/health/orders", wantStatus: http.StatusOK, wantBody: `"SERVING"`},
+		{name: "query parameter", method: http.MethodGet, target: "/v1/health?service=orders", wantStatus: http.StatusOK, wantBody: `"SERVING"`},
+		{name: "body", method: http.MethodPost, target: "/v1/health:check", body: `{"service":"orders"}`, wantStatus: http.StatusOK, wantBody: `"SERVING"`},
+		{name: "invalid body", method: http.MethodPost, target: "/v1/health:check", body: `{`, wantStatus: http.StatusBadRequest},
+		{name: "error status", method: http.MethodGet, target: "/v1/health/unknown", wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+
+			// Act
+			mux.ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+			assert.Contains(t, rec.Body.String(), tt.wantBody)
+		})
+	}
+}
+
+func TestRegistrar_RegisterHTTP_InvalidRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    *annotations.HttpRule
+		wantErr string
+	}{
+		{name: "unknown method", rule: Get("grpc.health.v1.Health.Unknown", "/v1/unknown"), wantErr: "Unknown"},
+		{name: "not a method", rule: Get("grpc.health.v1.Health", "/v1/health"), wantErr: "not a method"},
+		{name: "streaming method", rule: Get("grpc.health.v1.Health.Watch", "/v1/watch"), wantErr: "streaming"},
+		{name: "scalar body field", rule: Post(checkMethod, "/v1/health", "service"), wantErr: "not a message"},
+		{name: "unknown body field", rule: Post(checkMethod, "/v1/health", "missing"), wantErr: "not found"},
+		{name: "no pattern", rule: &annotations.HttpRule{Selector: checkMethod}, wantErr: "no pattern"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			opts := serveHealth(t)
+
+			// Act
+			err := New(tt.rule).RegisterHTTP(context.Background(), runtime.NewServeMux(), "passthrough:///bufconn", opts)
+
+			// Assert
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package admin
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	channelz "google.golang.org/grpc/channelz/service"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/legrch/netgex/config"
+)
+
+// ServiceLister reports the services registered on a gRPC server
+type ServiceLister interface {
+	GetServiceInfo() map[string]grpc.ServiceInfo
+}
+
+// Service is the admin API, reporting service information and the effective
+// configuration. It also registers channelz on the admin server.
+type Service struct {
+	cfg      *config.Config
+	services ServiceLister
+	version  *Version
+}
+
+// NewService creates the admin API for the configuration, the public gRPC server and the
+// version of the service
+func NewService(cfg *config.Config, services ServiceLister, version *Version) *Service {
+	return &Service{
+		cfg:      cfg,
+		services: services,
+		version:  version,
+	}
+}
+
+// RegisterGRPC registers the admin API and channelz with the gRPC server
+func (s *Service) RegisterGRPC(srv *grpc.Server) {
+	srv.RegisterService(&serviceDesc, s)
+	channelz.RegisterChannelzServiceToServer(srv)
+}
+
+// RegisterHTTP is a no-op; the admin API is only served over gRPC
+func (s *Service) RegisterHTTP(context.Context, *runtime.ServeMux, string, []grpc.DialOption) error {
+	return nil
+}
+
+// GetServiceInfo returns the service identity, build metadata, uptime and the public gRPC
+// services
+func (s *Service) GetServiceInfo(_ context.Context, _ *emptypb.Empty) (*structpb.Struct, error) {
+	names := slices.Sorted(maps.Keys(s.services.GetServiceInfo()))
+	services := make([]any, 0, len(names))
+	for _, name := range names {
+		services = append(services, name)
+	}
+
+	info := s.version.Info()
+	return structpb.NewStruct(map[string]any{
+		"name":        info.Name,
+		"version":     info.Version,
+		"commit":      info.Commit,
+		"build_date":  info.BuildDate,
+		"environment": s.cfg.Environment,
+		"go_version":  info.GoVersion,
+		"start_time":  info.StartTime.Format(time.RFC3339),
+		"uptime":      info.Uptime,
+		"services":    services,
+	})
+}
+
+// GetConfig returns the effective configuration keyed by environment variable name,
+// with secrets redacted
+func (s *Service) GetConfig(_ context.Context, _ *emptypb.Empty) (*structpb.Struct, error) {
+	values := map[string]any{}
+	for name, value := range s.cfg.Values() {
+		values[name] = value
+	}
+	return structpb.NewStruct(values)
+}
+
+// ListServices returns the public gRPC services with their methods
+func (s *Service) ListServices(_ context.Context, _ *emptypb.Empty) (*structpb.Struct, error) {
+	return structpb.NewStruct(map[string]any{
+		"services": servicesValue(ListServices(s.services)),
+	})
+}
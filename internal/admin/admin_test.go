@@ -0,0 +1,132 @@
+package admin
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/legrch/netgex/config"
+)
+
+// staticLister reports a fixed set of services
+type staticLister map[string]grpc.ServiceInfo
+
+func (l staticLister) GetServiceInfo() map[string]grpc.ServiceInfo {
+	return l
+}
+
+// dial serves the admin service on an in-memory listener and returns a client connection
+func dial(t *testing.T, svc *Service) *grpc.ClientConn {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	svc.RegisterGRPC(srv)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func TestService_GetServiceInfo(t *testing.T) {
+	// Arrange
+	cfg := config.NewConfig()
+	cfg.ServiceName = "orders"
+	cfg.ServiceVersion = "1.2.3"
+	version := NewVersion(cfg.ServiceName, cfg.ServiceVersion, BuildInfo{Commit: "abc123", BuildDate: "2024-03-05T14:07:09Z"})
+	conn := dial(t, NewService(cfg, staticLister{"orders.v1.Orders": {}, "grpc.health.v1.Health": {}}, version))
+
+	// Act
+	out := &structpb.Struct{}
+	err := conn.Invoke(context.Background(), GetServiceInfoMethod, &emptypb.Empty{}, out)
+
+	// Assert
+	require.NoError(t, err)
+	info := out.AsMap()
+	assert.Equal(t, "orders", info["name"])
+	assert.Equal(t, "1.2.3", info["version"])
+	assert.Equal(t, "abc123", info["commit"])
+	assert.Equal(t, "2024-03-05T14:07:09Z", info["build_date"])
+	assert.Equal(t, []any{"grpc.health.v1.Health", "orders.v1.Orders"}, info["services"])
+	assert.NotEmpty(t, info["start_time"])
+}
+
+func TestService_GetConfig(t *testing.T) {
+	// Arrange
+	cfg := config.NewConfig()
+	cfg.Registry.Token = "s3cret"
+	conn := dial(t, NewService(cfg, staticLister{}, NewVersion("", "", BuildInfo{})))
+
+	// Act
+	out := &structpb.Struct{}
+	err := conn.Invoke(context.Background(), GetConfigMethod, &emptypb.Empty{}, out)
+
+	// Assert
+	require.NoError(t, err)
+	values := out.AsMap()
+	assert.Equal(t, ":9090", values["GRPC_ADDRESS"])
+	assert.Equal(t, config.Redacted, values["REGISTRY_TOKEN"])
+}
+
+func TestService_ListServices(t *testing.T) {
+	// Arrange
+	conn := dial(t, NewService(config.NewConfig(), staticLister{
+		"orders.v1.Orders": {Methods: []grpc.MethodInfo{
+			{Name: "Watch", IsServerStream: true},
+			{Name: "Get"},
+		}},
+	}, NewVersion("", "", BuildInfo{})))
+
+	// Act
+	out := &structpb.Struct{}
+	err := conn.Invoke(context.Background(), ListServicesMethod, &emptypb.Empty{}, out)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []any{map[string]any{
+		"name": "orders.v1.Orders",
+		"methods": []any{
+			map[string]any{"name": "Get", "client_streaming": false, "server_streaming": false},
+			map[string]any{"name": "Watch", "client_streaming": false, "server_streaming": true},
+		},
+	}}, out.AsMap()["services"])
+}
+
+func TestServicesHandler(t *testing.T) {
+	// Arrange
+	handler := ServicesHandler(staticLister{"orders.v1.Orders": {Methods: []grpc.MethodInfo{{Name: "Get"}}}})
+	rec := httptest.NewRecorder()
+
+	// Act
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/services", nil))
+
+	// Assert
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `[{"name":"orders.v1.Orders","methods":[{"name":"Get","client_streaming":false,"server_streaming":false}]}]`, rec.Body.String())
+}
+
+func TestDescriptorRegistered(t *testing.T) {
+	// Act
+	desc, err := protoregistry.GlobalFiles.FindDescriptorByName(ServiceName)
+
+	// Assert
+	require.NoError(t, err, "reflection needs the admin API descriptor")
+	assert.Equal(t, adminProtoFile, desc.ParentFile().Path())
+}
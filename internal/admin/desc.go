@@ -0,0 +1,90 @@
+package admin
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Full names of the admin API, as served over gRPC
+const (
+	ServiceName          = "netgex.admin.v1.Admin"
+	GetServiceInfoMethod = "/" + ServiceName + "/GetServiceInfo"
+	GetConfigMethod      = "/" + ServiceName + "/GetConfig"
+	ListServicesMethod   = "/" + ServiceName + "/ListServices"
+)
+
+// adminProtoFile is the path the admin API descriptor is registered under
+const adminProtoFile = "netgex/admin/v1/admin.proto"
+
+// server is the handler type of the admin service descriptor
+type server interface {
+	GetServiceInfo(context.Context, *emptypb.Empty) (*structpb.Struct, error)
+	GetConfig(context.Context, *emptypb.Empty) (*structpb.Struct, error)
+	ListServices(context.Context, *emptypb.Empty) (*structpb.Struct, error)
+}
+
+// serviceDesc describes the admin API without generated code; all methods take
+// google.protobuf.Empty and return a google.protobuf.Struct
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*server)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "GetServiceInfo", Handler: unaryHandler(GetServiceInfoMethod, server.GetServiceInfo)},
+		{MethodName: "GetConfig", Handler: unaryHandler(GetConfigMethod, server.GetConfig)},
+		{MethodName: "ListServices", Handler: unaryHandler(ListServicesMethod, server.ListServices)},
+	},
+	Metadata: adminProtoFile,
+}
+
+// unaryHandler adapts a method to a grpc.MethodHandler, running the server interceptors
+func unaryHandler(
+	fullMethod string,
+	method func(server, context.Context, *emptypb.Empty) (*structpb.Struct, error),
+) grpc.MethodHandler {
+	return func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+		in := new(emptypb.Empty)
+		if err := dec(in); err != nil {
+			return nil, err
+		}
+		if interceptor == nil {
+			return method(srv.(server), ctx, in)
+		}
+		info := &grpc.UnaryServerInfo{Server: srv, FullMethod: fullMethod}
+		return interceptor(ctx, in, info, func(ctx context.Context, req any) (any, error) {
+			return method(srv.(server), ctx, req.(*emptypb.Empty))
+		})
+	}
+}
+
+// init registers the admin API file descriptor so it can be described by reflection
+func init() {
+	file := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String(adminProtoFile),
+		Package:    proto.String("netgex.admin.v1"),
+		Dependency: []string{"google/protobuf/empty.proto", "google/protobuf/struct.proto"},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("Admin"),
+			Method: []*descriptorpb.MethodDescriptorProto{
+				{Name: proto.String("GetServiceInfo"), InputType: proto.String(".google.protobuf.Empty"), OutputType: proto.String(".google.protobuf.Struct")},
+				{Name: proto.String("GetConfig"), InputType: proto.String(".google.protobuf.Empty"), OutputType: proto.String(".google.protobuf.Struct")},
+				{Name: proto.String("ListServices"), InputType: proto.String(".google.protobuf.Empty"), OutputType: proto.String(".google.protobuf.Struct")},
+			},
+		}},
+		Syntax: proto.String("proto3"),
+	}
+
+	fd, err := protodesc.NewFile(file, protoregistry.GlobalFiles)
+	if err != nil {
+		panic("admin: invalid descriptor: " + err.Error())
+	}
+	if err := protoregistry.GlobalFiles.RegisterFile(fd); err != nil {
+		panic("admin: failed to register descriptor: " + err.Error())
+	}
+}
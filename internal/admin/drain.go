@@ -0,0 +1,41 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/legrch/netgex/internal/inflight"
+)
+
+// DrainStatus tells deploy tooling whether the instance is draining and, once it is,
+// whether it is idle and safe to stop
+type DrainStatus struct {
+	Draining      bool                 `json:"draining"`
+	DrainingSince *time.Time           `json:"draining_since,omitempty"`
+	InFlight      inflight.Counts      `json:"in_flight"`
+	Connections   inflight.Connections `json:"connections"`
+	Idle          bool                 `json:"idle"`
+}
+
+// DrainHandler serves the drain status as JSON on GET, and starts draining on POST,
+// answering 202 Accepted with the status
+func DrainHandler(status func() DrainStatus, drain func()) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		code := http.StatusOK
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodPost:
+			drain()
+			code = http.StatusAccepted
+		default:
+			w.Header().Set("Allow", "GET, HEAD, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		_ = json.NewEncoder(w).Encode(status())
+	})
+}
@@ -0,0 +1,53 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/legrch/netgex/internal/inflight"
+)
+
+func TestDrainHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		wantCode int
+		draining bool
+	}{
+		{name: "status", method: http.MethodGet, wantCode: http.StatusOK},
+		{name: "start draining", method: http.MethodPost, wantCode: http.StatusAccepted, draining: true},
+		{name: "unsupported method", method: http.MethodDelete, wantCode: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			draining := false
+			status := func() DrainStatus {
+				return DrainStatus{Draining: draining, InFlight: inflight.Counts{GRPC: 2}, Connections: inflight.Connections{HTTP: 3}}
+			}
+			handler := DrainHandler(status, func() { draining = true })
+			rec := httptest.NewRecorder()
+
+			// Act
+			handler.ServeHTTP(rec, httptest.NewRequest(tt.method, "/drain", nil))
+
+			// Assert
+			assert.Equal(t, tt.wantCode, rec.Code)
+			assert.Equal(t, tt.draining, draining)
+			if rec.Code == http.StatusMethodNotAllowed {
+				return
+			}
+			var got DrainStatus
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+			assert.Equal(t, tt.draining, got.Draining)
+			assert.Equal(t, int64(2), got.InFlight.GRPC)
+			assert.Equal(t, int64(3), got.Connections.HTTP)
+		})
+	}
+}
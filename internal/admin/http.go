@@ -0,0 +1,134 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/legrch/netgex/internal/listener"
+)
+
+// HTTPOption is a function that configures an HTTPServer
+type HTTPOption func(*HTTPServer)
+
+// HTTPServer serves operational HTTP endpoints such as metrics and pprof on a single
+// admin address, along with /health and /readyz endpoints
+type HTTPServer struct {
+	logger       *slog.Logger
+	server       *http.Server
+	mux          *http.ServeMux
+	closeTimeout time.Duration
+	draining     atomic.Bool
+	listen       listener.Options
+	healthCheck  func(context.Context) error
+}
+
+// NewHTTPServer creates an admin HTTP server listening on address
+func NewHTTPServer(logger *slog.Logger, address string, closeTimeout time.Duration, opts ...HTTPOption) *HTTPServer {
+	s := &HTTPServer{
+		logger:       logger,
+		mux:          http.NewServeMux(),
+		closeTimeout: closeTimeout,
+	}
+	s.server = &http.Server{
+		Addr:              address,
+		Handler:           s.mux,
+		ReadHeaderTimeout: 5 * time.Second, // Prevent Slowloris attacks
+	}
+	s.mux.HandleFunc("/health", s.health)
+	// Kubernetes-style readiness endpoint, running the same checks
+	s.mux.HandleFunc("/readyz", s.health)
+
+	// Apply options
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// WithHTTPHandler mounts a handler on the admin HTTP server
+func WithHTTPHandler(pattern string, handler http.Handler) HTTPOption {
+	return func(s *HTTPServer) {
+		s.mux.Handle(pattern, handler)
+	}
+}
+
+// WithHTTPListenOptions sets the socket options of the listener, SO_REUSEPORT included
+func WithHTTPListenOptions(opts listener.Options) HTTPOption {
+	return func(s *HTTPServer) {
+		s.listen = opts
+	}
+}
+
+// WithHTTPHealthCheck makes the health endpoint report NOT_SERVING while check fails
+func WithHTTPHealthCheck(check func(context.Context) error) HTTPOption {
+	return func(s *HTTPServer) {
+		s.healthCheck = check
+	}
+}
+
+// health reports NOT_SERVING once the server is draining or while the health check fails
+func (s *HTTPServer) health(w http.ResponseWriter, r *http.Request) {
+	if s.healthCheck != nil {
+		if err := s.healthCheck(r.Context()); err != nil {
+			s.logger.Warn("health check failed", "error", err)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("NOT_SERVING"))
+			return
+		}
+	}
+	if s.draining.Load() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte("NOT_SERVING"))
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("OK"))
+}
+
+// Handler returns the handler serving the admin endpoints
+func (s *HTTPServer) Handler() http.Handler {
+	return s.server.Handler
+}
+
+// PreRun prepares the admin HTTP server
+func (*HTTPServer) PreRun(_ context.Context) error {
+	return nil
+}
+
+// Run starts the admin HTTP server
+func (s *HTTPServer) Run(ctx context.Context) error {
+	s.logger.Info("starting admin HTTP server", "address", s.server.Addr)
+	lis, err := listener.Listen(ctx, s.server.Addr, s.listen)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	if err := s.server.Serve(lis); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("admin HTTP server error: %w", err)
+	}
+	return nil
+}
+
+// Drain makes the health endpoint report NOT_SERVING ahead of shutdown
+func (s *HTTPServer) Drain() {
+	s.logger.Info("admin health set to NOT_SERVING")
+	s.draining.Store(true)
+}
+
+// Shutdown gracefully stops the admin HTTP server
+func (s *HTTPServer) Shutdown(ctx context.Context) error {
+	s.logger.Info("shutting down admin HTTP server")
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, s.closeTimeout)
+	defer cancel()
+
+	if err := s.server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("admin HTTP server shutdown error: %w", err)
+	}
+
+	return nil
+}
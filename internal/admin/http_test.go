@@ -0,0 +1,56 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPServer_Handler(t *testing.T) {
+	// Arrange
+	metrics := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { _, _ = w.Write([]byte("metrics")) })
+	srv := NewHTTPServer(slog.Default(), ":0", time.Second, WithHTTPHandler("/metrics", metrics))
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	// Act & Assert
+	assert.Equal(t, "metrics", get("/metrics").Body.String())
+	assert.Equal(t, http.StatusOK, get("/health").Code)
+
+	srv.Drain()
+	rec := get("/health")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "NOT_SERVING", rec.Body.String())
+}
+
+func TestHTTPServer_HealthCheck(t *testing.T) {
+	// Arrange
+	var checkErr error
+	srv := NewHTTPServer(slog.Default(), ":0", time.Second, WithHTTPHealthCheck(func(context.Context) error {
+		return checkErr
+	}))
+
+	get := func(path string) int {
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Code
+	}
+
+	// Act & Assert
+	assert.Equal(t, http.StatusOK, get("/health"))
+	assert.Equal(t, http.StatusOK, get("/readyz"))
+
+	checkErr = errors.New("database unreachable")
+	assert.Equal(t, http.StatusServiceUnavailable, get("/health"))
+	assert.Equal(t, http.StatusServiceUnavailable, get("/readyz"))
+}
@@ -0,0 +1,69 @@
+package admin
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// ServiceDescription describes a registered gRPC service and its methods
+type ServiceDescription struct {
+	Name    string              `json:"name"`
+	Methods []MethodDescription `json:"methods"`
+}
+
+// MethodDescription describes a method of a registered gRPC service
+type MethodDescription struct {
+	Name            string `json:"name"`
+	ClientStreaming bool   `json:"client_streaming"`
+	ServerStreaming bool   `json:"server_streaming"`
+}
+
+// ListServices returns the services registered on the server and their methods,
+// sorted by name
+func ListServices(lister ServiceLister) []ServiceDescription {
+	info := lister.GetServiceInfo()
+	services := make([]ServiceDescription, 0, len(info))
+	for _, name := range slices.Sorted(maps.Keys(info)) {
+		methods := make([]MethodDescription, 0, len(info[name].Methods))
+		for _, m := range info[name].Methods {
+			methods = append(methods, MethodDescription{
+				Name:            m.Name,
+				ClientStreaming: m.IsClientStream,
+				ServerStreaming: m.IsServerStream,
+			})
+		}
+		slices.SortFunc(methods, func(a, b MethodDescription) int {
+			return strings.Compare(a.Name, b.Name)
+		})
+		services = append(services, ServiceDescription{Name: name, Methods: methods})
+	}
+	return services
+}
+
+// ServicesHandler serves the registered services and their methods as JSON
+func ServicesHandler(lister ServiceLister) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(ListServices(lister))
+	})
+}
+
+// servicesValue converts the service list to a value accepted by structpb
+func servicesValue(services []ServiceDescription) []any {
+	values := make([]any, 0, len(services))
+	for _, svc := range services {
+		methods := make([]any, 0, len(svc.Methods))
+		for _, m := range svc.Methods {
+			methods = append(methods, map[string]any{
+				"name":             m.Name,
+				"client_streaming": m.ClientStreaming,
+				"server_streaming": m.ServerStreaming,
+			})
+		}
+		values = append(values, map[string]any{"name": svc.Name, "methods": methods})
+	}
+	return values
+}
@@ -0,0 +1,75 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	goruntime "runtime"
+	"runtime/debug"
+	"time"
+)
+
+// BuildInfo describes the build of the service, usually set with -ldflags. Empty fields
+// are read from the build information embedded by the Go toolchain.
+type BuildInfo struct {
+	Version   string
+	Commit    string
+	BuildDate string
+}
+
+// VersionInfo is the build metadata and uptime of the running service
+type VersionInfo struct {
+	Name      string    `json:"name"`
+	Version   string    `json:"version"`
+	Commit    string    `json:"commit"`
+	BuildDate string    `json:"build_date"`
+	GoVersion string    `json:"go_version"`
+	StartTime time.Time `json:"start_time"`
+	Uptime    string    `json:"uptime"`
+}
+
+// Version reports the version of the running service
+type Version struct {
+	name    string
+	build   BuildInfo
+	started time.Time
+}
+
+// NewVersion creates the version of the service, started now. The version falls back to
+// version, and the commit and build date to the VCS information of the binary.
+func NewVersion(name, version string, build BuildInfo) *Version {
+	if build.Version == "" {
+		build.Version = version
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range bi.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && build.Commit == "":
+				build.Commit = setting.Value
+			case setting.Key == "vcs.time" && build.BuildDate == "":
+				build.BuildDate = setting.Value
+			}
+		}
+	}
+	return &Version{name: name, build: build, started: time.Now()}
+}
+
+// Info returns the build metadata and the uptime so far
+func (v *Version) Info() VersionInfo {
+	return VersionInfo{
+		Name:      v.name,
+		Version:   v.build.Version,
+		Commit:    v.build.Commit,
+		BuildDate: v.build.BuildDate,
+		GoVersion: goruntime.Version(),
+		StartTime: v.started.UTC(),
+		Uptime:    time.Since(v.started).Round(time.Second).String(),
+	}
+}
+
+// Handler serves the version as JSON
+func (v *Version) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(v.Info())
+	})
+}
@@ -0,0 +1,33 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersion_Handler(t *testing.T) {
+	// Arrange
+	version := NewVersion("orders", "1.2.3", BuildInfo{Commit: "abc123", BuildDate: "2024-03-05T14:07:09Z"})
+	rec := httptest.NewRecorder()
+
+	// Act
+	version.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/version", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	var info VersionInfo
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &info))
+	assert.Equal(t, "orders", info.Name)
+	assert.Equal(t, "1.2.3", info.Version)
+	assert.Equal(t, "abc123", info.Commit)
+	assert.Equal(t, "2024-03-05T14:07:09Z", info.BuildDate)
+	assert.Equal(t, runtime.Version(), info.GoVersion)
+	assert.NotEmpty(t, info.Uptime)
+}
@@ -0,0 +1,54 @@
+// Package descriptors finds the protobuf descriptors of registered gRPC services, for
+// the registrars serving their methods over other protocols on the gateway: GraphQL,
+// HTTP rules and Twirp.
+package descriptors
+
+import (
+	"sort"
+
+	"github.com/legrch/netgex/service"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// GatewayOnly is embedded by the registrars that have no gRPC services of their own
+// and call the methods of the services implementing them
+type GatewayOnly struct{}
+
+// RegisterGRPC does nothing, the methods are served by their own services
+func (GatewayOnly) RegisterGRPC(*grpc.Server) {}
+
+// Services returns the gRPC services of the registrars whose descriptors are in the
+// global registry, sorted by name, found by registering them on a server that is never
+// started
+func Services(registrars []service.Registrar) []protoreflect.ServiceDescriptor {
+	srv := grpc.NewServer()
+	defer srv.Stop()
+	for _, registrar := range registrars {
+		registrar.RegisterGRPC(srv)
+	}
+
+	var services []protoreflect.ServiceDescriptor
+	for name := range srv.GetServiceInfo() {
+		desc, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(name))
+		if err != nil {
+			continue
+		}
+		if sd, ok := desc.(protoreflect.ServiceDescriptor); ok {
+			services = append(services, sd)
+		}
+	}
+	sort.Slice(services, func(i, j int) bool { return services[i].FullName() < services[j].FullName() })
+	return services
+}
+
+// MessageType returns the Go type of a message, or a dynamic one when no generated
+// type is linked in
+func MessageType(md protoreflect.MessageDescriptor) protoreflect.MessageType {
+	if mt, err := protoregistry.GlobalTypes.FindMessageByName(md.FullName()); err == nil {
+		return mt
+	}
+	return dynamicpb.NewMessageType(md)
+}
@@ -0,0 +1,63 @@
+package descriptors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/legrch/netgex/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// healthRegistrar registers the gRPC health service, plus a service without descriptor
+type healthRegistrar struct{}
+
+func (healthRegistrar) RegisterGRPC(srv *grpc.Server) {
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	srv.RegisterService(&grpc.ServiceDesc{ServiceName: "unknown.v1.Unknown", HandlerType: (*any)(nil)}, struct{}{})
+}
+
+func (healthRegistrar) RegisterHTTP(context.Context, *runtime.ServeMux, string, []grpc.DialOption) error {
+	return nil
+}
+
+func TestServices(t *testing.T) {
+	// Act
+	services := Services([]service.Registrar{healthRegistrar{}})
+
+	// Assert
+	require.Len(t, services, 1)
+	assert.Equal(t, protoreflect.FullName("grpc.health.v1.Health"), services[0].FullName())
+}
+
+func TestMessageType(t *testing.T) {
+	// Arrange
+	generated := (&healthpb.HealthCheckRequest{}).ProtoReflect().Descriptor()
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:        ptr("unlinked.proto"),
+		Package:     ptr("unlinked"),
+		MessageType: []*descriptorpb.DescriptorProto{{Name: ptr("Message")}},
+	}, nil)
+	require.NoError(t, err)
+	unlinked := file.Messages().Get(0)
+
+	// Act
+	generatedType := MessageType(generated)
+	unlinkedType := MessageType(unlinked)
+
+	// Assert
+	assert.IsType(t, &healthpb.HealthCheckRequest{}, generatedType.New().Interface())
+	assert.IsType(t, &dynamicpb.Message{}, unlinkedType.New().Interface())
+}
+
+func ptr(s string) *string {
+	return &s
+}
@@ -0,0 +1,149 @@
+// Package eventqueue sends events to an external service in the background: events are
+// queued without blocking the caller, sent while the queue runs as a process, and the
+// remaining ones are sent on shutdown.
+package eventqueue
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Queue holds events of type T until send delivers them
+type Queue[T any] struct {
+	send     func(ctx context.Context, events []T)
+	maxBatch int
+	items    chan T
+
+	mu     sync.RWMutex
+	closed bool
+
+	// pending counts the queued and in-flight events; sent is closed while it is zero
+	pendingMu sync.Mutex
+	pending   int
+	sent      chan struct{}
+}
+
+// New creates a queue holding up to size events, delivered with send
+func New[T any](size int, send func(ctx context.Context, event T)) *Queue[T] {
+	return NewBatched(size, 1, func(ctx context.Context, events []T) {
+		send(ctx, events[0])
+	})
+}
+
+// NewBatched creates a queue holding up to size events, delivered with send in batches
+// of the events queued at the time, up to maxBatch
+func NewBatched[T any](size, maxBatch int, send func(ctx context.Context, events []T)) *Queue[T] {
+	q := &Queue[T]{
+		send:     send,
+		maxBatch: max(maxBatch, 1),
+		items:    make(chan T, size),
+		sent:     make(chan struct{}),
+	}
+	close(q.sent)
+	return q
+}
+
+// Enqueue queues an event, returning false when the queue is full or shut down
+func (q *Queue[T]) Enqueue(event T) bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return false
+	}
+
+	q.addPending()
+	select {
+	case q.items <- event:
+		return true
+	default:
+		q.donePending()
+		return false
+	}
+}
+
+// addPending counts a queued event
+func (q *Queue[T]) addPending() {
+	q.pendingMu.Lock()
+	defer q.pendingMu.Unlock()
+	if q.pending == 0 {
+		q.sent = make(chan struct{})
+	}
+	q.pending++
+}
+
+// donePending counts events as sent or given up on
+func (q *Queue[T]) donePending() {
+	q.donePendingN(1)
+}
+
+func (q *Queue[T]) donePendingN(n int) {
+	q.pendingMu.Lock()
+	defer q.pendingMu.Unlock()
+	q.pending -= n
+	if q.pending == 0 {
+		close(q.sent)
+	}
+}
+
+// deliver sends an event with the events queued behind it, up to a batch, and counts
+// them as done
+func (q *Queue[T]) deliver(ctx context.Context, event T) {
+	batch := []T{event}
+	for len(batch) < q.maxBatch {
+		select {
+		case next := <-q.items:
+			batch = append(batch, next)
+			continue
+		default:
+		}
+		break
+	}
+
+	defer q.donePendingN(len(batch))
+	q.send(ctx, batch)
+}
+
+// Run sends the queued events until ctx is canceled
+func (q *Queue[T]) Run(ctx context.Context) error {
+	for {
+		select {
+		case event := <-q.items:
+			// An event being sent when shutdown begins is still delivered
+			q.deliver(context.WithoutCancel(ctx), event)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// Flush waits until the queued events are sent or ctx ends
+func (q *Queue[T]) Flush(ctx context.Context) error {
+	q.pendingMu.Lock()
+	sent := q.sent
+	q.pendingMu.Unlock()
+
+	select {
+	case <-sent:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("events not flushed: %w", ctx.Err())
+	}
+}
+
+// Shutdown stops queueing and sends the remaining events, bounded by ctx
+func (q *Queue[T]) Shutdown(ctx context.Context) error {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+
+	// Run stopped with the server's context, so the events left are sent here
+	for {
+		select {
+		case event := <-q.items:
+			q.deliver(ctx, event)
+		default:
+			return q.Flush(ctx)
+		}
+	}
+}
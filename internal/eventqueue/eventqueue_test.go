@@ -0,0 +1,113 @@
+package eventqueue
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder records the events it is sent
+type recorder struct {
+	mu     sync.Mutex
+	events []int
+}
+
+func (r *recorder) send(_ context.Context, event int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recorder) sent() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]int(nil), r.events...)
+}
+
+func TestQueue_RunAndFlush(t *testing.T) {
+	// Arrange
+	r := &recorder{}
+	q := New(10, r.send)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = q.Run(ctx) }()
+
+	// Act
+	q.Enqueue(1)
+	q.Enqueue(2)
+	err := q.Flush(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, r.sent())
+}
+
+func TestQueue_Full(t *testing.T) {
+	// Arrange
+	r := &recorder{}
+	q := New(1, r.send)
+
+	// Act
+	first := q.Enqueue(1)
+	second := q.Enqueue(2)
+
+	// Assert
+	assert.True(t, first)
+	assert.False(t, second, "events beyond the size are dropped")
+	require.NoError(t, q.Shutdown(context.Background()))
+	assert.Equal(t, []int{1}, r.sent())
+}
+
+func TestQueue_Shutdown(t *testing.T) {
+	// Arrange
+	r := &recorder{}
+	q := New(10, r.send)
+	q.Enqueue(1)
+
+	// Act
+	err := q.Shutdown(context.Background())
+	late := q.Enqueue(2)
+
+	// Assert
+	require.NoError(t, err)
+	assert.False(t, late, "events after shutdown are dropped")
+	assert.Equal(t, []int{1}, r.sent())
+}
+
+func TestQueue_FlushTimeout(t *testing.T) {
+	// Arrange
+	q := New(10, (&recorder{}).send)
+	q.Enqueue(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Act
+	err := q.Flush(ctx)
+
+	// Assert
+	assert.ErrorIs(t, err, context.Canceled, "nothing runs the queue")
+}
+
+func TestQueue_Batched(t *testing.T) {
+	// Arrange
+	var mu sync.Mutex
+	var batches [][]int
+	q := NewBatched(10, 2, func(_ context.Context, events []int) {
+		mu.Lock()
+		defer mu.Unlock()
+		batches = append(batches, events)
+	})
+	for i := 1; i <= 5; i++ {
+		q.Enqueue(i)
+	}
+
+	// Act
+	err := q.Shutdown(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, [][]int{{1, 2}, {3, 4}, {5}}, batches)
+}
@@ -8,8 +8,10 @@ type JSONConfig struct {
 	EmitUnpopulated bool `envconfig:"EMIT_UNPOPULATED" default:"true"`
 	// UseEnumNumbers renders enum values as numbers instead of strings
 	UseEnumNumbers bool `envconfig:"USE_ENUM_NUMBERS" default:"true"`
-	// AllowPartial allows incomplete proto messages
+	// AllowPartial allows incomplete proto messages, in responses and request bodies
 	AllowPartial bool `envconfig:"ALLOW_PARTIAL" default:"true"`
+	// DiscardUnknown ignores unknown fields of request bodies instead of rejecting them
+	DiscardUnknown bool `envconfig:"DISCARD_UNKNOWN" default:"false"`
 	// Multiline formats the output in indented form
 	Multiline bool `envconfig:"MULTILINE" default:"true"`
 	// Indent specifies the set of indentation characters to use in multiline mode
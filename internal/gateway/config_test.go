@@ -18,4 +18,5 @@ func TestDefaultJSONConfig(t *testing.T) {
 	assert.True(t, cfg.AllowPartial)
 	assert.True(t, cfg.Multiline)
 	assert.Equal(t, "  ", cfg.Indent)
+	assert.False(t, cfg.DiscardUnknown)
 }
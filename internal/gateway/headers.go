@@ -0,0 +1,82 @@
+package gateway
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+)
+
+// Outgoing header modes accepted by WithOutgoingHeaders
+const (
+	// OutgoingHeadersPrefixed forwards response metadata as Grpc-Metadata-<key> headers,
+	// the grpc-gateway default
+	OutgoingHeadersPrefixed = "prefixed"
+	// OutgoingHeadersStripped forwards response metadata as headers named after their
+	// keys, except for keys reserved by HTTP and gRPC
+	OutgoingHeadersStripped = "stripped"
+	// OutgoingHeadersNone forwards no response metadata
+	OutgoingHeadersNone = "none"
+)
+
+// reservedHeaders are metadata keys never forwarded without a prefix, as they would
+// overwrite the headers of the HTTP response
+var reservedHeaders = map[string]bool{
+	"content-type":      true,
+	"content-length":    true,
+	"content-encoding":  true,
+	"connection":        true,
+	"keep-alive":        true,
+	"te":                true,
+	"trailer":           true,
+	"transfer-encoding": true,
+	"upgrade":           true,
+}
+
+// parseOutgoingHeaders validates the name of an outgoing header mode
+func parseOutgoingHeaders(mode string) error {
+	switch mode {
+	case OutgoingHeadersPrefixed, OutgoingHeadersStripped, OutgoingHeadersNone:
+		return nil
+	}
+	return fmt.Errorf("invalid outgoing header mode %q: must be %q, %q or %q",
+		mode, OutgoingHeadersPrefixed, OutgoingHeadersStripped, OutgoingHeadersNone)
+}
+
+// outgoingHeaderMatcher returns the matcher forwarding response metadata in the mode.
+// With keys, only the listed metadata keys are forwarded; keys ending with "*" match
+// every key starting with the rest.
+func outgoingHeaderMatcher(mode string, keys []string) HeaderMatcherFunc {
+	return func(key string) (string, bool) {
+		key = strings.ToLower(key)
+		if mode == OutgoingHeadersNone || !matchesKey(key, keys) {
+			return "", false
+		}
+		if mode == OutgoingHeadersStripped {
+			if reservedHeaders[key] || strings.HasPrefix(key, "grpc-") {
+				return "", false
+			}
+			return key, true
+		}
+		return runtime.MetadataHeaderPrefix + key, true
+	}
+}
+
+// matchesKey reports whether the metadata key is listed, every key being listed when
+// the list is empty
+func matchesKey(key string, keys []string) bool {
+	if len(keys) == 0 {
+		return true
+	}
+	for _, k := range keys {
+		k = strings.ToLower(k)
+		if prefix, ok := strings.CutSuffix(k, "*"); ok {
+			if strings.HasPrefix(key, prefix) {
+				return true
+			}
+		} else if key == k {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package gateway
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutgoingHeaderMatcher(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       string
+		keys       []string
+		key        string
+		wantHeader string
+		wantOK     bool
+	}{
+		{name: "prefixed", mode: OutgoingHeadersPrefixed, key: "x-request-id", wantHeader: "Grpc-Metadata-x-request-id", wantOK: true},
+		{name: "stripped", mode: OutgoingHeadersStripped, key: "x-request-id", wantHeader: "x-request-id", wantOK: true},
+		{name: "stripped reserved key", mode: OutgoingHeadersStripped, key: "content-type"},
+		{name: "stripped grpc key", mode: OutgoingHeadersStripped, key: "grpc-status"},
+		{name: "prefixed reserved key", mode: OutgoingHeadersPrefixed, key: "content-type", wantHeader: "Grpc-Metadata-content-type", wantOK: true},
+		{name: "none", mode: OutgoingHeadersNone, key: "x-request-id"},
+		{name: "listed key", mode: OutgoingHeadersStripped, keys: []string{"X-Request-Id"}, key: "x-request-id", wantHeader: "x-request-id", wantOK: true},
+		{name: "unlisted key", mode: OutgoingHeadersStripped, keys: []string{"x-request-id"}, key: "x-internal-shard"},
+		{name: "listed prefix", mode: OutgoingHeadersPrefixed, keys: []string{"x-ratelimit-*"}, key: "x-ratelimit-remaining", wantHeader: "Grpc-Metadata-x-ratelimit-remaining", wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			matcher := outgoingHeaderMatcher(tt.mode, tt.keys)
+
+			// Act
+			header, ok := matcher(tt.key)
+
+			// Assert
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantHeader, header)
+		})
+	}
+}
+
+func TestServer_PreRun_InvalidOutgoingHeaders(t *testing.T) {
+	// Arrange
+	srv := NewServer(slog.New(slog.DiscardHandler), time.Second, ":50051", ":8080", WithOutgoingHeaders("all"))
+
+	// Act
+	err := srv.PreRun(context.Background())
+
+	// Assert
+	assert.ErrorContains(t, err, `invalid outgoing header mode "all"`)
+}
@@ -0,0 +1,155 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// Route is an HTTP route served by the gateway
+type Route struct {
+	// Method is the HTTP method, or the custom verb kind
+	Method string `json:"method"`
+	// Pattern is the HTTP path template, e.g. /v1/users/{id}
+	Pattern string `json:"pattern"`
+	// RPC is the full name of the backing gRPC method, e.g. /pkg.Service/Method
+	RPC string `json:"rpc"`
+}
+
+// ruleLister is implemented by registrars declaring HTTP rules at runtime, such as
+// httprule.Registrar
+type ruleLister interface {
+	HTTPRules() []*annotations.HttpRule
+}
+
+// Routes returns the HTTP routes of the gateway's services, read from the
+// google.api.http annotations of their registered proto descriptors, and those of the
+// HTTP rules declared at runtime
+func (s *Server) Routes() []Route {
+	routes := ServiceRoutes(s.serviceNames()...)
+	for _, registrar := range s.registrars {
+		lister, ok := registrar.(ruleLister)
+		if !ok {
+			continue
+		}
+		for _, rule := range lister.HTTPRules() {
+			rpc := selectorRPC(rule.GetSelector())
+			routes = appendHTTPRule(routes, rule, rpc)
+			for _, binding := range rule.GetAdditionalBindings() {
+				routes = appendHTTPRule(routes, binding, rpc)
+			}
+		}
+	}
+	sortRoutes(routes)
+	return routes
+}
+
+// selectorRPC turns the selector of an HTTP rule, pkg.Service.Method, into the gRPC name
+// of the method, /pkg.Service/Method
+func selectorRPC(selector string) string {
+	if strings.HasPrefix(selector, "/") {
+		return selector
+	}
+	i := strings.LastIndex(selector, ".")
+	if i < 0 {
+		return "/" + selector
+	}
+	return "/" + selector[:i] + "/" + selector[i+1:]
+}
+
+// RoutesHandler serves the routes of the gateway as JSON
+func (s *Server) RoutesHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(s.Routes())
+	})
+}
+
+// serviceNames returns the gRPC services of the registrars, found by registering them
+// on a server that is never started
+func (s *Server) serviceNames() []string {
+	srv := grpc.NewServer()
+	defer srv.Stop()
+	for _, registrar := range s.registrars {
+		registrar.RegisterGRPC(srv)
+	}
+
+	names := make([]string, 0, len(srv.GetServiceInfo()))
+	for name := range srv.GetServiceInfo() {
+		names = append(names, name)
+	}
+	return names
+}
+
+// ServiceRoutes returns the HTTP routes declared with google.api.http annotations on
+// the named gRPC services, sorted by pattern and method. Services whose descriptors are
+// not in the global registry have no routes.
+func ServiceRoutes(services ...string) []Route {
+	var routes []Route
+	for _, name := range services {
+		desc, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(name))
+		if err != nil {
+			continue
+		}
+		sd, ok := desc.(protoreflect.ServiceDescriptor)
+		if !ok {
+			continue
+		}
+
+		methods := sd.Methods()
+		for i := range methods.Len() {
+			md := methods.Get(i)
+			rule, ok := proto.GetExtension(md.Options(), annotations.E_Http).(*annotations.HttpRule)
+			if !ok || rule == nil {
+				continue
+			}
+			rpc := "/" + name + "/" + string(md.Name())
+			routes = appendHTTPRule(routes, rule, rpc)
+			for _, binding := range rule.GetAdditionalBindings() {
+				routes = appendHTTPRule(routes, binding, rpc)
+			}
+		}
+	}
+
+	sortRoutes(routes)
+	return routes
+}
+
+// sortRoutes sorts routes by pattern and method
+func sortRoutes(routes []Route) {
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Pattern != routes[j].Pattern {
+			return routes[i].Pattern < routes[j].Pattern
+		}
+		return routes[i].Method < routes[j].Method
+	})
+}
+
+// appendHTTPRule appends the route of an HTTP rule, skipping rules without a pattern
+func appendHTTPRule(routes []Route, rule *annotations.HttpRule, rpc string) []Route {
+	var method, pattern string
+	switch p := rule.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		method, pattern = "GET", p.Get
+	case *annotations.HttpRule_Put:
+		method, pattern = "PUT", p.Put
+	case *annotations.HttpRule_Post:
+		method, pattern = "POST", p.Post
+	case *annotations.HttpRule_Delete:
+		method, pattern = "DELETE", p.Delete
+	case *annotations.HttpRule_Patch:
+		method, pattern = "PATCH", p.Patch
+	case *annotations.HttpRule_Custom:
+		method, pattern = p.Custom.GetKind(), p.Custom.GetPath()
+	default:
+		return routes
+	}
+	return append(routes, Route{Method: method, Pattern: pattern, RPC: rpc})
+}
@@ -0,0 +1,157 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const routesTestService = "netgex.gateway.test.RoutesTestService"
+
+// init registers a service descriptor with HTTP annotations for the route tests
+func init() {
+	httpOptions := func(rule *annotations.HttpRule) *descriptorpb.MethodOptions {
+		opts := &descriptorpb.MethodOptions{}
+		proto.SetExtension(opts, annotations.E_Http, rule)
+		return opts
+	}
+
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("netgex/gateway/test/routes.proto"),
+		Package: proto.String("netgex.gateway.test"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: proto.String("Empty")},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("RoutesTestService"),
+			Method: []*descriptorpb.MethodDescriptorProto{
+				{
+					Name:       proto.String("GetUser"),
+					InputType:  proto.String(".netgex.gateway.test.Empty"),
+					OutputType: proto.String(".netgex.gateway.test.Empty"),
+					Options: httpOptions(&annotations.HttpRule{
+						Pattern: &annotations.HttpRule_Get{Get: "/v1/users/{id}"},
+						AdditionalBindings: []*annotations.HttpRule{
+							{Pattern: &annotations.HttpRule_Get{Get: "/v1/accounts/{id}"}},
+						},
+					}),
+				},
+				{
+					Name:       proto.String("CreateUser"),
+					InputType:  proto.String(".netgex.gateway.test.Empty"),
+					OutputType: proto.String(".netgex.gateway.test.Empty"),
+					Options: httpOptions(&annotations.HttpRule{
+						Pattern: &annotations.HttpRule_Post{Post: "/v1/users"},
+						Body:    "*",
+					}),
+				},
+				{
+					Name:       proto.String("Internal"),
+					InputType:  proto.String(".netgex.gateway.test.Empty"),
+					OutputType: proto.String(".netgex.gateway.test.Empty"),
+				},
+			},
+		}},
+	}
+
+	fd, err := protodesc.NewFile(file, protoregistry.GlobalFiles)
+	if err != nil {
+		panic(err)
+	}
+	if err := protoregistry.GlobalFiles.RegisterFile(fd); err != nil {
+		panic(err)
+	}
+}
+
+// routesTestRegistrar registers an empty implementation of the test service
+type routesTestRegistrar struct{}
+
+func (routesTestRegistrar) RegisterGRPC(srv *grpc.Server) {
+	srv.RegisterService(&grpc.ServiceDesc{ServiceName: routesTestService, HandlerType: (*any)(nil)}, struct{}{})
+}
+
+func (routesTestRegistrar) RegisterHTTP(context.Context, *runtime.ServeMux, string, []grpc.DialOption) error {
+	return nil
+}
+
+func TestServiceRoutes(t *testing.T) {
+	// Act
+	routes := ServiceRoutes(routesTestService, "unknown.Service")
+
+	// Assert
+	assert.Equal(t, []Route{
+		{Method: "GET", Pattern: "/v1/accounts/{id}", RPC: "/" + routesTestService + "/GetUser"},
+		{Method: "POST", Pattern: "/v1/users", RPC: "/" + routesTestService + "/CreateUser"},
+		{Method: "GET", Pattern: "/v1/users/{id}", RPC: "/" + routesTestService + "/GetUser"},
+	}, routes)
+}
+
+func TestServer_Routes(t *testing.T) {
+	// Arrange
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	srv := NewServer(logger, time.Second, ":50051", ":8080", WithServices(routesTestRegistrar{}))
+
+	// Act
+	routes := srv.Routes()
+
+	// Assert
+	require.Len(t, routes, 3)
+	assert.Equal(t, "/"+routesTestService+"/CreateUser", routes[1].RPC)
+}
+
+// ruleRegistrar declares HTTP rules at runtime
+type ruleRegistrar struct {
+	routesTestRegistrar
+}
+
+func (ruleRegistrar) HTTPRules() []*annotations.HttpRule {
+	return []*annotations.HttpRule{{
+		Selector: routesTestService + ".Internal",
+		Pattern:  &annotations.HttpRule_Get{Get: "/v1/internal"},
+	}}
+}
+
+func TestServer_Routes_RuntimeRules(t *testing.T) {
+	// Arrange
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	srv := NewServer(logger, time.Second, ":50051", ":8080", WithServices(ruleRegistrar{}))
+
+	// Act
+	routes := srv.Routes()
+
+	// Assert
+	require.Len(t, routes, 4)
+	assert.Equal(t, Route{Method: "GET", Pattern: "/v1/internal", RPC: "/" + routesTestService + "/Internal"}, routes[1])
+}
+
+func TestServer_RoutesHandler(t *testing.T) {
+	// Arrange
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	srv := NewServer(logger, time.Second, ":50051", ":8080", WithServices(routesTestRegistrar{}))
+	rec := httptest.NewRecorder()
+
+	// Act
+	srv.RoutesHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/routes", nil))
+
+	// Assert
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	var routes []Route
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &routes))
+	assert.Equal(t, srv.Routes(), routes)
+}
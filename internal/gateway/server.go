@@ -2,12 +2,18 @@ package gateway
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
-	"path/filepath"
+	"path"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
@@ -17,12 +23,23 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/protobuf/encoding/protojson"
 
+	// Registers google.rpc error details, such as BadRequest, so error bodies render them
+	_ "google.golang.org/genproto/googleapis/rpc/errdetails"
+
+	"github.com/legrch/netgex/internal/listener"
 	"github.com/legrch/netgex/service"
 )
 
 // HeaderMatcherFunc is a function for matching headers in gRPC gateway
 type HeaderMatcherFunc = func(string) (string, bool)
 
+// Middleware wraps the gateway HTTP handler
+type Middleware = func(http.Handler) http.Handler
+
+// RoutingErrorFunc returns the error of a request no route matches, written with the
+// mux error handler like the errors of RPCs
+type RoutingErrorFunc func(r *http.Request) error
+
 // Option is a function that configures a Server
 type Option func(*Server)
 
@@ -33,19 +50,62 @@ type Server struct {
 	closeTimeout          time.Duration
 	grpcAddress           string
 	httpAddress           string
+	backend               Backend
 	registrars            []service.Registrar
 	muxOptions            []runtime.ServeMuxOption
+	dialOptions           []grpc.DialOption
 	incomingHeaderMatcher HeaderMatcherFunc
 	outgoingHeaderMatcher HeaderMatcherFunc
+	outgoingHeaders       string
+	outgoingHeaderKeys    []string
 	corsEnabled           bool
 	corsOptions           cors.Options
 	pprofEnabled          bool
 	swaggerEnabled        bool
 	swaggerDir            string
+	swaggerFS             fs.FS
 	swaggerBasePath       string
+	swaggerCDN            string
+	swaggerToken          string
+	swaggerUser           string
+	swaggerPassword       string
 	jsonConfig            *JSONConfig
+	grpcHandler           http.Handler
+	handlers              map[string]http.Handler
+	healthPaths           []string
+	notFound              RoutingErrorFunc
+	methodNotAllowed      RoutingErrorFunc
+	unescapingMode        string
+	pathLengthFallback    bool
+	streamFraming         string
+	streamFlushInterval   time.Duration
+	timeoutHeaders        []string
+	middleware            []Middleware
+	virtualHosts          []virtualHost
+	serve                 ServeFunc
+	stopServe             context.CancelFunc
+	draining              atomic.Bool
+	listen                listener.Options
+	listener              net.Listener
+	bound                 listener.Bound
+	mu                    sync.Mutex
+}
+
+// virtualHost is a gateway served on this server's port for requests to its hosts
+type virtualHost struct {
+	hosts  []string
+	server *Server
+}
+
+// Backend is the gRPC server the gateway calls, reporting the address it is bound to
+type Backend interface {
+	Addr() string
+	Ready() <-chan struct{}
 }
 
+// ServeFunc serves the composed gateway handler until the context is canceled
+type ServeFunc func(ctx context.Context, handler http.Handler) error
+
 // NewServer creates a new gRPC-Gateway server
 func NewServer(
 	logger *slog.Logger,
@@ -63,7 +123,12 @@ func NewServer(
 			Addr:              httpAddress,
 			ReadHeaderTimeout: 5 * time.Second, // Prevent Slowloris attacks
 		},
-		jsonConfig: DefaultJSONConfig(),
+		jsonConfig:         DefaultJSONConfig(),
+		healthPaths:        []string{"/health"},
+		unescapingMode:     UnescapingLegacy,
+		pathLengthFallback: true,
+		streamFraming:      StreamFramingDefault,
+		outgoingHeaders:    OutgoingHeadersPrefixed,
 	}
 
 	// Apply options
@@ -88,6 +153,21 @@ func WithMuxOptions(options ...runtime.ServeMuxOption) Option {
 	}
 }
 
+// WithBackend makes the gateway wait for the gRPC server to listen and dial the address
+// it is bound to, which differs from the configured address when it uses port 0
+func WithBackend(backend Backend) Option {
+	return func(s *Server) {
+		s.backend = backend
+	}
+}
+
+// WithDialOptions adds options used when dialing the gRPC server
+func WithDialOptions(options ...grpc.DialOption) Option {
+	return func(s *Server) {
+		s.dialOptions = append(s.dialOptions, options...)
+	}
+}
+
 // WithIncomingHeaderMatcher sets the incoming header matcher function
 func WithIncomingHeaderMatcher(matcher HeaderMatcherFunc) Option {
 	return func(s *Server) {
@@ -126,6 +206,39 @@ func WithSwagger(dir, basePath string) Option {
 	}
 }
 
+// WithSwaggerFS enables Swagger UI serving the swagger files in fsys, e.g. files
+// embedded at build time, instead of reading a directory at runtime
+func WithSwaggerFS(fsys fs.FS, basePath string) Option {
+	return func(s *Server) {
+		s.swaggerEnabled = true
+		s.swaggerFS = fsys
+		s.swaggerBasePath = basePath
+	}
+}
+
+// WithSwaggerCDN loads the Swagger UI assets from the CDN base URL instead of the
+// copies embedded in the binary
+func WithSwaggerCDN(url string) Option {
+	return func(s *Server) {
+		s.swaggerCDN = url
+	}
+}
+
+// WithSwaggerAuthToken requires Swagger requests to carry an "Authorization: Bearer <token>" header
+func WithSwaggerAuthToken(token string) Option {
+	return func(s *Server) {
+		s.swaggerToken = token
+	}
+}
+
+// WithSwaggerBasicAuth requires Swagger requests to carry the given HTTP basic auth credentials
+func WithSwaggerBasicAuth(user, password string) Option {
+	return func(s *Server) {
+		s.swaggerUser = user
+		s.swaggerPassword = password
+	}
+}
+
 // WithJSONConfig sets the JSON configuration for the gateway
 func WithJSONConfig(config *JSONConfig) Option {
 	return func(s *Server) {
@@ -133,15 +246,170 @@ func WithJSONConfig(config *JSONConfig) Option {
 	}
 }
 
+// WithGRPCHandler serves gRPC requests on the HTTP port using the given handler,
+// accepting unencrypted HTTP/2 (h2c) connections
+func WithGRPCHandler(handler http.Handler) Option {
+	return func(s *Server) {
+		s.grpcHandler = handler
+	}
+}
+
+// WithHandler mounts an additional handler on the HTTP mux
+func WithHandler(pattern string, handler http.Handler) Option {
+	return func(s *Server) {
+		if s.handlers == nil {
+			s.handlers = make(map[string]http.Handler)
+		}
+		s.handlers[pattern] = handler
+	}
+}
+
+// WithHealthPaths sets the paths of the health endpoint, /health by default; no paths
+// disable it
+func WithHealthPaths(paths ...string) Option {
+	return func(s *Server) {
+		s.healthPaths = paths
+	}
+}
+
+// WithNotFoundHandler sets the error of requests matching no route, instead of NotFound
+func WithNotFoundHandler(fn RoutingErrorFunc) Option {
+	return func(s *Server) {
+		s.notFound = fn
+	}
+}
+
+// WithMethodNotAllowedHandler sets the error of requests whose path matches a route but
+// not its method, instead of Unimplemented
+func WithMethodNotAllowedHandler(fn RoutingErrorFunc) Option {
+	return func(s *Server) {
+		s.methodNotAllowed = fn
+	}
+}
+
+// WithUnescapingMode sets how path parameters are unescaped: UnescapingLegacy,
+// UnescapingAllExceptReserved, UnescapingAllExceptSlash or UnescapingAllCharacters
+func WithUnescapingMode(mode string) Option {
+	return func(s *Server) {
+		s.unescapingMode = mode
+	}
+}
+
+// WithPathLengthFallback enables or disables serving form-encoded POST requests as the
+// GET route of their path, or the method of their X-HTTP-Method-Override header, with
+// the form fields parsed as query parameters; enabled by default
+func WithPathLengthFallback(enabled bool) Option {
+	return func(s *Server) {
+		s.pathLengthFallback = enabled
+	}
+}
+
+// WithOutgoingHeaders sets how the metadata of gRPC responses is forwarded as HTTP
+// headers: OutgoingHeadersPrefixed, OutgoingHeadersStripped or OutgoingHeadersNone. With
+// keys, only the listed metadata keys are forwarded, "*" suffixes matching prefixes. A
+// matcher set with WithOutgoingHeaderMatcher takes precedence.
+func WithOutgoingHeaders(mode string, keys ...string) Option {
+	return func(s *Server) {
+		s.outgoingHeaders = mode
+		s.outgoingHeaderKeys = keys
+	}
+}
+
+// WithTimeoutHeaders sets the request headers holding the timeout of the gRPC call, such
+// as X-Request-Timeout, written as a Go duration ("1.5s") or seconds ("2"); the first
+// header a request has applies. Grpc-Timeout headers are always applied.
+func WithTimeoutHeaders(headers ...string) Option {
+	return func(s *Server) {
+		s.timeoutHeaders = headers
+	}
+}
+
+// WithStreamFraming sets how the messages of server-streaming methods are framed:
+// StreamFramingDefault, StreamFramingNDJSON, StreamFramingJSONArray or
+// StreamFramingLengthPrefixed
+func WithStreamFraming(framing string) Option {
+	return func(s *Server) {
+		s.streamFraming = framing
+	}
+}
+
+// WithStreamFlushInterval flushes the messages of server-streaming methods at most once
+// per interval instead of after every message, so small messages share packets
+func WithStreamFlushInterval(interval time.Duration) Option {
+	return func(s *Server) {
+		s.streamFlushInterval = interval
+	}
+}
+
+// WithMiddleware wraps the HTTP handler with middleware, inside CORS; the first
+// middleware is the outermost
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(s *Server) {
+		s.middleware = append(s.middleware, middleware...)
+	}
+}
+
+// WithVirtualHost serves requests whose Host matches one of hosts with the handler of
+// another gateway, which is not run on its own. Hosts are matched without the port.
+func WithVirtualHost(server *Server, hosts ...string) Option {
+	return func(s *Server) {
+		s.virtualHosts = append(s.virtualHosts, virtualHost{hosts: hosts, server: server})
+	}
+}
+
+// WithConnState calls fn when a client connection changes state, see http.Server.ConnState
+func WithConnState(fn func(net.Conn, http.ConnState)) Option {
+	return func(s *Server) {
+		s.server.ConnState = fn
+	}
+}
+
+// WithListenOptions sets the socket options of the listener, SO_REUSEPORT included
+func WithListenOptions(opts listener.Options) Option {
+	return func(s *Server) {
+		s.listen = opts
+	}
+}
+
+// WithListener serves on lis instead of listening on the HTTP address, e.g. an
+// in-memory listener
+func WithListener(lis net.Listener) Option {
+	return func(s *Server) {
+		s.listener = lis
+	}
+}
+
+// WithServeFunc serves the composed handler with serve instead of listening on the HTTP
+// address, e.g. to receive requests from a serverless runtime
+func WithServeFunc(serve ServeFunc) Option {
+	return func(s *Server) {
+		s.serve = serve
+	}
+}
+
 // PreRun prepares the gateway server
-func (*Server) PreRun(_ context.Context) error {
+func (s *Server) PreRun(ctx context.Context) error {
+	if _, err := parseUnescapingMode(s.unescapingMode); err != nil {
+		return err
+	}
+	if err := parseStreamFraming(s.streamFraming); err != nil {
+		return err
+	}
+	if err := parseOutgoingHeaders(s.outgoingHeaders); err != nil {
+		return err
+	}
+	for _, vh := range s.virtualHosts {
+		if err := vh.server.PreRun(ctx); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-// Run starts the gRPC-Gateway server
-func (s *Server) Run(ctx context.Context) error {
+// buildHandler registers the services with a new gateway mux and composes the HTTP handler
+func (s *Server) buildHandler(ctx context.Context) (http.Handler, error) {
 	// Create JSON marshaling options
-	jsonOpts := runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+	var marshaler runtime.Marshaler = &runtime.JSONPb{
 		MarshalOptions: protojson.MarshalOptions{
 			UseProtoNames:   s.jsonConfig.UseProtoNames,
 			EmitUnpopulated: s.jsonConfig.EmitUnpopulated,
@@ -150,10 +418,43 @@ func (s *Server) Run(ctx context.Context) error {
 			Multiline:       s.jsonConfig.Multiline,
 			Indent:          s.jsonConfig.Indent,
 		},
-	})
+		UnmarshalOptions: protojson.UnmarshalOptions{
+			AllowPartial:   s.jsonConfig.AllowPartial,
+			DiscardUnknown: s.jsonConfig.DiscardUnknown,
+		},
+	}
+
+	// Frame the messages of server-streaming methods
+	var framer *streamFramer
+	if s.streamFraming != StreamFramingDefault || s.streamFlushInterval > 0 {
+		framer = newStreamFramer(s.streamFraming, s.streamFlushInterval, marshaler)
+		marshaler = framer.Marshaler(marshaler)
+	}
+	jsonOpts := runtime.WithMarshalerOption(runtime.MIMEWildcard, marshaler)
 
-	// Add JSON options to mux options
-	muxOptions := append([]runtime.ServeMuxOption{jsonOpts}, s.muxOptions...)
+	unescapingMode, err := parseUnescapingMode(s.unescapingMode)
+	if err != nil {
+		return nil, err
+	}
+
+	// Add JSON options, path parsing and header matchers to mux options; user mux options
+	// come last and take precedence
+	muxOptions := []runtime.ServeMuxOption{jsonOpts, runtime.WithUnescapingMode(unescapingMode)}
+	if !s.pathLengthFallback {
+		muxOptions = append(muxOptions, runtime.WithDisablePathLengthFallback())
+	}
+	if s.incomingHeaderMatcher != nil {
+		muxOptions = append(muxOptions, runtime.WithIncomingHeaderMatcher(s.incomingHeaderMatcher))
+	}
+	if s.outgoingHeaderMatcher != nil {
+		muxOptions = append(muxOptions, runtime.WithOutgoingHeaderMatcher(s.outgoingHeaderMatcher))
+	} else if s.outgoingHeaders != OutgoingHeadersPrefixed || len(s.outgoingHeaderKeys) > 0 {
+		muxOptions = append(muxOptions, runtime.WithOutgoingHeaderMatcher(outgoingHeaderMatcher(s.outgoingHeaders, s.outgoingHeaderKeys)))
+	}
+	if s.notFound != nil || s.methodNotAllowed != nil {
+		muxOptions = append(muxOptions, runtime.WithRoutingErrorHandler(s.routingError))
+	}
+	muxOptions = append(muxOptions, s.muxOptions...)
 
 	// Create gRPC-Gateway mux
 	gwmux := runtime.NewServeMux(muxOptions...)
@@ -162,51 +463,198 @@ func (s *Server) Run(ctx context.Context) error {
 	opts := []grpc.DialOption{
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
 	}
+	opts = append(opts, s.dialOptions...)
 
 	// Register all service handlers
 	for _, registrar := range s.registrars {
-		if err := registrar.RegisterHTTP(ctx, gwmux, s.grpcAddress, opts); err != nil {
-			return fmt.Errorf("failed to register gateway: %w", err)
+		if err := registrar.RegisterHTTP(ctx, gwmux, s.backendAddress(), opts); err != nil {
+			return nil, fmt.Errorf("failed to register gateway: %w", err)
 		}
 	}
 
 	// Create root HTTP mux
 	mux := http.NewServeMux()
-	mux.Handle("/", gwmux)
+	var gwHandler http.Handler = gwmux
+	if framer != nil {
+		gwHandler = framer.Middleware(gwHandler)
+	}
+	if len(s.timeoutHeaders) > 0 {
+		gwHandler = timeoutMiddleware(s.timeoutHeaders, gwHandler)
+	}
+	mux.Handle("/", gwHandler)
 
 	// Add health check endpoints
-	mux.HandleFunc("/health", func(w http.ResponseWriter, _ *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte("OK"))
-	})
+	for _, path := range s.healthPaths {
+		mux.HandleFunc(path, s.health)
+	}
 
 	// Add Swagger UI if configured
 	if s.swaggerEnabled {
 		s.registerSwaggerHandler(mux)
 	}
 
-	// Apply CORS if enabled
+	// Mount additional handlers
+	for pattern, handler := range s.handlers {
+		mux.Handle(pattern, handler)
+	}
+
+	// Apply middleware, the first one outermost
 	var handler http.Handler = mux
+	for i := len(s.middleware) - 1; i >= 0; i-- {
+		handler = s.middleware[i](handler)
+	}
+
+	// Apply CORS if enabled
 	if s.corsEnabled {
-		handler = cors.New(s.corsOptions).Handler(mux)
+		handler = cors.New(s.corsOptions).Handler(handler)
+	}
+
+	// Route requests for virtual hosts to their gateways
+	if len(s.virtualHosts) > 0 {
+		hosts := make(map[string]http.Handler)
+		for _, vh := range s.virtualHosts {
+			vhHandler, err := vh.server.buildHandler(ctx)
+			if err != nil {
+				return nil, err
+			}
+			for _, host := range vh.hosts {
+				hosts[strings.ToLower(host)] = vhHandler
+			}
+		}
+		handler = hostDispatch(hosts, handler)
+	}
+
+	// Route gRPC requests to the gRPC server
+	if s.grpcHandler != nil {
+		handler = grpcDispatch(s.grpcHandler, handler)
+	}
+
+	return handler, nil
+}
+
+// backendAddress returns the address of the gRPC server to dial
+func (s *Server) backendAddress() string {
+	if s.backend != nil {
+		return s.backend.Addr()
+	}
+	return s.grpcAddress
+}
+
+// Run starts the gRPC-Gateway server
+func (s *Server) Run(ctx context.Context) error {
+	// Wait for the gRPC server to be bound before dialing it
+	if s.backend != nil {
+		select {
+		case <-s.backend.Ready():
+		case <-ctx.Done():
+			return nil
+		}
+	}
+
+	handler, err := s.buildHandler(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Accept HTTP/2 without TLS for gRPC requests
+	if s.grpcHandler != nil {
+		s.server.Protocols = new(http.Protocols)
+		s.server.Protocols.SetHTTP1(true)
+		s.server.Protocols.SetUnencryptedHTTP2(true)
 	}
 
 	// Set the handler
 	s.server.Handler = handler
 
+	// Hand the handler to the custom serve function, stopped on Shutdown
+	if s.serve != nil {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		s.mu.Lock()
+		s.stopServe = cancel
+		s.mu.Unlock()
+
+		s.bound.Set(nil)
+		s.logger.Info("starting gRPC-Gateway handler")
+		if err := s.serve(ctx, handler); err != nil {
+			return fmt.Errorf("gateway serve error: %w", err)
+		}
+		return nil
+	}
+
 	// Start the HTTP server
-	s.logger.Info("starting gRPC-Gateway server", "address", s.server.Addr)
-	if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	lis := s.listener
+	if lis == nil {
+		if lis, err = listener.Listen(ctx, s.server.Addr, s.listen); err != nil {
+			return fmt.Errorf("failed to listen: %w", err)
+		}
+	}
+	s.bound.Set(lis)
+	s.logger.Info("starting gRPC-Gateway server", "address", s.Addr())
+	if err := s.server.Serve(lis); err != nil && err != http.ErrServerClosed {
 		return fmt.Errorf("gateway server error: %w", err)
 	}
 
 	return nil
 }
 
+// Addr returns the address the server is listening on, which differs from the configured
+// address when it uses port 0
+func (s *Server) Addr() string {
+	return s.bound.Addr(s.server.Addr)
+}
+
+// Ready returns a channel that is closed once the server is listening or serving
+// with its serve function
+func (s *Server) Ready() <-chan struct{} {
+	return s.bound.Ready()
+}
+
+// routingError writes the error of the routing error handlers, falling back to the
+// grpc-gateway defaults
+func (s *Server) routingError(ctx context.Context, mux *runtime.ServeMux, marshaler runtime.Marshaler, w http.ResponseWriter, r *http.Request, httpStatus int) {
+	switch {
+	case httpStatus == http.StatusNotFound && s.notFound != nil:
+		runtime.HTTPError(ctx, mux, marshaler, w, r, s.notFound(r))
+	case httpStatus == http.StatusMethodNotAllowed && s.methodNotAllowed != nil:
+		runtime.HTTPError(ctx, mux, marshaler, w, r, s.methodNotAllowed(r))
+	default:
+		runtime.DefaultRoutingErrorHandler(ctx, mux, marshaler, w, r, httpStatus)
+	}
+}
+
+// health reports OK, or NOT_SERVING once the server is draining
+func (s *Server) health(w http.ResponseWriter, _ *http.Request) {
+	if s.draining.Load() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte("NOT_SERVING"))
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("OK"))
+}
+
+// Drain makes the health endpoint report NOT_SERVING and disables keep-alives,
+// so clients reconnect elsewhere instead of reusing connections to this instance
+func (s *Server) Drain() {
+	s.logger.Info("gateway health set to NOT_SERVING")
+	s.draining.Store(true)
+	s.server.SetKeepAlivesEnabled(false)
+	for _, vh := range s.virtualHosts {
+		vh.server.Drain()
+	}
+}
+
 // Shutdown gracefully stops the gRPC-Gateway server
 func (s *Server) Shutdown(ctx context.Context) error {
 	s.logger.Info("shutting down gRPC-Gateway server")
 
+	s.mu.Lock()
+	if s.stopServe != nil {
+		s.stopServe()
+	}
+	s.mu.Unlock()
+
 	shutdownCtx, cancel := context.WithTimeout(ctx, s.closeTimeout)
 	defer cancel()
 
@@ -217,33 +665,117 @@ func (s *Server) Shutdown(ctx context.Context) error {
 	return nil
 }
 
-// registerSwaggerHandler registers the Swagger UI handler
+// grpcDispatch routes HTTP/2 gRPC requests to grpcHandler and all other requests to next
+func grpcDispatch(grpcHandler, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+			grpcHandler.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// hostDispatch routes requests to the handler registered for their Host, ignoring the
+// port, and all other requests to next
+func hostDispatch(hosts map[string]http.Handler, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if handler, ok := hosts[strings.ToLower(host)]; ok {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// swaggerSpec is a swagger file listed in the Swagger UI spec selector
+type swaggerSpec struct {
+	// name is the path of the file without the .swagger.json suffix
+	name string
+	// file is the path of the swagger file in the swagger file system
+	file string
+}
+
+// swaggerURL is an entry of the Swagger UI urls array
+type swaggerURL struct {
+	URL  string `json:"url"`
+	Name string `json:"name"`
+}
+
+// findSwaggerSpecs returns the *.swagger.json files in fsys in lexical order
+func findSwaggerSpecs(fsys fs.FS) ([]swaggerSpec, error) {
+	var specs []swaggerSpec
+	err := fs.WalkDir(fsys, ".", func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".swagger.json") {
+			return nil
+		}
+		specs = append(specs, swaggerSpec{
+			name: strings.TrimSuffix(file, ".swagger.json"),
+			file: file,
+		})
+		return nil
+	})
+	return specs, err
+}
+
+// registerSwaggerHandler registers the Swagger UI handler, serving every swagger file
+// under /swagger/specs/ with a selector when there is more than one
 func (s *Server) registerSwaggerHandler(mux *http.ServeMux) {
-	// Check if swagger directory exists
-	if _, err := os.Stat(s.swaggerDir); os.IsNotExist(err) {
+	// Read the swagger directory unless the files are embedded
+	fsys := s.swaggerFS
+	if fsys == nil {
+		fsys = os.DirFS(s.swaggerDir)
+	}
+
+	specs, err := findSwaggerSpecs(fsys)
+	if errors.Is(err, fs.ErrNotExist) {
 		s.logger.Warn("swagger directory does not exist", "dir", s.swaggerDir)
 		return
 	}
-
-	// Find first swagger file
-	entries, err := os.ReadDir(s.swaggerDir)
 	if err != nil {
 		s.logger.Warn("failed to read swagger directory", "error", err)
 		return
 	}
 
-	// Find first swagger file and serve it as doc.json
-	for _, entry := range entries {
-		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".swagger.json") {
-			swaggerFile := filepath.Join(s.swaggerDir, entry.Name())
-			mux.HandleFunc("/swagger/doc.json", func(w http.ResponseWriter, r *http.Request) {
-				http.ServeFile(w, r, swaggerFile)
-			})
-			break
+	// Serve each spec by name, and the first one as doc.json for single-spec clients
+	files := make(map[string]string, len(specs))
+	urls := make([]swaggerURL, 0, len(specs))
+	for _, spec := range specs {
+		files[spec.name+".swagger.json"] = spec.file
+		urls = append(urls, swaggerURL{URL: "specs/" + spec.name + ".swagger.json", Name: spec.name})
+	}
+	mux.Handle("/swagger/specs/", s.swaggerAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file, ok := files[strings.TrimPrefix(r.URL.Path, "/swagger/specs/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
 		}
+		http.ServeFileFS(w, r, fsys, file)
+	})))
+	if len(specs) > 0 {
+		mux.Handle("/swagger/doc.json", s.swaggerAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFileFS(w, r, fsys, specs[0].file)
+		})))
 	}
 
 	// Configure swagger options
+	uiConfig := make(map[string]string)
+	if len(urls) > 1 {
+		urlsJSON, err := json.Marshal(urls)
+		if err != nil {
+			s.logger.Warn("failed to encode swagger specs", "error", err)
+			return
+		}
+		uiConfig["urls"] = string(urlsJSON)
+	}
+
 	swaggerOptions := []func(config *httpSwagger.Config){
 		httpSwagger.URL("doc.json"),
 	}
@@ -275,13 +807,32 @@ func (s *Server) registerSwaggerHandler(mux *http.ServeMux) {
 			  }
 			});`),
 			httpSwagger.Plugins([]string{"UrlMutatorPlugin"}),
-			httpSwagger.UIConfig(map[string]string{
-				"onComplete": fmt.Sprintf(`() => { window.ui.setBasePath('%s') }`, s.swaggerBasePath),
-			}),
 		)
+		uiConfig["onComplete"] = fmt.Sprintf(`() => { window.ui.setBasePath('%s') }`, s.swaggerBasePath)
 	}
+	if len(uiConfig) > 0 {
+		swaggerOptions = append(swaggerOptions, httpSwagger.UIConfig(uiConfig))
+	}
+
+	// Register swagger handler; the UI assets are embedded in the binary
+	var swaggerHandler http.Handler = httpSwagger.Handler(swaggerOptions...)
+	if s.swaggerCDN != "" {
+		swaggerHandler = swaggerCDNRedirect(s.swaggerCDN, swaggerHandler)
+	}
+	mux.Handle("/swagger/", s.swaggerAuth(swaggerHandler))
+	s.logger.Info("swagger UI enabled", "basePath", s.swaggerBasePath, "specs", len(specs), "cdn", s.swaggerCDN)
+}
 
-	// Register swagger handler
-	mux.Handle("/swagger/", httpSwagger.Handler(swaggerOptions...))
-	s.logger.Info("swagger UI enabled", "basePath", s.swaggerBasePath)
+// swaggerCDNRedirect redirects requests for Swagger UI assets to the same file under
+// the CDN base URL and serves the index page with next
+func swaggerCDNRedirect(cdn string, next http.Handler) http.Handler {
+	cdn = strings.TrimSuffix(cdn, "/")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch path.Ext(r.URL.Path) {
+		case ".js", ".css", ".png", ".map":
+			http.Redirect(w, r, cdn+"/"+path.Base(r.URL.Path), http.StatusFound)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
 }
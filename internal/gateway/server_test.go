@@ -2,10 +2,15 @@ package gateway
 
 import (
 	"context"
+	"encoding/json"
 	"log/slog"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
@@ -14,7 +19,12 @@ import (
 	"github.com/rs/cors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
 )
 
 // mockServiceRegistrar implements service.Registrar for testing
@@ -85,6 +95,18 @@ func TestWithMuxOptions(t *testing.T) {
 	assert.Len(t, srv.muxOptions, 2)
 }
 
+func TestWithDialOptions(t *testing.T) {
+	// Arrange
+	srv := &Server{}
+
+	// Act
+	WithDialOptions(grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(1 << 20)))(srv)
+	WithDialOptions(grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(1 << 20)))(srv)
+
+	// Assert
+	assert.Len(t, srv.dialOptions, 2)
+}
+
 func TestWithIncomingHeaderMatcher(t *testing.T) {
 	// Arrange
 	srv := &Server{}
@@ -150,6 +172,243 @@ func TestWithPprof(t *testing.T) {
 	assert.True(t, srv.pprofEnabled)
 }
 
+func TestWithHandler(t *testing.T) {
+	// Arrange
+	srv := &Server{}
+	handler := http.NotFoundHandler()
+
+	// Act
+	WithHandler("/internal/metrics", handler)(srv)
+	WithGRPCHandler(handler)(srv)
+
+	// Assert
+	assert.Contains(t, srv.handlers, "/internal/metrics")
+	assert.NotNil(t, srv.grpcHandler)
+}
+
+func TestServer_Drain(t *testing.T) {
+	// Arrange - capture the composed handler instead of listening
+	handlerCh := make(chan http.Handler, 1)
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	srv := NewServer(logger, time.Second, ":50051", ":8080", WithServeFunc(func(_ context.Context, h http.Handler) error {
+		handlerCh <- h
+		return nil
+	}))
+	require.NoError(t, srv.Run(context.Background()))
+	handler := <-handlerCh
+
+	health := func() int {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+		return rec.Code
+	}
+	require.Equal(t, http.StatusOK, health())
+
+	// Act
+	srv.Drain()
+
+	// Assert
+	assert.Equal(t, http.StatusServiceUnavailable, health())
+}
+
+func TestServer_HealthPaths(t *testing.T) {
+	tests := []struct {
+		name  string
+		paths []string
+		want  map[string]int
+	}{
+		{name: "default", want: map[string]int{"/health": http.StatusOK, "/healthz": http.StatusNotFound}},
+		{name: "custom", paths: []string{"/healthz", "/livez"}, want: map[string]int{"/health": http.StatusNotFound, "/healthz": http.StatusOK, "/livez": http.StatusOK}},
+		{name: "disabled", paths: []string{}, want: map[string]int{"/health": http.StatusNotFound}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handlerCh := make(chan http.Handler, 1)
+			opts := []Option{WithServeFunc(func(_ context.Context, h http.Handler) error {
+				handlerCh <- h
+				return nil
+			})}
+			if tt.paths != nil {
+				opts = append(opts, WithHealthPaths(tt.paths...))
+			}
+			srv := NewServer(slog.New(slog.DiscardHandler), time.Second, ":50051", ":8080", opts...)
+
+			// Act
+			require.NoError(t, srv.Run(context.Background()))
+			handler := <-handlerCh
+
+			// Assert
+			for path, want := range tt.want {
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+				assert.Equal(t, want, rec.Code, path)
+			}
+		})
+	}
+}
+
+func TestServer_RoutingErrorHandlers(t *testing.T) {
+	// Arrange - a GET route, so a POST to its path is not allowed
+	registrar := new(mockServiceRegistrar)
+	registrar.On("RegisterHTTP", mock.Anything, mock.Anything, ":50051", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		mux := args.Get(1).(*runtime.ServeMux)
+		require.NoError(t, mux.HandlePath(http.MethodGet, "/v1/orders", func(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+			w.WriteHeader(http.StatusOK)
+		}))
+	})
+	handlerCh := make(chan http.Handler, 1)
+	srv := NewServer(slog.New(slog.DiscardHandler), time.Second, ":50051", ":8080",
+		WithServices(registrar),
+		WithNotFoundHandler(func(r *http.Request) error {
+			return status.Errorf(codes.NotFound, "no route for %s", r.URL.Path)
+		}),
+		WithMethodNotAllowedHandler(func(r *http.Request) error {
+			return status.Errorf(codes.InvalidArgument, "%s is not supported", r.Method)
+		}),
+		WithServeFunc(func(_ context.Context, h http.Handler) error {
+			handlerCh <- h
+			return nil
+		}),
+	)
+	require.NoError(t, srv.Run(context.Background()))
+	handler := <-handlerCh
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "not found", method: http.MethodGet, path: "/v1/missing", wantStatus: http.StatusNotFound, wantBody: `{"code":5,"message":"no route for /v1/missing","details":[]}`},
+		{name: "method not allowed", method: http.MethodPost, path: "/v1/orders", wantStatus: http.StatusBadRequest, wantBody: `{"code":3,"message":"POST is not supported","details":[]}`},
+		{name: "route", method: http.MethodGet, path: "/v1/orders", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+
+			// Act
+			handler.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestGRPCDispatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		protoMajor  int
+		contentType string
+		wantGRPC    bool
+	}{
+		{name: "gRPC over HTTP/2", protoMajor: 2, contentType: "application/grpc", wantGRPC: true},
+		{name: "gRPC with subtype", protoMajor: 2, contentType: "application/grpc+proto", wantGRPC: true},
+		{name: "JSON over HTTP/2", protoMajor: 2, contentType: "application/json", wantGRPC: false},
+		{name: "gRPC content type over HTTP/1.1", protoMajor: 1, contentType: "application/grpc", wantGRPC: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var servedGRPC bool
+			grpcHandler := http.HandlerFunc(func(http.ResponseWriter, *http.Request) { servedGRPC = true })
+			handler := grpcDispatch(grpcHandler, http.NotFoundHandler())
+
+			req := httptest.NewRequest(http.MethodPost, "/pkg.Service/Method", nil)
+			req.ProtoMajor = tt.protoMajor
+			req.Header.Set("Content-Type", tt.contentType)
+
+			// Act
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			// Assert
+			assert.Equal(t, tt.wantGRPC, servedGRPC)
+		})
+	}
+}
+
+func TestHostDispatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		host     string
+		wantHost bool
+	}{
+		{name: "matching host", host: "partner.example.com", wantHost: true},
+		{name: "matching host with port", host: "partner.example.com:8080", wantHost: true},
+		{name: "host is case-insensitive", host: "Partner.Example.com", wantHost: true},
+		{name: "other host", host: "api.example.com", wantHost: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var servedHost bool
+			hostHandler := http.HandlerFunc(func(http.ResponseWriter, *http.Request) { servedHost = true })
+			handler := hostDispatch(map[string]http.Handler{"partner.example.com": hostHandler}, http.NotFoundHandler())
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/orders", nil)
+			req.Host = tt.host
+
+			// Act
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			// Assert
+			assert.Equal(t, tt.wantHost, servedHost)
+		})
+	}
+}
+
+func TestServer_VirtualHost(t *testing.T) {
+	// Arrange - each gateway registers only its own services and applies its own middleware
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	public := new(mockServiceRegistrar)
+	public.On("RegisterHTTP", mock.Anything, mock.Anything, ":50051", mock.Anything).Return(nil).Once()
+	partner := new(mockServiceRegistrar)
+	partner.On("RegisterHTTP", mock.Anything, mock.Anything, ":50051", mock.Anything).Return(nil).Once()
+
+	tag := func(value string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("X-Gateway", value)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+	partnerGateway := NewServer(logger, time.Second, ":50051", "", WithServices(partner), WithMiddleware(tag("partner")))
+	srv := NewServer(logger, time.Second, ":50051", ":8080",
+		WithServices(public),
+		WithMiddleware(tag("public"), tag("inner")),
+		WithVirtualHost(partnerGateway, "partner.example.com"),
+	)
+
+	// Act
+	handler, err := srv.buildHandler(context.Background())
+	require.NoError(t, err)
+
+	serve := func(host string) []string {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		req.Host = host
+		handler.ServeHTTP(rec, req)
+		return rec.Header().Values("X-Gateway")
+	}
+
+	// Assert
+	assert.Equal(t, []string{"public", "inner"}, serve("api.example.com"), "first middleware should be outermost")
+	assert.Equal(t, []string{"partner"}, serve("partner.example.com"))
+	public.AssertExpectations(t)
+	partner.AssertExpectations(t)
+}
+
 func TestWithSwagger(t *testing.T) {
 	// Arrange
 	srv := &Server{}
@@ -166,6 +425,150 @@ func TestWithSwagger(t *testing.T) {
 	assert.Equal(t, basePath, srv.swaggerBasePath)
 }
 
+func TestServer_SwaggerSpecs(t *testing.T) {
+	// Arrange - one spec at the top level and one in a subdirectory
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.swagger.json"), []byte(`{"swagger":"a"}`), 0o600))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "b", "v1"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b", "v1", "b.swagger.json"), []byte(`{"swagger":"b"}`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("docs"), 0o600))
+
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	srv := NewServer(logger, time.Second, ":50051", ":8080", WithSwagger(dir, ""))
+
+	// Act
+	handler, err := srv.buildHandler(context.Background())
+	require.NoError(t, err)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	// Assert
+	assert.JSONEq(t, `{"swagger":"a"}`, get("/swagger/specs/a.swagger.json").Body.String())
+	assert.JSONEq(t, `{"swagger":"b"}`, get("/swagger/specs/b/v1/b.swagger.json").Body.String())
+	assert.JSONEq(t, `{"swagger":"a"}`, get("/swagger/doc.json").Body.String(), "doc.json should serve the first spec")
+	assert.Equal(t, http.StatusNotFound, get("/swagger/specs/README.md").Code)
+
+	index := get("/swagger/index.html").Body.String()
+	assert.Contains(t, index, `urls: [{"url":"specs/a.swagger.json","name":"a"},{"url":"specs/b/v1/b.swagger.json","name":"b/v1/b"}]`)
+}
+
+func TestServer_SwaggerFS(t *testing.T) {
+	// Arrange
+	fsys := fstest.MapFS{
+		"v1/a.swagger.json": &fstest.MapFile{Data: []byte(`{"swagger":"a"}`)},
+	}
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	srv := NewServer(logger, time.Second, ":50051", ":8080", WithSwaggerFS(fsys, "/api"))
+
+	// Act
+	handler, err := srv.buildHandler(context.Background())
+	require.NoError(t, err)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/swagger/specs/v1/a.swagger.json", nil))
+
+	// Assert
+	assert.True(t, srv.swaggerEnabled)
+	assert.Equal(t, "/api", srv.swaggerBasePath)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"swagger":"a"}`, rec.Body.String())
+}
+
+func TestServer_SwaggerAssets(t *testing.T) {
+	tests := []struct {
+		name         string
+		cdn          string
+		wantCode     int
+		wantLocation string
+	}{
+		{name: "embedded", wantCode: http.StatusOK},
+		{name: "CDN", cdn: "https://unpkg.com/swagger-ui-dist@5/", wantCode: http.StatusFound, wantLocation: "https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+			srv := NewServer(logger, time.Second, ":50051", ":8080", WithSwagger(t.TempDir(), ""), WithSwaggerCDN(tt.cdn))
+			handler, err := srv.buildHandler(context.Background())
+			require.NoError(t, err)
+
+			// Act
+			asset := httptest.NewRecorder()
+			handler.ServeHTTP(asset, httptest.NewRequest(http.MethodGet, "/swagger/swagger-ui-bundle.js", nil))
+			index := httptest.NewRecorder()
+			handler.ServeHTTP(index, httptest.NewRequest(http.MethodGet, "/swagger/index.html", nil))
+
+			// Assert
+			assert.Equal(t, tt.wantCode, asset.Code)
+			assert.Equal(t, tt.wantLocation, asset.Header().Get("Location"))
+			assert.Equal(t, http.StatusOK, index.Code, "index page should always be served locally")
+		})
+	}
+}
+
+func TestServer_SwaggerAuth(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		setAuth  func(r *http.Request)
+		wantCode int
+	}{
+		{name: "no auth configured", wantCode: http.StatusOK},
+		{name: "missing credentials", opts: []Option{WithSwaggerAuthToken("secret")}, wantCode: http.StatusUnauthorized},
+		{
+			name:     "valid bearer token",
+			opts:     []Option{WithSwaggerAuthToken("secret")},
+			setAuth:  func(r *http.Request) { r.Header.Set("Authorization", "Bearer secret") },
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "invalid bearer token",
+			opts:     []Option{WithSwaggerAuthToken("secret")},
+			setAuth:  func(r *http.Request) { r.Header.Set("Authorization", "Bearer wrong") },
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "valid basic auth",
+			opts:     []Option{WithSwaggerBasicAuth("admin", "pass")},
+			setAuth:  func(r *http.Request) { r.SetBasicAuth("admin", "pass") },
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "invalid basic auth",
+			opts:     []Option{WithSwaggerBasicAuth("admin", "pass")},
+			setAuth:  func(r *http.Request) { r.SetBasicAuth("admin", "wrong") },
+			wantCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "a.swagger.json"), []byte(`{}`), 0o600))
+			logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+			srv := NewServer(logger, time.Second, ":50051", ":8080", append(tt.opts, WithSwagger(dir, ""))...)
+			handler, err := srv.buildHandler(context.Background())
+			require.NoError(t, err)
+
+			// Act & Assert - the UI and the specs are protected alike
+			for _, path := range []string{"/swagger/index.html", "/swagger/doc.json", "/swagger/specs/a.swagger.json"} {
+				req := httptest.NewRequest(http.MethodGet, path, nil)
+				if tt.setAuth != nil {
+					tt.setAuth(req)
+				}
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, req)
+				assert.Equal(t, tt.wantCode, rec.Code, path)
+			}
+		})
+	}
+}
+
 func TestWithJSONConfig(t *testing.T) {
 	// Arrange
 	srv := &Server{}
@@ -186,6 +589,55 @@ func TestWithJSONConfig(t *testing.T) {
 	assert.Equal(t, config, srv.jsonConfig)
 }
 
+func TestServer_JSONDiscardUnknown(t *testing.T) {
+	tests := []struct {
+		name           string
+		discardUnknown bool
+		wantStatus     int
+	}{
+		{name: "unknown fields rejected", wantStatus: http.StatusBadRequest},
+		{name: "unknown fields discarded", discardUnknown: true, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange - a route decoding its body with the gateway marshaler
+			registrar := new(mockServiceRegistrar)
+			registrar.On("RegisterHTTP", mock.Anything, mock.Anything, ":50051", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+				mux := args.Get(1).(*runtime.ServeMux)
+				require.NoError(t, mux.HandlePath(http.MethodPost, "/v1/check", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+					inbound, _ := runtime.MarshalerForRequest(mux, r)
+					if err := inbound.NewDecoder(r.Body).Decode(&grpc_health_v1.HealthCheckRequest{}); err != nil {
+						w.WriteHeader(http.StatusBadRequest)
+						return
+					}
+					w.WriteHeader(http.StatusOK)
+				}))
+			})
+			jsonConfig := DefaultJSONConfig()
+			jsonConfig.DiscardUnknown = tt.discardUnknown
+			handlerCh := make(chan http.Handler, 1)
+			srv := NewServer(slog.New(slog.DiscardHandler), time.Second, ":50051", ":8080",
+				WithServices(registrar),
+				WithJSONConfig(jsonConfig),
+				WithServeFunc(func(_ context.Context, h http.Handler) error {
+					handlerCh <- h
+					return nil
+				}),
+			)
+			require.NoError(t, srv.Run(context.Background()))
+			handler := <-handlerCh
+			rec := httptest.NewRecorder()
+
+			// Act
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/check", strings.NewReader(`{"service":"orders","client_version":"2.1"}`)))
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
+
 func TestServer_PreRun(t *testing.T) {
 	// Arrange
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
@@ -232,6 +684,41 @@ func TestServer_Run_RegisterHTTPError(t *testing.T) {
 	registrar.AssertExpectations(t)
 }
 
+// staticBackend is a gRPC server bound to a fixed address
+type staticBackend struct {
+	addr  string
+	ready chan struct{}
+}
+
+func (b *staticBackend) Addr() string           { return b.addr }
+func (b *staticBackend) Ready() <-chan struct{} { return b.ready }
+
+func TestServer_Run_Backend(t *testing.T) {
+	// Arrange - the gateway is configured with port 0 but dials the bound port
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	backend := &staticBackend{addr: "127.0.0.1:54321", ready: make(chan struct{})}
+	registrar := new(mockServiceRegistrar)
+	registrar.On("RegisterHTTP", mock.Anything, mock.Anything, backend.addr, mock.Anything).Return(assert.AnError)
+	srv := NewServer(logger, time.Second, "127.0.0.1:0", ":0", WithServices(registrar), WithBackend(backend))
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- srv.Run(context.Background()) }()
+
+	// Assert - the gateway waits for the backend to be ready
+	select {
+	case err := <-errCh:
+		t.Fatalf("gateway ran before the backend was ready: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Act
+	close(backend.ready)
+
+	// Assert
+	assert.ErrorIs(t, <-errCh, assert.AnError)
+	registrar.AssertExpectations(t)
+}
+
 func TestServer_Shutdown(t *testing.T) {
 	// Arrange
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
@@ -252,3 +739,53 @@ func TestServer_Shutdown(t *testing.T) {
 	// Assert
 	assert.NoError(t, err)
 }
+
+func TestServer_ErrorDetails(t *testing.T) {
+	// Arrange - a route failing with a BadRequest detail, as the validation interceptor does
+	registrar := new(mockServiceRegistrar)
+	registrar.On("RegisterHTTP", mock.Anything, mock.Anything, ":50051", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		mux := args.Get(1).(*runtime.ServeMux)
+		require.NoError(t, mux.HandlePath(http.MethodPost, "/v1/orders", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+			st, err := status.New(codes.InvalidArgument, "invalid order").WithDetails(&errdetails.BadRequest{
+				FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "customer_id", Description: "must not be empty"}},
+			})
+			require.NoError(t, err)
+			_, outbound := runtime.MarshalerForRequest(mux, r)
+			runtime.HTTPError(r.Context(), mux, outbound, w, r, st.Err())
+		}))
+	})
+	handlerCh := make(chan http.Handler, 1)
+	srv := NewServer(slog.New(slog.DiscardHandler), time.Second, ":50051", ":8080",
+		WithServices(registrar),
+		WithServeFunc(func(_ context.Context, h http.Handler) error {
+			handlerCh <- h
+			return nil
+		}),
+	)
+	require.NoError(t, srv.Run(context.Background()))
+	handler := <-handlerCh
+	rec := httptest.NewRecorder()
+
+	// Act
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/orders", strings.NewReader(`{}`)))
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	var body struct {
+		Code    int `json:"code"`
+		Details []struct {
+			Type            string `json:"@type"`
+			FieldViolations []struct {
+				Field       string `json:"field"`
+				Description string `json:"description"`
+			} `json:"field_violations"`
+		} `json:"details"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, int(codes.InvalidArgument), body.Code)
+	require.Len(t, body.Details, 1)
+	assert.Equal(t, "type.googleapis.com/google.rpc.BadRequest", body.Details[0].Type)
+	require.Len(t, body.Details[0].FieldViolations, 1)
+	assert.Equal(t, "customer_id", body.Details[0].FieldViolations[0].Field)
+	assert.Equal(t, "must not be empty", body.Details[0].FieldViolations[0].Description)
+}
@@ -0,0 +1,256 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/protobuf/proto"
+)
+
+// Stream framings accepted by WithStreamFraming
+const (
+	// StreamFramingDefault writes the grpc-gateway format: {"result": ...} objects
+	// separated by newlines
+	StreamFramingDefault = "default"
+	// StreamFramingNDJSON writes each message on its own line, as application/x-ndjson
+	StreamFramingNDJSON = "ndjson"
+	// StreamFramingJSONArray writes the messages as the elements of one JSON array, so
+	// clients can parse the whole response once it ends
+	StreamFramingJSONArray = "json-array"
+	// StreamFramingLengthPrefixed writes each message after its length as a 4-byte
+	// big-endian integer
+	StreamFramingLengthPrefixed = "length-prefixed"
+)
+
+// streamContentType marks the responses of server-streaming methods, so the framing
+// writer tells them from unary responses; it is replaced before the header is sent
+const streamContentType = "application/x-netgex-stream"
+
+// parseStreamFraming validates the name of a stream framing
+func parseStreamFraming(framing string) error {
+	switch framing {
+	case StreamFramingDefault, StreamFramingNDJSON, StreamFramingJSONArray, StreamFramingLengthPrefixed:
+		return nil
+	}
+	return fmt.Errorf("invalid stream framing %q: must be %q, %q, %q or %q",
+		framing, StreamFramingDefault, StreamFramingNDJSON, StreamFramingJSONArray, StreamFramingLengthPrefixed)
+}
+
+// streamFramer frames the responses of server-streaming methods and controls how often
+// they are flushed. Its marshaler marks streams and leaves the framing of messages to
+// its writer, which sees every message as one write.
+type streamFramer struct {
+	framing       string
+	flushInterval time.Duration
+	contentType   string
+}
+
+// newStreamFramer creates the framer of the streams marshaled by m
+func newStreamFramer(framing string, flushInterval time.Duration, m runtime.Marshaler) *streamFramer {
+	contentType := m.ContentType(nil)
+	if framing == StreamFramingNDJSON {
+		contentType = "application/x-ndjson"
+	}
+	return &streamFramer{framing: framing, flushInterval: flushInterval, contentType: contentType}
+}
+
+// Marshaler wraps the marshaler of the gateway
+func (f *streamFramer) Marshaler(m runtime.Marshaler) runtime.Marshaler {
+	return &framedMarshaler{Marshaler: m, framing: f.framing}
+}
+
+// Middleware frames the streams written by next
+func (f *streamFramer) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fw := &framedWriter{ResponseWriter: w, framer: f}
+		next.ServeHTTP(fw, r)
+		fw.finish()
+	})
+}
+
+// framedMarshaler marshals stream messages compactly and without the {"result": ...}
+// envelope of grpc-gateway unless the framing is the default one
+type framedMarshaler struct {
+	runtime.Marshaler
+	framing string
+}
+
+func (m *framedMarshaler) Marshal(v any) ([]byte, error) {
+	if m.framing == StreamFramingDefault {
+		return m.Marshaler.Marshal(v)
+	}
+
+	chunk := false
+	switch c := v.(type) {
+	case map[string]any:
+		if result, ok := c["result"]; ok && len(c) == 1 {
+			v, chunk = result, true
+		}
+	case map[string]proto.Message:
+		_, chunk = c["error"]
+	}
+	data, err := m.Marshaler.Marshal(v)
+	if err != nil || !chunk {
+		return data, err
+	}
+
+	// Keep each message on one line, whatever the indentation of the marshaler
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, data); err != nil {
+		return data, nil
+	}
+	return compact.Bytes(), nil
+}
+
+// Delimiter separates stream messages in the default framing; the writer frames them
+// otherwise
+func (m *framedMarshaler) Delimiter() []byte {
+	if m.framing != StreamFramingDefault {
+		return nil
+	}
+	if d, ok := m.Marshaler.(runtime.Delimited); ok {
+		return d.Delimiter()
+	}
+	return []byte("\n")
+}
+
+// StreamContentType marks message streams, leaving google.api.HttpBody streams alone
+func (m *framedMarshaler) StreamContentType(v any) string {
+	if _, ok := v.(*httpbody.HttpBody); ok {
+		if sct, ok := m.Marshaler.(runtime.StreamContentType); ok {
+			return sct.StreamContentType(v)
+		}
+		return m.Marshaler.ContentType(v)
+	}
+	return streamContentType
+}
+
+// framedWriter frames the messages of a stream, each written at once, and flushes them
+// at most once per flush interval
+type framedWriter struct {
+	http.ResponseWriter
+	framer *streamFramer
+
+	mu        sync.Mutex
+	checked   bool
+	streaming bool
+	frames    int
+	lastFlush time.Time
+	timer     *time.Timer
+	done      bool
+}
+
+// check tells streams by the content type set by framedMarshaler, before the header is
+// sent
+func (w *framedWriter) check() {
+	if w.checked {
+		return
+	}
+	w.checked = true
+	if w.Header().Get("Content-Type") == streamContentType {
+		w.streaming = true
+		w.Header().Set("Content-Type", w.framer.contentType)
+	}
+}
+
+func (w *framedWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.check()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *framedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.check()
+	if !w.streaming || w.framer.framing == StreamFramingDefault || len(p) == 0 {
+		return w.ResponseWriter.Write(p)
+	}
+
+	var prefix, suffix []byte
+	switch w.framer.framing {
+	case StreamFramingNDJSON:
+		suffix = []byte("\n")
+	case StreamFramingJSONArray:
+		prefix = []byte(",")
+		if w.frames == 0 {
+			prefix = []byte("[")
+		}
+	case StreamFramingLengthPrefixed:
+		prefix = binary.BigEndian.AppendUint32(nil, uint32(len(p)))
+	}
+	w.frames++
+
+	frame := make([]byte, 0, len(prefix)+len(p)+len(suffix))
+	frame = append(append(append(frame, prefix...), p...), suffix...)
+	if _, err := w.ResponseWriter.Write(frame); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush sends the written messages, or schedules it when the last flush is more recent
+// than the flush interval
+func (w *framedWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.streaming || w.framer.flushInterval <= 0 {
+		w.flush()
+		return
+	}
+	wait := w.framer.flushInterval - time.Since(w.lastFlush)
+	if wait <= 0 {
+		w.flush()
+		return
+	}
+	if w.timer == nil {
+		w.timer = time.AfterFunc(wait, func() {
+			w.mu.Lock()
+			defer w.mu.Unlock()
+			w.timer = nil
+			if !w.done {
+				w.flush()
+			}
+		})
+	}
+}
+
+// flush flushes the underlying writer; w.mu must be held
+func (w *framedWriter) flush() {
+	w.lastFlush = time.Now()
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// finish closes the JSON array of a stream and stops scheduled flushes. Streams without
+// messages write nothing, so they are told by their chunked transfer encoding.
+func (w *framedWriter) finish() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.done = true
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	if w.framer.framing != StreamFramingJSONArray {
+		return
+	}
+	switch {
+	case w.streaming && w.frames > 0:
+		_, _ = w.ResponseWriter.Write([]byte("]"))
+	case !w.checked && w.Header().Get("Transfer-Encoding") == "chunked":
+		w.Header().Set("Content-Type", w.framer.contentType)
+		_, _ = w.ResponseWriter.Write([]byte("[]"))
+	}
+}
+
+func (w *framedWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,165 @@
+package gateway
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// streamServer starts a gateway with a GET /v1/events route streaming the values, then
+// err when set, as a server-streaming method does
+func streamServer(t *testing.T, values []string, err error, opts ...Option) http.Handler {
+	t.Helper()
+
+	registrar := new(mockServiceRegistrar)
+	registrar.On("RegisterHTTP", mock.Anything, mock.Anything, ":50051", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		mux := args.Get(1).(*runtime.ServeMux)
+		require.NoError(t, mux.HandlePath(http.MethodGet, "/v1/events", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+			_, outbound := runtime.MarshalerForRequest(mux, r)
+			ctx := runtime.NewServerMetadataContext(r.Context(), runtime.ServerMetadata{})
+			i := 0
+			runtime.ForwardResponseStream(ctx, mux, outbound, w, r, func() (proto.Message, error) {
+				if i == len(values) {
+					if err != nil {
+						return nil, err
+					}
+					return nil, io.EOF
+				}
+				i++
+				return wrapperspb.String(values[i-1]), nil
+			}, mux.GetForwardResponseOptions()...)
+		}))
+	})
+	handlerCh := make(chan http.Handler, 1)
+	opts = append(opts, WithServices(registrar), WithServeFunc(func(_ context.Context, h http.Handler) error {
+		handlerCh <- h
+		return nil
+	}))
+	srv := NewServer(slog.New(slog.DiscardHandler), time.Second, ":50051", ":8080", opts...)
+	require.NoError(t, srv.PreRun(context.Background()))
+	require.NoError(t, srv.Run(context.Background()))
+	return <-handlerCh
+}
+
+func TestServer_StreamFraming(t *testing.T) {
+	notFound := status.Error(codes.NotFound, "gone")
+
+	tests := []struct {
+		name            string
+		opts            []Option
+		values          []string
+		err             error
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			name:            "default",
+			values:          []string{"a", "b"},
+			wantContentType: "application/json",
+			wantBody:        "{\n  \"result\": \"a\"\n}\n{\n  \"result\": \"b\"\n}\n",
+		},
+		{
+			name:            "ndjson",
+			opts:            []Option{WithStreamFraming(StreamFramingNDJSON)},
+			values:          []string{"a", "b"},
+			wantContentType: "application/x-ndjson",
+			wantBody:        "\"a\"\n\"b\"\n",
+		},
+		{
+			name:            "ndjson ending with an error",
+			opts:            []Option{WithStreamFraming(StreamFramingNDJSON)},
+			values:          []string{"a"},
+			err:             notFound,
+			wantContentType: "application/x-ndjson",
+			wantBody:        "\"a\"\n{\"error\":{\"code\":5,\"message\":\"gone\",\"details\":[]}}\n",
+		},
+		{
+			name:            "json array",
+			opts:            []Option{WithStreamFraming(StreamFramingJSONArray)},
+			values:          []string{"a", "b"},
+			wantContentType: "application/json",
+			wantBody:        `["a","b"]`,
+		},
+		{
+			name:            "json array ending with an error",
+			opts:            []Option{WithStreamFraming(StreamFramingJSONArray)},
+			values:          []string{"a"},
+			err:             notFound,
+			wantContentType: "application/json",
+			wantBody:        `["a",{"error":{"code":5,"message":"gone","details":[]}}]`,
+		},
+		{
+			name:            "empty json array",
+			opts:            []Option{WithStreamFraming(StreamFramingJSONArray)},
+			wantContentType: "application/json",
+			wantBody:        `[]`,
+		},
+		{
+			name:            "length-prefixed",
+			opts:            []Option{WithStreamFraming(StreamFramingLengthPrefixed)},
+			values:          []string{"a", "bc"},
+			wantContentType: "application/json",
+			wantBody:        "\x00\x00\x00\x03\"a\"\x00\x00\x00\x04\"bc\"",
+		},
+		{
+			name:            "flush interval",
+			opts:            []Option{WithStreamFlushInterval(time.Hour)},
+			values:          []string{"a", "b"},
+			wantContentType: "application/json",
+			wantBody:        "{\n  \"result\": \"a\"\n}\n{\n  \"result\": \"b\"\n}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handler := streamServer(t, tt.values, tt.err, tt.opts...)
+			rec := httptest.NewRecorder()
+
+			// Act
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/events", nil))
+
+			// Assert
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tt.wantContentType, rec.Header().Get("Content-Type"))
+			assert.Equal(t, tt.wantBody, rec.Body.String())
+		})
+	}
+}
+
+func TestServer_StreamFraming_ErrorBeforeMessages(t *testing.T) {
+	// Arrange
+	handler := streamServer(t, nil, status.Error(codes.NotFound, "gone"), WithStreamFraming(StreamFramingJSONArray))
+	rec := httptest.NewRecorder()
+
+	// Act
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/events", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.JSONEq(t, `{"error":{"code":5,"message":"gone","details":[]}}`, rec.Body.String())
+}
+
+func TestServer_PreRun_InvalidStreamFraming(t *testing.T) {
+	// Arrange
+	srv := NewServer(slog.New(slog.DiscardHandler), time.Second, ":50051", ":8080", WithStreamFraming("sse"))
+
+	// Act
+	err := srv.PreRun(context.Background())
+
+	// Assert
+	assert.ErrorContains(t, err, `invalid stream framing "sse"`)
+}
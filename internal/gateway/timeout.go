@@ -0,0 +1,46 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// timeoutMiddleware sets the deadline of the request, and so of its gRPC call, to the
+// timeout in the first of the headers the request has, written as a Go duration such as
+// "1.5s" or as seconds such as "2". Requests with an invalid or non-positive timeout are
+// rejected with 400. grpc-gateway applies Grpc-Timeout headers, in the gRPC format, itself.
+func timeoutMiddleware(headers []string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, header := range headers {
+			value := r.Header.Get(header)
+			if value == "" {
+				continue
+			}
+			timeout, ok := parseTimeout(value)
+			if !ok {
+				http.Error(w, "invalid "+http.CanonicalHeaderKey(header)+" header", http.StatusBadRequest)
+				return
+			}
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+			r = r.WithContext(ctx)
+			break
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// parseTimeout parses a positive timeout written as a Go duration or as seconds
+func parseTimeout(value string) (time.Duration, bool) {
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		seconds, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return 0, false
+		}
+		timeout = time.Duration(seconds * float64(time.Second))
+	}
+	return timeout, timeout > 0
+}
@@ -0,0 +1,51 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeoutMiddleware(t *testing.T) {
+	tests := []struct {
+		name         string
+		headers      map[string]string
+		wantStatus   int
+		wantDeadline time.Duration
+	}{
+		{name: "duration", headers: map[string]string{"X-Request-Timeout": "1.5s"}, wantStatus: http.StatusOK, wantDeadline: 1500 * time.Millisecond},
+		{name: "seconds", headers: map[string]string{"X-Request-Timeout": "2"}, wantStatus: http.StatusOK, wantDeadline: 2 * time.Second},
+		{name: "first header", headers: map[string]string{"X-Request-Timeout": "1s", "X-Timeout": "5s"}, wantStatus: http.StatusOK, wantDeadline: time.Second},
+		{name: "second header", headers: map[string]string{"X-Timeout": "5s"}, wantStatus: http.StatusOK, wantDeadline: 5 * time.Second},
+		{name: "no header", wantStatus: http.StatusOK},
+		{name: "invalid", headers: map[string]string{"X-Request-Timeout": "soon"}, wantStatus: http.StatusBadRequest},
+		{name: "negative", headers: map[string]string{"X-Request-Timeout": "-1s"}, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var deadline time.Duration
+			handler := timeoutMiddleware([]string{"x-request-timeout", "X-Timeout"}, http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				if d, ok := r.Context().Deadline(); ok {
+					deadline = time.Until(d)
+				}
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/v1/orders", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+
+			// Act
+			handler.ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.InDelta(t, tt.wantDeadline, deadline, float64(100*time.Millisecond))
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package gateway
+
+import (
+	"fmt"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+)
+
+// Unescaping modes accepted by WithUnescapingMode
+const (
+	// UnescapingLegacy unescapes the whole path before matching, so %2F splits segments
+	UnescapingLegacy = "legacy"
+	// UnescapingAllExceptReserved unescapes path parameters except RFC 6570 reserved
+	// characters
+	UnescapingAllExceptReserved = "all_except_reserved"
+	// UnescapingAllExceptSlash unescapes path parameters except %2F, keeping it in the
+	// parameter instead of splitting the segment
+	UnescapingAllExceptSlash = "all_except_slash"
+	// UnescapingAllCharacters unescapes every character of path parameters
+	UnescapingAllCharacters = "all_characters"
+)
+
+// parseUnescapingMode returns the grpc-gateway unescaping mode of its name
+func parseUnescapingMode(mode string) (runtime.UnescapingMode, error) {
+	switch mode {
+	case UnescapingLegacy:
+		return runtime.UnescapingModeLegacy, nil
+	case UnescapingAllExceptReserved:
+		return runtime.UnescapingModeAllExceptReserved, nil
+	case UnescapingAllExceptSlash:
+		return runtime.UnescapingModeAllExceptSlash, nil
+	case UnescapingAllCharacters:
+		return runtime.UnescapingModeAllCharacters, nil
+	}
+	return 0, fmt.Errorf("invalid unescaping mode %q: must be %q, %q, %q or %q",
+		mode, UnescapingLegacy, UnescapingAllExceptReserved, UnescapingAllExceptSlash, UnescapingAllCharacters)
+}
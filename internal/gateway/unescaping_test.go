@@ -0,0 +1,106 @@
+package gateway
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fileServer starts a gateway with a GET /v1/files/{name} route echoing the name
+func fileServer(t *testing.T, opts ...Option) http.Handler {
+	t.Helper()
+
+	registrar := new(mockServiceRegistrar)
+	registrar.On("RegisterHTTP", mock.Anything, mock.Anything, ":50051", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		mux := args.Get(1).(*runtime.ServeMux)
+		require.NoError(t, mux.HandlePath(http.MethodGet, "/v1/files/{name}", func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+			_, _ = w.Write([]byte(params["name"] + "?" + r.Form.Get("version")))
+		}))
+	})
+	handlerCh := make(chan http.Handler, 1)
+	opts = append(opts, WithServices(registrar), WithServeFunc(func(_ context.Context, h http.Handler) error {
+		handlerCh <- h
+		return nil
+	}))
+	srv := NewServer(slog.New(slog.DiscardHandler), time.Second, ":50051", ":8080", opts...)
+	require.NoError(t, srv.PreRun(context.Background()))
+	require.NoError(t, srv.Run(context.Background()))
+	return <-handlerCh
+}
+
+func TestServer_UnescapingMode(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "legacy splits on escaped slashes", mode: UnescapingLegacy, wantStatus: http.StatusNotFound},
+		{name: "all except slash keeps the parameter whole", mode: UnescapingAllExceptSlash, wantStatus: http.StatusOK, wantBody: "reports/2024 q1.csv?"},
+		{name: "all characters splits on escaped slashes", mode: UnescapingAllCharacters, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handler := fileServer(t, WithUnescapingMode(tt.mode))
+			rec := httptest.NewRecorder()
+
+			// Act
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/files/reports%2F2024%20q1.csv", nil))
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestServer_PathLengthFallback(t *testing.T) {
+	tests := []struct {
+		name       string
+		enabled    bool
+		wantStatus int
+	}{
+		{name: "enabled", enabled: true, wantStatus: http.StatusOK},
+		{name: "disabled", enabled: false, wantStatus: http.StatusNotImplemented},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handler := fileServer(t, WithPathLengthFallback(tt.enabled))
+			req := httptest.NewRequest(http.MethodPost, "/v1/files/report.csv", strings.NewReader("version=3"))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			rec := httptest.NewRecorder()
+
+			// Act
+			handler.ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
+
+func TestServer_PreRun_InvalidUnescapingMode(t *testing.T) {
+	// Arrange
+	srv := NewServer(slog.New(slog.DiscardHandler), time.Second, ":50051", ":8080", WithUnescapingMode("none"))
+
+	// Act
+	err := srv.PreRun(context.Background())
+
+	// Assert
+	assert.ErrorContains(t, err, `invalid unescaping mode "none"`)
+}
@@ -0,0 +1,71 @@
+package grpc
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"time"
+
+	healthGrpc "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/legrch/netgex/service"
+)
+
+// WithHealthCheckInterval sets the interval at which the health checks of registrars
+// implementing service.HealthChecker update the health status of their services.
+// An interval of 0 disables polling.
+func WithHealthCheckInterval(interval time.Duration) Option {
+	return func(s *Server) {
+		s.healthInterval = interval
+	}
+}
+
+// addHealthCheck records the services a registrar added to the server, so their health
+// status follows the registrar's health check. known are the services registered before it.
+func (s *Server) addHealthCheck(registrar service.Registrar, known map[string]bool) {
+	hc, ok := registrar.(service.HealthChecker)
+	for name := range s.server.GetServiceInfo() {
+		if known[name] {
+			continue
+		}
+		known[name] = true
+		if ok {
+			if s.healthChecks == nil {
+				s.healthChecks = map[string]service.HealthChecker{}
+			}
+			s.healthChecks[name] = hc
+		}
+	}
+}
+
+// pollHealth updates the health status of the checked services every interval until
+// ctx is canceled
+func (s *Server) pollHealth(ctx context.Context) {
+	ticker := time.NewTicker(s.healthInterval)
+	defer ticker.Stop()
+
+	for {
+		s.checkHealth(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkHealth runs the health checks once, setting each service SERVING or NOT_SERVING
+func (s *Server) checkHealth(ctx context.Context) {
+	for _, name := range slices.Sorted(maps.Keys(s.healthChecks)) {
+		checkCtx, cancel := context.WithTimeout(ctx, s.healthInterval)
+		err := s.healthChecks[name].HealthCheck(checkCtx)
+		cancel()
+
+		status := healthGrpc.HealthCheckResponse_SERVING
+		if err != nil {
+			status = healthGrpc.HealthCheckResponse_NOT_SERVING
+			s.logger.Warn("health check failed", "service", name, "error", err)
+		}
+		s.healthServer.SetServingStatus(name, status)
+	}
+}
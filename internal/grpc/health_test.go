@@ -0,0 +1,67 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// checkedRegistrar registers a service and reports the configured health
+type checkedRegistrar struct {
+	service string
+	err     error
+}
+
+func (r *checkedRegistrar) RegisterGRPC(srv *grpc.Server) {
+	srv.RegisterService(&grpc.ServiceDesc{ServiceName: r.service, HandlerType: (*any)(nil)}, struct{}{})
+}
+
+func (r *checkedRegistrar) RegisterHTTP(context.Context, *runtime.ServeMux, string, []grpc.DialOption) error {
+	return nil
+}
+
+func (r *checkedRegistrar) HealthCheck(context.Context) error {
+	return r.err
+}
+
+func TestServer_CheckHealth(t *testing.T) {
+	// Arrange
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	orders := &checkedRegistrar{service: "test.Orders"}
+	payments := &checkedRegistrar{service: "test.Payments", err: errors.New("database unreachable")}
+	server := NewServer(logger, time.Second, ":0",
+		WithServices(orders, payments),
+		WithHealthCheckInterval(time.Second),
+	)
+	require.NoError(t, server.PreRun(context.Background()))
+
+	servingStatus := func(name string) grpc_health_v1.HealthCheckResponse_ServingStatus {
+		resp, err := server.healthServer.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: name})
+		require.NoError(t, err)
+		return resp.GetStatus()
+	}
+
+	// Act
+	server.checkHealth(context.Background())
+
+	// Assert
+	assert.Len(t, server.healthChecks, 2, "only the registrars' services are checked")
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, servingStatus("test.Orders"))
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, servingStatus("test.Payments"))
+
+	// Act - the dependency recovers
+	payments.err = nil
+	server.checkHealth(context.Background())
+
+	// Assert
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, servingStatus("test.Payments"))
+}
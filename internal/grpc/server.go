@@ -5,13 +5,18 @@ import (
 	"fmt"
 	"log/slog"
 	"net"
+	"net/http"
 	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/health"
 	healthGrpc "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/reflection"
+	v1alphareflectiongrpc "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/stats"
 
+	"github.com/legrch/netgex/internal/listener"
 	"github.com/legrch/netgex/service"
 )
 
@@ -28,10 +33,29 @@ type Server struct {
 	unaryInterceptors  []grpc.UnaryServerInterceptor
 	streamInterceptors []grpc.StreamServerInterceptor
 	serverOptions      []grpc.ServerOption
+	statsHandlers      []stats.Handler
+	keepaliveParams    *keepalive.ServerParameters
+	keepalivePolicy    *keepalive.EnforcementPolicy
+	maxRecvMsgSize     int
+	maxSendMsgSize     int
+	healthServer       *health.Server
+	healthInterval     time.Duration
+	healthChecks       map[string]service.HealthChecker
+	listen             listener.Options
+	listener           net.Listener
+	bound              listener.Bound
 	reflectionEnabled  bool
+	reflectionVersions string
 	healthCheckEnabled bool
 }
 
+// Reflection protocol versions accepted by WithReflectionVersions
+const (
+	ReflectionV1      = "v1"
+	ReflectionV1Alpha = "v1alpha"
+	ReflectionBoth    = "both"
+)
+
 // NewServer creates a new gRPC server
 func NewServer(
 	logger *slog.Logger,
@@ -44,6 +68,7 @@ func NewServer(
 		closeTimeout:       closeTimeout,
 		address:            address,
 		reflectionEnabled:  false,
+		reflectionVersions: ReflectionBoth,
 		healthCheckEnabled: true, // Enable health checks by default
 	}
 
@@ -83,6 +108,45 @@ func WithOptions(options ...grpc.ServerOption) Option {
 	}
 }
 
+// WithStatsHandlers adds stats handlers that observe every connection and RPC
+func WithStatsHandlers(handlers ...stats.Handler) Option {
+	return func(s *Server) {
+		s.statsHandlers = append(s.statsHandlers, handlers...)
+	}
+}
+
+// WithKeepalive sets the keepalive parameters and the enforcement policy for client pings
+func WithKeepalive(params keepalive.ServerParameters, policy keepalive.EnforcementPolicy) Option {
+	return func(s *Server) {
+		s.keepaliveParams = &params
+		s.keepalivePolicy = &policy
+	}
+}
+
+// WithMaxMsgSizes sets the maximum message sizes the server receives and sends in bytes.
+// A size of 0 keeps the gRPC default.
+func WithMaxMsgSizes(recv, send int) Option {
+	return func(s *Server) {
+		s.maxRecvMsgSize = recv
+		s.maxSendMsgSize = send
+	}
+}
+
+// WithListenOptions sets the socket options of the listener, SO_REUSEPORT included
+func WithListenOptions(opts listener.Options) Option {
+	return func(s *Server) {
+		s.listen = opts
+	}
+}
+
+// WithListener serves on lis instead of listening on the address, e.g. an in-memory
+// listener
+func WithListener(lis net.Listener) Option {
+	return func(s *Server) {
+		s.listener = lis
+	}
+}
+
 // WithReflection enables or disables gRPC reflection
 func WithReflection(enabled bool) Option {
 	return func(s *Server) {
@@ -90,6 +154,14 @@ func WithReflection(enabled bool) Option {
 	}
 }
 
+// WithReflectionVersions selects the reflection protocol versions to register:
+// ReflectionV1, ReflectionV1Alpha or ReflectionBoth
+func WithReflectionVersions(versions string) Option {
+	return func(s *Server) {
+		s.reflectionVersions = versions
+	}
+}
+
 // WithHealthCheck enables or disables gRPC health checks
 func WithHealthCheck(enabled bool) Option {
 	return func(s *Server) {
@@ -101,7 +173,20 @@ func WithHealthCheck(enabled bool) Option {
 func (s *Server) PreRun(_ context.Context) error {
 	// Prepare server options
 
-	opts := make([]grpc.ServerOption, 0, len(s.serverOptions)+len(s.unaryInterceptors)+len(s.streamInterceptors))
+	opts := make([]grpc.ServerOption, 0, len(s.serverOptions)+len(s.statsHandlers)+6)
+	if s.keepaliveParams != nil {
+		opts = append(opts, grpc.KeepaliveParams(*s.keepaliveParams), grpc.KeepaliveEnforcementPolicy(*s.keepalivePolicy))
+	}
+	if s.maxRecvMsgSize > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(s.maxRecvMsgSize))
+	}
+	if s.maxSendMsgSize > 0 {
+		opts = append(opts, grpc.MaxSendMsgSize(s.maxSendMsgSize))
+	}
+	for _, handler := range s.statsHandlers {
+		opts = append(opts, grpc.StatsHandler(handler))
+	}
+	// Raw server options come after the configured settings so they can override them
 	opts = append(opts, s.serverOptions...)
 	opts = append(opts, grpc.ChainUnaryInterceptor(s.unaryInterceptors...), grpc.ChainStreamInterceptor(s.streamInterceptors...))
 
@@ -110,36 +195,68 @@ func (s *Server) PreRun(_ context.Context) error {
 
 	// Register health check service if enabled
 	if s.healthCheckEnabled {
-		healthServer := health.NewServer()
-		healthGrpc.RegisterHealthServer(srv, healthServer)
+		s.healthServer = health.NewServer()
+		healthGrpc.RegisterHealthServer(srv, s.healthServer)
 	}
 
-	// Register all service implementations
+	// Store the server
+	s.server = srv
+
+	// Register all service implementations, noting the services of those with health checks
+	known := map[string]bool{}
+	for name := range srv.GetServiceInfo() {
+		known[name] = true
+	}
 	for _, registrar := range s.registrars {
 		registrar.RegisterGRPC(srv)
+		s.addHealthCheck(registrar, known)
 	}
 
 	// Enable reflection if requested
 	if s.reflectionEnabled {
-		reflection.Register(srv)
+		if err := registerReflection(srv, s.reflectionVersions); err != nil {
+			return err
+		}
 	}
 
-	// Store the server
-	s.server = srv
+	return nil
+}
 
+// registerReflection registers the requested reflection protocol versions
+func registerReflection(srv *grpc.Server, versions string) error {
+	switch versions {
+	case ReflectionBoth, "":
+		reflection.Register(srv)
+	case ReflectionV1:
+		reflection.RegisterV1(srv)
+	case ReflectionV1Alpha:
+		v1alphareflectiongrpc.RegisterServerReflectionServer(srv, reflection.NewServer(reflection.ServerOptions{Services: srv}))
+	default:
+		return fmt.Errorf("invalid reflection versions %q: must be %q, %q or %q", versions, ReflectionV1, ReflectionV1Alpha, ReflectionBoth)
+	}
 	return nil
 }
 
 // Run starts the gRPC server
-func (s *Server) Run(_ context.Context) error {
+func (s *Server) Run(ctx context.Context) error {
 	// Create listener
-	lis, err := net.Listen("tcp", s.address)
-	if err != nil {
-		return fmt.Errorf("failed to listen: %w", err)
+	lis := s.listener
+	if lis == nil {
+		var err error
+		if lis, err = listener.Listen(ctx, s.address, s.listen); err != nil {
+			return fmt.Errorf("failed to listen: %w", err)
+		}
+	}
+
+	s.bound.Set(lis)
+
+	// Keep the health status of services with health checks up to date
+	if s.healthServer != nil && len(s.healthChecks) > 0 && s.healthInterval > 0 {
+		go s.pollHealth(ctx)
 	}
 
 	// Start server
-	s.logger.Info("starting gRPC server", "address", s.address)
+	s.logger.Info("starting gRPC server", "address", s.Addr())
 	if err := s.server.Serve(lis); err != nil {
 		return fmt.Errorf("server error: %w", err)
 	}
@@ -147,6 +264,39 @@ func (s *Server) Run(_ context.Context) error {
 	return nil
 }
 
+// Addr returns the address the server is listening on, which differs from the configured
+// address when it uses port 0
+func (s *Server) Addr() string {
+	return s.bound.Addr(s.address)
+}
+
+// Ready returns a channel that is closed once the server is listening
+func (s *Server) Ready() <-chan struct{} {
+	return s.bound.Ready()
+}
+
+// ServeHTTP serves gRPC requests received by an HTTP/2 server, so gRPC can share a port
+// with other HTTP handlers. It must only be called after PreRun.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.server.ServeHTTP(w, r)
+}
+
+// GetServiceInfo returns the services registered on the server, or nil before PreRun
+func (s *Server) GetServiceInfo() map[string]grpc.ServiceInfo {
+	if s.server == nil {
+		return nil
+	}
+	return s.server.GetServiceInfo()
+}
+
+// Drain sets all health check statuses to NOT_SERVING ahead of shutdown
+func (s *Server) Drain() {
+	if s.healthServer != nil {
+		s.logger.Info("gRPC health set to NOT_SERVING")
+		s.healthServer.Shutdown()
+	}
+}
+
 // Shutdown gracefully stops the gRPC server
 func (s *Server) Shutdown(_ context.Context) error {
 	s.logger.Info("shutting down gRPC server")
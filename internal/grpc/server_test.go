@@ -5,6 +5,7 @@ import (
 	"log/slog"
 	"net"
 	"os"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -13,8 +14,12 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
 )
 
 func TestNewServer(t *testing.T) {
@@ -108,6 +113,159 @@ func TestServer_PreRun(t *testing.T) {
 	assert.NotNil(t, srv.server)
 }
 
+func TestServer_PreRun_Keepalive(t *testing.T) {
+	// Arrange
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	params := keepalive.ServerParameters{MaxConnectionAge: time.Minute}
+	policy := keepalive.EnforcementPolicy{MinTime: time.Second}
+
+	// Act
+	srv := NewServer(logger, time.Second, ":50051", WithKeepalive(params, policy))
+	err := srv.PreRun(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, params, *srv.keepaliveParams)
+	assert.Equal(t, policy, *srv.keepalivePolicy)
+}
+
+func TestServer_Drain(t *testing.T) {
+	// Arrange
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	srv := NewServer(logger, time.Second, ":50051", WithHealthCheck(true))
+	require.NoError(t, srv.PreRun(context.Background()))
+
+	// Act
+	srv.Drain()
+
+	// Assert
+	resp, err := srv.healthServer.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, resp.GetStatus())
+}
+
+func TestServer_MaxMsgSizes(t *testing.T) {
+	// Arrange
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	srv := NewServer(logger, time.Second, "127.0.0.1:0", WithHealthCheck(true), WithMaxMsgSizes(16, 1<<20))
+	require.NoError(t, srv.PreRun(context.Background()))
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = srv.server.Serve(lis) }()
+	defer srv.server.Stop()
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// Act - the request exceeds the 16 byte receive limit
+	_, err = grpc_health_v1.NewHealthClient(conn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{
+		Service: "a-service-name-longer-than-the-limit",
+	})
+
+	// Assert
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+func TestServer_PreRun_ReflectionVersions(t *testing.T) {
+	const (
+		v1      = "grpc.reflection.v1.ServerReflection"
+		v1alpha = "grpc.reflection.v1alpha.ServerReflection"
+	)
+
+	tests := []struct {
+		name     string
+		versions string
+		want     []string
+		notWant  []string
+		wantErr  bool
+	}{
+		{name: "both", versions: ReflectionBoth, want: []string{v1, v1alpha}},
+		{name: "v1 only", versions: ReflectionV1, want: []string{v1}, notWant: []string{v1alpha}},
+		{name: "v1alpha only", versions: ReflectionV1Alpha, want: []string{v1alpha}, notWant: []string{v1}},
+		{name: "invalid", versions: "v2", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+			srv := NewServer(logger, time.Second, ":50051", WithReflection(true), WithReflectionVersions(tt.versions))
+
+			// Act
+			err := srv.PreRun(context.Background())
+
+			// Assert
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			services := srv.server.GetServiceInfo()
+			for _, name := range tt.want {
+				assert.Contains(t, services, name)
+			}
+			for _, name := range tt.notWant {
+				assert.NotContains(t, services, name)
+			}
+		})
+	}
+}
+
+// countingStatsHandler counts the connections and RPCs it observes
+type countingStatsHandler struct {
+	conns atomic.Int32
+	rpcs  atomic.Int32
+}
+
+func (h *countingStatsHandler) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (h *countingStatsHandler) HandleRPC(_ context.Context, s stats.RPCStats) {
+	if _, ok := s.(*stats.Begin); ok {
+		h.rpcs.Add(1)
+	}
+}
+
+func (h *countingStatsHandler) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (h *countingStatsHandler) HandleConn(_ context.Context, s stats.ConnStats) {
+	if _, ok := s.(*stats.ConnBegin); ok {
+		h.conns.Add(1)
+	}
+}
+
+func TestServer_StatsHandlers(t *testing.T) {
+	// Arrange
+	handler := &countingStatsHandler{}
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	srv := NewServer(logger, time.Second, "127.0.0.1:0", WithHealthCheck(true), WithStatsHandlers(handler))
+	require.NoError(t, srv.PreRun(context.Background()))
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = srv.server.Serve(lis) }()
+	defer srv.server.Stop()
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// Act
+	for range 2 {
+		_, err = grpc_health_v1.NewHealthClient(conn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+		require.NoError(t, err)
+	}
+
+	// Assert
+	assert.Equal(t, int32(1), handler.conns.Load())
+	assert.Equal(t, int32(2), handler.rpcs.Load())
+}
+
 func TestServer_RunAndShutdown(t *testing.T) {
 	// Skip in short mode
 	if testing.Short() {
@@ -0,0 +1,177 @@
+// Package inflight counts the gRPC calls and HTTP requests being served, so deploy
+// tooling can tell when a draining instance is idle, and the connections and streams
+// open to the servers.
+package inflight
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/legrch/netgex/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/stats"
+)
+
+// Transports label the requests counted by a Tracker
+const (
+	GRPC = "grpc"
+	HTTP = "http"
+)
+
+// Option configures a Tracker
+type Option func(*Tracker)
+
+// WithMetrics exports the in-flight requests as the <namespace>_inflight_requests gauge,
+// the open connections as <namespace>_open_connections and the active gRPC streams as
+// <namespace>_active_streams, labeled by transport
+func WithMetrics(namespace string) Option {
+	return func(t *Tracker) {
+		t.gauge = gaugeVec(namespace, "inflight_requests", "Number of gRPC calls and HTTP requests being served")
+		t.connGauge = gaugeVec(namespace, "open_connections", "Number of connections open to the gRPC and HTTP servers")
+		t.streamGauge = gaugeVec(namespace, "active_streams", "Number of gRPC streaming calls being served")
+	}
+}
+
+// Tracker counts in-flight requests, open connections and active streams
+type Tracker struct {
+	grpc        atomic.Int64
+	http        atomic.Int64
+	grpcConns   atomic.Int64
+	httpConns   atomic.Int64
+	grpcStreams atomic.Int64
+	gauge       *prometheus.GaugeVec
+	connGauge   *prometheus.GaugeVec
+	streamGauge *prometheus.GaugeVec
+}
+
+// New creates a Tracker
+func New(opts ...Option) *Tracker {
+	t := &Tracker{}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Counts are the in-flight requests of each transport
+type Counts struct {
+	GRPC int64 `json:"grpc"`
+	HTTP int64 `json:"http"`
+}
+
+// Idle tells whether no request is in flight
+func (c Counts) Idle() bool {
+	return c.GRPC == 0 && c.HTTP == 0
+}
+
+// InFlight returns the requests being served
+func (t *Tracker) InFlight() Counts {
+	return Counts{GRPC: t.grpc.Load(), HTTP: t.http.Load()}
+}
+
+// Connections are the connections open to the servers of each transport, and the
+// gRPC streams being served over them
+type Connections struct {
+	GRPC        int64 `json:"grpc"`
+	GRPCStreams int64 `json:"grpc_streams"`
+	HTTP        int64 `json:"http"`
+}
+
+// Connections returns the open connections and active streams
+func (t *Tracker) Connections() Connections {
+	return Connections{GRPC: t.grpcConns.Load(), GRPCStreams: t.grpcStreams.Load(), HTTP: t.httpConns.Load()}
+}
+
+// track counts a request of a transport until the returned function is called
+func (t *Tracker) track(counter *atomic.Int64, gauge *prometheus.GaugeVec, transport string) (done func()) {
+	add(counter, gauge, transport, 1)
+	return func() {
+		add(counter, gauge, transport, -1)
+	}
+}
+
+// add changes a count and its gauge, when metrics are exported
+func add(counter *atomic.Int64, gauge *prometheus.GaugeVec, transport string, delta int64) {
+	counter.Add(delta)
+	if gauge != nil {
+		gauge.WithLabelValues(transport).Add(float64(delta))
+	}
+}
+
+// UnaryServerInterceptor counts unary calls while their handler runs
+func (t *Tracker) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		defer t.track(&t.grpc, t.gauge, GRPC)()
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor counts streams, as calls and as active streams, until they end
+func (t *Tracker) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		defer t.track(&t.grpc, t.gauge, GRPC)()
+		defer t.track(&t.grpcStreams, t.streamGauge, GRPC)()
+		return handler(srv, ss)
+	}
+}
+
+// Middleware counts HTTP requests while their handler runs
+func (t *Tracker) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer t.track(&t.http, t.gauge, HTTP)()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ConnState counts the connections open to an HTTP server, for http.Server.ConnState.
+// Hijacked connections are no longer the server's and stop being counted.
+func (t *Tracker) ConnState(_ net.Conn, state http.ConnState) {
+	switch state {
+	case http.StateNew:
+		add(&t.httpConns, t.connGauge, HTTP, 1)
+	case http.StateHijacked, http.StateClosed:
+		add(&t.httpConns, t.connGauge, HTTP, -1)
+	}
+}
+
+// StatsHandler returns the stats handler counting the connections open to a gRPC server
+func (t *Tracker) StatsHandler() stats.Handler {
+	return connHandler{t}
+}
+
+// connHandler counts gRPC connections from their begin and end events
+type connHandler struct {
+	t *Tracker
+}
+
+func (h connHandler) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context { return ctx }
+
+func (h connHandler) HandleRPC(context.Context, stats.RPCStats) {}
+
+func (h connHandler) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context { return ctx }
+
+func (h connHandler) HandleConn(_ context.Context, s stats.ConnStats) {
+	switch s.(type) {
+	case *stats.ConnBegin:
+		add(&h.t.grpcConns, h.t.connGauge, GRPC, 1)
+	case *stats.ConnEnd:
+		add(&h.t.grpcConns, h.t.connGauge, GRPC, -1)
+	}
+}
+
+// gaugeVec returns the gauge of the namespace labeled by transport, registering it
+// unless already registered
+func gaugeVec(namespace, name, help string) *prometheus.GaugeVec {
+	gauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      name,
+			Help:      help,
+		},
+		[]string{"transport"},
+	)
+	return metrics.Register(gauge)
+}
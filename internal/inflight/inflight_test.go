@@ -0,0 +1,117 @@
+package inflight
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/stats"
+)
+
+func TestTracker_UnaryServerInterceptor(t *testing.T) {
+	// Arrange
+	tracker := New(WithMetrics("inflight_test"))
+	var during Counts
+	handler := func(context.Context, any) (any, error) {
+		during = tracker.InFlight()
+		return nil, nil
+	}
+
+	// Act
+	_, _ = tracker.UnaryServerInterceptor()(context.Background(), nil, &grpc.UnaryServerInfo{}, handler)
+
+	// Assert
+	assert.Equal(t, Counts{GRPC: 1}, during)
+	assert.Equal(t, Counts{}, tracker.InFlight())
+	assert.True(t, tracker.InFlight().Idle())
+	assert.Equal(t, float64(0), testutil.ToFloat64(tracker.gauge.WithLabelValues(GRPC)))
+}
+
+func TestTracker_Middleware(t *testing.T) {
+	// Arrange
+	tracker := New()
+	var during Counts
+	handler := tracker.Middleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		during = tracker.InFlight()
+	}))
+
+	// Act
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/orders", nil))
+
+	// Assert
+	assert.Equal(t, Counts{HTTP: 1}, during)
+	assert.False(t, during.Idle())
+	assert.True(t, tracker.InFlight().Idle())
+}
+
+func TestTracker_StreamServerInterceptor(t *testing.T) {
+	// Arrange
+	tracker := New(WithMetrics("inflight_stream_test"))
+	var during Connections
+	handler := func(any, grpc.ServerStream) error {
+		during = tracker.Connections()
+		return nil
+	}
+
+	// Act
+	_ = tracker.StreamServerInterceptor()(nil, nil, &grpc.StreamServerInfo{}, handler)
+
+	// Assert
+	assert.Equal(t, Connections{GRPCStreams: 1}, during)
+	assert.Equal(t, Connections{}, tracker.Connections())
+	assert.True(t, tracker.InFlight().Idle())
+}
+
+func TestTracker_ConnState(t *testing.T) {
+	// Arrange
+	tracker := New(WithMetrics("inflight_conn_test"))
+	gauge := tracker.connGauge.WithLabelValues(HTTP)
+
+	// Act
+	for _, state := range []http.ConnState{http.StateNew, http.StateNew, http.StateActive, http.StateIdle, http.StateNew} {
+		tracker.ConnState(nil, state)
+	}
+	opened := tracker.Connections()
+	tracker.ConnState(nil, http.StateClosed)
+	tracker.ConnState(nil, http.StateHijacked)
+
+	// Assert
+	assert.Equal(t, Connections{HTTP: 3}, opened)
+	assert.Equal(t, Connections{HTTP: 1}, tracker.Connections())
+	assert.Equal(t, float64(1), testutil.ToFloat64(gauge))
+}
+
+func TestTracker_StatsHandler(t *testing.T) {
+	// Arrange
+	tracker := New()
+	handler := tracker.StatsHandler()
+	ctx := handler.TagConn(context.Background(), &stats.ConnTagInfo{})
+
+	// Act
+	handler.HandleConn(ctx, &stats.ConnBegin{})
+	handler.HandleConn(ctx, &stats.ConnBegin{})
+	opened := tracker.Connections()
+	handler.HandleConn(ctx, &stats.ConnEnd{})
+
+	// Assert
+	assert.Equal(t, Connections{GRPC: 2}, opened)
+	assert.Equal(t, Connections{GRPC: 1}, tracker.Connections())
+}
+
+func TestWithMetrics_SharedBetweenTrackers(t *testing.T) {
+	// Act
+	var first, second *Tracker
+	assert.NotPanics(t, func() {
+		first = New(WithMetrics("inflightshared"))
+		second = New(WithMetrics("inflightshared"))
+	})
+
+	// Assert
+	assert.Same(t, first.gauge, second.gauge)
+	assert.Same(t, first.connGauge, second.connGauge)
+	assert.Same(t, first.streamGauge, second.streamGauge)
+}
@@ -0,0 +1,143 @@
+// Package listener creates TCP listeners, optionally bound with SO_REUSEPORT so a new
+// process can start accepting on the same address before the old one has drained, and
+// with the socket options of high-connection-count deployments.
+package listener
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// Options are the socket options of a listener and the connections it accepts. The
+// zero value listens with Go's defaults.
+type Options struct {
+	// ReusePort sets SO_REUSEPORT
+	ReusePort bool
+	// KeepAlive is the TCP keepalive period of accepted connections; zero uses Go's
+	// default of 15s and a negative period disables keepalives
+	KeepAlive time.Duration
+	// Delay disables TCP_NODELAY on accepted connections, so small writes are coalesced
+	Delay bool
+	// Backlog is the length of the queue of pending connections; zero uses the system's
+	// default, e.g. net.core.somaxconn on Linux
+	Backlog int
+	// Hook adjusts the listen config last, e.g. to set other socket options with Control
+	Hook func(*net.ListenConfig)
+}
+
+// Listen announces on the TCP address with the socket options
+func Listen(ctx context.Context, address string, opts Options) (net.Listener, error) {
+	lc := net.ListenConfig{KeepAlive: opts.KeepAlive}
+	if opts.ReusePort {
+		lc.Control = reusePortControl
+	}
+	if opts.Hook != nil {
+		opts.Hook(&lc)
+	}
+
+	lis, err := lc.Listen(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Backlog > 0 {
+		if err := backlog(lis, opts.Backlog); err != nil {
+			_ = lis.Close()
+			return nil, fmt.Errorf("failed to set the listen backlog: %w", err)
+		}
+	}
+	if opts.Delay {
+		lis = delayListener{lis}
+	}
+	return lis, nil
+}
+
+// backlog sets the listen backlog of a TCP listener
+func backlog(lis net.Listener, n int) error {
+	tcp, ok := lis.(*net.TCPListener)
+	if !ok {
+		return fmt.Errorf("not a TCP listener: %T", lis)
+	}
+	c, err := tcp.SyscallConn()
+	if err != nil {
+		return err
+	}
+	return setBacklog(c, n)
+}
+
+// delayListener disables TCP_NODELAY on the connections it accepts
+type delayListener struct {
+	net.Listener
+}
+
+func (l delayListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	if tcp, ok := conn.(*net.TCPConn); ok {
+		_ = tcp.SetNoDelay(false)
+	}
+	return conn, nil
+}
+
+// Bound records the address a server's listener is bound to, which differs from the
+// configured address when it uses port 0. The zero value is ready to use.
+type Bound struct {
+	mu    sync.Mutex
+	addr  net.Addr
+	ready chan struct{}
+}
+
+// Set records the listener's address and marks the server ready. A nil listener only
+// marks it ready, for servers that receive requests without listening.
+func (b *Bound) Set(lis net.Listener) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if lis != nil {
+		b.addr = lis.Addr()
+	}
+	ready := b.readyChan()
+	select {
+	case <-ready:
+	default:
+		close(ready)
+	}
+}
+
+// Ready returns a channel that is closed once Set has been called
+func (b *Bound) Ready() <-chan struct{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.readyChan()
+}
+
+// Addr returns the configured address with the port the listener is bound to, keeping
+// the configured host, or the configured address before the listener is bound
+func (b *Bound) Addr(configured string) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.addr == nil {
+		return configured
+	}
+
+	host, _, err := net.SplitHostPort(configured)
+	if err != nil {
+		return b.addr.String()
+	}
+	_, port, err := net.SplitHostPort(b.addr.String())
+	if err != nil {
+		return b.addr.String()
+	}
+	return net.JoinHostPort(host, port)
+}
+
+// readyChan returns the ready channel, creating it on first use; b.mu must be held
+func (b *Bound) readyChan() chan struct{} {
+	if b.ready == nil {
+		b.ready = make(chan struct{})
+	}
+	return b.ready
+}
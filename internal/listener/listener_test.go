@@ -0,0 +1,108 @@
+package listener
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListen_ReusePort(t *testing.T) {
+	// Arrange
+	first, err := Listen(context.Background(), "127.0.0.1:0", Options{ReusePort: true})
+	require.NoError(t, err)
+	defer first.Close()
+
+	// Act - a second listener binds the same address while the first is open
+	second, err := Listen(context.Background(), first.Addr().String(), Options{ReusePort: true})
+
+	// Assert
+	require.NoError(t, err)
+	defer second.Close()
+	assert.Equal(t, first.Addr().String(), second.Addr().String())
+}
+
+func TestListen_WithoutReusePort(t *testing.T) {
+	// Arrange
+	first, err := Listen(context.Background(), "127.0.0.1:0", Options{})
+	require.NoError(t, err)
+	defer first.Close()
+
+	// Act
+	_, err = Listen(context.Background(), first.Addr().String(), Options{})
+
+	// Assert
+	assert.Error(t, err, "the address is in use")
+}
+
+func TestListen_Options(t *testing.T) {
+	// Arrange
+	hooked := false
+	opts := Options{
+		ReusePort: true,
+		KeepAlive: time.Minute,
+		Delay:     true,
+		Backlog:   16,
+		Hook: func(lc *net.ListenConfig) {
+			hooked = lc.Control != nil && lc.KeepAlive == time.Minute
+		},
+	}
+
+	// Act
+	lis, err := Listen(context.Background(), "127.0.0.1:0", opts)
+
+	// Assert
+	require.NoError(t, err)
+	defer lis.Close()
+	assert.True(t, hooked, "the hook sees the listen config with the socket options")
+	assert.IsType(t, delayListener{}, lis)
+
+	conn, err := net.Dial("tcp", lis.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	accepted, err := lis.Accept()
+	require.NoError(t, err)
+	defer accepted.Close()
+	assert.IsType(t, &net.TCPConn{}, accepted)
+}
+
+func TestBound_Addr(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured string
+	}{
+		{name: "port 0 with host", configured: "127.0.0.1:0"},
+		{name: "port 0 without host", configured: ":0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var b Bound
+			require.Equal(t, tt.configured, b.Addr(tt.configured), "configured address before the listener is bound")
+
+			lis, err := Listen(context.Background(), tt.configured, Options{})
+			require.NoError(t, err)
+			defer lis.Close()
+			_, port, err := net.SplitHostPort(lis.Addr().String())
+			require.NoError(t, err)
+
+			// Act
+			b.Set(lis)
+
+			// Assert
+			host, _, err := net.SplitHostPort(tt.configured)
+			require.NoError(t, err)
+			assert.Equal(t, net.JoinHostPort(host, port), b.Addr(tt.configured))
+			assert.NotEqual(t, "0", port)
+			select {
+			case <-b.Ready():
+			default:
+				t.Fatal("bound listener should be ready")
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package listener
+
+import (
+	"errors"
+	"syscall"
+)
+
+// reusePortControl reports that SO_REUSEPORT is unavailable on this platform
+func reusePortControl(_, _ string, _ syscall.RawConn) error {
+	return errors.New("SO_REUSEPORT is not supported on this platform")
+}
+
+// setBacklog reports that the listen backlog cannot be changed on this platform
+func setBacklog(_ syscall.RawConn, _ int) error {
+	return errors.New("setting the listen backlog is not supported on this platform")
+}
@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// labelName matches valid Prometheus label names, excluding the reserved __ prefix
+var labelName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// ParseLabels parses METRICS_LABELS entries of the form key=value, e.g. "team=payments"
+func ParseLabels(entries []string) (map[string]string, error) {
+	labels := map[string]string{}
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(entry, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !labelName.MatchString(key) || strings.HasPrefix(key, "__") {
+			return nil, fmt.Errorf("invalid METRICS_LABELS entry %q: expected label_name=value", entry)
+		}
+		labels[key] = strings.TrimSpace(value)
+	}
+	return labels, nil
+}
+
+// labeledGatherer adds constant labels to every metric of a gatherer, whoever registered
+// it. A label a metric already has keeps its value.
+type labeledGatherer struct {
+	gatherer prometheus.Gatherer
+	labels   map[string]string
+}
+
+// Gather gathers the metrics and adds the labels they miss
+func (g labeledGatherer) Gather() ([]*dto.MetricFamily, error) {
+	families, err := g.gatherer.Gather()
+	for _, family := range families {
+		for _, m := range family.Metric {
+			for name, value := range g.labels {
+				if !slices.ContainsFunc(m.Label, func(l *dto.LabelPair) bool { return l.GetName() == name }) {
+					m.Label = append(m.Label, &dto.LabelPair{Name: &name, Value: &value})
+				}
+			}
+			// The exposition format expects labels sorted by name
+			slices.SortFunc(m.Label, func(a, b *dto.LabelPair) int {
+				return strings.Compare(a.GetName(), b.GetName())
+			})
+		}
+	}
+	return families, err
+}
@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLabels(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []string
+		want    map[string]string
+		wantErr string
+	}{
+		{name: "pairs", entries: []string{"team=payments", " region = eu-west-1 "}, want: map[string]string{"team": "payments", "region": "eu-west-1"}},
+		{name: "empty", want: map[string]string{}},
+		{name: "missing value separator", entries: []string{"team"}, wantErr: `invalid METRICS_LABELS entry "team"`},
+		{name: "invalid name", entries: []string{"team-name=payments"}, wantErr: "invalid METRICS_LABELS entry"},
+		{name: "reserved name", entries: []string{"__name__=payments"}, wantErr: "invalid METRICS_LABELS entry"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			labels, err := ParseLabels(tt.entries)
+
+			// Assert
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, labels)
+		})
+	}
+}
+
+func TestHandler_Labels(t *testing.T) {
+	// Arrange
+	gauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "labels_test_shards", Help: "Test gauge"}, []string{"region"})
+	prometheus.MustRegister(gauge)
+	t.Cleanup(func() { prometheus.Unregister(gauge) })
+	gauge.WithLabelValues("us-east-1").Set(3)
+	handler := Handler(map[string]string{"team": "payments", "region": "eu-west-1"})
+	rec := httptest.NewRecorder()
+
+	// Act
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	// Assert
+	assert.Contains(t, rec.Body.String(), `labels_test_shards{region="us-east-1",team="payments"} 3`, "labels of the metric take precedence")
+}
+
+func TestHandler_LabelsApplyToAllMetrics(t *testing.T) {
+	// Arrange
+	netgex := Register(prometheus.NewCounter(prometheus.CounterOpts{Name: "labels_test_netgex_total", Help: "Test counter"}))
+	app := prometheus.NewCounter(prometheus.CounterOpts{Name: "labels_test_app_total", Help: "Test counter"})
+	thirdParty := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "labels_test_library_total", Help: "Test counter", ConstLabels: prometheus.Labels{"team": "platform"},
+	})
+	prometheus.MustRegister(app, thirdParty)
+	t.Cleanup(func() {
+		prometheus.Unregister(netgex)
+		prometheus.Unregister(app)
+		prometheus.Unregister(thirdParty)
+	})
+	handler := Handler(map[string]string{"team": "payments"})
+	rec := httptest.NewRecorder()
+
+	// Act
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	// Assert
+	body := rec.Body.String()
+	assert.Contains(t, body, `labels_test_netgex_total{team="payments"} 0`)
+	assert.Contains(t, body, `labels_test_app_total{team="payments"} 0`, "application metrics get the labels")
+	assert.Contains(t, body, `labels_test_library_total{team="platform"} 0`, "labels set by a collector are kept")
+}
@@ -0,0 +1,23 @@
+package metrics
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Register registers c with the default registry, returning the collector already
+// registered under the same descriptors if there is one, so servers created more than
+// once in a process share their collectors instead of panicking
+func Register[C prometheus.Collector](c C) C {
+	if err := prometheus.Register(c); err != nil {
+		var already prometheus.AlreadyRegisteredError
+		if errors.As(err, &already) {
+			if existing, ok := already.ExistingCollector.(C); ok {
+				return existing
+			}
+		}
+		panic(err)
+	}
+	return c
+}
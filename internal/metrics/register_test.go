@@ -0,0 +1,26 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegister(t *testing.T) {
+	// Arrange
+	newCounter := func() prometheus.Counter {
+		return prometheus.NewCounter(prometheus.CounterOpts{Name: "register_test_total", Help: "Test counter"})
+	}
+	first := Register(newCounter())
+	t.Cleanup(func() { prometheus.Unregister(first) })
+
+	// Act
+	second := Register(newCounter())
+
+	// Assert
+	assert.Same(t, first, second, "registering twice returns the registered collector")
+	assert.Panics(t, func() {
+		Register(prometheus.NewGauge(prometheus.GaugeOpts{Name: "register_test_total", Help: "Other help"}))
+	}, "inconsistent collectors still panic")
+}
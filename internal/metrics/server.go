@@ -7,46 +7,99 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/legrch/netgex/internal/listener"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
+// Option is a function that configures a Server
+type Option func(*Server)
+
 // Server represents a server for exposing Prometheus metrics
 type Server struct {
 	logger       *slog.Logger
 	server       *http.Server
 	closeTimeout time.Duration
+	listen       listener.Options
+	path         string
+	labels       map[string]string
 }
 
-// NewServer creates a new metrics server
-func NewServer(logger *slog.Logger, address string, closeTimeout time.Duration) *Server {
-	mux := http.NewServeMux()
-	mux.Handle("/metrics", promhttp.Handler())
+// NewServer creates a new metrics server. With an empty address no listener is started,
+// so metrics are only served where Handler is mounted.
+func NewServer(logger *slog.Logger, address string, closeTimeout time.Duration, opts ...Option) *Server {
+	m := &Server{
+		logger:       logger,
+		closeTimeout: closeTimeout,
+		path:         "/metrics",
+	}
+
+	// Apply options
+	for _, opt := range opts {
+		opt(m)
+	}
 
-	server := &http.Server{
+	mux := http.NewServeMux()
+	mux.Handle(m.path, Handler(m.labels))
+	m.server = &http.Server{
 		Addr:              address,
 		Handler:           mux,
 		ReadHeaderTimeout: 5 * time.Second,
 	}
 
-	return &Server{
-		logger:       logger,
-		server:       server,
-		closeTimeout: closeTimeout,
+	return m
+}
+
+// WithListenOptions sets the socket options of the listener, SO_REUSEPORT included
+func WithListenOptions(opts listener.Options) Option {
+	return func(m *Server) {
+		m.listen = opts
+	}
+}
+
+// WithPath sets the path metrics are served at, /metrics by default
+func WithPath(path string) Option {
+	return func(m *Server) {
+		m.path = path
+	}
+}
+
+// WithLabels adds constant labels to every served metric, as Handler does
+func WithLabels(labels map[string]string) Option {
+	return func(m *Server) {
+		m.labels = labels
 	}
 }
 
 // PreRun prepares the metrics server
 func (*Server) PreRun(_ context.Context) error {
-	// Register application metrics
-	RegisterAppMetrics()
 	return nil
 }
 
+// Handler returns the handler exposing the Prometheus metrics, adding the constant
+// labels to every metric of the default registry: those of netgex, of the application
+// and of third-party collectors alike, as OTLP resource attributes apply to every
+// exported metric. A label a metric already has keeps its value.
+func Handler(labels map[string]string) http.Handler {
+	if len(labels) == 0 {
+		return promhttp.Handler()
+	}
+	gatherer := labeledGatherer{gatherer: prometheus.DefaultGatherer, labels: labels}
+	return promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer, promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}))
+}
+
 // Run starts the metrics server
-func (m *Server) Run(_ context.Context) error {
+func (m *Server) Run(ctx context.Context) error {
+	if m.server.Addr == "" {
+		return nil
+	}
+
 	m.logger.Info("starting metrics server", "address", m.server.Addr)
-	if err := m.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	lis, err := listener.Listen(ctx, m.server.Addr, m.listen)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	if err := m.server.Serve(lis); err != nil && err != http.ErrServerClosed {
 		return fmt.Errorf("metrics server error: %w", err)
 	}
 	return nil
@@ -66,24 +119,50 @@ func (m *Server) Shutdown(ctx context.Context) error {
 	return nil
 }
 
-// AppVersion is a gauge for tracking application version
-var AppVersion = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-	Namespace: "app",
-	Name:      "version",
-	Help:      "Application version",
-}, []string{"version"})
+// AppMetrics are the gauges describing the application
+type AppMetrics struct {
+	// Version is set to 1 for the running version
+	Version *prometheus.GaugeVec
+	// MemoryLimit is the effective runtime memory limit (GOMEMLIMIT) in bytes
+	MemoryLimit prometheus.Gauge
+	// GCPercent is the effective GC target percentage (GOGC), -1 when disabled
+	GCPercent prometheus.Gauge
+}
 
-// RegisterAppMetrics registers application metrics with Prometheus
-func RegisterAppMetrics() {
-	prometheus.MustRegister(AppVersion)
+// App returns the application metrics of the namespace, registering them unless already
+// registered
+func App(namespace string) *AppMetrics {
+	m := &AppMetrics{
+		Version: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "version",
+			Help:      "Application version",
+		}, []string{"version"}),
+		MemoryLimit: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "memory_limit_bytes",
+			Help:      "Effective runtime memory limit (GOMEMLIMIT) in bytes",
+		}),
+		GCPercent: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "gc_percent",
+			Help:      "Effective GC target percentage (GOGC), -1 when disabled",
+		}),
+	}
+	m.Version = Register(m.Version)
+	m.MemoryLimit = Register(m.MemoryLimit)
+	m.GCPercent = Register(m.GCPercent)
+	return m
 }
 
-// UnregisterAppMetrics unregisters application metrics from Prometheus
-func UnregisterAppMetrics() {
-	prometheus.Unregister(AppVersion)
+// SetVersion sets the application version metric
+func (m *AppMetrics) SetVersion(version string) {
+	m.Version.Reset()
+	m.Version.WithLabelValues(version).Set(1)
 }
 
-// SetAppVersion sets the application version metric
-func SetAppVersion(version string) {
-	AppVersion.WithLabelValues(version).Set(1)
+// SetRuntimeSettings sets the runtime memory limit and GC percent metrics
+func (m *AppMetrics) SetRuntimeSettings(memoryLimit int64, gcPercent int) {
+	m.MemoryLimit.Set(float64(memoryLimit))
+	m.GCPercent.Set(float64(gcPercent))
 }
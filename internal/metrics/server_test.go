@@ -3,6 +3,8 @@ package metrics
 import (
 	"context"
 	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 	"time"
@@ -34,17 +36,27 @@ func TestServer_PreRun(t *testing.T) {
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 	server := NewServer(logger, ":9091", 5*time.Second)
 
-	// Unregister metrics to avoid test pollution
-	prometheus.Unregister(AppVersion)
-
 	// Act
 	err := server.PreRun(context.Background())
 
 	// Assert
 	assert.NoError(t, err)
+}
+
+func TestNewServer_WithPath(t *testing.T) {
+	// Arrange
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	server := NewServer(logger, ":9091", 5*time.Second, WithPath("/internal/metrics"))
+
+	// Act
+	served := httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(served, httptest.NewRequest(http.MethodGet, "/internal/metrics", nil))
+	defaultPath := httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(defaultPath, httptest.NewRequest(http.MethodGet, "/metrics", nil))
 
-	// Clean up
-	prometheus.Unregister(AppVersion)
+	// Assert
+	assert.Equal(t, http.StatusOK, served.Code)
+	assert.Equal(t, http.StatusNotFound, defaultPath.Code)
 }
 
 func TestServer_Shutdown(t *testing.T) {
@@ -60,38 +72,19 @@ func TestServer_Shutdown(t *testing.T) {
 	assert.NoError(t, err)
 }
 
-func TestSetAppVersion(t *testing.T) {
-	// Arrange - unregister to avoid test pollution
-	prometheus.Unregister(AppVersion)
-	prometheus.MustRegister(AppVersion)
-
+func TestApp(t *testing.T) {
 	// Act
-	SetAppVersion("1.0.0")
+	app := App("orders")
+	app.SetVersion("1.0.0")
+	app.SetVersion("1.1.0")
+	app.SetRuntimeSettings(512<<20, 50)
 
 	// Assert
-	count, err := testutil.GatherAndCount(prometheus.DefaultGatherer, "app_version")
+	assert.Same(t, app.Version, App("orders").Version, "the metrics of a namespace are registered once")
+	count, err := testutil.GatherAndCount(prometheus.DefaultGatherer, "orders_version")
 	assert.NoError(t, err)
-	assert.Equal(t, 1, count, "app_version metric should be registered and have a value")
-
-	// Clean up
-	prometheus.Unregister(AppVersion)
-}
-
-func TestRegisterAndUnregisterAppMetrics(t *testing.T) {
-	// Ensure metric is unregistered at the start
-	prometheus.Unregister(AppVersion)
-
-	// Act - Register
-	RegisterAppMetrics()
-
-	// Assert - Check if metric exists after registration
-	_, err := testutil.GatherAndCount(prometheus.DefaultGatherer, "app_version")
-	assert.NoError(t, err, "Metric should be registered")
-
-	// Act - Unregister
-	UnregisterAppMetrics()
-
-	// Assert - Check metric no longer exists
-	count, _ := testutil.GatherAndCount(prometheus.DefaultGatherer, "app_version")
-	assert.Equal(t, 0, count, "Metric should not be registered")
+	assert.Equal(t, 1, count, "only the current version is reported")
+	assert.InDelta(t, 1, testutil.ToFloat64(app.Version.WithLabelValues("1.1.0")), 0)
+	assert.InDelta(t, float64(512<<20), testutil.ToFloat64(app.MemoryLimit), 0)
+	assert.InDelta(t, float64(50), testutil.ToFloat64(app.GCPercent), 0)
 }
@@ -0,0 +1,83 @@
+package pprof
+
+import (
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// restrict wraps the handler with the configured network and authentication checks
+func (p *Server) restrict(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !p.allowedAddress(r.RemoteAddr) {
+			p.logger.Warn("pprof request rejected", "remote", r.RemoteAddr, "reason", "address not allowed")
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		if !p.authorized(r) {
+			if p.basicAuthUser != "" {
+				w.Header().Set("WWW-Authenticate", `Basic realm="pprof"`)
+			}
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allowedAddress reports whether the remote address is within the allowed networks.
+// All addresses are allowed when no networks are configured.
+func (p *Server) allowedAddress(remoteAddr string) bool {
+	if len(p.allowedNets) == 0 {
+		return true
+	}
+
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	for _, ipNet := range p.allowedNets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// authorized reports whether the request carries valid credentials.
+// Requests are authorized when no credentials are configured, otherwise
+// either a matching bearer token or matching basic auth is accepted.
+func (p *Server) authorized(r *http.Request) bool {
+	if p.authToken == "" && p.basicAuthUser == "" {
+		return true
+	}
+
+	if p.authToken != "" {
+		if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && secureEqual(token, p.authToken) {
+			return true
+		}
+	}
+
+	if p.basicAuthUser != "" {
+		user, password, ok := r.BasicAuth()
+		if ok && secureEqual(user, p.basicAuthUser) && secureEqual(password, p.basicAuthPassword) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// secureEqual compares two strings in constant time
+func secureEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
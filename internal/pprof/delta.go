@@ -0,0 +1,79 @@
+package pprof
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/grafana/pyroscope-go/godeltaprof"
+)
+
+// defaultDeltaSeconds is the sampling window used when no seconds parameter is given
+const defaultDeltaSeconds = 30
+
+// deltaProfiler produces a profile containing only the changes since its previous call
+type deltaProfiler interface {
+	Profile(w io.Writer) error
+}
+
+// deltaHandler serves the difference between two profiles taken a sampling window apart.
+// A fresh profiler is created per request so concurrent requests don't share baselines.
+func deltaHandler(name string, newProfiler func() deltaProfiler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		seconds := defaultDeltaSeconds
+		if v := r.FormValue("seconds"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				http.Error(w, "invalid seconds parameter", http.StatusBadRequest)
+				return
+			}
+			seconds = n
+		}
+
+		gc, _ := strconv.Atoi(r.FormValue("gc"))
+		profiler := newProfiler()
+
+		// Take the baseline; its cumulative content is discarded
+		if gc > 0 {
+			runtime.GC()
+		}
+		if err := profiler.Profile(io.Discard); err != nil {
+			http.Error(w, fmt.Sprintf("failed to capture baseline %s profile: %v", name, err), http.StatusInternalServerError)
+			return
+		}
+
+		timer := time.NewTimer(time.Duration(seconds) * time.Second)
+		defer timer.Stop()
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-timer.C:
+		}
+
+		if gc > 0 {
+			runtime.GC()
+		}
+
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="delta_%s.pprof.gz"`, name))
+		_ = profiler.Profile(w)
+	}
+}
+
+// registerDeltaHandlers registers the delta heap, block and mutex profile endpoints
+func registerDeltaHandlers(mux *http.ServeMux) {
+	mux.HandleFunc("/debug/pprof/delta_heap", deltaHandler("heap", func() deltaProfiler {
+		return godeltaprof.NewHeapProfiler()
+	}))
+	mux.HandleFunc("/debug/pprof/delta_block", deltaHandler("block", func() deltaProfiler {
+		return godeltaprof.NewBlockProfiler()
+	}))
+	mux.HandleFunc("/debug/pprof/delta_mutex", deltaHandler("mutex", func() deltaProfiler {
+		return godeltaprof.NewMutexProfiler()
+	}))
+}
@@ -0,0 +1,143 @@
+package pprof
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"runtime/pprof"
+	"time"
+)
+
+// Heap dump formats
+const (
+	heapDumpFormatProfile = "profile"
+	heapDumpFormatDump    = "dump"
+)
+
+// errHeapDumpTooLarge is returned when a heap profile exceeds the heap dump limit
+var errHeapDumpTooLarge = errors.New("heap profile exceeds heap dump limit")
+
+// heapDumpResult describes a written heap dump
+type heapDumpResult struct {
+	Format string `json:"format"`
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+}
+
+// handleHeapDump writes a heap profile or a full heap dump to the configured directory.
+// Only one dump is written at a time. Full dumps are refused when the heap is larger
+// than the configured maximum size, and profiles are discarded once they exceed it.
+func (p *Server) handleHeapDump(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	format := r.FormValue("format")
+	if format == "" {
+		format = heapDumpFormatProfile
+	}
+	if format != heapDumpFormatProfile && format != heapDumpFormatDump {
+		http.Error(w, fmt.Sprintf("unsupported heap dump format: %q", format), http.StatusBadRequest)
+		return
+	}
+
+	if !p.heapDumpMu.TryLock() {
+		http.Error(w, "heap dump already in progress", http.StatusConflict)
+		return
+	}
+	defer p.heapDumpMu.Unlock()
+
+	if format == heapDumpFormatDump && p.heapDumpMaxBytes > 0 {
+		var stats runtime.MemStats
+		runtime.ReadMemStats(&stats)
+		if int64(stats.HeapSys) > p.heapDumpMaxBytes { //nolint:gosec // heap size fits in int64
+			http.Error(w, fmt.Sprintf("heap size %d exceeds heap dump limit %d", stats.HeapSys, p.heapDumpMaxBytes),
+				http.StatusRequestEntityTooLarge)
+			return
+		}
+	}
+
+	result, err := p.writeHeapDump(format)
+	if errors.Is(err, errHeapDumpTooLarge) {
+		http.Error(w, fmt.Sprintf("%s %d", err, p.heapDumpMaxBytes), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		p.logger.Error("failed to write heap dump", "format", format, "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	p.logger.Info("heap dump written", "format", result.Format, "path", result.Path, "size", result.Size)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+// writeHeapDump writes the requested heap dump format to a new file in the dump directory
+func (p *Server) writeHeapDump(format string) (*heapDumpResult, error) {
+	if err := os.MkdirAll(p.heapDumpDir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create heap dump directory: %w", err)
+	}
+
+	ext := "pb.gz"
+	if format == heapDumpFormatDump {
+		ext = "dump"
+	}
+	name := fmt.Sprintf("heap-%s.%s", time.Now().UTC().Format("20060102T150405.000000000"), ext)
+	path := filepath.Join(p.heapDumpDir, name)
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create heap dump file: %w", err)
+	}
+	defer f.Close()
+
+	switch format {
+	case heapDumpFormatDump:
+		debug.WriteHeapDump(f.Fd())
+	default:
+		runtime.GC()
+		var out io.Writer = f
+		if p.heapDumpMaxBytes > 0 {
+			out = &limitedWriter{w: f, remaining: p.heapDumpMaxBytes}
+		}
+		if err := pprof.Lookup("heap").WriteTo(out, 0); err != nil {
+			_ = os.Remove(path)
+			if errors.Is(err, errHeapDumpTooLarge) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("failed to write heap profile: %w", err)
+		}
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat heap dump file: %w", err)
+	}
+
+	return &heapDumpResult{Format: format, Path: path, Size: info.Size()}, nil
+}
+
+// limitedWriter fails with errHeapDumpTooLarge instead of writing past remaining bytes
+type limitedWriter struct {
+	w         io.Writer
+	remaining int64
+}
+
+func (l *limitedWriter) Write(b []byte) (int, error) {
+	if int64(len(b)) > l.remaining {
+		return 0, errHeapDumpTooLarge
+	}
+	n, err := l.w.Write(b)
+	l.remaining -= int64(n)
+	return n, err
+}
@@ -2,52 +2,260 @@ package pprof
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
-
-	// #nosec G108 - pprof endpoints are intentionally exposed for debugging
-	_ "net/http/pprof" // Register pprof handlers
+	"net/http/pprof"
+	"runtime"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/felixge/fgprof"
+
+	"github.com/legrch/netgex/internal/listener"
 )
 
+// Option is a function that configures a Server
+type Option func(*Server)
+
 // Server represents a server for exposing pprof profiling endpoints
 type Server struct {
-	logger *slog.Logger
-	server *http.Server
+	logger            *slog.Logger
+	server            *http.Server
+	authToken         string
+	basicAuthUser     string
+	basicAuthPassword string
+	allowedCIDRs      []string
+	allowedNets       []*net.IPNet
+	fgprofEnabled     bool
+	blockProfileRate  int
+	mutexProfileFrac  int
+	heapDumpDir       string
+	heapDumpMaxBytes  int64
+	heapDumpMu        sync.Mutex
+	tracer            tracer
+	listen            listener.Options
 }
 
-// NewServer creates a new pprof server
-func NewServer(logger *slog.Logger, address string) *Server {
-	return &Server{
+// NewServer creates a new pprof server. With an empty address no listener is started,
+// so the endpoints are only served where Handler is mounted.
+func NewServer(logger *slog.Logger, address string, opts ...Option) *Server {
+	p := &Server{
 		logger: logger,
-		server: &http.Server{
-			Addr:              address,
-			Handler:           http.DefaultServeMux, // DefaultServeMux has pprof handlers registered
-			ReadHeaderTimeout: 5 * time.Second,      // Prevent Slowloris attacks
-		},
+	}
+
+	// Apply options
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	p.server = &http.Server{
+		Addr:              address,
+		Handler:           p.restrict(p.newMux()),
+		ReadHeaderTimeout: 5 * time.Second, // Prevent Slowloris attacks
+	}
+
+	return p
+}
+
+// WithAuthToken requires requests to carry an "Authorization: Bearer <token>" header
+func WithAuthToken(token string) Option {
+	return func(p *Server) {
+		p.authToken = token
 	}
 }
 
+// WithBasicAuth requires requests to carry the given HTTP basic auth credentials
+func WithBasicAuth(user, password string) Option {
+	return func(p *Server) {
+		p.basicAuthUser = user
+		p.basicAuthPassword = password
+	}
+}
+
+// WithAllowedCIDRs restricts access to clients whose address is in one of the given
+// CIDR ranges. Plain IP addresses are accepted as single-host ranges.
+func WithAllowedCIDRs(cidrs ...string) Option {
+	return func(p *Server) {
+		p.allowedCIDRs = append(p.allowedCIDRs, cidrs...)
+	}
+}
+
+// WithFgprof enables the /debug/fgprof wall-clock profiling endpoint
+func WithFgprof(enabled bool) Option {
+	return func(p *Server) {
+		p.fgprofEnabled = enabled
+	}
+}
+
+// WithBlockProfileRate sets the runtime block profile rate applied in PreRun.
+// A rate of 0 leaves the runtime setting untouched.
+func WithBlockProfileRate(rate int) Option {
+	return func(p *Server) {
+		p.blockProfileRate = rate
+	}
+}
+
+// WithMutexProfileFraction sets the runtime mutex profile fraction applied in PreRun.
+// A fraction of 0 leaves the runtime setting untouched.
+func WithMutexProfileFraction(fraction int) Option {
+	return func(p *Server) {
+		p.mutexProfileFrac = fraction
+	}
+}
+
+// WithHeapDump enables the /debug/heapdump endpoint writing dumps to dir.
+// Full heap dumps are refused when the heap exceeds maxBytes, and heap profiles when
+// they grow past it (0 disables the limit).
+func WithHeapDump(dir string, maxBytes int64) Option {
+	return func(p *Server) {
+		p.heapDumpDir = dir
+		p.heapDumpMaxBytes = maxBytes
+	}
+}
+
+// WithListenOptions sets the socket options of the listener, SO_REUSEPORT included
+func WithListenOptions(opts listener.Options) Option {
+	return func(p *Server) {
+		p.listen = opts
+	}
+}
+
+// newMux creates a mux with the standard pprof handlers and any optional endpoints registered
+func (p *Server) newMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	// Execution traces spanning separate start and stop requests
+	mux.HandleFunc("/debug/trace/start", p.tracer.handleStart)
+	mux.HandleFunc("/debug/trace/stop", p.tracer.handleStop)
+
+	// Delta variants report what changed over a sampling window instead of cumulative totals
+	registerDeltaHandlers(mux)
+
+	// fgprof samples all goroutines, so profiles include off-CPU time such as IO waits
+	if p.fgprofEnabled {
+		mux.Handle("/debug/fgprof", fgprof.Handler())
+	}
+
+	if p.heapDumpDir != "" {
+		mux.HandleFunc("/debug/heapdump", p.handleHeapDump)
+	}
+
+	return mux
+}
+
 // PreRun prepares the pprof server
-func (*Server) PreRun(_ context.Context) error {
+func (p *Server) PreRun(_ context.Context) error {
+	nets, err := parseCIDRs(p.allowedCIDRs)
+	if err != nil {
+		return err
+	}
+	p.allowedNets = nets
+
+	// Enable contention profiling if requested
+	if p.blockProfileRate > 0 {
+		runtime.SetBlockProfileRate(p.blockProfileRate)
+		p.logger.Info("block profiling enabled", "rate", p.blockProfileRate)
+	}
+	if p.mutexProfileFrac > 0 {
+		runtime.SetMutexProfileFraction(p.mutexProfileFrac)
+		p.logger.Info("mutex profiling enabled", "fraction", p.mutexProfileFrac)
+	}
+
 	return nil
 }
 
-// Run starts the pprof server
-func (p *Server) Run(_ context.Context) error {
+// Handler returns the pprof endpoints with the configured access restrictions applied.
+// Profile rates and allowed networks take effect once PreRun has been called.
+func (p *Server) Handler() http.Handler {
+	return p.server.Handler
+}
+
+// Run starts the pprof server and stops it when the context is canceled
+func (p *Server) Run(ctx context.Context) error {
+	if p.server.Addr == "" {
+		return nil
+	}
+
 	p.logger.Info("starting pprof server", "address", p.server.Addr)
-	if err := p.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		return fmt.Errorf("pprof server error: %w", err)
+
+	lis, err := listener.Listen(ctx, p.server.Addr, p.listen)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- p.server.Serve(lis)
+	}()
+
+	select {
+	case <-ctx.Done():
+		// Long-running profile requests would otherwise hold the server open
+		if err := p.server.Close(); err != nil {
+			return fmt.Errorf("pprof server close error: %w", err)
+		}
+		return nil
+	case err := <-errCh:
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("pprof server error: %w", err)
+		}
+		return nil
 	}
-	return nil
 }
 
-// Shutdown gracefully stops the pprof server
+// Shutdown gracefully stops the pprof server, closing remaining connections
+// if in-flight profile requests outlive the context, and stops a running execution trace
 func (p *Server) Shutdown(ctx context.Context) error {
 	p.logger.Info("shutting down pprof server")
+	defer p.tracer.discard()
 	if err := p.server.Shutdown(ctx); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+			p.logger.Warn("pprof server shutdown timed out, forcing close")
+			_ = p.server.Close()
+			return nil
+		}
 		return fmt.Errorf("pprof server shutdown error: %w", err)
 	}
 	return nil
 }
+
+// parseCIDRs converts the configured address ranges into networks
+func parseCIDRs(cidrs []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+
+		if !strings.Contains(cidr, "/") {
+			ip := net.ParseIP(cidr)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid pprof allowed address: %q", cidr)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 8 * net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pprof allowed CIDR %q: %w", cidr, err)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
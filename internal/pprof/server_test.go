@@ -5,7 +5,10 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"runtime"
+	"runtime/trace"
 	"testing"
 	"time"
 
@@ -98,3 +101,295 @@ func TestServer_Integration(t *testing.T) {
 	err = server.Shutdown(ctx)
 	assert.NoError(t, err)
 }
+
+func TestServer_Restrictions(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []Option
+		remoteAddr string
+		setup      func(*http.Request)
+		wantStatus int
+	}{
+		{
+			name:       "no restrictions",
+			remoteAddr: "203.0.113.10:1234",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "address outside allowed CIDRs",
+			opts:       []Option{WithAllowedCIDRs("10.0.0.0/8", "127.0.0.1")},
+			remoteAddr: "203.0.113.10:1234",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "address inside allowed CIDRs",
+			opts:       []Option{WithAllowedCIDRs("10.0.0.0/8", "127.0.0.1")},
+			remoteAddr: "127.0.0.1:1234",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "missing bearer token",
+			opts:       []Option{WithAuthToken("secret")},
+			remoteAddr: "127.0.0.1:1234",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "valid bearer token",
+			opts:       []Option{WithAuthToken("secret")},
+			remoteAddr: "127.0.0.1:1234",
+			setup: func(r *http.Request) {
+				r.Header.Set("Authorization", "Bearer secret")
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid basic auth",
+			opts:       []Option{WithBasicAuth("admin", "pass")},
+			remoteAddr: "127.0.0.1:1234",
+			setup: func(r *http.Request) {
+				r.SetBasicAuth("admin", "wrong")
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "valid basic auth",
+			opts:       []Option{WithBasicAuth("admin", "pass")},
+			remoteAddr: "127.0.0.1:1234",
+			setup: func(r *http.Request) {
+				r.SetBasicAuth("admin", "pass")
+			},
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+			server := NewServer(logger, ":6060", tt.opts...)
+			require.NoError(t, server.PreRun(context.Background()))
+
+			req := httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.setup != nil {
+				tt.setup(req)
+			}
+			rec := httptest.NewRecorder()
+
+			// Act
+			server.server.Handler.ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
+
+func TestServer_PreRun_InvalidCIDR(t *testing.T) {
+	// Arrange
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	server := NewServer(logger, ":6060", WithAllowedCIDRs("not-a-cidr/99"))
+
+	// Act
+	err := server.PreRun(context.Background())
+
+	// Assert
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid pprof allowed CIDR")
+}
+
+func TestServer_Fgprof(t *testing.T) {
+	tests := []struct {
+		name       string
+		enabled    bool
+		wantStatus int
+	}{
+		{name: "disabled by default", enabled: false, wantStatus: http.StatusNotFound},
+		{name: "enabled", enabled: true, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+			server := NewServer(logger, ":6060", WithFgprof(tt.enabled))
+			require.NoError(t, server.PreRun(context.Background()))
+
+			req := httptest.NewRequest(http.MethodGet, "/debug/fgprof?seconds=1", nil)
+			rec := httptest.NewRecorder()
+
+			// Act
+			server.server.Handler.ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
+
+func TestServer_DeltaProfiles(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+	}{
+		{name: "delta heap", path: "/debug/pprof/delta_heap?seconds=1", wantStatus: http.StatusOK},
+		{name: "delta block", path: "/debug/pprof/delta_block?seconds=1", wantStatus: http.StatusOK},
+		{name: "delta mutex", path: "/debug/pprof/delta_mutex?seconds=1", wantStatus: http.StatusOK},
+		{name: "invalid seconds", path: "/debug/pprof/delta_heap?seconds=-1", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+			server := NewServer(logger, ":6060")
+			require.NoError(t, server.PreRun(context.Background()))
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			rec := httptest.NewRecorder()
+
+			// Act
+			server.server.Handler.ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantStatus == http.StatusOK {
+				assert.NotEmpty(t, rec.Body.Bytes())
+			}
+		})
+	}
+}
+
+func TestServer_PreRun_ProfileRates(t *testing.T) {
+	// Arrange
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	server := NewServer(logger, ":6060", WithBlockProfileRate(1), WithMutexProfileFraction(5))
+	prevMutex := runtime.SetMutexProfileFraction(-1)
+	t.Cleanup(func() {
+		runtime.SetBlockProfileRate(0)
+		runtime.SetMutexProfileFraction(prevMutex)
+	})
+
+	// Act
+	err := server.PreRun(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 5, runtime.SetMutexProfileFraction(-1))
+}
+
+func TestServer_Run_ContextCanceled(t *testing.T) {
+	// Arrange
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	server := NewServer(logger, "127.0.0.1:0")
+	require.NoError(t, server.PreRun(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Run(ctx)
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	// Act
+	cancel()
+
+	// Assert
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("pprof server did not stop after context cancellation")
+	}
+}
+
+func TestServer_HeapDump(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		query      string
+		maxBytes   int64
+		wantStatus int
+	}{
+		{name: "heap profile", method: http.MethodPost, wantStatus: http.StatusOK},
+		{name: "heap profile over limit", method: http.MethodPost, maxBytes: 1, wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "full dump over limit", method: http.MethodPost, query: "?format=dump", maxBytes: 1, wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "unsupported format", method: http.MethodPost, query: "?format=core", wantStatus: http.StatusBadRequest},
+		{name: "method not allowed", method: http.MethodGet, wantStatus: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+			dir := t.TempDir()
+			server := NewServer(logger, ":6060", WithHeapDump(dir, tt.maxBytes))
+			require.NoError(t, server.PreRun(context.Background()))
+
+			req := httptest.NewRequest(tt.method, "/debug/heapdump"+tt.query, nil)
+			rec := httptest.NewRecorder()
+
+			// Act
+			server.server.Handler.ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantStatus == http.StatusOK {
+				entries, err := os.ReadDir(dir)
+				require.NoError(t, err)
+				assert.Len(t, entries, 1)
+				assert.Contains(t, rec.Body.String(), entries[0].Name())
+			}
+			if tt.wantStatus == http.StatusRequestEntityTooLarge {
+				entries, err := os.ReadDir(dir)
+				require.NoError(t, err)
+				assert.Empty(t, entries, "nothing is left behind")
+			}
+		})
+	}
+}
+
+func TestServer_ExecutionTrace(t *testing.T) {
+	// Arrange
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	server := NewServer(logger, ":6060")
+	require.NoError(t, server.PreRun(context.Background()))
+	handler := server.server.Handler
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, nil))
+		return rec
+	}
+
+	// Act & Assert - stopping without a trace fails
+	assert.Equal(t, http.StatusConflict, serve("/debug/trace/stop").Code)
+
+	// Act & Assert - start, reject a second start, then stop and stream
+	assert.Equal(t, http.StatusAccepted, serve("/debug/trace/start?seconds=10").Code)
+	assert.Equal(t, http.StatusConflict, serve("/debug/trace/start").Code)
+
+	rec := serve("/debug/trace/stop")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotEmpty(t, rec.Body.Bytes())
+}
+
+func TestServer_Shutdown_StopsExecutionTrace(t *testing.T) {
+	// Arrange
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	server := NewServer(logger, ":6060")
+	require.NoError(t, server.PreRun(context.Background()))
+	rec := httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/debug/trace/start", nil))
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	require.True(t, trace.IsEnabled())
+
+	// Act
+	err := server.Shutdown(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.False(t, trace.IsEnabled(), "the trace stops with the server")
+	assert.Nil(t, server.tracer.buf, "the captured data is dropped")
+}
@@ -0,0 +1,112 @@
+package pprof
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"runtime/trace"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// maxTraceDuration bounds how long a started trace runs before it stops automatically
+const maxTraceDuration = 5 * time.Minute
+
+// tracer manages an execution trace started and stopped through separate requests
+type tracer struct {
+	mu      sync.Mutex
+	running bool
+	buf     *bytes.Buffer
+	timer   *time.Timer
+}
+
+// handleStart starts an execution trace which stops after the requested number of
+// seconds, or after maxTraceDuration if none is given
+func (t *tracer) handleStart(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	duration := maxTraceDuration
+	if v := r.FormValue("seconds"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			http.Error(w, "invalid seconds parameter", http.StatusBadRequest)
+			return
+		}
+		duration = min(time.Duration(n)*time.Second, maxTraceDuration)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.running {
+		http.Error(w, "execution trace already running", http.StatusConflict)
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	if err := trace.Start(buf); err != nil {
+		http.Error(w, fmt.Sprintf("failed to start execution trace: %v", err), http.StatusConflict)
+		return
+	}
+
+	t.running = true
+	t.buf = buf
+	t.timer = time.AfterFunc(duration, t.stop)
+
+	w.WriteHeader(http.StatusAccepted)
+	_, _ = fmt.Fprintf(w, "execution trace started for %s\n", duration)
+}
+
+// handleStop stops the running execution trace, if any, and streams the captured data
+func (t *tracer) handleStop(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	t.stop()
+
+	t.mu.Lock()
+	buf := t.buf
+	t.buf = nil
+	t.mu.Unlock()
+
+	if buf == nil {
+		http.Error(w, "no execution trace captured", http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", `attachment; filename="trace.out"`)
+	_, _ = buf.WriteTo(w)
+}
+
+// stop ends the running trace while keeping the captured data for handleStop
+func (t *tracer) stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.running {
+		return
+	}
+
+	t.timer.Stop()
+	trace.Stop()
+	t.running = false
+}
+
+// discard stops the running trace and drops the captured data nobody will fetch anymore
+func (t *tracer) discard() {
+	t.stop()
+
+	t.mu.Lock()
+	t.buf = nil
+	t.mu.Unlock()
+}
@@ -0,0 +1,175 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Consul registers services with the local Consul agent. The service carries a TTL
+// check kept passing by Refresh, plus gRPC and HTTP health checks run by the agent.
+type Consul struct {
+	address string
+	token   string
+	client  *http.Client
+
+	mu        sync.Mutex
+	serviceID string
+}
+
+// NewConsul creates a backend for the Consul agent at address (e.g. "http://127.0.0.1:8500").
+// The token may be empty.
+func NewConsul(address, token string) *Consul {
+	return &Consul{
+		address: strings.TrimSuffix(address, "/"),
+		token:   token,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// consulCheck is a check definition of the agent service registration API
+type consulCheck struct {
+	CheckID                        string `json:"CheckID,omitempty"`
+	Name                           string `json:"Name"`
+	TTL                            string `json:"TTL,omitempty"`
+	GRPC                           string `json:"GRPC,omitempty"`
+	HTTP                           string `json:"HTTP,omitempty"`
+	Interval                       string `json:"Interval,omitempty"`
+	DeregisterCriticalServiceAfter string `json:"DeregisterCriticalServiceAfter,omitempty"`
+}
+
+// consulRegistration is the payload of the agent service registration API
+type consulRegistration struct {
+	ID      string            `json:"ID"`
+	Name    string            `json:"Name"`
+	Address string            `json:"Address"`
+	Port    int               `json:"Port"`
+	Tags    []string          `json:"Tags,omitempty"`
+	Meta    map[string]string `json:"Meta,omitempty"`
+	Checks  []consulCheck     `json:"Checks"`
+}
+
+// Register registers the service with the agent
+func (c *Consul) Register(ctx context.Context, service Service, ttl time.Duration) error {
+	meta := map[string]string{}
+	if service.HTTPPort != 0 {
+		meta["http_port"] = strconv.Itoa(service.HTTPPort)
+	}
+	for k, v := range service.Meta {
+		meta[k] = v
+	}
+
+	// Services whose TTL check stays critical are removed by the agent, so crashed
+	// instances don't linger when Deregister never runs
+	checks := []consulCheck{{
+		CheckID:                        ttlCheckID(service.ID),
+		Name:                           "Service TTL",
+		TTL:                            ttl.String(),
+		DeregisterCriticalServiceAfter: (10 * ttl).String(),
+	}}
+	interval := (ttl / 3).String()
+	if service.GRPCHealth && service.GRPCPort != 0 {
+		checks = append(checks, consulCheck{
+			Name:     "gRPC health",
+			GRPC:     net.JoinHostPort(service.Host, strconv.Itoa(service.GRPCPort)),
+			Interval: interval,
+		})
+	}
+	if service.HTTPHealthPath != "" && service.HTTPPort != 0 {
+		checks = append(checks, consulCheck{
+			Name:     "HTTP health",
+			HTTP:     "http://" + net.JoinHostPort(service.Host, strconv.Itoa(service.HTTPPort)) + service.HTTPHealthPath,
+			Interval: interval,
+		})
+	}
+
+	// The service port is the gRPC one, or the HTTP one when gRPC is not reachable
+	port := service.GRPCPort
+	if port == 0 {
+		port = service.HTTPPort
+	}
+	body, err := json.Marshal(consulRegistration{
+		ID:      service.ID,
+		Name:    service.Name,
+		Address: service.Host,
+		Port:    port,
+		Tags:    service.Tags,
+		Meta:    meta,
+		Checks:  checks,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := c.put(ctx, "/v1/agent/service/register", body); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.serviceID = service.ID
+	c.mu.Unlock()
+
+	// Mark the service passing right away instead of waiting for the first refresh
+	return c.Refresh(ctx)
+}
+
+// Refresh marks the TTL check of the registered service as passing
+func (c *Consul) Refresh(ctx context.Context) error {
+	c.mu.Lock()
+	id := c.serviceID
+	c.mu.Unlock()
+	if id == "" {
+		return nil
+	}
+
+	return c.put(ctx, "/v1/agent/check/pass/"+url.PathEscape(ttlCheckID(id)), nil)
+}
+
+// Deregister removes the registered service from the agent
+func (c *Consul) Deregister(ctx context.Context) error {
+	c.mu.Lock()
+	id := c.serviceID
+	c.serviceID = ""
+	c.mu.Unlock()
+	if id == "" {
+		return nil
+	}
+
+	return c.put(ctx, "/v1/agent/service/deregister/"+url.PathEscape(id), nil)
+}
+
+// put sends a PUT request to the agent API
+func (c *Consul) put(ctx context.Context, path string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, c.address+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("X-Consul-Token", c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("consul request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("consul returned status %d for %s", resp.StatusCode, path)
+	}
+	return nil
+}
+
+// ttlCheckID returns the ID of the TTL check registered with a service
+func ttlCheckID(serviceID string) string {
+	return "service:" + serviceID + ":ttl"
+}
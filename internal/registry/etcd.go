@@ -0,0 +1,142 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Etcd registers services as keys bound to a lease using the etcd v3 JSON gateway.
+// The service is stored as JSON at <prefix><name>/<id> and disappears when the
+// lease expires, so crashed instances are removed without deregistration.
+type Etcd struct {
+	endpoint string
+	prefix   string
+	client   *http.Client
+
+	mu      sync.Mutex
+	leaseID string
+}
+
+// NewEtcd creates a backend for the etcd endpoint (e.g. "http://etcd:2379") storing
+// services below prefix (e.g. "/services/")
+func NewEtcd(endpoint, prefix string) *Etcd {
+	return &Etcd{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		prefix:   prefix,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Register grants a lease for ttl and stores the service bound to it
+func (e *Etcd) Register(ctx context.Context, service Service, ttl time.Duration) error {
+	var lease struct {
+		ID string `json:"ID"`
+	}
+	ttlSeconds := int64((ttl + time.Second - 1) / time.Second)
+	if err := e.post(ctx, "/v3/lease/grant", map[string]any{"TTL": strconv.FormatInt(ttlSeconds, 10)}, &lease); err != nil {
+		return err
+	}
+	if lease.ID == "" {
+		return fmt.Errorf("etcd returned no lease ID")
+	}
+
+	value, err := json.Marshal(service)
+	if err != nil {
+		return err
+	}
+	put := map[string]any{
+		"key":   base64.StdEncoding.EncodeToString([]byte(e.prefix + service.Name + "/" + service.ID)),
+		"value": base64.StdEncoding.EncodeToString(value),
+		"lease": lease.ID,
+	}
+	if err := e.post(ctx, "/v3/kv/put", put, nil); err != nil {
+		return err
+	}
+
+	// Revoke the lease of a previous registration so its key doesn't linger until expiry
+	e.mu.Lock()
+	previous := e.leaseID
+	e.leaseID = lease.ID
+	e.mu.Unlock()
+	if previous != "" {
+		_ = e.post(ctx, "/v3/lease/revoke", map[string]any{"ID": previous}, nil)
+	}
+
+	return nil
+}
+
+// Refresh keeps the lease of the registered service alive
+func (e *Etcd) Refresh(ctx context.Context) error {
+	e.mu.Lock()
+	id := e.leaseID
+	e.mu.Unlock()
+	if id == "" {
+		return nil
+	}
+
+	var resp struct {
+		Result struct {
+			TTL string `json:"TTL"`
+		} `json:"result"`
+	}
+	if err := e.post(ctx, "/v3/lease/keepalive", map[string]any{"ID": id}, &resp); err != nil {
+		return err
+	}
+
+	// etcd reports an expired lease with a missing or non-positive TTL
+	if ttl, _ := strconv.ParseInt(resp.Result.TTL, 10, 64); ttl <= 0 {
+		return fmt.Errorf("etcd lease %s expired", id)
+	}
+	return nil
+}
+
+// Deregister revokes the lease, deleting the service key
+func (e *Etcd) Deregister(ctx context.Context) error {
+	e.mu.Lock()
+	id := e.leaseID
+	e.leaseID = ""
+	e.mu.Unlock()
+	if id == "" {
+		return nil
+	}
+
+	return e.post(ctx, "/v3/lease/revoke", map[string]any{"ID": id}, nil)
+}
+
+// post sends a JSON request to the gateway, decoding the response into out when non-nil
+func (e *Etcd) post(ctx context.Context, path string, in, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("etcd request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("etcd returned status %d for %s", resp.StatusCode, path)
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("failed to decode etcd response: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,279 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Service describes a service instance announced to the registry
+type Service struct {
+	ID       string            `json:"id"`
+	Name     string            `json:"name"`
+	Host     string            `json:"host"`
+	GRPCPort int               `json:"grpc_port"`
+	HTTPPort int               `json:"http_port"`
+	Tags     []string          `json:"tags,omitempty"`
+	Meta     map[string]string `json:"meta,omitempty"`
+	// GRPCHealth reports whether the gRPC health service can be used for checks
+	GRPCHealth bool `json:"grpc_health"`
+	// HTTPHealthPath is the HTTP health endpoint used for checks, if any
+	HTTPHealthPath string `json:"http_health_path,omitempty"`
+}
+
+// NewService builds a service from the addresses of the gRPC and HTTP servers.
+// Addresses without a host or with an unspecified one (e.g. ":9090" or "[::]:9090") are
+// advertised as advertiseHost, falling back to the hostname. The ID defaults to
+// "<name>-<host>-<port>", using the gRPC port or else the HTTP one. An empty address
+// leaves the port 0, for gRPC-only services or gRPC not reachable from other hosts.
+func NewService(name, id, advertiseHost, grpcAddress, httpAddress string) (Service, error) {
+	if grpcAddress == "" && httpAddress == "" {
+		return Service{}, fmt.Errorf("no address to advertise")
+	}
+	var grpcHost, httpHost string
+	var grpcPort, httpPort int
+	var err error
+	if grpcAddress != "" {
+		if grpcHost, grpcPort, err = splitAddress(grpcAddress); err != nil {
+			return Service{}, fmt.Errorf("invalid gRPC address: %w", err)
+		}
+	}
+	if httpAddress != "" {
+		if httpHost, httpPort, err = splitAddress(httpAddress); err != nil {
+			return Service{}, fmt.Errorf("invalid HTTP address: %w", err)
+		}
+	}
+
+	host := advertiseHost
+	for _, listenHost := range []string{grpcHost, httpHost} {
+		if host == "" && listenHost != "" && !net.ParseIP(listenHost).IsUnspecified() {
+			host = listenHost
+		}
+	}
+	if host == "" {
+		if host, err = os.Hostname(); err != nil {
+			return Service{}, fmt.Errorf("failed to determine advertise host: %w", err)
+		}
+	}
+
+	if id == "" {
+		port := grpcPort
+		if port == 0 {
+			port = httpPort
+		}
+		id = fmt.Sprintf("%s-%s-%d", name, host, port)
+	}
+
+	return Service{
+		ID:       id,
+		Name:     name,
+		Host:     host,
+		GRPCPort: grpcPort,
+		HTTPPort: httpPort,
+	}, nil
+}
+
+// splitAddress splits a listen address into host and numeric port
+func splitAddress(address string) (string, int, error) {
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", 0, err
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid port %q", portStr)
+	}
+	return host, port, nil
+}
+
+// Backend registers services with a service registry
+type Backend interface {
+	// Register announces the service, expiring it unless refreshed within ttl
+	Register(ctx context.Context, service Service, ttl time.Duration) error
+	// Refresh extends the registration of the last registered service
+	Refresh(ctx context.Context) error
+	// Deregister removes the last registered service
+	Deregister(ctx context.Context) error
+}
+
+// Option is a function that configures a Registrar
+type Option func(*Registrar)
+
+// deregisterTimeout bounds the deregistration when draining starts
+const deregisterTimeout = 5 * time.Second
+
+// Registrar is a process that registers the service once the servers are ready,
+// refreshes its TTL while running and deregisters it when draining starts or on shutdown
+type Registrar struct {
+	logger  *slog.Logger
+	backend Backend
+	service func() (Service, error)
+	ttl     time.Duration
+	ready   <-chan struct{}
+
+	mu         sync.Mutex
+	registered *Service
+	stopped    bool
+	stop       chan struct{}
+}
+
+// NewRegistrar creates a new registrar announcing the service built by service, called
+// once the servers are ready so it can use their bound addresses
+func NewRegistrar(logger *slog.Logger, backend Backend, service func() (Service, error), opts ...Option) *Registrar {
+	r := &Registrar{
+		logger:  logger,
+		backend: backend,
+		service: service,
+		ttl:     15 * time.Second,
+		stop:    make(chan struct{}),
+	}
+
+	// Apply options
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// WithTTL sets how long the registration stays valid without a refresh.
+// The registrar refreshes it three times per TTL.
+func WithTTL(ttl time.Duration) Option {
+	return func(r *Registrar) {
+		r.ttl = ttl
+	}
+}
+
+// WithReady delays the registration until ready is closed, e.g. until the listeners are
+// bound
+func WithReady(ready <-chan struct{}) Option {
+	return func(r *Registrar) {
+		r.ready = ready
+	}
+}
+
+// PreRun prepares the registrar
+func (r *Registrar) PreRun(_ context.Context) error {
+	if r.ttl < time.Second {
+		return fmt.Errorf("registry TTL must be at least 1s, got %s", r.ttl)
+	}
+	return nil
+}
+
+// Run registers the service once ready and keeps the registration alive until the
+// context is canceled or the registrar is drained or shut down
+func (r *Registrar) Run(ctx context.Context) error {
+	if r.ready != nil {
+		select {
+		case <-r.ready:
+		case <-ctx.Done():
+			return nil
+		case <-r.stop:
+			return nil
+		}
+	}
+
+	service, err := r.service()
+	if err != nil {
+		return fmt.Errorf("service registration failed: %w", err)
+	}
+	if err := r.register(ctx, service); err != nil {
+		return fmt.Errorf("service registration failed: %w", err)
+	}
+
+	ticker := time.NewTicker(r.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-r.stop:
+			return nil
+		case <-ticker.C:
+			r.refresh(ctx)
+		}
+	}
+}
+
+// Drain deregisters the service, so clients stop discovering the instance while it
+// still serves the requests they sent before
+func (r *Registrar) Drain() {
+	ctx, cancel := context.WithTimeout(context.Background(), deregisterTimeout)
+	defer cancel()
+	if err := r.deregister(ctx); err != nil {
+		r.logger.Error("service deregistration failed", "error", err)
+	}
+}
+
+// Shutdown deregisters the service unless Drain already did
+func (r *Registrar) Shutdown(ctx context.Context) error {
+	if err := r.deregister(ctx); err != nil {
+		return fmt.Errorf("service deregistration failed: %w", err)
+	}
+	return nil
+}
+
+// register announces the service unless the registrar was stopped
+func (r *Registrar) register(ctx context.Context, service Service) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stopped {
+		return nil
+	}
+
+	r.logger.Info("registering service",
+		"id", service.ID,
+		"name", service.Name,
+		"host", service.Host,
+		"grpc_port", service.GRPCPort,
+		"http_port", service.HTTPPort,
+	)
+	if err := r.backend.Register(ctx, service, r.ttl); err != nil {
+		return err
+	}
+	r.registered = &service
+	return nil
+}
+
+// deregister stops the registrar and removes the registration, once
+func (r *Registrar) deregister(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.stopped {
+		r.stopped = true
+		close(r.stop)
+	}
+	if r.registered == nil {
+		return nil
+	}
+
+	r.logger.Info("deregistering service", "id", r.registered.ID)
+	r.registered = nil
+	return r.backend.Deregister(ctx)
+}
+
+// refresh extends the registration, registering again if the registry lost it
+// (e.g. after an agent restart or an expired lease)
+func (r *Registrar) refresh(ctx context.Context) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.registered == nil {
+		return
+	}
+
+	err := r.backend.Refresh(ctx)
+	if err == nil {
+		return
+	}
+
+	r.logger.Warn("service registration refresh failed, registering again", "id", r.registered.ID, "error", err)
+	if err := r.backend.Register(ctx, *r.registered, r.ttl); err != nil {
+		r.logger.Error("service registration failed", "id", r.registered.ID, "error", err)
+	}
+}
@@ -0,0 +1,322 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewService(t *testing.T) {
+	tests := []struct {
+		name          string
+		id            string
+		advertiseHost string
+		grpcAddress   string
+		httpAddress   string
+		want          Service
+		wantErr       bool
+	}{
+		{
+			name:          "advertise host",
+			advertiseHost: "10.0.0.5",
+			grpcAddress:   ":9090",
+			httpAddress:   ":8080",
+			want:          Service{ID: "svc-10.0.0.5-9090", Name: "svc", Host: "10.0.0.5", GRPCPort: 9090, HTTPPort: 8080},
+		},
+		{
+			name:        "host from listen address",
+			id:          "svc-1",
+			grpcAddress: "10.0.0.6:9090",
+			httpAddress: "10.0.0.6:8080",
+			want:        Service{ID: "svc-1", Name: "svc", Host: "10.0.0.6", GRPCPort: 9090, HTTPPort: 8080},
+		},
+		{
+			name:        "gRPC only",
+			id:          "svc-1",
+			grpcAddress: "10.0.0.6:9090",
+			want:        Service{ID: "svc-1", Name: "svc", Host: "10.0.0.6", GRPCPort: 9090},
+		},
+		{
+			name:        "HTTP only",
+			httpAddress: "10.0.0.6:8080",
+			want:        Service{ID: "svc-10.0.0.6-8080", Name: "svc", Host: "10.0.0.6", HTTPPort: 8080},
+		},
+		{
+			name:    "no address",
+			wantErr: true,
+		},
+		{
+			name:        "invalid address",
+			grpcAddress: "9090",
+			httpAddress: ":8080",
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			service, err := NewService("svc", tt.id, tt.advertiseHost, tt.grpcAddress, tt.httpAddress)
+
+			// Assert
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, service)
+		})
+	}
+}
+
+func TestConsul(t *testing.T) {
+	// Arrange
+	var mu sync.Mutex
+	var paths []string
+	var registration consulRegistration
+	consul := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "token", r.Header.Get("X-Consul-Token"))
+		mu.Lock()
+		defer mu.Unlock()
+		paths = append(paths, r.URL.Path)
+		if r.URL.Path == "/v1/agent/service/register" {
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&registration))
+		}
+	}))
+	defer consul.Close()
+
+	backend := NewConsul(consul.URL, "token")
+	service := Service{ID: "svc-1", Name: "svc", Host: "10.0.0.5", GRPCPort: 9090, HTTPPort: 8080, Tags: []string{"v1"}, GRPCHealth: true, HTTPHealthPath: "/health"}
+
+	// Act
+	require.NoError(t, backend.Register(context.Background(), service, 15*time.Second))
+	require.NoError(t, backend.Refresh(context.Background()))
+	require.NoError(t, backend.Deregister(context.Background()))
+
+	// Assert
+	assert.Equal(t, []string{
+		"/v1/agent/service/register",
+		"/v1/agent/check/pass/service:svc-1:ttl",
+		"/v1/agent/check/pass/service:svc-1:ttl",
+		"/v1/agent/service/deregister/svc-1",
+	}, paths)
+	assert.Equal(t, 9090, registration.Port)
+	assert.Equal(t, "8080", registration.Meta["http_port"])
+	require.Len(t, registration.Checks, 3)
+	assert.Equal(t, "15s", registration.Checks[0].TTL)
+	assert.Equal(t, "10.0.0.5:9090", registration.Checks[1].GRPC)
+	assert.Equal(t, "http://10.0.0.5:8080/health", registration.Checks[2].HTTP)
+}
+
+func TestEtcd(t *testing.T) {
+	// Arrange
+	var mu sync.Mutex
+	keys := map[string]string{}
+	leases := map[string]string{}
+	etcd := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		var body map[string]string
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		switch r.URL.Path {
+		case "/v3/lease/grant":
+			assert.Equal(t, "15", body["TTL"])
+			_, _ = w.Write([]byte(`{"ID":"42","TTL":"15"}`))
+		case "/v3/kv/put":
+			key, _ := base64.StdEncoding.DecodeString(body["key"])
+			value, _ := base64.StdEncoding.DecodeString(body["value"])
+			keys[string(key)] = string(value)
+			leases[string(key)] = body["lease"]
+		case "/v3/lease/keepalive":
+			_, _ = w.Write([]byte(`{"result":{"ID":"42","TTL":"15"}}`))
+		case "/v3/lease/revoke":
+			for key, lease := range leases {
+				if lease == body["ID"] {
+					delete(keys, key)
+				}
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer etcd.Close()
+
+	backend := NewEtcd(etcd.URL, "/services/")
+	service := Service{ID: "svc-1", Name: "svc", Host: "10.0.0.5", GRPCPort: 9090, HTTPPort: 8080}
+
+	// Act
+	require.NoError(t, backend.Register(context.Background(), service, 15*time.Second))
+	require.NoError(t, backend.Refresh(context.Background()))
+
+	// Assert
+	mu.Lock()
+	var stored Service
+	require.NoError(t, json.Unmarshal([]byte(keys["/services/svc/svc-1"]), &stored))
+	assert.Equal(t, service, stored)
+	assert.Equal(t, "42", leases["/services/svc/svc-1"])
+	mu.Unlock()
+
+	// Act - deregistration revokes the lease and removes the key
+	require.NoError(t, backend.Deregister(context.Background()))
+
+	// Assert
+	mu.Lock()
+	assert.Empty(t, keys)
+	mu.Unlock()
+}
+
+// fakeBackend records calls and fails refreshes when refreshErr is set
+type fakeBackend struct {
+	mu          sync.Mutex
+	registers   int
+	refreshes   int
+	deregisters int
+	refreshErr  error
+}
+
+func (f *fakeBackend) Register(context.Context, Service, time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.registers++
+	return nil
+}
+
+func (f *fakeBackend) Refresh(context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.refreshes++
+	return f.refreshErr
+}
+
+func (f *fakeBackend) Deregister(context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.deregisters++
+	return nil
+}
+
+// staticService returns a service builder always building service
+func staticService(service Service) func() (Service, error) {
+	return func() (Service, error) {
+		return service, nil
+	}
+}
+
+func TestRegistrar_PreRun_InvalidTTL(t *testing.T) {
+	// Arrange
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	r := NewRegistrar(logger, &fakeBackend{}, staticService(Service{}), WithTTL(100*time.Millisecond))
+
+	// Act
+	err := r.PreRun(context.Background())
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func TestRegistrar_RunAndShutdown(t *testing.T) {
+	// Arrange
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	backend := &fakeBackend{refreshErr: errors.New("check not found")}
+	r := NewRegistrar(logger, backend, staticService(Service{ID: "svc-1"}), WithTTL(30*time.Millisecond))
+
+	done := make(chan error, 1)
+	go func() {
+		done <- r.Run(context.Background())
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	// Act
+	require.NoError(t, r.Shutdown(context.Background()))
+
+	// Assert
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("registrar did not stop after shutdown")
+	}
+	backend.mu.Lock()
+	defer backend.mu.Unlock()
+	assert.Positive(t, backend.refreshes)
+	assert.Equal(t, 1+backend.refreshes, backend.registers, "failed refreshes register again")
+	assert.Equal(t, 1, backend.deregisters)
+}
+
+func TestRegistrar_RegistersWhenReadyAndDeregistersOnDrain(t *testing.T) {
+	// Arrange
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	backend := &fakeBackend{}
+	ready := make(chan struct{})
+	r := NewRegistrar(logger, backend, staticService(Service{ID: "svc-1"}), WithTTL(time.Minute), WithReady(ready))
+
+	done := make(chan error, 1)
+	go func() {
+		done <- r.Run(context.Background())
+	}()
+	time.Sleep(20 * time.Millisecond)
+	backend.mu.Lock()
+	assert.Zero(t, backend.registers, "registered before ready")
+	backend.mu.Unlock()
+
+	// Act
+	close(ready)
+	assert.Eventually(t, func() bool {
+		backend.mu.Lock()
+		defer backend.mu.Unlock()
+		return backend.registers == 1
+	}, time.Second, 5*time.Millisecond)
+	r.Drain()
+
+	// Assert
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("registrar did not stop after drain")
+	}
+	require.NoError(t, r.Shutdown(context.Background()))
+	backend.mu.Lock()
+	defer backend.mu.Unlock()
+	assert.Equal(t, 1, backend.deregisters, "deregistered once, when draining")
+}
+
+func TestRegistrar_ShutdownBeforeReady(t *testing.T) {
+	// Arrange
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	backend := &fakeBackend{}
+	r := NewRegistrar(logger, backend, staticService(Service{ID: "svc-1"}), WithReady(make(chan struct{})))
+
+	done := make(chan error, 1)
+	go func() {
+		done <- r.Run(context.Background())
+	}()
+
+	// Act
+	require.NoError(t, r.Shutdown(context.Background()))
+
+	// Assert
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("registrar did not stop after shutdown")
+	}
+	backend.mu.Lock()
+	defer backend.mu.Unlock()
+	assert.Zero(t, backend.registers)
+	assert.Zero(t, backend.deregisters)
+}
@@ -0,0 +1,79 @@
+package scaffold
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	blockComment = regexp.MustCompile(`(?s)/\*.*?\*/`)
+	lineComment  = regexp.MustCompile(`//[^\n]*`)
+	packageDecl  = regexp.MustCompile(`\bpackage\s+([\w.]+)\s*;`)
+	goPackage    = regexp.MustCompile(`\boption\s+go_package\s*=\s*"([^"]+)"\s*;`)
+	serviceDecl  = regexp.MustCompile(`\bservice\s+(\w+)\s*\{`)
+	rpcDecl      = regexp.MustCompile(`\brpc\s+(\w+)\s*\(`)
+)
+
+// protoFile is the part of a proto file the generator needs
+type protoFile struct {
+	Package   string
+	GoPackage string
+	Services  []protoService
+}
+
+// protoService is a service declared in a proto file
+type protoService struct {
+	Name    string
+	Methods []string
+}
+
+// parseProto reads the package, go_package and services of a proto file. It is not a
+// full parser, but handles the declarations protoc-gen-go needs.
+func parseProto(src string) (*protoFile, error) {
+	src = lineComment.ReplaceAllString(blockComment.ReplaceAllString(src, ""), "")
+
+	file := &protoFile{}
+	if m := packageDecl.FindStringSubmatch(src); m != nil {
+		file.Package = m[1]
+	}
+	m := goPackage.FindStringSubmatch(src)
+	if m == nil {
+		return nil, fmt.Errorf("missing option go_package")
+	}
+	file.GoPackage, _, _ = strings.Cut(m[1], ";")
+
+	for _, loc := range serviceDecl.FindAllStringSubmatchIndex(src, -1) {
+		body, err := block(src[loc[1]:])
+		if err != nil {
+			return nil, fmt.Errorf("service %s: %w", src[loc[2]:loc[3]], err)
+		}
+		svc := protoService{Name: src[loc[2]:loc[3]]}
+		for _, rpc := range rpcDecl.FindAllStringSubmatch(body, -1) {
+			svc.Methods = append(svc.Methods, rpc[1])
+		}
+		file.Services = append(file.Services, svc)
+	}
+	if len(file.Services) == 0 {
+		return nil, fmt.Errorf("no service declared")
+	}
+
+	return file, nil
+}
+
+// block returns the text up to the brace closing an opened block
+func block(src string) (string, error) {
+	depth := 1
+	for i, r := range src {
+		switch r {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return src[:i], nil
+			}
+		}
+	}
+	return "", fmt.Errorf("unterminated block")
+}
@@ -0,0 +1,76 @@
+package scaffold
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const greeterProto = `syntax = "proto3";
+
+// Package greeter.v1 says hello
+package greeter.v1;
+
+import "google/api/annotations.proto";
+
+option go_package = "example.com/orders/api/greeter/v1;greeterv1";
+
+/* The greeting service
+   service Commented { rpc Ignored(A) returns (B); } */
+service GreeterService {
+  // SayHello greets by name
+  rpc SayHello(HelloRequest) returns (HelloResponse) {
+    option (google.api.http) = {
+      get: "/v1/greeter/{name}"
+    };
+  }
+  rpc SayHelloStream(stream HelloRequest) returns (stream HelloResponse);
+}
+
+service AdminService {
+  rpc Reset(ResetRequest) returns (ResetResponse);
+}
+
+message HelloRequest {
+  string name = 1;
+}
+`
+
+func TestParseProto(t *testing.T) {
+	// Act
+	file, err := parseProto(greeterProto)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, &protoFile{
+		Package:   "greeter.v1",
+		GoPackage: "example.com/orders/api/greeter/v1",
+		Services: []protoService{
+			{Name: "GreeterService", Methods: []string{"SayHello", "SayHelloStream"}},
+			{Name: "AdminService", Methods: []string{"Reset"}},
+		},
+	}, file)
+}
+
+func TestParseProto_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		wantErr string
+	}{
+		{name: "missing go_package", src: `package a; service S {}`, wantErr: "missing option go_package"},
+		{name: "no service", src: `option go_package = "example.com/a"; message M {}`, wantErr: "no service declared"},
+		{name: "unterminated service", src: `option go_package = "example.com/a"; service S { rpc A(B) returns (C) {`, wantErr: "service S: unterminated block"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, err := parseProto(tt.src)
+
+			// Assert
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
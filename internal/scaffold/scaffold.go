@@ -0,0 +1,227 @@
+// Package scaffold generates the skeleton of a new netgex service: a main.go wired to
+// server.NewServer, a registrar per gRPC service, a Dockerfile and an environment
+// template listing the config.Config variables.
+package scaffold
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"go/format"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/legrch/netgex/config"
+)
+
+// goVersion is the Go version of the generated module and Docker build image
+const goVersion = "1.24"
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+var templates = template.Must(template.New("").
+	Funcs(template.FuncMap{"join": strings.Join}).
+	ParseFS(templateFS, "templates/*.tmpl"))
+
+// validName matches service names usable as binary and directory names
+var validName = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+// Options describes the service to generate
+type Options struct {
+	// Name is the service name, e.g. orders
+	Name string
+	// Module is the Go module path; defaults to Name
+	Module string
+	// Dir is the output directory; defaults to Name
+	Dir string
+	// Proto is an optional proto file whose services get registrars wired to the
+	// generated code in its go_package
+	Proto string
+}
+
+// service is the template data of a registrar
+type service struct {
+	Name      string
+	FullName  string
+	GoPackage string
+	Methods   []string
+}
+
+// data is the template data of the generated files
+type data struct {
+	Name      string
+	Module    string
+	GoVersion string
+	Services  []service
+	Ports     []string
+	Variables []config.Variable
+}
+
+// Generate writes the service skeleton and returns the paths of the files it wrote.
+// Existing files are never overwritten.
+func Generate(opts Options) ([]string, error) {
+	if !validName.MatchString(opts.Name) {
+		return nil, fmt.Errorf("invalid service name %q: use lowercase letters, digits and dashes", opts.Name)
+	}
+	if opts.Module == "" {
+		opts.Module = opts.Name
+	}
+	if opts.Dir == "" {
+		opts.Dir = opts.Name
+	}
+
+	services, err := services(opts)
+	if err != nil {
+		return nil, err
+	}
+	d := data{
+		Name:      opts.Name,
+		Module:    opts.Module,
+		GoVersion: goVersion,
+		Services:  services,
+		Ports:     ports(),
+		Variables: config.Variables(),
+	}
+
+	files := map[string]func() ([]byte, error){
+		"go.mod":       func() ([]byte, error) { return render("go.mod.tmpl", d, false) },
+		"main.go":      func() ([]byte, error) { return render("main.go.tmpl", d, true) },
+		"Dockerfile":   func() ([]byte, error) { return render("Dockerfile.tmpl", d, false) },
+		".env.example": func() ([]byte, error) { return render("env.tmpl", d, false) },
+	}
+	for _, svc := range services {
+		path := filepath.Join("internal", "service", snakeCase(svc.Name)+".go")
+		files[path] = func() ([]byte, error) { return render("service.go.tmpl", svc, true) }
+	}
+
+	// Refuse to overwrite anything before writing the first file
+	for path := range files {
+		if _, err := os.Stat(filepath.Join(opts.Dir, path)); err == nil {
+			return nil, fmt.Errorf("%s already exists", filepath.Join(opts.Dir, path))
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	written := make([]string, 0, len(files))
+	for path, renderFile := range files {
+		content, err := renderFile()
+		if err != nil {
+			return written, fmt.Errorf("render %s: %w", path, err)
+		}
+		path = filepath.Join(opts.Dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return written, err
+		}
+		if err := os.WriteFile(path, content, 0o644); err != nil {
+			return written, err
+		}
+		written = append(written, path)
+	}
+	slices.Sort(written)
+
+	return written, nil
+}
+
+// services returns the registrars to generate: one per service of the proto file, or a
+// single skeleton named after the service without one
+func services(opts Options) ([]service, error) {
+	if opts.Proto == "" {
+		name := camelCase(opts.Name)
+		return []service{{Name: name, FullName: name}}, nil
+	}
+
+	src, err := os.ReadFile(opts.Proto)
+	if err != nil {
+		return nil, err
+	}
+	file, err := parseProto(string(src))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", opts.Proto, err)
+	}
+
+	services := make([]service, 0, len(file.Services))
+	for _, svc := range file.Services {
+		fullName := svc.Name
+		if file.Package != "" {
+			fullName = file.Package + "." + svc.Name
+		}
+		services = append(services, service{
+			Name:      svc.Name,
+			FullName:  fullName,
+			GoPackage: file.GoPackage,
+			Methods:   svc.Methods,
+		})
+	}
+	return services, nil
+}
+
+// ports returns the ports of the default gRPC, HTTP and metrics addresses
+func ports() []string {
+	defaults := map[string]string{}
+	for _, v := range config.Variables() {
+		defaults[v.Name] = v.Default
+	}
+
+	var ports []string
+	for _, name := range []string{"GRPC_ADDRESS", "HTTP_ADDRESS", "METRICS_ADDRESS"} {
+		if _, port, err := net.SplitHostPort(defaults[name]); err == nil {
+			ports = append(ports, port)
+		}
+	}
+	return ports
+}
+
+// render executes a template, formatting Go source
+func render(name string, d any, gofmt bool) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, name, d); err != nil {
+		return nil, err
+	}
+	if !gofmt {
+		return buf.Bytes(), nil
+	}
+	return format.Source(buf.Bytes())
+}
+
+// camelCase converts a dashed name to an exported Go identifier, e.g. order-api to OrderAPI
+func camelCase(name string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(name, "-") {
+		if part == "api" || part == "id" || part == "http" || part == "grpc" {
+			b.WriteString(strings.ToUpper(part))
+			continue
+		}
+		for i, r := range part {
+			if i == 0 {
+				r = unicode.ToUpper(r)
+			}
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// snakeCase converts a Go identifier to a file name, e.g. GreeterService to greeter_service
+func snakeCase(name string) string {
+	var b strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,112 @@
+package scaffold
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// parseGo reports whether a generated Go file parses
+func parseGo(t *testing.T, path string) {
+	t.Helper()
+	_, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.AllErrors)
+	require.NoError(t, err, path)
+}
+
+func TestGenerate(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	proto := filepath.Join(dir, "greeter.proto")
+	require.NoError(t, os.WriteFile(proto, []byte(greeterProto), 0o600))
+	out := filepath.Join(dir, "orders")
+
+	// Act
+	files, err := Generate(Options{Name: "orders", Module: "example.com/orders", Dir: out, Proto: proto})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(out, ".env.example"),
+		filepath.Join(out, "Dockerfile"),
+		filepath.Join(out, "go.mod"),
+		filepath.Join(out, "internal/service/admin_service.go"),
+		filepath.Join(out, "internal/service/greeter_service.go"),
+		filepath.Join(out, "main.go"),
+	}, files)
+
+	read := func(name string) string {
+		content, err := os.ReadFile(filepath.Join(out, name))
+		require.NoError(t, err)
+		return string(content)
+	}
+	parseGo(t, filepath.Join(out, "main.go"))
+	parseGo(t, filepath.Join(out, "internal/service/greeter_service.go"))
+
+	assert.Contains(t, read("main.go"), `"example.com/orders/internal/service"`)
+	assert.Contains(t, read("main.go"), "service.NewGreeterService(),")
+	assert.Contains(t, read("main.go"), "service.NewAdminService(),")
+
+	greeter := read("internal/service/greeter_service.go")
+	assert.Contains(t, greeter, `pb "example.com/orders/api/greeter/v1"`)
+	assert.Contains(t, greeter, "pb.UnimplementedGreeterServiceServer")
+	assert.Contains(t, greeter, "pb.RegisterGreeterServiceHandlerFromEndpoint(ctx, mux, endpoint, opts)")
+	assert.Contains(t, greeter, `return "greeter.v1.GreeterService"`)
+	assert.Contains(t, greeter, "SayHello, SayHelloStream")
+
+	assert.Contains(t, read("Dockerfile"), "EXPOSE 9090 8080 9091")
+	assert.Contains(t, read("go.mod"), "module example.com/orders")
+
+	env := read(".env.example")
+	assert.Contains(t, env, "\nSERVICE_NAME=orders\n")
+	assert.Contains(t, env, "\n# GRPC_ADDRESS=:9090\n")
+	assert.Contains(t, env, "\n# PPROF_AUTH_TOKEN=  # secret\n")
+	assert.Equal(t, 1, strings.Count(env, "SERVICE_NAME="))
+}
+
+func TestGenerate_WithoutProto(t *testing.T) {
+	// Arrange
+	out := filepath.Join(t.TempDir(), "order-api")
+
+	// Act
+	_, err := Generate(Options{Name: "order-api", Dir: out})
+
+	// Assert
+	require.NoError(t, err)
+	path := filepath.Join(out, "internal/service/order_api.go")
+	parseGo(t, path)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "type OrderAPI struct{}")
+	assert.Contains(t, string(content), "// TODO: register the generated gRPC service")
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	t.Run("invalid name", func(t *testing.T) {
+		// Act
+		_, err := Generate(Options{Name: "Orders", Dir: t.TempDir()})
+
+		// Assert
+		assert.ErrorContains(t, err, "invalid service name")
+	})
+
+	t.Run("existing file", func(t *testing.T) {
+		// Arrange
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0o600))
+
+		// Act
+		files, err := Generate(Options{Name: "orders", Dir: dir})
+
+		// Assert
+		assert.ErrorContains(t, err, "already exists")
+		assert.Empty(t, files)
+		_, statErr := os.Stat(filepath.Join(dir, "go.mod"))
+		assert.True(t, os.IsNotExist(statErr), "nothing should be written")
+	})
+}
@@ -11,6 +11,8 @@ import (
 	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/status"
+
+	"github.com/legrch/netgex/internal/metrics"
 )
 
 // GetUnaryInterceptors returns the unary interceptors for telemetry
@@ -124,33 +126,59 @@ func (s *Service) TracingStreamInterceptor() grpc.StreamServerInterceptor {
 	}
 }
 
+// grpcMetrics are the collectors of the metrics interceptors
+type grpcMetrics struct {
+	requests       *prometheus.CounterVec
+	duration       *prometheus.HistogramVec
+	streamRequests *prometheus.CounterVec
+	streamDuration *prometheus.HistogramVec
+}
+
+// grpcMetricsFor returns the collectors of the namespace, registering them unless
+// already registered
+func grpcMetricsFor(namespace string) *grpcMetrics {
+	m := &grpcMetrics{
+		requests: metrics.Register(prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "grpc_requests_total",
+				Help:      "Total number of gRPC requests",
+			},
+			[]string{"method", "status"},
+		)),
+		duration: metrics.Register(prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "grpc_request_duration_seconds",
+				Help:      "Duration of gRPC requests in seconds",
+				Buckets:   []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 2, 5, 10},
+			},
+			[]string{"method"},
+		)),
+		streamRequests: metrics.Register(prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "grpc_stream_requests_total",
+				Help:      "Total number of gRPC stream requests",
+			},
+			[]string{"method", "status"},
+		)),
+		streamDuration: metrics.Register(prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "grpc_stream_duration_seconds",
+				Help:      "Duration of gRPC streams in seconds",
+				Buckets:   []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 2, 5, 10, 30, 60, 120, 300, 600},
+			},
+			[]string{"method"},
+		)),
+	}
+	return m
+}
+
 // MetricsUnaryInterceptor creates a gRPC unary interceptor for Prometheus metrics
 func (s *Service) MetricsUnaryInterceptor() grpc.UnaryServerInterceptor {
-	// Initialize metrics
-	grpcRequestsTotal := prometheus.NewCounterVec(
-		prometheus.CounterOpts{
-			Namespace: s.config.Telemetry.Metrics.Namespace,
-			Name:      "grpc_requests_total",
-			Help:      "Total number of gRPC requests",
-		},
-		[]string{"method", "status"},
-	)
-
-	grpcRequestDuration := prometheus.NewHistogramVec(
-		prometheus.HistogramOpts{
-			Namespace: s.config.Telemetry.Metrics.Namespace,
-			Name:      "grpc_request_duration_seconds",
-			Help:      "Duration of gRPC requests in seconds",
-			Buckets:   []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 2, 5, 10},
-		},
-		[]string{"method"},
-	)
-
-	// Register metrics
-	prometheus.MustRegister(
-		grpcRequestsTotal,
-		grpcRequestDuration,
-	)
+	m := grpcMetricsFor(s.config.Telemetry.Metrics.Namespace)
 
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 		startTime := time.Now()
@@ -163,8 +191,12 @@ func (s *Service) MetricsUnaryInterceptor() grpc.UnaryServerInterceptor {
 			statusCode = status.Code(err).String()
 		}
 
-		grpcRequestsTotal.WithLabelValues(info.FullMethod, statusCode).Inc()
-		grpcRequestDuration.WithLabelValues(info.FullMethod).Observe(duration)
+		method, ok := s.methodLabel(info.FullMethod)
+		if !ok {
+			return resp, err
+		}
+		m.requests.WithLabelValues(method, statusCode).Inc()
+		m.duration.WithLabelValues(method).Observe(duration)
 
 		return resp, err
 	}
@@ -172,31 +204,7 @@ func (s *Service) MetricsUnaryInterceptor() grpc.UnaryServerInterceptor {
 
 // MetricsStreamInterceptor creates a gRPC stream interceptor for Prometheus metrics
 func (s *Service) MetricsStreamInterceptor() grpc.StreamServerInterceptor {
-	// Initialize metrics
-	grpcStreamRequestsTotal := prometheus.NewCounterVec(
-		prometheus.CounterOpts{
-			Namespace: s.config.Telemetry.Metrics.Namespace,
-			Name:      "grpc_stream_requests_total",
-			Help:      "Total number of gRPC stream requests",
-		},
-		[]string{"method", "status"},
-	)
-
-	grpcStreamDuration := prometheus.NewHistogramVec(
-		prometheus.HistogramOpts{
-			Namespace: s.config.Telemetry.Metrics.Namespace,
-			Name:      "grpc_stream_duration_seconds",
-			Help:      "Duration of gRPC streams in seconds",
-			Buckets:   []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 2, 5, 10, 30, 60, 120, 300, 600},
-		},
-		[]string{"method"},
-	)
-
-	// Register metrics
-	prometheus.MustRegister(
-		grpcStreamRequestsTotal,
-		grpcStreamDuration,
-	)
+	m := grpcMetricsFor(s.config.Telemetry.Metrics.Namespace)
 
 	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
 		startTime := time.Now()
@@ -209,8 +217,12 @@ func (s *Service) MetricsStreamInterceptor() grpc.StreamServerInterceptor {
 			statusCode = status.Code(err).String()
 		}
 
-		grpcStreamRequestsTotal.WithLabelValues(info.FullMethod, statusCode).Inc()
-		grpcStreamDuration.WithLabelValues(info.FullMethod).Observe(duration)
+		method, ok := s.methodLabel(info.FullMethod)
+		if !ok {
+			return err
+		}
+		m.streamRequests.WithLabelValues(method, statusCode).Inc()
+		m.streamDuration.WithLabelValues(method).Observe(duration)
 
 		return err
 	}
@@ -0,0 +1,31 @@
+package telemetry
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+
+	"github.com/legrch/netgex/config"
+)
+
+func TestService_MetricsUnaryInterceptor_SharedAcrossServices(t *testing.T) {
+	// Arrange
+	cfg := config.NewConfig()
+	cfg.Telemetry.Metrics.Namespace = "interceptors_test"
+	handler := func(context.Context, interface{}) (interface{}, error) { return nil, nil }
+	info := &grpc.UnaryServerInfo{FullMethod: "/orders.v1.Orders/Get"}
+
+	// Act - creating the interceptors of a second service does not register twice
+	first := NewService(slog.Default(), cfg).MetricsUnaryInterceptor()
+	second := NewService(slog.Default(), cfg).MetricsUnaryInterceptor()
+	_, _ = first(context.Background(), nil, info, handler)
+	_, _ = second(context.Background(), nil, info, handler)
+
+	// Assert
+	requests := grpcMetricsFor("interceptors_test").requests
+	assert.InDelta(t, 2, testutil.ToFloat64(requests.WithLabelValues("/orders.v1.Orders/Get", "success")), 0)
+}
@@ -0,0 +1,42 @@
+package telemetry
+
+import (
+	"fmt"
+	"path"
+)
+
+// methodLabel returns the method label of a call in request metrics: the first
+// METRICS_COLLAPSE_METHODS pattern the method matches, or the method itself. It reports
+// false for methods matching METRICS_EXCLUDE_METHODS, which are not recorded.
+func (s *Service) methodLabel(method string) (string, bool) {
+	cfg := s.config.Telemetry.Metrics
+	for _, pattern := range cfg.ExcludeMethods {
+		if matched, _ := path.Match(pattern, method); matched {
+			return "", false
+		}
+	}
+	for _, pattern := range cfg.CollapseMethods {
+		if matched, _ := path.Match(pattern, method); matched {
+			return pattern, true
+		}
+	}
+	return method, true
+}
+
+// validateMethodPatterns rejects malformed METRICS_EXCLUDE_METHODS and
+// METRICS_COLLAPSE_METHODS patterns, which would otherwise never match
+func validateMethodPatterns(exclude, collapse []string) error {
+	if err := validatePatterns("METRICS_EXCLUDE_METHODS", exclude); err != nil {
+		return err
+	}
+	return validatePatterns("METRICS_COLLAPSE_METHODS", collapse)
+}
+
+func validatePatterns(name string, patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid %s pattern %q: %w", name, pattern, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package telemetry
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/legrch/netgex/config"
+)
+
+func TestService_MethodLabel(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.Telemetry.Metrics.ExcludeMethods = []string{"/grpc.health.v1.Health/*", "/grpc.reflection.*/*"}
+	cfg.Telemetry.Metrics.CollapseMethods = []string{"/events.v1.Events/Subscribe*"}
+	s := NewService(slog.Default(), cfg)
+
+	tests := []struct {
+		name      string
+		method    string
+		wantLabel string
+		wantOK    bool
+	}{
+		{name: "health", method: "/grpc.health.v1.Health/Check"},
+		{name: "reflection", method: "/grpc.reflection.v1.ServerReflection/ServerReflectionInfo"},
+		{name: "collapsed", method: "/events.v1.Events/SubscribeOrders", wantLabel: "/events.v1.Events/Subscribe*", wantOK: true},
+		{name: "other", method: "/orders.v1.Orders/Get", wantLabel: "/orders.v1.Orders/Get", wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			label, ok := s.methodLabel(tt.method)
+
+			// Assert
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantLabel, label)
+		})
+	}
+}
+
+func TestValidateMethodPatterns(t *testing.T) {
+	// Act
+	err := validateMethodPatterns(nil, []string{"/events.v1.Events/[Sub"})
+
+	// Assert
+	assert.ErrorContains(t, err, `invalid METRICS_COLLAPSE_METHODS pattern "/events.v1.Events/[Sub"`)
+}
@@ -3,10 +3,8 @@ package telemetry
 import (
 	"context"
 	"fmt"
-	"net/http"
 
-	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/legrch/netgex/internal/metrics"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
@@ -35,14 +33,15 @@ func (s *Service) setupMetrics(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("failed to create resource: %w", err)
 	}
+	if res, err = s.withMetricsLabels(res); err != nil {
+		return err
+	}
 
 	switch cfg.Backend {
 	case "prometheus":
-		// Register HTTP handler for Prometheus metrics
-		http.Handle(cfg.Path, promhttp.Handler())
+		// The metrics server serves the default registry, so there is nothing to set up
+		// and no meter provider to clean up
 		s.logger.Info("initialized Prometheus metrics", "path", cfg.Path)
-
-		// We don't need a meter provider to clean up in this case
 		return nil
 
 	case "otlp":
@@ -79,63 +78,16 @@ func (s *Service) setupMetrics(ctx context.Context) error {
 	return nil
 }
 
-// RegisterMetrics registers common application metrics
-func (s *Service) RegisterMetrics() {
-	// Only register when using Prometheus
-	if s.config.Telemetry.Metrics.Backend != "prometheus" {
-		return
+// withMetricsLabels adds the METRICS_LABELS to the resource of exported metrics, as
+// Prometheus metrics get them when served
+func (s *Service) withMetricsLabels(res *resource.Resource) (*resource.Resource, error) {
+	labels, err := metrics.ParseLabels(s.config.Telemetry.Metrics.Labels)
+	if err != nil {
+		return nil, err
 	}
-
-	// Create a custom registry
-	registry := prometheus.NewRegistry()
-
-	// Example metric definitions
-	httpRequestsTotal := prometheus.NewCounterVec(
-		prometheus.CounterOpts{
-			Namespace: s.config.Telemetry.Metrics.Namespace,
-			Name:      "http_requests_total",
-			Help:      "Total number of HTTP requests",
-		},
-		[]string{"method", "path", "status"},
-	)
-
-	httpRequestDuration := prometheus.NewHistogramVec(
-		prometheus.HistogramOpts{
-			Namespace: s.config.Telemetry.Metrics.Namespace,
-			Name:      "http_request_duration_seconds",
-			Help:      "Duration of HTTP requests in seconds",
-			Buckets:   []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 2, 5, 10},
-		},
-		[]string{"method", "path"},
-	)
-
-	grpcRequestsTotal := prometheus.NewCounterVec(
-		prometheus.CounterOpts{
-			Namespace: s.config.Telemetry.Metrics.Namespace,
-			Name:      "grpc_requests_total",
-			Help:      "Total number of gRPC requests",
-		},
-		[]string{"method", "status"},
-	)
-
-	grpcRequestDuration := prometheus.NewHistogramVec(
-		prometheus.HistogramOpts{
-			Namespace: s.config.Telemetry.Metrics.Namespace,
-			Name:      "grpc_request_duration_seconds",
-			Help:      "Duration of gRPC requests in seconds",
-			Buckets:   []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 2, 5, 10},
-		},
-		[]string{"method"},
-	)
-
-	// Register with registry
-	registry.MustRegister(
-		httpRequestsTotal,
-		httpRequestDuration,
-		grpcRequestsTotal,
-		grpcRequestDuration,
-	)
-
-	// Use our registry as the default prometheus registry
-	http.Handle(s.config.Telemetry.Metrics.Path, promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	attrs := make([]attribute.KeyValue, 0, len(labels))
+	for key, value := range labels {
+		attrs = append(attrs, attribute.String(key, value))
+	}
+	return resource.Merge(res, resource.NewSchemaless(attrs...))
 }
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/legrch/netgex/config"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
@@ -59,7 +60,11 @@ func (s *Service) setupOTEL(ctx context.Context) error {
 
 	// Set up metrics if enabled
 	if cfg.MetricsEnabled {
-		meterProvider, err := s.setupOTELMetrics(ctx, cfg, res, headers)
+		metricsRes, err := s.withMetricsLabels(res)
+		if err != nil {
+			return err
+		}
+		meterProvider, err := s.setupOTELMetrics(ctx, cfg, metricsRes, headers)
 		if err != nil {
 			return fmt.Errorf("failed to set up OTEL metrics: %w", err)
 		}
@@ -99,14 +104,13 @@ func (s *Service) setupOTELTracing(
 	}
 
 	// Create TracerProvider with the exporter
-	tp := sdktrace.NewTracerProvider(
-		sdktrace.WithBatcher(exporter,
+	tp := sdktrace.NewTracerProvider(append(
+		s.samplingOptions(exporter, cfg.SampleRate,
 			sdktrace.WithMaxExportBatchSize(cfg.BatchSize),
 			sdktrace.WithBatchTimeout(cfg.BatchTimeout),
 		),
 		sdktrace.WithResource(res),
-		sdktrace.WithSampler(sdktrace.TraceIDRatioBased(cfg.SampleRate)),
-	)
+	)...)
 
 	// Set global TracerProvider and propagators
 	otel.SetTracerProvider(tp)
@@ -117,6 +121,7 @@ func (s *Service) setupOTELTracing(
 
 	s.logger.Info("OTLP tracing initialized",
 		"endpoint", cfg.Endpoint,
+		"sampler", s.config.Telemetry.Tracing.Sampler,
 		"sample_rate", cfg.SampleRate)
 
 	return tp, nil
@@ -148,7 +153,13 @@ func (s *Service) setupOTELMetrics(
 		return nil, fmt.Errorf("failed to create OTLP HTTP metric exporter: %w", err)
 	}
 
-	reader := metric.NewPeriodicReader(exp)
+	// Bridge the Prometheus registry, so its metrics are exported as well as served
+	var readerOpts []metric.PeriodicReaderOption
+	if s.prometheusBridged() {
+		readerOpts = append(readerOpts, metric.WithProducer(newPrometheusProducer(prometheus.DefaultGatherer)))
+		s.logger.Info("bridging Prometheus metrics into OTLP")
+	}
+	reader := metric.NewPeriodicReader(exp, readerOpts...)
 
 	// Create MeterProvider
 	mp := metric.NewMeterProvider(
@@ -165,6 +176,13 @@ func (s *Service) setupOTELMetrics(
 	return mp, nil
 }
 
+// prometheusBridged reports whether the Prometheus registry is exported over OTLP: both
+// Prometheus and OTEL metrics are enabled and the bridge is not turned off
+func (s *Service) prometheusBridged() bool {
+	metrics := s.config.Telemetry.Metrics
+	return metrics.Enabled && metrics.Backend == "prometheus" && s.config.Telemetry.OTEL.MetricsPrometheusBridge
+}
+
 // parseHeaders parses a comma-separated list of key=value pairs into a map
 func parseHeaders(headerStr string) map[string]string {
 	headers := make(map[string]string)
@@ -0,0 +1,175 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// prometheusScope is the instrumentation scope of the metrics bridged from Prometheus
+const prometheusScope = "github.com/legrch/netgex/internal/telemetry/prometheus"
+
+// prometheusProducer bridges a Prometheus registry into OpenTelemetry readers, so the
+// metrics of every collector, including third-party ones, are exported over OTLP too.
+// Counters become monotonic sums, gauges and untyped metrics gauges, and histograms and
+// summaries keep their kind; all are cumulative.
+type prometheusProducer struct {
+	gatherer prometheus.Gatherer
+	// start is the start time of cumulative points without a created timestamp
+	start time.Time
+}
+
+var _ metric.Producer = (*prometheusProducer)(nil)
+
+func newPrometheusProducer(gatherer prometheus.Gatherer) *prometheusProducer {
+	return &prometheusProducer{gatherer: gatherer, start: time.Now()}
+}
+
+// Produce gathers the registry and converts its metric families. Families gathered with
+// errors are still converted, the error is returned alongside them.
+func (p *prometheusProducer) Produce(context.Context) ([]metricdata.ScopeMetrics, error) {
+	families, gatherErr := p.gatherer.Gather()
+	if len(families) == 0 {
+		return nil, gatherErr
+	}
+
+	now := time.Now()
+	metrics := make([]metricdata.Metrics, 0, len(families))
+	for _, family := range families {
+		m := metricdata.Metrics{Name: family.GetName(), Description: family.GetHelp(), Unit: family.GetUnit()}
+		switch family.GetType() {
+		case dto.MetricType_COUNTER:
+			m.Data = p.counter(family, now)
+		case dto.MetricType_GAUGE, dto.MetricType_UNTYPED:
+			m.Data = gauge(family, now)
+		case dto.MetricType_HISTOGRAM:
+			m.Data = p.histogram(family, now)
+		case dto.MetricType_SUMMARY:
+			m.Data = p.summary(family, now)
+		default:
+			continue
+		}
+		metrics = append(metrics, m)
+	}
+
+	scope := metricdata.ScopeMetrics{Scope: instrumentation.Scope{Name: prometheusScope}, Metrics: metrics}
+	if gatherErr != nil {
+		return []metricdata.ScopeMetrics{scope}, fmt.Errorf("failed to gather Prometheus metrics: %w", gatherErr)
+	}
+	return []metricdata.ScopeMetrics{scope}, nil
+}
+
+func (p *prometheusProducer) counter(family *dto.MetricFamily, now time.Time) metricdata.Sum[float64] {
+	points := make([]metricdata.DataPoint[float64], 0, len(family.GetMetric()))
+	for _, m := range family.GetMetric() {
+		points = append(points, metricdata.DataPoint[float64]{
+			Attributes: attributes(m),
+			StartTime:  p.startTime(m.GetCounter().GetCreatedTimestamp()),
+			Time:       pointTime(m, now),
+			Value:      m.GetCounter().GetValue(),
+		})
+	}
+	return metricdata.Sum[float64]{DataPoints: points, Temporality: metricdata.CumulativeTemporality, IsMonotonic: true}
+}
+
+func gauge(family *dto.MetricFamily, now time.Time) metricdata.Gauge[float64] {
+	points := make([]metricdata.DataPoint[float64], 0, len(family.GetMetric()))
+	for _, m := range family.GetMetric() {
+		value := m.GetGauge().GetValue()
+		if family.GetType() == dto.MetricType_UNTYPED {
+			value = m.GetUntyped().GetValue()
+		}
+		points = append(points, metricdata.DataPoint[float64]{
+			Attributes: attributes(m),
+			Time:       pointTime(m, now),
+			Value:      value,
+		})
+	}
+	return metricdata.Gauge[float64]{DataPoints: points}
+}
+
+// histogram converts the cumulative buckets of Prometheus histograms into the bucket
+// counts of OpenTelemetry, the +Inf bucket becoming the overflow bucket
+func (p *prometheusProducer) histogram(family *dto.MetricFamily, now time.Time) metricdata.Histogram[float64] {
+	points := make([]metricdata.HistogramDataPoint[float64], 0, len(family.GetMetric()))
+	for _, m := range family.GetMetric() {
+		h := m.GetHistogram()
+		var bounds []float64
+		var counts []uint64
+		var previous uint64
+		for _, b := range h.GetBucket() {
+			if math.IsInf(b.GetUpperBound(), +1) {
+				continue
+			}
+			bounds = append(bounds, b.GetUpperBound())
+			counts = append(counts, b.GetCumulativeCount()-previous)
+			previous = b.GetCumulativeCount()
+		}
+		counts = append(counts, h.GetSampleCount()-previous)
+
+		points = append(points, metricdata.HistogramDataPoint[float64]{
+			Attributes:   attributes(m),
+			StartTime:    p.startTime(h.GetCreatedTimestamp()),
+			Time:         pointTime(m, now),
+			Count:        h.GetSampleCount(),
+			Sum:          h.GetSampleSum(),
+			Bounds:       bounds,
+			BucketCounts: counts,
+		})
+	}
+	return metricdata.Histogram[float64]{DataPoints: points, Temporality: metricdata.CumulativeTemporality}
+}
+
+func (p *prometheusProducer) summary(family *dto.MetricFamily, now time.Time) metricdata.Summary {
+	points := make([]metricdata.SummaryDataPoint, 0, len(family.GetMetric()))
+	for _, m := range family.GetMetric() {
+		s := m.GetSummary()
+		quantiles := make([]metricdata.QuantileValue, 0, len(s.GetQuantile()))
+		for _, q := range s.GetQuantile() {
+			quantiles = append(quantiles, metricdata.QuantileValue{Quantile: q.GetQuantile(), Value: q.GetValue()})
+		}
+		points = append(points, metricdata.SummaryDataPoint{
+			Attributes:     attributes(m),
+			StartTime:      p.startTime(s.GetCreatedTimestamp()),
+			Time:           pointTime(m, now),
+			Count:          s.GetSampleCount(),
+			Sum:            s.GetSampleSum(),
+			QuantileValues: quantiles,
+		})
+	}
+	return metricdata.Summary{DataPoints: points}
+}
+
+// startTime returns the created timestamp of a cumulative point, or the start of the
+// producer without one
+func (p *prometheusProducer) startTime(created *timestamppb.Timestamp) time.Time {
+	if created == nil {
+		return p.start
+	}
+	return created.AsTime()
+}
+
+// pointTime returns the timestamp of a metric, or now for metrics without one
+func pointTime(m *dto.Metric, now time.Time) time.Time {
+	if m.TimestampMs != nil {
+		return time.UnixMilli(m.GetTimestampMs())
+	}
+	return now
+}
+
+func attributes(m *dto.Metric) attribute.Set {
+	kvs := make([]attribute.KeyValue, 0, len(m.GetLabel()))
+	for _, l := range m.GetLabel() {
+		kvs = append(kvs, attribute.String(l.GetName(), l.GetValue()))
+	}
+	return attribute.NewSet(kvs...)
+}
@@ -0,0 +1,103 @@
+package telemetry
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/legrch/netgex/config"
+)
+
+func TestPrometheusProducer_Produce(t *testing.T) {
+	// Arrange
+	registry := prometheus.NewRegistry()
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "jobs_total", Help: "Jobs run"}, []string{"queue"})
+	counter.WithLabelValues("emails").Add(3)
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "pool_size"})
+	gauge.Set(7)
+	histogram := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "latency_seconds", Buckets: []float64{0.1, 1}})
+	for _, v := range []float64{0.05, 0.5, 0.7, 5} {
+		histogram.Observe(v)
+	}
+	summary := prometheus.NewSummary(prometheus.SummaryOpts{Name: "size_bytes", Objectives: map[float64]float64{0.5: 0.05}})
+	summary.Observe(10)
+	untyped := prometheus.NewUntypedFunc(prometheus.UntypedOpts{Name: "legacy_value"}, func() float64 { return 2 })
+	registry.MustRegister(counter, gauge, histogram, summary, untyped)
+
+	reader := metric.NewManualReader(metric.WithProducer(newPrometheusProducer(registry)))
+	provider := metric.NewMeterProvider(metric.WithReader(reader))
+	defer func() { _ = provider.Shutdown(context.Background()) }()
+
+	// Act
+	var rm metricdata.ResourceMetrics
+	err := reader.Collect(context.Background(), &rm)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, rm.ScopeMetrics, 1)
+	assert.Equal(t, prometheusScope, rm.ScopeMetrics[0].Scope.Name)
+	got := map[string]metricdata.Aggregation{}
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		got[m.Name] = m.Data
+	}
+	require.Len(t, got, 5)
+
+	sum := got["jobs_total"].(metricdata.Sum[float64])
+	assert.True(t, sum.IsMonotonic)
+	assert.Equal(t, metricdata.CumulativeTemporality, sum.Temporality)
+	require.Len(t, sum.DataPoints, 1)
+	assert.Equal(t, 3.0, sum.DataPoints[0].Value)
+	assert.Equal(t, attribute.NewSet(attribute.String("queue", "emails")), sum.DataPoints[0].Attributes)
+	assert.False(t, sum.DataPoints[0].StartTime.IsZero())
+
+	assert.Equal(t, 7.0, got["pool_size"].(metricdata.Gauge[float64]).DataPoints[0].Value)
+	assert.Equal(t, 2.0, got["legacy_value"].(metricdata.Gauge[float64]).DataPoints[0].Value)
+
+	hist := got["latency_seconds"].(metricdata.Histogram[float64]).DataPoints[0]
+	assert.Equal(t, uint64(4), hist.Count)
+	assert.InDelta(t, 6.25, hist.Sum, 1e-9)
+	assert.Equal(t, []float64{0.1, 1}, hist.Bounds)
+	assert.Equal(t, []uint64{1, 2, 1}, hist.BucketCounts, "cumulative buckets become counts with an overflow bucket")
+
+	summaryPoint := got["size_bytes"].(metricdata.Summary).DataPoints[0]
+	assert.Equal(t, uint64(1), summaryPoint.Count)
+	assert.Equal(t, []metricdata.QuantileValue{{Quantile: 0.5, Value: 10}}, summaryPoint.QuantileValues)
+}
+
+func TestService_PrometheusBridged(t *testing.T) {
+	tests := []struct {
+		name    string
+		enabled bool
+		backend string
+		bridge  bool
+		want    bool
+	}{
+		{name: "prometheus metrics", enabled: true, backend: "prometheus", bridge: true, want: true},
+		{name: "bridge disabled", enabled: true, backend: "prometheus", bridge: false, want: false},
+		{name: "metrics disabled", enabled: false, backend: "prometheus", bridge: true, want: false},
+		{name: "otlp backend", enabled: true, backend: "otlp", bridge: true, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			cfg := config.NewConfig()
+			cfg.Telemetry.Metrics.Enabled = tt.enabled
+			cfg.Telemetry.Metrics.Backend = tt.backend
+			cfg.Telemetry.OTEL.MetricsPrometheusBridge = tt.bridge
+			s := NewService(slog.New(slog.DiscardHandler), cfg)
+
+			// Act
+			got := s.prometheusBridged()
+
+			// Assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
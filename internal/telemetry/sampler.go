@@ -0,0 +1,104 @@
+package telemetry
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Trace samplers accepted by TRACING_SAMPLER
+const (
+	// samplerRatio samples the TRACING_SAMPLE_RATE or OTEL_SAMPLE_RATE ratio of traces
+	samplerRatio = "ratio"
+	// samplerErrorBiased samples the same ratio, but no health checks or reflection
+	// calls, and exports every failing span
+	samplerErrorBiased = "errorbiased"
+)
+
+// unsampledMethods are the gRPC methods the error-biased sampler never samples
+var unsampledMethods = []string{"/grpc.health.v1.Health/*", "/grpc.reflection.*/*"}
+
+// parseSampler validates the name of a trace sampler
+func parseSampler(name string) error {
+	switch name {
+	case samplerRatio, samplerErrorBiased:
+		return nil
+	}
+	return fmt.Errorf("invalid trace sampler %q: must be %q or %q", name, samplerRatio, samplerErrorBiased)
+}
+
+// samplingOptions returns the tracer provider options sampling traces at rate with the
+// configured sampler and exporting the sampled spans in batches
+func (s *Service) samplingOptions(exporter sdktrace.SpanExporter, rate float64, opts ...sdktrace.BatchSpanProcessorOption) []sdktrace.TracerProviderOption {
+	batcher := sdktrace.NewBatchSpanProcessor(exporter, opts...)
+	if s.config.Telemetry.Tracing.Sampler != samplerErrorBiased {
+		return []sdktrace.TracerProviderOption{
+			sdktrace.WithSpanProcessor(batcher),
+			sdktrace.WithSampler(sdktrace.TraceIDRatioBased(rate)),
+		}
+	}
+	return []sdktrace.TracerProviderOption{
+		sdktrace.WithSpanProcessor(errorSpanProcessor{batcher}),
+		sdktrace.WithSampler(errorBiasedSampler{sdktrace.TraceIDRatioBased(rate)}),
+	}
+}
+
+// errorBiasedSampler drops the spans of health checks and reflection calls, samples the
+// others like ratio and records those ratio drops, so errorSpanProcessor can still
+// export them when they fail
+type errorBiasedSampler struct {
+	ratio sdktrace.Sampler
+}
+
+func (s errorBiasedSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	// Server spans are named /package.Service/Method, client spans without the slash
+	method := "/" + strings.TrimPrefix(p.Name, "/")
+	for _, pattern := range unsampledMethods {
+		if matched, _ := path.Match(pattern, method); matched {
+			return sdktrace.SamplingResult{
+				Decision:   sdktrace.Drop,
+				Tracestate: trace.SpanContextFromContext(p.ParentContext).TraceState(),
+			}
+		}
+	}
+
+	result := s.ratio.ShouldSample(p)
+	if result.Decision == sdktrace.Drop {
+		result.Decision = sdktrace.RecordOnly
+	}
+	return result
+}
+
+func (s errorBiasedSampler) Description() string {
+	return "ErrorBiased{" + s.ratio.Description() + "}"
+}
+
+// errorSpanProcessor passes sampled spans and the failing spans the sampler only
+// recorded to the wrapped processor, marking the latter sampled so it exports them
+type errorSpanProcessor struct {
+	sdktrace.SpanProcessor
+}
+
+func (p errorSpanProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	if !s.SpanContext().IsSampled() {
+		if s.Status().Code != codes.Error {
+			return
+		}
+		s = sampledSpan{s}
+	}
+	p.SpanProcessor.OnEnd(s)
+}
+
+// sampledSpan is a recorded span with the sampled flag set
+type sampledSpan struct {
+	sdktrace.ReadOnlySpan
+}
+
+func (s sampledSpan) SpanContext() trace.SpanContext {
+	sc := s.ReadOnlySpan.SpanContext()
+	return sc.WithTraceFlags(sc.TraceFlags().WithSampled(true))
+}
@@ -0,0 +1,74 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/legrch/netgex/config"
+)
+
+func TestSamplingOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		sampler string
+		rate    float64
+		want    []string
+	}{
+		{name: "ratio", sampler: "ratio", rate: 1, want: []string{"/grpc.health.v1.Health/Check", "/orders.v1.Orders/Get", "/orders.v1.Orders/Create"}},
+		{name: "ratio none", sampler: "ratio", rate: 0},
+		{name: "error biased", sampler: "errorbiased", rate: 1, want: []string{"/orders.v1.Orders/Get", "/orders.v1.Orders/Create"}},
+		{name: "error biased errors only", sampler: "errorbiased", rate: 0, want: []string{"/orders.v1.Orders/Create"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			cfg := config.NewConfig()
+			cfg.Telemetry.Tracing.Sampler = tt.sampler
+			s := NewService(slog.New(slog.DiscardHandler), cfg)
+			exporter := tracetest.NewInMemoryExporter()
+			tp := sdktrace.NewTracerProvider(s.samplingOptions(exporter, tt.rate)...)
+			tracer := tp.Tracer("test")
+
+			// Act
+			for _, name := range []string{"/grpc.health.v1.Health/Check", "/orders.v1.Orders/Get", "/orders.v1.Orders/Create"} {
+				_, span := tracer.Start(context.Background(), name)
+				if name != "/orders.v1.Orders/Get" {
+					span.RecordError(errors.New("failed"))
+					span.SetStatus(codes.Error, "failed")
+				}
+				span.End()
+			}
+			require.NoError(t, tp.ForceFlush(context.Background()))
+
+			// Assert
+			var names []string
+			for _, span := range exporter.GetSpans() {
+				assert.True(t, span.SpanContext.IsSampled())
+				names = append(names, span.Name)
+			}
+			assert.Equal(t, tt.want, names)
+		})
+	}
+}
+
+func TestService_PreRun_InvalidSampler(t *testing.T) {
+	// Arrange
+	cfg := config.NewConfig()
+	cfg.Telemetry.Tracing.Sampler = "always"
+	s := NewService(slog.New(slog.DiscardHandler), cfg)
+
+	// Act
+	err := s.PreRun(context.Background())
+
+	// Assert
+	assert.ErrorContains(t, err, `invalid trace sampler "always"`)
+}
@@ -13,15 +13,24 @@ type Service struct {
 	logger *slog.Logger
 	config *config.Config
 	// tracer is `otlp.TracerProvider`, `jaeger.Tracer`, or none
-	tracer interface{ Shutdown(context.Context) error }
+	tracer provider
 	// meter is `otlp.MeterProvider`, or none
-	meter interface{ Shutdown(context.Context) error }
+	meter provider
 	// profiler is `pyroscope.Profiler`, or none
-	profiler interface{ Stop() error }
+	profiler interface {
+		Flush(wait bool)
+		Stop() error
+	}
 	// otelProvider is the unified OpenTelemetry provider if enabled
 	otelProvider interface{ Shutdown(context.Context) error }
 }
 
+// provider is a trace or meter provider, flushed before it is shut down
+type provider interface {
+	ForceFlush(context.Context) error
+	Shutdown(context.Context) error
+}
+
 // NewService creates a new telemetry service
 func NewService(logger *slog.Logger, config *config.Config) *Service {
 	return &Service{
@@ -34,6 +43,14 @@ func NewService(logger *slog.Logger, config *config.Config) *Service {
 func (s *Service) PreRun(ctx context.Context) error {
 	s.logger.Info("initializing telemetry services")
 
+	metrics := s.config.Telemetry.Metrics
+	if err := validateMethodPatterns(metrics.ExcludeMethods, metrics.CollapseMethods); err != nil {
+		return err
+	}
+	if err := parseSampler(s.config.Telemetry.Tracing.Sampler); err != nil {
+		return err
+	}
+
 	// Initialize logging first for better diagnostics
 	if err := s.setupLogging(ctx); err != nil {
 		return fmt.Errorf("failed to set up logging: %w", err)
@@ -73,11 +90,16 @@ func (s *Service) Run(ctx context.Context) error {
 	return nil
 }
 
-// Shutdown gracefully terminates telemetry components
+// Shutdown gracefully terminates telemetry components. The last batches of spans,
+// metrics and profiles are flushed first, before the exporters close their connections,
+// within TELEMETRY_FLUSH_TIMEOUT: telemetry is shut down after the other processes, which
+// may have used up the shutdown timeout.
 func (s *Service) Shutdown(ctx context.Context) error {
 	s.logger.Info("shutting down telemetry services")
 
-	var errs []error
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.config.Telemetry.FlushTimeout)
+	defer cancel()
+	errs := s.flush(ctx)
 
 	// Shutdown tracing
 	if s.tracer != nil {
@@ -113,3 +135,32 @@ func (s *Service) Shutdown(ctx context.Context) error {
 
 	return nil
 }
+
+// flush exports the spans, metrics and profiles not exported yet
+func (s *Service) flush(ctx context.Context) []error {
+	var errs []error
+	if s.tracer != nil {
+		if err := s.tracer.ForceFlush(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("trace provider flush: %w", err))
+		}
+	}
+	if s.meter != nil {
+		if err := s.meter.ForceFlush(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("meter provider flush: %w", err))
+		}
+	}
+	if s.profiler != nil {
+		// Uploads have no context, so wait for them up to the flush timeout only
+		done := make(chan struct{})
+		go func() {
+			s.profiler.Flush(true)
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			errs = append(errs, fmt.Errorf("profiler flush: %w", ctx.Err()))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,98 @@
+package telemetry
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/legrch/netgex/config"
+)
+
+// callLog records calls, made by the flush goroutine too
+type callLog struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (l *callLog) add(call string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.calls = append(l.calls, call)
+}
+
+// recordingProvider records the calls made to it in a shared log
+type recordingProvider struct {
+	name  string
+	calls *callLog
+}
+
+func (p recordingProvider) ForceFlush(ctx context.Context) error {
+	p.calls.add(p.name + " flush")
+	return ctx.Err()
+}
+
+func (p recordingProvider) Shutdown(ctx context.Context) error {
+	p.calls.add(p.name + " shutdown")
+	return ctx.Err()
+}
+
+type recordingProfiler struct {
+	calls *callLog
+	delay time.Duration
+}
+
+func (p recordingProfiler) Flush(bool) {
+	time.Sleep(p.delay)
+	p.calls.add("profiler flush")
+}
+
+func (p recordingProfiler) Stop() error {
+	p.calls.add("profiler stop")
+	return nil
+}
+
+func TestService_Shutdown_FlushesFirst(t *testing.T) {
+	// Arrange
+	calls := &callLog{}
+	s := NewService(slog.New(slog.DiscardHandler), config.NewConfig())
+	s.tracer = recordingProvider{name: "tracer", calls: calls}
+	s.meter = recordingProvider{name: "meter", calls: calls}
+	s.profiler = recordingProfiler{calls: calls}
+
+	// The shared shutdown context has run out, flushing gets its own timeout
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Act
+	err := s.Shutdown(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"tracer flush", "meter flush", "profiler flush",
+		"tracer shutdown", "meter shutdown", "profiler stop",
+	}, calls.calls)
+}
+
+func TestService_Shutdown_FlushTimeout(t *testing.T) {
+	// Arrange
+	calls := &callLog{}
+	cfg := config.NewConfig()
+	cfg.Telemetry.FlushTimeout = 10 * time.Millisecond
+	s := NewService(slog.New(slog.DiscardHandler), cfg)
+	s.profiler = recordingProfiler{calls: calls, delay: time.Second}
+
+	// Act
+	start := time.Now()
+	err := s.Shutdown(context.Background())
+
+	// Assert
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "profiler flush")
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
@@ -76,14 +76,13 @@ func (s *Service) setupTracing(ctx context.Context) error {
 	}
 
 	// Create TracerProvider with the exporter
-	tp := sdktrace.NewTracerProvider(
-		sdktrace.WithBatcher(exporter,
+	tp := sdktrace.NewTracerProvider(append(
+		s.samplingOptions(exporter, cfg.SampleRate,
 			sdktrace.WithMaxExportBatchSize(cfg.BatchSize),
 			sdktrace.WithBatchTimeout(cfg.BatchTimeout),
 		),
 		sdktrace.WithResource(res),
-		sdktrace.WithSampler(sdktrace.TraceIDRatioBased(cfg.SampleRate)),
-	)
+	)...)
 
 	// Set global TracerProvider
 	otel.SetTracerProvider(tp)
@@ -91,6 +90,7 @@ func (s *Service) setupTracing(ctx context.Context) error {
 
 	s.logger.Info("tracing initialized successfully",
 		"backend", cfg.Backend,
+		"sampler", cfg.Sampler,
 		"sample_rate", cfg.SampleRate)
 
 	return nil
@@ -0,0 +1,159 @@
+package watchdog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"runtime"
+	"runtime/pprof"
+	"time"
+)
+
+// maxStackDumpBytes bounds the size of a goroutine dump written to the logger
+const maxStackDumpBytes = 1 << 20
+
+// Option is a function that configures a Watchdog
+type Option func(*Watchdog)
+
+// Watchdog is a process that samples goroutine counts and scheduler latency,
+// logging when configured thresholds are exceeded
+type Watchdog struct {
+	logger             *slog.Logger
+	interval           time.Duration
+	goroutineThreshold int
+	latencyThreshold   time.Duration
+	dumpStacks         bool
+	stop               chan struct{}
+	alerting           bool
+}
+
+// NewWatchdog creates a new watchdog
+func NewWatchdog(logger *slog.Logger, opts ...Option) *Watchdog {
+	w := &Watchdog{
+		logger:             logger,
+		interval:           10 * time.Second,
+		goroutineThreshold: 10000,
+		latencyThreshold:   100 * time.Millisecond,
+		stop:               make(chan struct{}),
+	}
+
+	// Apply options
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	return w
+}
+
+// WithInterval sets how often the watchdog samples
+func WithInterval(interval time.Duration) Option {
+	return func(w *Watchdog) {
+		w.interval = interval
+	}
+}
+
+// WithGoroutineThreshold sets the goroutine count above which the watchdog alerts (0 disables)
+func WithGoroutineThreshold(threshold int) Option {
+	return func(w *Watchdog) {
+		w.goroutineThreshold = threshold
+	}
+}
+
+// WithLatencyThreshold sets the scheduler latency above which the watchdog alerts (0 disables)
+func WithLatencyThreshold(threshold time.Duration) Option {
+	return func(w *Watchdog) {
+		w.latencyThreshold = threshold
+	}
+}
+
+// WithStackDump enables dumping all goroutine stacks to the logger when an alert starts
+func WithStackDump(enabled bool) Option {
+	return func(w *Watchdog) {
+		w.dumpStacks = enabled
+	}
+}
+
+// PreRun prepares the watchdog
+func (*Watchdog) PreRun(_ context.Context) error {
+	return nil
+}
+
+// Run samples the runtime until the context is canceled or the watchdog is shut down
+func (w *Watchdog) Run(ctx context.Context) error {
+	w.logger.Info("starting watchdog",
+		"interval", w.interval,
+		"goroutine_threshold", w.goroutineThreshold,
+		"latency_threshold", w.latencyThreshold,
+	)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-w.stop:
+			return nil
+		case <-ticker.C:
+			w.check()
+		}
+	}
+}
+
+// Shutdown stops the watchdog
+func (w *Watchdog) Shutdown(_ context.Context) error {
+	w.logger.Info("shutting down watchdog")
+	select {
+	case <-w.stop:
+	default:
+		close(w.stop)
+	}
+	return nil
+}
+
+// check takes one sample and logs when thresholds are exceeded. Stacks are dumped
+// only when entering the alerting state so a persistent condition doesn't flood logs.
+func (w *Watchdog) check() {
+	goroutines := runtime.NumGoroutine()
+	latency := schedulerLatency()
+
+	exceeded := false
+	if w.goroutineThreshold > 0 && goroutines > w.goroutineThreshold {
+		exceeded = true
+		w.logger.Warn("goroutine count exceeds threshold", "goroutines", goroutines, "threshold", w.goroutineThreshold)
+	}
+	if w.latencyThreshold > 0 && latency > w.latencyThreshold {
+		exceeded = true
+		w.logger.Warn("scheduler latency exceeds threshold", "latency", latency, "threshold", w.latencyThreshold)
+	}
+
+	if exceeded && !w.alerting && w.dumpStacks {
+		w.logger.Warn("goroutine dump", "stacks", goroutineDump())
+	}
+	if !exceeded && w.alerting {
+		w.logger.Info("watchdog thresholds recovered", "goroutines", goroutines, "latency", latency)
+	}
+	w.alerting = exceeded
+}
+
+// schedulerLatency measures how long a newly spawned goroutine waits to be scheduled
+func schedulerLatency() time.Duration {
+	start := time.Now()
+	done := make(chan time.Duration)
+	go func() {
+		done <- time.Since(start)
+	}()
+	return <-done
+}
+
+// goroutineDump returns the stacks of all goroutines, truncated to maxStackDumpBytes
+func goroutineDump() string {
+	var buf bytes.Buffer
+	_ = pprof.Lookup("goroutine").WriteTo(&buf, 2)
+	if buf.Len() > maxStackDumpBytes {
+		buf.Truncate(maxStackDumpBytes)
+		buf.WriteString("\n... truncated")
+	}
+	return buf.String()
+}
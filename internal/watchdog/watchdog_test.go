@@ -0,0 +1,71 @@
+package watchdog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWatchdog(t *testing.T) {
+	// Arrange
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+
+	// Act
+	w := NewWatchdog(logger,
+		WithInterval(time.Second),
+		WithGoroutineThreshold(5),
+		WithLatencyThreshold(time.Millisecond),
+		WithStackDump(true),
+	)
+
+	// Assert
+	assert.Equal(t, time.Second, w.interval)
+	assert.Equal(t, 5, w.goroutineThreshold)
+	assert.Equal(t, time.Millisecond, w.latencyThreshold)
+	assert.True(t, w.dumpStacks)
+}
+
+func TestWatchdog_Check(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	w := NewWatchdog(logger, WithGoroutineThreshold(1), WithLatencyThreshold(0), WithStackDump(true))
+
+	// Act - first check enters the alerting state and dumps stacks once
+	w.check()
+	w.check()
+
+	// Assert
+	assert.True(t, w.alerting)
+	assert.Contains(t, buf.String(), "goroutine count exceeds threshold")
+	assert.Equal(t, 1, bytes.Count(buf.Bytes(), []byte("goroutine dump")))
+}
+
+func TestWatchdog_RunAndShutdown(t *testing.T) {
+	// Arrange
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	w := NewWatchdog(logger, WithInterval(10*time.Millisecond))
+	require.NoError(t, w.PreRun(context.Background()))
+
+	done := make(chan error, 1)
+	go func() {
+		done <- w.Run(context.Background())
+	}()
+	time.Sleep(30 * time.Millisecond)
+
+	// Act
+	require.NoError(t, w.Shutdown(context.Background()))
+
+	// Assert
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("watchdog did not stop after shutdown")
+	}
+}
@@ -0,0 +1,251 @@
+// Package lambda runs an http.Handler behind AWS Lambda, translating API Gateway
+// (REST and HTTP APIs), Application Load Balancer and Function URL events into HTTP
+// requests and the handler's responses back into the matching event responses.
+package lambda
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+)
+
+// eventKind identifies the Lambda event source shape
+type eventKind int
+
+const (
+	// kindRESTAPI is an API Gateway REST API (payload format 1.0) event
+	kindRESTAPI eventKind = iota
+	// kindHTTPAPI is an API Gateway HTTP API or Function URL (payload format 2.0) event
+	kindHTTPAPI
+	// kindALB is an Application Load Balancer event
+	kindALB
+)
+
+// event is the union of the request fields used by the supported event sources
+type event struct {
+	Version string `json:"version"`
+
+	// Payload format 1.0 and ALB
+	HTTPMethod                      string              `json:"httpMethod"`
+	Path                            string              `json:"path"`
+	QueryStringParameters           map[string]string   `json:"queryStringParameters"`
+	MultiValueQueryStringParameters map[string][]string `json:"multiValueQueryStringParameters"`
+	MultiValueHeaders               map[string][]string `json:"multiValueHeaders"`
+
+	// Payload format 2.0
+	RawPath        string   `json:"rawPath"`
+	RawQueryString string   `json:"rawQueryString"`
+	Cookies        []string `json:"cookies"`
+
+	Headers         map[string]string `json:"headers"`
+	Body            string            `json:"body"`
+	IsBase64Encoded bool              `json:"isBase64Encoded"`
+
+	RequestContext struct {
+		ELB      *json.RawMessage `json:"elb"`
+		Identity struct {
+			SourceIP string `json:"sourceIp"`
+		} `json:"identity"`
+		HTTP struct {
+			Method   string `json:"method"`
+			SourceIP string `json:"sourceIp"`
+		} `json:"http"`
+	} `json:"requestContext"`
+}
+
+// kind returns the source of the event
+func (e *event) kind() eventKind {
+	switch {
+	case e.Version == "2.0":
+		return kindHTTPAPI
+	case e.RequestContext.ELB != nil:
+		return kindALB
+	default:
+		return kindRESTAPI
+	}
+}
+
+// response is the union of the response fields of the supported event sources
+type response struct {
+	StatusCode        int                 `json:"statusCode"`
+	StatusDescription string              `json:"statusDescription,omitempty"`
+	Headers           map[string]string   `json:"headers,omitempty"`
+	MultiValueHeaders map[string][]string `json:"multiValueHeaders,omitempty"`
+	Cookies           []string            `json:"cookies,omitempty"`
+	Body              string              `json:"body"`
+	IsBase64Encoded   bool                `json:"isBase64Encoded"`
+}
+
+// Handler invokes an http.Handler for Lambda events
+type Handler struct {
+	handler http.Handler
+}
+
+// NewHandler creates a Lambda event handler serving requests with h
+func NewHandler(h http.Handler) *Handler {
+	return &Handler{handler: h}
+}
+
+// Invoke serves a single event, returning the response payload
+func (h *Handler) Invoke(ctx context.Context, payload []byte) ([]byte, error) {
+	var e event
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return nil, fmt.Errorf("failed to decode event: %w", err)
+	}
+
+	req, err := e.request(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rec := httptest.NewRecorder()
+	h.handler.ServeHTTP(rec, req)
+
+	return json.Marshal(e.response(rec.Result().StatusCode, rec.Header(), rec.Body.Bytes()))
+}
+
+// request builds the HTTP request described by the event
+func (e *event) request(ctx context.Context) (*http.Request, error) {
+	body := []byte(e.Body)
+	if e.IsBase64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(e.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode body: %w", err)
+		}
+		body = decoded
+	}
+
+	method, path, query, remoteIP := e.HTTPMethod, e.Path, e.query(), e.RequestContext.Identity.SourceIP
+	if e.kind() == kindHTTPAPI {
+		method, path, query, remoteIP = e.RequestContext.HTTP.Method, e.RawPath, e.RawQueryString, e.RequestContext.HTTP.SourceIP
+	}
+
+	target := path
+	if query != "" {
+		target += "?" + query
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	if len(e.MultiValueHeaders) > 0 {
+		for key, values := range e.MultiValueHeaders {
+			for _, value := range values {
+				req.Header.Add(key, value)
+			}
+		}
+	} else {
+		for key, value := range e.Headers {
+			req.Header.Set(key, value)
+		}
+	}
+	if len(e.Cookies) > 0 {
+		req.Header.Set("Cookie", strings.Join(e.Cookies, "; "))
+	}
+
+	req.Host = req.Header.Get("Host")
+	if remoteIP != "" {
+		req.RemoteAddr = remoteIP + ":0"
+	}
+
+	return req, nil
+}
+
+// query returns the encoded query string of payload format 1.0 and ALB events.
+// ALB passes parameters still URL-encoded, while API Gateway decodes them.
+func (e *event) query() string {
+	unescape := func(s string) string { return s }
+	if e.kind() == kindALB {
+		unescape = func(s string) string {
+			if decoded, err := url.QueryUnescape(s); err == nil {
+				return decoded
+			}
+			return s
+		}
+	}
+
+	values := url.Values{}
+	if len(e.MultiValueQueryStringParameters) > 0 {
+		for key, list := range e.MultiValueQueryStringParameters {
+			for _, value := range list {
+				values.Add(unescape(key), unescape(value))
+			}
+		}
+	} else {
+		for key, value := range e.QueryStringParameters {
+			values.Set(unescape(key), unescape(value))
+		}
+	}
+	return values.Encode()
+}
+
+// response builds the event response for the HTTP response
+func (e *event) response(status int, header http.Header, body []byte) response {
+	resp := response{StatusCode: status}
+
+	if isText(header.Get("Content-Type")) {
+		resp.Body = string(body)
+	} else {
+		resp.Body = base64.StdEncoding.EncodeToString(body)
+		resp.IsBase64Encoded = true
+	}
+
+	switch {
+	case e.kind() == kindHTTPAPI:
+		// Payload format 2.0 joins repeated headers and returns cookies separately
+		resp.Headers = make(map[string]string, len(header))
+		for key, values := range header {
+			if key == "Set-Cookie" {
+				resp.Cookies = values
+				continue
+			}
+			resp.Headers[key] = strings.Join(values, ",")
+		}
+	case len(e.MultiValueHeaders) > 0:
+		resp.MultiValueHeaders = header
+	default:
+		resp.Headers = make(map[string]string, len(header))
+		for key := range header {
+			resp.Headers[key] = header.Get(key)
+		}
+	}
+
+	if e.kind() == kindALB {
+		resp.StatusDescription = fmt.Sprintf("%d %s", status, http.StatusText(status))
+	}
+
+	return resp
+}
+
+// isText reports whether a body of the content type can be returned without base64 encoding
+func isText(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "+json"),
+		strings.HasSuffix(mediaType, "+xml"),
+		mediaType == "application/json",
+		mediaType == "application/xml",
+		mediaType == "application/javascript",
+		mediaType == "application/x-www-form-urlencoded":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,230 @@
+package lambda
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// echoHandler reports the received request in headers and body
+var echoHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Add("Set-Cookie", "a=1")
+	w.Header().Add("Set-Cookie", "b=2")
+	w.Header().Set("X-Method", r.Method)
+	w.Header().Set("X-Uri", r.URL.RequestURI())
+	w.Header().Set("X-Trace", r.Header.Get("X-B3-Traceid"))
+	w.Header().Set("X-Cookie", r.Header.Get("Cookie"))
+	w.WriteHeader(http.StatusCreated)
+	_, _ = w.Write(body)
+})
+
+func TestHandler_Invoke(t *testing.T) {
+	tests := []struct {
+		name   string
+		event  string
+		assert func(t *testing.T, resp response)
+	}{
+		{
+			name: "REST API",
+			event: `{"httpMethod":"POST","path":"/v1/items","queryStringParameters":{"q":"a b"},
+				"headers":{"X-B3-TraceId":"abc"},"body":"{\"id\":1}","requestContext":{"identity":{"sourceIp":"1.2.3.4"}}}`,
+			assert: func(t *testing.T, resp response) {
+				assert.Equal(t, http.StatusCreated, resp.StatusCode)
+				assert.Equal(t, "POST", resp.Headers["X-Method"])
+				assert.Equal(t, "/v1/items?q=a+b", resp.Headers["X-Uri"])
+				assert.Equal(t, "abc", resp.Headers["X-Trace"])
+				assert.Equal(t, `{"id":1}`, resp.Body)
+				assert.False(t, resp.IsBase64Encoded)
+				assert.Empty(t, resp.StatusDescription)
+			},
+		},
+		{
+			name: "HTTP API",
+			event: `{"version":"2.0","rawPath":"/v1/items","rawQueryString":"q=a%20b","cookies":["s=1","t=2"],
+				"headers":{"x-b3-traceid":"abc"},"body":"eyJpZCI6MX0=","isBase64Encoded":true,
+				"requestContext":{"http":{"method":"PUT","sourceIp":"1.2.3.4"}}}`,
+			assert: func(t *testing.T, resp response) {
+				assert.Equal(t, "PUT", resp.Headers["X-Method"])
+				assert.Equal(t, "/v1/items?q=a%20b", resp.Headers["X-Uri"])
+				assert.Equal(t, "s=1; t=2", resp.Headers["X-Cookie"])
+				assert.Equal(t, []string{"a=1", "b=2"}, resp.Cookies)
+				assert.NotContains(t, resp.Headers, "Set-Cookie")
+				assert.Equal(t, `{"id":1}`, resp.Body)
+			},
+		},
+		{
+			name: "ALB with multi-value headers",
+			event: `{"httpMethod":"GET","path":"/v1/items","multiValueQueryStringParameters":{"q":["a%20b"]},
+				"multiValueHeaders":{"x-b3-traceid":["abc"]},"body":"","requestContext":{"elb":{"targetGroupArn":"arn"}}}`,
+			assert: func(t *testing.T, resp response) {
+				assert.Equal(t, "/v1/items?q=a+b", resp.MultiValueHeaders["X-Uri"][0])
+				assert.Equal(t, []string{"a=1", "b=2"}, resp.MultiValueHeaders["Set-Cookie"])
+				assert.Equal(t, "201 Created", resp.StatusDescription)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			h := NewHandler(echoHandler)
+
+			// Act
+			payload, err := h.Invoke(context.Background(), []byte(tt.event))
+
+			// Assert
+			require.NoError(t, err)
+			var resp response
+			require.NoError(t, json.Unmarshal(payload, &resp))
+			tt.assert(t, resp)
+		})
+	}
+}
+
+func TestHandler_Invoke_BinaryResponse(t *testing.T) {
+	// Arrange
+	data := []byte{0x89, 'P', 'N', 'G', 0x00}
+	h := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write(data)
+	}))
+
+	// Act
+	payload, err := h.Invoke(context.Background(), []byte(`{"httpMethod":"GET","path":"/logo.png"}`))
+
+	// Assert
+	require.NoError(t, err)
+	var resp response
+	require.NoError(t, json.Unmarshal(payload, &resp))
+	assert.True(t, resp.IsBase64Encoded)
+	assert.Equal(t, base64.StdEncoding.EncodeToString(data), resp.Body)
+}
+
+func TestServe(t *testing.T) {
+	// Arrange - a fake Runtime API delivering one invocation
+	var mu sync.Mutex
+	var result []byte
+	done := make(chan struct{})
+	delivered := false
+	runtimeAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2018-06-01/runtime/invocation/next":
+			mu.Lock()
+			first := !delivered
+			delivered = true
+			mu.Unlock()
+			if !first {
+				// Block like the real API until the runtime is stopped
+				<-r.Context().Done()
+				return
+			}
+			w.Header().Set("Lambda-Runtime-Aws-Request-Id", "req-1")
+			w.Header().Set("Lambda-Runtime-Deadline-Ms", "9999999999999")
+			_, _ = w.Write([]byte(`{"httpMethod":"GET","path":"/ping"}`))
+		case "/2018-06-01/runtime/invocation/req-1/response":
+			mu.Lock()
+			result, _ = io.ReadAll(r.Body)
+			mu.Unlock()
+			w.WriteHeader(http.StatusAccepted)
+			close(done)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer runtimeAPI.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- Serve(ctx, slog.New(slog.DiscardHandler), runtimeAPI.Listener.Addr().String(), NewHandler(echoHandler))
+	}()
+
+	// Act
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("invocation result was not posted")
+	}
+	cancel()
+
+	// Assert
+	require.NoError(t, <-errCh)
+	mu.Lock()
+	defer mu.Unlock()
+	var resp response
+	require.NoError(t, json.Unmarshal(result, &resp))
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "/ping", resp.Headers["X-Uri"])
+}
+
+func TestServe_RejectedResponseKeepsPolling(t *testing.T) {
+	// Arrange - a fake Runtime API rejecting the first response as too large
+	var mu sync.Mutex
+	var reported []byte
+	var next int
+	done := make(chan struct{})
+	runtimeAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2018-06-01/runtime/invocation/next":
+			mu.Lock()
+			next++
+			n := next
+			mu.Unlock()
+			if n > 2 {
+				<-r.Context().Done()
+				return
+			}
+			w.Header().Set("Lambda-Runtime-Aws-Request-Id", fmt.Sprintf("req-%d", n))
+			_, _ = w.Write([]byte(`{"httpMethod":"GET","path":"/ping"}`))
+		case "/2018-06-01/runtime/invocation/req-1/response":
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+		case "/2018-06-01/runtime/invocation/req-1/error":
+			mu.Lock()
+			reported, _ = io.ReadAll(r.Body)
+			mu.Unlock()
+			w.WriteHeader(http.StatusAccepted)
+		case "/2018-06-01/runtime/invocation/req-2/response":
+			w.WriteHeader(http.StatusAccepted)
+			close(done)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer runtimeAPI.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- Serve(ctx, slog.New(slog.DiscardHandler), runtimeAPI.Listener.Addr().String(), NewHandler(echoHandler))
+	}()
+
+	// Act
+	select {
+	case <-done:
+	case err := <-errCh:
+		t.Fatalf("serve stopped: %v", err)
+	case <-time.After(time.Second):
+		t.Fatal("the next invocation was not served")
+	}
+	cancel()
+
+	// Assert - the rejected response is reported as an error of its invocation
+	require.NoError(t, <-errCh)
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Contains(t, string(reported), `"errorType":"ResponseError"`)
+	assert.Contains(t, string(reported), "status 413")
+}
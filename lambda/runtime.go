@@ -0,0 +1,119 @@
+package lambda
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// runtimeAPIVersion is the path prefix of the Lambda Runtime API
+const runtimeAPIVersion = "/2018-06-01/runtime"
+
+// RuntimeAPI returns the address of the Lambda Runtime API, empty outside Lambda
+func RuntimeAPI() string {
+	return os.Getenv("AWS_LAMBDA_RUNTIME_API")
+}
+
+// Serve polls the Lambda Runtime API at address (host:port) for invocations and
+// serves them with h until the context is canceled. Only a failure to get the next
+// invocation ends it; results the Runtime API rejects are logged.
+func Serve(ctx context.Context, logger *slog.Logger, address string, h *Handler) error {
+	client := &http.Client{}
+	base := "http://" + address + runtimeAPIVersion
+
+	for {
+		if err := serveNext(ctx, logger, client, base, h); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// serveNext waits for the next invocation and posts its result
+func serveNext(ctx context.Context, logger *slog.Logger, client *http.Client, base string, h *Handler) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+"/invocation/next", nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to get next invocation: %w", err)
+	}
+	payload, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read invocation: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("runtime API returned status %d for next invocation", resp.StatusCode)
+	}
+
+	requestID := resp.Header.Get("Lambda-Runtime-Aws-Request-Id")
+
+	// X-Ray reads the trace header of the current invocation from the environment
+	if traceID := resp.Header.Get("Lambda-Runtime-Trace-Id"); traceID != "" {
+		_ = os.Setenv("_X_AMZN_TRACE_ID", traceID)
+	}
+
+	invokeCtx := ctx
+	if deadline, err := strconv.ParseInt(resp.Header.Get("Lambda-Runtime-Deadline-Ms"), 10, 64); err == nil {
+		var cancel context.CancelFunc
+		invokeCtx, cancel = context.WithDeadline(ctx, time.UnixMilli(deadline))
+		defer cancel()
+	}
+
+	result, err := h.Invoke(invokeCtx, payload)
+	if err != nil {
+		postError(ctx, logger, client, base, requestID, "InvocationError", err)
+		return nil
+	}
+
+	if err := post(ctx, client, base+"/invocation/"+requestID+"/response", result); err != nil {
+		// E.g. a response over the payload limit; report it instead so the invocation
+		// fails right away rather than timing out
+		logger.Error("failed to post invocation response", "request_id", requestID, "error", err)
+		postError(ctx, logger, client, base, requestID, "ResponseError", err)
+	}
+	return nil
+}
+
+// postError reports a failed invocation to the Runtime API, logging when that fails too
+func postError(ctx context.Context, logger *slog.Logger, client *http.Client, base, requestID, errorType string, err error) {
+	body, _ := json.Marshal(map[string]string{
+		"errorMessage": err.Error(),
+		"errorType":    errorType,
+	})
+	if err := post(ctx, client, base+"/invocation/"+requestID+"/error", body); err != nil {
+		logger.Error("failed to post invocation error", "request_id", requestID, "error", err)
+	}
+}
+
+// post sends a result to the Runtime API
+func post(ctx context.Context, client *http.Client, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post invocation result: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("runtime API returned status %d for invocation result", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+// Package lifecycle defines the events a server publishes as it starts, serves and shuts
+// down, and the Bus delivering them, so applications and extensions can react to the
+// lifecycle, e.g. warm caches once ready or flush buffers once shut down, without
+// wrapping the server's Run.
+package lifecycle
+
+import (
+	"sync"
+
+	"github.com/legrch/netgex/config"
+)
+
+// Event is one of the lifecycle events below
+type Event interface {
+	lifecycleEvent()
+}
+
+// ConfigLoaded is published when the server starts with its effective configuration,
+// and again with Reload set whenever the remote configuration changes
+type ConfigLoaded struct {
+	Config *config.Config
+	Reload bool
+}
+
+// ProcessStarted is published when the server starts running a process, after the
+// PreRun of every process succeeded
+type ProcessStarted struct {
+	// Index is the position of the process in the server's start order
+	Index int
+	// Name is the name of the process, or its type when it has none
+	Name string
+}
+
+// Ready is published once every process reporting readiness is ready and the server
+// accepts requests
+type Ready struct{}
+
+// Draining is published when shutdown begins, before the server reports NOT_SERVING
+// and waits for the drain delay
+type Draining struct{}
+
+// ProcessStopped is published after the Shutdown of a process returns, in reverse start
+// order
+type ProcessStopped struct {
+	Index int
+	Name  string
+	// Err is the error returned by Shutdown
+	Err error
+}
+
+// ShutdownComplete is published when Run returns
+type ShutdownComplete struct {
+	// Err is the error Run returns
+	Err error
+}
+
+func (ConfigLoaded) lifecycleEvent()     {}
+func (ProcessStarted) lifecycleEvent()   {}
+func (Ready) lifecycleEvent()            {}
+func (Draining) lifecycleEvent()         {}
+func (ProcessStopped) lifecycleEvent()   {}
+func (ShutdownComplete) lifecycleEvent() {}
+
+// Handler reacts to events. Handlers run in the goroutine publishing the event, in
+// subscription order, so they must return quickly and hand long work to a goroutine.
+type Handler func(Event)
+
+// subscription is a handler with the id removing it
+type subscription struct {
+	id      uint64
+	handler Handler
+}
+
+// Bus delivers events to the handlers subscribed to it. The zero value is ready to use.
+type Bus struct {
+	mu     sync.RWMutex
+	nextID uint64
+	subs   []subscription
+}
+
+// Subscribe adds handler and returns the function removing it
+func (b *Bus) Subscribe(handler Handler) (unsubscribe func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	id := b.nextID
+	b.subs = append(b.subs, subscription{id: id, handler: handler})
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		for i, sub := range b.subs {
+			if sub.id == id {
+				b.subs = append(b.subs[:i:i], b.subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Subscribers returns the number of subscribed handlers
+func (b *Bus) Subscribers() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subs)
+}
+
+// Publish delivers event to the subscribed handlers. Handlers may subscribe and
+// unsubscribe while it is delivered.
+func (b *Bus) Publish(event Event) {
+	b.mu.RLock()
+	subs := b.subs
+	b.mu.RUnlock()
+
+	for _, sub := range subs {
+		sub.handler(event)
+	}
+}
@@ -0,0 +1,65 @@
+package lifecycle
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBus_Publish(t *testing.T) {
+	// Arrange
+	var bus Bus
+	var got []string
+	bus.Subscribe(func(e Event) {
+		if stopped, ok := e.(ProcessStopped); ok {
+			got = append(got, "first:"+stopped.Name+":"+stopped.Err.Error())
+		}
+	})
+	bus.Subscribe(func(Event) { got = append(got, "second") })
+
+	// Act
+	bus.Publish(ProcessStopped{Index: 1, Name: "db/orders", Err: errors.New("close failed")})
+
+	// Assert
+	assert.Equal(t, []string{"first:db/orders:close failed", "second"}, got, "handlers run in subscription order")
+	assert.Equal(t, 2, bus.Subscribers())
+}
+
+func TestBus_Unsubscribe(t *testing.T) {
+	// Arrange
+	var bus Bus
+	var first, second int
+	unsubscribe := bus.Subscribe(func(Event) { first++ })
+	bus.Subscribe(func(Event) { second++ })
+
+	// Act
+	bus.Publish(Ready{})
+	unsubscribe()
+	unsubscribe()
+	bus.Publish(Draining{})
+
+	// Assert
+	assert.Equal(t, 1, first)
+	assert.Equal(t, 2, second)
+	assert.Equal(t, 1, bus.Subscribers())
+}
+
+func TestBus_SubscribeWhilePublishing(t *testing.T) {
+	// Arrange
+	var bus Bus
+	var unsubscribe func()
+	var late int
+	unsubscribe = bus.Subscribe(func(Event) {
+		unsubscribe()
+		bus.Subscribe(func(Event) { late++ })
+	})
+
+	// Act
+	bus.Publish(Ready{})
+	bus.Publish(Draining{})
+
+	// Assert
+	assert.Equal(t, 1, late, "handlers added while publishing receive the next events")
+	assert.Equal(t, 1, bus.Subscribers())
+}
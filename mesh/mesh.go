@@ -0,0 +1,187 @@
+// Package mesh propagates service mesh tracing and routing headers set by Envoy and
+// Istio from inbound requests to outbound calls. Sidecars can only stitch a request
+// into a single trace when the application copies these headers onto its own calls.
+package mesh
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// headers are the tracing and routing headers propagated by name. W3C trace context
+// is left to the OpenTelemetry propagator, which replaces it with the client span.
+var headers = map[string]bool{
+	"x-request-id":      true,
+	"x-b3-traceid":      true,
+	"x-b3-spanid":       true,
+	"x-b3-parentspanid": true,
+	"x-b3-sampled":      true,
+	"x-b3-flags":        true,
+	"b3":                true,
+	"x-ot-span-context": true,
+}
+
+// envoyPrefix marks Envoy headers, which are propagated except for those describing
+// a single hop that the outbound sidecar sets itself
+const envoyPrefix = "x-envoy-"
+
+// hopHeaders are Envoy headers that must not be copied to outbound calls
+var hopHeaders = map[string]bool{
+	"x-envoy-attempt-count":          true,
+	"x-envoy-expected-rq-timeout-ms": true,
+	"x-envoy-upstream-service-time":  true,
+	"x-envoy-original-path":          true,
+	"x-envoy-internal":               true,
+	"x-envoy-peer-metadata":          true,
+	"x-envoy-peer-metadata-id":       true,
+}
+
+// IsPropagated reports whether the header (in any case) is propagated
+func IsPropagated(key string) bool {
+	key = strings.ToLower(key)
+	if headers[key] {
+		return true
+	}
+	return strings.HasPrefix(key, envoyPrefix) && !hopHeaders[key]
+}
+
+// contextKey is the key under which propagated headers are stored in a context
+type contextKey struct{}
+
+// NewContext returns a context carrying the propagated headers found in md
+func NewContext(ctx context.Context, md metadata.MD) context.Context {
+	propagated := metadata.MD{}
+	for key, values := range md {
+		if IsPropagated(key) {
+			propagated[strings.ToLower(key)] = values
+		}
+	}
+	if len(propagated) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, contextKey{}, propagated)
+}
+
+// FromContext returns the propagated headers carried by the context, if any
+func FromContext(ctx context.Context) metadata.MD {
+	md, _ := ctx.Value(contextKey{}).(metadata.MD)
+	return md
+}
+
+// UnaryServerInterceptor captures propagated headers from incoming gRPC metadata
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		return handler(NewContext(ctx, md), req)
+	}
+}
+
+// StreamServerInterceptor captures propagated headers from incoming gRPC metadata
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		md, _ := metadata.FromIncomingContext(ss.Context())
+		return handler(srv, &serverStream{ServerStream: ss, ctx: NewContext(ss.Context(), md)})
+	}
+}
+
+// serverStream overrides the context of a grpc.ServerStream
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the context carrying the propagated headers
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// UnaryClientInterceptor adds the propagated headers of the context to outgoing gRPC calls
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(outgoingContext(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor adds the propagated headers of the context to outgoing gRPC streams
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(outgoingContext(ctx), desc, cc, method, opts...)
+	}
+}
+
+// outgoingContext merges the propagated headers into the outgoing metadata,
+// keeping values the caller already set explicitly
+func outgoingContext(ctx context.Context) context.Context {
+	propagated := FromContext(ctx)
+	if len(propagated) == 0 {
+		return ctx
+	}
+
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	for key, values := range propagated {
+		if len(md.Get(key)) == 0 {
+			md.Set(key, values...)
+		}
+	}
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// Middleware captures propagated headers from inbound HTTP requests
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		md := metadata.MD{}
+		for key, values := range r.Header {
+			md[strings.ToLower(key)] = values
+		}
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), md)))
+	})
+}
+
+// Transport wraps base (http.DefaultTransport when nil) so outbound HTTP requests
+// carry the propagated headers of their context
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return roundTripper{base: base}
+}
+
+// roundTripper adds propagated headers to outbound requests
+type roundTripper struct {
+	base http.RoundTripper
+}
+
+// RoundTrip adds the propagated headers not already set on the request
+func (t roundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	propagated := FromContext(r.Context())
+	if len(propagated) == 0 {
+		return t.base.RoundTrip(r)
+	}
+
+	// RoundTrippers must not modify the caller's request
+	r = r.Clone(r.Context())
+	for key, values := range propagated {
+		if r.Header.Get(key) != "" {
+			continue
+		}
+		for _, value := range values {
+			r.Header.Add(key, value)
+		}
+	}
+	return t.base.RoundTrip(r)
+}
+
+// HeaderMatcher is a gateway incoming header matcher forwarding propagated headers
+// to the gRPC server unprefixed, and all other headers as the default matcher does
+func HeaderMatcher(key string) (string, bool) {
+	if IsPropagated(key) {
+		return strings.ToLower(key), true
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
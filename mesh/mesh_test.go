@@ -0,0 +1,111 @@
+package mesh
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestIsPropagated(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{key: "x-request-id", want: true},
+		{key: "X-B3-TraceId", want: true},
+		{key: "b3", want: true},
+		{key: "x-envoy-force-trace", want: true},
+		{key: "x-envoy-attempt-count", want: false},
+		{key: "x-envoy-expected-rq-timeout-ms", want: false},
+		{key: "traceparent", want: false},
+		{key: "authorization", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsPropagated(tt.key))
+		})
+	}
+}
+
+func TestInterceptors_PropagateInboundToOutbound(t *testing.T) {
+	// Arrange
+	inbound := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		"x-request-id", "req-1",
+		"x-b3-traceid", "abc",
+		"x-envoy-attempt-count", "2",
+		"authorization", "Bearer secret",
+	))
+
+	var outgoing metadata.MD
+	invoker := func(ctx context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		outgoing, _ = metadata.FromOutgoingContext(ctx)
+		return nil
+	}
+	handler := func(ctx context.Context, _ any) (any, error) {
+		// An explicitly set header wins over the propagated one
+		ctx = metadata.AppendToOutgoingContext(ctx, "x-request-id", "req-override")
+		return nil, UnaryClientInterceptor()(ctx, "/svc/Method", nil, nil, nil, invoker)
+	}
+
+	// Act
+	_, err := UnaryServerInterceptor()(inbound, nil, &grpc.UnaryServerInfo{}, handler)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"req-override"}, outgoing.Get("x-request-id"))
+	assert.Equal(t, []string{"abc"}, outgoing.Get("x-b3-traceid"))
+	assert.Empty(t, outgoing.Get("x-envoy-attempt-count"))
+	assert.Empty(t, outgoing.Get("authorization"))
+}
+
+func TestMiddlewareAndTransport(t *testing.T) {
+	// Arrange
+	var received http.Header
+	upstream := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+	}))
+	defer upstream.Close()
+
+	client := &http.Client{Transport: Transport(nil)}
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, upstream.URL, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Request-Id", "req-1")
+	req.Header.Set("X-B3-Sampled", "1")
+	req.Header.Set("Cookie", "session=1")
+
+	// Act
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	// Assert
+	assert.Equal(t, "req-1", received.Get("X-Request-Id"))
+	assert.Equal(t, "1", received.Get("X-B3-Sampled"))
+	assert.Empty(t, received.Get("Cookie"))
+}
+
+func TestHeaderMatcher(t *testing.T) {
+	key, ok := HeaderMatcher("X-B3-Traceid")
+	assert.True(t, ok)
+	assert.Equal(t, "x-b3-traceid", key)
+
+	key, ok = HeaderMatcher("Grpc-Metadata-Foo")
+	assert.True(t, ok)
+	assert.Equal(t, "Foo", key)
+
+	_, ok = HeaderMatcher("X-Custom")
+	assert.False(t, ok)
+}
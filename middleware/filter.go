@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"google.golang.org/grpc"
+)
+
+// MethodMatcher reports whether an interceptor applies to a method, given by its full
+// name, e.g. "/grpc.health.v1.Health/Check". A nil MethodMatcher matches every method.
+type MethodMatcher func(fullMethod string) bool
+
+// MatchMethods returns the matcher of the methods matching an include pattern, or any
+// method without include patterns, and no exclude pattern. Patterns use path.Match
+// syntax, e.g. "/grpc.health.v1.Health/*" or "/grpc.reflection.*/*". Without patterns
+// the matcher is nil.
+func MatchMethods(include, exclude []string) (MethodMatcher, error) {
+	for _, pattern := range append(append([]string{}, include...), exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid method pattern %q: %w", pattern, err)
+		}
+	}
+	if len(include) == 0 && len(exclude) == 0 {
+		return nil, nil
+	}
+
+	return func(fullMethod string) bool {
+		return (len(include) == 0 || matchesAny(include, fullMethod)) && !matchesAny(exclude, fullMethod)
+	}, nil
+}
+
+func matchesAny(patterns []string, fullMethod string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, fullMethod); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterUnary runs the interceptor for the methods the matcher matches, and calls the
+// handler directly for the others
+func FilterUnary(match MethodMatcher, interceptor grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	if match == nil {
+		return interceptor
+	}
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !match(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		return interceptor(ctx, req, info, handler)
+	}
+}
+
+// FilterStream is the stream counterpart of FilterUnary
+func FilterStream(match MethodMatcher, interceptor grpc.StreamServerInterceptor) grpc.StreamServerInterceptor {
+	if match == nil {
+		return interceptor
+	}
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !match(info.FullMethod) {
+			return handler(srv, ss)
+		}
+		return interceptor(srv, ss, info, handler)
+	}
+}
+
+// PathMatcher reports whether an HTTP middleware applies to a request path. A nil
+// PathMatcher matches every path.
+type PathMatcher func(path string) bool
+
+// ExcludePaths returns the matcher of the paths matching none of the patterns. Patterns
+// ending with "*" match the paths starting with the rest, the others the path itself.
+// Without patterns the matcher is nil.
+func ExcludePaths(patterns ...string) PathMatcher {
+	if len(patterns) == 0 {
+		return nil
+	}
+	return func(path string) bool {
+		for _, pattern := range patterns {
+			if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+				if strings.HasPrefix(path, prefix) {
+					return false
+				}
+			} else if path == pattern {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// FilterHTTP applies the middleware to the requests whose path the matcher matches, and
+// passes the others straight to the next handler
+func FilterHTTP(match PathMatcher, mw func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	if match == nil {
+		return mw
+	}
+	return func(next http.Handler) http.Handler {
+		filtered := mw(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !match(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			filtered.ServeHTTP(w, r)
+		})
+	}
+}
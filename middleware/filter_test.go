@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+func TestMatchMethods(t *testing.T) {
+	tests := []struct {
+		name       string
+		include    []string
+		exclude    []string
+		fullMethod string
+		want       bool
+	}{
+		{name: "excluded", exclude: []string{"/grpc.health.v1.Health/*"}, fullMethod: "/grpc.health.v1.Health/Check"},
+		{name: "not excluded", exclude: []string{"/grpc.health.v1.Health/*"}, fullMethod: "/orders.v1.Orders/Get", want: true},
+		{name: "excluded wildcard service", exclude: []string{"/grpc.reflection.*/*"}, fullMethod: "/grpc.reflection.v1.ServerReflection/ServerReflectionInfo"},
+		{name: "included", include: []string{"/orders.v1.Orders/*"}, fullMethod: "/orders.v1.Orders/Get", want: true},
+		{name: "not included", include: []string{"/orders.v1.Orders/*"}, fullMethod: "/users.v1.Users/Get"},
+		{name: "included and excluded", include: []string{"/orders.v1.Orders/*"}, exclude: []string{"/orders.v1.Orders/Watch"}, fullMethod: "/orders.v1.Orders/Watch"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			match, err := MatchMethods(tt.include, tt.exclude)
+			require.NoError(t, err)
+
+			// Act
+			got := match(tt.fullMethod)
+
+			// Assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMatchMethods_NoPatterns(t *testing.T) {
+	// Act
+	match, err := MatchMethods(nil, nil)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Nil(t, match)
+}
+
+func TestMatchMethods_InvalidPattern(t *testing.T) {
+	// Act
+	_, err := MatchMethods(nil, []string{"/svc/[Method"})
+
+	// Assert
+	assert.ErrorContains(t, err, `invalid method pattern "/svc/[Method"`)
+}
+
+func TestFilterStream(t *testing.T) {
+	// Arrange
+	var calls []string
+	interceptor := FilterStream(func(fullMethod string) bool { return fullMethod == "/svc/Watch" },
+		func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			calls = append(calls, "interceptor")
+			return handler(srv, ss)
+		})
+	handler := func(any, grpc.ServerStream) error {
+		calls = append(calls, "handler")
+		return nil
+	}
+
+	// Act
+	require.NoError(t, interceptor(nil, nil, &grpc.StreamServerInfo{FullMethod: "/svc/Watch"}, handler))
+	matched := calls
+	calls = nil
+	require.NoError(t, interceptor(nil, nil, &grpc.StreamServerInfo{FullMethod: "/svc/List"}, handler))
+
+	// Assert
+	assert.Equal(t, []string{"interceptor", "handler"}, matched)
+	assert.Equal(t, []string{"handler"}, calls)
+}
+
+func TestUnaryInterceptorsWithAccessLogFor(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	accessLogger := slog.New(slog.NewJSONHandler(&buf, nil))
+	logged := func(fullMethod string) bool { return fullMethod != "/svc/Method" }
+	handler := chain(UnaryInterceptorsWithAccessLogFor(accessLogger, logged, slog.New(slog.DiscardHandler)),
+		func(context.Context, any) (any, error) { return "ok", nil })
+
+	// Act
+	resp, err := handler(context.Background(), nil)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+	assert.Empty(t, buf.String())
+}
+
+func TestExcludePaths(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want bool
+	}{
+		{name: "excluded path", path: "/health", want: false},
+		{name: "excluded prefix", path: "/debug/pprof/heap", want: false},
+		{name: "path under excluded path", path: "/health/live", want: true},
+		{name: "other path", path: "/v1/orders", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			match := ExcludePaths("/health", "/debug/*")
+
+			// Act
+			got := match(tt.path)
+
+			// Assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFilterHTTP(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	handler := FilterHTTP(ExcludePaths("/health"), AccessLogHTTPMiddleware(slog.New(slog.NewJSONHandler(&buf, nil))))(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusNoContent) }))
+
+	// Act
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	skipped := buf.String()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/orders", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, skipped)
+	assert.Contains(t, buf.String(), `"path":"/v1/orders"`)
+}
@@ -0,0 +1,159 @@
+package middleware
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc/codes"
+
+	"github.com/legrch/netgex/errreport"
+)
+
+// IncidentIDHeader is the response header carrying the ID of a recovered panic, which
+// is also logged and reported with it
+const IncidentIDHeader = "X-Incident-Id"
+
+// RecoveryHTTPMiddleware turns panics in HTTP handlers, such as gateway routes
+// registered with HandlePath or response marshalers, into 500 responses with an
+// incident ID, logging the panic with its stack and sending it to the reporters. When
+// the response has already started, the connection is aborted instead.
+func RecoveryHTTPMiddleware(logger *slog.Logger, reporters ...errreport.ErrorReporter) func(http.Handler) http.Handler {
+	reporter := recoveryReporter(logger, reporters)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rw := &startedWriter{ResponseWriter: w}
+			defer func() {
+				p := recover()
+				if p == nil {
+					return
+				}
+				if p == http.ErrAbortHandler {
+					panic(p)
+				}
+
+				incidentID := newRequestID()
+				tags := map[string]string{"route": r.Method + " " + r.URL.Path, "incident_id": incidentID}
+				if id := r.Header.Get(RequestIDHeader); id != "" {
+					tags["request_id"] = id
+				}
+				reporter.Report(r.Context(), errreport.Panic(p, tags))
+
+				if rw.started {
+					panic(http.ErrAbortHandler)
+				}
+				writeIncident(w, incidentID)
+			}()
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
+
+// AccessLogHTTPMiddleware logs every HTTP request once served, with its method, path,
+// status, response size, duration, request ID, peer, user agent and referer, at warn
+// level for 5xx responses
+func AccessLogHTTPMiddleware(logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rw := &accessWriter{ResponseWriter: w, status: http.StatusOK}
+			defer func() {
+				level := slog.LevelInfo
+				if rw.status >= http.StatusInternalServerError {
+					level = slog.LevelWarn
+				}
+				logger.Log(r.Context(), level, "HTTP request",
+					"method", r.Method,
+					"path", r.URL.Path,
+					"proto", r.Proto,
+					"status", rw.status,
+					"bytes", rw.bytes,
+					"duration", time.Since(start),
+					"request_id", r.Header.Get(RequestIDHeader),
+					"peer", r.RemoteAddr,
+					"user_agent", r.UserAgent(),
+					"referer", r.Referer(),
+				)
+			}()
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
+
+// accessWriter records the status and size of the response
+type accessWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+func (w *accessWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *accessWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+	return n, err
+}
+
+// Flush passes flushes through, for streamed responses
+func (w *accessWriter) Flush() {
+	w.wroteHeader = true
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the wrapped writer for http.ResponseController
+func (w *accessWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// writeIncident writes a 500 response in the gateway's error format
+func writeIncident(w http.ResponseWriter, incidentID string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set(IncidentIDHeader, incidentID)
+	w.WriteHeader(http.StatusInternalServerError)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"code":    codes.Internal,
+		"message": "internal error, incident " + incidentID,
+		"details": []any{},
+	})
+}
+
+// startedWriter records whether the response has started
+type startedWriter struct {
+	http.ResponseWriter
+	started bool
+}
+
+func (w *startedWriter) WriteHeader(status int) {
+	w.started = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *startedWriter) Write(b []byte) (int, error) {
+	w.started = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush passes flushes through, for streamed responses
+func (w *startedWriter) Flush() {
+	w.started = true
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the wrapped writer for http.ResponseController
+func (w *startedWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoveryHTTPMiddleware(t *testing.T) {
+	// Arrange
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+	reporter := &recordingReporter{}
+	handler := RecoveryHTTPMiddleware(logger, reporter)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/v1/orders", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	rec := httptest.NewRecorder()
+
+	// Act
+	handler.ServeHTTP(rec, req)
+
+	// Assert
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	incidentID := rec.Header().Get(IncidentIDHeader)
+	require.NotEmpty(t, incidentID)
+	var body struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, 13, body.Code)
+	assert.Contains(t, body.Message, incidentID)
+
+	assert.Contains(t, logs.String(), "panic=boom")
+	assert.Contains(t, logs.String(), "incident_id="+incidentID)
+	require.Len(t, reporter.reports, 1)
+	assert.Equal(t, map[string]string{"route": "GET /v1/orders", "incident_id": incidentID, "request_id": "req-1"}, reporter.reports[0].Tags)
+}
+
+func TestRecoveryHTTPMiddleware_ResponseStarted(t *testing.T) {
+	// Arrange
+	handler := RecoveryHTTPMiddleware(slog.New(slog.DiscardHandler))(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		panic("marshaler failed")
+	}))
+
+	// Act & Assert - a started response cannot become a 500, so the connection is aborted
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/orders", nil))
+	})
+}
+
+func TestRecoveryHTTPMiddleware_NoPanic(t *testing.T) {
+	// Arrange
+	handler := RecoveryHTTPMiddleware(slog.New(slog.DiscardHandler))(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	rec := httptest.NewRecorder()
+
+	// Act
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/orders", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Empty(t, rec.Header().Get(IncidentIDHeader))
+}
+
+func TestAccessLogHTTPMiddleware(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		wantLevel string
+	}{
+		{name: "success", status: http.StatusCreated, wantLevel: "INFO"},
+		{name: "client error", status: http.StatusNotFound, wantLevel: "INFO"},
+		{name: "server error", status: http.StatusBadGateway, wantLevel: "WARN"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var logs bytes.Buffer
+			logger := slog.New(slog.NewJSONHandler(&logs, nil))
+			handler := AccessLogHTTPMiddleware(logger)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte("hello"))
+			}))
+			req := httptest.NewRequest(http.MethodPost, "/v1/orders", nil)
+			req.Header.Set(RequestIDHeader, "req-1")
+			req.Header.Set("User-Agent", "curl/8.0")
+
+			// Act
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			// Assert
+			var record map[string]any
+			require.NoError(t, json.Unmarshal(logs.Bytes(), &record))
+			assert.Equal(t, tt.wantLevel, record["level"])
+			assert.Equal(t, "HTTP request", record["msg"])
+			assert.Equal(t, "POST", record["method"])
+			assert.Equal(t, "/v1/orders", record["path"])
+			assert.EqualValues(t, tt.status, record["status"])
+			assert.EqualValues(t, 5, record["bytes"])
+			assert.Equal(t, "req-1", record["request_id"])
+			assert.Equal(t, "curl/8.0", record["user_agent"])
+			assert.Equal(t, req.RemoteAddr, record["peer"])
+		})
+	}
+}
@@ -0,0 +1,284 @@
+// Package middleware provides gRPC server interceptors for panic recovery, request IDs,
+// request validation, default deadlines, access logging and error reporting, and panic recovery and access
+// logging for HTTP handlers. server.WithDefaultMiddleware chains them in the recommended order; they can
+// also be added individually.
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"slices"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/legrch/netgex/errreport"
+)
+
+// RequestIDHeader is the metadata key carrying the request ID
+const RequestIDHeader = "x-request-id"
+
+// DefaultTimeout is the deadline WithDefaultMiddleware gives unary calls without one
+const DefaultTimeout = 30 * time.Second
+
+// requestIDKey is the key under which the request ID is stored in a context
+type requestIDKey struct{}
+
+// RequestIDFromContext returns the request ID set by the request ID interceptors
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// withRequestID takes the request ID from the incoming metadata or generates one. A
+// generated ID is added to the incoming metadata, so it is propagated like a received
+// one, and returned to the client in the response header.
+func withRequestID(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var id string
+	if ids := md.Get(RequestIDHeader); len(ids) > 0 && ids[0] != "" {
+		id = ids[0]
+	} else {
+		id = newRequestID()
+		md = md.Copy()
+		md.Set(RequestIDHeader, id)
+		ctx = metadata.NewIncomingContext(ctx, md)
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDHeader, id))
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// newRequestID returns a random 128-bit hex request ID
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// RequestIDUnaryInterceptor reads the x-request-id metadata, generating an ID when the
+// client sent none, and makes it available through RequestIDFromContext
+func RequestIDUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(withRequestID(ctx), req)
+	}
+}
+
+// RequestIDStreamInterceptor is the stream counterpart of RequestIDUnaryInterceptor
+func RequestIDStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &serverStream{ServerStream: ss, ctx: withRequestID(ss.Context())})
+	}
+}
+
+// RecoveryUnaryInterceptor turns panics in handlers into Internal errors, logging the
+// panic with its stack and sending it to the reporters, such as a Sentry client
+func RecoveryUnaryInterceptor(logger *slog.Logger, reporters ...errreport.ErrorReporter) grpc.UnaryServerInterceptor {
+	reporter := recoveryReporter(logger, reporters)
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				reporter.Report(ctx, errreport.Panic(p, callTags(ctx, info.FullMethod)))
+				err = status.Error(codes.Internal, "internal error")
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// RecoveryStreamInterceptor is the stream counterpart of RecoveryUnaryInterceptor
+func RecoveryStreamInterceptor(logger *slog.Logger, reporters ...errreport.ErrorReporter) grpc.StreamServerInterceptor {
+	reporter := recoveryReporter(logger, reporters)
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				reporter.Report(ss.Context(), errreport.Panic(p, callTags(ss.Context(), info.FullMethod)))
+				err = status.Error(codes.Internal, "internal error")
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+// recoveryReporter logs panics and sends them to the reporters
+func recoveryReporter(logger *slog.Logger, reporters []errreport.ErrorReporter) errreport.ErrorReporter {
+	return errreport.Multi(append([]errreport.ErrorReporter{errreport.NewLogReporter(logger)}, reporters...)...)
+}
+
+// callTags returns the tags reports of a call carry
+func callTags(ctx context.Context, method string) map[string]string {
+	tags := map[string]string{"method": method}
+	if id := RequestIDFromContext(ctx); id != "" {
+		tags["request_id"] = id
+	}
+	return tags
+}
+
+// DefaultReportedCodes are the codes ErrorReportingUnaryInterceptor reports unless given
+// others: server-side faults rather than client mistakes or unavailable dependencies
+var DefaultReportedCodes = []codes.Code{codes.Internal, codes.Unknown, codes.DataLoss}
+
+// ErrorReportingUnaryInterceptor sends the errors of calls failing with one of the codes,
+// DefaultReportedCodes when none are given, to reporter
+func ErrorReportingUnaryInterceptor(reporter errreport.ErrorReporter, reported ...codes.Code) grpc.UnaryServerInterceptor {
+	if len(reported) == 0 {
+		reported = DefaultReportedCodes
+	}
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		reportError(ctx, reporter, reported, info.FullMethod, err)
+		return resp, err
+	}
+}
+
+// ErrorReportingStreamInterceptor is the stream counterpart of ErrorReportingUnaryInterceptor
+func ErrorReportingStreamInterceptor(reporter errreport.ErrorReporter, reported ...codes.Code) grpc.StreamServerInterceptor {
+	if len(reported) == 0 {
+		reported = DefaultReportedCodes
+	}
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		err := handler(srv, ss)
+		reportError(ss.Context(), reporter, reported, info.FullMethod, err)
+		return err
+	}
+}
+
+// reportError reports the error of a call when its code is reported
+func reportError(ctx context.Context, reporter errreport.ErrorReporter, reported []codes.Code, method string, err error) {
+	if err == nil {
+		return
+	}
+	code := status.Code(err)
+	if !slices.Contains(reported, code) {
+		return
+	}
+	tags := callTags(ctx, method)
+	tags["code"] = code.String()
+	reporter.Report(ctx, errreport.Report{Err: err, Tags: tags})
+}
+
+// DeadlineUnaryInterceptor gives calls without a deadline the timeout, so a stuck
+// dependency cannot hold a request forever. Streams are left alone since they are
+// often long-lived.
+func DeadlineUnaryInterceptor(timeout time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if _, ok := ctx.Deadline(); ok || timeout <= 0 {
+			return handler(ctx, req)
+		}
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return handler(ctx, req)
+	}
+}
+
+// AccessLogUnaryInterceptor logs every call with its status code, duration and request ID
+func AccessLogUnaryInterceptor(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logAccess(ctx, logger, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// AccessLogStreamInterceptor is the stream counterpart of AccessLogUnaryInterceptor,
+// logging once the stream ends
+func AccessLogStreamInterceptor(logger *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logAccess(ss.Context(), logger, info.FullMethod, start, err)
+		return err
+	}
+}
+
+// logAccess logs a finished call, at warn level for server-side failures
+func logAccess(ctx context.Context, logger *slog.Logger, method string, start time.Time, err error) {
+	code := status.Code(err)
+	level := slog.LevelInfo
+	switch code {
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable, codes.DeadlineExceeded:
+		level = slog.LevelWarn
+	}
+	var peerAddr, userAgent string
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		peerAddr = p.Addr.String()
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ua := md.Get("user-agent"); len(ua) > 0 {
+			userAgent = ua[0]
+		}
+	}
+	logger.Log(ctx, level, "gRPC call",
+		"method", method,
+		"code", code.String(),
+		"duration", time.Since(start),
+		"request_id", RequestIDFromContext(ctx),
+		"peer", peerAddr,
+		"user_agent", userAgent,
+	)
+}
+
+// UnaryInterceptors returns the recommended unary chain: request ID, access log,
+// recovery, validation and the default deadline. The access log sits outside recovery so
+// recovered panics are logged as Internal errors. Panics are also sent to the reporters.
+func UnaryInterceptors(logger *slog.Logger, reporters ...errreport.ErrorReporter) []grpc.UnaryServerInterceptor {
+	return UnaryInterceptorsWithAccessLog(logger, logger, reporters...)
+}
+
+// UnaryInterceptorsWithAccessLog is UnaryInterceptors writing the access log to its own
+// logger, such as one of the accesslog package
+func UnaryInterceptorsWithAccessLog(accessLogger, logger *slog.Logger, reporters ...errreport.ErrorReporter) []grpc.UnaryServerInterceptor {
+	return UnaryInterceptorsWithAccessLogFor(accessLogger, nil, logger, reporters...)
+}
+
+// UnaryInterceptorsWithAccessLogFor is UnaryInterceptorsWithAccessLog logging only the
+// calls of the methods logged matches, e.g. to leave health checks out
+func UnaryInterceptorsWithAccessLogFor(accessLogger *slog.Logger, logged MethodMatcher, logger *slog.Logger, reporters ...errreport.ErrorReporter) []grpc.UnaryServerInterceptor {
+	return []grpc.UnaryServerInterceptor{
+		RequestIDUnaryInterceptor(),
+		FilterUnary(logged, AccessLogUnaryInterceptor(accessLogger)),
+		RecoveryUnaryInterceptor(logger, reporters...),
+		ValidationUnaryInterceptor(),
+		DeadlineUnaryInterceptor(DefaultTimeout),
+	}
+}
+
+// StreamInterceptors returns the recommended stream chain: request ID, access log,
+// recovery and validation
+func StreamInterceptors(logger *slog.Logger, reporters ...errreport.ErrorReporter) []grpc.StreamServerInterceptor {
+	return StreamInterceptorsWithAccessLog(logger, logger, reporters...)
+}
+
+// StreamInterceptorsWithAccessLog is StreamInterceptors writing the access log to its own
+// logger
+func StreamInterceptorsWithAccessLog(accessLogger, logger *slog.Logger, reporters ...errreport.ErrorReporter) []grpc.StreamServerInterceptor {
+	return StreamInterceptorsWithAccessLogFor(accessLogger, nil, logger, reporters...)
+}
+
+// StreamInterceptorsWithAccessLogFor is StreamInterceptorsWithAccessLog logging only the
+// streams of the methods logged matches
+func StreamInterceptorsWithAccessLogFor(accessLogger *slog.Logger, logged MethodMatcher, logger *slog.Logger, reporters ...errreport.ErrorReporter) []grpc.StreamServerInterceptor {
+	return []grpc.StreamServerInterceptor{
+		RequestIDStreamInterceptor(),
+		FilterStream(logged, AccessLogStreamInterceptor(accessLogger)),
+		RecoveryStreamInterceptor(logger, reporters...),
+		ValidationStreamInterceptor(),
+	}
+}
+
+// serverStream overrides the context of a grpc.ServerStream
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the context carrying the request ID
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,199 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/legrch/netgex/errreport"
+)
+
+// chain calls the interceptors in order around handler
+func chain(interceptors []grpc.UnaryServerInterceptor, handler grpc.UnaryHandler) grpc.UnaryHandler {
+	info := &grpc.UnaryServerInfo{FullMethod: "/svc/Method"}
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		next, interceptor := handler, interceptors[i]
+		handler = func(ctx context.Context, req any) (any, error) {
+			return interceptor(ctx, req, info, next)
+		}
+	}
+	return handler
+}
+
+func TestRequestIDUnaryInterceptor(t *testing.T) {
+	tests := []struct {
+		name string
+		md   metadata.MD
+		want string
+	}{
+		{name: "received", md: metadata.Pairs(RequestIDHeader, "req-1"), want: "req-1"},
+		{name: "generated", md: metadata.MD{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := metadata.NewIncomingContext(context.Background(), tt.md)
+			var id string
+			var incoming metadata.MD
+			handler := func(ctx context.Context, _ any) (any, error) {
+				id = RequestIDFromContext(ctx)
+				incoming, _ = metadata.FromIncomingContext(ctx)
+				return nil, nil
+			}
+
+			// Act
+			_, err := RequestIDUnaryInterceptor()(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+
+			// Assert
+			require.NoError(t, err)
+			if tt.want != "" {
+				assert.Equal(t, tt.want, id)
+			} else {
+				assert.Len(t, id, 32)
+			}
+			assert.Equal(t, []string{id}, incoming.Get(RequestIDHeader))
+		})
+	}
+}
+
+func TestRecoveryUnaryInterceptor(t *testing.T) {
+	// Arrange
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+	handler := func(context.Context, any) (any, error) {
+		panic("boom")
+	}
+
+	// Act
+	_, err := RecoveryUnaryInterceptor(logger)(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/svc/Method"}, handler)
+
+	// Assert
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Contains(t, logs.String(), "panic=boom")
+	assert.Contains(t, logs.String(), "method=/svc/Method")
+}
+
+// recordingReporter records the reports it receives
+type recordingReporter struct {
+	reports []errreport.Report
+}
+
+func (r *recordingReporter) Report(_ context.Context, report errreport.Report) {
+	r.reports = append(r.reports, report)
+}
+
+func TestRecoveryUnaryInterceptor_Reporters(t *testing.T) {
+	// Arrange
+	reporter := &recordingReporter{}
+	handler := func(context.Context, any) (any, error) {
+		panic("boom")
+	}
+
+	// Act
+	_, err := RecoveryUnaryInterceptor(slog.New(slog.DiscardHandler), reporter)(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/svc/Method"}, handler)
+
+	// Assert
+	assert.Equal(t, codes.Internal, status.Code(err))
+	require.Len(t, reporter.reports, 1)
+	assert.Equal(t, "boom", reporter.reports[0].Panic)
+	assert.Equal(t, map[string]string{"method": "/svc/Method"}, reporter.reports[0].Tags)
+}
+
+func TestErrorReportingUnaryInterceptor(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		reported []codes.Code
+		want     string
+	}{
+		{name: "default code", err: status.Error(codes.Internal, "corrupted"), want: "Internal"},
+		{name: "unreported code", err: status.Error(codes.NotFound, "missing")},
+		{name: "configured code", err: status.Error(codes.Unavailable, "down"), reported: []codes.Code{codes.Unavailable}, want: "Unavailable"},
+		{name: "success"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			reporter := &recordingReporter{}
+			handler := func(context.Context, any) (any, error) {
+				return nil, tt.err
+			}
+
+			// Act
+			_, err := ErrorReportingUnaryInterceptor(reporter, tt.reported...)(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/svc/Method"}, handler)
+
+			// Assert
+			assert.Equal(t, tt.err, err)
+			if tt.want == "" {
+				assert.Empty(t, reporter.reports)
+				return
+			}
+			require.Len(t, reporter.reports, 1)
+			assert.Equal(t, tt.err, reporter.reports[0].Err)
+			assert.Equal(t, tt.want, reporter.reports[0].Tags["code"])
+		})
+	}
+}
+
+func TestDeadlineUnaryInterceptor(t *testing.T) {
+	existing, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want time.Duration
+	}{
+		{name: "without deadline", ctx: context.Background(), want: time.Minute},
+		{name: "with deadline", ctx: existing, want: time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var remaining time.Duration
+			handler := func(ctx context.Context, _ any) (any, error) {
+				deadline, ok := ctx.Deadline()
+				require.True(t, ok)
+				remaining = time.Until(deadline)
+				return nil, nil
+			}
+
+			// Act
+			_, err := DeadlineUnaryInterceptor(time.Minute)(tt.ctx, nil, &grpc.UnaryServerInfo{}, handler)
+
+			// Assert
+			require.NoError(t, err)
+			assert.InDelta(t, tt.want, remaining, float64(time.Second))
+		})
+	}
+}
+
+func TestUnaryInterceptors_LogRecoveredPanic(t *testing.T) {
+	// Arrange
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(RequestIDHeader, "req-1"))
+	handler := chain(UnaryInterceptors(logger), func(context.Context, any) (any, error) {
+		panic("boom")
+	})
+
+	// Act
+	_, err := handler(ctx, nil)
+
+	// Assert
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Contains(t, logs.String(), `msg="gRPC call" method=/svc/Method code=Internal`)
+	assert.Contains(t, logs.String(), "request_id=req-1")
+}
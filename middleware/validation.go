@@ -0,0 +1,158 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The methods generated by protoc-gen-validate and the errors they return
+type (
+	validator interface {
+		Validate() error
+	}
+	allValidator interface {
+		ValidateAll() error
+	}
+	fieldError interface {
+		Field() string
+		Reason() string
+	}
+	multiError interface {
+		AllErrors() []error
+	}
+	causer interface {
+		Cause() error
+	}
+)
+
+// ValidationUnaryInterceptor rejects requests whose ValidateAll or Validate method, as
+// generated by protoc-gen-validate, fails. They are answered with InvalidArgument and a
+// google.rpc.BadRequest detail holding a violation per field, which the gateway renders
+// in the details of its JSON error body. Requests without these methods pass through.
+func ValidationUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := validateMessage(req); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// ValidationStreamInterceptor is the stream counterpart of ValidationUnaryInterceptor,
+// validating every message received from the client
+func ValidationStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &validatingStream{ServerStream: ss})
+	}
+}
+
+// validatingStream validates the messages it receives
+type validatingStream struct {
+	grpc.ServerStream
+}
+
+func (s *validatingStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return validateMessage(m)
+}
+
+// validateMessage validates a message, preferring ValidateAll to report every violation
+func validateMessage(m any) error {
+	var err error
+	switch v := m.(type) {
+	case allValidator:
+		err = v.ValidateAll()
+	case validator:
+		err = v.Validate()
+	default:
+		return nil
+	}
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return InvalidArgument(err.Error(), FieldViolations(err)...)
+}
+
+// InvalidArgument returns an InvalidArgument error with a google.rpc.BadRequest detail
+// holding the violations, for handlers validating requests themselves
+func InvalidArgument(msg string, violations ...*errdetails.BadRequest_FieldViolation) error {
+	st := status.New(codes.InvalidArgument, msg)
+	if len(violations) == 0 {
+		return st.Err()
+	}
+	withDetails, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if err != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
+
+// FieldViolations returns the violations of a protoc-gen-validate error, one per failed
+// rule. Fields of nested messages are joined with dots, such as address.city.
+func FieldViolations(err error) []*errdetails.BadRequest_FieldViolation {
+	var violations []*errdetails.BadRequest_FieldViolation
+	collectViolations(err, nil, &violations)
+	return violations
+}
+
+// collectViolations appends the violations of err, its fields under the parent path
+func collectViolations(err error, parent []string, violations *[]*errdetails.BadRequest_FieldViolation) {
+	var multi multiError
+	if errors.As(err, &multi) {
+		for _, e := range multi.AllErrors() {
+			collectViolations(e, parent, violations)
+		}
+		return
+	}
+
+	var fe fieldError
+	if !errors.As(err, &fe) {
+		*violations = append(*violations, &errdetails.BadRequest_FieldViolation{
+			Field:       strings.Join(parent, "."),
+			Description: err.Error(),
+		})
+		return
+	}
+	path := append(parent[:len(parent):len(parent)], snakeCase(fe.Field()))
+
+	// Nested messages report their own violations as the cause
+	if c, ok := fe.(causer); ok && c.Cause() != nil {
+		var nested fieldError
+		var nestedMulti multiError
+		if errors.As(c.Cause(), &nested) || errors.As(c.Cause(), &nestedMulti) {
+			collectViolations(c.Cause(), path, violations)
+			return
+		}
+	}
+	*violations = append(*violations, &errdetails.BadRequest_FieldViolation{
+		Field:       strings.Join(path, "."),
+		Description: fe.Reason(),
+	})
+}
+
+// snakeCase turns the Go field names of protoc-gen-validate errors, such as
+// DisplayName, into proto field names such as display_name
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
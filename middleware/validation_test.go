@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// pgvError mimics the field errors generated by protoc-gen-validate
+type pgvError struct {
+	field  string
+	reason string
+	cause  error
+}
+
+func (e pgvError) Field() string  { return e.field }
+func (e pgvError) Reason() string { return e.reason }
+func (e pgvError) Cause() error   { return e.cause }
+func (e pgvError) Error() string  { return "invalid " + e.field + ": " + e.reason }
+
+// pgvMultiError mimics the errors of the generated ValidateAll methods
+type pgvMultiError []error
+
+func (m pgvMultiError) Error() string      { return errors.Join(m...).Error() }
+func (m pgvMultiError) AllErrors() []error { return m }
+
+// createOrderRequest validates like a protoc-gen-validate message
+type createOrderRequest struct {
+	validateAll error
+}
+
+func (r createOrderRequest) Validate() error    { return errors.New("first violation only") }
+func (r createOrderRequest) ValidateAll() error { return r.validateAll }
+
+func TestValidationUnaryInterceptor(t *testing.T) {
+	tests := []struct {
+		name           string
+		req            any
+		wantCode       codes.Code
+		wantViolations map[string]string
+	}{
+		{name: "valid", req: createOrderRequest{}, wantCode: codes.OK},
+		{name: "no validation methods", req: struct{}{}, wantCode: codes.OK},
+		{
+			name: "field violations",
+			req: createOrderRequest{validateAll: pgvMultiError{
+				pgvError{field: "CustomerId", reason: "value length must be at least 1 runes"},
+				pgvError{field: "Address", reason: "embedded message failed validation", cause: pgvMultiError{
+					pgvError{field: "PostalCode", reason: "value does not match regex pattern"},
+				}},
+			}},
+			wantCode: codes.InvalidArgument,
+			wantViolations: map[string]string{
+				"customer_id":         "value length must be at least 1 runes",
+				"address.postal_code": "value does not match regex pattern",
+			},
+		},
+		{
+			name:     "status errors pass through",
+			req:      createOrderRequest{validateAll: status.Error(codes.FailedPrecondition, "closed")},
+			wantCode: codes.FailedPrecondition,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			interceptor := ValidationUnaryInterceptor()
+			handler := func(context.Context, any) (any, error) { return "ok", nil }
+
+			// Act
+			_, err := interceptor(context.Background(), tt.req, &grpc.UnaryServerInfo{FullMethod: "/orders.v1.Orders/Create"}, handler)
+
+			// Assert
+			st := status.Convert(err)
+			require.Equal(t, tt.wantCode, st.Code())
+			if tt.wantViolations == nil {
+				return
+			}
+			require.Len(t, st.Details(), 1)
+			badRequest, ok := st.Details()[0].(*errdetails.BadRequest)
+			require.True(t, ok)
+			violations := map[string]string{}
+			for _, v := range badRequest.GetFieldViolations() {
+				violations[v.GetField()] = v.GetDescription()
+			}
+			assert.Equal(t, tt.wantViolations, violations)
+		})
+	}
+}
+
+func TestInvalidArgument(t *testing.T) {
+	// Act
+	err := InvalidArgument("invalid order", &errdetails.BadRequest_FieldViolation{Field: "quantity", Description: "must be positive"})
+
+	// Assert
+	st := status.Convert(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	assert.Equal(t, "invalid order", st.Message())
+	require.Len(t, st.Details(), 1)
+	assert.Equal(t, "quantity", st.Details()[0].(*errdetails.BadRequest).GetFieldViolations()[0].GetField())
+}
@@ -0,0 +1,132 @@
+// Package migrate runs database migrations before a server starts serving. Migrations
+// run in the PreRun of a process placed after the user's processes, so database pools
+// are connected, and before the servers, so no listener accepts requests on an outdated
+// schema. A Locker keeps replicas starting together from migrating concurrently.
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Runner applies the pending migrations, e.g. a golang-migrate or goose wrapper. It must
+// return nil when the schema is already up to date.
+type Runner interface {
+	Migrate(ctx context.Context) error
+}
+
+// RunnerFunc adapts a function to a Runner
+type RunnerFunc func(ctx context.Context) error
+
+// Migrate calls f
+func (f RunnerFunc) Migrate(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Locker serializes migrations across replicas. Lock blocks until the lock is held or
+// ctx ends and returns the function releasing it.
+type Locker interface {
+	Lock(ctx context.Context) (unlock func(context.Context) error, err error)
+}
+
+// Option configures a Process
+type Option func(*Process)
+
+// WithLocker holds lock while migrating, so only one replica migrates at a time and the
+// others find the schema up to date once they get the lock
+func WithLocker(lock Locker) Option {
+	return func(p *Process) {
+		p.locker = lock
+	}
+}
+
+// WithTimeout bounds waiting for the lock and running the migrations, unbounded by default
+func WithTimeout(timeout time.Duration) Option {
+	return func(p *Process) {
+		p.timeout = timeout
+	}
+}
+
+// WithLogger sets the logger of the process, slog.Default() by default
+func WithLogger(logger *slog.Logger) Option {
+	return func(p *Process) {
+		p.logger = logger
+	}
+}
+
+// Process runs migrations in its PreRun
+type Process struct {
+	runner  Runner
+	locker  Locker
+	timeout time.Duration
+	logger  *slog.Logger
+}
+
+// NewProcess creates a process running runner
+func NewProcess(runner Runner, opts ...Option) *Process {
+	p := &Process{
+		runner: runner,
+		logger: slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// PreRun runs the migrations, failing startup when they fail
+func (p *Process) PreRun(ctx context.Context) (err error) {
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+
+	ctx, span := otel.Tracer("migrate").Start(ctx, "migrate", trace.WithSpanKind(trace.SpanKindInternal))
+	defer func() {
+		if err != nil {
+			span.SetStatus(otelcodes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	if p.locker != nil {
+		p.logger.Info("waiting for migration lock")
+		unlock, err := p.locker.Lock(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		defer func() {
+			// Release the lock even when ctx timed out during the migrations
+			if unlockErr := unlock(context.WithoutCancel(ctx)); unlockErr != nil {
+				err = errors.Join(err, fmt.Errorf("failed to release migration lock: %w", unlockErr))
+			}
+		}()
+	}
+
+	p.logger.Info("running migrations")
+	start := time.Now()
+	if err := p.runner.Migrate(ctx); err != nil {
+		p.logger.Error("migrations failed", "error", err, "duration", time.Since(start))
+		return fmt.Errorf("migrations failed: %w", err)
+	}
+	p.logger.Info("migrations completed", "duration", time.Since(start))
+	return nil
+}
+
+// Run returns immediately, the migrations ran in PreRun
+func (*Process) Run(_ context.Context) error {
+	return nil
+}
+
+// Shutdown has nothing to release
+func (*Process) Shutdown(_ context.Context) error {
+	return nil
+}
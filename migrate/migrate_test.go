@@ -0,0 +1,94 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLocker records lock and unlock calls in steps
+type fakeLocker struct {
+	steps   *[]string
+	lockErr error
+}
+
+func (l *fakeLocker) Lock(ctx context.Context) (func(context.Context) error, error) {
+	if l.lockErr != nil {
+		return nil, l.lockErr
+	}
+	*l.steps = append(*l.steps, "lock")
+	return func(ctx context.Context) error {
+		*l.steps = append(*l.steps, "unlock")
+		return ctx.Err()
+	}, nil
+}
+
+func TestProcess_PreRun(t *testing.T) {
+	tests := []struct {
+		name       string
+		migrateErr error
+		lockErr    error
+		wantSteps  []string
+		wantErr    string
+	}{
+		{
+			name:      "migrates holding the lock",
+			wantSteps: []string{"lock", "migrate", "unlock"},
+		},
+		{
+			name:       "releases the lock when migrations fail",
+			migrateErr: errors.New("dirty database version 3"),
+			wantSteps:  []string{"lock", "migrate", "unlock"},
+			wantErr:    "migrations failed: dirty database version 3",
+		},
+		{
+			name:    "does not migrate without the lock",
+			lockErr: errors.New("connection refused"),
+			wantErr: "failed to acquire migration lock: connection refused",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var steps []string
+			runner := RunnerFunc(func(context.Context) error {
+				steps = append(steps, "migrate")
+				return tt.migrateErr
+			})
+			p := NewProcess(runner, WithLocker(&fakeLocker{steps: &steps, lockErr: tt.lockErr}))
+
+			// Act
+			err := p.PreRun(context.Background())
+
+			// Assert
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantSteps, steps)
+		})
+	}
+}
+
+func TestProcess_PreRunTimeout(t *testing.T) {
+	// Arrange
+	var steps []string
+	runner := RunnerFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	p := NewProcess(runner, WithLocker(&fakeLocker{steps: &steps}), WithTimeout(10*time.Millisecond))
+
+	// Act
+	err := p.PreRun(context.Background())
+
+	// Assert
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, []string{"lock", "unlock"}, steps, "the lock is released after the timeout")
+}
@@ -0,0 +1,47 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+)
+
+// postgresLock is a PostgreSQL session-level advisory lock
+type postgresLock struct {
+	db  *sql.DB
+	key int64
+}
+
+// PostgresLock returns a Locker taking the advisory lock named name on db. The lock is
+// held by a dedicated connection, so PostgreSQL releases it if the process dies while
+// migrating.
+func PostgresLock(db *sql.DB, name string) Locker {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name))
+	return &postgresLock{db: db, key: int64(h.Sum64())} //nolint:gosec // the hash is only a lock id
+}
+
+// Lock waits for the advisory lock
+func (l *postgresLock) Lock(ctx context.Context) (func(context.Context) error, error) {
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", l.key); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return func(ctx context.Context) error {
+		defer conn.Close()
+		var unlocked bool
+		if err := conn.QueryRowContext(ctx, "SELECT pg_advisory_unlock($1)", l.key).Scan(&unlocked); err != nil {
+			return err
+		}
+		if !unlocked {
+			return fmt.Errorf("advisory lock %d was not held", l.key)
+		}
+		return nil
+	}, nil
+}
@@ -0,0 +1,107 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// advisoryDriver records the statements run on its connections and answers the unlock
+// query with true
+type advisoryDriver struct {
+	mu      sync.Mutex
+	queries []string
+}
+
+func (d *advisoryDriver) Open(string) (driver.Conn, error) {
+	return &advisoryConn{driver: d}, nil
+}
+
+func (d *advisoryDriver) record(query string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.queries = append(d.queries, query)
+}
+
+type advisoryConn struct {
+	driver *advisoryDriver
+}
+
+func (c *advisoryConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.driver.record(query)
+	return driver.RowsAffected(0), nil
+}
+
+func (c *advisoryConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.driver.record(query)
+	return &boolRows{value: true}, nil
+}
+
+func (*advisoryConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (*advisoryConn) Close() error {
+	return nil
+}
+
+func (*advisoryConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("not supported")
+}
+
+// boolRows is a single row with a single boolean column
+type boolRows struct {
+	value bool
+	done  bool
+}
+
+func (*boolRows) Columns() []string {
+	return []string{"result"}
+}
+
+func (*boolRows) Close() error {
+	return nil
+}
+
+func (r *boolRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = r.value
+	return nil
+}
+
+var testDriver = &advisoryDriver{}
+
+func init() {
+	sql.Register("advisorytest", testDriver)
+}
+
+func TestPostgresLock(t *testing.T) {
+	// Arrange
+	db, err := sql.Open("advisorytest", "")
+	require.NoError(t, err)
+	defer db.Close()
+	lock := PostgresLock(db, "orders")
+
+	// Act
+	unlock, lockErr := lock.Lock(context.Background())
+	require.NoError(t, lockErr)
+	unlockErr := unlock(context.Background())
+
+	// Assert
+	require.NoError(t, unlockErr)
+	assert.Equal(t, []string{
+		"SELECT pg_advisory_lock($1)",
+		"SELECT pg_advisory_unlock($1)",
+	}, testDriver.queries)
+	assert.Equal(t, lock, PostgresLock(db, "orders"), "the key is derived from the name")
+}
@@ -0,0 +1,69 @@
+package redis
+
+import (
+	"sync"
+
+	"github.com/legrch/netgex/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// collector exports the statistics of the clients of a namespace when scraped
+type collector struct {
+	mu      sync.Mutex
+	clients map[string]*Client
+
+	connections *prometheus.Desc
+	timeouts    *prometheus.Desc
+}
+
+// add starts exporting the statistics of a client
+func (c *collector) add(name string, client *Client) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.clients[name] = client
+}
+
+// remove stops exporting the statistics of a client
+func (c *collector) remove(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.clients, name)
+}
+
+// Describe implements prometheus.Collector
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.connections
+	ch <- c.timeouts
+}
+
+// Collect implements prometheus.Collector
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for name, client := range c.clients {
+		if _, ok := client.conn.(PoolStatser); !ok {
+			continue
+		}
+		stats := client.Stats()
+		ch <- prometheus.MustNewConstMetric(c.connections, prometheus.GaugeValue, float64(stats.Open-stats.Idle), name, "in_use")
+		ch <- prometheus.MustNewConstMetric(c.connections, prometheus.GaugeValue, float64(stats.Idle), name, "idle")
+		ch <- prometheus.MustNewConstMetric(c.timeouts, prometheus.CounterValue, float64(stats.Timeouts), name)
+	}
+}
+
+// statsCollector returns the collector of the namespace, registering it unless already
+// registered
+func statsCollector(namespace string) *collector {
+	name := func(metric string) string {
+		return prometheus.BuildFQName(namespace, "redis_pool", metric)
+	}
+	c := &collector{
+		clients: map[string]*Client{},
+		connections: prometheus.NewDesc(name("connections"),
+			"Number of connections of the Redis client by state: in_use or idle", []string{"client", "state"}, nil),
+		timeouts: prometheus.NewDesc(name("timeouts_total"),
+			"Total number of times a Redis command timed out waiting for a connection", []string{"client"}, nil),
+	}
+	return metrics.Register(c)
+}
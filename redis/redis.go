@@ -0,0 +1,247 @@
+// Package redis runs a Redis client as a server process: it pings the server in PreRun
+// so an unreachable server fails startup, reports its health to the server's readiness
+// checks, exports the statistics of its connection pool and closes it on shutdown. Pings
+// and the commands run through the Client get a client span each. The protocol is left to
+// the client, such as go-redis, behind the small Conn interface:
+//
+//	type goRedis struct{ *goredis.Client }
+//
+//	func (c goRedis) Ping(ctx context.Context) error { return c.Client.Ping(ctx).Err() }
+//
+//	func (c goRedis) PoolStats() redis.Stats {
+//		s := c.Client.PoolStats()
+//		return redis.Stats{Open: int(s.TotalConns), Idle: int(s.IdleConns), Timeouts: uint64(s.Timeouts)}
+//	}
+//
+//	sessions := redis.New(goRedis{goredis.NewClient(&goredis.Options{Addr: "redis:6379"})})
+package redis
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Conn is the Redis client a Client manages
+type Conn interface {
+	// Ping checks the server responds
+	Ping(ctx context.Context) error
+	// Close closes the connections of the client
+	Close() error
+}
+
+// Commands is implemented by the Conns running the commands of the Client, such as
+// those of httpcache.RedisStore
+type Commands interface {
+	// Get returns the value of key, reporting whether it was found
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores value under key, expiring after ttl; 0 keeps it
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Del deletes the keys
+	Del(ctx context.Context, keys ...string) error
+}
+
+// PoolStatser is implemented by the Conns reporting the statistics of their connection
+// pool, exported with WithMetrics
+type PoolStatser interface {
+	PoolStats() Stats
+}
+
+// Stats are the connection statistics of a client
+type Stats struct {
+	// Open is the number of open connections, in use or idle
+	Open int
+	// Idle is the number of connections waiting in the pool
+	Idle int
+	// Timeouts is the number of times a command timed out waiting for a connection
+	Timeouts uint64
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithName names the client in logs, health errors and metrics, "default" by default
+func WithName(name string) Option {
+	return func(c *Client) {
+		c.name = name
+	}
+}
+
+// WithLogger sets the logger of the client, slog.Default() by default
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// WithPingTimeout bounds the ping of PreRun and of health checks, 5s by default
+func WithPingTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.pingTimeout = timeout
+	}
+}
+
+// WithMetrics exports the connection statistics of a Conn implementing PoolStatser in
+// the redis_pool_* metrics of the namespace, labeled with the client name
+func WithMetrics(namespace string) Option {
+	return func(c *Client) {
+		c.namespace = namespace
+		c.metrics = true
+	}
+}
+
+// Client is the process managing a Redis client
+type Client struct {
+	conn        Conn
+	name        string
+	logger      *slog.Logger
+	pingTimeout time.Duration
+	namespace   string
+	metrics     bool
+}
+
+// New creates the process managing conn
+func New(conn Conn, opts ...Option) *Client {
+	c := &Client{
+		conn:        conn,
+		name:        "default",
+		logger:      slog.Default(),
+		pingTimeout: 5 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Conn returns the managed Redis client
+func (c *Client) Conn() Conn {
+	return c.conn
+}
+
+// Name returns the name of the client
+func (c *Client) Name() string {
+	return "redis/" + c.name
+}
+
+// Stats returns the connection statistics of the client, zero when its Conn does not
+// implement PoolStatser
+func (c *Client) Stats() Stats {
+	if s, ok := c.conn.(PoolStatser); ok {
+		return s.PoolStats()
+	}
+	return Stats{}
+}
+
+// Get returns the value of key with the Commands of the Conn
+func (c *Client) Get(ctx context.Context, key string) (value []byte, found bool, err error) {
+	err = c.trace(ctx, "GET", func(ctx context.Context) error {
+		commands, err := c.commands()
+		if err != nil {
+			return err
+		}
+		value, found, err = commands.Get(ctx, key)
+		return err
+	})
+	return value, found, err
+}
+
+// Set stores value under key for ttl with the Commands of the Conn
+func (c *Client) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.trace(ctx, "SET", func(ctx context.Context) error {
+		commands, err := c.commands()
+		if err != nil {
+			return err
+		}
+		return commands.Set(ctx, key, value, ttl)
+	})
+}
+
+// Del deletes the keys with the Commands of the Conn
+func (c *Client) Del(ctx context.Context, keys ...string) error {
+	return c.trace(ctx, "DEL", func(ctx context.Context) error {
+		commands, err := c.commands()
+		if err != nil {
+			return err
+		}
+		return commands.Del(ctx, keys...)
+	})
+}
+
+// commands returns the Commands of the Conn
+func (c *Client) commands() (Commands, error) {
+	commands, ok := c.conn.(Commands)
+	if !ok {
+		return nil, fmt.Errorf("redis %s: the client does not implement redis.Commands", c.name)
+	}
+	return commands, nil
+}
+
+// PreRun pings the server, failing startup when it is unreachable
+func (c *Client) PreRun(ctx context.Context) error {
+	if err := c.ping(ctx); err != nil {
+		return fmt.Errorf("failed to connect to redis %s: %w", c.name, err)
+	}
+	if c.metrics {
+		statsCollector(c.namespace).add(c.name, c)
+	}
+	c.logger.Info("connected to redis", "client", c.name)
+	return nil
+}
+
+// Run keeps the client open until the context is canceled
+func (c *Client) Run(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+// HealthCheck pings the server, failing readiness while it is unreachable
+func (c *Client) HealthCheck(ctx context.Context) error {
+	return c.ping(ctx)
+}
+
+// Shutdown closes the client once the servers no longer use it
+func (c *Client) Shutdown(_ context.Context) error {
+	c.logger.Info("closing redis client", "client", c.name)
+	if c.metrics {
+		statsCollector(c.namespace).remove(c.name)
+	}
+	if err := c.conn.Close(); err != nil {
+		return fmt.Errorf("failed to close redis %s: %w", c.name, err)
+	}
+	return nil
+}
+
+// ping pings the server within the ping timeout
+func (c *Client) ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, c.pingTimeout)
+	defer cancel()
+	return c.trace(ctx, "PING", c.conn.Ping)
+}
+
+// trace runs a command in a client span
+func (c *Client) trace(ctx context.Context, command string, run func(context.Context) error) error {
+	// Arguments are left out of the span as they may hold values or passwords
+	ctx, span := otel.Tracer("redis").Start(ctx, "redis "+command,
+		trace.WithAttributes(
+			attribute.String("db.system", "redis"),
+			attribute.String("db.operation", command),
+			attribute.String("redis.client", c.name),
+		),
+		trace.WithSpanKind(trace.SpanKindClient),
+	)
+	defer span.End()
+
+	if err := run(ctx); err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// fakeConn is a Redis client whose pings fail while down is set, keeping values in a map
+type fakeConn struct {
+	down   atomic.Bool
+	closed atomic.Bool
+	stats  Stats
+	values map[string][]byte
+}
+
+func (c *fakeConn) Get(_ context.Context, key string) ([]byte, bool, error) {
+	value, ok := c.values[key]
+	return value, ok, nil
+}
+
+func (c *fakeConn) Set(_ context.Context, key string, value []byte, _ time.Duration) error {
+	c.values[key] = value
+	return nil
+}
+
+func (c *fakeConn) Del(_ context.Context, keys ...string) error {
+	for _, key := range keys {
+		delete(c.values, key)
+	}
+	return nil
+}
+
+func (c *fakeConn) Ping(ctx context.Context) error {
+	if c.down.Load() {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return nil
+}
+
+func (c *fakeConn) Close() error {
+	if c.closed.Swap(true) {
+		return errors.New("already closed")
+	}
+	return nil
+}
+
+func (c *fakeConn) PoolStats() Stats {
+	return c.stats
+}
+
+func TestClient_Lifecycle(t *testing.T) {
+	// Arrange
+	conn := &fakeConn{stats: Stats{Open: 5, Idle: 3, Timeouts: 2}}
+	c := New(conn, WithName("sessions"), WithPingTimeout(10*time.Millisecond), WithMetrics("redistest"))
+
+	// Act
+	err := c.PreRun(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Same(t, conn, c.Conn())
+	assert.Equal(t, "redis/sessions", c.Name())
+	assert.NoError(t, c.HealthCheck(context.Background()))
+
+	conn.down.Store(true)
+	assert.ErrorIs(t, c.HealthCheck(context.Background()), context.DeadlineExceeded)
+	conn.down.Store(false)
+
+	expected := `
+# HELP redistest_redis_pool_connections Number of connections of the Redis client by state: in_use or idle
+# TYPE redistest_redis_pool_connections gauge
+redistest_redis_pool_connections{client="sessions",state="idle"} 3
+redistest_redis_pool_connections{client="sessions",state="in_use"} 2
+# HELP redistest_redis_pool_timeouts_total Total number of times a Redis command timed out waiting for a connection
+# TYPE redistest_redis_pool_timeouts_total counter
+redistest_redis_pool_timeouts_total{client="sessions"} 2
+`
+	col := statsCollector("redistest")
+	assert.NoError(t, testutil.CollectAndCompare(col, strings.NewReader(expected)))
+
+	require.NoError(t, c.Shutdown(context.Background()))
+	assert.True(t, conn.closed.Load())
+	assert.Equal(t, 0, testutil.CollectAndCount(col))
+	assert.ErrorContains(t, c.Shutdown(context.Background()), "failed to close redis sessions")
+}
+
+func TestClient_PreRunFailsWhenUnreachable(t *testing.T) {
+	// Arrange
+	conn := &fakeConn{}
+	conn.down.Store(true)
+	c := New(conn, WithPingTimeout(10*time.Millisecond))
+
+	// Act
+	err := c.PreRun(context.Background())
+
+	// Assert
+	assert.ErrorContains(t, err, "failed to connect to redis default")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestClient_StatsWithoutPoolStats(t *testing.T) {
+	// Arrange
+	c := New(struct{ Conn }{&fakeConn{}})
+
+	// Act
+	stats := c.Stats()
+
+	// Assert
+	assert.Equal(t, Stats{}, stats)
+}
+
+func TestClient_Tracing(t *testing.T) {
+	// Arrange
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	conn := &fakeConn{values: map[string][]byte{}}
+	c := New(conn, WithName("sessions"))
+	ctx := context.Background()
+
+	// Act
+	require.NoError(t, c.HealthCheck(ctx))
+	require.NoError(t, c.Set(ctx, "key", []byte("secret"), time.Minute))
+	value, found, err := c.Get(ctx, "key")
+	require.NoError(t, err)
+	require.NoError(t, c.Del(ctx, "key"))
+	unsupported := New(struct{ Conn }{conn})
+	_, _, unsupportedErr := unsupported.Get(ctx, "key")
+
+	// Assert
+	assert.True(t, found)
+	assert.Equal(t, []byte("secret"), value)
+	assert.ErrorContains(t, unsupportedErr, "does not implement redis.Commands")
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 5)
+	for i, name := range []string{"redis PING", "redis SET", "redis GET", "redis DEL", "redis GET"} {
+		assert.Equal(t, name, spans[i].Name())
+		assert.Equal(t, trace.SpanKindClient, spans[i].SpanKind())
+		assert.Contains(t, spans[i].Attributes(), attribute.String("db.system", "redis"))
+	}
+	assert.Contains(t, spans[1].Attributes(), attribute.String("redis.client", "sessions"))
+	for _, attr := range spans[1].Attributes() {
+		assert.NotContains(t, attr.Value.Emit(), "secret", "arguments are left out")
+	}
+	assert.Equal(t, codes.Unset, spans[2].Status().Code)
+	assert.Equal(t, codes.Error, spans[4].Status().Code)
+}
+
+func TestWithMetrics_SharedBetweenClients(t *testing.T) {
+	// Arrange
+	first := New(&fakeConn{}, WithName("first"), WithMetrics("redisshared"))
+	second := New(&fakeConn{}, WithName("second"), WithMetrics("redisshared"))
+
+	// Act
+	assert.NotPanics(t, func() {
+		require.NoError(t, first.PreRun(context.Background()))
+		require.NoError(t, second.PreRun(context.Background()))
+	})
+
+	// Assert
+	assert.Same(t, statsCollector("redisshared"), statsCollector("redisshared"))
+	assert.Equal(t, 2, testutil.CollectAndCount(statsCollector("redisshared"), "redisshared_redis_pool_timeouts_total"))
+}
@@ -0,0 +1,272 @@
+// Package rollbar reports errors and panics to Rollbar. The Client is an
+// errreport.ErrorReporter: it queues reports, sends them in the background while it runs
+// as a server process and flushes the pending ones on shutdown.
+package rollbar
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"runtime"
+	"time"
+
+	"google.golang.org/grpc/status"
+
+	"github.com/legrch/netgex/errreport"
+	"github.com/legrch/netgex/internal/eventqueue"
+)
+
+// DefaultEndpoint is the Rollbar API receiving items
+const DefaultEndpoint = "https://api.rollbar.com/api/1/item/"
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithEnvironment sets the environment of items, production by default
+func WithEnvironment(environment string) Option {
+	return func(c *Client) {
+		c.environment = environment
+	}
+}
+
+// WithCodeVersion tags items with the code version, e.g. the service version
+func WithCodeVersion(version string) Option {
+	return func(c *Client) {
+		c.codeVersion = version
+	}
+}
+
+// WithServerHost sets the server host of items, the hostname by default
+func WithServerHost(host string) Option {
+	return func(c *Client) {
+		c.host = host
+	}
+}
+
+// WithEndpoint sets the URL items are posted to, DefaultEndpoint by default
+func WithEndpoint(endpoint string) Option {
+	return func(c *Client) {
+		c.endpoint = endpoint
+	}
+}
+
+// WithQueueSize sets how many items wait to be sent before new ones are dropped, 100 by
+// default
+func WithQueueSize(size int) Option {
+	return func(c *Client) {
+		c.queueSize = size
+	}
+}
+
+// WithTimeout bounds sending an item, 5s by default
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithHTTPClient sets the HTTP client sending items
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = client
+	}
+}
+
+// WithLogger sets the logger of the client, slog.Default() by default
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// Client sends items to the Rollbar project of an access token
+type Client struct {
+	token       string
+	endpoint    string
+	environment string
+	codeVersion string
+	host        string
+	queueSize   int
+	timeout     time.Duration
+	httpClient  *http.Client
+	logger      *slog.Logger
+
+	queue *eventqueue.Queue[*item]
+}
+
+// New creates a client sending items with a post_server_item access token
+func New(token string, opts ...Option) (*Client, error) {
+	if token == "" {
+		return nil, errors.New("rollbar access token is required")
+	}
+
+	hostname, _ := os.Hostname()
+	c := &Client{
+		token:       token,
+		endpoint:    DefaultEndpoint,
+		environment: "production",
+		host:        hostname,
+		queueSize:   100,
+		timeout:     5 * time.Second,
+		httpClient:  http.DefaultClient,
+		logger:      slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.queue = eventqueue.New(c.queueSize, c.send)
+	return c, nil
+}
+
+// item is the subset of the Rollbar item payload the client sends
+type item struct {
+	Data data `json:"data"`
+}
+
+type data struct {
+	Environment string            `json:"environment"`
+	Level       string            `json:"level"`
+	Timestamp   int64             `json:"timestamp"`
+	CodeVersion string            `json:"code_version,omitempty"`
+	Platform    string            `json:"platform"`
+	Language    string            `json:"language"`
+	Context     string            `json:"context,omitempty"`
+	Server      server            `json:"server"`
+	Body        body              `json:"body"`
+	Custom      map[string]string `json:"custom,omitempty"`
+}
+
+type server struct {
+	Host string `json:"host,omitempty"`
+}
+
+type body struct {
+	Trace traceBody `json:"trace"`
+}
+
+type traceBody struct {
+	Frames    []frame   `json:"frames"`
+	Exception exception `json:"exception"`
+}
+
+type frame struct {
+	Filename string `json:"filename"`
+	Lineno   int    `json:"lineno"`
+	Method   string `json:"method"`
+}
+
+type exception struct {
+	Class   string `json:"class"`
+	Message string `json:"message"`
+}
+
+// Report queues the item of a report, dropping it when the queue is full
+func (c *Client) Report(_ context.Context, r errreport.Report) {
+	it := &item{Data: data{
+		Environment: c.environment,
+		Level:       "error",
+		Timestamp:   time.Now().Unix(),
+		CodeVersion: c.codeVersion,
+		Platform:    "go",
+		Language:    "go",
+		Context:     r.Tags["method"],
+		Server:      server{Host: c.host},
+		Body:        body{Trace: traceBody{Frames: frames(r.Stack), Exception: newException(r)}},
+		Custom:      r.Tags,
+	}}
+	if r.Panic != nil {
+		it.Data.Level = "critical"
+	}
+
+	if !c.queue.Enqueue(it) {
+		c.logger.Warn("rollbar item dropped")
+	}
+}
+
+// newException describes the failure of a report: the panic value, the code and message
+// of gRPC errors, or the type and message of other errors
+func newException(r errreport.Report) exception {
+	if r.Panic != nil {
+		return exception{Class: fmt.Sprintf("%T", r.Panic), Message: fmt.Sprint(r.Panic)}
+	}
+	if st, ok := status.FromError(r.Err); ok {
+		return exception{Class: st.Code().String(), Message: st.Message()}
+	}
+	return exception{Class: fmt.Sprintf("%T", r.Err), Message: r.Err.Error()}
+}
+
+// frames converts program counters, innermost first, to frames with the outermost call
+// first as Rollbar expects. Rollbar requires at least one frame, so errors without a
+// stack get an empty one.
+func frames(pcs []uintptr) []frame {
+	if len(pcs) == 0 {
+		return []frame{{Filename: "unknown"}}
+	}
+	iter := runtime.CallersFrames(pcs)
+
+	var out []frame
+	for {
+		f, more := iter.Next()
+		out = append([]frame{{Filename: f.File, Lineno: f.Line, Method: f.Function}}, out...)
+		if !more {
+			return out
+		}
+	}
+}
+
+// send posts an item
+func (c *Client) send(ctx context.Context, it *item) {
+	payload, err := json.Marshal(it)
+	if err != nil {
+		c.logger.Error("failed to encode rollbar item", "error", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		c.logger.Error("failed to send rollbar item", "error", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Rollbar-Access-Token", c.token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		c.logger.Warn("failed to send rollbar item", "error", err)
+		return
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		c.logger.Warn("rollbar rejected item", "status", resp.StatusCode)
+	}
+}
+
+// Flush waits until the queued items are sent or ctx ends
+func (c *Client) Flush(ctx context.Context) error {
+	return c.queue.Flush(ctx)
+}
+
+// PreRun prepares the client
+func (*Client) PreRun(_ context.Context) error {
+	return nil
+}
+
+// Run sends the queued items until ctx is canceled
+func (c *Client) Run(ctx context.Context) error {
+	return c.queue.Run(ctx)
+}
+
+// Shutdown stops reporting and sends the remaining items, bounded by ctx
+func (c *Client) Shutdown(ctx context.Context) error {
+	if err := c.queue.Shutdown(ctx); err != nil {
+		return fmt.Errorf("rollbar: %w", err)
+	}
+	return nil
+}
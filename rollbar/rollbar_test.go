@@ -0,0 +1,120 @@
+package rollbar
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/legrch/netgex/errreport"
+)
+
+// fakeRollbar records the items posted to it
+type fakeRollbar struct {
+	mu     sync.Mutex
+	tokens []string
+	items  []item
+}
+
+func startFakeRollbar(t *testing.T) (*fakeRollbar, string) {
+	t.Helper()
+
+	f := &fakeRollbar{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var it item
+		if err := json.NewDecoder(r.Body).Decode(&it); err != nil {
+			http.Error(w, "bad item", http.StatusBadRequest)
+			return
+		}
+
+		f.mu.Lock()
+		f.tokens = append(f.tokens, r.Header.Get("X-Rollbar-Access-Token"))
+		f.items = append(f.items, it)
+		f.mu.Unlock()
+	}))
+	t.Cleanup(srv.Close)
+	return f, srv.URL
+}
+
+func (f *fakeRollbar) received() []item {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]item(nil), f.items...)
+}
+
+// panicReport recovers a panic the way recovery interceptors do
+func panicReport(tags map[string]string) (r errreport.Report) {
+	defer func() {
+		r = errreport.Panic(recover(), tags)
+	}()
+	panic("nil map")
+}
+
+func TestNew_RequiresToken(t *testing.T) {
+	// Act
+	_, err := New("")
+
+	// Assert
+	assert.ErrorContains(t, err, "access token is required")
+}
+
+func TestClient_Report(t *testing.T) {
+	// Arrange
+	fake, endpoint := startFakeRollbar(t)
+	c, err := New("secret", WithEndpoint(endpoint), WithEnvironment("staging"), WithCodeVersion("1.4.2"), WithServerHost("orders-0"))
+	require.NoError(t, err)
+	tags := map[string]string{"method": "/orders.v1.Orders/Get"}
+
+	// Act
+	c.Report(context.Background(), errreport.Report{Err: status.Error(codes.Internal, "database corrupted"), Tags: tags})
+	c.Report(context.Background(), panicReport(tags))
+	require.NoError(t, c.Shutdown(context.Background()))
+
+	// Assert
+	items := fake.received()
+	require.Len(t, items, 2)
+	assert.Equal(t, []string{"secret", "secret"}, fake.tokens)
+
+	errored := items[0].Data
+	assert.Equal(t, "error", errored.Level)
+	assert.Equal(t, "staging", errored.Environment)
+	assert.Equal(t, "1.4.2", errored.CodeVersion)
+	assert.Equal(t, "orders-0", errored.Server.Host)
+	assert.Equal(t, "/orders.v1.Orders/Get", errored.Context)
+	assert.Equal(t, exception{Class: "Internal", Message: "database corrupted"}, errored.Body.Trace.Exception)
+	assert.Len(t, errored.Body.Trace.Frames, 1, "errors without a stack get a placeholder frame")
+
+	panicked := items[1].Data
+	assert.Equal(t, "critical", panicked.Level)
+	assert.Equal(t, exception{Class: "string", Message: "nil map"}, panicked.Body.Trace.Exception)
+	frames := panicked.Body.Trace.Frames
+	assert.Contains(t, frames[len(frames)-1].Method, "panicReport", "the panicking frame is last")
+}
+
+func TestClient_RunAndFlush(t *testing.T) {
+	// Arrange
+	fake, endpoint := startFakeRollbar(t)
+	c, err := New("secret", WithEndpoint(endpoint))
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = c.Run(ctx) }()
+
+	// Act
+	c.Report(ctx, errreport.Report{Err: errors.New("payment provider returned garbage")})
+	flushErr := c.Flush(context.Background())
+
+	// Assert
+	require.NoError(t, flushErr)
+	items := fake.received()
+	require.Len(t, items, 1)
+	assert.Equal(t, "*errors.errorString", items[0].Data.Body.Trace.Exception.Class)
+}
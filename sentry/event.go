@@ -0,0 +1,120 @@
+package sentry
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+// event is the subset of the Sentry event payload the client sends
+type event struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   time.Time         `json:"timestamp"`
+	Level       string            `json:"level"`
+	Platform    string            `json:"platform"`
+	Release     string            `json:"release,omitempty"`
+	Environment string            `json:"environment,omitempty"`
+	ServerName  string            `json:"server_name,omitempty"`
+	Transaction string            `json:"transaction,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Exception   exceptions        `json:"exception"`
+	Contexts    map[string]any    `json:"contexts,omitempty"`
+	SDK         sdk               `json:"sdk"`
+}
+
+type exceptions struct {
+	Values []exception `json:"values"`
+}
+
+type exception struct {
+	Type       string      `json:"type"`
+	Value      string      `json:"value"`
+	Mechanism  *mechanism  `json:"mechanism,omitempty"`
+	Stacktrace *stacktrace `json:"stacktrace,omitempty"`
+}
+
+// mechanism tells Sentry how the error was captured; panics are unhandled
+type mechanism struct {
+	Type    string `json:"type"`
+	Handled bool   `json:"handled"`
+}
+
+type stacktrace struct {
+	Frames []frame `json:"frames"`
+}
+
+type frame struct {
+	Function string `json:"function"`
+	Module   string `json:"module,omitempty"`
+	Filename string `json:"filename"`
+	AbsPath  string `json:"abs_path"`
+	Lineno   int    `json:"lineno"`
+	InApp    bool   `json:"in_app"`
+}
+
+type sdk struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// newEventID returns a random 128-bit hex event ID
+func newEventID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// stackFrames converts program counters, innermost first, to frames with the outermost
+// call first as Sentry expects
+func stackFrames(pcs []uintptr) *stacktrace {
+	if len(pcs) == 0 {
+		return nil
+	}
+	iter := runtime.CallersFrames(pcs)
+
+	var frames []frame
+	for {
+		f, more := iter.Next()
+		module, function := splitFunction(f.Function)
+		frames = append(frames, frame{
+			Function: function,
+			Module:   module,
+			Filename: filepath.Base(f.File),
+			AbsPath:  f.File,
+			Lineno:   f.Line,
+			InApp:    inApp(module),
+		})
+		if !more {
+			break
+		}
+	}
+
+	slices.Reverse(frames)
+	return &stacktrace{Frames: frames}
+}
+
+// splitFunction splits a qualified function name such as
+// github.com/acme/orders.(*Service).Get into its package and function
+func splitFunction(name string) (module, function string) {
+	slash := strings.LastIndex(name, "/")
+	dot := strings.Index(name[slash+1:], ".")
+	if dot < 0 {
+		return "", name
+	}
+	return name[:slash+1+dot], name[slash+2+dot:]
+}
+
+// inApp tells whether a frame belongs to the application rather than the runtime, gRPC
+// or this module
+func inApp(module string) bool {
+	for _, prefix := range []string{"runtime", "google.golang.org/", "github.com/grpc-ecosystem/", "github.com/legrch/netgex/", "net/http"} {
+		if strings.HasPrefix(module, prefix) {
+			return false
+		}
+	}
+	return module != ""
+}
@@ -0,0 +1,260 @@
+// Package sentry reports errors and panics to Sentry. The Client is an
+// errreport.ErrorReporter: it queues reports, sends them in the background while it runs
+// as a server process and flushes the pending ones on shutdown. Events carry the
+// release, environment, tags and trace of the failure.
+package sentry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/status"
+
+	"github.com/legrch/netgex/errreport"
+	"github.com/legrch/netgex/internal/eventqueue"
+)
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithRelease tags events with the release, e.g. the service version
+func WithRelease(release string) Option {
+	return func(c *Client) {
+		c.release = release
+	}
+}
+
+// WithEnvironment tags events with the environment, e.g. production
+func WithEnvironment(environment string) Option {
+	return func(c *Client) {
+		c.environment = environment
+	}
+}
+
+// WithServerName sets the server name of events, the hostname by default
+func WithServerName(name string) Option {
+	return func(c *Client) {
+		c.serverName = name
+	}
+}
+
+// WithTags adds tags to every event
+func WithTags(tags map[string]string) Option {
+	return func(c *Client) {
+		for k, v := range tags {
+			c.tags[k] = v
+		}
+	}
+}
+
+// WithQueueSize sets how many events wait to be sent before new ones are dropped, 100 by
+// default
+func WithQueueSize(size int) Option {
+	return func(c *Client) {
+		c.queueSize = size
+	}
+}
+
+// WithTimeout bounds sending an event, 5s by default
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithHTTPClient sets the HTTP client sending events
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = client
+	}
+}
+
+// WithLogger sets the logger of the client, slog.Default() by default
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// Client sends events to the Sentry project of a DSN
+type Client struct {
+	dsn         string
+	endpoint    string
+	auth        string
+	release     string
+	environment string
+	serverName  string
+	tags        map[string]string
+	queueSize   int
+	timeout     time.Duration
+	httpClient  *http.Client
+	logger      *slog.Logger
+
+	queue *eventqueue.Queue[*event]
+}
+
+// New creates a client sending events to the project of dsn, such as
+// https://public@o1.ingest.sentry.io/42
+func New(dsn string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sentry DSN: %w", err)
+	}
+	key := u.User.Username()
+	slash := strings.LastIndex(u.Path, "/")
+	if key == "" || u.Host == "" || slash < 0 || u.Path[slash+1:] == "" {
+		return nil, errors.New("invalid sentry DSN: expected scheme://key@host/project")
+	}
+	project := u.Path[slash+1:]
+
+	hostname, _ := os.Hostname()
+	c := &Client{
+		dsn:        dsn,
+		endpoint:   fmt.Sprintf("%s://%s%s/api/%s/envelope/", u.Scheme, u.Host, u.Path[:slash], project),
+		auth:       fmt.Sprintf("Sentry sentry_version=7, sentry_client=%s/%s, sentry_key=%s", sdkName, sdkVersion, key),
+		serverName: hostname,
+		tags:       map[string]string{},
+		queueSize:  100,
+		timeout:    5 * time.Second,
+		httpClient: http.DefaultClient,
+		logger:     slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.queue = eventqueue.New(c.queueSize, c.send)
+	return c, nil
+}
+
+const (
+	sdkName    = "netgex.sentry"
+	sdkVersion = "1.0.0"
+)
+
+// CaptureError reports err, tagged with the trace of ctx
+func (c *Client) CaptureError(ctx context.Context, err error) {
+	c.Report(ctx, errreport.Report{Err: err})
+}
+
+// Report queues the event of a report, dropping it when the queue is full
+func (c *Client) Report(ctx context.Context, r errreport.Report) {
+	e := &event{
+		EventID:     newEventID(),
+		Timestamp:   time.Now().UTC(),
+		Level:       "error",
+		Platform:    "go",
+		Release:     c.release,
+		Environment: c.environment,
+		ServerName:  c.serverName,
+		Transaction: r.Tags["method"],
+		Tags:        map[string]string{},
+		Exception:   exceptions{Values: []exception{newException(r)}},
+		SDK:         sdk{Name: sdkName, Version: sdkVersion},
+	}
+	if r.Panic != nil {
+		e.Level = "fatal"
+	}
+	for k, v := range c.tags {
+		e.Tags[k] = v
+	}
+	for k, v := range r.Tags {
+		e.Tags[k] = v
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		e.Contexts = map[string]any{
+			"trace": map[string]string{"trace_id": sc.TraceID().String(), "span_id": sc.SpanID().String()},
+		}
+	}
+
+	if !c.queue.Enqueue(e) {
+		c.logger.Warn("sentry event dropped", "event_id", e.EventID)
+	}
+}
+
+// newException describes the failure of a report: the panic value with its stack, the
+// code and message of gRPC errors, or the type and message of other errors
+func newException(r errreport.Report) exception {
+	if r.Panic != nil {
+		return exception{
+			Type:       fmt.Sprintf("%T", r.Panic),
+			Value:      fmt.Sprint(r.Panic),
+			Mechanism:  &mechanism{Type: "panic", Handled: false},
+			Stacktrace: stackFrames(r.Stack),
+		}
+	}
+	if st, ok := status.FromError(r.Err); ok {
+		return exception{Type: st.Code().String(), Value: st.Message()}
+	}
+	return exception{Type: fmt.Sprintf("%T", r.Err), Value: r.Err.Error()}
+}
+
+// send posts an event as an envelope
+func (c *Client) send(ctx context.Context, e *event) {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		c.logger.Error("failed to encode sentry event", "error", err)
+		return
+	}
+	header, _ := json.Marshal(map[string]any{"event_id": e.EventID, "sent_at": time.Now().UTC(), "dsn": c.dsn})
+	item, _ := json.Marshal(map[string]any{"type": "event", "length": len(payload)})
+	var body bytes.Buffer
+	body.Write(header)
+	body.WriteByte('\n')
+	body.Write(item)
+	body.WriteByte('\n')
+	body.Write(payload)
+	body.WriteByte('\n')
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, &body)
+	if err != nil {
+		c.logger.Error("failed to send sentry event", "error", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", c.auth)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		c.logger.Warn("failed to send sentry event", "event_id", e.EventID, "error", err)
+		return
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		c.logger.Warn("sentry rejected event", "event_id", e.EventID, "status", resp.StatusCode)
+	}
+}
+
+// Flush waits until the queued events are sent or ctx ends
+func (c *Client) Flush(ctx context.Context) error {
+	return c.queue.Flush(ctx)
+}
+
+// PreRun prepares the client
+func (*Client) PreRun(_ context.Context) error {
+	return nil
+}
+
+// Run sends the queued events until ctx is canceled
+func (c *Client) Run(ctx context.Context) error {
+	return c.queue.Run(ctx)
+}
+
+// Shutdown stops reporting and sends the remaining events, bounded by ctx
+func (c *Client) Shutdown(ctx context.Context) error {
+	if err := c.queue.Shutdown(ctx); err != nil {
+		return fmt.Errorf("sentry: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package sentry
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/legrch/netgex/errreport"
+)
+
+// fakeSentry records the events posted to the envelope endpoint of project 42
+type fakeSentry struct {
+	mu     sync.Mutex
+	auth   []string
+	events []event
+}
+
+func startFakeSentry(t *testing.T) (*fakeSentry, string) {
+	t.Helper()
+
+	f := &fakeSentry{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/42/envelope/" {
+			http.NotFound(w, r)
+			return
+		}
+		// The envelope header and the item header precede the event
+		scanner := bufio.NewScanner(r.Body)
+		scanner.Buffer(nil, 1<<20)
+		var lines []string
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		var e event
+		if len(lines) != 3 || json.Unmarshal([]byte(lines[2]), &e) != nil {
+			http.Error(w, "bad envelope", http.StatusBadRequest)
+			return
+		}
+
+		f.mu.Lock()
+		f.auth = append(f.auth, r.Header.Get("X-Sentry-Auth"))
+		f.events = append(f.events, e)
+		f.mu.Unlock()
+	}))
+	t.Cleanup(srv.Close)
+	return f, strings.Replace(srv.URL, "http://", "http://public@", 1) + "/42"
+}
+
+func (f *fakeSentry) received() []event {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]event(nil), f.events...)
+}
+
+func TestNew_InvalidDSN(t *testing.T) {
+	tests := []struct {
+		name string
+		dsn  string
+	}{
+		{name: "no key", dsn: "https://o1.ingest.sentry.io/42"},
+		{name: "no project", dsn: "https://public@o1.ingest.sentry.io/"},
+		{name: "not a URL", dsn: "://"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, err := New(tt.dsn)
+
+			// Assert
+			assert.ErrorContains(t, err, "invalid sentry DSN")
+		})
+	}
+}
+
+// panicReport recovers a panic the way recovery interceptors do
+func panicReport(tags map[string]string) (r errreport.Report) {
+	defer func() {
+		r = errreport.Panic(recover(), tags)
+	}()
+	panic("nil map")
+}
+
+func TestClient_Report(t *testing.T) {
+	// Arrange
+	fake, dsn := startFakeSentry(t)
+	c, err := New(dsn, WithRelease("1.4.2"), WithEnvironment("production"), WithServerName("orders-0"))
+	require.NoError(t, err)
+	tags := map[string]string{"method": "/orders.v1.Orders/Get", "code": "Internal"}
+
+	// Act
+	c.Report(context.Background(), errreport.Report{Err: status.Error(codes.Internal, "database corrupted"), Tags: tags})
+	c.Report(context.Background(), panicReport(tags))
+	require.NoError(t, c.Shutdown(context.Background()))
+
+	// Assert
+	events := fake.received()
+	require.Len(t, events, 2)
+
+	assert.Equal(t, "error", events[0].Level)
+	assert.Equal(t, "1.4.2", events[0].Release)
+	assert.Equal(t, "production", events[0].Environment)
+	assert.Equal(t, "orders-0", events[0].ServerName)
+	assert.Equal(t, "/orders.v1.Orders/Get", events[0].Transaction)
+	assert.Equal(t, "Internal", events[0].Tags["code"])
+	assert.Equal(t, exception{Type: "Internal", Value: "database corrupted"}, events[0].Exception.Values[0])
+
+	assert.Equal(t, "fatal", events[1].Level)
+	panicked := events[1].Exception.Values[0]
+	assert.Equal(t, "nil map", panicked.Value)
+	assert.Equal(t, &mechanism{Type: "panic", Handled: false}, panicked.Mechanism)
+	require.NotNil(t, panicked.Stacktrace)
+	frames := panicked.Stacktrace.Frames
+	assert.Contains(t, frames[len(frames)-1].Function, "panicReport", "the panicking frame is last")
+	assert.Contains(t, fake.auth[0], "sentry_key=public")
+}
+
+func TestClient_RunAndFlush(t *testing.T) {
+	// Arrange
+	fake, dsn := startFakeSentry(t)
+	c, err := New(dsn, WithTags(map[string]string{"service": "orders"}))
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = c.Run(ctx) }()
+
+	// Act
+	c.CaptureError(ctx, errors.New("payment provider returned garbage"))
+	flushErr := c.Flush(context.Background())
+
+	// Assert
+	require.NoError(t, flushErr)
+	events := fake.received()
+	require.Len(t, events, 1)
+	assert.Equal(t, "orders", events[0].Tags["service"])
+	assert.Equal(t, "*errors.errorString", events[0].Exception.Values[0].Type)
+}
+
+func TestClient_DropsAfterShutdown(t *testing.T) {
+	// Arrange
+	fake, dsn := startFakeSentry(t)
+	c, err := New(dsn)
+	require.NoError(t, err)
+	require.NoError(t, c.Shutdown(context.Background()))
+
+	// Act
+	c.CaptureError(context.Background(), errors.New("late"))
+
+	// Assert
+	require.NoError(t, c.Flush(context.Background()))
+	assert.Empty(t, fake.received())
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/legrch/netgex/accesslog"
+)
+
+// applyAccessLog creates the logger of the default middleware's access logs from the
+// ACCESS_LOG_* settings, sampling its routes. The application logger is used with the
+// "slog" sink; the otlp sink exports records while the server runs.
+func (s *Server) applyAccessLog() error {
+	cfg := s.cfg.AccessLog
+
+	var handler slog.Handler
+	switch cfg.Sink {
+	case "slog", "":
+		handler = s.logger.Handler()
+	case "stdout", "file":
+		out := os.Stdout
+		if cfg.Sink == "file" {
+			if cfg.FilePath == "" {
+				return errors.New("access log error: ACCESS_LOG_FILE_PATH is required by the file sink")
+			}
+			f, err := os.OpenFile(cfg.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+			if err != nil {
+				return fmt.Errorf("access log error: %w", err)
+			}
+			out = f
+		}
+		h, err := accesslog.NewHandler(out, cfg.Format)
+		if err != nil {
+			return fmt.Errorf("access log error: %w", err)
+		}
+		handler = h
+	case "otlp":
+		exporter := accesslog.NewExporter(cfg.Endpoint,
+			accesslog.WithLogger(s.logger),
+			accesslog.WithResource(map[string]string{
+				"service.name":           s.cfg.ServiceName,
+				"service.version":        s.cfg.ServiceVersion,
+				"deployment.environment": s.cfg.Environment,
+			}),
+		)
+		s.addProcesses(exporter)
+		handler = exporter.Handler()
+	default:
+		return fmt.Errorf("access log error: invalid sink %q: must be \"slog\", \"stdout\", \"file\" or \"otlp\"", cfg.Sink)
+	}
+
+	if len(cfg.SampleRates) > 0 {
+		rates, err := accesslog.ParseSampleRates(cfg.SampleRates)
+		if err != nil {
+			return fmt.Errorf("access log error: %w", err)
+		}
+		handler = accesslog.NewSampler(handler, rates)
+	}
+
+	s.accessLogger = slog.New(handler)
+	return nil
+}
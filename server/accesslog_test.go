@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/legrch/netgex/config"
+)
+
+func TestServer_AccessLogFile(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "access.log")
+	ctx, cancel := context.WithCancel(context.Background())
+	s := NewServer(
+		WithLogger(slog.New(slog.DiscardHandler)),
+		WithSplashDisabled(),
+		WithInMemoryTransport(),
+		WithDefaultMiddleware(),
+		WithAccessLogFile(path),
+		WithAccessLogFormat("combined"),
+		WithHTTPAccessLog(true),
+		WithServices(healthProxyRegistrar{}),
+	)
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+	defer func() {
+		cancel()
+		assert.NoError(t, <-done)
+	}()
+
+	select {
+	case <-s.Ready():
+	case err := <-done:
+		t.Fatalf("server stopped: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server not ready")
+	}
+	client := &http.Client{Transport: &http.Transport{DialContext: s.DialHTTPInMemory}}
+
+	// Act - the gateway request makes a gRPC call, both logged, and the health check is not
+	resp, err := client.Get("http://bufconn/status")
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	resp, err = client.Get("http://bufconn/health")
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	// Assert
+	logs, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(logs)), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"POST /grpc.health.v1.Health/Check HTTP/2.0" 200`)
+	assert.Contains(t, lines[1], `"GET /status HTTP/1.1" 200 7`)
+}
+
+func TestServer_ApplyAccessLog_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.AccessLogConfig
+		wantErr string
+	}{
+		{name: "sink", cfg: config.AccessLogConfig{Sink: "syslog"}, wantErr: `invalid sink "syslog"`},
+		{name: "format", cfg: config.AccessLogConfig{Sink: "stdout", Format: "xml"}, wantErr: `invalid access log format "xml"`},
+		{name: "file path", cfg: config.AccessLogConfig{Sink: "file", Format: "json"}, wantErr: "ACCESS_LOG_FILE_PATH is required"},
+		{name: "sample rate", cfg: config.AccessLogConfig{Sink: "slog", SampleRates: []string{"/svc/Method"}}, wantErr: "expected pattern=rate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			s := NewServer(WithLogger(slog.New(slog.DiscardHandler)))
+			s.cfg.AccessLog = tt.cfg
+
+			// Act
+			err := s.applyAccessLog()
+
+			// Assert
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
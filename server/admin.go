@@ -0,0 +1,63 @@
+package server
+
+import (
+	"errors"
+
+	"github.com/legrch/netgex/internal/admin"
+	"github.com/legrch/netgex/internal/metrics"
+	"github.com/legrch/netgex/internal/pprof"
+
+	grpcserver "github.com/legrch/netgex/internal/grpc"
+)
+
+// BuildInfo describes the build of the service, see WithBuildInfo
+type BuildInfo = admin.BuildInfo
+
+// newAdminServer creates the internal admin gRPC server. Health and reflection are
+// always served there, so they can be disabled on the public server.
+func (s *Server) newAdminServer(grpcServer *grpcserver.Server) *grpcserver.Server {
+	return s.newGRPCServer(
+		s.logger.With("listener", "admin"),
+		s.cfg.AdminAddress,
+		grpcserver.WithServices(admin.NewService(s.cfg, grpcServer, s.version)),
+		grpcserver.WithReflection(true),
+		grpcserver.WithHealthCheck(true),
+	)
+}
+
+// applyAdminHTTP disables the separate metrics and pprof listeners, which are served on
+// the admin HTTP address instead
+func (s *Server) applyAdminHTTP() error {
+	if s.cfg.SinglePortEnabled || s.cfg.LambdaEnabled {
+		return errors.New("admin HTTP address cannot be combined with single-port or lambda mode")
+	}
+
+	s.cfg.MetricsAddress = ""
+	s.cfg.PprofAddress = ""
+
+	return nil
+}
+
+// newAdminHTTPServer creates the admin HTTP server serving metrics, pprof, health, the
+// version, the drain status, the services registered on the gRPC server and the gateway
+// routes
+func (s *Server) newAdminHTTPServer(grpcServer *grpcserver.Server, pprofServer *pprof.Server) *admin.HTTPServer {
+	opts := []admin.HTTPOption{
+		admin.WithHTTPListenOptions(s.listenOptions()),
+		admin.WithHTTPHealthCheck(s.HealthCheck),
+		admin.WithHTTPHandler("/version", s.version.Handler()),
+		admin.WithHTTPHandler("/services", admin.ServicesHandler(grpcServer)),
+		admin.WithHTTPHandler("/drain", admin.DrainHandler(s.drainStatus, s.Drain)),
+	}
+	if s.cfg.MetricsServerEnabled {
+		opts = append(opts, admin.WithHTTPHandler(s.cfg.Telemetry.Metrics.Path, metrics.Handler(s.metricsLabels)))
+	}
+	if pprofServer != nil {
+		opts = append(opts, admin.WithHTTPHandler("/debug/", pprofServer.Handler()))
+	}
+	if s.gateway != nil {
+		opts = append(opts, admin.WithHTTPHandler("/routes", s.gateway.RoutesHandler()))
+	}
+
+	return admin.NewHTTPServer(s.logger, s.cfg.AdminHTTPAddress, s.cfg.CloseTimeout, opts...)
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/legrch/netgex/internal/admin"
+	"github.com/legrch/netgex/internal/pprof"
+)
+
+func TestServer_NewAdminServer(t *testing.T) {
+	// Arrange - reflection and health are disabled on the public server
+	s := NewServer(
+		WithLogger(slog.Default()),
+		WithReflection(false),
+		WithHealthCheck(false),
+		WithAdminAddress("127.0.0.1:0"),
+	)
+	grpcServer := s.newGRPCServer(s.logger, s.cfg.GRPCAddress)
+	require.NoError(t, grpcServer.PreRun(context.Background()))
+
+	// Act
+	adminServer := s.newAdminServer(grpcServer)
+	err := adminServer.PreRun(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	services := adminServer.GetServiceInfo()
+	for _, name := range []string{
+		admin.ServiceName,
+		"grpc.channelz.v1.Channelz",
+		"grpc.health.v1.Health",
+		"grpc.reflection.v1.ServerReflection",
+	} {
+		assert.Contains(t, services, name)
+	}
+	assert.Empty(t, grpcServer.GetServiceInfo(), "public server should not serve operational services")
+}
+
+func TestServer_ApplyAdminHTTP(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr bool
+	}{
+		{name: "separate listeners", opts: []Option{WithAdminHTTPAddress(":9092")}},
+		{name: "single-port mode", opts: []Option{WithAdminHTTPAddress(":9092"), WithSinglePort("8080")}, wantErr: true},
+		{name: "lambda mode", opts: []Option{WithAdminHTTPAddress(":9092"), WithLambda(true)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			s := NewServer(tt.opts...)
+
+			// Act
+			err := s.applyAdminHTTP()
+
+			// Assert
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Empty(t, s.cfg.MetricsAddress, "metrics should not get its own listener")
+			assert.Empty(t, s.cfg.PprofAddress, "pprof should not get its own listener")
+		})
+	}
+}
+
+func TestServer_NewAdminHTTPServer(t *testing.T) {
+	// Arrange
+	s := NewServer(WithLogger(slog.Default()), WithAdminHTTPAddress("127.0.0.1:0"))
+	s.version = admin.NewVersion(s.cfg.ServiceName, s.cfg.ServiceVersion, s.buildInfo)
+	pprofServer := pprof.NewServer(s.logger, "")
+	grpcServer := s.newGRPCServer(s.logger, s.cfg.GRPCAddress)
+	require.NoError(t, s.addGateways(grpcServer, pprofServer))
+
+	// Act
+	handler := s.newAdminHTTPServer(grpcServer, pprofServer).Handler()
+
+	// Assert
+	for _, path := range []string{"/metrics", "/health", "/debug/pprof/", "/version", "/services", "/routes", "/drain"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusOK, rec.Code, path)
+	}
+}
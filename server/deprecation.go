@@ -0,0 +1,57 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/legrch/netgex/deprecation"
+)
+
+// applyDeprecation creates the deprecation policy from the options and DEPRECATED_METHODS
+// and signals deprecated methods through the gRPC interceptors
+func (s *Server) applyDeprecation() error {
+	if !s.deprecationEnabled && s.cfg.DeprecatedMethods == "" {
+		return nil
+	}
+
+	opts, err := parseDeprecatedMethods(s.cfg.DeprecatedMethods)
+	if err != nil {
+		return err
+	}
+	if s.cfg.Telemetry.Metrics.Enabled && s.cfg.Telemetry.Metrics.Backend == "prometheus" {
+		opts = append(opts, deprecation.WithMetrics(s.cfg.Telemetry.Metrics.Namespace))
+	}
+	// Options given in code take precedence over the environment
+	s.deprecation = deprecation.New(append(opts, s.deprecationOptions...)...)
+
+	s.addGRPCUnaryInterceptors(s.deprecation.UnaryServerInterceptor())
+	s.addGRPCStreamInterceptors(s.deprecation.StreamServerInterceptor())
+	return nil
+}
+
+// parseDeprecatedMethods parses the DEPRECATED_METHODS list
+func parseDeprecatedMethods(value string) ([]deprecation.Option, error) {
+	var opts []deprecation.Option
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		method, sunset, hasSunset := strings.Cut(entry, "@")
+		var d deprecation.Deprecation
+		if hasSunset {
+			date, err := time.Parse(time.DateOnly, sunset)
+			if err != nil {
+				return nil, fmt.Errorf("invalid sunset date in DEPRECATED_METHODS entry %q: %w", entry, err)
+			}
+			d.Sunset = date
+		}
+		if !strings.HasPrefix(method, "/") || strings.Count(method, "/") != 2 {
+			return nil, fmt.Errorf("invalid DEPRECATED_METHODS entry %q: expected /package.Service/Method", entry)
+		}
+		opts = append(opts, deprecation.WithMethod(method, d))
+	}
+	return opts, nil
+}
@@ -0,0 +1,36 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDeprecatedMethods(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{name: "empty", value: "", want: 0},
+		{name: "methods", value: "/orders.v1.Orders/Get, /orders.v1.Orders/List@2026-06-30", want: 2},
+		{name: "invalid sunset", value: "/orders.v1.Orders/Get@next-year", wantErr: true},
+		{name: "invalid method", value: "orders.v1.Orders.Get", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			opts, err := parseDeprecatedMethods(tt.value)
+
+			// Assert
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, opts, tt.want)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"time"
+
+	"github.com/legrch/netgex/internal/admin"
+	"github.com/legrch/netgex/internal/inflight"
+	"github.com/legrch/netgex/lifecycle"
+)
+
+// Drain makes the server report NOT_SERVING so load balancers stop sending traffic,
+// without shutting it down. Deploy tooling can start draining with POST /drain on the
+// admin HTTP address and stop the instance once GET /drain reports it idle. Shutdown
+// drains the server if it is not draining yet.
+func (s *Server) Drain() {
+	s.drainOnce.Do(func() {
+		now := time.Now().UTC()
+		s.drainingSince.Store(&now)
+		s.events.Publish(lifecycle.Draining{})
+		for _, p := range s.processes {
+			if d, ok := p.(Drainer); ok {
+				d.Drain()
+			}
+		}
+	})
+}
+
+// drainStatus reports whether the server is draining and the requests still in flight
+func (s *Server) drainStatus() admin.DrainStatus {
+	var counts inflight.Counts
+	var conns inflight.Connections
+	if s.inflight != nil {
+		counts = s.inflight.InFlight()
+		conns = s.inflight.Connections()
+	}
+	since := s.drainingSince.Load()
+	return admin.DrainStatus{
+		Draining:      since != nil,
+		DrainingSince: since,
+		InFlight:      counts,
+		Connections:   conns,
+		Idle:          since != nil && counts.Idle(),
+	}
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/legrch/netgex/errreport"
+	"github.com/legrch/netgex/middleware"
+	"github.com/legrch/netgex/rollbar"
+	"github.com/legrch/netgex/sentry"
+	"github.com/legrch/netgex/webhook"
+)
+
+// applyErrorReporting creates the Sentry and Rollbar clients and hands failures of calls
+// to the error reporters. Reporters that are processes are added before the servers, so
+// they flush after the servers have shut down.
+func (s *Server) applyErrorReporting() error {
+	if err := s.applySentry(); err != nil {
+		return err
+	}
+	if err := s.applyRollbar(); err != nil {
+		return err
+	}
+	if len(s.errorReporters) == 0 {
+		return nil
+	}
+
+	for _, reporter := range s.errorReporters {
+		if p, ok := reporter.(Process); ok {
+			s.addProcesses(p)
+		}
+	}
+	reporter := errreport.Multi(s.errorReporters...)
+	s.addGRPCUnaryInterceptors(middleware.ErrorReportingUnaryInterceptor(reporter, s.reportedCodes...))
+	s.addGRPCStreamInterceptors(middleware.ErrorReportingStreamInterceptor(reporter, s.reportedCodes...))
+	return nil
+}
+
+// applySentry creates the Sentry client, tagging events with the service version and
+// environment
+func (s *Server) applySentry() error {
+	if s.sentryDSN == "" {
+		return nil
+	}
+
+	opts := []sentry.Option{
+		sentry.WithRelease(s.cfg.ServiceVersion),
+		sentry.WithEnvironment(s.cfg.Environment),
+		sentry.WithTags(map[string]string{"service": s.cfg.ServiceName}),
+		sentry.WithLogger(s.logger),
+	}
+	client, err := sentry.New(s.sentryDSN, append(opts, s.sentryOptions...)...)
+	if err != nil {
+		return fmt.Errorf("sentry: %w", err)
+	}
+	s.errorReporters = append(s.errorReporters, client)
+	return nil
+}
+
+// applyRollbar creates the Rollbar client, tagging items with the service version and
+// environment
+func (s *Server) applyRollbar() error {
+	if s.rollbarToken == "" {
+		return nil
+	}
+
+	opts := []rollbar.Option{
+		rollbar.WithCodeVersion(s.cfg.ServiceVersion),
+		rollbar.WithEnvironment(s.cfg.Environment),
+		rollbar.WithLogger(s.logger),
+	}
+	client, err := rollbar.New(s.rollbarToken, append(opts, s.rollbarOptions...)...)
+	if err != nil {
+		return fmt.Errorf("rollbar: %w", err)
+	}
+	s.errorReporters = append(s.errorReporters, client)
+	return nil
+}
+
+// superviseProcess runs a process, returning its failure after sending it to the error
+// reporters. A panicking process fails like one returning an error instead of crashing
+// the service before the other processes shut down.
+func (s *Server) superviseProcess(ctx context.Context, index int, process Process) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			report := errreport.Panic(p, map[string]string{"process": processName(process)})
+			errreport.Multi(append([]errreport.ErrorReporter{errreport.NewLogReporter(s.logger)}, s.errorReporters...)...).Report(ctx, report)
+			s.notify(webhook.ProcessCrashed, processName(process), report.Err)
+			err = fmt.Errorf("process %d panicked: %v", index, p)
+		}
+	}()
+
+	s.logger.Info("starting process", "index", index)
+	if err := process.Run(ctx); err != nil {
+		s.reportProcessError(ctx, process, err)
+		s.notify(webhook.ProcessCrashed, processName(process), err)
+		return fmt.Errorf("process %d error: %w", index, err)
+	}
+	return nil
+}
+
+// reportProcessError sends the failure of a process to the error reporters
+func (s *Server) reportProcessError(ctx context.Context, process Process, err error) {
+	report := errreport.Report{Err: err, Tags: map[string]string{"process": processName(process)}}
+	for _, reporter := range s.errorReporters {
+		reporter.Report(ctx, report)
+	}
+}
@@ -0,0 +1,390 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/rs/cors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/legrch/netgex/fieldmask"
+	"github.com/legrch/netgex/graphql"
+	"github.com/legrch/netgex/httpcache"
+	"github.com/legrch/netgex/internal/gateway"
+	"github.com/legrch/netgex/internal/pprof"
+	"github.com/legrch/netgex/mesh"
+	"github.com/legrch/netgex/middleware"
+	"github.com/legrch/netgex/service"
+	"github.com/legrch/netgex/twirp"
+
+	grpcserver "github.com/legrch/netgex/internal/grpc"
+)
+
+// GatewayMiddleware wraps the HTTP handler of a gateway server
+type GatewayMiddleware = gateway.Middleware
+
+// GatewayRoutingErrorFunc returns the error of an HTTP request no gateway route matches
+type GatewayRoutingErrorFunc = gateway.RoutingErrorFunc
+
+// GatewayRoute is an HTTP route served by the gateway, with its backing gRPC method
+type GatewayRoute = gateway.Route
+
+// GatewayMetadataAnnotator returns the gRPC metadata added to the calls of an HTTP request
+// translated by the gateway, such as its client IP or the result of authenticating it
+type GatewayMetadataAnnotator = func(context.Context, *http.Request) metadata.MD
+
+// GatewayResponseModifier modifies the HTTP response of a gateway call from its response
+// message before the body is written, e.g. setting cookies and headers. Returning an error
+// answers the request with it instead.
+type GatewayResponseModifier = func(context.Context, http.ResponseWriter, proto.Message) error
+
+// GatewayStatusCode returns a GatewayResponseModifier answering the calls of the given
+// gRPC methods (e.g. "/orders.v1.Orders/CreateOrder") with code instead of 200, such as
+// 201 for creates. Modifiers writing the status code must come last, as headers set after
+// it are not sent.
+func GatewayStatusCode(code int, methods ...string) GatewayResponseModifier {
+	return func(ctx context.Context, w http.ResponseWriter, _ proto.Message) error {
+		if method, ok := runtime.RPCMethod(ctx); ok && slices.Contains(methods, method) {
+			w.WriteHeader(code)
+		}
+		return nil
+	}
+}
+
+// GatewayServerOption is a function that configures an additional gateway server
+type GatewayServerOption func(*gatewayServer)
+
+// gatewayServer describes an additional gateway with its own services, served on its
+// own address or on the main HTTP port for its hosts
+type gatewayServer struct {
+	name        string
+	address     string
+	hosts       []string
+	services    []service.Registrar
+	muxOptions  []runtime.ServeMuxOption
+	corsOptions *cors.Options
+	middleware  []GatewayMiddleware
+}
+
+// WithGatewayServerServices exposes services through the gateway server
+func WithGatewayServerServices(services ...service.Registrar) GatewayServerOption {
+	return func(g *gatewayServer) {
+		g.services = append(g.services, services...)
+	}
+}
+
+// WithGatewayServerHosts serves the gateway on the main HTTP port for requests to the
+// given hosts; only used when the gateway server has no address of its own
+func WithGatewayServerHosts(hosts ...string) GatewayServerOption {
+	return func(g *gatewayServer) {
+		g.hosts = append(g.hosts, hosts...)
+	}
+}
+
+// WithGatewayServerMuxOptions sets the ServeMux options for the gateway server
+func WithGatewayServerMuxOptions(options ...runtime.ServeMuxOption) GatewayServerOption {
+	return func(g *gatewayServer) {
+		g.muxOptions = append(g.muxOptions, options...)
+	}
+}
+
+// WithGatewayServerCORS enables CORS with the specified options for the gateway server
+func WithGatewayServerCORS(options cors.Options) GatewayServerOption {
+	return func(g *gatewayServer) {
+		g.corsOptions = &options
+	}
+}
+
+// WithGatewayServerMiddleware wraps the gateway server's handler; the first middleware
+// is the outermost
+func WithGatewayServerMiddleware(middleware ...GatewayMiddleware) GatewayServerOption {
+	return func(g *gatewayServer) {
+		g.middleware = append(g.middleware, middleware...)
+	}
+}
+
+// grpcServices returns the services of the main gRPC server: those from WithServices and
+// those of the gateway servers, which call it, each registered once
+func (s *Server) grpcServices() []service.Registrar {
+	services := slices.Clone(s.services)
+	for _, g := range s.gatewayServers {
+		for _, svc := range g.services {
+			if !slices.Contains(services, svc) {
+				services = append(services, svc)
+			}
+		}
+	}
+	return services
+}
+
+// sharedGatewayOptions returns the options every gateway server gets from the config
+func (s *Server) sharedGatewayOptions() []gateway.Option {
+	jsonConfig := gateway.DefaultJSONConfig()
+	jsonConfig.DiscardUnknown = s.cfg.JSONDiscardUnknown
+
+	opts := []gateway.Option{
+		gateway.WithListenOptions(s.listenOptions()),
+		gateway.WithJSONConfig(jsonConfig),
+		gateway.WithHealthPaths(s.cfg.HTTPHealthPaths...),
+		gateway.WithUnescapingMode(s.cfg.GatewayUnescapingMode),
+		gateway.WithPathLengthFallback(s.cfg.GatewayPathLengthFallback),
+		gateway.WithStreamFraming(s.cfg.GatewayStreamFraming),
+		gateway.WithStreamFlushInterval(s.cfg.GatewayStreamFlushInterval),
+		gateway.WithOutgoingHeaders(s.cfg.GatewayOutgoingHeaders, s.cfg.GatewayOutgoingHeaderKeys...),
+		gateway.WithTimeoutHeaders(s.cfg.GatewayTimeoutHeaders...),
+		gateway.WithNotFoundHandler(s.gwNotFound),
+		gateway.WithMethodNotAllowedHandler(s.gwMethodNotAllowed),
+	}
+
+	// Serve the build metadata next to the health endpoint
+	if s.cfg.HTTPVersionPath != "" {
+		opts = append(opts, gateway.WithHandler(s.cfg.HTTPVersionPath, s.version.Handler()))
+	}
+
+	// Leave operational endpoints, such as health checks and scrapes, out of access logs
+	// and metrics
+	observed := middleware.ExcludePaths(s.cfg.ObservabilityExcludePaths...)
+
+	// Count in-flight requests outside the other middleware, and open connections
+	if s.inflight != nil {
+		opts = append(opts,
+			gateway.WithMiddleware(middleware.FilterHTTP(observed, s.inflight.Middleware)),
+			gateway.WithConnState(s.inflight.ConnState),
+		)
+	}
+
+	// Log requests outside recovery, so recovered panics are logged as 500 responses
+	if s.accessLogger != nil && s.cfg.AccessLog.HTTPEnabled {
+		opts = append(opts, gateway.WithMiddleware(middleware.FilterHTTP(observed, middleware.AccessLogHTTPMiddleware(s.accessLogger))))
+	}
+
+	// Turn panics of HTTP handlers and marshalers into 500 responses, like the recovery
+	// interceptor does for gRPC handlers
+	if s.defaultMiddleware || len(s.errorReporters) > 0 {
+		opts = append(opts, gateway.WithMiddleware(middleware.RecoveryHTTPMiddleware(s.logger, s.errorReporters...)))
+	}
+
+	// The gateway receives what the server sends and vice versa, so the limits mirror each other
+	if s.cfg.GRPCMaxSendMsgSize > 0 {
+		opts = append(opts, gateway.WithDialOptions(grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(s.cfg.GRPCMaxSendMsgSize))))
+	}
+	if s.cfg.GRPCMaxRecvMsgSize > 0 {
+		opts = append(opts, gateway.WithDialOptions(grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(s.cfg.GRPCMaxRecvMsgSize))))
+	}
+
+	// Add the metadata annotated from HTTP requests to their calls
+	for _, annotator := range s.gwMetadataAnnotators {
+		opts = append(opts, gateway.WithMuxOptions(runtime.WithMetadata(annotator)))
+	}
+
+	// Forward mesh and tenant headers from HTTP requests to the gRPC server
+	var matcher gateway.HeaderMatcherFunc
+	if s.cfg.MeshHeadersEnabled {
+		matcher = mesh.HeaderMatcher
+	}
+	if s.tenancy != nil {
+		matcher = s.tenancy.HeaderMatcher(matcher)
+	}
+	if matcher != nil {
+		opts = append(opts, gateway.WithIncomingHeaderMatcher(matcher))
+	}
+
+	// Turn the deprecation metadata of responses into HTTP headers
+	if s.deprecation != nil {
+		opts = append(opts,
+			gateway.WithMuxOptions(runtime.WithForwardResponseOption(s.deprecation.ForwardResponseOption)),
+			gateway.WithMiddleware(s.deprecation.Middleware),
+		)
+	}
+
+	// Accept field masks with JSON field names in query parameters
+	if s.fieldMasksEnabled {
+		opts = append(opts, gateway.WithMuxOptions(runtime.SetQueryParameterParser(fieldmask.QueryParameterParser{})))
+	}
+
+	// Prune GET responses to the fields the client asks for
+	if s.cfg.GatewayPartialResponseEnabled {
+		partial := fieldmask.NewPartialResponse(s.cfg.GatewayPartialResponseParam)
+		opts = append(opts,
+			gateway.WithMuxOptions(runtime.WithForwardResponseOption(partial.ForwardResponseOption)),
+			gateway.WithMiddleware(partial.Middleware),
+		)
+	}
+
+	// Modify responses last, as modifiers may write the status code
+	for _, modifier := range s.gwResponseModifiers {
+		opts = append(opts, gateway.WithMuxOptions(runtime.WithForwardResponseOption(modifier)))
+	}
+
+	// Dial the address the gRPC server is bound to, so it can listen on port 0
+	switch {
+	case s.grpcServer != nil && s.grpcMemory != nil:
+		opts = append(opts,
+			gateway.WithBackend(memoryBackend{s.grpcServer}),
+			gateway.WithDialOptions(grpc.WithContextDialer(s.DialGRPCInMemory)),
+		)
+	case s.grpcServer != nil:
+		opts = append(opts, gateway.WithBackend(s.grpcServer))
+	}
+
+	return opts
+}
+
+// addGateways adds the main gateway and the additional gateway servers, mounting those
+// without an address as virtual hosts of the main gateway
+func (s *Server) addGateways(grpcServer *grpcserver.Server, pprofServer *pprof.Server) error {
+	gatewayOpts := append(s.sharedGatewayOptions(),
+		gateway.WithServices(s.services...),
+		gateway.WithMuxOptions(s.gwServerMuxOptions...),
+		gateway.WithCORS(&s.gwCORSOptions),
+	)
+
+	// Serve the GraphQL schema of the services
+	if s.cfg.GraphQLEnabled {
+		gatewayOpts = append(gatewayOpts, gateway.WithServices(
+			graphql.New(
+				graphql.WithServices(s.services...),
+				graphql.WithPath(s.cfg.GraphQLPath),
+				graphql.WithMaxDepth(s.cfg.GraphQLMaxDepth),
+				graphql.WithMaxComplexity(s.cfg.GraphQLMaxComplexity),
+			),
+		))
+	}
+
+	// Serve the services over Twirp, and JSON-RPC when it has a path
+	if s.cfg.TwirpEnabled {
+		gatewayOpts = append(gatewayOpts, gateway.WithServices(twirp.New(
+			twirp.WithServices(s.services...),
+			twirp.WithPrefix(s.cfg.TwirpPrefix),
+			twirp.WithJSONRPC(s.cfg.TwirpJSONRPCPath),
+		)))
+	}
+
+	// Answer conditional requests, outside the cache so hits are revalidated too
+	if s.gwETagEnabled {
+		gatewayOpts = append(gatewayOpts, gateway.WithMiddleware(httpcache.ETag(s.gwETagOptions...)))
+	}
+
+	// Cache responses of the configured routes
+	if s.gwCacheStore != nil {
+		cacheOpts := s.gwCacheOptions
+		if s.cfg.Telemetry.Metrics.Enabled && s.cfg.Telemetry.Metrics.Backend == "prometheus" {
+			cacheOpts = append([]httpcache.Option{httpcache.WithMetrics(s.cfg.Telemetry.Metrics.Namespace)}, cacheOpts...)
+		}
+		gatewayOpts = append(gatewayOpts, gateway.WithMiddleware(httpcache.Middleware(s.gwCacheStore, cacheOpts...)))
+	}
+
+	// Set Cache-Control inside the cache, so it skips no-store routes and stores the header
+	if len(s.gwCacheControlOptions) > 0 {
+		gatewayOpts = append(gatewayOpts, gateway.WithMiddleware(httpcache.CacheControl(s.gwCacheControlOptions...)))
+	}
+
+	// Create additional gateway servers
+	for _, g := range s.gatewayServers {
+		gw, err := s.newGatewayServer(g)
+		if err != nil {
+			return err
+		}
+		if g.address == "" {
+			gatewayOpts = append(gatewayOpts, gateway.WithVirtualHost(gw, g.hosts...))
+		} else {
+			s.addProcesses(gw)
+		}
+	}
+
+	// Add swagger if configured
+	if s.swaggerEnabled() {
+		gatewayOpts = append(gatewayOpts,
+			gateway.WithSwagger(s.cfg.SwaggerDir, s.cfg.SwaggerBasePath),
+			gateway.WithSwaggerCDN(s.cfg.SwaggerUICDN),
+			gateway.WithSwaggerAuthToken(s.cfg.SwaggerAuthToken),
+			gateway.WithSwaggerBasicAuth(s.cfg.SwaggerBasicAuthUser, s.cfg.SwaggerBasicAuthPassword),
+		)
+		if s.swaggerFS != nil {
+			gatewayOpts = append(gatewayOpts, gateway.WithSwaggerFS(s.swaggerFS, s.cfg.SwaggerBasePath))
+		}
+	} else if s.cfg.SwaggerEnabled {
+		s.logger.Info("swagger UI disabled in production, set SWAGGER_PRODUCTION_ENABLED to serve it")
+	}
+
+	// Serve gRPC, metrics and pprof on the HTTP port in single-port mode
+	if s.cfg.SinglePortEnabled {
+		gatewayOpts = append(gatewayOpts, s.singlePortGatewayOptions(grpcServer, pprofServer)...)
+	}
+
+	// Serve the gateway on its in-memory listener
+	if s.httpMemory != nil {
+		gatewayOpts = append(gatewayOpts, gateway.WithListener(s.httpMemory))
+	}
+
+	// Serve Lambda events with the composed gateway handler
+	if s.cfg.LambdaEnabled {
+		gatewayOpts = append(gatewayOpts, gateway.WithServeFunc(s.serveLambda))
+	}
+
+	gatewayServer := gateway.NewServer(
+		s.logger,
+		s.cfg.CloseTimeout,
+		s.cfg.GRPCAddress,
+		s.cfg.HTTPAddress,
+		gatewayOpts...,
+	)
+	s.addProcesses(gatewayServer)
+	s.gateway = gatewayServer
+
+	return nil
+}
+
+// Routes returns the HTTP routes of the main gateway's services, read from their
+// google.api.http annotations. It is empty until the server has been started with Run.
+func (s *Server) Routes() []GatewayRoute {
+	if s.gateway == nil {
+		return nil
+	}
+	return s.gateway.Routes()
+}
+
+// newGatewayServer creates the gateway for an additional gateway server, dialing the
+// main gRPC server
+func (s *Server) newGatewayServer(g *gatewayServer) (*gateway.Server, error) {
+	if g.address == "" && len(g.hosts) == 0 {
+		return nil, fmt.Errorf("gateway server %q needs an address or hosts", g.name)
+	}
+
+	opts := append(s.sharedGatewayOptions(),
+		gateway.WithServices(g.services...),
+		gateway.WithMuxOptions(g.muxOptions...),
+		gateway.WithMiddleware(g.middleware...),
+	)
+	if g.corsOptions != nil {
+		opts = append(opts, gateway.WithCORS(g.corsOptions))
+	}
+
+	return gateway.NewServer(
+		s.logger.With("gateway", g.name),
+		s.cfg.CloseTimeout,
+		s.cfg.GRPCAddress,
+		g.address,
+		opts...,
+	), nil
+}
+
+// gatewayFeature describes an additional gateway server on the splash screen
+func gatewayFeature(g *gatewayServer) string {
+	if g.address == "" {
+		return fmt.Sprintf("HTTP %s: %s", g.name, strings.Join(g.hosts, ", "))
+	}
+	return fmt.Sprintf("HTTP %s: %s", g.name, g.address)
+}
+
+// swaggerEnabled reports whether Swagger is served; in the "production" environment
+// this additionally requires SwaggerProductionEnabled
+func (s *Server) swaggerEnabled() bool {
+	return s.cfg.SwaggerEnabled && (s.cfg.Environment != "production" || s.cfg.SwaggerProductionEnabled)
+}
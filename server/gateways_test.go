@@ -0,0 +1,545 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/legrch/netgex/config"
+	"github.com/legrch/netgex/httprule"
+	"github.com/legrch/netgex/internal/admin"
+	mocksvc "github.com/legrch/netgex/internal/mocks/service"
+)
+
+func TestServer_NewGatewayServer(t *testing.T) {
+	tests := []struct {
+		name        string
+		gateway     *gatewayServer
+		wantFeature string
+		wantErr     bool
+	}{
+		{
+			name:        "own address",
+			gateway:     &gatewayServer{name: "partner", address: ":8081"},
+			wantFeature: "HTTP partner: :8081",
+		},
+		{
+			name:        "virtual hosts",
+			gateway:     &gatewayServer{name: "partner", hosts: []string{"partner.example.com", "partners.example.com"}},
+			wantFeature: "HTTP partner: partner.example.com, partners.example.com",
+		},
+		{
+			name:    "neither address nor hosts",
+			gateway: &gatewayServer{name: "partner"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			s := NewServer(WithLogger(slog.Default()))
+
+			// Act
+			gw, err := s.newGatewayServer(tt.gateway)
+
+			// Assert
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotNil(t, gw)
+			assert.Equal(t, tt.wantFeature, gatewayFeature(tt.gateway))
+		})
+	}
+}
+
+func TestServer_SwaggerEnabled(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *config.Config
+		want bool
+	}{
+		{name: "disabled", cfg: &config.Config{Environment: "development"}, want: false},
+		{name: "development", cfg: &config.Config{SwaggerEnabled: true, Environment: "development"}, want: true},
+		{name: "production", cfg: &config.Config{SwaggerEnabled: true, Environment: "production"}, want: false},
+		{
+			name: "production enabled",
+			cfg:  &config.Config{SwaggerEnabled: true, Environment: "production", SwaggerProductionEnabled: true},
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			s := NewServer(WithConfig(tt.cfg))
+
+			// Act & Assert
+			assert.Equal(t, tt.want, s.swaggerEnabled())
+		})
+	}
+}
+
+func TestServer_GRPCServices(t *testing.T) {
+	// Arrange
+	public := mocksvc.NewRegistrar(t)
+	partner := mocksvc.NewRegistrar(t)
+	s := NewServer(
+		WithServices(public, partner),
+		WithGatewayServer("partner", ":8081", WithGatewayServerServices(partner)),
+		WithGatewayServer("internal", ":8082", WithGatewayServerServices(mocksvc.NewRegistrar(t))),
+	)
+
+	// Act
+	services := s.grpcServices()
+
+	// Assert
+	assert.Len(t, services, 3, "gateway server services should be registered once on the gRPC server")
+	assert.Len(t, s.services, 2, "the main gateway should only expose WithServices")
+}
+
+func TestServer_Routes(t *testing.T) {
+	// Arrange
+	s := NewServer(WithLogger(slog.Default()))
+	assert.Nil(t, s.Routes(), "no routes before Run")
+	grpcServer := s.newGRPCServer(s.logger, s.cfg.GRPCAddress)
+
+	// Act
+	err := s.addGateways(grpcServer, nil)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Empty(t, s.Routes(), "services without HTTP annotations have no routes")
+}
+
+func TestServer_HTTPRules(t *testing.T) {
+	// Arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	s := NewServer(
+		WithLogger(slog.New(slog.DiscardHandler)),
+		WithSplashDisabled(),
+		WithInMemoryTransport(),
+		WithServices(httprule.New(httprule.Get("grpc.health.v1.Health.Check", "/v1/health/{service}"))),
+	)
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+	defer func() {
+		cancel()
+		assert.NoError(t, <-done)
+	}()
+
+	select {
+	case <-s.Ready():
+	case err := <-done:
+		t.Fatalf("server stopped: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server not ready")
+	}
+	client := &http.Client{Transport: &http.Transport{DialContext: s.DialHTTPInMemory}}
+
+	// Act
+	resp, err := client.Get("http://bufconn/v1/health/")
+
+	// Assert
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	assert.JSONEq(t, `{"status": 1}`, string(body), "the empty service is SERVING")
+	assert.Contains(t, s.Routes(), GatewayRoute{Method: "GET", Pattern: "/v1/health/{service}", RPC: "/grpc.health.v1.Health/Check"})
+}
+
+func TestServer_VersionEndpoint(t *testing.T) {
+	// Arrange
+	cfg := config.NewConfig()
+	cfg.ServiceName = "orders"
+	cfg.ServiceVersion = "1.2.3"
+	ctx, cancel := context.WithCancel(context.Background())
+	s := NewServer(
+		WithConfig(cfg),
+		WithLogger(slog.New(slog.DiscardHandler)),
+		WithSplashDisabled(),
+		WithInMemoryTransport(),
+		WithBuildInfo(BuildInfo{Commit: "abc123", BuildDate: "2024-03-05T14:07:09Z"}),
+	)
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+	defer func() {
+		cancel()
+		assert.NoError(t, <-done)
+	}()
+
+	select {
+	case <-s.Ready():
+	case err := <-done:
+		t.Fatalf("server stopped: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server not ready")
+	}
+	client := &http.Client{Transport: &http.Transport{DialContext: s.DialHTTPInMemory}}
+
+	// Act
+	resp, err := client.Get("http://bufconn/version")
+
+	// Assert
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var info admin.VersionInfo
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&info))
+	assert.Equal(t, "orders", info.Name)
+	assert.Equal(t, "1.2.3", info.Version, "the version falls back to SERVICE_VERSION")
+	assert.Equal(t, "abc123", info.Commit)
+	assert.Equal(t, "2024-03-05T14:07:09Z", info.BuildDate)
+}
+
+// fileRegistrar serves a file descriptor on GET /v1/file, forwarding it through the mux
+// like generated gateway handlers do
+type fileRegistrar struct{}
+
+func (fileRegistrar) RegisterGRPC(*grpc.Server) {}
+
+func (fileRegistrar) RegisterHTTP(_ context.Context, mux *runtime.ServeMux, _ string, _ []grpc.DialOption) error {
+	return mux.HandlePath(http.MethodGet, "/v1/file", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		_, outbound := runtime.MarshalerForRequest(mux, r)
+		ctx := runtime.NewServerMetadataContext(r.Context(), runtime.ServerMetadata{})
+		runtime.ForwardResponseMessage(ctx, mux, outbound, w, r, &descriptorpb.FileDescriptorProto{
+			Name:    proto.String("orders.proto"),
+			Package: proto.String("orders.v1"),
+			Options: &descriptorpb.FileOptions{GoPackage: proto.String("example.com/orders")},
+		}, mux.GetForwardResponseOptions()...)
+	})
+}
+
+func TestServer_GatewayPartialResponse(t *testing.T) {
+	// Arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	s := NewServer(
+		WithLogger(slog.New(slog.DiscardHandler)),
+		WithSplashDisabled(),
+		WithInMemoryTransport(),
+		WithGatewayPartialResponse(true),
+		WithServices(fileRegistrar{}),
+	)
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+	defer func() {
+		cancel()
+		assert.NoError(t, <-done)
+	}()
+
+	select {
+	case <-s.Ready():
+	case err := <-done:
+		t.Fatalf("server stopped: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server not ready")
+	}
+	client := &http.Client{Transport: &http.Transport{DialContext: s.DialHTTPInMemory}}
+
+	// Act
+	resp, err := client.Get("http://bufconn/v1/file?fields=name,options.goPackage")
+
+	// Assert
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(body, &got))
+	assert.Equal(t, "orders.proto", got["name"])
+	assert.Empty(t, got["package"], "fields outside the mask are cleared")
+	assert.Equal(t, "example.com/orders", got["options"].(map[string]any)["go_package"])
+}
+
+// metadataRegistrar writes the outgoing metadata the gateway annotates requests to
+// /echo.v1.Echo/Echo with, on GET /v1/metadata
+type metadataRegistrar struct{}
+
+func (metadataRegistrar) RegisterGRPC(*grpc.Server) {}
+
+func (metadataRegistrar) RegisterHTTP(_ context.Context, mux *runtime.ServeMux, _ string, _ []grpc.DialOption) error {
+	return mux.HandlePath(http.MethodGet, "/v1/metadata", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		ctx, err := runtime.AnnotateContext(r.Context(), mux, r, "/echo.v1.Echo/Echo")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		md, _ := metadata.FromOutgoingContext(ctx)
+		_ = json.NewEncoder(w).Encode(md)
+	})
+}
+
+func TestServer_GatewayMetadataAnnotators(t *testing.T) {
+	// Arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	s := NewServer(
+		WithLogger(slog.New(slog.DiscardHandler)),
+		WithSplashDisabled(),
+		WithInMemoryTransport(),
+		WithGatewayMetadataAnnotators(
+			func(_ context.Context, r *http.Request) metadata.MD {
+				return metadata.Pairs("x-user-agent", r.UserAgent())
+			},
+			func(_ context.Context, r *http.Request) metadata.MD {
+				return metadata.Pairs("x-authenticated", strconv.FormatBool(r.Header.Get("Authorization") != ""))
+			},
+		),
+		WithServices(metadataRegistrar{}),
+	)
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+	defer func() {
+		cancel()
+		assert.NoError(t, <-done)
+	}()
+
+	select {
+	case <-s.Ready():
+	case err := <-done:
+		t.Fatalf("server stopped: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server not ready")
+	}
+	client := &http.Client{Transport: &http.Transport{DialContext: s.DialHTTPInMemory}}
+	req, err := http.NewRequest(http.MethodGet, "http://bufconn/v1/metadata", nil)
+	require.NoError(t, err)
+	req.Header.Set("User-Agent", "orders-cli/1.0")
+
+	// Act
+	resp, err := client.Do(req)
+
+	// Assert
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var md map[string][]string
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&md))
+	assert.Equal(t, []string{"orders-cli/1.0"}, md["x-user-agent"])
+	assert.Equal(t, []string{"false"}, md["x-authenticated"])
+}
+
+func TestServer_OpenConnections(t *testing.T) {
+	// Arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	s := NewServer(
+		WithLogger(slog.New(slog.DiscardHandler)),
+		WithSplashDisabled(),
+		WithInMemoryTransport(),
+		WithServices(metadataRegistrar{}),
+	)
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+	defer func() {
+		cancel()
+		assert.NoError(t, <-done)
+	}()
+
+	select {
+	case <-s.Ready():
+	case err := <-done:
+		t.Fatalf("server stopped: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server not ready")
+	}
+	transport := &http.Transport{DialContext: s.DialHTTPInMemory}
+	client := &http.Client{Transport: transport}
+
+	// Act
+	resp, err := client.Get("http://bufconn/v1/metadata")
+	require.NoError(t, err)
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	open := s.drainStatus().Connections
+	transport.CloseIdleConnections()
+
+	// Assert
+	assert.Equal(t, int64(1), open.HTTP, "the keep-alive connection stays open")
+	require.Eventually(t, func() bool { return s.drainStatus().Connections.HTTP == 0 }, time.Second, time.Millisecond)
+}
+
+// ordersRegistrar answers POST /v1/orders and GET /v1/orders/{id} with the order ID,
+// forwarding it through the mux like generated gateway handlers do
+type ordersRegistrar struct{}
+
+func (ordersRegistrar) RegisterGRPC(*grpc.Server) {}
+
+func (ordersRegistrar) RegisterHTTP(_ context.Context, mux *runtime.ServeMux, _ string, _ []grpc.DialOption) error {
+	forward := func(method string) runtime.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+			_, outbound := runtime.MarshalerForRequest(mux, r)
+			ctx, err := runtime.AnnotateContext(r.Context(), mux, r, method)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			ctx = runtime.NewServerMetadataContext(ctx, runtime.ServerMetadata{})
+			runtime.ForwardResponseMessage(ctx, mux, outbound, w, r, wrapperspb.String("42"), mux.GetForwardResponseOptions()...)
+		}
+	}
+	if err := mux.HandlePath(http.MethodPost, "/v1/orders", forward("/orders.v1.Orders/CreateOrder")); err != nil {
+		return err
+	}
+	return mux.HandlePath(http.MethodGet, "/v1/orders/{id}", forward("/orders.v1.Orders/GetOrder"))
+}
+
+func TestServer_GatewayResponseModifiers(t *testing.T) {
+	// Arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	s := NewServer(
+		WithLogger(slog.New(slog.DiscardHandler)),
+		WithSplashDisabled(),
+		WithInMemoryTransport(),
+		WithGatewayResponseModifiers(
+			func(_ context.Context, w http.ResponseWriter, resp proto.Message) error {
+				if order, ok := resp.(*wrapperspb.StringValue); ok {
+					http.SetCookie(w, &http.Cookie{Name: "last_order", Value: order.GetValue()})
+				}
+				return nil
+			},
+			GatewayStatusCode(http.StatusCreated, "/orders.v1.Orders/CreateOrder"),
+		),
+		WithServices(ordersRegistrar{}),
+	)
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+	defer func() {
+		cancel()
+		assert.NoError(t, <-done)
+	}()
+
+	select {
+	case <-s.Ready():
+	case err := <-done:
+		t.Fatalf("server stopped: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server not ready")
+	}
+	client := &http.Client{Transport: &http.Transport{DialContext: s.DialHTTPInMemory}}
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		wantStatus int
+	}{
+		{name: "create", method: http.MethodPost, target: "http://bufconn/v1/orders", wantStatus: http.StatusCreated},
+		{name: "get", method: http.MethodGet, target: "http://bufconn/v1/orders/42", wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, tt.target, strings.NewReader("{}"))
+			require.NoError(t, err)
+
+			// Act
+			resp, err := client.Do(req)
+
+			// Assert
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			require.Len(t, resp.Cookies(), 1)
+			assert.Equal(t, "42", resp.Cookies()[0].Value)
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.Equal(t, `"42"`, string(body))
+		})
+	}
+}
+
+func TestServer_GraphQL(t *testing.T) {
+	// Arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	s := NewServer(
+		WithLogger(slog.New(slog.DiscardHandler)),
+		WithSplashDisabled(),
+		WithInMemoryTransport(),
+		WithGraphQL(true),
+		WithGraphQLPath("/api/graphql"),
+	)
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+	defer func() {
+		cancel()
+		assert.NoError(t, <-done)
+	}()
+
+	select {
+	case <-s.Ready():
+	case err := <-done:
+		t.Fatalf("server stopped: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server not ready")
+	}
+	client := &http.Client{Transport: &http.Transport{DialContext: s.DialHTTPInMemory}}
+
+	// Act
+	resp, err := client.Post("http://bufconn/api/graphql", "application/graphql",
+		strings.NewReader(`{ __schema { queryType { name } } }`))
+
+	// Assert
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"data":{"__schema":{"queryType":{"name":"Query"}}}}`, string(body))
+}
+
+func TestServer_Twirp(t *testing.T) {
+	// Arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	s := NewServer(
+		WithLogger(slog.New(slog.DiscardHandler)),
+		WithSplashDisabled(),
+		WithInMemoryTransport(),
+		WithTwirp(true),
+		WithTwirpJSONRPC("/jsonrpc"),
+	)
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+	defer func() {
+		cancel()
+		assert.NoError(t, <-done)
+	}()
+
+	select {
+	case <-s.Ready():
+	case err := <-done:
+		t.Fatalf("server stopped: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server not ready")
+	}
+	client := &http.Client{Transport: &http.Transport{DialContext: s.DialHTTPInMemory}}
+
+	// Act
+	resp, err := client.Post("http://bufconn/jsonrpc", "application/json",
+		strings.NewReader(`{"jsonrpc":"2.0","method":"orders.v1.Orders/GetOrder","id":1}`))
+
+	// Assert
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","error":{"code":-32601,"message":"Method not found"},"id":1}`, string(body))
+}
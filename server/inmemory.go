@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"google.golang.org/grpc/test/bufconn"
+
+	grpcserver "github.com/legrch/netgex/internal/grpc"
+)
+
+// InMemoryTarget is the gRPC target to dial with DialGRPCInMemory as the context dialer
+const InMemoryTarget = "passthrough:///bufconn"
+
+// inMemoryBufferSize is the buffer size of the in-memory listeners
+const inMemoryBufferSize = 1 << 20
+
+// errNotInMemory is returned when dialing in memory without WithInMemoryTransport
+var errNotInMemory = errors.New("server does not use the in-memory transport")
+
+// memoryBackend is the gRPC server as dialed by the gateway over the in-memory transport
+type memoryBackend struct {
+	*grpcserver.Server
+}
+
+// Addr returns the in-memory target instead of the listener address
+func (memoryBackend) Addr() string {
+	return InMemoryTarget
+}
+
+// applyInMemory disables the servers that would listen on TCP, since only the gRPC
+// server and the main gateway have in-memory listeners
+func (s *Server) applyInMemory() error {
+	if s.cfg.SinglePortEnabled || s.cfg.LambdaEnabled {
+		return errors.New("in-memory transport cannot be combined with single-port or lambda mode")
+	}
+	if len(s.grpcListeners) > 0 {
+		return errors.New("in-memory transport cannot be combined with additional gRPC listeners")
+	}
+	for _, g := range s.gatewayServers {
+		if g.address != "" {
+			return fmt.Errorf("in-memory transport cannot be combined with gateway server %q on its own address", g.name)
+		}
+	}
+
+	s.cfg.MetricsServerEnabled = false
+	s.cfg.PprofEnabled = false
+	s.cfg.AdminEnabled = false
+	s.cfg.AdminHTTPAddress = ""
+	s.cfg.Registry.Enabled = false
+
+	return nil
+}
+
+// DialGRPCInMemory connects to the gRPC server over the in-memory transport. Use it with
+// grpc.WithContextDialer and InMemoryTarget.
+func (s *Server) DialGRPCInMemory(ctx context.Context, _ string) (net.Conn, error) {
+	if s.grpcMemory == nil {
+		return nil, errNotInMemory
+	}
+	return s.grpcMemory.DialContext(ctx)
+}
+
+// DialHTTPInMemory connects to the gateway over the in-memory transport. Use it as the
+// DialContext of an http.Transport.
+func (s *Server) DialHTTPInMemory(ctx context.Context, _, _ string) (net.Conn, error) {
+	if s.httpMemory == nil {
+		return nil, errNotInMemory
+	}
+	return s.httpMemory.DialContext(ctx)
+}
+
+// newMemoryListener creates an in-memory listener
+func newMemoryListener() *bufconn.Listener {
+	return bufconn.Listen(inMemoryBufferSize)
+}
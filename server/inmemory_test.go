@@ -0,0 +1,162 @@
+package server
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// healthProxyRegistrar serves the gRPC health status on GET /status through the
+// gateway's connection to the gRPC server
+type healthProxyRegistrar struct{}
+
+func (healthProxyRegistrar) RegisterGRPC(*grpc.Server) {}
+
+func (healthProxyRegistrar) RegisterHTTP(ctx context.Context, mux *runtime.ServeMux, endpoint string, opts []grpc.DialOption) error {
+	conn, err := grpc.NewClient(endpoint, opts...)
+	if err != nil {
+		return err
+	}
+	go func() {
+		<-ctx.Done()
+		_ = conn.Close()
+	}()
+
+	return mux.HandlePath(http.MethodGet, "/status", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		resp, err := grpc_health_v1.NewHealthClient(conn).Check(r.Context(), &grpc_health_v1.HealthCheckRequest{})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(resp.GetStatus().String()))
+	})
+}
+
+func TestServer_InMemoryTransport(t *testing.T) {
+	// Arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	s := NewServer(
+		WithLogger(slog.New(slog.DiscardHandler)),
+		WithSplashDisabled(),
+		WithInMemoryTransport(),
+		WithServices(healthProxyRegistrar{}),
+	)
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+	defer func() {
+		cancel()
+		assert.NoError(t, <-done)
+	}()
+
+	select {
+	case <-s.Ready():
+	case err := <-done:
+		t.Fatalf("server stopped: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server not ready")
+	}
+	client := &http.Client{Transport: &http.Transport{DialContext: s.DialHTTPInMemory}}
+
+	// Act - the gateway calls the gRPC server in memory
+	resp, err := client.Get("http://bufconn/status")
+
+	// Assert
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "SERVING", string(body))
+	assert.False(t, s.cfg.MetricsServerEnabled, "metrics would listen on TCP")
+}
+
+func TestServer_Run_MultipleInstances(t *testing.T) {
+	// Arrange - servers in one process share the Prometheus default registry
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 2)
+	var servers []*Server
+	for range 2 {
+		s := NewServer(
+			WithLogger(slog.New(slog.DiscardHandler)),
+			WithSplashDisabled(),
+			WithInMemoryTransport(),
+			WithDefaultMiddleware(),
+			WithMetricsBackend("prometheus", ""),
+			WithServices(healthProxyRegistrar{}),
+		)
+		servers = append(servers, s)
+	}
+
+	// Act
+	for _, s := range servers {
+		go func() { done <- s.Run(ctx) }()
+	}
+
+	// Assert
+	for _, s := range servers {
+		select {
+		case <-s.Ready():
+		case err := <-done:
+			t.Fatalf("server stopped: %v", err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("server not ready")
+		}
+	}
+	cancel()
+	assert.NoError(t, <-done)
+	assert.NoError(t, <-done)
+}
+
+func TestServer_ApplyInMemory(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr bool
+	}{
+		{name: "gRPC and gateway only", opts: []Option{WithAdmin(true)}},
+		{name: "virtual host gateway", opts: []Option{WithGatewayServer("public", "", WithGatewayServerHosts("api.example.com"))}},
+		{name: "single-port mode", opts: []Option{WithSinglePort("8080")}, wantErr: true},
+		{name: "additional listener", opts: []Option{WithGRPCListener("internal", ":9095")}, wantErr: true},
+		{name: "gateway server on its own address", opts: []Option{WithGatewayServer("public", ":8081")}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			s := NewServer(append([]Option{WithInMemoryTransport()}, tt.opts...)...)
+
+			// Act
+			err := s.applyInMemory()
+
+			// Assert
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.False(t, s.cfg.AdminEnabled)
+			assert.False(t, s.cfg.PprofEnabled)
+		})
+	}
+}
+
+func TestServer_DialInMemory_Disabled(t *testing.T) {
+	// Arrange
+	s := NewServer()
+
+	// Act
+	_, grpcErr := s.DialGRPCInMemory(context.Background(), "")
+	_, httpErr := s.DialHTTPInMemory(context.Background(), "tcp", "")
+
+	// Assert
+	assert.ErrorIs(t, grpcErr, errNotInMemory)
+	assert.ErrorIs(t, httpErr, errNotInMemory)
+}
@@ -0,0 +1,39 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/legrch/netgex/lambda"
+)
+
+// applyLambda disables the HTTP, metrics and pprof listeners, since requests arrive as
+// Lambda events. The gRPC server keeps listening on loopback for the gateway to dial.
+func (s *Server) applyLambda() error {
+	if s.cfg.SinglePortEnabled {
+		return errors.New("lambda mode cannot be combined with single-port mode")
+	}
+	if lambda.RuntimeAPI() == "" {
+		return errors.New("AWS_LAMBDA_RUNTIME_API is not set; not running in AWS Lambda")
+	}
+
+	_, grpcPort, err := net.SplitHostPort(s.cfg.GRPCAddress)
+	if err != nil {
+		return fmt.Errorf("invalid gRPC address: %w", err)
+	}
+
+	s.cfg.GRPCAddress = net.JoinHostPort("127.0.0.1", grpcPort)
+	s.cfg.HTTPAddress = ""
+	s.cfg.MetricsAddress = ""
+	s.cfg.PprofAddress = ""
+
+	return nil
+}
+
+// serveLambda serves Lambda invocations with the gateway handler
+func (s *Server) serveLambda(ctx context.Context, handler http.Handler) error {
+	return lambda.Serve(ctx, s.logger, lambda.RuntimeAPI(), lambda.NewHandler(handler))
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"crypto/tls"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	grpcserver "github.com/legrch/netgex/internal/grpc"
+	"github.com/legrch/netgex/service"
+)
+
+// ListenerOption is a function that configures an additional gRPC listener
+type ListenerOption func(*grpcListener)
+
+// grpcListener describes an additional gRPC server with its own services
+type grpcListener struct {
+	name               string
+	address            string
+	services           []service.Registrar
+	unaryInterceptors  []grpc.UnaryServerInterceptor
+	streamInterceptors []grpc.StreamServerInterceptor
+	serverOptions      []grpc.ServerOption
+	tlsConfig          *tls.Config
+}
+
+// WithListenerServices registers services on the listener
+func WithListenerServices(services ...service.Registrar) ListenerOption {
+	return func(l *grpcListener) {
+		l.services = append(l.services, services...)
+	}
+}
+
+// WithListenerUnaryInterceptors adds unary interceptors that run after the server-wide ones
+func WithListenerUnaryInterceptors(interceptors ...grpc.UnaryServerInterceptor) ListenerOption {
+	return func(l *grpcListener) {
+		l.unaryInterceptors = append(l.unaryInterceptors, interceptors...)
+	}
+}
+
+// WithListenerStreamInterceptors adds stream interceptors that run after the server-wide ones
+func WithListenerStreamInterceptors(interceptors ...grpc.StreamServerInterceptor) ListenerOption {
+	return func(l *grpcListener) {
+		l.streamInterceptors = append(l.streamInterceptors, interceptors...)
+	}
+}
+
+// WithListenerServerOptions adds raw gRPC server options to the listener
+func WithListenerServerOptions(options ...grpc.ServerOption) ListenerOption {
+	return func(l *grpcListener) {
+		l.serverOptions = append(l.serverOptions, options...)
+	}
+}
+
+// WithListenerTLS serves the listener over TLS
+func WithListenerTLS(config *tls.Config) ListenerOption {
+	return func(l *grpcListener) {
+		l.tlsConfig = config
+	}
+}
+
+// newListenerServer creates the gRPC server for an additional listener. Server-wide
+// settings and interceptors apply, but services registered with WithServices don't.
+func (s *Server) newListenerServer(l *grpcListener) *grpcserver.Server {
+	unary := append(append([]grpc.UnaryServerInterceptor{}, s.grpcUnaryServerInterceptors...), l.unaryInterceptors...)
+	stream := append(append([]grpc.StreamServerInterceptor{}, s.grpcStreamServerInterceptors...), l.streamInterceptors...)
+
+	options := l.serverOptions
+	if l.tlsConfig != nil {
+		options = append([]grpc.ServerOption{grpc.Creds(credentials.NewTLS(l.tlsConfig))}, options...)
+	}
+
+	opts := []grpcserver.Option{
+		grpcserver.WithServices(l.services...),
+		grpcserver.WithUnaryInterceptors(unary...),
+		grpcserver.WithStreamInterceptors(stream...),
+		grpcserver.WithOptions(options...),
+	}
+	if s.inflight != nil {
+		opts = append(opts, grpcserver.WithStatsHandlers(s.inflight.StatsHandler()))
+	}
+
+	return s.newGRPCServer(s.logger.With("listener", l.name), l.address, opts...)
+}
+
+// listenerFeature describes an additional listener on the splash screen
+func listenerFeature(l *grpcListener) string {
+	feature := fmt.Sprintf("gRPC %s: %s", l.name, l.address)
+	if l.tlsConfig != nil {
+		feature += " (TLS)"
+	}
+	return feature
+}
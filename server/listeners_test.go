@@ -0,0 +1,34 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	mocksvc "github.com/legrch/netgex/internal/mocks/service"
+)
+
+func TestServer_NewListenerServer(t *testing.T) {
+	// Arrange - only the listener's own services are registered on it
+	public := mocksvc.NewRegistrar(t)
+	internal := mocksvc.NewRegistrar(t)
+	internal.EXPECT().RegisterGRPC(mock.Anything).Return().Once()
+
+	s := NewServer(
+		WithLogger(slog.Default()),
+		WithServices(public),
+		WithGRPCListener("admin", "127.0.0.1:0", WithListenerServices(internal)),
+	)
+
+	// Act
+	srv := s.newListenerServer(s.grpcListeners[0])
+	err := srv.PreRun(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "gRPC admin: 127.0.0.1:0", listenerFeature(s.grpcListeners[0]))
+}
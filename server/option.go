@@ -1,15 +1,32 @@
 package server
 
 import (
+	"io"
+	"io/fs"
 	"log/slog"
+	"net"
 	"time"
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/rs/cors"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/stats"
 
 	"github.com/legrch/netgex/config"
+	"github.com/legrch/netgex/deprecation"
+	"github.com/legrch/netgex/errreport"
+	"github.com/legrch/netgex/fieldmask"
+	"github.com/legrch/netgex/httpcache"
+	"github.com/legrch/netgex/lifecycle"
+	"github.com/legrch/netgex/migrate"
+	"github.com/legrch/netgex/redis"
+	"github.com/legrch/netgex/rollbar"
+	"github.com/legrch/netgex/sentry"
 	"github.com/legrch/netgex/service"
+	"github.com/legrch/netgex/tenant"
+	"github.com/legrch/netgex/webhook"
 )
 
 // Option is a function that configures a Server
@@ -22,13 +39,79 @@ func WithLogger(logger *slog.Logger) Option {
 	}
 }
 
-// WithConfig sets the configuration for the Server
+// WithConfig sets the base configuration for the Server, used instead of loading
+// defaults, the config file and the environment. Configuration shortcut options
+// are applied on top of it regardless of their order relative to WithConfig.
 func WithConfig(config *config.Config) Option {
 	return func(s *Server) {
+		s.baseCfg = config
 		s.cfg = config
 	}
 }
 
+// WithConfigFile sets a YAML or JSON file layered between the defaults and the environment
+func WithConfigFile(path string) Option {
+	return func(s *Server) {
+		s.cfgFile = path
+	}
+}
+
+// WithEnvPrefix namespaces the environment variables the Server reads its configuration
+// from, so GRPC_ADDRESS becomes <PREFIX>_GRPC_ADDRESS. Unprefixed variables are ignored.
+// It has no effect when WithConfig is used.
+func WithEnvPrefix(prefix string) Option {
+	return func(s *Server) {
+		s.envPrefix = prefix
+	}
+}
+
+// WithSecretResolver registers a resolver for config values using the given scheme,
+// e.g. WithSecretResolver("vault", config.NewVaultResolver(addr, token))
+func WithSecretResolver(scheme string, resolver config.SecretResolver) Option {
+	return func(s *Server) {
+		if s.secretResolvers == nil {
+			s.secretResolvers = map[string]config.SecretResolver{}
+		}
+		s.secretResolvers[scheme] = resolver
+	}
+}
+
+// WithRemoteConfig layers values from a remote source (Consul, etcd) between the config
+// file and the environment. Changes are delivered to handlers set with WithConfigReloadHandler.
+// It has no effect when WithConfig is used.
+func WithRemoteConfig(source config.RemoteSource) Option {
+	return func(s *Server) {
+		s.remoteConfig = source
+	}
+}
+
+// WithConfigReloadHandler adds a handler called with the rebuilt configuration whenever
+// the remote configuration changes
+func WithConfigReloadHandler(handler ConfigReloadHandler) Option {
+	return func(s *Server) {
+		s.reloadHandlers = append(s.reloadHandlers, handler)
+	}
+}
+
+// WithLifecycleHandler subscribes handler to the lifecycle events of the Server, from
+// the ConfigLoaded event at the start of Run to ShutdownComplete
+func WithLifecycleHandler(handler lifecycle.Handler) Option {
+	return func(s *Server) {
+		s.events.Subscribe(handler)
+	}
+}
+
+// configOption wraps a configuration change so it is applied on top of the loaded
+// configuration, giving functional options the highest precedence
+func configOption(fn func(*config.Config)) Option {
+	return func(s *Server) {
+		if s.cfg != nil {
+			fn(s.cfg)
+		}
+		s.cfgOverrides = append(s.cfgOverrides, fn)
+	}
+}
+
 // WithServices sets the service implementations
 func WithServices(services ...service.Registrar) Option {
 	return func(s *Server) {
@@ -50,6 +133,32 @@ func WithGRPCServerOptions(options ...grpc.ServerOption) Option {
 	}
 }
 
+// WithGRPCListener adds a gRPC server on another address hosting its own set of services,
+// e.g. internal or admin APIs kept off the public listener. Server-wide interceptors,
+// keepalive, message size, health and reflection settings apply to it as well.
+func WithGRPCListener(name, address string, opts ...ListenerOption) Option {
+	return func(s *Server) {
+		l := &grpcListener{name: name, address: address}
+		for _, opt := range opts {
+			opt(l)
+		}
+		s.grpcListeners = append(s.grpcListeners, l)
+	}
+}
+
+// WithGatewayServer adds a gateway server with its own services, CORS policy and
+// middleware, e.g. for a partner API. It listens on address, or with an empty address
+// is served on the main HTTP port for the hosts set by WithGatewayServerHosts.
+func WithGatewayServer(name, address string, opts ...GatewayServerOption) Option {
+	return func(s *Server) {
+		g := &gatewayServer{name: name, address: address}
+		for _, opt := range opts {
+			opt(g)
+		}
+		s.gatewayServers = append(s.gatewayServers, g)
+	}
+}
+
 // WithGRPCUnaryInterceptors sets the unary interceptors for the gRPC server
 func WithGRPCUnaryInterceptors(interceptors ...grpc.UnaryServerInterceptor) Option {
 	return func(s *Server) {
@@ -64,6 +173,168 @@ func WithGRPCStreamInterceptors(interceptors ...grpc.StreamServerInterceptor) Op
 	}
 }
 
+// WithScopedUnaryInterceptors adds unary interceptors running only for the calls the
+// selector selects, e.g. authentication for SelectServices("admin.v1.AdminService"). They
+// run after the global interceptors set with WithGRPCUnaryInterceptors.
+func WithScopedUnaryInterceptors(selector Selector, interceptors ...grpc.UnaryServerInterceptor) Option {
+	return func(s *Server) {
+		s.grpcScopedUnaryInterceptors = append(s.grpcScopedUnaryInterceptors, scopedUnaryInterceptor(selector, interceptors))
+	}
+}
+
+// WithScopedStreamInterceptors adds stream interceptors running only for the streams the
+// selector selects, after the global interceptors set with WithGRPCStreamInterceptors
+func WithScopedStreamInterceptors(selector Selector, interceptors ...grpc.StreamServerInterceptor) Option {
+	return func(s *Server) {
+		s.grpcScopedStreamInterceptors = append(s.grpcScopedStreamInterceptors, scopedStreamInterceptor(selector, interceptors))
+	}
+}
+
+// WithGRPCStatsHandlers adds stats handlers, e.g. otelgrpc.NewServerHandler(), to the gRPC
+// server and the additional listeners. Unlike interceptors they also observe connections.
+func WithGRPCStatsHandlers(handlers ...stats.Handler) Option {
+	return func(s *Server) {
+		s.grpcStatsHandlers = append(s.grpcStatsHandlers, handlers...)
+	}
+}
+
+// WithGatewayCache caches GET responses of the main gateway in store for the routes
+// configured with httpcache.WithRoute. Hits and misses are counted when Prometheus
+// metrics are enabled.
+func WithGatewayCache(store httpcache.Store, opts ...httpcache.Option) Option {
+	return func(s *Server) {
+		s.gwCacheStore = store
+		s.gwCacheOptions = opts
+	}
+}
+
+// WithGatewayETags adds ETags to GET responses of the main gateway and answers
+// If-None-Match and If-Modified-Since requests with 304 Not Modified
+func WithGatewayETags(opts ...httpcache.ETagOption) Option {
+	return func(s *Server) {
+		s.gwETagEnabled = true
+		s.gwETagOptions = opts
+	}
+}
+
+// WithGatewayCacheControl sets the Cache-Control header of main gateway responses from
+// policies per route prefix, see httpcache.WithControlRoute
+func WithGatewayCacheControl(opts ...httpcache.ControlOption) Option {
+	return func(s *Server) {
+		s.gwCacheControlOptions = append(s.gwCacheControlOptions, opts...)
+	}
+}
+
+// WithDeprecations marks gRPC methods and HTTP routes as deprecated, in addition to
+// DEPRECATED_METHODS and methods with the deprecated proto option. Calls to them get
+// Deprecation, Sunset and Link headers and are counted when Prometheus metrics are enabled.
+func WithDeprecations(opts ...deprecation.Option) Option {
+	return func(s *Server) {
+		s.deprecationEnabled = true
+		s.deprecationOptions = append(s.deprecationOptions, opts...)
+	}
+}
+
+// WithTenancy resolves the tenant of every call, by default from the X-Tenant-ID header,
+// and makes it available through tenant.FromContext. The gateway forwards the tenant
+// headers, host and Authorization header to the gRPC server, where the tenant is added
+// to the span, counted when Prometheus metrics are enabled and optionally rate limited.
+func WithTenancy(opts ...tenant.Option) Option {
+	return func(s *Server) {
+		s.tenancyEnabled = true
+		s.tenancyOptions = append(s.tenancyOptions, opts...)
+	}
+}
+
+// WithFieldMasks validates the read_mask and update_mask fields of unary requests and
+// prunes responses to their read mask. The gateway accepts masks in query parameters with
+// JSON field names, e.g. ?update_mask=title,author.displayName.
+func WithFieldMasks(opts ...fieldmask.Option) Option {
+	return func(s *Server) {
+		s.fieldMasksEnabled = true
+		s.fieldMaskOptions = append(s.fieldMaskOptions, opts...)
+	}
+}
+
+// WithRedis runs Redis clients as processes of the Server: they are pinged at startup,
+// checked by the readiness checks and closed after the servers have shut down
+func WithRedis(clients ...*redis.Client) Option {
+	return func(s *Server) {
+		for _, c := range clients {
+			s.processes = append(s.processes, c)
+		}
+	}
+}
+
+// WithMigrations runs runner at startup, after the PreRun of the processes added with
+// WithProcesses and WithRedis, so their connections are ready, and before the servers
+// open their listeners. A failing migration stops the startup. Use migrate.WithLocker to
+// keep replicas from migrating concurrently.
+func WithMigrations(runner migrate.Runner, opts ...migrate.Option) Option {
+	return func(s *Server) {
+		s.migrationRunner = runner
+		s.migrationOptions = opts
+	}
+}
+
+// WithSentry reports errors to the Sentry project of dsn, see WithErrorReporter. Events
+// are tagged with SERVICE_VERSION as release and ENVIRONMENT, and flushed on shutdown
+// once the servers have stopped.
+func WithSentry(dsn string, opts ...sentry.Option) Option {
+	return func(s *Server) {
+		s.sentryDSN = dsn
+		s.sentryOptions = append(s.sentryOptions, opts...)
+	}
+}
+
+// WithRollbar reports errors to the Rollbar project of a post_server_item access token,
+// see WithErrorReporter. Items are tagged with SERVICE_VERSION as code version and
+// ENVIRONMENT, and flushed on shutdown once the servers have stopped.
+func WithRollbar(token string, opts ...rollbar.Option) Option {
+	return func(s *Server) {
+		s.rollbarToken = token
+		s.rollbarOptions = append(s.rollbarOptions, opts...)
+	}
+}
+
+// WithErrorReporter sends failures to the reporters: panics and errors of gRPC calls with
+// a reported code, panics of gateway handlers, and processes failing, panicking or
+// failing to shut down. Panics are logged even without reporters. Reporters that are
+// processes run alongside the servers and shut down after them.
+func WithErrorReporter(reporters ...errreport.ErrorReporter) Option {
+	return func(s *Server) {
+		s.errorReporters = append(s.errorReporters, reporters...)
+	}
+}
+
+// WithReportedCodes sets the codes of gRPC errors sent to the error reporters,
+// middleware.DefaultReportedCodes by default
+func WithReportedCodes(reported ...codes.Code) Option {
+	return func(s *Server) {
+		s.reportedCodes = reported
+	}
+}
+
+// WithWebhook posts a JSON notification to url when a process crashes, a PreRun fails or
+// shutdown exceeds CLOSE_TIMEOUT. Notifications carry the service, version, host and
+// error chain.
+func WithWebhook(url string, opts ...webhook.Option) Option {
+	return WithNotifier(webhook.New(url, opts...))
+}
+
+// WithSlackWebhook posts the notifications of WithWebhook as messages to a Slack incoming
+// webhook URL
+func WithSlackWebhook(url string, opts ...webhook.Option) Option {
+	return WithNotifier(webhook.NewSlack(url, opts...))
+}
+
+// WithNotifier sends the notifications of WithWebhook to notifiers, such as a pager
+func WithNotifier(notifiers ...webhook.Notifier) Option {
+	return func(s *Server) {
+		s.notifiers = append(s.notifiers, notifiers...)
+	}
+}
+
 // WithGatewayMuxOptions sets the ServeMux options for the gateway server
 func WithGatewayMuxOptions(options ...runtime.ServeMuxOption) Option {
 	return func(s *Server) {
@@ -71,6 +342,24 @@ func WithGatewayMuxOptions(options ...runtime.ServeMuxOption) Option {
 	}
 }
 
+// WithGatewayMetadataAnnotators adds the metadata the annotators return to the gRPC calls
+// of the gateways, e.g. the client IP or the result of authenticating the HTTP request,
+// as runtime.WithMetadata does for a single gateway
+func WithGatewayMetadataAnnotators(annotators ...GatewayMetadataAnnotator) Option {
+	return func(s *Server) {
+		s.gwMetadataAnnotators = append(s.gwMetadataAnnotators, annotators...)
+	}
+}
+
+// WithGatewayResponseModifiers modifies the HTTP responses of the gateways' calls from
+// their response messages, setting cookies, headers or, with GatewayStatusCode, the
+// status code, as runtime.WithForwardResponseOption does for a single gateway
+func WithGatewayResponseModifiers(modifiers ...GatewayResponseModifier) Option {
+	return func(s *Server) {
+		s.gwResponseModifiers = append(s.gwResponseModifiers, modifiers...)
+	}
+}
+
 // WithGatewayCORS enables CORS with the specified options for the gateway
 func WithGatewayCORS(options cors.Options) Option {
 	return func(s *Server) {
@@ -79,73 +368,439 @@ func WithGatewayCORS(options cors.Options) Option {
 	}
 }
 
+// WithGatewayDiscardUnknown makes the gateway ignore unknown fields of JSON request
+// bodies instead of rejecting them with a 400, for clients sending extra fields
+func WithGatewayDiscardUnknown(enabled bool) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.JSONDiscardUnknown = enabled
+	})
+}
+
+// WithGatewayUnescapingMode sets how the gateway unescapes path parameters: "legacy" (the
+// default), "all_except_reserved", "all_except_slash" or "all_characters"; use
+// "all_except_slash" for parameters containing %2F
+func WithGatewayUnescapingMode(mode string) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.GatewayUnescapingMode = mode
+	})
+}
+
+// WithGatewayPathLengthFallback enables or disables serving form-encoded POST requests as
+// the GET route of their path, with the form fields as query parameters
+func WithGatewayPathLengthFallback(enabled bool) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.GatewayPathLengthFallback = enabled
+	})
+}
+
+// WithGatewayStreamFraming sets how the gateway frames server-streaming responses:
+// "default" (grpc-gateway's {"result": ...} lines), "ndjson", "json-array" or
+// "length-prefixed"
+func WithGatewayStreamFraming(framing string) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.GatewayStreamFraming = framing
+	})
+}
+
+// WithGatewayOutgoingHeaders sets how the gateway forwards the metadata of gRPC responses
+// as headers: "prefixed" (Grpc-Metadata-<key>, the default), "stripped" (<key>) or
+// "none"; with keys, only the listed metadata keys, "*" suffixes matching prefixes
+func WithGatewayOutgoingHeaders(mode string, keys ...string) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.GatewayOutgoingHeaders = mode
+		cfg.GatewayOutgoingHeaderKeys = keys
+	})
+}
+
+// WithGatewayTimeoutHeaders sets the request headers from which gateway clients set the
+// deadline of the gRPC call, such as "X-Request-Timeout: 1.5s"
+func WithGatewayTimeoutHeaders(headers ...string) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.GatewayTimeoutHeaders = headers
+	})
+}
+
+// WithGatewayStreamFlushInterval flushes server-streaming responses at most once per
+// interval instead of after every message
+func WithGatewayStreamFlushInterval(interval time.Duration) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.GatewayStreamFlushInterval = interval
+	})
+}
+
+// WithGatewayPartialResponse enables or disables pruning gateway GET responses to the
+// fields listed in the fields query parameter, such as ?fields=id,author.displayName
+func WithGatewayPartialResponse(enabled bool) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.GatewayPartialResponseEnabled = enabled
+	})
+}
+
+// WithGraphQL enables or disables the GraphQL endpoint generated from the services,
+// served on the gateway at GRAPHQL_PATH
+func WithGraphQL(enabled bool) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.GraphQLEnabled = enabled
+	})
+}
+
+// WithGraphQLPath sets the path of the GraphQL endpoint, /graphql by default
+func WithGraphQLPath(path string) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.GraphQLPath = path
+	})
+}
+
+// WithGraphQLLimits sets how deep GraphQL operations may nest fields and how many fields
+// they may select; 0 disables a limit
+func WithGraphQLLimits(maxDepth, maxComplexity int) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.GraphQLMaxDepth = maxDepth
+		cfg.GraphQLMaxComplexity = maxComplexity
+	})
+}
+
+// WithTwirp enables or disables serving the services over Twirp-style
+// POST /twirp/pkg.Service/Method endpoints on the gateway
+func WithTwirp(enabled bool) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.TwirpEnabled = enabled
+	})
+}
+
+// WithTwirpPrefix sets the path prefix of the Twirp endpoints, /twirp by default
+func WithTwirpPrefix(prefix string) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.TwirpPrefix = prefix
+	})
+}
+
+// WithTwirpJSONRPC also serves the services as JSON-RPC 2.0 on path when Twirp is
+// enabled; an empty path disables it
+func WithTwirpJSONRPC(path string) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.TwirpJSONRPCPath = path
+	})
+}
+
+// WithGatewayNotFoundHandler sets the error returned for HTTP requests matching no route,
+// written in the format of RPC errors; by default it is NotFound with "Not Found"
+func WithGatewayNotFoundHandler(fn GatewayRoutingErrorFunc) Option {
+	return func(s *Server) {
+		s.gwNotFound = fn
+	}
+}
+
+// WithGatewayMethodNotAllowedHandler sets the error returned for HTTP requests whose path
+// matches a route but not its method, written in the format of RPC errors; by default it
+// is Unimplemented, answered with a 501
+func WithGatewayMethodNotAllowedHandler(fn GatewayRoutingErrorFunc) Option {
+	return func(s *Server) {
+		s.gwMethodNotAllowed = fn
+	}
+}
+
 // Configuration shortcuts for common config fields
 
 // WithGRPCAddress sets the gRPC server address
 func WithGRPCAddress(address string) Option {
-	return func(s *Server) {
-		s.cfg.GRPCAddress = address
-	}
+	return configOption(func(cfg *config.Config) {
+		cfg.GRPCAddress = address
+	})
 }
 
 // WithHTTPAddress sets the HTTP server address
 func WithHTTPAddress(address string) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.HTTPAddress = address
+	})
+}
+
+// WithHTTP enables or disables the gateway; gRPC-only services need no HTTP listener
+func WithHTTP(enabled bool) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.HTTPEnabled = enabled
+	})
+}
+
+// WithHTTPHealthPaths sets the paths of the gateway health endpoint, such as /healthz
+// on platforms expecting it; no paths disable it when /health clashes with a route
+func WithHTTPHealthPaths(paths ...string) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.HTTPHealthPaths = paths
+	})
+}
+
+// WithHTTPVersionPath sets the path of the gateway version endpoint, /version by default;
+// an empty path disables it
+func WithHTTPVersionPath(path string) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.HTTPVersionPath = path
+	})
+}
+
+// WithBuildInfo sets the version, commit and build date served by the version endpoints
+// and the admin API, usually set with -ldflags; empty fields fall back to SERVICE_VERSION
+// and the VCS information of the binary
+func WithBuildInfo(info BuildInfo) Option {
 	return func(s *Server) {
-		s.cfg.HTTPAddress = address
+		s.buildInfo = info
 	}
 }
 
+// WithMetricsServer enables or disables the metrics server
+func WithMetricsServer(enabled bool) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.MetricsServerEnabled = enabled
+	})
+}
+
 // WithMetricsAddress sets the metrics server address
 func WithMetricsAddress(address string) Option {
-	return func(s *Server) {
-		s.cfg.MetricsAddress = address
-	}
+	return configOption(func(cfg *config.Config) {
+		cfg.MetricsAddress = address
+	})
+}
+
+// WithPprof enables or disables the pprof server
+func WithPprof(enabled bool) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.PprofEnabled = enabled
+	})
 }
 
 // WithPprofAddress sets the pprof server address
 func WithPprofAddress(address string) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.PprofAddress = address
+	})
+}
+
+// WithAdmin enables or disables the internal admin gRPC server
+func WithAdmin(enabled bool) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.AdminEnabled = enabled
+	})
+}
+
+// WithAdminAddress sets the admin gRPC server address
+func WithAdminAddress(address string) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.AdminAddress = address
+	})
+}
+
+// WithAdminHTTPAddress serves metrics, pprof and a health endpoint on a single address
+// instead of separate listeners
+func WithAdminHTTPAddress(address string) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.AdminHTTPAddress = address
+	})
+}
+
+// WithReusePort binds all listeners with SO_REUSEPORT, so a new version of the service
+// can start accepting on the same addresses before the old one drains
+func WithReusePort(enabled bool) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.ReusePortEnabled = enabled
+	})
+}
+
+// WithSocketOptions sets the TCP keepalive period of accepted connections (0 uses Go's
+// 15s, negative disables it), TCP_NODELAY and the listen backlog (0 uses the system's
+// default) of every listener
+func WithSocketOptions(keepAlive time.Duration, noDelay bool, backlog int) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.TCPKeepAlive = keepAlive
+		cfg.TCPNoDelay = noDelay
+		cfg.ListenBacklog = backlog
+	})
+}
+
+// WithListenConfig adjusts the listen config of every listener after the socket options
+// are applied, e.g. to set other options with Control; chain the Control already set to
+// keep SO_REUSEPORT
+func WithListenConfig(hook func(*net.ListenConfig)) Option {
 	return func(s *Server) {
-		s.cfg.PprofAddress = address
+		s.listenConfigHook = hook
 	}
 }
 
-// WithCloseTimeout sets the timeout for graceful shutdown
-func WithCloseTimeout(timeout time.Duration) Option {
+// WithShutdownDelay keeps serving for the given duration after the context passed to Run
+// is canceled, before shutdown begins
+func WithShutdownDelay(delay time.Duration) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.ShutdownDelay = delay
+	})
+}
+
+// WithStartupTimeout logs the timing of each startup phase when the server is not ready
+// within the given timeout, e.g. a PreRun hanging on an unreachable dependency. With
+// failFast, Run then fails with ErrStartupTimeout instead of waiting longer.
+func WithStartupTimeout(timeout time.Duration, failFast bool) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.StartupTimeout = timeout
+		cfg.StartupFailFast = failFast
+	})
+}
+
+// WithSinglePort serves HTTP, gRPC (via h2c), metrics and pprof on the given port,
+// as required by platforms routing a single PORT such as Cloud Run and Heroku
+func WithSinglePort(port string) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.SinglePortEnabled = true
+		cfg.Port = port
+	})
+}
+
+// WithLambda serves the gateway from AWS Lambda events (API Gateway, ALB, Function URLs)
+// instead of listening for HTTP requests
+func WithLambda(enabled bool) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.LambdaEnabled = enabled
+	})
+}
+
+// WithInMemoryTransport serves gRPC and the gateway on in-memory listeners instead of
+// TCP, for hermetic tests. The gateway dials the gRPC server in memory, and clients
+// connect with DialGRPCInMemory and DialHTTPInMemory. Metrics, pprof, the admin servers
+// and the service registry are disabled.
+func WithInMemoryTransport() Option {
 	return func(s *Server) {
-		s.cfg.CloseTimeout = timeout
+		s.grpcMemory = newMemoryListener()
+		s.httpMemory = newMemoryListener()
 	}
 }
 
+// WithCloseTimeout sets the timeout for graceful shutdown
+func WithCloseTimeout(timeout time.Duration) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.CloseTimeout = timeout
+	})
+}
+
 // WithReflection enables or disables gRPC reflection
 func WithReflection(enabled bool) Option {
-	return func(s *Server) {
-		s.cfg.ReflectionEnabled = enabled
-	}
+	return configOption(func(cfg *config.Config) {
+		cfg.ReflectionEnabled = enabled
+	})
+}
+
+// WithGRPCKeepalive sets the gRPC keepalive parameters and the enforcement policy for client pings
+func WithGRPCKeepalive(params keepalive.ServerParameters, policy keepalive.EnforcementPolicy) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.GRPCKeepalive = config.GRPCKeepaliveConfig{
+			MaxConnectionIdle:     params.MaxConnectionIdle,
+			MaxConnectionAge:      params.MaxConnectionAge,
+			MaxConnectionAgeGrace: params.MaxConnectionAgeGrace,
+			Time:                  params.Time,
+			Timeout:               params.Timeout,
+			MinTime:               policy.MinTime,
+			PermitWithoutStream:   policy.PermitWithoutStream,
+		}
+	})
+}
+
+// WithReflectionVersions selects the reflection protocol versions to register ("v1", "v1alpha" or "both")
+func WithReflectionVersions(versions string) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.ReflectionVersions = versions
+	})
 }
 
 // WithHealthCheck enables or disables health checks
 func WithHealthCheck(enabled bool) Option {
-	return func(s *Server) {
-		s.cfg.HealthCheckEnabled = enabled
-	}
+	return configOption(func(cfg *config.Config) {
+		cfg.HealthCheckEnabled = enabled
+	})
+}
+
+// WithHealthCheckInterval sets the interval at which registrars implementing
+// service.HealthChecker update the gRPC health status of their services; 0 disables it
+func WithHealthCheckInterval(interval time.Duration) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.HealthCheckInterval = interval
+	})
 }
 
 // WithSwaggerDir sets the directory containing swagger files
 func WithSwaggerDir(dir string) Option {
-	return func(s *Server) {
-		s.cfg.SwaggerEnabled = true
-		s.cfg.SwaggerDir = dir
-	}
+	return configOption(func(cfg *config.Config) {
+		cfg.SwaggerEnabled = true
+		cfg.SwaggerDir = dir
+	})
 }
 
 // WithSwaggerBasePath sets the base path for swagger UI
 func WithSwaggerBasePath(path string) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.SwaggerEnabled = true
+		cfg.SwaggerBasePath = path
+	})
+}
+
+// WithSwaggerFS enables Swagger serving the *.swagger.json files in fsys instead of
+// reading SwaggerDir at runtime, e.g. files embedded at build time with go:embed
+func WithSwaggerFS(fsys fs.FS, basePath string) Option {
+	setBasePath := WithSwaggerBasePath(basePath)
+	return func(s *Server) {
+		s.swaggerFS = fsys
+		setBasePath(s)
+	}
+}
+
+// WithSwaggerUICDN loads the Swagger UI assets from the CDN base URL instead of the
+// embedded copies, e.g. "https://unpkg.com/swagger-ui-dist@5"
+func WithSwaggerUICDN(url string) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.SwaggerUICDN = url
+	})
+}
+
+// WithSwaggerAuthToken requires Swagger requests to carry an "Authorization: Bearer <token>" header
+func WithSwaggerAuthToken(token string) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.SwaggerAuthToken = token
+	})
+}
+
+// WithSwaggerBasicAuth requires Swagger requests to carry the given HTTP basic auth credentials
+func WithSwaggerBasicAuth(user, password string) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.SwaggerBasicAuthUser = user
+		cfg.SwaggerBasicAuthPassword = password
+	})
+}
+
+// WithSwaggerInProduction serves Swagger even when the environment is "production"
+func WithSwaggerInProduction(enabled bool) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.SwaggerProductionEnabled = enabled
+	})
+}
+
+// WithSplashDisabled turns off the splash screen printed once the server has started
+func WithSplashDisabled() Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.SplashEnabled = false
+	})
+}
+
+// WithSplashWriter displays the splash screen on w instead of stdout
+func WithSplashWriter(w io.Writer) Option {
 	return func(s *Server) {
-		s.cfg.SwaggerEnabled = true
-		s.cfg.SwaggerBasePath = path
+		s.splashWriter = w
 	}
 }
 
+// WithSplashFormat sets how the splash screen is displayed: splash.FormatBanner prints
+// the banner, splash.FormatLog emits a single structured log record
+func WithSplashFormat(format string) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.SplashFormat = format
+	})
+}
+
 // WithTelemetry enables telemetry for the server with the given configuration
 func WithTelemetry() Option {
 	return func(s *Server) {
@@ -153,44 +808,132 @@ func WithTelemetry() Option {
 	}
 }
 
+// WithDefaultMiddleware enables the recommended interceptor stack: request IDs, access
+// logs, panic recovery and a default deadline for unary calls, in front of any
+// interceptors added with WithGRPCUnaryInterceptors, followed by telemetry
+func WithDefaultMiddleware() Option {
+	return func(s *Server) {
+		s.defaultMiddleware = true
+		s.telemetryEnabled = true
+	}
+}
+
+// WithAccessLogSink sets where the default middleware writes access logs: "slog" (the
+// application log, the default), "stdout", "file" or "otlp"
+func WithAccessLogSink(sink string) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.AccessLog.Sink = sink
+	})
+}
+
+// WithAccessLogFile writes access logs to the file at path, appending to it
+func WithAccessLogFile(path string) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.AccessLog.Sink = "file"
+		cfg.AccessLog.FilePath = path
+	})
+}
+
+// WithAccessLogFormat sets the format of the stdout and file access log sinks: "json"
+// (the default) or "combined" for the Apache combined log format
+func WithAccessLogFormat(format string) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.AccessLog.Format = format
+	})
+}
+
+// WithAccessLogSampleRates keeps only a fraction of the access logs of high-traffic
+// routes, each rate written as pattern=rate, such as "/orders.v1.Orders/List=0.1"
+func WithAccessLogSampleRates(rates ...string) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.AccessLog.SampleRates = rates
+	})
+}
+
+// WithHTTPAccessLog enables or disables access logs of gateway HTTP requests, alongside
+// the ones of gRPC calls
+func WithHTTPAccessLog(enabled bool) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.AccessLog.HTTPEnabled = enabled
+	})
+}
+
+// WithObservabilityExcludePaths sets the paths left out of gateway access logs and
+// in-flight request metrics, replacing the default list of operational endpoints; paths
+// ending with "*" match every path starting with the rest
+func WithObservabilityExcludePaths(paths ...string) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.ObservabilityExcludePaths = paths
+	})
+}
+
 // WithTracingBackend configures which tracing backend to use
 func WithTracingBackend(backend string, endpoint string) Option {
+	setBackend := configOption(func(cfg *config.Config) {
+		cfg.Telemetry.Tracing.Enabled = true
+		cfg.Telemetry.Tracing.Backend = backend
+		cfg.Telemetry.Tracing.Endpoint = endpoint
+	})
 	return func(s *Server) {
 		s.telemetryEnabled = true
-		s.cfg.Telemetry.Tracing.Enabled = true
-		s.cfg.Telemetry.Tracing.Backend = backend
-		s.cfg.Telemetry.Tracing.Endpoint = endpoint
+		setBackend(s)
 	}
 }
 
+// WithTracingSampler sets the trace sampler: "ratio" samples the configured ratio of
+// traces, "errorbiased" also never samples health checks and reflection calls and always
+// exports failing spans
+func WithTracingSampler(sampler string) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.Telemetry.Tracing.Sampler = sampler
+	})
+}
+
 // WithMetricsBackend configures which metrics backend to use
 func WithMetricsBackend(backend string, endpoint string) Option {
+	setBackend := configOption(func(cfg *config.Config) {
+		cfg.Telemetry.Metrics.Enabled = true
+		cfg.Telemetry.Metrics.Backend = backend
+		cfg.Telemetry.Metrics.Endpoint = endpoint
+	})
 	return func(s *Server) {
 		s.telemetryEnabled = true
-		s.cfg.Telemetry.Metrics.Enabled = true
-		s.cfg.Telemetry.Metrics.Backend = backend
-		s.cfg.Telemetry.Metrics.Endpoint = endpoint
+		setBackend(s)
 	}
 }
 
 // WithProfilingBackend configures which profiling backend to use
 func WithProfilingBackend(backend string, endpoint string) Option {
+	setBackend := configOption(func(cfg *config.Config) {
+		cfg.Telemetry.Profiling.Enabled = true
+		cfg.Telemetry.Profiling.Backend = backend
+		cfg.Telemetry.Profiling.Endpoint = endpoint
+	})
 	return func(s *Server) {
 		s.telemetryEnabled = true
-		s.cfg.Telemetry.Profiling.Enabled = true
-		s.cfg.Telemetry.Profiling.Backend = backend
-		s.cfg.Telemetry.Profiling.Endpoint = endpoint
+		setBackend(s)
 	}
 }
 
 // WithOTEL configures OpenTelemetry as a unified provider
 func WithOTEL(endpoint string, insecure bool) Option {
+	setOTEL := configOption(func(cfg *config.Config) {
+		cfg.Telemetry.OTEL.Enabled = true
+		cfg.Telemetry.OTEL.Endpoint = endpoint
+		cfg.Telemetry.OTEL.Insecure = insecure
+		cfg.Telemetry.OTEL.TracesEnabled = true
+		cfg.Telemetry.OTEL.MetricsEnabled = true
+	})
 	return func(s *Server) {
 		s.telemetryEnabled = true
-		s.cfg.Telemetry.OTEL.Enabled = true
-		s.cfg.Telemetry.OTEL.Endpoint = endpoint
-		s.cfg.Telemetry.OTEL.Insecure = insecure
-		s.cfg.Telemetry.OTEL.TracesEnabled = true
-		s.cfg.Telemetry.OTEL.MetricsEnabled = true
+		setOTEL(s)
 	}
 }
+
+// WithTelemetryFlushTimeout sets the time given to flushing the last spans, metrics and
+// profiles on shutdown, on top of the close timeout
+func WithTelemetryFlushTimeout(timeout time.Duration) Option {
+	return configOption(func(cfg *config.Config) {
+		cfg.Telemetry.FlushTimeout = timeout
+	})
+}
@@ -1,18 +1,33 @@
 package server
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
 	"log/slog"
 	"net/http"
 	"os"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/legrch/netgex/config"
+	"github.com/legrch/netgex/errreport"
+	"github.com/legrch/netgex/fieldmask"
+	"github.com/legrch/netgex/httpcache"
+	"github.com/legrch/netgex/internal/listener"
+	"github.com/legrch/netgex/redis"
+	"github.com/legrch/netgex/rollbar"
+	"github.com/legrch/netgex/sentry"
+	"github.com/legrch/netgex/tenant"
 	"github.com/rs/cors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/stats"
 )
 
 // mockRegistrar implements service.Registrar
@@ -23,6 +38,11 @@ func (m *mockRegistrar) RegisterHTTP(ctx context.Context, mux *runtime.ServeMux,
 	return nil
 }
 
+// mockStatsHandler implements stats.Handler
+type mockStatsHandler struct {
+	stats.Handler
+}
+
 // mockProcess implements Process
 type mockProcess struct{}
 
@@ -75,6 +95,58 @@ func TestWithServices(t *testing.T) {
 	assert.Contains(t, s.services, svc2)
 }
 
+func TestWithGRPCListener(t *testing.T) {
+	// Arrange
+	s := &Server{}
+	svc := &mockRegistrar{}
+	unary := func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(ctx, req)
+	}
+
+	// Act
+	opt := WithGRPCListener("admin", ":9095",
+		WithListenerServices(svc),
+		WithListenerUnaryInterceptors(unary),
+		WithListenerTLS(&tls.Config{MinVersion: tls.VersionTLS13}),
+	)
+	opt(s)
+
+	// Assert
+	require.Len(t, s.grpcListeners, 1)
+	l := s.grpcListeners[0]
+	assert.Equal(t, "admin", l.name)
+	assert.Equal(t, ":9095", l.address)
+	assert.Contains(t, l.services, svc)
+	assert.Len(t, l.unaryInterceptors, 1)
+	assert.NotNil(t, l.tlsConfig)
+}
+
+func TestWithGatewayServer(t *testing.T) {
+	// Arrange
+	s := &Server{}
+	svc := &mockRegistrar{}
+
+	// Act
+	opt := WithGatewayServer("partner", "",
+		WithGatewayServerServices(svc),
+		WithGatewayServerHosts("partner.example.com"),
+		WithGatewayServerCORS(cors.Options{AllowedOrigins: []string{"https://partner.example.com"}}),
+		WithGatewayServerMiddleware(func(next http.Handler) http.Handler { return next }),
+	)
+	opt(s)
+
+	// Assert
+	require.Len(t, s.gatewayServers, 1)
+	g := s.gatewayServers[0]
+	assert.Equal(t, "partner", g.name)
+	assert.Empty(t, g.address)
+	assert.Equal(t, []string{"partner.example.com"}, g.hosts)
+	assert.Contains(t, g.services, svc)
+	require.NotNil(t, g.corsOptions)
+	assert.Equal(t, []string{"https://partner.example.com"}, g.corsOptions.AllowedOrigins)
+	assert.Len(t, g.middleware, 1)
+}
+
 func TestWithProcesses(t *testing.T) {
 	// Arrange
 	s := &Server{}
@@ -91,6 +163,88 @@ func TestWithProcesses(t *testing.T) {
 	assert.Contains(t, s.processes, p2)
 }
 
+// nopRedisConn is a Redis client that is always reachable
+type nopRedisConn struct{}
+
+func (nopRedisConn) Ping(context.Context) error { return nil }
+
+func (nopRedisConn) Close() error { return nil }
+
+func TestWithRedis(t *testing.T) {
+	// Arrange
+	s := &Server{}
+	c := redis.New(nopRedisConn{})
+
+	// Act
+	WithRedis(c)(s)
+
+	// Assert
+	require.Len(t, s.processes, 1)
+	assert.Same(t, c, s.processes[0])
+}
+
+func TestWithSentry(t *testing.T) {
+	// Arrange
+	s := &Server{}
+
+	// Act
+	WithSentry("https://public@o1.ingest.sentry.io/42", sentry.WithServerName("orders-0"))(s)
+
+	// Assert
+	assert.Equal(t, "https://public@o1.ingest.sentry.io/42", s.sentryDSN)
+	assert.Len(t, s.sentryOptions, 1)
+}
+
+func TestWithRollbar(t *testing.T) {
+	// Arrange
+	s := &Server{}
+
+	// Act
+	WithRollbar("secret", rollbar.WithServerHost("orders-0"))(s)
+
+	// Assert
+	assert.Equal(t, "secret", s.rollbarToken)
+	assert.Len(t, s.rollbarOptions, 1)
+}
+
+func TestWithErrorReporter(t *testing.T) {
+	// Arrange
+	s := &Server{}
+	reporter := errreport.NewLogReporter(slog.Default())
+
+	// Act
+	WithErrorReporter(reporter)(s)
+	WithReportedCodes(codes.Internal)(s)
+
+	// Assert
+	assert.Equal(t, []errreport.ErrorReporter{reporter}, s.errorReporters)
+	assert.Equal(t, []codes.Code{codes.Internal}, s.reportedCodes)
+}
+
+func TestWithWebhook(t *testing.T) {
+	// Arrange
+	s := &Server{}
+
+	// Act
+	WithWebhook("https://hooks.example.com/netgex")(s)
+	WithSlackWebhook("https://hooks.slack.com/services/T0/B0/x")(s)
+
+	// Assert
+	assert.Len(t, s.notifiers, 2)
+}
+
+func TestWithSplashWriter(t *testing.T) {
+	// Arrange
+	s := &Server{}
+	var buf bytes.Buffer
+
+	// Act
+	WithSplashWriter(&buf)(s)
+
+	// Assert
+	assert.Equal(t, &buf, s.splashWriter)
+}
+
 func TestWithGRPCServerOptions(t *testing.T) {
 	// Arrange
 	s := &Server{}
@@ -123,6 +277,19 @@ func TestWithGRPCUnaryInterceptors(t *testing.T) {
 	assert.Len(t, s.grpcUnaryServerInterceptors, 2)
 }
 
+func TestWithDefaultMiddleware(t *testing.T) {
+	// Arrange
+	s := &Server{}
+
+	// Act
+	o := WithDefaultMiddleware()
+	o(s)
+
+	// Assert
+	assert.True(t, s.defaultMiddleware)
+	assert.True(t, s.telemetryEnabled)
+}
+
 func TestWithGRPCStreamInterceptors(t *testing.T) {
 	// Arrange
 	s := &Server{}
@@ -141,6 +308,81 @@ func TestWithGRPCStreamInterceptors(t *testing.T) {
 	assert.Len(t, s.grpcStreamServerInterceptors, 2)
 }
 
+func TestWithGRPCStatsHandlers(t *testing.T) {
+	// Arrange
+	s := &Server{}
+	handler := &mockStatsHandler{}
+
+	// Act
+	WithGRPCStatsHandlers(handler)(s)
+	WithGRPCStatsHandlers(handler)(s)
+
+	// Assert
+	assert.Len(t, s.grpcStatsHandlers, 2)
+}
+
+func TestWithGatewayCache(t *testing.T) {
+	// Arrange
+	s := &Server{}
+	store := httpcache.NewMemoryStore(100)
+
+	// Act
+	WithGatewayCache(store, httpcache.WithRoute("/v1/items", time.Minute))(s)
+
+	// Assert
+	assert.Same(t, store, s.gwCacheStore)
+	assert.Len(t, s.gwCacheOptions, 1)
+}
+
+func TestWithGatewayETags(t *testing.T) {
+	// Arrange
+	s := &Server{}
+
+	// Act
+	WithGatewayETags(httpcache.WithETagRoute("/v1", true))(s)
+
+	// Assert
+	assert.True(t, s.gwETagEnabled)
+	assert.Len(t, s.gwETagOptions, 1)
+}
+
+func TestWithGatewayCacheControl(t *testing.T) {
+	// Arrange
+	s := &Server{}
+
+	// Act
+	WithGatewayCacheControl(httpcache.WithControlRoute("/v1", "no-store"))(s)
+	WithGatewayCacheControl(httpcache.WithControlRoute("/v1/products", "public, max-age=60", http.MethodGet))(s)
+
+	// Assert
+	assert.Len(t, s.gwCacheControlOptions, 2)
+}
+
+func TestWithTenancy(t *testing.T) {
+	// Arrange
+	s := &Server{}
+
+	// Act
+	WithTenancy(tenant.WithRequired())(s)
+	WithTenancy(tenant.WithRateLimit(10, 20))(s)
+
+	// Assert
+	assert.True(t, s.tenancyEnabled)
+	assert.Len(t, s.tenancyOptions, 2)
+}
+
+func TestWithFieldMasks(t *testing.T) {
+	// Arrange
+	s := &Server{}
+
+	// Act
+	WithFieldMasks(fieldmask.WithReadMaskField("field_mask"))(s)
+
+	// Assert
+	assert.True(t, s.fieldMasksEnabled)
+	assert.Len(t, s.fieldMaskOptions, 1)
+}
+
 func TestWithGatewayMuxOptions(t *testing.T) {
 	// Arrange
 	s := &Server{}
@@ -200,6 +442,27 @@ func TestConfigShortcuts(t *testing.T) {
 				assert.Equal(t, ":9092", s.cfg.MetricsAddress)
 			},
 		},
+		{
+			name:   "WithHTTP",
+			option: WithHTTP(false),
+			validate: func(t *testing.T, s *Server) {
+				assert.False(t, s.cfg.HTTPEnabled)
+			},
+		},
+		{
+			name:   "WithMetricsServer",
+			option: WithMetricsServer(false),
+			validate: func(t *testing.T, s *Server) {
+				assert.False(t, s.cfg.MetricsServerEnabled)
+			},
+		},
+		{
+			name:   "WithPprof",
+			option: WithPprof(false),
+			validate: func(t *testing.T, s *Server) {
+				assert.False(t, s.cfg.PprofEnabled)
+			},
+		},
 		{
 			name:   "WithPprofAddress",
 			option: WithPprofAddress(":6061"),
@@ -207,6 +470,66 @@ func TestConfigShortcuts(t *testing.T) {
 				assert.Equal(t, ":6061", s.cfg.PprofAddress)
 			},
 		},
+		{
+			name:   "WithAdmin",
+			option: WithAdmin(true),
+			validate: func(t *testing.T, s *Server) {
+				assert.True(t, s.cfg.AdminEnabled)
+			},
+		},
+		{
+			name:   "WithAdminAddress",
+			option: WithAdminAddress("127.0.0.1:9096"),
+			validate: func(t *testing.T, s *Server) {
+				assert.Equal(t, "127.0.0.1:9096", s.cfg.AdminAddress)
+			},
+		},
+		{
+			name:   "WithAdminHTTPAddress",
+			option: WithAdminHTTPAddress(":9092"),
+			validate: func(t *testing.T, s *Server) {
+				assert.Equal(t, ":9092", s.cfg.AdminHTTPAddress)
+			},
+		},
+		{
+			name:   "WithReusePort",
+			option: WithReusePort(true),
+			validate: func(t *testing.T, s *Server) {
+				assert.True(t, s.cfg.ReusePortEnabled)
+			},
+		},
+		{
+			name:   "WithSocketOptions",
+			option: WithSocketOptions(time.Minute, false, 4096),
+			validate: func(t *testing.T, s *Server) {
+				assert.Equal(t, time.Minute, s.cfg.TCPKeepAlive)
+				assert.False(t, s.cfg.TCPNoDelay)
+				assert.Equal(t, 4096, s.cfg.ListenBacklog)
+				assert.Equal(t, listener.Options{KeepAlive: time.Minute, Delay: true, Backlog: 4096}, s.listenOptions())
+			},
+		},
+		{
+			name:   "WithShutdownDelay",
+			option: WithShutdownDelay(5 * time.Second),
+			validate: func(t *testing.T, s *Server) {
+				assert.Equal(t, 5*time.Second, s.cfg.ShutdownDelay)
+			},
+		},
+		{
+			name:   "WithSinglePort",
+			option: WithSinglePort("8081"),
+			validate: func(t *testing.T, s *Server) {
+				assert.True(t, s.cfg.SinglePortEnabled)
+				assert.Equal(t, "8081", s.cfg.Port)
+			},
+		},
+		{
+			name:   "WithLambda",
+			option: WithLambda(true),
+			validate: func(t *testing.T, s *Server) {
+				assert.True(t, s.cfg.LambdaEnabled)
+			},
+		},
 		{
 			name:   "WithCloseTimeout",
 			option: WithCloseTimeout(5 * time.Second),
@@ -221,6 +544,27 @@ func TestConfigShortcuts(t *testing.T) {
 				assert.False(t, s.cfg.ReflectionEnabled)
 			},
 		},
+		{
+			name: "WithGRPCKeepalive",
+			option: WithGRPCKeepalive(
+				keepalive.ServerParameters{MaxConnectionAge: time.Minute, MaxConnectionAgeGrace: 10 * time.Second, Time: time.Hour},
+				keepalive.EnforcementPolicy{MinTime: time.Second, PermitWithoutStream: true},
+			),
+			validate: func(t *testing.T, s *Server) {
+				assert.Equal(t, time.Minute, s.cfg.GRPCKeepalive.MaxConnectionAge)
+				assert.Equal(t, 10*time.Second, s.cfg.GRPCKeepalive.MaxConnectionAgeGrace)
+				assert.Equal(t, time.Hour, s.cfg.GRPCKeepalive.Time)
+				assert.Equal(t, time.Second, s.cfg.GRPCKeepalive.MinTime)
+				assert.True(t, s.cfg.GRPCKeepalive.PermitWithoutStream)
+			},
+		},
+		{
+			name:   "WithReflectionVersions",
+			option: WithReflectionVersions("v1"),
+			validate: func(t *testing.T, s *Server) {
+				assert.Equal(t, "v1", s.cfg.ReflectionVersions)
+			},
+		},
 		{
 			name:   "WithHealthCheck",
 			option: WithHealthCheck(false),
@@ -228,6 +572,13 @@ func TestConfigShortcuts(t *testing.T) {
 				assert.False(t, s.cfg.HealthCheckEnabled)
 			},
 		},
+		{
+			name:   "WithHealthCheckInterval",
+			option: WithHealthCheckInterval(time.Minute),
+			validate: func(t *testing.T, s *Server) {
+				assert.Equal(t, time.Minute, s.cfg.HealthCheckInterval)
+			},
+		},
 		{
 			name:   "WithSwaggerDir",
 			option: WithSwaggerDir("/custom/swagger"),
@@ -244,6 +595,58 @@ func TestConfigShortcuts(t *testing.T) {
 				assert.Equal(t, "/api/v1", s.cfg.SwaggerBasePath)
 			},
 		},
+		{
+			name:   "WithSwaggerFS",
+			option: WithSwaggerFS(fstest.MapFS{}, "/api/v1"),
+			validate: func(t *testing.T, s *Server) {
+				assert.NotNil(t, s.swaggerFS)
+				assert.True(t, s.cfg.SwaggerEnabled)
+				assert.Equal(t, "/api/v1", s.cfg.SwaggerBasePath)
+			},
+		},
+		{
+			name:   "WithSplashDisabled",
+			option: WithSplashDisabled(),
+			validate: func(t *testing.T, s *Server) {
+				assert.False(t, s.cfg.SplashEnabled)
+			},
+		},
+		{
+			name:   "WithSplashFormat",
+			option: WithSplashFormat("log"),
+			validate: func(t *testing.T, s *Server) {
+				assert.Equal(t, "log", s.cfg.SplashFormat)
+			},
+		},
+		{
+			name:   "WithSwaggerUICDN",
+			option: WithSwaggerUICDN("https://unpkg.com/swagger-ui-dist@5"),
+			validate: func(t *testing.T, s *Server) {
+				assert.Equal(t, "https://unpkg.com/swagger-ui-dist@5", s.cfg.SwaggerUICDN)
+			},
+		},
+		{
+			name:   "WithSwaggerAuthToken",
+			option: WithSwaggerAuthToken("secret"),
+			validate: func(t *testing.T, s *Server) {
+				assert.Equal(t, "secret", s.cfg.SwaggerAuthToken)
+			},
+		},
+		{
+			name:   "WithSwaggerBasicAuth",
+			option: WithSwaggerBasicAuth("admin", "pass"),
+			validate: func(t *testing.T, s *Server) {
+				assert.Equal(t, "admin", s.cfg.SwaggerBasicAuthUser)
+				assert.Equal(t, "pass", s.cfg.SwaggerBasicAuthPassword)
+			},
+		},
+		{
+			name:   "WithSwaggerInProduction",
+			option: WithSwaggerInProduction(true),
+			validate: func(t *testing.T, s *Server) {
+				assert.True(t, s.cfg.SwaggerProductionEnabled)
+			},
+		},
 	}
 
 	for _, tt := range tests {
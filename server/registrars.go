@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/legrch/netgex/service"
+)
+
+// registrars returns every registrar of the server: those of the main gRPC server,
+// including the gateway servers' services, and those of the additional listeners,
+// each once
+func (s *Server) registrars() []service.Registrar {
+	registrars := s.grpcServices()
+	for _, l := range s.grpcListeners {
+		for _, svc := range l.services {
+			if !slices.Contains(registrars, svc) {
+				registrars = append(registrars, svc)
+			}
+		}
+	}
+	return registrars
+}
+
+// applySkippedServers skips the servers with nothing to serve: the gateway when none of
+// its registrars registers HTTP handlers, and the gRPC server when the registrars only
+// serve plain HTTP handlers. Both are kept when a feature relies on them.
+func (s *Server) applySkippedServers() {
+	if s.cfg.HTTPEnabled && len(s.services) > 0 && !slices.ContainsFunc(s.services, service.ServesHTTP) &&
+		!s.cfg.SinglePortEnabled && !s.cfg.LambdaEnabled && !s.cfg.GraphQLEnabled && !s.cfg.TwirpEnabled &&
+		len(s.gatewayServers) == 0 {
+		s.logger.Info("HTTP gateway skipped, no service registers HTTP handlers")
+		s.cfg.HTTPEnabled = false
+		s.cfg.HTTPAddress = ""
+	}
+
+	registrars := s.grpcServices()
+	if len(registrars) > 0 && !slices.ContainsFunc(registrars, service.ServesGRPC) &&
+		len(s.grpcListeners) == 0 && !s.cfg.SinglePortEnabled && !s.cfg.GraphQLEnabled && !s.cfg.TwirpEnabled &&
+		!s.cfg.AdminEnabled && s.cfg.AdminHTTPAddress == "" && !s.cfg.Registry.Enabled {
+		s.logger.Info("gRPC server skipped, no service registers a gRPC service")
+		s.grpcSkipped = true
+		s.cfg.GRPCAddress = ""
+	}
+}
+
+// logRegistrars logs the registered services along with the capabilities they implement
+func (s *Server) logRegistrars() {
+	for _, r := range s.registrars() {
+		_, health := r.(service.HealthChecker)
+		_, closer := r.(service.Closer)
+		s.logger.Info("service registered", "service", service.Name(r), "health_check", health, "close", closer)
+	}
+}
+
+// HealthCheck runs the health checks of the registrars and processes implementing
+// service.HealthChecker, such as database pools, and returns the errors of those that
+// fail, joined
+func (s *Server) HealthCheck(ctx context.Context) error {
+	var errs []error
+	for _, r := range s.registrars() {
+		if hc, ok := r.(service.HealthChecker); ok {
+			if err := hc.HealthCheck(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", service.Name(r), err))
+			}
+		}
+	}
+	for _, p := range s.processes {
+		if hc, ok := p.(service.HealthChecker); ok {
+			if err := hc.HealthCheck(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", processName(p), err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// processName returns the name of a process implementing service.Namer, or its type
+func processName(p Process) string {
+	if n, ok := p.(service.Namer); ok {
+		return n.Name()
+	}
+	return fmt.Sprintf("%T", p)
+}
+
+// closeRegistrars closes the registrars implementing service.Closer in reverse
+// registration order, returning the first error
+func (s *Server) closeRegistrars(ctx context.Context) error {
+	var firstErr error
+	registrars := s.registrars()
+	for i := len(registrars) - 1; i >= 0; i-- {
+		c, ok := registrars[i].(service.Closer)
+		if !ok {
+			continue
+		}
+		if err := c.Close(ctx); err != nil {
+			s.logger.Error("service close error", "service", service.Name(registrars[i]), "error", err)
+			if firstErr == nil {
+				firstErr = fmt.Errorf("close %s: %w", service.Name(registrars[i]), err)
+			}
+		}
+	}
+	return firstErr
+}
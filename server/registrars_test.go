@@ -0,0 +1,207 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	mocksvc "github.com/legrch/netgex/internal/mocks/service"
+	"github.com/legrch/netgex/service"
+)
+
+// capableRegistrar implements all optional registrar capabilities
+type capableRegistrar struct {
+	name      string
+	healthErr error
+	closeErr  error
+	closed    *[]string
+}
+
+func (r *capableRegistrar) RegisterGRPC(*grpc.Server) {}
+
+func (r *capableRegistrar) RegisterHTTP(context.Context, *runtime.ServeMux, string, []grpc.DialOption) error {
+	return nil
+}
+
+func (r *capableRegistrar) Name() string {
+	return r.name
+}
+
+func (r *capableRegistrar) HealthCheck(context.Context) error {
+	return r.healthErr
+}
+
+func (r *capableRegistrar) Close(context.Context) error {
+	*r.closed = append(*r.closed, r.name)
+	return r.closeErr
+}
+
+func TestServer_Registrars(t *testing.T) {
+	// Arrange
+	shared := mocksvc.NewRegistrar(t)
+	internal := mocksvc.NewRegistrar(t)
+	s := NewServer(
+		WithServices(shared),
+		WithGatewayServer("public", ":0", WithGatewayServerServices(shared)),
+		WithGRPCListener("internal", ":0", WithListenerServices(internal, shared)),
+	)
+
+	// Act
+	registrars := s.registrars()
+
+	// Assert
+	assert.Len(t, registrars, 2, "each registrar is listed once")
+}
+
+func TestServer_HealthCheck(t *testing.T) {
+	// Arrange
+	var closed []string
+	s := NewServer(WithServices(
+		&capableRegistrar{name: "orders", closed: &closed},
+		&capableRegistrar{name: "payments", healthErr: errors.New("database unreachable"), closed: &closed},
+		mocksvc.NewRegistrar(t),
+	))
+
+	// Act
+	err := s.HealthCheck(context.Background())
+
+	// Assert
+	assert.EqualError(t, err, "payments: database unreachable")
+}
+
+// checkedProcess is a process reporting its health, such as a database pool
+type checkedProcess struct {
+	mockProcess
+	healthErr error
+}
+
+func (p *checkedProcess) Name() string {
+	return "db/orders"
+}
+
+func (p *checkedProcess) HealthCheck(context.Context) error {
+	return p.healthErr
+}
+
+func TestServer_HealthCheckProcesses(t *testing.T) {
+	// Arrange
+	s := NewServer(WithProcesses(
+		&mockProcess{},
+		&checkedProcess{healthErr: errors.New("connection refused")},
+	))
+
+	// Act
+	err := s.HealthCheck(context.Background())
+
+	// Assert
+	assert.EqualError(t, err, "db/orders: connection refused")
+}
+
+func TestServer_CloseRegistrars(t *testing.T) {
+	// Arrange
+	var closed []string
+	s := NewServer(
+		WithLogger(slog.Default()),
+		WithServices(
+			&capableRegistrar{name: "orders", closeErr: errors.New("flush failed"), closed: &closed},
+			&capableRegistrar{name: "payments", closed: &closed},
+		),
+	)
+
+	// Act
+	err := s.closeRegistrars(context.Background())
+
+	// Assert
+	assert.EqualError(t, err, "close orders: flush failed")
+	assert.Equal(t, []string{"payments", "orders"}, closed, "closed in reverse order")
+}
+
+// grpcOnlyRegistrar serves gRPC only
+type grpcOnlyRegistrar struct {
+	service.GRPCOnly
+}
+
+func (grpcOnlyRegistrar) RegisterGRPC(*grpc.Server) {}
+
+// httpOnlyRegistrar serves a plain HTTP handler only
+type httpOnlyRegistrar struct {
+	service.HTTPOnly
+}
+
+func (httpOnlyRegistrar) RegisterHTTP(_ context.Context, mux *runtime.ServeMux, _ string, _ []grpc.DialOption) error {
+	return mux.HandlePath(http.MethodGet, "/hello", func(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+		_, _ = io.WriteString(w, "hello")
+	})
+}
+
+func TestServer_ApplySkippedServers(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []Option
+		wantHTTP    bool
+		wantGRPC    bool
+		wantAddress bool
+	}{
+		{name: "no services", wantHTTP: true, wantGRPC: true},
+		{name: "gRPC and HTTP", opts: []Option{WithServices(&capableRegistrar{})}, wantHTTP: true, wantGRPC: true},
+		{name: "gRPC only", opts: []Option{WithServices(grpcOnlyRegistrar{})}, wantHTTP: false, wantGRPC: true},
+		{name: "mixed", opts: []Option{WithServices(grpcOnlyRegistrar{}, httpOnlyRegistrar{})}, wantHTTP: true, wantGRPC: true},
+		{name: "HTTP only", opts: []Option{WithServices(httpOnlyRegistrar{})}, wantHTTP: true, wantGRPC: false},
+		{name: "gRPC only in single-port mode", opts: []Option{WithServices(grpcOnlyRegistrar{}), WithSinglePort("8080")}, wantHTTP: true, wantGRPC: true},
+		{name: "gRPC only with Twirp", opts: []Option{WithServices(grpcOnlyRegistrar{}), WithTwirp(true)}, wantHTTP: true, wantGRPC: true},
+		{name: "HTTP only with the admin server", opts: []Option{WithServices(httpOnlyRegistrar{}), WithAdmin(true)}, wantHTTP: true, wantGRPC: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			s := NewServer(append([]Option{WithLogger(slog.New(slog.DiscardHandler))}, tt.opts...)...)
+
+			// Act
+			s.applySkippedServers()
+
+			// Assert
+			assert.Equal(t, tt.wantHTTP, s.cfg.HTTPEnabled)
+			assert.Equal(t, tt.wantHTTP, s.cfg.HTTPAddress != "")
+			assert.Equal(t, tt.wantGRPC, !s.grpcSkipped)
+			assert.Equal(t, tt.wantGRPC, s.cfg.GRPCAddress != "")
+		})
+	}
+}
+
+func TestServer_Run_HTTPOnly(t *testing.T) {
+	// Arrange
+	s := NewServer(
+		WithLogger(slog.New(slog.DiscardHandler)),
+		WithSplashDisabled(),
+		WithInMemoryTransport(),
+		WithServices(httpOnlyRegistrar{}),
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() { errCh <- s.Run(ctx) }()
+	<-s.Ready()
+	client := &http.Client{Transport: &http.Transport{DialContext: s.DialHTTPInMemory}}
+
+	// Act
+	resp, err := client.Get("http://bufconn/hello")
+
+	// Assert
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(body))
+	assert.Empty(t, s.GRPCAddr(), "no gRPC server")
+
+	cancel()
+	require.NoError(t, <-errCh)
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/legrch/netgex/internal/registry"
+)
+
+// newRegistrar creates the process registering the service with the configured registry
+// once the servers are ready
+func (s *Server) newRegistrar() (*registry.Registrar, error) {
+	cfg := s.cfg.Registry
+
+	var backend registry.Backend
+	switch cfg.Backend {
+	case "consul":
+		backend = registry.NewConsul(cfg.Address, cfg.Token)
+	case "etcd":
+		backend = registry.NewEtcd(cfg.Address, cfg.Prefix)
+	default:
+		return nil, fmt.Errorf("unsupported registry backend %q", cfg.Backend)
+	}
+
+	return registry.NewRegistrar(s.logger, backend, s.registryService,
+		registry.WithTTL(cfg.TTL),
+		registry.WithReady(s.ready),
+	), nil
+}
+
+// registryService builds the registered service from the addresses the servers are bound
+// to, so port 0 advertises the actual ports
+func (s *Server) registryService() (registry.Service, error) {
+	cfg := s.cfg.Registry
+
+	// In single-port mode gRPC listens on loopback, and is only reachable through h2c
+	// on the HTTP port
+	grpcAddress := s.GRPCAddr()
+	if s.cfg.SinglePortEnabled {
+		grpcAddress = ""
+		if s.cfg.SinglePortGRPCEnabled {
+			grpcAddress = s.HTTPAddr()
+		}
+	}
+	httpAddress := s.HTTPAddr()
+
+	service, err := registry.NewService(s.cfg.ServiceName, cfg.ServiceID, cfg.AdvertiseHost, grpcAddress, httpAddress)
+	if err != nil {
+		return registry.Service{}, err
+	}
+	service.Tags = cfg.Tags
+	service.Meta = map[string]string{
+		"version":     s.cfg.ServiceVersion,
+		"environment": s.cfg.Environment,
+	}
+	service.GRPCHealth = s.cfg.HealthCheckEnabled && grpcAddress != ""
+	if httpAddress != "" && len(s.cfg.HTTPHealthPaths) > 0 {
+		service.HTTPHealthPath = s.cfg.HTTPHealthPaths[0]
+	}
+
+	return service, nil
+}
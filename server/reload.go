@@ -0,0 +1,57 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/legrch/netgex/config"
+	"github.com/legrch/netgex/lifecycle"
+)
+
+// ConfigReloadHandler is called with the rebuilt configuration after remote values change.
+// Listeners and other subsystems created at startup keep their original settings; handlers
+// apply whatever can change at runtime (sampling rates, rate limits, feature flags).
+type ConfigReloadHandler func(ctx context.Context, cfg *config.Config)
+
+// configWatcher is a process watching the remote config source and notifying reload handlers
+type configWatcher struct {
+	logger   *slog.Logger
+	source   config.RemoteSource
+	load     func() (*config.Config, error)
+	handlers []ConfigReloadHandler
+	events   *lifecycle.Bus
+}
+
+// PreRun prepares the config watcher
+func (*configWatcher) PreRun(_ context.Context) error {
+	return nil
+}
+
+// Run watches the remote source until the context is canceled
+func (w *configWatcher) Run(ctx context.Context) error {
+	w.logger.Info("watching remote configuration")
+	err := w.source.Watch(ctx, func(map[string]string) {
+		// Rebuild with the full layering so env and options keep precedence over remote values
+		cfg, err := w.load()
+		if err != nil {
+			w.logger.Error("failed to reload configuration", "error", err)
+			return
+		}
+
+		w.logger.Info("configuration reloaded from remote source")
+		for _, handler := range w.handlers {
+			handler(ctx, cfg)
+		}
+		w.events.Publish(lifecycle.ConfigLoaded{Config: cfg, Reload: true})
+	})
+	if err != nil {
+		// The configuration was loaded at startup, so a failed watch only stops reloads
+		w.logger.Error("stopped watching remote configuration", "error", err)
+	}
+	return nil
+}
+
+// Shutdown stops the config watcher; Run returns once its context is canceled
+func (*configWatcher) Shutdown(_ context.Context) error {
+	return nil
+}
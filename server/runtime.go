@@ -0,0 +1,129 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"runtime/debug"
+	"runtime/metrics"
+	"strconv"
+	"strings"
+
+	appmetrics "github.com/legrch/netgex/internal/metrics"
+)
+
+// memoryLimitUnits maps GOMEMLIMIT suffixes to their byte multipliers
+var memoryLimitUnits = []struct {
+	suffix     string
+	multiplier int64
+}{
+	{"TiB", 1 << 40},
+	{"GiB", 1 << 30},
+	{"MiB", 1 << 20},
+	{"KiB", 1 << 10},
+	{"B", 1},
+}
+
+// applyRuntimeSettings applies the configured memory limit and GC percent, then
+// records the effective values and the service version as metrics
+func (s *Server) applyRuntimeSettings() error {
+	if s.cfg.MemoryLimit != "" {
+		limit, err := parseMemoryLimit(s.cfg.MemoryLimit)
+		if err != nil {
+			return err
+		}
+		debug.SetMemoryLimit(limit)
+	}
+
+	if s.cfg.GCPercent != "" {
+		percent, err := parseGCPercent(s.cfg.GCPercent)
+		if err != nil {
+			return err
+		}
+		debug.SetGCPercent(percent)
+	}
+
+	memoryLimit, gcPercent := effectiveRuntimeSettings()
+	if s.cfg.Telemetry.Metrics.Enabled && s.cfg.Telemetry.Metrics.Backend == "prometheus" {
+		app := appmetrics.App(s.cfg.Telemetry.Metrics.Namespace)
+		app.SetVersion(s.cfg.ServiceVersion)
+		app.SetRuntimeSettings(memoryLimit, gcPercent)
+	}
+	s.logger.Info("runtime settings applied", "memory_limit", formatMemoryLimit(memoryLimit), "gc_percent", gcPercent)
+
+	return nil
+}
+
+// parseMemoryLimit parses a memory limit using the GOMEMLIMIT syntax ("off", "512MiB", "1073741824")
+func parseMemoryLimit(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	if value == "off" {
+		return math.MaxInt64, nil
+	}
+
+	multiplier := int64(1)
+	number := value
+	for _, unit := range memoryLimitUnits {
+		if strings.HasSuffix(value, unit.suffix) {
+			multiplier = unit.multiplier
+			number = strings.TrimSuffix(value, unit.suffix)
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || n < 0 || n > math.MaxInt64/multiplier {
+		return 0, fmt.Errorf("invalid memory limit: %q", value)
+	}
+
+	return n * multiplier, nil
+}
+
+// parseGCPercent parses a GC percent using the GOGC syntax ("off", "100")
+func parseGCPercent(value string) (int, error) {
+	value = strings.TrimSpace(value)
+	if value == "off" {
+		return -1, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid GC percent: %q", value)
+	}
+
+	return n, nil
+}
+
+// effectiveRuntimeSettings reads the memory limit and GC percent currently in effect
+func effectiveRuntimeSettings() (memoryLimit int64, gcPercent int) {
+	samples := []metrics.Sample{
+		{Name: "/gc/gomemlimit:bytes"},
+		{Name: "/gc/gogc:percent"},
+	}
+	metrics.Read(samples)
+
+	//nolint:gosec // runtime values are within int64 range
+	return int64(samples[0].Value.Uint64()), int(samples[1].Value.Uint64())
+}
+
+// formatMemoryLimit renders a memory limit for display
+func formatMemoryLimit(limit int64) string {
+	if limit == math.MaxInt64 {
+		return "off"
+	}
+
+	for _, unit := range memoryLimitUnits {
+		if limit >= unit.multiplier && limit%unit.multiplier == 0 {
+			return fmt.Sprintf("%d%s", limit/unit.multiplier, unit.suffix)
+		}
+	}
+
+	return fmt.Sprintf("%dB", limit)
+}
+
+// formatGCPercent renders a GC percent for display
+func formatGCPercent(percent int) string {
+	if percent < 0 {
+		return "off"
+	}
+	return strconv.Itoa(percent)
+}
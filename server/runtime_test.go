@@ -0,0 +1,75 @@
+package server
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMemoryLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    int64
+		wantErr bool
+	}{
+		{name: "off", value: "off", want: math.MaxInt64},
+		{name: "bytes", value: "1048576", want: 1 << 20},
+		{name: "bytes suffix", value: "1024B", want: 1 << 10},
+		{name: "mebibytes", value: "512MiB", want: 512 << 20},
+		{name: "gibibytes", value: "2GiB", want: 2 << 30},
+		{name: "invalid unit", value: "512MB", wantErr: true},
+		{name: "negative", value: "-1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got, err := parseMemoryLimit(tt.value)
+
+			// Assert
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseGCPercent(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{name: "off", value: "off", want: -1},
+		{name: "percent", value: "50", want: 50},
+		{name: "invalid", value: "fast", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got, err := parseGCPercent(tt.value)
+
+			// Assert
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFormatMemoryLimit(t *testing.T) {
+	assert.Equal(t, "off", formatMemoryLimit(math.MaxInt64))
+	assert.Equal(t, "512MiB", formatMemoryLimit(512<<20))
+	assert.Equal(t, "1500B", formatMemoryLimit(1500))
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+
+	"github.com/legrch/netgex/service"
+)
+
+// Selector selects the gRPC calls scoped interceptors apply to, by their full method
+// name, e.g. "/admin.v1.AdminService/DeleteUser"
+type Selector func(fullMethod string) bool
+
+// SelectServices selects the calls of the named gRPC services, e.g. "admin.v1.AdminService"
+func SelectServices(names ...string) Selector {
+	return func(fullMethod string) bool {
+		return slices.Contains(names, methodService(fullMethod))
+	}
+}
+
+// SelectMethods selects the calls of the methods, given by their full names
+func SelectMethods(fullMethods ...string) Selector {
+	return func(fullMethod string) bool {
+		return slices.Contains(fullMethods, fullMethod)
+	}
+}
+
+// SelectRegistrars selects the calls of the gRPC services the registrars register, found
+// by registering them on a server that is never started, on the first call
+func SelectRegistrars(registrars ...service.Registrar) Selector {
+	names := sync.OnceValue(func() []string {
+		srv := grpc.NewServer()
+		defer srv.Stop()
+		for _, r := range registrars {
+			r.RegisterGRPC(srv)
+		}
+
+		var names []string
+		for name := range srv.GetServiceInfo() {
+			names = append(names, name)
+		}
+		return names
+	})
+	return func(fullMethod string) bool {
+		return slices.Contains(names(), methodService(fullMethod))
+	}
+}
+
+// Not selects the calls the selector does not select
+func (s Selector) Not() Selector {
+	return func(fullMethod string) bool {
+		return !s(fullMethod)
+	}
+}
+
+// methodService returns the service of a full method name
+func methodService(fullMethod string) string {
+	name, _, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	return name
+}
+
+// scopedUnaryInterceptor runs the interceptors, in order, for the calls the selector
+// selects and calls the handler directly for the others
+func scopedUnaryInterceptor(selector Selector, interceptors []grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !selector(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, next := interceptors[i], handler
+			handler = func(ctx context.Context, req any) (any, error) {
+				return interceptor(ctx, req, info, next)
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+// scopedStreamInterceptor runs the interceptors, in order, for the streams the selector
+// selects and calls the handler directly for the others
+func scopedStreamInterceptor(selector Selector, interceptors []grpc.StreamServerInterceptor) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !selector(info.FullMethod) {
+			return handler(srv, ss)
+		}
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, next := interceptors[i], handler
+			handler = func(srv any, ss grpc.ServerStream) error {
+				return interceptor(srv, ss, info, next)
+			}
+		}
+		return handler(srv, ss)
+	}
+}
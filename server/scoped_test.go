@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/legrch/netgex/service"
+)
+
+// healthRegistrar registers the gRPC health service
+type healthRegistrar struct {
+	service.GRPCOnly
+}
+
+func (healthRegistrar) RegisterGRPC(srv *grpc.Server) {
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+}
+
+func TestSelector(t *testing.T) {
+	tests := []struct {
+		name       string
+		selector   Selector
+		fullMethod string
+		want       bool
+	}{
+		{name: "service", selector: SelectServices("admin.v1.AdminService"), fullMethod: "/admin.v1.AdminService/DeleteUser", want: true},
+		{name: "other service", selector: SelectServices("admin.v1.AdminService"), fullMethod: "/orders.v1.Orders/Get"},
+		{name: "method", selector: SelectMethods("/orders.v1.Orders/Delete"), fullMethod: "/orders.v1.Orders/Delete", want: true},
+		{name: "other method", selector: SelectMethods("/orders.v1.Orders/Delete"), fullMethod: "/orders.v1.Orders/Get"},
+		{name: "registrar", selector: SelectRegistrars(healthRegistrar{}), fullMethod: "/grpc.health.v1.Health/Check", want: true},
+		{name: "other registrar", selector: SelectRegistrars(healthRegistrar{}), fullMethod: "/orders.v1.Orders/Get"},
+		{name: "not", selector: SelectServices("grpc.health.v1.Health").Not(), fullMethod: "/orders.v1.Orders/Get", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := tt.selector(tt.fullMethod)
+
+			// Assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestScopedUnaryInterceptor(t *testing.T) {
+	// Arrange
+	var calls []string
+	record := func(name string) grpc.UnaryServerInterceptor {
+		return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			calls = append(calls, name)
+			return handler(ctx, req)
+		}
+	}
+	interceptor := scopedUnaryInterceptor(SelectServices("admin.v1.AdminService"), []grpc.UnaryServerInterceptor{record("auth"), record("audit")})
+	handler := func(context.Context, any) (any, error) {
+		calls = append(calls, "handler")
+		return "ok", nil
+	}
+
+	// Act
+	resp, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/admin.v1.AdminService/DeleteUser"}, handler)
+	selected := calls
+	calls = nil
+	_, _ = interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/orders.v1.Orders/Get"}, handler)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+	assert.Equal(t, []string{"auth", "audit", "handler"}, selected)
+	assert.Equal(t, []string{"handler"}, calls)
+}
+
+func TestScopedStreamInterceptor(t *testing.T) {
+	// Arrange
+	var calls []string
+	record := func(name string) grpc.StreamServerInterceptor {
+		return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			calls = append(calls, name)
+			return handler(srv, ss)
+		}
+	}
+	interceptor := scopedStreamInterceptor(SelectMethods("/orders.v1.Orders/Watch"), []grpc.StreamServerInterceptor{record("auth"), record("audit")})
+	handler := func(any, grpc.ServerStream) error {
+		calls = append(calls, "handler")
+		return nil
+	}
+
+	// Act
+	err := interceptor(nil, nil, &grpc.StreamServerInfo{FullMethod: "/orders.v1.Orders/Watch"}, handler)
+	selected := calls
+	calls = nil
+	_ = interceptor(nil, nil, &grpc.StreamServerInfo{FullMethod: "/orders.v1.Orders/List"}, handler)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"auth", "audit", "handler"}, selected)
+	assert.Equal(t, []string{"handler"}, calls)
+}
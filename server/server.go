@@ -2,8 +2,17 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"log/slog"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/legrch/netgex/config"
@@ -12,11 +21,34 @@ import (
 	"github.com/legrch/netgex/splash"
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/legrch/netgex/deprecation"
+	"github.com/legrch/netgex/errreport"
+	"github.com/legrch/netgex/fieldmask"
+	"github.com/legrch/netgex/httpcache"
+	"github.com/legrch/netgex/internal/admin"
 	"github.com/legrch/netgex/internal/gateway"
+	"github.com/legrch/netgex/internal/inflight"
+	"github.com/legrch/netgex/internal/listener"
 	"github.com/legrch/netgex/internal/metrics"
 	"github.com/legrch/netgex/internal/pprof"
+	"github.com/legrch/netgex/internal/watchdog"
+	"github.com/legrch/netgex/lifecycle"
+	"github.com/legrch/netgex/mesh"
+	"github.com/legrch/netgex/middleware"
+	"github.com/legrch/netgex/migrate"
+	"github.com/legrch/netgex/rollbar"
+	"github.com/legrch/netgex/sentry"
+	"github.com/legrch/netgex/tenant"
+	"github.com/legrch/netgex/webhook"
 	"github.com/rs/cors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/test/bufconn"
 
 	grpcserver "github.com/legrch/netgex/internal/grpc"
 )
@@ -50,25 +82,98 @@ type Process interface {
 	Shutdown(ctx context.Context) error
 }
 
+// Readier is implemented by processes that report when they are ready to serve,
+// e.g. once their listener is bound
+type Readier interface {
+	Ready() <-chan struct{}
+}
+
+// Drainer is implemented by processes that can stop reporting themselves healthy
+// ahead of shutdown, so load balancers move traffic away before listeners close
+type Drainer interface {
+	Drain()
+}
+
 // Server represents the main entry point for the application
 type Server struct {
 	cfg                          *config.Config
+	baseCfg                      *config.Config
+	cfgFile                      string
+	envPrefix                    string
+	secretResolvers              map[string]config.SecretResolver
+	remoteConfig                 config.RemoteSource
+	reloadHandlers               []ConfigReloadHandler
+	cfgOverrides                 []func(*config.Config)
+	cfgErr                       error
 	processes                    []Process
 	logger                       *slog.Logger
 	services                     []service.Registrar
 	grpcServerOptions            []grpc.ServerOption
 	grpcUnaryServerInterceptors  []grpc.UnaryServerInterceptor
 	grpcStreamServerInterceptors []grpc.StreamServerInterceptor
+	grpcScopedUnaryInterceptors  []grpc.UnaryServerInterceptor
+	grpcScopedStreamInterceptors []grpc.StreamServerInterceptor
+	grpcStatsHandlers            []stats.Handler
+	listenConfigHook             func(*net.ListenConfig)
+	grpcListeners                []*grpcListener
+	gatewayServers               []*gatewayServer
 	gwServerMuxOptions           []runtime.ServeMuxOption
+	gwMetadataAnnotators         []GatewayMetadataAnnotator
+	gwResponseModifiers          []GatewayResponseModifier
 	gwCORSEnabled                bool
 	gwCORSOptions                cors.Options
+	gwNotFound                   GatewayRoutingErrorFunc
+	gwMethodNotAllowed           GatewayRoutingErrorFunc
+	gwCacheStore                 httpcache.Store
+	gwCacheOptions               []httpcache.Option
+	gwETagEnabled                bool
+	gwETagOptions                []httpcache.ETagOption
+	gwCacheControlOptions        []httpcache.ControlOption
+	deprecationEnabled           bool
+	deprecationOptions           []deprecation.Option
+	deprecation                  *deprecation.Policy
+	tenancyEnabled               bool
+	tenancyOptions               []tenant.Option
+	tenancy                      *tenant.Resolver
+	fieldMasksEnabled            bool
+	fieldMaskOptions             []fieldmask.Option
+	migrationRunner              migrate.Runner
+	migrationOptions             []migrate.Option
+	sentryDSN                    string
+	sentryOptions                []sentry.Option
+	rollbarToken                 string
+	rollbarOptions               []rollbar.Option
+	errorReporters               []errreport.ErrorReporter
+	reportedCodes                []codes.Code
+	swaggerFS                    fs.FS
+	grpcServer                   *grpcserver.Server
+	grpcSkipped                  bool
+	gateway                      *gateway.Server
+	splashWriter                 io.Writer
+	grpcMemory                   *bufconn.Listener
+	httpMemory                   *bufconn.Listener
+	ready                        chan struct{}
+	startup                      *startupTrace
+	events                       lifecycle.Bus
+	notifiers                    []webhook.Notifier
+	metricsLabels                map[string]string
+	inflight                     *inflight.Tracker
+	drainOnce                    sync.Once
+	drainingSince                atomic.Pointer[time.Time]
+	notifications                sync.WaitGroup
 	telemetryEnabled             bool
+	defaultMiddleware            bool
+	accessLogger                 *slog.Logger
+	buildInfo                    BuildInfo
+	version                      *admin.Version
 }
 
 // NewServer creates a new Server with the given options
 func NewServer(opts ...Option) *Server {
 	s := &Server{
-		cfg: config.NewConfig(),
+		cfg:     config.NewConfig(),
+		ready:   make(chan struct{}),
+		startup: newStartupTrace(),
 	}
 
 	// Apply options
@@ -76,128 +181,622 @@ func NewServer(opts ...Option) *Server {
 		opt(s)
 	}
 
+	// Layer the configuration: defaults < config file < environment < options
+	endLoad := s.startup.phase("config.load")
+	s.cfg, s.cfgErr = s.loadConfig()
+	endLoad(s.cfgErr)
+
 	return s
 }
 
+// loadConfig builds the effective configuration and applies the option overrides on top
+func (s *Server) loadConfig() (*config.Config, error) {
+	cfg := s.baseCfg
+	// loaded holds the values Load already resolved, nil with WithConfig
+	var loaded *config.Config
+	if cfg == nil {
+		loadOpts := []config.LoadOption{
+			config.WithFile(s.cfgFile),
+			config.WithEnvPrefix(s.envPrefix),
+		}
+		if s.remoteConfig != nil {
+			loadOpts = append(loadOpts, config.WithRemoteSource(s.remoteConfig))
+		}
+		for scheme, resolver := range s.secretResolvers {
+			loadOpts = append(loadOpts, config.WithSecretResolver(scheme, resolver))
+		}
+
+		var err error
+		cfg, err = config.Load(loadOpts...)
+		if err != nil {
+			return config.NewConfig(), fmt.Errorf("failed to load config: %w", err)
+		}
+		resolved := *cfg
+		loaded = &resolved
+	}
+
+	for _, override := range s.cfgOverrides {
+		override(cfg)
+	}
+
+	// Resolve the references of WithConfig, or only those options set on a loaded config
+	ctx, cancel := context.WithTimeout(context.Background(), config.SecretsTimeout)
+	defer cancel()
+	var err error
+	if loaded != nil {
+		err = cfg.ResolveChangedSecrets(ctx, loaded, s.secretResolvers)
+	} else {
+		err = cfg.ResolveSecrets(ctx, s.secretResolvers)
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("failed to resolve config secrets: %w", err)
+	}
+
+	return cfg, nil
+}
+
 // Run starts the Server and all its processes
 func (s *Server) Run(ctx context.Context) error {
+	err := s.run(ctx)
+	s.notifications.Wait()
+	s.events.Publish(lifecycle.ShutdownComplete{Err: err})
+	return err
+}
+
+// Subscribe adds a handler receiving the lifecycle events of the Server and returns the
+// function removing it. Handlers subscribed after Run started miss the earlier events.
+func (s *Server) Subscribe(handler lifecycle.Handler) (unsubscribe func()) {
+	return s.events.Subscribe(handler)
+}
+
+// run starts the Server and its processes, and shuts them down once ctx is canceled or a
+// process fails
+func (s *Server) run(ctx context.Context) error {
+	if s.cfgErr != nil {
+		return s.cfgErr
+	}
+
 	if s.logger == nil {
 		s.logger = slog.Default()
 		// Set LogLevel from config
 		slog.SetLogLoggerLevel(parseLogLevel(s.cfg.LogLevel))
 	}
+	s.events.Publish(lifecycle.ConfigLoaded{Config: s.cfg})
+
+	// Report the startup phases when not ready within STARTUP_TIMEOUT
+	deadline, stopWatch := s.watchStartup()
+	defer stopWatch()
+	s.version = admin.NewVersion(s.cfg.ServiceName, s.cfg.ServiceVersion, s.buildInfo)
 
 	s.logger.Info("starting application")
 
+	// Apply memory limit and GC tuning before anything allocates heavily
+	if err := s.applyRuntimeSettings(); err != nil {
+		return fmt.Errorf("runtime settings error: %w", err)
+	}
+
+	// Serve metrics on METRICS_PORT and label them with METRICS_LABELS
+	if err := s.applyMetricsConfig(); err != nil {
+		return err
+	}
+
+	// Serve gRPC and the gateway on in-memory listeners only
+	if s.grpcMemory != nil {
+		if err := s.applyInMemory(); err != nil {
+			return fmt.Errorf("in-memory transport error: %w", err)
+		}
+	}
+
+	// Clear the addresses of disabled servers
+	if err := s.applyDisabledServers(); err != nil {
+		return err
+	}
+
+	// Skip the servers none of the services needs
+	s.applySkippedServers()
+
+	// Move all listeners onto PORT in single-port mode
+	if s.cfg.SinglePortEnabled {
+		if err := s.applySinglePort(); err != nil {
+			return fmt.Errorf("single-port mode error: %w", err)
+		}
+	}
+
+	// Receive requests from the Lambda runtime instead of listeners
+	if s.cfg.LambdaEnabled {
+		if err := s.applyLambda(); err != nil {
+			return fmt.Errorf("lambda mode error: %w", err)
+		}
+	}
+
+	// Serve metrics and pprof on the admin HTTP address
+	if s.cfg.AdminHTTPAddress != "" {
+		if err := s.applyAdminHTTP(); err != nil {
+			return err
+		}
+	}
+
+	// Signal deprecated methods in response headers
+	if err := s.applyDeprecation(); err != nil {
+		return err
+	}
+
+	// Run the scoped interceptors after the global ones, for the calls they select
+	s.addGRPCUnaryInterceptors(s.grpcScopedUnaryInterceptors...)
+	s.addGRPCStreamInterceptors(s.grpcScopedStreamInterceptors...)
+
+	// Leave methods such as health checks out of traces, metrics and access logs
+	observed, err := middleware.MatchMethods(s.cfg.InterceptorIncludeMethods, s.cfg.InterceptorExcludeMethods)
+	if err != nil {
+		return err
+	}
+
 	// Initialize telemetry if enabled
 	var telemetryService *telemetry.Service
 	if s.telemetryEnabled {
 		telemetryService = telemetry.NewService(s.logger, s.cfg)
 		s.addProcesses(telemetryService)
-		s.addGRPCUnaryInterceptors(telemetryService.GetUnaryInterceptors()...)
-		s.addGRPCStreamInterceptors(telemetryService.GetStreamInterceptors()...)
+		for _, interceptor := range telemetryService.GetUnaryInterceptors() {
+			s.addGRPCUnaryInterceptors(middleware.FilterUnary(observed, interceptor))
+		}
+		for _, interceptor := range telemetryService.GetStreamInterceptors() {
+			s.addGRPCStreamInterceptors(middleware.FilterStream(observed, interceptor))
+		}
 	}
 
-	// Create gRPC server
-	grpcServer := grpcserver.NewServer(
-		s.logger,
-		s.cfg.CloseTimeout,
-		s.cfg.GRPCAddress,
-		grpcserver.WithServices(s.services...),
-		grpcserver.WithUnaryInterceptors(s.grpcUnaryServerInterceptors...),
-		grpcserver.WithStreamInterceptors(s.grpcStreamServerInterceptors...),
-		grpcserver.WithReflection(s.cfg.ReflectionEnabled),
-		grpcserver.WithHealthCheck(s.cfg.HealthCheckEnabled),
-		grpcserver.WithOptions(s.grpcServerOptions...),
-	)
-	s.addProcesses(grpcServer)
+	// Report failures of calls inside their span
+	if err := s.applyErrorReporting(); err != nil {
+		return err
+	}
 
-	// Create gateway server
-	gatewayOpts := []gateway.Option{
-		gateway.WithServices(s.services...),
-		gateway.WithMuxOptions(s.gwServerMuxOptions...),
-		gateway.WithCORS(&s.gwCORSOptions),
+	// Put the recommended middleware in front of the user's interceptors, its recovery
+	// sending panics to the error reporters. Without it, reporters still get panics from a
+	// recovery interceptor of their own.
+	if s.defaultMiddleware {
+		if err := s.applyAccessLog(); err != nil {
+			return err
+		}
+		s.grpcUnaryServerInterceptors = append(middleware.UnaryInterceptorsWithAccessLogFor(s.accessLogger, observed, s.logger, s.errorReporters...), s.grpcUnaryServerInterceptors...)
+		s.grpcStreamServerInterceptors = append(middleware.StreamInterceptorsWithAccessLogFor(s.accessLogger, observed, s.logger, s.errorReporters...), s.grpcStreamServerInterceptors...)
+	} else if len(s.errorReporters) > 0 {
+		s.grpcUnaryServerInterceptors = append([]grpc.UnaryServerInterceptor{middleware.RecoveryUnaryInterceptor(s.logger, s.errorReporters...)}, s.grpcUnaryServerInterceptors...)
+		s.grpcStreamServerInterceptors = append([]grpc.StreamServerInterceptor{middleware.RecoveryStreamInterceptor(s.logger, s.errorReporters...)}, s.grpcStreamServerInterceptors...)
 	}
 
-	// Add swagger if configured
-	if s.cfg.SwaggerEnabled {
-		gatewayOpts = append(gatewayOpts, gateway.WithSwagger(s.cfg.SwaggerDir, s.cfg.SwaggerBasePath))
+	// Count in-flight calls outside every other interceptor, for the drain status
+	var inflightOpts []inflight.Option
+	if s.cfg.Telemetry.Metrics.Enabled && s.cfg.Telemetry.Metrics.Backend == "prometheus" {
+		inflightOpts = append(inflightOpts, inflight.WithMetrics(s.cfg.Telemetry.Metrics.Namespace))
+	}
+	s.inflight = inflight.New(inflightOpts...)
+	s.grpcUnaryServerInterceptors = append([]grpc.UnaryServerInterceptor{s.inflight.UnaryServerInterceptor()}, s.grpcUnaryServerInterceptors...)
+	s.grpcStreamServerInterceptors = append([]grpc.StreamServerInterceptor{s.inflight.StreamServerInterceptor()}, s.grpcStreamServerInterceptors...)
+
+	// Migrate once the user's processes are connected and before the servers listen
+	if s.migrationRunner != nil {
+		opts := append([]migrate.Option{migrate.WithLogger(s.logger)}, s.migrationOptions...)
+		s.addProcesses(migrate.NewProcess(s.migrationRunner, opts...))
 	}
 
-	gatewayServer := gateway.NewServer(
-		s.logger,
-		s.cfg.CloseTimeout,
-		s.cfg.GRPCAddress,
-		s.cfg.HTTPAddress,
-		gatewayOpts...,
-	)
-	s.addProcesses(gatewayServer)
+	// Resolve the tenant of calls inside their span
+	s.applyTenancy()
 
-	// Initialize metrics server
-	metricsServer := metrics.NewServer(s.logger, s.cfg.MetricsAddress, s.cfg.CloseTimeout)
-	s.addProcesses(metricsServer)
+	// Validate field masks next to the handlers, pruning responses to their read mask
+	if s.fieldMasksEnabled {
+		s.addGRPCUnaryInterceptors(fieldmask.UnaryServerInterceptor(s.fieldMaskOptions...))
+	}
+
+	// Capture mesh tracing and routing headers so outbound calls can propagate them
+	if s.cfg.MeshHeadersEnabled {
+		s.addGRPCUnaryInterceptors(mesh.UnaryServerInterceptor())
+		s.addGRPCStreamInterceptors(mesh.StreamServerInterceptor())
+	}
+
+	// Create gRPC server
+	s.logRegistrars()
+	var grpcServer *grpcserver.Server
+	if !s.grpcSkipped {
+		grpcOpts := []grpcserver.Option{
+			grpcserver.WithServices(s.grpcServices()...),
+			grpcserver.WithUnaryInterceptors(s.grpcUnaryServerInterceptors...),
+			grpcserver.WithStreamInterceptors(s.grpcStreamServerInterceptors...),
+			grpcserver.WithOptions(s.grpcServerOptions...),
+			grpcserver.WithStatsHandlers(s.inflight.StatsHandler()),
+		}
+		if s.grpcMemory != nil {
+			grpcOpts = append(grpcOpts, grpcserver.WithListener(s.grpcMemory))
+		}
+		grpcServer = s.newGRPCServer(s.logger, s.cfg.GRPCAddress, grpcOpts...)
+		s.addProcesses(grpcServer)
+		s.grpcServer = grpcServer
+	}
+
+	// Create additional gRPC listeners
+	for _, l := range s.grpcListeners {
+		s.addProcesses(s.newListenerServer(l))
+	}
+
+	// Create the internal admin gRPC server
+	if s.cfg.AdminEnabled {
+		s.addProcesses(s.newAdminServer(grpcServer))
+	}
 
 	// Initialize pprof server
+	var pprofServer *pprof.Server
 	if s.cfg.PprofEnabled {
-		pprofServer := pprof.NewServer(s.logger, s.cfg.PprofAddress)
+		pprofServer = pprof.NewServer(
+			s.logger,
+			s.cfg.PprofAddress,
+			pprof.WithAuthToken(s.cfg.PprofAuthToken),
+			pprof.WithBasicAuth(s.cfg.PprofBasicAuthUser, s.cfg.PprofBasicAuthPassword),
+			pprof.WithAllowedCIDRs(s.cfg.PprofAllowedCIDRs...),
+			pprof.WithFgprof(s.cfg.PprofFgprofEnabled),
+			pprof.WithBlockProfileRate(s.cfg.PprofBlockRate),
+			pprof.WithMutexProfileFraction(s.cfg.PprofMutexFraction),
+			pprof.WithHeapDump(s.cfg.PprofHeapDumpDir, s.cfg.PprofHeapDumpMaxBytes),
+			pprof.WithListenOptions(s.listenOptions()),
+		)
+	}
+
+	// Create gateway servers
+	if s.cfg.HTTPEnabled {
+		if err := s.addGateways(grpcServer, pprofServer); err != nil {
+			return err
+		}
+	}
+
+	// Initialize metrics server
+	if s.cfg.MetricsServerEnabled {
+		s.addProcesses(metrics.NewServer(s.logger, s.cfg.MetricsAddress, s.cfg.CloseTimeout,
+			metrics.WithListenOptions(s.listenOptions()),
+			metrics.WithPath(s.cfg.Telemetry.Metrics.Path),
+			metrics.WithLabels(s.metricsLabels),
+		))
+	}
+
+	if pprofServer != nil {
 		s.addProcesses(pprofServer)
 	}
 
+	if s.cfg.AdminHTTPAddress != "" {
+		s.addProcesses(s.newAdminHTTPServer(grpcServer, pprofServer))
+	}
+
+	// Watch remote configuration for changes; a base config from WithConfig is never reloaded
+	if s.remoteConfig != nil && s.baseCfg == nil && (len(s.reloadHandlers) > 0 || s.events.Subscribers() > 0) {
+		s.addProcesses(&configWatcher{
+			logger:   s.logger,
+			source:   s.remoteConfig,
+			load:     s.loadConfig,
+			handlers: s.reloadHandlers,
+			events:   &s.events,
+		})
+	}
+
+	// Initialize watchdog
+	if s.cfg.Watchdog.Enabled {
+		s.addProcesses(watchdog.NewWatchdog(
+			s.logger,
+			watchdog.WithInterval(s.cfg.Watchdog.Interval),
+			watchdog.WithGoroutineThreshold(s.cfg.Watchdog.GoroutineThreshold),
+			watchdog.WithLatencyThreshold(s.cfg.Watchdog.LatencyThreshold),
+			watchdog.WithStackDump(s.cfg.Watchdog.DumpStacks),
+		))
+	}
+
+	// Register with the service registry once ready; it deregisters when draining starts
+	if s.cfg.Registry.Enabled {
+		registrar, err := s.newRegistrar()
+		if err != nil {
+			return fmt.Errorf("service registry: %w", err)
+		}
+		s.addProcesses(registrar)
+	}
+
 	// Run PreRun for all processes
 	for _, p := range s.processes {
-		if err := p.PreRun(ctx); err != nil {
+		endPreRun := s.startup.phase(preRunPhase(p), attribute.String("process.name", processName(p)))
+		err := s.preRun(ctx, p, deadline)
+		endPreRun(err)
+		if err != nil {
+			s.notify(webhook.PreRunFailed, processName(p), err)
 			return fmt.Errorf("pre-run error: %w", err)
 		}
 	}
 
-	// Create error channel
-	errCh := make(chan error, len(s.processes))
+	// Processes keep running after ctx is canceled until shutdown actually begins,
+	// so they keep serving through the shutdown and drain delays
+	runCtx, stopRun := context.WithCancel(context.WithoutCancel(ctx))
+	defer stopRun()
 
-	// Start all processes
+	// Start all processes; the first one failing cancels the context of the others, and
+	// its error is the cause of the group context
+	group, groupCtx := errgroup.WithContext(runCtx)
 	for i, p := range s.processes {
 		process := p
 		index := i
 
-		go func() {
-			s.logger.Info("starting process", "index", index)
-			if err := process.Run(ctx); err != nil {
-				errCh <- fmt.Errorf("process %d error: %w", index, err)
-			}
-		}()
+		s.events.Publish(lifecycle.ProcessStarted{Index: index, Name: processName(process)})
+		endRun := s.startup.phase("process.run", attribute.String("process.name", processName(process)))
+		group.Go(func() error {
+			return s.superviseProcess(groupCtx, index, process)
+		})
+		if r, ok := process.(Readier); ok {
+			s.startup.watch(r, endRun)
+		} else {
+			endRun(nil)
+		}
 	}
 
-	// Give processes a moment to start
+	// Give processes a moment to start, and wait for listeners to be bound so the
+	// splash screen shows their actual addresses
+	endReadiness := s.startup.phase("readiness")
 	time.Sleep(StartupDelay)
+	err = s.waitReady(ctx, groupCtx, deadline)
+	stopWatch()
+	endReadiness(err)
+	s.startup.emit(otel.Tracer("server"), err)
+	if err != nil {
+		s.logger.Error("process error", "error", err)
+	} else {
+		close(s.ready)
+		s.events.Publish(lifecycle.Ready{})
 
-	// Display splash screen after processes have started
-	s.displaySplash()
+		// Display splash screen after processes have started
+		if s.cfg.SplashEnabled {
+			s.displaySplash()
+		}
 
-	// Wait for context cancellation or error
-	var err error
-	select {
-	case <-ctx.Done():
-		s.logger.Info("context canceled, shutting down")
-	case err = <-errCh:
-		s.logger.Error("process error", "error", err)
+		// Wait for context cancellation or error
+		select {
+		case <-ctx.Done():
+			s.logger.Info("context canceled, shutting down")
+			err = s.delayShutdown(groupCtx)
+			if err == nil {
+				// Report NOT_SERVING and give load balancers time to stop sending traffic;
+				// a failed process has nothing left to drain, so this only runs here
+				s.Drain()
+				err = s.waitDrainDelay(groupCtx)
+			}
+		case <-groupCtx.Done():
+			err = context.Cause(groupCtx)
+			s.logger.Error("process error", "error", err)
+		}
 	}
+	stopRun()
 
 	// Create shutdown context
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.cfg.CloseTimeout)
 	defer cancel()
 
 	// Shutdown all processes in reverse order
+	var shutdownErrs []error
 	for i := len(s.processes) - 1; i >= 0; i-- {
 		p := s.processes[i]
-		if shutdownErr := p.Shutdown(shutdownCtx); shutdownErr != nil {
+		shutdownErr := p.Shutdown(shutdownCtx)
+		if shutdownErr != nil {
 			s.logger.Error("shutdown error", "error", shutdownErr)
-			if err == nil {
-				err = shutdownErr
-			}
+			s.reportProcessError(shutdownCtx, p, shutdownErr)
+			shutdownErrs = append(shutdownErrs, fmt.Errorf("%s: %w", processName(p), shutdownErr))
 		}
+		s.events.Publish(lifecycle.ProcessStopped{Index: i, Name: processName(p), Err: shutdownErr})
 	}
 
+	// Wait for the processes to return from Run, as long as the shutdown timeout allows
+	runErr := s.waitProcesses(shutdownCtx, group)
+	if shutdownCtx.Err() != nil {
+		timeoutErr := fmt.Errorf("processes did not shut down within %s", s.cfg.CloseTimeout)
+		s.notify(webhook.ShutdownTimeout, "", errors.Join(append([]error{timeoutErr}, shutdownErrs...)...))
+	}
+
+	// Release the resources of the services once nothing calls them anymore
+	closeErr := s.closeRegistrars(shutdownCtx)
+
 	s.logger.Info("application stopped")
-	return err
+
+	// A process failing during shutdown is the first error of the group, unless one
+	// failed before
+	errs := append([]error{err}, shutdownErrs...)
+	if runErr != nil && !errors.Is(err, runErr) {
+		errs = append(errs, runErr)
+	}
+	return errors.Join(append(errs, closeErr)...)
+}
+
+// waitProcesses waits for the Run of every process to return, and returns the error of
+// the first failing one. Processes still running when ctx is done are left behind.
+func (s *Server) waitProcesses(ctx context.Context, group *errgroup.Group) error {
+	done := make(chan error, 1)
+	go func() { done <- group.Wait() }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		s.logger.Error("processes still running after shutdown")
+		return nil
+	}
+}
+
+// listenOptions returns the socket options of every listener
+func (s *Server) listenOptions() listener.Options {
+	return listener.Options{
+		ReusePort: s.cfg.ReusePortEnabled,
+		KeepAlive: s.cfg.TCPKeepAlive,
+		Delay:     !s.cfg.TCPNoDelay,
+		Backlog:   s.cfg.ListenBacklog,
+		Hook:      s.listenConfigHook,
+	}
+}
+
+// newGRPCServer creates a gRPC server with the configured server-wide settings
+func (s *Server) newGRPCServer(logger *slog.Logger, address string, opts ...grpcserver.Option) *grpcserver.Server {
+	opts = append([]grpcserver.Option{
+		grpcserver.WithReflection(s.cfg.ReflectionEnabled),
+		grpcserver.WithReflectionVersions(s.cfg.ReflectionVersions),
+		grpcserver.WithHealthCheck(s.cfg.HealthCheckEnabled),
+		grpcserver.WithHealthCheckInterval(s.cfg.HealthCheckInterval),
+		grpcserver.WithListenOptions(s.listenOptions()),
+		grpcserver.WithMaxMsgSizes(s.cfg.GRPCMaxRecvMsgSize, s.cfg.GRPCMaxSendMsgSize),
+		grpcserver.WithStatsHandlers(s.grpcStatsHandlers...),
+		grpcserver.WithKeepalive(
+			keepalive.ServerParameters{
+				MaxConnectionIdle:     s.cfg.GRPCKeepalive.MaxConnectionIdle,
+				MaxConnectionAge:      s.cfg.GRPCKeepalive.MaxConnectionAge,
+				MaxConnectionAgeGrace: s.cfg.GRPCKeepalive.MaxConnectionAgeGrace,
+				Time:                  s.cfg.GRPCKeepalive.Time,
+				Timeout:               s.cfg.GRPCKeepalive.Timeout,
+			},
+			keepalive.EnforcementPolicy{
+				MinTime:             s.cfg.GRPCKeepalive.MinTime,
+				PermitWithoutStream: s.cfg.GRPCKeepalive.PermitWithoutStream,
+			},
+		),
+	}, opts...)
+
+	return grpcserver.NewServer(logger, s.cfg.CloseTimeout, address, opts...)
+}
+
+// applyDisabledServers clears the addresses of disabled servers, so they are neither
+// started nor advertised
+func (s *Server) applyDisabledServers() error {
+	if !s.cfg.HTTPEnabled {
+		if s.cfg.SinglePortEnabled || s.cfg.LambdaEnabled {
+			return errors.New("single-port and lambda mode require the HTTP gateway")
+		}
+		s.cfg.HTTPAddress = ""
+	}
+	if !s.cfg.MetricsServerEnabled {
+		s.cfg.MetricsAddress = ""
+	}
+	if !s.cfg.PprofEnabled {
+		s.cfg.PprofAddress = ""
+	}
+	return nil
+}
+
+// delayShutdown keeps serving for the shutdown delay, covering the time it takes for the
+// instance to be removed from service endpoints. A process failing, canceling failed
+// with its error, ends the delay early.
+func (s *Server) delayShutdown(failed context.Context) error {
+	if s.cfg.ShutdownDelay <= 0 {
+		return nil
+	}
+
+	s.logger.Info("delaying shutdown", "delay", s.cfg.ShutdownDelay)
+	select {
+	case <-time.After(s.cfg.ShutdownDelay):
+		return nil
+	case <-failed.Done():
+		err := context.Cause(failed)
+		s.logger.Error("process error", "error", err)
+		return err
+	}
+}
+
+// waitReady waits until all processes implementing Readier are ready. It returns the
+// error of a process failing first, the cause of failed, ErrStartupTimeout once deadline
+// is closed, and nil early when ctx is canceled.
+func (s *Server) waitReady(ctx, failed context.Context, deadline <-chan struct{}) error {
+	for _, p := range s.processes {
+		r, ok := p.(Readier)
+		if !ok {
+			continue
+		}
+		select {
+		case <-r.Ready():
+		case <-failed.Done():
+			return context.Cause(failed)
+		case <-deadline:
+			return s.startupTimeoutError()
+		case <-ctx.Done():
+			return nil
+		}
+	}
+	return nil
+}
+
+// applyMetricsConfig serves metrics on METRICS_PORT unless METRICS_ADDRESS is set, and
+// parses the constant labels of the served metrics
+func (s *Server) applyMetricsConfig() error {
+	if port := s.cfg.Telemetry.Metrics.Port; port != 0 && s.cfg.MetricsAddress == config.NewConfig().MetricsAddress {
+		s.cfg.MetricsAddress = ":" + strconv.Itoa(port)
+	}
+
+	labels, err := metrics.ParseLabels(s.cfg.Telemetry.Metrics.Labels)
+	if err != nil {
+		return err
+	}
+	s.metricsLabels = labels
+	return nil
+}
+
+// waitDrainDelay waits for the drain delay, returning the cause of failed early when a
+// process fails meanwhile
+func (s *Server) waitDrainDelay(failed context.Context) error {
+	if s.cfg.DrainDelay <= 0 {
+		return nil
+	}
+
+	s.logger.Info("draining connections", "delay", s.cfg.DrainDelay)
+	select {
+	case <-time.After(s.cfg.DrainDelay):
+		return nil
+	case <-failed.Done():
+		err := context.Cause(failed)
+		s.logger.Error("process error", "error", err)
+		return err
+	}
+}
+
+// Ready returns a channel that is closed once all processes are started and the
+// listeners are bound
+func (s *Server) Ready() <-chan struct{} {
+	return s.ready
+}
+
+// GRPCAddr returns the address the gRPC server is bound to, which differs from the
+// configured address when it uses port 0. It is empty until the server is ready.
+func (s *Server) GRPCAddr() string {
+	if s.grpcServer == nil {
+		return ""
+	}
+	return s.grpcServer.Addr()
+}
+
+// HTTPAddr returns the address the gateway is bound to, which differs from the
+// configured address when it uses port 0. It is empty until the server is ready, and
+// when the gateway is disabled.
+func (s *Server) HTTPAddr() string {
+	if s.gateway == nil {
+		return ""
+	}
+	return s.gateway.Addr()
+}
+
+// Services returns the services registered on the public gRPC server and their methods.
+// It is empty until the server has been started with Run.
+func (s *Server) Services() map[string]grpc.ServiceInfo {
+	if s.grpcServer == nil {
+		return nil
+	}
+	return s.grpcServer.GetServiceInfo()
+}
+
+// serviceNames returns the sorted names of the application services registered on the
+// gRPC server, leaving out the built-in health and reflection services
+func (s *Server) serviceNames() []string {
+	if s.grpcServer == nil {
+		return nil
+	}
+
+	var names []string
+	for name := range s.grpcServer.GetServiceInfo() {
+		if !strings.HasPrefix(name, "grpc.") {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
 }
 
 func (s *Server) addProcesses(processes ...Process) {
@@ -214,26 +813,81 @@ func (s *Server) addGRPCStreamInterceptors(interceptors ...grpc.StreamServerInte
 
 // displaySplash initializes and displays the splash screen
 func (s *Server) displaySplash() {
+	// Show the addresses the servers are bound to, which differ from the configured
+	// addresses for port 0
+	grpcAddress, httpAddress := s.cfg.GRPCAddress, s.cfg.HTTPAddress
+	if s.grpcServer != nil {
+		grpcAddress = s.grpcServer.Addr()
+	}
+	if s.gateway != nil {
+		httpAddress = s.gateway.Addr()
+	}
+
 	splashOpts := []splash.SplashOption{
-		splash.WithGRPCAddress(s.cfg.GRPCAddress),
-		splash.WithHTTPAddress(s.cfg.HTTPAddress),
+		splash.WithGRPCAddress(grpcAddress),
+		splash.WithHTTPAddress(httpAddress),
 		splash.WithMetricsAddress(s.cfg.MetricsAddress),
-		splash.WithPprofAddress(s.cfg.PprofAddress),
+		splash.WithServices(s.serviceNames()...),
+	}
+	if s.gateway != nil {
+		splashOpts = append(splashOpts, splash.WithRouteCount(len(s.gateway.Routes())))
+	}
+
+	if s.cfg.PprofEnabled {
+		splashOpts = append(splashOpts, splash.WithPprofAddress(s.cfg.PprofAddress))
+	}
+
+	// Everything public is served on the HTTP port in single-port mode
+	if s.cfg.SinglePortEnabled {
+		splashOpts = append(splashOpts, s.singlePortSplashOptions()...)
+	}
+
+	// Metrics and pprof share the admin HTTP address
+	if s.cfg.AdminHTTPAddress != "" {
+		if s.cfg.MetricsServerEnabled {
+			splashOpts = append(splashOpts, splash.WithMetricsAddress(s.cfg.AdminHTTPAddress+s.cfg.Telemetry.Metrics.Path))
+		}
+		if s.cfg.PprofEnabled {
+			splashOpts = append(splashOpts, splash.WithPprofAddress(s.cfg.AdminHTTPAddress+"/debug/pprof/"))
+		}
 	}
 
+	// Add effective runtime settings
+	memoryLimit, gcPercent := effectiveRuntimeSettings()
+	splashOpts = append(splashOpts, splash.WithRuntimeSettings(formatMemoryLimit(memoryLimit), formatGCPercent(gcPercent)))
+
 	// Add features
-	if s.cfg.ReflectionEnabled {
-		splashOpts = append(splashOpts, splash.WithFeature("gRPC Reflection"))
+	if s.cfg.ReflectionEnabled && !s.grpcSkipped {
+		feature := "gRPC Reflection"
+		if s.cfg.ReflectionVersions != "" && s.cfg.ReflectionVersions != grpcserver.ReflectionBoth {
+			feature += " (" + s.cfg.ReflectionVersions + ")"
+		}
+		splashOpts = append(splashOpts, splash.WithFeature(feature))
 	}
-	if s.cfg.HealthCheckEnabled {
+	if s.cfg.HealthCheckEnabled && !s.grpcSkipped {
 		splashOpts = append(splashOpts, splash.WithFeature("Health Checks"))
 	}
 	if s.gwCORSEnabled {
 		splashOpts = append(splashOpts, splash.WithFeature("CORS"))
 	}
+	if s.cfg.Watchdog.Enabled {
+		splashOpts = append(splashOpts, splash.WithFeature("Watchdog"))
+	}
+	for _, l := range s.grpcListeners {
+		splashOpts = append(splashOpts, splash.WithFeature(listenerFeature(l)))
+	}
+	if s.cfg.AdminEnabled {
+		splashOpts = append(splashOpts, splash.WithFeature("gRPC admin: "+s.cfg.AdminAddress))
+	}
+	for _, g := range s.gatewayServers {
+		splashOpts = append(splashOpts, splash.WithFeature(gatewayFeature(g)))
+	}
+	if s.cfg.Registry.Enabled {
+		splashOpts = append(splashOpts, splash.WithFeature("Service Registry ("+s.cfg.Registry.Backend+")"))
+	}
 
 	// Add swagger if enabled
-	if s.cfg.SwaggerEnabled {
+	if s.swaggerEnabled() {
 		splashOpts = append(splashOpts, splash.WithSwaggerBasePath(s.cfg.SwaggerBasePath))
 	}
 
@@ -280,7 +934,19 @@ func (s *Server) displaySplash() {
 		}
 	}
 
+	if s.splashWriter != nil {
+		splashOpts = append(splashOpts, splash.WithWriter(s.splashWriter))
+	}
+
 	// Create and display splash
-	splash := splash.NewSplash(splashOpts...)
-	splash.Display()
+	sp := splash.NewSplash(splashOpts...)
+	switch s.cfg.SplashFormat {
+	case splash.FormatLog:
+		sp.Log(context.Background(), s.logger)
+	case splash.FormatBanner, "":
+		sp.Display()
+	default:
+		s.logger.Warn("unknown splash format, displaying the banner", "format", s.cfg.SplashFormat)
+		sp.Display()
+	}
 }
@@ -1,19 +1,32 @@
 package server
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"log/slog"
+	"net"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/legrch/netgex/config"
-	"github.com/prometheus/client_golang/prometheus"
+	"github.com/legrch/netgex/errreport"
+	"github.com/legrch/netgex/internal/inflight"
+	"github.com/legrch/netgex/lifecycle"
+	"github.com/legrch/netgex/migrate"
+	"github.com/legrch/netgex/webhook"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
 )
 
 // mockProcessWithExpectations is a mock implementation of the Process interface
@@ -224,12 +237,6 @@ func TestServer_Run_Success(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// Unregister metrics to avoid duplication
-	prometheus.Unregister(prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Namespace: "app",
-		Name:      "version",
-	}, []string{"version"}))
-
 	process1 := new(mockProcessWithExpectations)
 	process1.On("PreRun", mock.Anything).Return(nil)
 	process1.On("Run", mock.Anything).Return(nil)
@@ -276,8 +283,24 @@ func TestServer_Run_ShutdownError(t *testing.T) {
 	testShutdownError(t)
 }
 
+func TestServer_Services(t *testing.T) {
+	// Arrange
+	s := NewServer(WithLogger(slog.Default()), WithReflection(false), WithHealthCheck(true))
+	assert.Nil(t, s.Services(), "no services before Run")
+	s.grpcServer = s.newGRPCServer(s.logger, s.cfg.GRPCAddress)
+	require.NoError(t, s.grpcServer.PreRun(context.Background()))
+
+	// Act
+	services := s.Services()
+
+	// Assert
+	require.Contains(t, services, "grpc.health.v1.Health")
+	assert.NotEmpty(t, services["grpc.health.v1.Health"].Methods)
+}
+
 func TestServer_DisplaySplash(t *testing.T) {
 	// Arrange
+	var buf bytes.Buffer
 	s := NewServer(
 		WithGRPCAddress(":50051"),
 		WithHTTPAddress(":8081"),
@@ -287,10 +310,770 @@ func TestServer_DisplaySplash(t *testing.T) {
 		WithHealthCheck(true),
 		WithSwaggerDir("./api"),
 		WithSwaggerBasePath("/api/v1"),
+		WithSplashWriter(&buf),
+	)
+
+	// Act
+	s.displaySplash()
+
+	// Assert
+	assert.Contains(t, buf.String(), "gRPC API: :50051")
+	assert.Contains(t, buf.String(), "HTTP API: :8081")
+}
+
+func TestServer_DisplaySplash_LogFormat(t *testing.T) {
+	// Arrange
+	var logs, banner bytes.Buffer
+	s := NewServer(
+		WithLogger(slog.New(slog.NewJSONHandler(&logs, nil))),
+		WithGRPCAddress(":50051"),
+		WithSplashFormat("log"),
+		WithSplashWriter(&banner),
+	)
+
+	// Act
+	s.displaySplash()
+
+	// Assert
+	assert.Empty(t, banner.String())
+	assert.Contains(t, logs.String(), `"msg":"startup summary"`)
+	assert.Contains(t, logs.String(), `"grpc":":50051"`)
+}
+
+func TestServer_Run_Splash(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []Option
+		wantSplash bool
+	}{
+		{name: "enabled", wantSplash: true},
+		{name: "disabled", opts: []Option{WithSplashDisabled()}, wantSplash: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var buf bytes.Buffer
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+			s := NewServer(append([]Option{
+				WithLogger(slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))),
+				WithConfig(&config.Config{SplashEnabled: true, GRPCAddress: "127.0.0.1:0", CloseTimeout: time.Second}),
+				WithSplashWriter(&buf),
+			}, tt.opts...)...)
+
+			// Act
+			require.NoError(t, s.Run(ctx))
+
+			// Assert - the splash shows the port the gRPC server is bound to
+			assert.NotEqual(t, "127.0.0.1:0", s.grpcServer.Addr())
+			assert.Equal(t, tt.wantSplash, strings.Contains(buf.String(), "gRPC API: "+s.grpcServer.Addr()))
+		})
+	}
+}
+
+func TestServer_WaitReady(t *testing.T) {
+	t.Run("ready", func(t *testing.T) {
+		// Arrange
+		ready := make(chan struct{})
+		close(ready)
+		s := NewServer(WithProcesses(&readyProcess{ready: ready}))
+
+		// Act & Assert
+		assert.NoError(t, s.waitReady(context.Background(), context.Background(), nil))
+	})
+
+	t.Run("process error", func(t *testing.T) {
+		// Arrange
+		s := NewServer(WithProcesses(&readyProcess{ready: make(chan struct{})}))
+		failed, fail := context.WithCancelCause(context.Background())
+		fail(errors.New("listen failed"))
+
+		// Act & Assert
+		assert.EqualError(t, s.waitReady(context.Background(), failed, nil), "listen failed")
+	})
+}
+
+// readyProcess is a process reporting readiness on its channel
+type readyProcess struct {
+	mockProcess
+	ready chan struct{}
+}
+
+func (p *readyProcess) Ready() <-chan struct{} {
+	return p.ready
+}
+
+func TestNewServer_ConfigPrecedence(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("GRPC_ADDRESS: \":7000\"\nHTTP_ADDRESS: \":7001\"\nMETRICS_ADDRESS: \":7002\"\n"), 0o600))
+	t.Setenv("HTTP_ADDRESS", ":7101")
+	t.Setenv("METRICS_ADDRESS", ":7102")
+
+	// Act
+	s := NewServer(
+		WithMetricsAddress(":7202"),
+		WithConfigFile(path),
+	)
+
+	// Assert
+	require.NoError(t, s.cfgErr)
+	assert.Equal(t, ":7000", s.cfg.GRPCAddress, "file overrides defaults")
+	assert.Equal(t, ":7101", s.cfg.HTTPAddress, "environment overrides file")
+	assert.Equal(t, ":7202", s.cfg.MetricsAddress, "options override environment")
+	assert.Equal(t, ":6060", s.cfg.PprofAddress, "defaults are kept")
+}
+
+func TestNewServer_WithConfigKeepsOptions(t *testing.T) {
+	// Arrange
+	cfg := config.NewConfig()
+
+	// Act - shortcut options given before WithConfig still apply
+	s := NewServer(
+		WithGRPCAddress(":50051"),
+		WithConfig(cfg),
+	)
+
+	// Assert
+	assert.Same(t, cfg, s.cfg)
+	assert.Equal(t, ":50051", s.cfg.GRPCAddress)
+}
+
+func TestNewServer_ResolvesSecretsOnce(t *testing.T) {
+	// Arrange
+	t.Setenv("PPROF_AUTH_TOKEN", "awssm://pprof-token")
+	var resolved []string
+	resolver := config.SecretResolverFunc(func(_ context.Context, ref *url.URL) (string, error) {
+		resolved = append(resolved, ref.Host)
+		// A secret looking like a reference is kept verbatim
+		return "awssm://secret-" + ref.Host, nil
+	})
+
+	// Act
+	s := NewServer(
+		WithSecretResolver("awssm", resolver),
+		WithPprofAddress("awssm://pprof-address"),
+	)
+
+	// Assert
+	require.NoError(t, s.cfgErr)
+	assert.Equal(t, []string{"pprof-token", "pprof-address"}, resolved, "loaded values are not resolved again")
+	assert.Equal(t, "awssm://secret-pprof-token", s.cfg.PprofAuthToken)
+	assert.Equal(t, "awssm://secret-pprof-address", s.cfg.PprofAddress)
+}
+
+func TestServer_Run_ConfigError(t *testing.T) {
+	// Arrange
+	s := NewServer(WithConfigFile(filepath.Join(t.TempDir(), "missing.yaml")))
+
+	// Act
+	err := s.Run(context.Background())
+
+	// Assert
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to load config")
+}
+
+func TestServer_Run_InvalidMetricsLabels(t *testing.T) {
+	// Arrange
+	cfg := config.NewConfig()
+	cfg.Telemetry.Metrics.Labels = []string{"region"}
+	s := NewServer(WithLogger(slog.New(slog.DiscardHandler)), WithConfig(cfg))
+
+	// Act
+	err := s.Run(context.Background())
+
+	// Assert
+	assert.ErrorContains(t, err, `invalid METRICS_LABELS entry "region"`)
+}
+
+func TestServer_ApplyMetricsConfig(t *testing.T) {
+	tests := []struct {
+		name           string
+		metricsAddress string
+		port           int
+		want           string
+	}{
+		{name: "port of the default address", metricsAddress: ":9091", port: 9200, want: ":9200"},
+		{name: "explicit address wins", metricsAddress: "127.0.0.1:9300", port: 9200, want: "127.0.0.1:9300"},
+		{name: "no port", metricsAddress: ":9091", port: 0, want: ":9091"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			cfg := config.NewConfig()
+			cfg.MetricsAddress = tt.metricsAddress
+			cfg.Telemetry.Metrics.Port = tt.port
+			s := NewServer(WithConfig(cfg))
+
+			// Act
+			err := s.applyMetricsConfig()
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, s.cfg.MetricsAddress)
+		})
+	}
+}
+
+func TestServer_Run_InvalidRegistryBackend(t *testing.T) {
+	// Arrange
+	cfg := config.NewConfig()
+	cfg.Registry.Enabled = true
+	cfg.Registry.Backend = "zookeeper"
+	s := NewServer(WithConfig(cfg))
+
+	// Act
+	err := s.Run(context.Background())
+
+	// Assert
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unsupported registry backend "zookeeper"`)
+}
+
+func TestServer_RegistryService(t *testing.T) {
+	// Arrange - servers on port 0 advertise the ports they are bound to
+	ctx, cancel := context.WithCancel(context.Background())
+	s := NewServer(
+		WithLogger(slog.New(slog.DiscardHandler)),
+		WithSplashDisabled(),
+		WithGRPCAddress("127.0.0.1:0"),
+		WithHTTPAddress("127.0.0.1:0"),
+		WithServices(healthProxyRegistrar{}),
+	)
+	s.cfg.ServiceName = "orders"
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+	defer func() {
+		cancel()
+		assert.NoError(t, <-done)
+	}()
+	select {
+	case <-s.Ready():
+	case err := <-done:
+		t.Fatalf("server stopped: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server not ready")
+	}
+	_, grpcPort, err := net.SplitHostPort(s.GRPCAddr())
+	require.NoError(t, err)
+	_, httpPort, err := net.SplitHostPort(s.HTTPAddr())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		singlePort     bool
+		singlePortGRPC bool
+		wantGRPCPort   string
+		wantGRPCHealth bool
+	}{
+		{name: "separate ports", wantGRPCPort: grpcPort, wantGRPCHealth: true},
+		{name: "single port with gRPC over h2c", singlePort: true, singlePortGRPC: true, wantGRPCPort: httpPort, wantGRPCHealth: true},
+		{name: "single port without gRPC", singlePort: true, wantGRPCPort: "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.cfg.SinglePortEnabled = tt.singlePort
+			s.cfg.SinglePortGRPCEnabled = tt.singlePortGRPC
+
+			// Act
+			service, err := s.registryService()
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, "127.0.0.1", service.Host)
+			assert.Equal(t, tt.wantGRPCPort, strconv.Itoa(service.GRPCPort))
+			assert.Equal(t, httpPort, strconv.Itoa(service.HTTPPort))
+			assert.Equal(t, tt.wantGRPCHealth, service.GRPCHealth)
+		})
+	}
+}
+
+// preRunProcess records that its PreRun ran
+type preRunProcess struct {
+	mockProcess
+	preRun bool
+}
+
+func (p *preRunProcess) PreRun(context.Context) error {
+	p.preRun = true
+	return nil
+}
+
+func TestServer_Run_MigrationError(t *testing.T) {
+	// Arrange
+	pool := &preRunProcess{}
+	var poolReady bool
+	s := NewServer(
+		WithLogger(slog.Default()),
+		WithMigrations(migrate.RunnerFunc(func(context.Context) error {
+			poolReady = pool.preRun
+			return errors.New("dirty database version 3")
+		})),
+		WithProcesses(pool),
+	)
+
+	// Act
+	err := s.Run(context.Background())
+
+	// Assert
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "migrations failed: dirty database version 3")
+	assert.True(t, poolReady, "migrations run after the PreRun of the user's processes")
+}
+
+func TestServer_Run_InvalidSentryDSN(t *testing.T) {
+	// Arrange
+	s := NewServer(WithLogger(slog.Default()), WithSentry("https://o1.ingest.sentry.io/42"))
+
+	// Act
+	err := s.Run(context.Background())
+
+	// Assert
+	assert.ErrorContains(t, err, "sentry: invalid sentry DSN")
+}
+
+// recordingReporter records the reports it receives
+type recordingReporter struct {
+	mu      sync.Mutex
+	reports []errreport.Report
+}
+
+func (r *recordingReporter) Report(_ context.Context, report errreport.Report) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reports = append(r.reports, report)
+}
+
+// panickingProcess panics once it runs
+type panickingProcess struct {
+	mockProcess
+}
+
+func (*panickingProcess) Run(context.Context) error {
+	panic("nil map")
+}
+
+func (*panickingProcess) Name() string {
+	return "indexer"
+}
+
+func TestServer_Run_ProcessPanic(t *testing.T) {
+	// Arrange
+	reporter := &recordingReporter{}
+	s := NewServer(
+		WithLogger(slog.New(slog.DiscardHandler)),
+		WithSplashDisabled(),
+		WithInMemoryTransport(),
+		WithErrorReporter(reporter),
+		WithProcesses(&panickingProcess{}),
 	)
 
-	// Act & Assert - Just make sure it doesn't panic
-	require.NotPanics(t, func() {
-		s.displaySplash()
+	// Act
+	err := s.Run(context.Background())
+
+	// Assert
+	assert.ErrorContains(t, err, "process 0 panicked: nil map")
+	reporter.mu.Lock()
+	defer reporter.mu.Unlock()
+	require.NotEmpty(t, reporter.reports)
+	assert.Equal(t, "nil map", reporter.reports[0].Panic)
+	assert.Equal(t, "indexer", reporter.reports[0].Tags["process"])
+	assert.NotEmpty(t, reporter.reports[0].Stack)
+}
+
+// failingProcess fails after a while, and fails to shut down
+type failingProcess struct {
+	mockProcess
+	runErr      error
+	shutdownErr error
+}
+
+func (p *failingProcess) Run(context.Context) error {
+	time.Sleep(10 * time.Millisecond)
+	return p.runErr
+}
+
+func (p *failingProcess) Shutdown(context.Context) error {
+	return p.shutdownErr
+}
+
+// blockingProcess runs until its context is canceled, recording when it returned
+type blockingProcess struct {
+	mockProcess
+	returned atomic.Bool
+}
+
+func (p *blockingProcess) Run(ctx context.Context) error {
+	<-ctx.Done()
+	time.Sleep(10 * time.Millisecond)
+	p.returned.Store(true)
+	return nil
+}
+
+func TestServer_Run_FirstErrorCancelsOthers(t *testing.T) {
+	// Arrange
+	failing := &failingProcess{runErr: errors.New("connection lost"), shutdownErr: errors.New("flush failed")}
+	blocking := &blockingProcess{}
+	s := NewServer(
+		WithLogger(slog.New(slog.DiscardHandler)),
+		WithSplashDisabled(),
+		WithInMemoryTransport(),
+		WithProcesses(blocking, failing),
+	)
+
+	// Act
+	err := s.Run(context.Background())
+
+	// Assert - the errors are joined, and Run returns once the processes did
+	assert.ErrorContains(t, err, "connection lost")
+	assert.ErrorContains(t, err, "flush failed")
+	assert.True(t, blocking.returned.Load(), "the other processes are canceled and waited for")
+}
+
+// recordingNotifier records the notifications it receives
+type recordingNotifier struct {
+	mu            sync.Mutex
+	notifications []webhook.Notification
+}
+
+func (r *recordingNotifier) Notify(_ context.Context, n webhook.Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.notifications = append(r.notifications, n)
+	return nil
+}
+
+func TestServer_Run_Notifications(t *testing.T) {
+	tests := []struct {
+		name    string
+		process Process
+		kind    webhook.Kind
+		want    string
+	}{
+		{name: "pre-run failure", process: &fakeServer{preRunErr: errors.New("no database")}, kind: webhook.PreRunFailed, want: "no database"},
+		{name: "process panic", process: &panickingProcess{}, kind: webhook.ProcessCrashed, want: "panic: nil map"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			notifier := &recordingNotifier{}
+			s := NewServer(
+				WithLogger(slog.New(slog.DiscardHandler)),
+				WithSplashDisabled(),
+				WithInMemoryTransport(),
+				WithNotifier(notifier),
+				WithProcesses(tt.process),
+			)
+
+			// Act
+			err := s.Run(context.Background())
+
+			// Assert
+			require.Error(t, err)
+			require.Len(t, notifier.notifications, 1, "Run waits for the notifications")
+			n := notifier.notifications[0]
+			assert.Equal(t, tt.kind, n.Kind)
+			assert.EqualError(t, n.Err, tt.want)
+			assert.NotEmpty(t, n.Host)
+		})
+	}
+}
+
+func TestServer_LifecycleEvents(t *testing.T) {
+	// Arrange
+	var (
+		mu     sync.Mutex
+		events []string
+	)
+	record := func(e lifecycle.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		name := strings.TrimPrefix(fmt.Sprintf("%T", e), "lifecycle.")
+		// Collapse the events published for each process
+		if len(events) == 0 || events[len(events)-1] != name {
+			events = append(events, name)
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s := NewServer(
+		WithLogger(slog.New(slog.DiscardHandler)),
+		WithSplashDisabled(),
+		WithInMemoryTransport(),
+		WithLifecycleHandler(record),
+	)
+	var late []lifecycle.Event
+	unsubscribe := s.Subscribe(func(e lifecycle.Event) { late = append(late, e) })
+	unsubscribe()
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+	<-s.Ready()
+
+	// Act
+	cancel()
+	err := <-done
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"ConfigLoaded",
+		"ProcessStarted",
+		"Ready",
+		"Draining",
+		"ProcessStopped",
+		"ShutdownComplete",
+	}, events)
+	assert.Empty(t, late, "unsubscribed handlers receive nothing")
+}
+
+func TestServer_DelayShutdown(t *testing.T) {
+	t.Run("waits for the delay", func(t *testing.T) {
+		// Arrange
+		s := NewServer(WithLogger(slog.Default()), WithShutdownDelay(50*time.Millisecond))
+
+		// Act
+		start := time.Now()
+		err := s.delayShutdown(context.Background())
+
+		// Assert
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
 	})
+
+	t.Run("process error ends the delay", func(t *testing.T) {
+		// Arrange
+		s := NewServer(WithLogger(slog.Default()), WithShutdownDelay(time.Minute))
+		failed, fail := context.WithCancelCause(context.Background())
+		fail(errors.New("process failed"))
+
+		// Act
+		err := s.delayShutdown(failed)
+
+		// Assert
+		assert.EqualError(t, err, "process failed")
+	})
+}
+
+// drainingProcess records when it was told to drain
+type drainingProcess struct {
+	mockProcess
+	drainedAt time.Time
+}
+
+func (p *drainingProcess) Drain() { p.drainedAt = time.Now() }
+
+func TestServer_Drain(t *testing.T) {
+	t.Run("waits for the drain delay", func(t *testing.T) {
+		// Arrange
+		process := &drainingProcess{}
+		s := NewServer(WithLogger(slog.Default()), WithProcesses(process, &mockProcess{}))
+		s.cfg.DrainDelay = 50 * time.Millisecond
+
+		// Act
+		start := time.Now()
+		s.Drain()
+		err := s.waitDrainDelay(context.Background())
+
+		// Assert
+		assert.NoError(t, err)
+		assert.False(t, process.drainedAt.IsZero(), "draining processes are drained")
+		assert.GreaterOrEqual(t, time.Since(start), s.cfg.DrainDelay, "shutdown waits for the drain delay")
+	})
+
+	t.Run("process error ends the delay", func(t *testing.T) {
+		// Arrange
+		s := NewServer(WithLogger(slog.Default()))
+		s.cfg.DrainDelay = time.Minute
+		failed, fail := context.WithCancelCause(context.Background())
+		fail(errors.New("process failed"))
+
+		// Act
+		err := s.waitDrainDelay(failed)
+
+		// Assert
+		assert.EqualError(t, err, "process failed")
+	})
+}
+
+func TestServer_Run_ProcessErrorSkipsDrain(t *testing.T) {
+	// Arrange
+	draining := &drainingProcess{}
+	s := NewServer(
+		WithLogger(slog.New(slog.DiscardHandler)),
+		WithSplashDisabled(),
+		WithInMemoryTransport(),
+		WithProcesses(draining, &failingProcess{runErr: errors.New("connection lost")}),
+	)
+	s.cfg.DrainDelay = time.Minute
+
+	// Act
+	start := time.Now()
+	err := s.Run(context.Background())
+
+	// Assert - a crashed server stops without draining or waiting for the drain delay
+	assert.ErrorContains(t, err, "connection lost")
+	assert.True(t, draining.drainedAt.IsZero(), "processes are not drained")
+	assert.Less(t, time.Since(start), s.cfg.DrainDelay)
+}
+
+func TestServer_DrainStatus(t *testing.T) {
+	// Arrange
+	process := &drainingProcess{}
+	s := NewServer(WithLogger(slog.Default()), WithProcesses(process))
+	s.inflight = inflight.New()
+	release := make(chan struct{})
+	called := make(chan struct{})
+	go func() {
+		_, _ = s.inflight.UnaryServerInterceptor()(context.Background(), nil, &grpc.UnaryServerInfo{}, func(context.Context, any) (any, error) {
+			close(called)
+			<-release
+			return nil, nil
+		})
+	}()
+	<-called
+
+	// Act
+	before := s.drainStatus()
+	s.Drain()
+	busy := s.drainStatus()
+	drainedAt := process.drainedAt
+	s.Drain()
+	close(release)
+	require.Eventually(t, func() bool { return s.drainStatus().Idle }, time.Second, time.Millisecond)
+
+	// Assert
+	assert.False(t, before.Draining)
+	assert.True(t, busy.Draining)
+	assert.NotNil(t, busy.DrainingSince)
+	assert.Equal(t, int64(1), busy.InFlight.GRPC)
+	assert.False(t, busy.Idle, "a call is still in flight")
+	assert.Equal(t, drainedAt, process.drainedAt, "processes are drained once")
+}
+
+func TestServer_ApplySinglePort(t *testing.T) {
+	tests := []struct {
+		name     string
+		port     string
+		grpcAddr string
+		wantGRPC string
+		wantErr  bool
+	}{
+		{name: "moves listeners", port: "8081", grpcAddr: ":9090", wantGRPC: "127.0.0.1:9090"},
+		{name: "gRPC port conflicts with PORT", port: "9090", grpcAddr: ":9090", wantErr: true},
+		{name: "invalid gRPC address", port: "8081", grpcAddr: "9090", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			s := NewServer(WithGRPCAddress(tt.grpcAddr), WithSinglePort(tt.port))
+
+			// Act
+			err := s.applySinglePort()
+
+			// Assert
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, ":"+tt.port, s.cfg.HTTPAddress)
+			assert.Equal(t, tt.wantGRPC, s.cfg.GRPCAddress)
+			assert.Empty(t, s.cfg.MetricsAddress)
+			assert.Empty(t, s.cfg.PprofAddress)
+		})
+	}
+}
+
+func TestServer_ApplyLambda(t *testing.T) {
+	t.Run("outside Lambda", func(t *testing.T) {
+		// Arrange
+		t.Setenv("AWS_LAMBDA_RUNTIME_API", "")
+		s := NewServer(WithLambda(true))
+
+		// Act
+		err := s.applyLambda()
+
+		// Assert
+		assert.ErrorContains(t, err, "AWS_LAMBDA_RUNTIME_API")
+	})
+
+	t.Run("disables listeners", func(t *testing.T) {
+		// Arrange
+		t.Setenv("AWS_LAMBDA_RUNTIME_API", "127.0.0.1:9001")
+		s := NewServer(WithLambda(true), WithGRPCAddress(":9090"))
+
+		// Act
+		err := s.applyLambda()
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "127.0.0.1:9090", s.cfg.GRPCAddress)
+		assert.Empty(t, s.cfg.HTTPAddress)
+		assert.Empty(t, s.cfg.MetricsAddress)
+		assert.Empty(t, s.cfg.PprofAddress)
+	})
+}
+
+func TestServer_ApplyDisabledServers(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []Option
+		wantHTTP    string
+		wantMetrics string
+		wantPprof   string
+		wantErr     bool
+	}{
+		{name: "all enabled", wantHTTP: ":8080", wantMetrics: ":9091", wantPprof: ":6060"},
+		{name: "gRPC only", opts: []Option{WithHTTP(false)}, wantMetrics: ":9091", wantPprof: ":6060"},
+		{name: "no metrics or pprof", opts: []Option{WithMetricsServer(false), WithPprof(false)}, wantHTTP: ":8080"},
+		{name: "single-port needs HTTP", opts: []Option{WithHTTP(false), WithSinglePort("8081")}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			s := NewServer(tt.opts...)
+
+			// Act
+			err := s.applyDisabledServers()
+
+			// Assert
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantHTTP, s.cfg.HTTPAddress)
+			assert.Equal(t, tt.wantMetrics, s.cfg.MetricsAddress)
+			assert.Equal(t, tt.wantPprof, s.cfg.PprofAddress)
+		})
+	}
+}
+
+func TestServer_PprofRestricted(t *testing.T) {
+	assert.False(t, NewServer().pprofRestricted())
+	assert.True(t, NewServer(WithConfig(&config.Config{PprofAuthToken: "token"})).pprofRestricted())
+	assert.True(t, NewServer(WithConfig(&config.Config{PprofAllowedCIDRs: []string{"10.0.0.0/8"}})).pprofRestricted())
+}
+
+func TestNewServer_WithEnvPrefix(t *testing.T) {
+	// Arrange
+	t.Setenv("GRPC_ADDRESS", ":7000")
+	t.Setenv("SVCA_GRPC_ADDRESS", ":7100")
+	t.Setenv("SVCB_GRPC_ADDRESS", ":7200")
+
+	// Act
+	a := NewServer(WithEnvPrefix("SVCA"))
+	b := NewServer(WithEnvPrefix("SVCB"))
+	unprefixed := NewServer()
+
+	// Assert
+	assert.Equal(t, ":7100", a.cfg.GRPCAddress)
+	assert.Equal(t, ":7200", b.cfg.GRPCAddress)
+	assert.Equal(t, ":7000", unprefixed.cfg.GRPCAddress)
 }
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/legrch/netgex/internal/gateway"
+	grpcserver "github.com/legrch/netgex/internal/grpc"
+	"github.com/legrch/netgex/internal/metrics"
+	"github.com/legrch/netgex/internal/pprof"
+	"github.com/legrch/netgex/splash"
+)
+
+// internalPrefix is the path prefix of operational endpoints in single-port mode
+const internalPrefix = "/internal"
+
+// applySinglePort moves the HTTP server onto PORT and disables the separate metrics and
+// pprof listeners. The gRPC server keeps listening on loopback for the gateway to dial.
+func (s *Server) applySinglePort() error {
+	_, grpcPort, err := net.SplitHostPort(s.cfg.GRPCAddress)
+	if err != nil {
+		return fmt.Errorf("invalid gRPC address: %w", err)
+	}
+	if grpcPort == s.cfg.Port {
+		return fmt.Errorf("gRPC port %s must differ from PORT", grpcPort)
+	}
+
+	s.cfg.HTTPAddress = ":" + s.cfg.Port
+	s.cfg.GRPCAddress = net.JoinHostPort("127.0.0.1", grpcPort)
+	s.cfg.MetricsAddress = ""
+	s.cfg.PprofAddress = ""
+
+	return nil
+}
+
+// singlePortGatewayOptions mounts gRPC and the operational endpoints on the gateway
+func (s *Server) singlePortGatewayOptions(grpcServer *grpcserver.Server, pprofServer *pprof.Server) []gateway.Option {
+	var opts []gateway.Option
+
+	if s.cfg.MetricsServerEnabled {
+		opts = append(opts, gateway.WithHandler(internalPrefix+s.cfg.Telemetry.Metrics.Path, metrics.Handler(s.metricsLabels)))
+	}
+
+	if s.cfg.SinglePortGRPCEnabled {
+		opts = append(opts, gateway.WithGRPCHandler(grpcServer))
+	}
+
+	if pprofServer != nil {
+		// The HTTP port is public, so profiling endpoints are never exposed unprotected
+		if !s.pprofRestricted() {
+			s.logger.Warn("pprof is not served in single-port mode without an auth token, basic auth or allowed CIDRs")
+		} else {
+			opts = append(opts, gateway.WithHandler(internalPrefix+"/debug/", http.StripPrefix(internalPrefix, pprofServer.Handler())))
+		}
+	}
+
+	return opts
+}
+
+// singlePortSplashOptions reports the endpoints served on the HTTP port
+func (s *Server) singlePortSplashOptions() []splash.SplashOption {
+	var opts []splash.SplashOption
+
+	if s.cfg.MetricsServerEnabled {
+		opts = append(opts, splash.WithMetricsAddress(s.cfg.HTTPAddress+internalPrefix+s.cfg.Telemetry.Metrics.Path))
+	}
+
+	if s.cfg.SinglePortGRPCEnabled {
+		opts = append(opts, splash.WithGRPCAddress(s.cfg.HTTPAddress+" (h2c)"))
+	}
+	if s.cfg.PprofEnabled && s.pprofRestricted() {
+		opts = append(opts, splash.WithPprofAddress(s.cfg.HTTPAddress+internalPrefix+"/debug/pprof/"))
+	}
+
+	return opts
+}
+
+// pprofRestricted reports whether pprof access requires credentials or an allowed network
+func (s *Server) pprofRestricted() bool {
+	return s.cfg.PprofAuthToken != "" || s.cfg.PprofBasicAuthUser != "" || len(s.cfg.PprofAllowedCIDRs) > 0
+}
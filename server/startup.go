@@ -0,0 +1,194 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/legrch/netgex/internal/telemetry"
+)
+
+// startupSpan is the name of the root span of the startup trace
+const startupSpan = "service.start"
+
+// ErrStartupTimeout is returned by Run when the server is not ready within
+// STARTUP_TIMEOUT and STARTUP_FAIL_FAST is set
+var ErrStartupTimeout = errors.New("server not ready within the startup timeout")
+
+// startupTrace records the phases of the server startup, emitted as a "service.start"
+// trace once the server is ready. The tracer provider is only set up by the telemetry
+// process, so phases are recorded with their times and emitted afterwards.
+type startupTrace struct {
+	mu     sync.Mutex
+	start  time.Time
+	phases []*startupPhase
+	// watchers end the run phases of the processes becoming ready
+	watchers sync.WaitGroup
+	emitted  chan struct{}
+}
+
+// startupPhase is a phase of the startup; it has no end until it finished
+type startupPhase struct {
+	name  string
+	attrs []attribute.KeyValue
+	start time.Time
+	end   time.Time
+	err   error
+}
+
+func newStartupTrace() *startupTrace {
+	return &startupTrace{start: time.Now(), emitted: make(chan struct{})}
+}
+
+// phase starts a phase of the startup and returns the function ending it with its error
+func (t *startupTrace) phase(name string, attrs ...attribute.KeyValue) (end func(err error)) {
+	p := &startupPhase{name: name, attrs: attrs, start: time.Now()}
+	t.mu.Lock()
+	t.phases = append(t.phases, p)
+	t.mu.Unlock()
+
+	return func(err error) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if p.end.IsZero() {
+			p.end, p.err = time.Now(), err
+		}
+	}
+}
+
+// watch ends a phase once the process is ready, unless the trace is emitted first
+func (t *startupTrace) watch(r Readier, end func(err error)) {
+	t.watchers.Add(1)
+	go func() {
+		defer t.watchers.Done()
+		select {
+		case <-r.Ready():
+			end(nil)
+		case <-t.emitted:
+			// The process may have become ready at the same time
+			select {
+			case <-r.Ready():
+				end(nil)
+			default:
+			}
+		}
+	}()
+}
+
+// emit creates the spans of the startup with the recorded times. Phases that did not
+// finish, e.g. processes that never became ready, end with the trace and an error status.
+func (t *startupTrace) emit(tracer trace.Tracer, err error) {
+	close(t.emitted)
+	t.watchers.Wait()
+	now := time.Now()
+
+	ctx, root := tracer.Start(context.Background(), startupSpan,
+		trace.WithTimestamp(t.start),
+		trace.WithSpanKind(trace.SpanKindInternal),
+	)
+
+	t.mu.Lock()
+	for _, p := range t.phases {
+		_, span := tracer.Start(ctx, p.name, trace.WithTimestamp(p.start), trace.WithAttributes(p.attrs...))
+		end := p.end
+		switch {
+		case end.IsZero():
+			end = now
+			span.SetStatus(codes.Error, "did not finish")
+		case p.err != nil:
+			span.RecordError(p.err)
+			span.SetStatus(codes.Error, p.err.Error())
+		}
+		span.End(trace.WithTimestamp(end))
+	}
+	t.mu.Unlock()
+
+	if err != nil {
+		root.RecordError(err)
+		root.SetStatus(codes.Error, err.Error())
+	}
+	root.End(trace.WithTimestamp(now))
+}
+
+// preRunPhase names the pre-run phase of a process, the telemetry process initializing
+// the tracer provider the startup trace is emitted with
+func preRunPhase(p Process) string {
+	if _, ok := p.(*telemetry.Service); ok {
+		return "telemetry.init"
+	}
+	return "process.prerun"
+}
+
+// watchStartup logs the startup phases when the server is not ready within
+// STARTUP_TIMEOUT. The returned deadline is closed then if the server fails fast, and
+// is nil otherwise; stop ends the watch once the server is ready.
+func (s *Server) watchStartup() (deadline <-chan struct{}, stop func()) {
+	if s.cfg.StartupTimeout <= 0 {
+		return nil, func() {}
+	}
+
+	exceeded := make(chan struct{})
+	timer := time.AfterFunc(s.cfg.StartupTimeout, func() {
+		s.startup.report(s.logger, s.cfg.StartupTimeout)
+		close(exceeded)
+	})
+	if s.cfg.StartupFailFast {
+		deadline = exceeded
+	}
+	return deadline, func() { timer.Stop() }
+}
+
+// preRun runs the PreRun of a process. Once deadline is closed, its context is canceled
+// and the process is given up on, even when PreRun does not return.
+func (s *Server) preRun(ctx context.Context, p Process, deadline <-chan struct{}) error {
+	if deadline == nil {
+		return p.PreRun(ctx)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- p.PreRun(ctx) }()
+	select {
+	case err := <-done:
+		return err
+	case <-deadline:
+		return s.startupTimeoutError()
+	}
+}
+
+func (s *Server) startupTimeoutError() error {
+	return fmt.Errorf("%w (%s)", ErrStartupTimeout, s.cfg.StartupTimeout)
+}
+
+// report logs the phases of the startup with their durations, the ones still running
+// pointing at what the startup waits for
+func (t *startupTrace) report(logger *slog.Logger, timeout time.Duration) {
+	now := time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	logger.Warn("server not ready within the startup timeout", "timeout", timeout, "elapsed", now.Sub(t.start))
+	for _, p := range t.phases {
+		args := []any{"phase", p.name}
+		for _, attr := range p.attrs {
+			args = append(args, string(attr.Key), attr.Value.Emit())
+		}
+		switch {
+		case p.end.IsZero():
+			args = append(args, "duration", now.Sub(p.start), "running", true)
+		case p.err != nil:
+			args = append(args, "duration", p.end.Sub(p.start), "error", p.err)
+		default:
+			args = append(args, "duration", p.end.Sub(p.start))
+		}
+		logger.Warn("startup phase", args...)
+	}
+}
@@ -0,0 +1,157 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/legrch/netgex/config"
+	"github.com/legrch/netgex/internal/telemetry"
+)
+
+// readier is a Readier, ready once its channel is closed
+type readier chan struct{}
+
+func (r readier) Ready() <-chan struct{} { return r }
+
+func TestStartupTrace_Emit(t *testing.T) {
+	// Arrange
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	startup := newStartupTrace()
+	startup.phase("config.load")(nil)
+	startup.phase("process.prerun", attribute.String("process.name", "db"))(errors.New("unreachable"))
+	ready := make(readier)
+	startup.watch(ready, startup.phase("process.run", attribute.String("process.name", "grpc")))
+	close(ready)
+	startup.watch(make(readier), startup.phase("process.run", attribute.String("process.name", "http")))
+
+	// Act
+	startup.emit(provider.Tracer("server"), nil)
+
+	// Assert
+	spans := recorder.Ended()
+	require.Len(t, spans, 5)
+	root := spans[len(spans)-1]
+	assert.Equal(t, startupSpan, root.Name())
+	assert.Equal(t, codes.Unset, root.Status().Code)
+
+	for _, span := range spans[:len(spans)-1] {
+		assert.Equal(t, root.SpanContext().SpanID(), span.Parent().SpanID(), span.Name())
+		assert.False(t, span.StartTime().Before(root.StartTime()), span.Name())
+		assert.False(t, span.EndTime().After(root.EndTime()), span.Name())
+	}
+	assert.Equal(t, "config.load", spans[0].Name())
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Equal(t, "unreachable", spans[1].Status().Description)
+	assert.Contains(t, spans[1].Attributes(), attribute.String("process.name", "db"))
+	assert.Equal(t, codes.Unset, spans[2].Status().Code, "the ready process finished its run phase")
+	assert.Equal(t, codes.Error, spans[3].Status().Code, "the process never became ready")
+	assert.Equal(t, "did not finish", spans[3].Status().Description)
+}
+
+func TestPreRunPhase(t *testing.T) {
+	// Act
+	telemetryPhase := preRunPhase(telemetry.NewService(slog.New(slog.DiscardHandler), config.NewConfig()))
+	processPhase := preRunPhase(&configWatcher{})
+
+	// Assert
+	assert.Equal(t, "telemetry.init", telemetryPhase)
+	assert.Equal(t, "process.prerun", processPhase)
+}
+
+// slowProcess is a process whose PreRun waits for release, or for its context
+type slowProcess struct {
+	mockProcess
+	release chan struct{}
+}
+
+func (p *slowProcess) PreRun(ctx context.Context) error {
+	select {
+	case <-p.release:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// lockedBuffer is a buffer safe for concurrent use
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestServer_StartupTimeout(t *testing.T) {
+	t.Run("fail fast", func(t *testing.T) {
+		// Arrange
+		var logs bytes.Buffer
+		s := NewServer(
+			WithLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+			WithInMemoryTransport(),
+			WithSplashDisabled(),
+			WithProcesses(&slowProcess{release: make(chan struct{})}),
+			WithStartupTimeout(50*time.Millisecond, true),
+		)
+
+		// Act
+		err := s.Run(context.Background())
+
+		// Assert
+		require.ErrorIs(t, err, ErrStartupTimeout)
+		assert.Contains(t, logs.String(), "server not ready within the startup timeout")
+		assert.Contains(t, logs.String(), "phase=process.prerun process.name=*server.slowProcess")
+		assert.Contains(t, logs.String(), "running=true")
+	})
+
+	t.Run("report only", func(t *testing.T) {
+		// Arrange
+		// The report is logged by a timer, concurrently with Run
+		logs := &lockedBuffer{}
+		process := &slowProcess{release: make(chan struct{})}
+		s := NewServer(
+			WithLogger(slog.New(slog.NewTextHandler(logs, nil))),
+			WithInMemoryTransport(),
+			WithSplashDisabled(),
+			WithProcesses(process),
+			WithStartupTimeout(50*time.Millisecond, false),
+		)
+		ctx, cancel := context.WithCancel(context.Background())
+		errCh := make(chan error, 1)
+
+		// Act
+		go func() { errCh <- s.Run(ctx) }()
+		time.Sleep(100 * time.Millisecond)
+		close(process.release)
+		<-s.Ready()
+		cancel()
+
+		// Assert
+		require.NoError(t, <-errCh)
+		assert.Contains(t, logs.String(), "server not ready within the startup timeout")
+	})
+}
@@ -0,0 +1,23 @@
+package server
+
+import (
+	"github.com/legrch/netgex/tenant"
+)
+
+// applyTenancy creates the tenant resolver from the options and resolves the tenant of
+// calls through the gRPC interceptors. It runs after telemetry, so the tenant is added
+// to the span of the call.
+func (s *Server) applyTenancy() {
+	if !s.tenancyEnabled {
+		return
+	}
+
+	var opts []tenant.Option
+	if s.cfg.Telemetry.Metrics.Enabled && s.cfg.Telemetry.Metrics.Backend == "prometheus" {
+		opts = append(opts, tenant.WithMetrics(s.cfg.Telemetry.Metrics.Namespace))
+	}
+	s.tenancy = tenant.New(append(opts, s.tenancyOptions...)...)
+
+	s.addGRPCUnaryInterceptors(s.tenancy.UnaryServerInterceptor())
+	s.addGRPCStreamInterceptors(s.tenancy.StreamServerInterceptor())
+}
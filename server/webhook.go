@@ -0,0 +1,39 @@
+package server
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/legrch/netgex/webhook"
+)
+
+// notify sends a notification about a failure to the webhooks in the background. Run
+// waits for the notifications before returning, so they are not lost when the service
+// exits.
+func (s *Server) notify(kind webhook.Kind, process string, err error) {
+	if len(s.notifiers) == 0 {
+		return
+	}
+
+	host, _ := os.Hostname()
+	n := webhook.Notification{
+		Kind:        kind,
+		Service:     s.cfg.ServiceName,
+		Version:     s.cfg.ServiceVersion,
+		Environment: s.cfg.Environment,
+		Host:        host,
+		Process:     process,
+		Err:         err,
+		Time:        time.Now().UTC(),
+	}
+	s.notifications.Add(1)
+	go func() {
+		defer s.notifications.Done()
+		for _, notifier := range s.notifiers {
+			if err := notifier.Notify(context.Background(), n); err != nil {
+				s.logger.Warn("failed to send webhook notification", "kind", kind, "error", err)
+			}
+		}
+	}()
+}
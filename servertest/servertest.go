@@ -0,0 +1,143 @@
+// Package servertest runs a netgex server for integration tests on ephemeral loopback
+// ports, with clients connected to it and shutdown registered with the test.
+package servertest
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/legrch/netgex/server"
+)
+
+// StartTimeout is how long Start waits for the server to be ready
+const StartTimeout = 10 * time.Second
+
+// Server is a netgex server running for the duration of a test
+type Server struct {
+	*server.Server
+
+	// GRPCConn is a client connection to the gRPC server
+	GRPCConn *grpc.ClientConn
+	// HTTPClient is an HTTP client for the gateway
+	HTTPClient *http.Client
+	// URL is the base URL of the gateway, e.g. http://127.0.0.1:54321; empty when the
+	// gateway is disabled
+	URL string
+}
+
+// Start runs a server with the given options on ephemeral loopback ports and waits
+// until it is ready. Logging, the splash screen, metrics and pprof are off unless opts
+// enable them. The server is stopped and the clients closed when the test ends.
+func Start(tb testing.TB, opts ...server.Option) *Server {
+	tb.Helper()
+
+	opts = append(opts,
+		server.WithGRPCAddress("127.0.0.1:0"),
+		server.WithHTTPAddress("127.0.0.1:0"),
+	)
+	srv := run(tb, opts)
+
+	conn, err := grpc.NewClient(srv.GRPCAddr(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		tb.Fatalf("servertest: failed to create gRPC client: %v", err)
+	}
+
+	s := &Server{
+		Server:     srv,
+		GRPCConn:   conn,
+		HTTPClient: &http.Client{Timeout: StartTimeout},
+	}
+	if addr := srv.HTTPAddr(); addr != "" {
+		s.URL = "http://" + addr
+	}
+	s.cleanup(tb)
+
+	return s
+}
+
+// StartInMemory runs a server like Start, but over in-memory listeners without binding
+// any port (see server.WithInMemoryTransport). The clients connect in memory, and URL
+// is http://bufconn.
+func StartInMemory(tb testing.TB, opts ...server.Option) *Server {
+	tb.Helper()
+
+	srv := run(tb, append(opts, server.WithInMemoryTransport()))
+
+	conn, err := grpc.NewClient(server.InMemoryTarget,
+		grpc.WithContextDialer(srv.DialGRPCInMemory),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		tb.Fatalf("servertest: failed to create gRPC client: %v", err)
+	}
+
+	s := &Server{
+		Server:   srv,
+		GRPCConn: conn,
+		HTTPClient: &http.Client{
+			Timeout:   StartTimeout,
+			Transport: &http.Transport{DialContext: srv.DialHTTPInMemory},
+		},
+	}
+	if srv.HTTPAddr() != "" {
+		s.URL = "http://bufconn"
+	}
+	s.cleanup(tb)
+
+	return s
+}
+
+// cleanup closes the clients when the test ends, before the server is stopped
+func (s *Server) cleanup(tb testing.TB) {
+	tb.Cleanup(func() {
+		_ = s.GRPCConn.Close()
+		s.HTTPClient.CloseIdleConnections()
+	})
+}
+
+// run runs a server with the test defaults and opts until the test ends, and waits
+// until it is ready
+func run(tb testing.TB, opts []server.Option) *server.Server {
+	tb.Helper()
+
+	opts = append([]server.Option{
+		server.WithLogger(slog.New(slog.DiscardHandler)),
+		server.WithSplashDisabled(),
+		server.WithMetricsServer(false),
+		server.WithPprof(false),
+		server.WithAdminAddress("127.0.0.1:0"),
+	}, opts...)
+	srv := server.NewServer(opts...)
+
+	// Run the server until the test ends
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	var runErr error
+	go func() {
+		defer close(done)
+		runErr = srv.Run(ctx)
+	}()
+	tb.Cleanup(func() {
+		cancel()
+		<-done
+		if runErr != nil {
+			tb.Errorf("servertest: server error: %v", runErr)
+		}
+	})
+
+	select {
+	case <-srv.Ready():
+	case <-done:
+		tb.Fatalf("servertest: server stopped before it was ready: %v", runErr)
+	case <-time.After(StartTimeout):
+		tb.Fatalf("servertest: server not ready after %s", StartTimeout)
+	}
+
+	return srv
+}
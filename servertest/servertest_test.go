@@ -0,0 +1,72 @@
+package servertest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/legrch/netgex/middleware"
+	"github.com/legrch/netgex/server"
+)
+
+func TestStart(t *testing.T) {
+	// Arrange
+	s := Start(t)
+
+	// Act
+	health, err := grpc_health_v1.NewHealthClient(s.GRPCConn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	require.NoError(t, err)
+	resp, err := s.HTTPClient.Get(s.URL + "/health")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	// Assert
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, health.GetStatus())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotContains(t, s.GRPCAddr(), ":0")
+}
+
+func TestStart_HTTPDisabled(t *testing.T) {
+	// Act
+	s := Start(t, server.WithHTTP(false))
+
+	// Assert
+	assert.Empty(t, s.URL)
+	assert.NotEmpty(t, s.GRPCAddr())
+}
+
+func TestStartInMemory(t *testing.T) {
+	// Arrange
+	s := StartInMemory(t)
+
+	// Act
+	health, err := grpc_health_v1.NewHealthClient(s.GRPCConn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	require.NoError(t, err)
+	resp, err := s.HTTPClient.Get(s.URL + "/health")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	// Assert
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, health.GetStatus())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "http://bufconn", s.URL)
+}
+
+func TestStartInMemory_DefaultMiddleware(t *testing.T) {
+	// Arrange
+	s := StartInMemory(t, server.WithDefaultMiddleware())
+	var header metadata.MD
+
+	// Act
+	_, err := grpc_health_v1.NewHealthClient(s.GRPCConn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{}, grpc.Header(&header))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Len(t, header.Get(middleware.RequestIDHeader), 1)
+}
@@ -2,13 +2,15 @@ package service
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"google.golang.org/grpc"
 )
 
 // Registrar is an interface for gRPC service implementations that can register
-// themselves with both gRPC and HTTP/REST gateway servers
+// themselves with both gRPC and HTTP/REST gateway servers. Registrars can additionally
+// implement Namer, HealthChecker and Closer, which the server detects.
 type Registrar interface {
 	// RegisterGRPC registers the gRPC service with the gRPC server
 	RegisterGRPC(*grpc.Server)
@@ -16,3 +18,73 @@ type Registrar interface {
 	// RegisterHTTP registers the HTTP/REST handlers with the gateway mux
 	RegisterHTTP(context.Context, *runtime.ServeMux, string, []grpc.DialOption) error
 }
+
+// Namer is implemented by registrars that report a name, used in logs and errors
+type Namer interface {
+	Name() string
+}
+
+// HealthChecker is implemented by registrars that can report their health, e.g. by
+// checking their dependencies; the server is unhealthy while any check fails
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// Closer is implemented by registrars that release resources on shutdown; Close is
+// called after the servers have stopped
+type Closer interface {
+	Close(ctx context.Context) error
+}
+
+// Name returns the name of the registrar if it implements Namer, and its type otherwise
+func Name(r Registrar) string {
+	if n, ok := r.(Namer); ok {
+		return n.Name()
+	}
+	return fmt.Sprintf("%T", r)
+}
+
+// ServesHTTP reports whether the registrar registers HTTP handlers. Registrars are assumed
+// to, unless they implement ServesHTTP returning false, e.g. by embedding GRPCOnly.
+func ServesHTTP(r Registrar) bool {
+	if h, ok := r.(interface{ ServesHTTP() bool }); ok {
+		return h.ServesHTTP()
+	}
+	return true
+}
+
+// ServesGRPC reports whether the registrar registers a gRPC service. Registrars are
+// assumed to, unless they implement ServesGRPC returning false, e.g. by embedding HTTPOnly.
+func ServesGRPC(r Registrar) bool {
+	if g, ok := r.(interface{ ServesGRPC() bool }); ok {
+		return g.ServesGRPC()
+	}
+	return true
+}
+
+// GRPCOnly is embedded by registrars serving gRPC only. It implements RegisterHTTP as a
+// no-op, and the server skips the gateway when none of its registrars serves HTTP.
+type GRPCOnly struct{}
+
+// RegisterHTTP registers nothing
+func (GRPCOnly) RegisterHTTP(context.Context, *runtime.ServeMux, string, []grpc.DialOption) error {
+	return nil
+}
+
+// ServesHTTP reports that the registrar registers no HTTP handlers
+func (GRPCOnly) ServesHTTP() bool {
+	return false
+}
+
+// HTTPOnly is embedded by registrars serving plain HTTP handlers only, not backed by a
+// gRPC service. It implements RegisterGRPC as a no-op, and the server skips the gRPC
+// server when none of the registrars serves gRPC.
+type HTTPOnly struct{}
+
+// RegisterGRPC registers nothing
+func (HTTPOnly) RegisterGRPC(*grpc.Server) {}
+
+// ServesGRPC reports that the registrar registers no gRPC service
+func (HTTPOnly) ServesGRPC() bool {
+	return false
+}
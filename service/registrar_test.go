@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+type anonymousRegistrar struct{}
+
+func (anonymousRegistrar) RegisterGRPC(*grpc.Server) {}
+
+func (anonymousRegistrar) RegisterHTTP(context.Context, *runtime.ServeMux, string, []grpc.DialOption) error {
+	return nil
+}
+
+type namedRegistrar struct {
+	anonymousRegistrar
+}
+
+func (namedRegistrar) Name() string {
+	return "orders"
+}
+
+func TestName(t *testing.T) {
+	tests := []struct {
+		name      string
+		registrar Registrar
+		want      string
+	}{
+		{name: "Namer", registrar: namedRegistrar{}, want: "orders"},
+		{name: "type name", registrar: anonymousRegistrar{}, want: "service.anonymousRegistrar"},
+		{name: "pointer type name", registrar: &anonymousRegistrar{}, want: "*service.anonymousRegistrar"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := Name(tt.registrar)
+
+			// Assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+type grpcOnlyRegistrar struct {
+	GRPCOnly
+}
+
+func (grpcOnlyRegistrar) RegisterGRPC(*grpc.Server) {}
+
+type httpOnlyRegistrar struct {
+	HTTPOnly
+}
+
+func (httpOnlyRegistrar) RegisterHTTP(context.Context, *runtime.ServeMux, string, []grpc.DialOption) error {
+	return nil
+}
+
+func TestServes(t *testing.T) {
+	tests := []struct {
+		name      string
+		registrar Registrar
+		wantGRPC  bool
+		wantHTTP  bool
+	}{
+		{name: "both", registrar: anonymousRegistrar{}, wantGRPC: true, wantHTTP: true},
+		{name: "gRPC only", registrar: grpcOnlyRegistrar{}, wantGRPC: true, wantHTTP: false},
+		{name: "HTTP only", registrar: httpOnlyRegistrar{}, wantGRPC: false, wantHTTP: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			gotGRPC, gotHTTP := ServesGRPC(tt.registrar), ServesHTTP(tt.registrar)
+
+			// Assert
+			assert.Equal(t, tt.wantGRPC, gotGRPC)
+			assert.Equal(t, tt.wantHTTP, gotHTTP)
+		})
+	}
+}
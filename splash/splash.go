@@ -1,12 +1,24 @@
 package splash
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"log/slog"
+	"net"
 	"os"
 	"runtime"
 	"strings"
 )
 
+// Splash output formats
+const (
+	// FormatBanner prints the splash screen as a human-readable banner
+	FormatBanner = "banner"
+	// FormatLog emits the splash screen as a single structured log record
+	FormatLog = "log"
+)
+
 // SplashOption is a function that configures a Splash
 type SplashOption func(*Splash)
 
@@ -20,7 +32,12 @@ type Splash struct {
 	pprofAddress    string
 	swaggerEnabled  bool
 	swaggerBasePath string
+	memoryLimit     string
+	gcPercent       string
 	features        []string
+	services        []string
+	routeCount      int
+	writer          io.Writer
 }
 
 // NewSplash creates a new Splash with the given options
@@ -36,6 +53,7 @@ func NewSplash(opts ...SplashOption) *Splash {
 		hostname:  hostname,
 		goVersion: runtime.Version(),
 		features:  []string{},
+		writer:    os.Stdout,
 	}
 
 	// Apply options
@@ -82,6 +100,14 @@ func WithSwaggerBasePath(path string) SplashOption {
 	}
 }
 
+// WithRuntimeSettings sets the effective memory limit and GC percent for the splash screen
+func WithRuntimeSettings(memoryLimit, gcPercent string) SplashOption {
+	return func(s *Splash) {
+		s.memoryLimit = memoryLimit
+		s.gcPercent = gcPercent
+	}
+}
+
 // WithFeature adds a feature to the splash screen
 func WithFeature(feature string) SplashOption {
 	return func(s *Splash) {
@@ -89,6 +115,27 @@ func WithFeature(feature string) SplashOption {
 	}
 }
 
+// WithServices sets the registered gRPC service names for the splash screen
+func WithServices(names ...string) SplashOption {
+	return func(s *Splash) {
+		s.services = append(s.services, names...)
+	}
+}
+
+// WithRouteCount sets the number of HTTP routes served by the gateway for the splash screen
+func WithRouteCount(count int) SplashOption {
+	return func(s *Splash) {
+		s.routeCount = count
+	}
+}
+
+// WithWriter sets the writer the splash screen is displayed on, stdout by default
+func WithWriter(w io.Writer) SplashOption {
+	return func(s *Splash) {
+		s.writer = w
+	}
+}
+
 // String returns the splash screen as a string
 //
 //nolint:gocyclo // This function is complex by nature
@@ -110,7 +157,11 @@ func (s *Splash) String() string {
 		}
 
 		if s.httpAddress != "" {
-			splash = append(splash, fmt.Sprintf("   • HTTP API: %s", s.httpAddress))
+			httpAPI := fmt.Sprintf("   • HTTP API: %s", s.httpAddress)
+			if s.routeCount > 0 {
+				httpAPI += fmt.Sprintf(" (%d routes)", s.routeCount)
+			}
+			splash = append(splash, httpAPI)
 		}
 
 		if s.metricsAddress != "" {
@@ -125,6 +176,9 @@ func (s *Splash) String() string {
 		if s.swaggerEnabled {
 			// Extract port from HTTP address
 			port := strings.TrimPrefix(s.httpAddress, ":")
+			if _, p, err := net.SplitHostPort(s.httpAddress); err == nil {
+				port = p
+			}
 
 			// Create clickable link for terminal
 			swaggerURL := fmt.Sprintf("http://localhost:%s/swagger", port)
@@ -136,6 +190,27 @@ func (s *Splash) String() string {
 		splash = append(splash, "")
 	}
 
+	// Add registered services if any
+	if len(s.services) > 0 {
+		splash = append(splash, "🧩 Services:")
+		for _, name := range s.services {
+			splash = append(splash, fmt.Sprintf("   • %s", name))
+		}
+		splash = append(splash, "")
+	}
+
+	// Add runtime settings if set
+	if s.memoryLimit != "" || s.gcPercent != "" {
+		splash = append(splash, "⚙️  Runtime:")
+		if s.memoryLimit != "" {
+			splash = append(splash, fmt.Sprintf("   • GOMEMLIMIT: %s", s.memoryLimit))
+		}
+		if s.gcPercent != "" {
+			splash = append(splash, fmt.Sprintf("   • GOGC: %s", s.gcPercent))
+		}
+		splash = append(splash, "")
+	}
+
 	// Add features information if any
 	if len(s.features) > 0 {
 		splash = append(splash, "✨ Features:")
@@ -148,7 +223,56 @@ func (s *Splash) String() string {
 	return strings.Join(splash, "\n")
 }
 
-// Display prints the splash screen to stdout
+// Display prints the splash screen to the writer
 func (s *Splash) Display() {
-	fmt.Print(s.String())
+	_, _ = fmt.Fprint(s.writer, s.String())
+}
+
+// Log emits the splash screen as a single "startup summary" record, so log pipelines
+// get the same information as the banner
+func (s *Splash) Log(ctx context.Context, logger *slog.Logger) {
+	attrs := []slog.Attr{
+		slog.String("hostname", s.hostname),
+		slog.String("go_version", s.goVersion),
+	}
+
+	var endpoints []any
+	for _, e := range []struct{ key, value string }{
+		{"grpc", s.grpcAddress},
+		{"http", s.httpAddress},
+		{"metrics", s.metricsAddress},
+		{"pprof", s.pprofAddress},
+	} {
+		if e.value != "" {
+			endpoints = append(endpoints, slog.String(e.key, e.value))
+		}
+	}
+	if s.swaggerEnabled {
+		endpoints = append(endpoints, slog.String("swagger_base_path", s.swaggerBasePath))
+	}
+	if len(endpoints) > 0 {
+		attrs = append(attrs, slog.Group("endpoints", endpoints...))
+	}
+
+	if s.routeCount > 0 {
+		attrs = append(attrs, slog.Int("routes", s.routeCount))
+	}
+	if len(s.services) > 0 {
+		attrs = append(attrs, slog.Any("services", s.services))
+	}
+	if s.memoryLimit != "" || s.gcPercent != "" {
+		attrs = append(attrs, slog.Group("runtime",
+			slog.String("gomemlimit", s.memoryLimit),
+			slog.String("gogc", s.gcPercent),
+		))
+	}
+	if len(s.features) > 0 {
+		features := make([]string, len(s.features))
+		for i, feature := range s.features {
+			features[i] = strings.TrimSpace(feature)
+		}
+		attrs = append(attrs, slog.Any("features", features))
+	}
+
+	logger.LogAttrs(ctx, slog.LevelInfo, "startup summary", attrs...)
 }
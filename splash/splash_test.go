@@ -1,10 +1,15 @@
 package splash
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
 	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewSplash(t *testing.T) {
@@ -108,6 +113,31 @@ func TestSplash_String(t *testing.T) {
 				"Feature 2",
 			},
 		},
+		{
+			name:   "splash with runtime settings",
+			splash: NewSplash(WithRuntimeSettings("512MiB", "off")),
+			contains: []string{
+				"Runtime",
+				"GOMEMLIMIT: 512MiB",
+				"GOGC: off",
+			},
+		},
+		{
+			name: "splash with services and routes",
+			splash: NewSplash(
+				WithHTTPAddress("127.0.0.1:41234"),
+				WithSwaggerBasePath("/"),
+				WithServices("pkg.v1.GreeterService", "pkg.v1.UserService"),
+				WithRouteCount(3),
+			),
+			contains: []string{
+				"HTTP API: 127.0.0.1:41234 (3 routes)",
+				"http://localhost:41234/swagger",
+				"Services",
+				"pkg.v1.GreeterService",
+				"pkg.v1.UserService",
+			},
+		},
 		{
 			name: "complete splash",
 			splash: NewSplash(
@@ -152,12 +182,15 @@ func TestSplash_String(t *testing.T) {
 }
 
 func TestSplash_Display(t *testing.T) {
-	// We can't easily test the output to stdout, but we can at least make sure
-	// the function doesn't panic
-	s := NewSplash()
-	assert.NotPanics(t, func() {
-		s.Display()
-	})
+	// Arrange
+	var buf bytes.Buffer
+	s := NewSplash(WithWriter(&buf), WithGRPCAddress(":50051"))
+
+	// Act
+	s.Display()
+
+	// Assert
+	assert.Equal(t, s.String(), buf.String())
 }
 
 func TestWithGRPCAddress(t *testing.T) {
@@ -234,3 +267,29 @@ func TestWithFeature(t *testing.T) {
 	// Assert
 	assert.Equal(t, []string{"Feature 1", "Feature 2"}, s.features)
 }
+
+func TestSplash_Log(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	s := NewSplash(
+		WithGRPCAddress(":50051"),
+		WithHTTPAddress(":8081"),
+		WithServices("pkg.v1.GreeterService"),
+		WithRouteCount(2),
+		WithFeature("Health Checks"),
+	)
+
+	// Act
+	s.Log(context.Background(), logger)
+
+	// Assert - a single JSON record carrying the splash information
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "startup summary", record["msg"])
+	assert.Equal(t, map[string]any{"grpc": ":50051", "http": ":8081"}, record["endpoints"])
+	assert.Equal(t, []any{"pkg.v1.GreeterService"}, record["services"])
+	assert.InDelta(t, 2, record["routes"], 0)
+	assert.Equal(t, []any{"Health Checks"}, record["features"])
+	assert.NotContains(t, record, "runtime")
+}
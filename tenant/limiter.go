@@ -0,0 +1,86 @@
+package tenant
+
+import (
+	"sync"
+	"time"
+)
+
+// maxBuckets is the number of tenant buckets above which full buckets are dropped, so
+// requests naming arbitrary tenants cannot grow the limiter without bound
+const maxBuckets = 10000
+
+// limit is a token bucket rate; a zero rate means unlimited
+type limit struct {
+	rps   float64
+	burst int
+}
+
+// bucket holds the tokens left to a tenant
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// limiter rate limits tenants with a token bucket each
+type limiter struct {
+	rate    limit
+	tenants map[string]limit
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+// newLimiter creates a limiter that lets every tenant through until rates are set
+func newLimiter() *limiter {
+	return &limiter{
+		tenants: map[string]limit{},
+		buckets: map[string]*bucket{},
+		now:     time.Now,
+	}
+}
+
+// allow takes a token from the bucket of the tenant, reporting whether one was left
+func (l *limiter) allow(id string) bool {
+	rate, ok := l.tenants[id]
+	if !ok {
+		rate = l.rate
+	}
+	if rate.rps <= 0 {
+		return true
+	}
+	burst := float64(max(rate.burst, 1))
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	b, ok := l.buckets[id]
+	if !ok {
+		if len(l.buckets) >= maxBuckets {
+			l.sweep(now)
+		}
+		b = &bucket{tokens: burst, last: now}
+		l.buckets[id] = b
+	}
+	b.tokens = min(burst, b.tokens+now.Sub(b.last).Seconds()*rate.rps)
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// sweep drops the buckets that have refilled, as new ones start full anyway
+func (l *limiter) sweep(now time.Time) {
+	for id, b := range l.buckets {
+		rate, ok := l.tenants[id]
+		if !ok {
+			rate = l.rate
+		}
+		if b.tokens+now.Sub(b.last).Seconds()*rate.rps >= float64(max(rate.burst, 1)) {
+			delete(l.buckets, id)
+		}
+	}
+}
@@ -0,0 +1,345 @@
+// Package tenant resolves the tenant of gRPC calls and HTTP requests from a header, the
+// subdomain of the host or a JWT claim. The tenant is stored in the context, added to
+// the active span and to logs written through LogHandler, counted per tenant and
+// optionally rate limited per tenant.
+package tenant
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/legrch/netgex/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Tenant is the tenant a request is made for
+type Tenant struct {
+	ID string
+}
+
+// tenantKey is the key under which the tenant is stored in a context
+type tenantKey struct{}
+
+// NewContext returns a copy of ctx carrying t
+func NewContext(ctx context.Context, t Tenant) context.Context {
+	return context.WithValue(ctx, tenantKey{}, t)
+}
+
+// FromContext returns the tenant of ctx, reporting whether one was resolved
+func FromContext(ctx context.Context) (Tenant, bool) {
+	t, ok := ctx.Value(tenantKey{}).(Tenant)
+	return t, ok
+}
+
+// Source extracts the tenant ID from the metadata of a request, returning an empty
+// string when the request does not name one. HTTP headers are passed as lowercase
+// metadata keys, with the host under :authority.
+type Source func(md metadata.MD) string
+
+// FromHeader reads the tenant ID from a header, e.g. X-Tenant-ID
+func FromHeader(name string) Source {
+	name = strings.ToLower(name)
+	return func(md metadata.MD) string {
+		return first(md, name)
+	}
+}
+
+// FromSubdomain reads the tenant ID from the subdomain of domain in the host, e.g. acme
+// in acme.example.com. The host forwarded by the gateway takes precedence over the
+// authority of the gRPC call.
+func FromSubdomain(domain string) Source {
+	suffix := "." + strings.ToLower(strings.Trim(domain, "."))
+	return func(md metadata.MD) string {
+		host := first(md, "x-forwarded-host")
+		if host == "" {
+			host = first(md, ":authority")
+		}
+		host = strings.ToLower(host)
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		sub, ok := strings.CutSuffix(host, suffix)
+		if !ok || sub == "" || strings.Contains(sub, ".") {
+			return ""
+		}
+		return sub
+	}
+}
+
+// FromJWTClaim reads the tenant ID from a string claim of the bearer token in the
+// Authorization header. The token signature is not verified: the claim is only trusted
+// when an authentication interceptor in front of the resolver verifies tokens.
+func FromJWTClaim(claim string) Source {
+	return func(md metadata.MD) string {
+		token, ok := strings.CutPrefix(first(md, "authorization"), "Bearer ")
+		if !ok {
+			return ""
+		}
+		parts := strings.Split(token, ".")
+		if len(parts) != 3 {
+			return ""
+		}
+		payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+		if err != nil {
+			return ""
+		}
+		var claims map[string]any
+		if json.Unmarshal(payload, &claims) != nil {
+			return ""
+		}
+		id, _ := claims[claim].(string)
+		return id
+	}
+}
+
+// first returns the first value of key in md
+func first(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return strings.TrimSpace(values[0])
+	}
+	return ""
+}
+
+// Option configures a Resolver
+type Option func(*Resolver)
+
+// WithSources sets where the tenant is read from; the first source naming a tenant wins.
+// Without sources the tenant is read from the X-Tenant-ID header.
+func WithSources(sources ...Source) Option {
+	return func(r *Resolver) {
+		r.sources = append(r.sources, sources...)
+	}
+}
+
+// WithHeaderForwarding makes the gateway forward the headers to the gRPC server, so a
+// FromHeader source sees them on calls made through the gateway. X-Tenant-ID is always
+// forwarded.
+func WithHeaderForwarding(headers ...string) Option {
+	return func(r *Resolver) {
+		for _, h := range headers {
+			r.headers[http.CanonicalHeaderKey(h)] = true
+		}
+	}
+}
+
+// WithRequired rejects requests without a tenant with InvalidArgument, or 400 Bad
+// Request over HTTP
+func WithRequired() Option {
+	return func(r *Resolver) {
+		r.required = true
+	}
+}
+
+// WithRateLimit limits every tenant to rps requests per second with bursts of burst
+func WithRateLimit(rps float64, burst int) Option {
+	return func(r *Resolver) {
+		r.limiter.rate = limit{rps: rps, burst: burst}
+	}
+}
+
+// WithTenantRateLimit overrides the rate limit of one tenant
+func WithTenantRateLimit(id string, rps float64, burst int) Option {
+	return func(r *Resolver) {
+		r.limiter.tenants[id] = limit{rps: rps, burst: burst}
+	}
+}
+
+// WithMetrics counts requests per tenant in the tenant_requests_total metric of the
+// namespace. Tenant IDs become label values, so use it only when the set of tenants is
+// bounded, e.g. with sources trusted after authentication.
+func WithMetrics(namespace string) Option {
+	return func(r *Resolver) {
+		r.requests = requestsCounter(namespace)
+	}
+}
+
+// Resolver resolves the tenant of requests
+type Resolver struct {
+	sources  []Source
+	headers  map[string]bool
+	required bool
+	limiter  *limiter
+	requests *prometheus.CounterVec
+}
+
+// New creates a resolver from opts
+func New(opts ...Option) *Resolver {
+	r := &Resolver{
+		headers: map[string]bool{"X-Tenant-Id": true},
+		limiter: newLimiter(),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if len(r.sources) == 0 {
+		r.sources = []Source{FromHeader("X-Tenant-ID")}
+	}
+	return r
+}
+
+// Resolve returns the tenant named by the request metadata, reporting whether one was
+// found
+func (r *Resolver) Resolve(md metadata.MD) (Tenant, bool) {
+	for _, source := range r.sources {
+		if id := source(md); id != "" {
+			return Tenant{ID: id}, true
+		}
+	}
+	return Tenant{}, false
+}
+
+// errMissing and errLimited are the reasons a request is rejected
+var (
+	errMissing = status.Error(codes.InvalidArgument, "tenant is required")
+	errLimited = status.Error(codes.ResourceExhausted, "tenant rate limit exceeded")
+)
+
+// admit resolves the tenant of a request, rejecting it when the tenant is missing but
+// required or over its rate limit, and returns the context carrying the tenant
+func (r *Resolver) admit(ctx context.Context, md metadata.MD) (context.Context, error) {
+	t, ok := r.Resolve(md)
+	if !ok {
+		if r.required {
+			return ctx, errMissing
+		}
+		return ctx, nil
+	}
+
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("tenant.id", t.ID))
+	if !r.limiter.allow(t.ID) {
+		r.count(t.ID, "rate_limited")
+		return ctx, errLimited
+	}
+	r.count(t.ID, "accepted")
+	return NewContext(ctx, t), nil
+}
+
+// count records a request of a tenant
+func (r *Resolver) count(id, outcome string) {
+	if r.requests != nil {
+		r.requests.WithLabelValues(id, outcome).Inc()
+	}
+}
+
+// UnaryServerInterceptor resolves the tenant of unary calls
+func (r *Resolver) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		ctx, err := r.admit(ctx, md)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor resolves the tenant of streams
+func (r *Resolver) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		md, _ := metadata.FromIncomingContext(ss.Context())
+		ctx, err := r.admit(ss.Context(), md)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// Middleware resolves the tenant of HTTP requests, for handlers not backed by gRPC
+// methods; calls through the gateway are resolved by the gRPC interceptors
+func (r *Resolver) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		md := metadata.MD{}
+		for name, values := range req.Header {
+			md.Append(name, values...)
+		}
+		md.Set(":authority", req.Host)
+
+		ctx, err := r.admit(req.Context(), md)
+		switch {
+		case err == nil:
+			next.ServeHTTP(w, req.WithContext(ctx))
+		case errors.Is(err, errLimited):
+			http.Error(w, "tenant rate limit exceeded", http.StatusTooManyRequests)
+		default:
+			http.Error(w, "tenant is required", http.StatusBadRequest)
+		}
+	})
+}
+
+// HeaderMatcher extends the incoming header matcher of the gateway, runtime.DefaultHeaderMatcher
+// when nil, to forward the tenant headers under their own name
+func (r *Resolver) HeaderMatcher(next runtime.HeaderMatcherFunc) runtime.HeaderMatcherFunc {
+	if next == nil {
+		next = runtime.DefaultHeaderMatcher
+	}
+	return func(key string) (string, bool) {
+		if r.headers[http.CanonicalHeaderKey(key)] {
+			return strings.ToLower(key), true
+		}
+		return next(key)
+	}
+}
+
+// serverStream overrides the context of a server stream
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// LogHandler wraps h to add a tenant attribute to records logged with a context carrying
+// a tenant, e.g. through slog.InfoContext
+func LogHandler(h slog.Handler) slog.Handler {
+	return &logHandler{Handler: h}
+}
+
+// logHandler adds the tenant of the context to log records
+type logHandler struct {
+	slog.Handler
+}
+
+func (h *logHandler) Handle(ctx context.Context, record slog.Record) error {
+	if t, ok := FromContext(ctx); ok {
+		record.AddAttrs(slog.String("tenant", t.ID))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *logHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &logHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *logHandler) WithGroup(name string) slog.Handler {
+	return &logHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+// requestsCounter returns the tenant requests counter of the namespace, registering it
+// unless already registered
+func requestsCounter(namespace string) *prometheus.CounterVec {
+	counter := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tenant_requests_total",
+			Help:      "Total number of requests by tenant and outcome: accepted or rate_limited",
+		},
+		[]string{"tenant", "outcome"},
+	)
+	return metrics.Register(counter)
+}
@@ -0,0 +1,236 @@
+package tenant
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// token returns an unsigned JWT carrying the claims
+func token(claims string) string {
+	return "Bearer e30." + base64.RawURLEncoding.EncodeToString([]byte(claims)) + ".sig"
+}
+
+func TestResolver_Resolve(t *testing.T) {
+	tests := []struct {
+		name    string
+		sources []Source
+		md      metadata.MD
+		want    string
+	}{
+		{name: "default header", md: metadata.Pairs("x-tenant-id", "acme"), want: "acme"},
+		{name: "missing", md: metadata.MD{}},
+		{
+			name:    "subdomain",
+			sources: []Source{FromSubdomain("example.com")},
+			md:      metadata.Pairs(":authority", "acme.example.com:8080"),
+			want:    "acme",
+		},
+		{
+			name:    "forwarded host wins",
+			sources: []Source{FromSubdomain("example.com")},
+			md:      metadata.Pairs(":authority", "localhost:9090", "x-forwarded-host", "acme.example.com"),
+			want:    "acme",
+		},
+		{
+			name:    "nested subdomain",
+			sources: []Source{FromSubdomain("example.com")},
+			md:      metadata.Pairs(":authority", "a.b.example.com"),
+		},
+		{
+			name:    "jwt claim",
+			sources: []Source{FromJWTClaim("tenant_id")},
+			md:      metadata.Pairs("authorization", token(`{"sub":"u1","tenant_id":"acme"}`)),
+			want:    "acme",
+		},
+		{
+			name:    "jwt without claim",
+			sources: []Source{FromJWTClaim("tenant_id")},
+			md:      metadata.Pairs("authorization", token(`{"sub":"u1"}`)),
+		},
+		{
+			name:    "first source wins",
+			sources: []Source{FromHeader("X-Org"), FromJWTClaim("tenant_id")},
+			md:      metadata.Pairs("x-org", "globex", "authorization", token(`{"tenant_id":"acme"}`)),
+			want:    "globex",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			r := New(WithSources(tt.sources...))
+
+			// Act
+			got, ok := r.Resolve(tt.md)
+
+			// Assert
+			assert.Equal(t, tt.want != "", ok)
+			assert.Equal(t, tt.want, got.ID)
+		})
+	}
+}
+
+// healthServer records the tenant of every call
+type healthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+	tenants chan string
+}
+
+func (h *healthServer) Check(ctx context.Context, _ *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	tn, _ := FromContext(ctx)
+	h.tenants <- tn.ID
+	return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
+}
+
+func TestResolver_UnaryServerInterceptor(t *testing.T) {
+	// Arrange
+	r := New(WithRequired(), WithRateLimit(1, 1), WithTenantRateLimit("vip", 100, 100), WithMetrics("tenanttest"))
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(grpc.UnaryInterceptor(r.UnaryServerInterceptor()))
+	health := &healthServer{tenants: make(chan string, 10)}
+	grpc_health_v1.RegisterHealthServer(srv, health)
+	go func() { _ = srv.Serve(lis) }()
+	defer srv.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	defer conn.Close()
+	client := grpc_health_v1.NewHealthClient(conn)
+	check := func(id string) error {
+		ctx := context.Background()
+		if id != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, "x-tenant-id", id)
+		}
+		_, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+		return err
+	}
+
+	// Act
+	missingErr := check("")
+	firstErr := check("acme")
+	limitedErr := check("acme")
+	vipErr := check("vip")
+
+	// Assert
+	assert.Equal(t, codes.InvalidArgument, status.Code(missingErr))
+	require.NoError(t, firstErr)
+	assert.Equal(t, "acme", <-health.tenants)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(limitedErr))
+	require.NoError(t, vipErr)
+	assert.Equal(t, "vip", <-health.tenants)
+	assert.Equal(t, 1.0, testutil.ToFloat64(requestsCounter("tenanttest").WithLabelValues("acme", "accepted")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(requestsCounter("tenanttest").WithLabelValues("acme", "rate_limited")))
+}
+
+func TestResolver_Middleware(t *testing.T) {
+	// Arrange
+	r := New(WithSources(FromSubdomain("example.com")), WithRequired())
+	var got string
+	handler := r.Middleware(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+		tn, _ := FromContext(req.Context())
+		got = tn.ID
+	}))
+
+	// Act
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://acme.example.com/v1/items", nil))
+	missing := httptest.NewRecorder()
+	handler.ServeHTTP(missing, httptest.NewRequest(http.MethodGet, "http://example.com/v1/items", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "acme", got)
+	assert.Equal(t, http.StatusBadRequest, missing.Code)
+}
+
+func TestResolver_HeaderMatcher(t *testing.T) {
+	// Arrange
+	matcher := New(WithHeaderForwarding("X-Org")).HeaderMatcher(nil)
+
+	tests := []struct {
+		header string
+		want   string
+		wantOK bool
+	}{
+		{header: "X-Tenant-Id", want: "x-tenant-id", wantOK: true},
+		{header: "x-org", want: "x-org", wantOK: true},
+		{header: "Accept", want: "grpcgateway-Accept", wantOK: true},
+		{header: "X-Other"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			// Act
+			got, ok := matcher(tt.header)
+
+			// Assert
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLimiter_Refill(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	l := newLimiter()
+	l.rate = limit{rps: 2, burst: 2}
+	l.now = func() time.Time { return now }
+
+	// Act
+	first, second, third := l.allow("acme"), l.allow("acme"), l.allow("acme")
+	now = now.Add(500 * time.Millisecond)
+	refilled := l.allow("acme")
+
+	// Assert
+	assert.True(t, first)
+	assert.True(t, second)
+	assert.False(t, third)
+	assert.True(t, refilled)
+}
+
+func TestLogHandler(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	logger := slog.New(LogHandler(slog.NewTextHandler(&buf, nil)))
+	ctx := NewContext(context.Background(), Tenant{ID: "acme"})
+
+	// Act
+	logger.InfoContext(ctx, "order created")
+
+	// Assert
+	assert.Contains(t, buf.String(), "tenant=acme")
+}
+
+func TestWithMetrics_SharedBetweenResolvers(t *testing.T) {
+	// Act
+	var first, second *Resolver
+	assert.NotPanics(t, func() {
+		first = New(WithMetrics("tenantshared"))
+		second = New(WithMetrics("tenantshared"))
+	})
+
+	// Assert
+	assert.Same(t, first.requests, second.requests)
+}
@@ -0,0 +1,102 @@
+package twirp
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Twirp error codes; the gRPC codes map to them one to one, plus codes for requests
+// that could not be routed or decoded
+const (
+	codeCanceled           = "canceled"
+	codeUnknown            = "unknown"
+	codeInvalidArgument    = "invalid_argument"
+	codeMalformed          = "malformed"
+	codeDeadlineExceeded   = "deadline_exceeded"
+	codeNotFound           = "not_found"
+	codeBadRoute           = "bad_route"
+	codeAlreadyExists      = "already_exists"
+	codePermissionDenied   = "permission_denied"
+	codeUnauthenticated    = "unauthenticated"
+	codeResourceExhausted  = "resource_exhausted"
+	codeFailedPrecondition = "failed_precondition"
+	codeAborted            = "aborted"
+	codeOutOfRange         = "out_of_range"
+	codeUnimplemented      = "unimplemented"
+	codeInternal           = "internal"
+	codeUnavailable        = "unavailable"
+	codeDataLoss           = "data_loss"
+)
+
+// grpcCodes maps gRPC codes to Twirp codes
+var grpcCodes = map[codes.Code]string{
+	codes.Canceled:           codeCanceled,
+	codes.Unknown:            codeUnknown,
+	codes.InvalidArgument:    codeInvalidArgument,
+	codes.DeadlineExceeded:   codeDeadlineExceeded,
+	codes.NotFound:           codeNotFound,
+	codes.AlreadyExists:      codeAlreadyExists,
+	codes.PermissionDenied:   codePermissionDenied,
+	codes.ResourceExhausted:  codeResourceExhausted,
+	codes.FailedPrecondition: codeFailedPrecondition,
+	codes.Aborted:            codeAborted,
+	codes.OutOfRange:         codeOutOfRange,
+	codes.Unimplemented:      codeUnimplemented,
+	codes.Internal:           codeInternal,
+	codes.Unavailable:        codeUnavailable,
+	codes.DataLoss:           codeDataLoss,
+	codes.Unauthenticated:    codeUnauthenticated,
+}
+
+// httpStatuses are the HTTP statuses of the Twirp codes, as Twirp servers answer them
+var httpStatuses = map[string]int{
+	codeCanceled:           http.StatusRequestTimeout,
+	codeUnknown:            http.StatusInternalServerError,
+	codeInvalidArgument:    http.StatusBadRequest,
+	codeMalformed:          http.StatusBadRequest,
+	codeDeadlineExceeded:   http.StatusRequestTimeout,
+	codeNotFound:           http.StatusNotFound,
+	codeBadRoute:           http.StatusNotFound,
+	codeAlreadyExists:      http.StatusConflict,
+	codePermissionDenied:   http.StatusForbidden,
+	codeUnauthenticated:    http.StatusUnauthorized,
+	codeResourceExhausted:  http.StatusTooManyRequests,
+	codeFailedPrecondition: http.StatusPreconditionFailed,
+	codeAborted:            http.StatusConflict,
+	codeOutOfRange:         http.StatusBadRequest,
+	codeUnimplemented:      http.StatusNotImplemented,
+	codeInternal:           http.StatusInternalServerError,
+	codeUnavailable:        http.StatusServiceUnavailable,
+	codeDataLoss:           http.StatusInternalServerError,
+}
+
+// twirpError is the JSON body of Twirp errors
+type twirpError struct {
+	Code string            `json:"code"`
+	Msg  string            `json:"msg"`
+	Meta map[string]string `json:"meta,omitempty"`
+}
+
+func newError(code, msg string) *twirpError {
+	return &twirpError{Code: code, Msg: msg}
+}
+
+// fromStatus returns the Twirp error of a gRPC status, internal for codes without one
+func fromStatus(st *status.Status) *twirpError {
+	code, ok := grpcCodes[st.Code()]
+	if !ok {
+		code = codeInternal
+	}
+	return newError(code, st.Message())
+}
+
+// writeError writes a Twirp error with the HTTP status of its code; errors are always
+// JSON, whatever the encoding of the request
+func writeError(w http.ResponseWriter, err *twirpError) {
+	w.Header().Set("Content-Type", contentTypeJSON)
+	w.WriteHeader(httpStatuses[err.Code])
+	_ = json.NewEncoder(w).Encode(err)
+}
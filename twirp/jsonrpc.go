@@ -0,0 +1,171 @@
+package twirp
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// JSON-RPC 2.0 error codes; errors of the methods are server errors with the Twirp
+// code of their status in the error data
+const (
+	jsonRPCParseError     = -32700
+	jsonRPCInvalidRequest = -32600
+	jsonRPCMethodNotFound = -32601
+	jsonRPCInvalidParams  = -32602
+	jsonRPCInternalError  = -32603
+	jsonRPCServerError    = -32000
+)
+
+// jsonRPCRequest is a JSON-RPC 2.0 request; requests without an id are notifications,
+// executed without a response
+type jsonRPCRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+	ID      json.RawMessage `json:"id"`
+}
+
+type jsonRPCResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *jsonRPCError   `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+type jsonRPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+// jsonRPCHandler serves the methods over JSON-RPC 2.0, by the name pkg.Service/Method
+type jsonRPCHandler struct {
+	conn    *grpc.ClientConn
+	mux     *runtime.ServeMux
+	methods map[string]*method
+}
+
+// serve answers a request or a batch of requests; batches are answered with the
+// responses of their requests that are not notifications, in order
+func (h *jsonRPCHandler) serve(w http.ResponseWriter, req *http.Request, _ map[string]string) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, req.Body, maxRequestSize))
+	if err != nil {
+		writeJSONRPC(w, errorResponse(nil, jsonRPCParseError, "Parse error: "+err.Error()))
+		return
+	}
+	body = bytes.TrimSpace(body)
+
+	if len(body) > 0 && body[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(body, &batch); err != nil {
+			writeJSONRPC(w, errorResponse(nil, jsonRPCParseError, "Parse error"))
+			return
+		}
+		if len(batch) == 0 {
+			writeJSONRPC(w, errorResponse(nil, jsonRPCInvalidRequest, "Invalid Request: empty batch"))
+			return
+		}
+		var responses []*jsonRPCResponse
+		for _, raw := range batch {
+			if resp := h.call(req, raw); resp != nil {
+				responses = append(responses, resp)
+			}
+		}
+		if len(responses) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeJSONRPC(w, responses)
+		return
+	}
+
+	if !json.Valid(body) {
+		writeJSONRPC(w, errorResponse(nil, jsonRPCParseError, "Parse error"))
+		return
+	}
+	resp := h.call(req, body)
+	if resp == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSONRPC(w, resp)
+}
+
+// call executes a request and returns its response, or nil for notifications
+func (h *jsonRPCHandler) call(req *http.Request, raw json.RawMessage) *jsonRPCResponse {
+	var r jsonRPCRequest
+	if err := json.Unmarshal(raw, &r); err != nil || r.JSONRPC != "2.0" || r.Method == "" {
+		return errorResponse(r.ID, jsonRPCInvalidRequest, "Invalid Request")
+	}
+	resp := h.execute(req, &r)
+	if len(r.ID) == 0 {
+		return nil
+	}
+	return resp
+}
+
+// execute calls the method of a request with its params as the request message
+func (h *jsonRPCHandler) execute(req *http.Request, r *jsonRPCRequest) *jsonRPCResponse {
+	m, ok := h.methods[methodName(r.Method)]
+	if !ok {
+		return errorResponse(r.ID, jsonRPCMethodNotFound, "Method not found")
+	}
+
+	in := m.input.New().Interface()
+	params := bytes.TrimSpace(r.Params)
+	if len(params) > 0 && !bytes.Equal(params, []byte("null")) {
+		if params[0] != '{' {
+			return errorResponse(r.ID, jsonRPCInvalidParams, "Invalid params: params must be an object")
+		}
+		if err := jsonUnmarshal.Unmarshal(params, in); err != nil {
+			return errorResponse(r.ID, jsonRPCInvalidParams, "Invalid params: "+err.Error())
+		}
+	}
+
+	out, err := invoke(req.Context(), h.conn, h.mux, req, m, in)
+	if err != nil {
+		twerr := fromStatus(status.Convert(err))
+		resp := errorResponse(r.ID, jsonRPCServerError, twerr.Msg)
+		resp.Error.Data = map[string]string{"code": twerr.Code}
+		return resp
+	}
+	result, err := jsonMarshal.Marshal(out)
+	if err != nil {
+		return errorResponse(r.ID, jsonRPCInternalError, "Internal error: "+err.Error())
+	}
+	return &jsonRPCResponse{JSONRPC: "2.0", Result: result, ID: r.ID}
+}
+
+// methodName turns the method of a request, pkg.Service/Method, /pkg.Service/Method or
+// pkg.Service.Method, into its key in the methods
+func methodName(name string) string {
+	name = strings.TrimPrefix(name, "/")
+	if strings.Contains(name, "/") {
+		return name
+	}
+	i := strings.LastIndex(name, ".")
+	if i < 0 {
+		return name
+	}
+	return name[:i] + "/" + name[i+1:]
+}
+
+func errorResponse(id json.RawMessage, code int, msg string) *jsonRPCResponse {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	return &jsonRPCResponse{JSONRPC: "2.0", Error: &jsonRPCError{Code: code, Message: msg}, ID: id}
+}
+
+func writeJSONRPC(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", contentTypeJSON)
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,128 @@
+package twirp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONRPCHandler_Serve(t *testing.T) {
+	// Arrange
+	mux := serveTwirp(t, WithJSONRPC("/jsonrpc"))
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "request",
+			body:       `{"jsonrpc":"2.0","method":"grpc.health.v1.Health/Check","params":{"service":"orders"},"id":1}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"jsonrpc":"2.0","result":{"status":"SERVING"},"id":1}`,
+		},
+		{
+			name:       "dotted method name",
+			body:       `{"jsonrpc":"2.0","method":"grpc.health.v1.Health.Check","params":{"service":"orders"},"id":"a"}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"jsonrpc":"2.0","result":{"status":"SERVING"},"id":"a"}`,
+		},
+		{
+			name:       "error status",
+			body:       `{"jsonrpc":"2.0","method":"grpc.health.v1.Health/Check","params":{"service":"payments"},"id":1}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"jsonrpc":"2.0","error":{"code":-32000,"message":"unknown service","data":{"code":"not_found"}},"id":1}`,
+		},
+		{
+			name:       "unknown method",
+			body:       `{"jsonrpc":"2.0","method":"grpc.health.v1.Health/Watch","id":1}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"jsonrpc":"2.0","error":{"code":-32601,"message":"Method not found"},"id":1}`,
+		},
+		{
+			name:       "positional params",
+			body:       `{"jsonrpc":"2.0","method":"grpc.health.v1.Health/Check","params":["orders"],"id":1}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params: params must be an object"},"id":1}`,
+		},
+		{
+			name:       "invalid request",
+			body:       `{"jsonrpc":"1.0","method":"grpc.health.v1.Health/Check","id":1}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":1}`,
+		},
+		{
+			name:       "parse error",
+			body:       `{"jsonrpc":`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"jsonrpc":"2.0","error":{"code":-32700,"message":"Parse error"},"id":null}`,
+		},
+		{
+			name:       "notification",
+			body:       `{"jsonrpc":"2.0","method":"grpc.health.v1.Health/Check","params":{"service":"orders"}}`,
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name: "batch",
+			body: `[
+				{"jsonrpc":"2.0","method":"grpc.health.v1.Health/Check","params":{"service":"orders"},"id":1},
+				{"jsonrpc":"2.0","method":"grpc.health.v1.Health/Check","params":{"service":"orders"}},
+				{"jsonrpc":"2.0","method":"unknown","id":2}
+			]`,
+			wantStatus: http.StatusOK,
+			wantBody: `[
+				{"jsonrpc":"2.0","result":{"status":"SERVING"},"id":1},
+				{"jsonrpc":"2.0","error":{"code":-32601,"message":"Method not found"},"id":2}
+			]`,
+		},
+		{
+			name:       "empty batch",
+			body:       `[]`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request: empty batch"},"id":null}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/jsonrpc", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+
+			// Act
+			mux.ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+			if tt.wantBody == "" {
+				assert.Empty(t, rec.Body.String())
+				return
+			}
+			assert.JSONEq(t, tt.wantBody, rec.Body.String())
+		})
+	}
+}
+
+func TestMethodName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "pkg.Service/Method", want: "pkg.Service/Method"},
+		{name: "/pkg.Service/Method", want: "pkg.Service/Method"},
+		{name: "pkg.Service.Method", want: "pkg.Service/Method"},
+		{name: "Method", want: "Method"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := methodName(tt.name)
+
+			// Assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,234 @@
+// Package twirp serves the registered gRPC services over Twirp-style JSON and protobuf
+// endpoints, and optionally JSON-RPC 2.0, on the gateway. It eases the migration of
+// clients that cannot adopt the REST mapping of grpc-gateway.
+//
+//	server.WithServices(
+//		orders.New(),
+//		twirp.New(twirp.WithServices(orders.New()), twirp.WithJSONRPC("/jsonrpc")),
+//	)
+//
+// Every unary method is served on POST /twirp/pkg.Service/Method, taking the request as
+// application/json or application/protobuf and answering in the same encoding. JSON uses
+// the proto field names and emits unpopulated fields, as Twirp servers do. Errors are
+// Twirp errors, {"code": "not_found", "msg": "..."}, with the HTTP status of their code.
+//
+// Methods are called on the gateway's gRPC endpoint, with the HTTP request headers as
+// metadata like the generated gateway handlers. Streaming methods are not served.
+package twirp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/legrch/netgex/internal/descriptors"
+	"github.com/legrch/netgex/service"
+)
+
+// DefaultPrefix is the path prefix of the Twirp routes by default
+const DefaultPrefix = "/twirp"
+
+// maxRequestSize bounds the body of requests
+const maxRequestSize = 4 << 20
+
+// The encodings of requests and responses
+const (
+	contentTypeJSON     = "application/json"
+	contentTypeProtobuf = "application/protobuf"
+)
+
+var (
+	jsonMarshal   = protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}
+	jsonUnmarshal = protojson.UnmarshalOptions{DiscardUnknown: true}
+)
+
+// Option configures a Registrar
+type Option func(*Registrar)
+
+// WithServices sets the registrars whose gRPC services are served
+func WithServices(services ...service.Registrar) Option {
+	return func(r *Registrar) {
+		r.services = append(r.services, services...)
+	}
+}
+
+// WithPrefix sets the path prefix of the Twirp routes, /twirp by default
+func WithPrefix(prefix string) Option {
+	return func(r *Registrar) {
+		r.prefix = prefix
+	}
+}
+
+// WithJSONRPC also serves the methods as JSON-RPC 2.0 on path, named pkg.Service/Method
+func WithJSONRPC(path string) Option {
+	return func(r *Registrar) {
+		r.jsonRPCPath = path
+	}
+}
+
+// Registrar registers the Twirp routes on the gateway
+type Registrar struct {
+	descriptors.GatewayOnly
+	services    []service.Registrar
+	prefix      string
+	jsonRPCPath string
+}
+
+// New creates a registrar serving the services over Twirp
+func New(opts ...Option) *Registrar {
+	r := &Registrar{prefix: DefaultPrefix}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Name returns the name of the registrar
+func (*Registrar) Name() string {
+	return "twirp"
+}
+
+// RegisterHTTP registers a route per unary method of the services, and the JSON-RPC
+// endpoint when enabled, calling the methods on endpoint
+func (r *Registrar) RegisterHTTP(ctx context.Context, mux *runtime.ServeMux, endpoint string, opts []grpc.DialOption) (err error) {
+	conn, err := grpc.NewClient(endpoint, opts...)
+	if err != nil {
+		return fmt.Errorf("twirp: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = conn.Close()
+			return
+		}
+		go func() {
+			<-ctx.Done()
+			_ = conn.Close()
+		}()
+	}()
+
+	methods := r.methods()
+	prefix := strings.TrimSuffix(r.prefix, "/")
+	for _, m := range methods {
+		h := &handler{conn: conn, mux: mux, method: m}
+		pattern := prefix + m.rpc
+		if err := mux.HandlePath(http.MethodPost, pattern, h.serve); err != nil {
+			return fmt.Errorf("twirp: POST %s: %w", pattern, err)
+		}
+	}
+
+	if r.jsonRPCPath != "" {
+		h := &jsonRPCHandler{conn: conn, mux: mux, methods: map[string]*method{}}
+		for _, m := range methods {
+			h.methods[strings.TrimPrefix(m.rpc, "/")] = m
+		}
+		if err := mux.HandlePath(http.MethodPost, r.jsonRPCPath, h.serve); err != nil {
+			return fmt.Errorf("twirp: POST %s: %w", r.jsonRPCPath, err)
+		}
+	}
+	return nil
+}
+
+// method is a unary method of a service
+type method struct {
+	rpc    string
+	input  protoreflect.MessageType
+	output protoreflect.MessageType
+}
+
+// methods returns the unary methods of the services of the registrars
+func (r *Registrar) methods() []*method {
+	var methods []*method
+	for _, sd := range descriptors.Services(r.services) {
+		mds := sd.Methods()
+		for i := range mds.Len() {
+			md := mds.Get(i)
+			if md.IsStreamingClient() || md.IsStreamingServer() {
+				continue
+			}
+			methods = append(methods, &method{
+				rpc:    "/" + string(sd.FullName()) + "/" + string(md.Name()),
+				input:  descriptors.MessageType(md.Input()),
+				output: descriptors.MessageType(md.Output()),
+			})
+		}
+	}
+	sort.Slice(methods, func(i, j int) bool { return methods[i].rpc < methods[j].rpc })
+	return methods
+}
+
+// handler serves a method over Twirp
+type handler struct {
+	conn   *grpc.ClientConn
+	mux    *runtime.ServeMux
+	method *method
+}
+
+// serve decodes the request in its encoding, calls the method and writes its response
+// in the same encoding
+func (h *handler) serve(w http.ResponseWriter, req *http.Request, _ map[string]string) {
+	contentType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if contentType != contentTypeJSON && contentType != contentTypeProtobuf {
+		writeError(w, newError(codeBadRoute, fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))))
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, req.Body, maxRequestSize))
+	if err != nil {
+		writeError(w, newError(codeMalformed, "failed to read request body: "+err.Error()))
+		return
+	}
+	in := h.method.input.New().Interface()
+	if contentType == contentTypeJSON {
+		err = jsonUnmarshal.Unmarshal(body, in)
+	} else {
+		err = proto.Unmarshal(body, in)
+	}
+	if err != nil {
+		writeError(w, newError(codeMalformed, "the request could not be decoded: "+err.Error()))
+		return
+	}
+
+	out, err := invoke(req.Context(), h.conn, h.mux, req, h.method, in)
+	if err != nil {
+		writeError(w, fromStatus(status.Convert(err)))
+		return
+	}
+	var resp []byte
+	if contentType == contentTypeJSON {
+		resp, err = jsonMarshal.Marshal(out)
+	} else {
+		resp, err = proto.Marshal(out)
+	}
+	if err != nil {
+		writeError(w, newError(codeInternal, "failed to encode the response: "+err.Error()))
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(resp)
+}
+
+// invoke calls a method, forwarding the headers of the HTTP request as metadata
+func invoke(ctx context.Context, conn *grpc.ClientConn, mux *runtime.ServeMux, req *http.Request, m *method, in proto.Message) (proto.Message, error) {
+	ctx, err := runtime.AnnotateContext(ctx, mux, req, m.rpc)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	out := m.output.New().Interface()
+	if err := conn.Invoke(ctx, m.rpc, in, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,151 @@
+package twirp
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+// healthService registers the health service, reporting orders as serving
+type healthService struct{}
+
+func (healthService) RegisterGRPC(srv *grpc.Server) {
+	hs := health.NewServer()
+	hs.SetServingStatus("orders", grpc_health_v1.HealthCheckResponse_SERVING)
+	grpc_health_v1.RegisterHealthServer(srv, hs)
+}
+
+func (healthService) RegisterHTTP(context.Context, *runtime.ServeMux, string, []grpc.DialOption) error {
+	return nil
+}
+
+// serveTwirp serves the health service on an in-memory listener and returns the gateway
+// mux with the registrar's routes registered
+func serveTwirp(t *testing.T, opts ...Option) *runtime.ServeMux {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	healthService{}.RegisterGRPC(srv)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	mux := runtime.NewServeMux()
+	dialOpts := []grpc.DialOption{
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	}
+	registrar := New(append([]Option{WithServices(healthService{})}, opts...)...)
+	require.NoError(t, registrar.RegisterHTTP(ctx, mux, "passthrough:///bufconn", dialOpts))
+	return mux
+}
+
+func TestRegistrar_RegisterHTTP(t *testing.T) {
+	// Arrange
+	mux := serveTwirp(t)
+
+	tests := []struct {
+		name        string
+		target      string
+		contentType string
+		body        string
+		wantStatus  int
+		wantBody    string
+	}{
+		{
+			name:        "json",
+			target:      "/twirp/grpc.health.v1.Health/Check",
+			contentType: "application/json; charset=utf-8",
+			body:        `{"service":"orders","unknown":1}`,
+			wantStatus:  http.StatusOK,
+			wantBody:    `{"status":"SERVING"}`,
+		},
+		{
+			name:        "error status",
+			target:      "/twirp/grpc.health.v1.Health/Check",
+			contentType: "application/json",
+			body:        `{"service":"payments"}`,
+			wantStatus:  http.StatusNotFound,
+			wantBody:    `{"code":"not_found","msg":"unknown service"}`,
+		},
+		{
+			name:        "malformed body",
+			target:      "/twirp/grpc.health.v1.Health/Check",
+			contentType: "application/json",
+			body:        `{"service":`,
+			wantStatus:  http.StatusBadRequest,
+			wantBody:    `"code":"malformed"`,
+		},
+		{
+			name:        "unexpected content type",
+			target:      "/twirp/grpc.health.v1.Health/Check",
+			contentType: "text/plain",
+			body:        `service=orders`,
+			wantStatus:  http.StatusNotFound,
+			wantBody:    `"code":"bad_route"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			rec := httptest.NewRecorder()
+
+			// Act
+			mux.ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+			assert.Contains(t, rec.Body.String(), tt.wantBody)
+		})
+	}
+}
+
+func TestRegistrar_RegisterHTTP_Protobuf(t *testing.T) {
+	// Arrange
+	mux := serveTwirp(t, WithPrefix("/rpc/"))
+	body, err := proto.Marshal(&grpc_health_v1.HealthCheckRequest{Service: "orders"})
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/rpc/grpc.health.v1.Health/Check", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/protobuf")
+	rec := httptest.NewRecorder()
+
+	// Act
+	mux.ServeHTTP(rec, req)
+
+	// Assert
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "application/protobuf", rec.Header().Get("Content-Type"))
+	var resp grpc_health_v1.HealthCheckResponse
+	require.NoError(t, proto.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, resp.GetStatus())
+}
+
+func TestRegistrar_RegisterHTTP_StreamingMethods(t *testing.T) {
+	// Arrange
+	mux := serveTwirp(t)
+	req := httptest.NewRequest(http.MethodPost, "/twirp/grpc.health.v1.Health/Watch", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	// Act
+	mux.ServeHTTP(rec, req)
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,220 @@
+// Package webhook notifies chat channels and HTTP endpoints when a service fails: a
+// process crashes, a process fails to prepare, or shutdown exceeds its timeout. It suits
+// small teams without a full alerting stack.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Kind is the failure a notification is about
+type Kind string
+
+const (
+	// ProcessCrashed is sent when the Run of a process fails or panics
+	ProcessCrashed Kind = "process_crashed"
+	// PreRunFailed is sent when the PreRun of a process fails, stopping the startup
+	PreRunFailed Kind = "prerun_failed"
+	// ShutdownTimeout is sent when the processes do not shut down within CLOSE_TIMEOUT
+	ShutdownTimeout Kind = "shutdown_timeout"
+)
+
+// Notification describes a failure of a service
+type Notification struct {
+	Kind        Kind
+	Service     string
+	Version     string
+	Environment string
+	Host        string
+	// Process is the name of the failed process, empty for shutdown timeouts
+	Process string
+	Err     error
+	Time    time.Time
+}
+
+// ErrorChain returns the messages of the error and of the errors it wraps, outermost
+// first
+func (n Notification) ErrorChain() []string {
+	var chain []string
+	queue := []error{n.Err}
+	for len(queue) > 0 {
+		err := queue[0]
+		queue = queue[1:]
+		if err == nil {
+			continue
+		}
+		chain = append(chain, err.Error())
+		switch e := err.(type) {
+		case interface{ Unwrap() error }:
+			queue = append(queue, e.Unwrap())
+		case interface{ Unwrap() []error }:
+			queue = append(queue, e.Unwrap()...)
+		}
+	}
+	return chain
+}
+
+// Notifier delivers notifications
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// Option configures a Webhook
+type Option func(*Webhook)
+
+// WithTimeout bounds delivering a notification, 5s by default
+func WithTimeout(timeout time.Duration) Option {
+	return func(w *Webhook) {
+		w.timeout = timeout
+	}
+}
+
+// WithHTTPClient sets the HTTP client delivering notifications
+func WithHTTPClient(client *http.Client) Option {
+	return func(w *Webhook) {
+		w.httpClient = client
+	}
+}
+
+// WithHeader sets a header of the requests, such as an authorization token
+func WithHeader(key, value string) Option {
+	return func(w *Webhook) {
+		w.header.Set(key, value)
+	}
+}
+
+// WithKinds restricts the notifications sent to the kinds, all kinds by default
+func WithKinds(kinds ...Kind) Option {
+	return func(w *Webhook) {
+		w.kinds = kinds
+	}
+}
+
+// Webhook posts notifications to a URL
+type Webhook struct {
+	url        string
+	encode     func(Notification) any
+	timeout    time.Duration
+	httpClient *http.Client
+	header     http.Header
+	kinds      []Kind
+}
+
+// New creates a webhook posting notifications as JSON objects with the kind, service,
+// version, environment, host, process, error, error chain and time
+func New(url string, opts ...Option) *Webhook {
+	return newWebhook(url, encodeJSON, opts)
+}
+
+// NewSlack creates a webhook posting notifications as messages to a Slack incoming
+// webhook URL
+func NewSlack(url string, opts ...Option) *Webhook {
+	return newWebhook(url, encodeSlack, opts)
+}
+
+func newWebhook(url string, encode func(Notification) any, opts []Option) *Webhook {
+	w := &Webhook{
+		url:        url,
+		encode:     encode,
+		timeout:    5 * time.Second,
+		httpClient: http.DefaultClient,
+		header:     http.Header{},
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// Notify posts a notification, unless its kind is filtered out
+func (w *Webhook) Notify(ctx context.Context, n Notification) error {
+	if len(w.kinds) > 0 && !slices.Contains(w.kinds, n.Kind) {
+		return nil
+	}
+
+	body, err := json.Marshal(w.encode(n))
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, w.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	for key, values := range w.header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %w", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// payload is the body of generic webhooks
+type payload struct {
+	Kind        Kind      `json:"kind"`
+	Service     string    `json:"service"`
+	Version     string    `json:"version,omitempty"`
+	Environment string    `json:"environment,omitempty"`
+	Host        string    `json:"host,omitempty"`
+	Process     string    `json:"process,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	ErrorChain  []string  `json:"error_chain,omitempty"`
+	Time        time.Time `json:"time"`
+}
+
+func encodeJSON(n Notification) any {
+	p := payload{
+		Kind:        n.Kind,
+		Service:     n.Service,
+		Version:     n.Version,
+		Environment: n.Environment,
+		Host:        n.Host,
+		Process:     n.Process,
+		ErrorChain:  n.ErrorChain(),
+		Time:        n.Time,
+	}
+	if n.Err != nil {
+		p.Error = n.Err.Error()
+	}
+	return p
+}
+
+// titles describe the kinds in Slack messages
+var titles = map[Kind]string{
+	ProcessCrashed:  "process crashed",
+	PreRunFailed:    "startup failed",
+	ShutdownTimeout: "shutdown timed out",
+}
+
+func encodeSlack(n Notification) any {
+	var b strings.Builder
+	fmt.Fprintf(&b, ":rotating_light: *%s %s*", n.Service, titles[n.Kind])
+	if n.Process != "" {
+		fmt.Fprintf(&b, " in `%s`", n.Process)
+	}
+	fmt.Fprintf(&b, "\nhost: %s, version: %s", n.Host, n.Version)
+	if n.Environment != "" {
+		fmt.Fprintf(&b, ", environment: %s", n.Environment)
+	}
+	if chain := n.ErrorChain(); len(chain) > 0 {
+		fmt.Fprintf(&b, "\n```%s```", strings.Join(chain, "\n"))
+	}
+	return map[string]string{"text": b.String()}
+}
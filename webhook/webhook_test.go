@@ -0,0 +1,121 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startReceiver records the body and headers of the requests it receives
+func startReceiver(t *testing.T, status int) (*[]map[string]any, *http.Header, string) {
+	t.Helper()
+
+	var bodies []map[string]any
+	var header http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+		header = r.Header
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return &bodies, &header, srv.URL
+}
+
+func crash() Notification {
+	cause := errors.New("connection refused")
+	return Notification{
+		Kind:        ProcessCrashed,
+		Service:     "orders",
+		Version:     "1.4.2",
+		Environment: "production",
+		Host:        "orders-0",
+		Process:     "indexer",
+		Err:         fmt.Errorf("indexer: %w", errors.Join(cause, errors.New("retries exhausted"))),
+		Time:        time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestNotification_ErrorChain(t *testing.T) {
+	// Act
+	chain := crash().ErrorChain()
+
+	// Assert
+	assert.Equal(t, []string{
+		"indexer: connection refused\nretries exhausted",
+		"connection refused\nretries exhausted",
+		"connection refused",
+		"retries exhausted",
+	}, chain)
+}
+
+func TestWebhook_Notify(t *testing.T) {
+	// Arrange
+	bodies, header, url := startReceiver(t, http.StatusOK)
+	w := New(url, WithHeader("Authorization", "Bearer secret"))
+
+	// Act
+	err := w.Notify(context.Background(), crash())
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, *bodies, 1)
+	body := (*bodies)[0]
+	assert.Equal(t, "process_crashed", body["kind"])
+	assert.Equal(t, "orders", body["service"])
+	assert.Equal(t, "1.4.2", body["version"])
+	assert.Equal(t, "orders-0", body["host"])
+	assert.Equal(t, "indexer", body["process"])
+	assert.Len(t, body["error_chain"], 4)
+	assert.Equal(t, "Bearer secret", header.Get("Authorization"))
+}
+
+func TestNewSlack(t *testing.T) {
+	// Arrange
+	bodies, _, url := startReceiver(t, http.StatusOK)
+	w := NewSlack(url)
+
+	// Act
+	err := w.Notify(context.Background(), crash())
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, *bodies, 1)
+	text := (*bodies)[0]["text"].(string)
+	assert.Contains(t, text, "*orders process crashed* in `indexer`")
+	assert.Contains(t, text, "host: orders-0, version: 1.4.2, environment: production")
+	assert.Contains(t, text, "connection refused")
+}
+
+func TestWebhook_WithKinds(t *testing.T) {
+	// Arrange
+	bodies, _, url := startReceiver(t, http.StatusOK)
+	w := New(url, WithKinds(ShutdownTimeout))
+
+	// Act
+	err := w.Notify(context.Background(), crash())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Empty(t, *bodies, "other kinds are filtered out")
+}
+
+func TestWebhook_RejectedStatus(t *testing.T) {
+	// Arrange
+	_, _, url := startReceiver(t, http.StatusForbidden)
+
+	// Act
+	err := New(url).Notify(context.Background(), crash())
+
+	// Assert
+	assert.EqualError(t, err, "webhook returned status 403")
+}
@@ -0,0 +1,310 @@
+// Package worker runs background tasks on a bounded pool of goroutines as a server
+// process. Tasks wait in a bounded queue, submitters are held back or turned away when it
+// is full, panics are isolated to the task, and shutdown drains the queue before the
+// close timeout.
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	metricsreg "github.com/legrch/netgex/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// ErrQueueFull is returned by TrySubmit when the queue has no room
+	ErrQueueFull = errors.New("worker queue full")
+	// ErrClosed is returned when tasks are submitted after shutdown began
+	ErrClosed = errors.New("worker pool closed")
+)
+
+// Task is a unit of background work. Its context is canceled when shutdown gives up
+// waiting for the queue to drain.
+type Task func(ctx context.Context) error
+
+// Option configures a Pool
+type Option func(*Pool)
+
+// WithName names the pool in logs and metrics, "default" by default
+func WithName(name string) Option {
+	return func(p *Pool) {
+		p.name = name
+	}
+}
+
+// WithWorkers sets how many tasks run at once, GOMAXPROCS by default
+func WithWorkers(n int) Option {
+	return func(p *Pool) {
+		p.workers = n
+	}
+}
+
+// WithQueueSize sets how many tasks may wait for a worker, 100 by default
+func WithQueueSize(n int) Option {
+	return func(p *Pool) {
+		p.queueSize = n
+	}
+}
+
+// WithLogger sets the logger of the pool, slog.Default() by default
+func WithLogger(logger *slog.Logger) Option {
+	return func(p *Pool) {
+		p.logger = logger
+	}
+}
+
+// WithMetrics records the queue depth, the time tasks wait in the queue and task results
+// in the worker_* metrics of the namespace, labeled with the pool name
+func WithMetrics(namespace string) Option {
+	return func(p *Pool) {
+		p.metrics = poolMetrics(namespace)
+	}
+}
+
+// item is a queued task
+type item struct {
+	task     Task
+	enqueued time.Time
+}
+
+// Pool is a process running submitted tasks on a fixed number of workers
+type Pool struct {
+	name      string
+	workers   int
+	queueSize int
+	logger    *slog.Logger
+	metrics   *metrics
+
+	queue chan item
+	// closing wakes submitters blocked on a full queue when shutdown begins; mu keeps
+	// the queue open while submitters send to it
+	closing   chan struct{}
+	closeOnce sync.Once
+	mu        sync.RWMutex
+	closed    bool
+
+	start   sync.Once
+	running sync.WaitGroup
+	// taskCtx is the context of every task, canceled when shutdown stops waiting
+	taskCtx    context.Context
+	cancelTask context.CancelFunc
+}
+
+// New creates a pool from opts; tasks may be submitted before it runs
+func New(opts ...Option) *Pool {
+	taskCtx, cancel := context.WithCancel(context.Background())
+	p := &Pool{
+		name:       "default",
+		workers:    runtime.GOMAXPROCS(0),
+		queueSize:  100,
+		logger:     slog.Default(),
+		closing:    make(chan struct{}),
+		taskCtx:    taskCtx,
+		cancelTask: cancel,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	p.workers = max(p.workers, 1)
+	p.queue = make(chan item, max(p.queueSize, 0))
+	return p
+}
+
+// Submit queues a task, waiting for room while the queue is full until ctx is done.
+// Waiting submitters are how backpressure reaches callers.
+func (p *Pool) Submit(ctx context.Context, task Task) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+	select {
+	case p.queue <- item{task: task, enqueued: time.Now()}:
+		p.metrics.enqueued(p.name)
+		return nil
+	case <-p.closing:
+		return ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// TrySubmit queues a task, returning ErrQueueFull at once when the queue is full, for
+// callers that shed load rather than wait
+func (p *Pool) TrySubmit(task Task) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+	select {
+	case p.queue <- item{task: task, enqueued: time.Now()}:
+		p.metrics.enqueued(p.name)
+		return nil
+	default:
+		p.metrics.result(p.name, "rejected")
+		return ErrQueueFull
+	}
+}
+
+// Len returns the number of queued tasks
+func (p *Pool) Len() int {
+	return len(p.queue)
+}
+
+// PreRun prepares the pool
+func (*Pool) PreRun(_ context.Context) error {
+	return nil
+}
+
+// Run starts the workers and returns when ctx is canceled. The workers keep running
+// queued tasks until Shutdown drains the queue.
+func (p *Pool) Run(ctx context.Context) error {
+	p.logger.Info("starting worker pool", "pool", p.name, "workers", p.workers, "queue_size", p.queueSize)
+	p.startWorkers()
+	select {
+	case <-ctx.Done():
+	case <-p.closing:
+	}
+	return nil
+}
+
+// Shutdown stops accepting tasks and waits for the workers to run the queued ones. When
+// ctx ends first, running tasks are canceled, queued ones are dropped and an error is
+// returned.
+func (p *Pool) Shutdown(ctx context.Context) error {
+	p.logger.Info("shutting down worker pool", "pool", p.name, "queued", len(p.queue))
+
+	// Wake blocked submitters first, as they hold mu until they return
+	p.closeOnce.Do(func() { close(p.closing) })
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.queue)
+	}
+	p.mu.Unlock()
+	// Drain tasks submitted before the pool ran
+	p.startWorkers()
+
+	done := make(chan struct{})
+	go func() {
+		p.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		p.cancelTask()
+		return nil
+	case <-ctx.Done():
+		dropped := len(p.queue)
+		p.cancelTask()
+		return fmt.Errorf("worker pool %s did not drain, %d tasks dropped: %w", p.name, dropped, ctx.Err())
+	}
+}
+
+// startWorkers starts the workers once
+func (p *Pool) startWorkers() {
+	p.start.Do(func() {
+		p.running.Add(p.workers)
+		for range p.workers {
+			go p.work()
+		}
+	})
+}
+
+// work runs queued tasks until the queue is closed and empty
+func (p *Pool) work() {
+	defer p.running.Done()
+	for it := range p.queue {
+		p.metrics.dequeued(p.name, time.Since(it.enqueued))
+		if p.taskCtx.Err() != nil {
+			p.metrics.result(p.name, "dropped")
+			continue
+		}
+		p.run(it.task)
+	}
+}
+
+// run runs a task, recovering panics so they only fail the task
+func (p *Pool) run(task Task) {
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			p.logger.Error("panic in worker task", "pool", p.name, "panic", r, "stack", string(debug.Stack()))
+			p.metrics.result(p.name, "panic")
+		}
+	}()
+
+	if err := task(p.taskCtx); err != nil {
+		p.logger.Error("worker task failed", "pool", p.name, "duration", time.Since(start), "error", err)
+		p.metrics.result(p.name, "error")
+		return
+	}
+	p.metrics.result(p.name, "success")
+}
+
+// metrics are the collectors of a namespace; a nil *metrics records nothing
+type metrics struct {
+	depth   *prometheus.GaugeVec
+	latency *prometheus.HistogramVec
+	tasks   *prometheus.CounterVec
+}
+
+// enqueued records a task entering the queue
+func (m *metrics) enqueued(pool string) {
+	if m != nil {
+		m.depth.WithLabelValues(pool).Inc()
+	}
+}
+
+// dequeued records a task leaving the queue after waiting for wait
+func (m *metrics) dequeued(pool string, wait time.Duration) {
+	if m != nil {
+		m.depth.WithLabelValues(pool).Dec()
+		m.latency.WithLabelValues(pool).Observe(wait.Seconds())
+	}
+}
+
+// result records the outcome of a task
+func (m *metrics) result(pool, result string) {
+	if m != nil {
+		m.tasks.WithLabelValues(pool, result).Inc()
+	}
+}
+
+// poolMetrics returns the collectors of the namespace, registering them unless already
+// registered
+func poolMetrics(namespace string) *metrics {
+	m := &metrics{
+		depth: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "worker_queue_depth",
+			Help:      "Number of tasks waiting in the worker pool queue",
+		}, []string{"pool"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "worker_queue_latency_seconds",
+			Help:      "Time tasks wait in the worker pool queue in seconds",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"pool"}),
+		tasks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "worker_tasks_total",
+			Help:      "Total number of worker pool tasks by result: success, error, panic, rejected or dropped",
+		}, []string{"pool", "result"}),
+	}
+	m.depth = metricsreg.Register(m.depth)
+	m.latency = metricsreg.Register(m.latency)
+	m.tasks = metricsreg.Register(m.tasks)
+	return m
+}
@@ -0,0 +1,147 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool_RunsTasks(t *testing.T) {
+	// Arrange
+	p := New(WithName("runs"), WithWorkers(2), WithMetrics("workertest"))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = p.Run(ctx) }()
+	var done atomic.Int32
+
+	// Act
+	for range 10 {
+		require.NoError(t, p.Submit(ctx, func(context.Context) error {
+			done.Add(1)
+			return nil
+		}))
+	}
+	require.NoError(t, p.Submit(ctx, func(context.Context) error { return errors.New("boom") }))
+	require.NoError(t, p.Submit(ctx, func(context.Context) error { panic("boom") }))
+	err := p.Shutdown(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int32(10), done.Load())
+	m := poolMetrics("workertest")
+	assert.Equal(t, 10.0, testutil.ToFloat64(m.tasks.WithLabelValues("runs", "success")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.tasks.WithLabelValues("runs", "error")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.tasks.WithLabelValues("runs", "panic")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.depth.WithLabelValues("runs")))
+}
+
+func TestPool_Backpressure(t *testing.T) {
+	// Arrange
+	p := New(WithWorkers(1), WithQueueSize(1))
+	release := make(chan struct{})
+	started := make(chan struct{})
+	blocking := func(context.Context) error {
+		close(started)
+		<-release
+		return nil
+	}
+	go func() { _ = p.Run(context.Background()) }()
+	require.NoError(t, p.Submit(context.Background(), blocking))
+	<-started
+	require.NoError(t, p.TrySubmit(func(context.Context) error { return nil }))
+
+	// Act
+	fullErr := p.TrySubmit(func(context.Context) error { return nil })
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	waitErr := p.Submit(ctx, func(context.Context) error { return nil })
+	close(release)
+
+	// Assert
+	assert.ErrorIs(t, fullErr, ErrQueueFull)
+	assert.ErrorIs(t, waitErr, context.DeadlineExceeded)
+	assert.NoError(t, p.Shutdown(context.Background()))
+}
+
+func TestPool_SubmitAfterShutdown(t *testing.T) {
+	// Arrange
+	p := New()
+	require.NoError(t, p.Shutdown(context.Background()))
+
+	// Act
+	err := p.Submit(context.Background(), func(context.Context) error { return nil })
+	tryErr := p.TrySubmit(func(context.Context) error { return nil })
+
+	// Assert
+	assert.ErrorIs(t, err, ErrClosed)
+	assert.ErrorIs(t, tryErr, ErrClosed)
+}
+
+func TestPool_ShutdownDrainsQueuedTasks(t *testing.T) {
+	// Arrange
+	p := New(WithWorkers(1))
+	var done atomic.Int32
+	for range 5 {
+		require.NoError(t, p.TrySubmit(func(context.Context) error {
+			done.Add(1)
+			return nil
+		}))
+	}
+
+	// Act
+	err := p.Shutdown(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int32(5), done.Load())
+}
+
+func TestPool_ShutdownTimeout(t *testing.T) {
+	// Arrange
+	p := New(WithWorkers(1))
+	started := make(chan struct{})
+	canceled := make(chan struct{})
+	require.NoError(t, p.TrySubmit(func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		close(canceled)
+		return ctx.Err()
+	}))
+	require.NoError(t, p.TrySubmit(func(context.Context) error { return nil }))
+	go func() { _ = p.Run(context.Background()) }()
+	<-started
+
+	// Act
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := p.Shutdown(ctx)
+
+	// Assert
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, "1 tasks dropped")
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("running task was not canceled")
+	}
+}
+
+func TestWithMetrics_SharedBetweenPools(t *testing.T) {
+	// Act
+	var first, second *Pool
+	assert.NotPanics(t, func() {
+		first = New(WithMetrics("workershared"))
+		second = New(WithMetrics("workershared"))
+	})
+
+	// Assert
+	assert.Same(t, first.metrics.depth, second.metrics.depth)
+	assert.Same(t, first.metrics.latency, second.metrics.latency)
+	assert.Same(t, first.metrics.tasks, second.metrics.tasks)
+}
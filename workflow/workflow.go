@@ -0,0 +1,144 @@
+// Package workflow runs the worker of a workflow engine, such as a Temporal worker, as a
+// server process, so one binary serves gRPC and executes workflows with the same logger,
+// telemetry providers and graceful shutdown.
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// Worker is the lifecycle of a workflow engine worker. Temporal's worker.Worker
+// satisfies it: Start polls task queues in the background and Stop waits for running
+// activities to finish.
+type Worker interface {
+	Start() error
+	Stop()
+}
+
+// Option configures a Process
+type Option func(*Process)
+
+// WithName names the worker in logs, e.g. after its task queue
+func WithName(name string) Option {
+	return func(p *Process) {
+		p.name = name
+	}
+}
+
+// WithLogger sets the logger of the process, slog.Default() by default
+func WithLogger(logger *slog.Logger) Option {
+	return func(p *Process) {
+		p.logger = logger
+	}
+}
+
+// Process runs a Worker as a server process
+type Process struct {
+	worker Worker
+	name   string
+	logger *slog.Logger
+
+	ready    chan struct{}
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewProcess creates a process running worker
+func NewProcess(worker Worker, opts ...Option) *Process {
+	p := &Process{
+		worker: worker,
+		name:   "workflow",
+		logger: slog.Default(),
+		ready:  make(chan struct{}),
+		stop:   make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// PreRun prepares the process
+func (*Process) PreRun(_ context.Context) error {
+	return nil
+}
+
+// Run starts the worker and returns when ctx is canceled or the process is shut down
+func (p *Process) Run(ctx context.Context) error {
+	p.logger.Info("starting workflow worker", "worker", p.name)
+	if err := p.worker.Start(); err != nil {
+		return fmt.Errorf("failed to start workflow worker %s: %w", p.name, err)
+	}
+	close(p.ready)
+
+	select {
+	case <-ctx.Done():
+	case <-p.stop:
+	}
+	return nil
+}
+
+// Ready is closed once the worker has started
+func (p *Process) Ready() <-chan struct{} {
+	return p.ready
+}
+
+// Shutdown stops the worker, waiting for its running activities until ctx ends
+func (p *Process) Shutdown(ctx context.Context) error {
+	p.logger.Info("shutting down workflow worker", "worker", p.name)
+	p.stopOnce.Do(func() { close(p.stop) })
+
+	select {
+	case <-p.ready:
+	default:
+		// Never started, nothing to stop
+		return nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		p.worker.Stop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("workflow worker %s did not stop: %w", p.name, ctx.Err())
+	}
+}
+
+// Logger adapts a slog.Logger to the key-value logger interface of workflow engines,
+// such as Temporal's log.Logger, so their logs go where the server's go
+type Logger struct {
+	logger *slog.Logger
+}
+
+// NewLogger creates a Logger writing to logger
+func NewLogger(logger *slog.Logger) *Logger {
+	return &Logger{logger: logger}
+}
+
+// Debug logs at debug level
+func (l *Logger) Debug(msg string, keyvals ...any) {
+	l.logger.Debug(msg, keyvals...)
+}
+
+// Info logs at info level
+func (l *Logger) Info(msg string, keyvals ...any) {
+	l.logger.Info(msg, keyvals...)
+}
+
+// Warn logs at warn level
+func (l *Logger) Warn(msg string, keyvals ...any) {
+	l.logger.Warn(msg, keyvals...)
+}
+
+// Error logs at error level
+func (l *Logger) Error(msg string, keyvals ...any) {
+	l.logger.Error(msg, keyvals...)
+}
@@ -0,0 +1,105 @@
+package workflow
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeWorker records its lifecycle; Stop blocks until release is closed
+type fakeWorker struct {
+	startErr error
+	started  chan struct{}
+	stopped  chan struct{}
+	release  chan struct{}
+}
+
+func newFakeWorker() *fakeWorker {
+	release := make(chan struct{})
+	close(release)
+	return &fakeWorker{started: make(chan struct{}), stopped: make(chan struct{}), release: release}
+}
+
+func (w *fakeWorker) Start() error {
+	if w.startErr != nil {
+		return w.startErr
+	}
+	close(w.started)
+	return nil
+}
+
+func (w *fakeWorker) Stop() {
+	<-w.release
+	close(w.stopped)
+}
+
+func TestProcess_Lifecycle(t *testing.T) {
+	// Arrange
+	w := newFakeWorker()
+	p := NewProcess(w, WithName("orders"))
+	errCh := make(chan error, 1)
+
+	// Act
+	go func() { errCh <- p.Run(context.Background()) }()
+	<-p.Ready()
+	err := p.Shutdown(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	require.NoError(t, <-errCh)
+	select {
+	case <-w.stopped:
+	default:
+		t.Fatal("worker was not stopped")
+	}
+}
+
+func TestProcess_StartError(t *testing.T) {
+	// Arrange
+	w := newFakeWorker()
+	w.startErr = errors.New("namespace not found")
+	p := NewProcess(w)
+
+	// Act
+	err := p.Run(context.Background())
+
+	// Assert
+	assert.ErrorContains(t, err, "namespace not found")
+	assert.NoError(t, p.Shutdown(context.Background()))
+}
+
+func TestProcess_ShutdownTimeout(t *testing.T) {
+	// Arrange
+	w := newFakeWorker()
+	w.release = make(chan struct{})
+	defer close(w.release)
+	p := NewProcess(w)
+	go func() { _ = p.Run(context.Background()) }()
+	<-p.Ready()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// Act
+	err := p.Shutdown(ctx)
+
+	// Assert
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestLogger(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	logger := NewLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	// Act
+	logger.Info("workflow completed", "WorkflowID", "order-1")
+
+	// Assert
+	assert.Contains(t, buf.String(), `msg="workflow completed" WorkflowID=order-1`)
+}